@@ -17,21 +17,37 @@ type BoundedQueue struct {
 	currentBytes int64
 	currentWaiters int64
 	lock sync.Mutex
-	waiters *orderedmap.OrderedMap[uuid.UUID, waiter]
+	waiters *orderedmap.OrderedMap[uuid.UUID, *waiter]
+
+	// policy orders the waiters, see WithPolicy().
+	policy Policy
+	// fair shares admission among clients under PolicyWeighted.
+	fair *fairShare
 }
 
 type waiter struct {
 	readyCh chan struct{} 
 	pendingBytes int64
 	ID uuid.UUID 
+	// err is set before readyCh closes when the waiter is
+	// shed instead of admitted.
+	err error
+	// client and tag order the waiter under PolicyWeighted.
+	client string
+	tag float64
 }
 
-func NewBoundedQueue(maxLimitBytes, maxLimitWaiters int64) *BoundedQueue {
-	return &BoundedQueue{
+func NewBoundedQueue(maxLimitBytes, maxLimitWaiters int64, opts ...Option) *BoundedQueue {
+	bq := &BoundedQueue{
 		maxLimitBytes: maxLimitBytes,
 		maxLimitWaiters: maxLimitWaiters,
-		waiters: orderedmap.New[uuid.UUID, waiter](), 
+		waiters: orderedmap.New[uuid.UUID, *waiter](), 
+		policy: PolicyFIFO,
+	}
+	for _, opt := range opts {
+		opt(bq)
 	}
+	return bq
 }
 
 func (bq *BoundedQueue) admit(pendingBytes int64) (bool, error) {
@@ -49,7 +65,11 @@ func (bq *BoundedQueue) admit(pendingBytes int64) (bool, error) {
 
 	// since we were unable to admit, check if we can wait.
 	if bq.currentWaiters + 1 > bq.maxLimitWaiters { // too many waiters
-		return false, ErrTooManyWaiters
+		// LIFO sheds the oldest waiter in favor of this request.
+		if bq.policy != PolicyLIFO || !bq.shedOldest() {
+			return false, ErrTooManyWaiters
+		}
+		return false, nil
 	}
 
 	// if we got to this point we need to wait to acquire bytes, so update currentWaiters before releasing mutex.
@@ -64,12 +84,13 @@ func (bq *BoundedQueue) Acquire(ctx context.Context, pendingBytes int64) error {
 	}
 
 	// otherwise we need to wait for bytes to be released
-	curWaiter := waiter{
+	curWaiter := &waiter{
 		pendingBytes: pendingBytes,
 		readyCh: make(chan struct{}),
 	}
 
 	bq.lock.Lock()
+	bq.fair.enqueue(ctx, curWaiter)
 
 	// generate unique key
 	for {
@@ -87,7 +108,7 @@ func (bq *BoundedQueue) Acquire(ctx context.Context, pendingBytes int64) error {
 
 	select {
 	case <-curWaiter.readyCh:
-		return nil
+		return curWaiter.err
 	case <-ctx.Done():
 		// canceled before acquired so remove waiter.
 		bq.lock.Lock()
@@ -99,6 +120,7 @@ func (bq *BoundedQueue) Acquire(ctx context.Context, pendingBytes int64) error {
 			return err
 		}
 
+		bq.fair.dequeue(curWaiter)
 		bq.currentWaiters -= 1
 		return err
 	}
@@ -118,12 +140,12 @@ func (bq *BoundedQueue) Release(pendingBytes int64) error {
 		if bq.waiters.Len() == 0 {
 			return nil
 		}
-		next := bq.waiters.Oldest()
-		nextWaiter := next.Value
-		nextKey := next.Key
+		nextWaiter := bq.next()
+		nextKey := nextWaiter.ID
 		if bq.currentBytes + nextWaiter.pendingBytes <= bq.maxLimitBytes {
 			bq.currentBytes += nextWaiter.pendingBytes
 			bq.currentWaiters -= 1
+			bq.fair.admitted(nextWaiter)
 			close(nextWaiter.readyCh)
 			_, found := bq.waiters.Delete(nextKey)
 			if !found {
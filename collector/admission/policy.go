@@ -0,0 +1,187 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+)
+
+// Policy orders the requests waiting for admission.
+type Policy string
+
+const (
+	// PolicyFIFO admits waiters in the order they arrived, and
+	// rejects new requests when the waiter limit is reached.
+	PolicyFIFO Policy = "fifo"
+	// PolicyLIFO admits the newest waiter first, and sheds the
+	// oldest waiter, which has likely exceeded its client's
+	// deadline, in favor of new requests when the waiter limit is
+	// reached.
+	PolicyLIFO Policy = "lifo"
+	// PolicyWeighted shares admission among clients in proportion
+	// to their weights, see WithWeights().  Each client's waiters
+	// are admitted in the order they arrived.
+	PolicyWeighted Policy = "weighted"
+)
+
+// ErrShed is returned to a waiter shed under PolicyLIFO.
+var ErrShed = fmt.Errorf("rejecting request, shed in favor of newer requests")
+
+// Validate returns an error for an unknown policy.  The empty policy
+// is PolicyFIFO.
+func (p Policy) Validate() error {
+	switch p {
+	case "", PolicyFIFO, PolicyLIFO, PolicyWeighted:
+		return nil
+	}
+	return fmt.Errorf("unknown admission policy %q, use %s, %s or %s", p, PolicyFIFO, PolicyLIFO, PolicyWeighted)
+}
+
+// Option configures a BoundedQueue.
+type Option func(*BoundedQueue)
+
+// WithPolicy orders the waiters of the queue.  PolicyWeighted also
+// requires WithWeights(), without which every request belongs to the
+// same client.
+func WithPolicy(p Policy) Option {
+	return func(bq *BoundedQueue) {
+		if p == "" {
+			p = PolicyFIFO
+		}
+		bq.policy = p
+		if p == PolicyWeighted && bq.fair == nil {
+			bq.fair = newFairShare(nil, nil)
+		}
+	}
+}
+
+// WithWeights selects PolicyWeighted, under which identify names the
+// client of the context passed to Acquire() and weights are the
+// shares of the named clients.  Clients without a weight, including
+// requests without an identity, have weight 1.
+func WithWeights(identify func(context.Context) string, weights map[string]int) Option {
+	return func(bq *BoundedQueue) {
+		bq.policy = PolicyWeighted
+		bq.fair = newFairShare(identify, weights)
+	}
+}
+
+// next returns the waiter to admit next, called with the lock held
+// when there are waiters.
+func (bq *BoundedQueue) next() *waiter {
+	switch bq.policy {
+	case PolicyLIFO:
+		return bq.waiters.Newest().Value
+	case PolicyWeighted:
+		return bq.fair.next(bq)
+	default:
+		return bq.waiters.Oldest().Value
+	}
+}
+
+// shedOldest rejects the oldest waiter, whose place goes to a new
+// request.  Returns false when there is no waiter to shed, called
+// with the lock held.
+func (bq *BoundedQueue) shedOldest() bool {
+	oldest := bq.waiters.Oldest()
+	if oldest == nil {
+		return false
+	}
+	w := oldest.Value
+	bq.waiters.Delete(w.ID)
+	bq.fair.dequeue(w)
+	w.err = ErrShed
+	close(w.readyCh)
+	return true
+}
+
+// fairShare implements start-time fair queuing: each waiter is tagged
+// with the virtual time at which its client's previous waiters will
+// have been served, in proportion to the client's weight, and the
+// waiter with the lowest tag is admitted next.  A nil *fairShare
+// does nothing.
+type fairShare struct {
+	identify func(context.Context) string
+	weights  map[string]int
+	// vtime is the tag of the last waiter admitted.
+	vtime float64
+	// clients are the clients with waiters.
+	clients map[string]*clientShare
+}
+
+type clientShare struct {
+	// finish is the virtual time when the client's waiters will
+	// have been served.
+	finish  float64
+	waiters int
+}
+
+func newFairShare(identify func(context.Context) string, weights map[string]int) *fairShare {
+	return &fairShare{
+		identify: identify,
+		weights:  weights,
+		clients:  map[string]*clientShare{},
+	}
+}
+
+func (f *fairShare) weight(client string) float64 {
+	if w := f.weights[client]; w > 0 {
+		return float64(w)
+	}
+	return 1
+}
+
+// enqueue tags a new waiter.
+func (f *fairShare) enqueue(ctx context.Context, w *waiter) {
+	if f == nil {
+		return
+	}
+	if f.identify != nil {
+		w.client = f.identify(ctx)
+	}
+	cs := f.clients[w.client]
+	if cs == nil {
+		cs = &clientShare{}
+		f.clients[w.client] = cs
+	}
+	w.tag = f.vtime
+	if cs.finish > w.tag {
+		w.tag = cs.finish
+	}
+	cs.finish = w.tag + float64(w.pendingBytes)/f.weight(w.client)
+	cs.waiters++
+}
+
+// dequeue forgets a waiter that leaves without admission.  A client
+// without waiters starts afresh.
+func (f *fairShare) dequeue(w *waiter) {
+	if f == nil {
+		return
+	}
+	if cs := f.clients[w.client]; cs != nil {
+		if cs.waiters--; cs.waiters == 0 {
+			delete(f.clients, w.client)
+		}
+	}
+}
+
+// admitted advances the virtual time to the tag of an admitted
+// waiter.
+func (f *fairShare) admitted(w *waiter) {
+	if f == nil {
+		return
+	}
+	f.vtime = w.tag
+	f.dequeue(w)
+}
+
+// next returns the waiter with the lowest tag, the oldest among
+// equal tags.
+func (f *fairShare) next(bq *BoundedQueue) *waiter {
+	var best *waiter
+	for pair := bq.waiters.Oldest(); pair != nil; pair = pair.Next() {
+		if best == nil || pair.Value.tag < best.tag {
+			best = pair.Value
+		}
+	}
+	return best
+}
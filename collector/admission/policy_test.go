@@ -0,0 +1,155 @@
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type clientKey struct{}
+
+func withClient(client string) context.Context {
+	return context.WithValue(context.Background(), clientKey{}, client)
+}
+
+func identifyClient(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+// queueWaiters fills bq and starts one waiter of size for each
+// context, in order, returning the names of the waiters in the order
+// they are admitted.
+func queueWaiters(t *testing.T, bq *BoundedQueue, size int64, names []string, ctxs []context.Context) (<-chan string, <-chan error) {
+	require.True(t, bq.TryAcquire(bq.maxLimitBytes))
+
+	admitted := make(chan string, len(names))
+	errs := make(chan error, len(names))
+	for i, name := range names {
+		name, ctx := name, ctxs[i]
+		waiting := bq.waiters.Len()
+		go func() {
+			if err := bq.Acquire(ctx, size); err != nil {
+				errs <- err
+				return
+			}
+			admitted <- name
+		}()
+		// Wait for the waiter to queue, or to be rejected.
+		require.Eventually(t, func() bool {
+			bq.lock.Lock()
+			defer bq.lock.Unlock()
+			return bq.waiters.Len() > waiting || len(errs) != 0
+		}, time.Second, time.Millisecond)
+	}
+	return admitted, errs
+}
+
+// admitOne releases size bytes and returns the waiter admitted.
+func admitOne(t *testing.T, bq *BoundedQueue, size int64, admitted <-chan string) string {
+	require.NoError(t, bq.Release(size))
+	select {
+	case name := <-admitted:
+		return name
+	case <-time.After(time.Second):
+		t.Fatal("no waiter admitted")
+		return ""
+	}
+}
+
+func backgrounds(n int) []context.Context {
+	ctxs := make([]context.Context, n)
+	for i := range ctxs {
+		ctxs[i] = context.Background()
+	}
+	return ctxs
+}
+
+func TestPolicyFIFO(t *testing.T) {
+	bq := NewBoundedQueue(10, 10, WithPolicy(PolicyFIFO))
+	names := []string{"a", "b", "c"}
+	admitted, _ := queueWaiters(t, bq, 10, names, backgrounds(3))
+
+	require.Equal(t, "a", admitOne(t, bq, 10, admitted))
+	require.Equal(t, "b", admitOne(t, bq, 10, admitted))
+	require.Equal(t, "c", admitOne(t, bq, 10, admitted))
+}
+
+func TestPolicyLIFO(t *testing.T) {
+	bq := NewBoundedQueue(10, 10, WithPolicy(PolicyLIFO))
+	names := []string{"a", "b", "c"}
+	admitted, _ := queueWaiters(t, bq, 10, names, backgrounds(3))
+
+	require.Equal(t, "c", admitOne(t, bq, 10, admitted))
+	require.Equal(t, "b", admitOne(t, bq, 10, admitted))
+	require.Equal(t, "a", admitOne(t, bq, 10, admitted))
+}
+
+func TestPolicyLIFOShedsOldest(t *testing.T) {
+	bq := NewBoundedQueue(10, 2, WithPolicy(PolicyLIFO))
+	names := []string{"a", "b", "c"}
+	admitted, errs := queueWaiters(t, bq, 10, names, backgrounds(3))
+
+	// The third waiter took the place of the first.
+	require.ErrorIs(t, <-errs, ErrShed)
+	require.Equal(t, 2, bq.waiters.Len())
+	require.Equal(t, int64(2), bq.currentWaiters)
+
+	require.Equal(t, "c", admitOne(t, bq, 10, admitted))
+	require.Equal(t, "b", admitOne(t, bq, 10, admitted))
+	require.Equal(t, int64(0), bq.currentWaiters)
+}
+
+func TestPolicyFIFORejectsNewest(t *testing.T) {
+	bq := NewBoundedQueue(10, 2)
+	names := []string{"a", "b", "c"}
+	admitted, errs := queueWaiters(t, bq, 10, names, backgrounds(3))
+
+	require.ErrorIs(t, <-errs, ErrTooManyWaiters)
+	require.Equal(t, "a", admitOne(t, bq, 10, admitted))
+	require.Equal(t, "b", admitOne(t, bq, 10, admitted))
+}
+
+func TestPolicyWeighted(t *testing.T) {
+	bq := NewBoundedQueue(10, 20, WithWeights(identifyClient, map[string]int{"big": 3}))
+
+	// The big client queues its waiters first, yet the small
+	// client is admitted once for every three of the big client's.
+	var names []string
+	var ctxs []context.Context
+	for i := 0; i < 6; i++ {
+		names = append(names, "big")
+		ctxs = append(ctxs, withClient("big"))
+	}
+	for i := 0; i < 2; i++ {
+		names = append(names, "small")
+		ctxs = append(ctxs, withClient("small"))
+	}
+	admitted, _ := queueWaiters(t, bq, 10, names, ctxs)
+
+	var order []string
+	for range names {
+		order = append(order, admitOne(t, bq, 10, admitted))
+	}
+	require.Equal(t, []string{"big", "small", "big", "big", "big", "small", "big", "big"}, order)
+	require.Empty(t, bq.fair.clients)
+}
+
+func TestPolicyWeightedCanceled(t *testing.T) {
+	bq := NewBoundedQueue(10, 20, WithWeights(identifyClient, nil))
+	ctx, cancel := context.WithCancel(withClient("a"))
+	_, errs := queueWaiters(t, bq, 10, []string{"a"}, []context.Context{ctx})
+
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+	require.Empty(t, bq.fair.clients)
+}
+
+func TestPolicyValidate(t *testing.T) {
+	for _, p := range []Policy{"", PolicyFIFO, PolicyLIFO, PolicyWeighted} {
+		require.NoError(t, p.Validate())
+	}
+	require.ErrorContains(t, Policy("random").Validate(), `unknown admission policy "random"`)
+}
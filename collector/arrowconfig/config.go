@@ -0,0 +1,280 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package arrowconfig defines the OTel-Arrow settings shared by the
+// exporter and receiver.  Each type is meant to be embedded with
+// `mapstructure:",squash"` so that the resulting configuration keys
+// are identical wherever they appear, including in distributions
+// that build their own components on the Arrow streams.
+package arrowconfig // import "github.com/open-telemetry/otel-arrow/collector/arrowconfig"
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/open-telemetry/otel-arrow/collector/admission"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.uber.org/multierr"
+)
+
+// StreamConfig configures the number and lifetime of Arrow streams
+// opened by a client.
+type StreamConfig struct {
+	// NumStreams determines the number of OTel Arrow streams.
+	NumStreams int `mapstructure:"num_streams"`
+
+	// MaxStreamLifetime should be set to less than the value of
+	// grpc: keepalive: max_connection_age_grace plus the timeout.
+	MaxStreamLifetime time.Duration `mapstructure:"max_stream_lifetime"`
+}
+
+// CompressionConfig configures compression of the Arrow IPC payload,
+// independent of gRPC-level compression.
+type CompressionConfig struct {
+	// PayloadCompression is applied on the Arrow IPC stream
+	// internally and may have different results from using
+	// gRPC-level compression.  This is disabled by default, since
+	// gRPC-level compression is enabled by default.  This can be
+	// set to "zstd" to turn on Arrow-Zstd compression.
+	PayloadCompression configcompression.Type `mapstructure:"payload_compression"`
+
+	// PayloadZstd configures the Zstd encoder used for payload
+	// compression.
+	PayloadZstd PayloadZstdConfig `mapstructure:"payload_zstd"`
+
+	// DisablePayloadCompressionWithGRPC sends the payloads
+	// uncompressed when gRPC-level compression is enabled, so
+	// that each payload is compressed only once, by gRPC.
+	DisablePayloadCompressionWithGRPC bool `mapstructure:"disable_payload_compression_with_grpc"`
+}
+
+// PayloadZstdConfig configures the Zstd encoder or decoder of Arrow
+// payloads, which is shared by every stream with the same settings.
+type PayloadZstdConfig struct {
+	// WholePayload compresses each payload as a whole, instead
+	// of at the Arrow IPC level, on the streams of receivers that
+	// declare support for it in their capabilities.  The
+	// following settings configure the encoder of these
+	// payloads.  Decoders ignore it.
+	WholePayload bool `mapstructure:"whole_payload"`
+
+	// Concurrency is the number of payloads that are compressed
+	// or decompressed at once, zero for GOMAXPROCS.
+	Concurrency uint `mapstructure:"concurrency"`
+
+	// WindowSizeMiB is the window size of the encoder, or the
+	// largest window size accepted by the decoder, a power of
+	// two.  Zero selects the library default.
+	WindowSizeMiB uint32 `mapstructure:"window_size_mib"`
+
+	// Level is the compression level of the encoder, from 1 to
+	// 22, zero for the library default.  Decoders ignore it.
+	Level int `mapstructure:"level"`
+}
+
+// DowngradeConfig determines whether and when a client uses standard
+// OTLP instead of Arrow.
+type DowngradeConfig struct {
+	// Disabled prevents using OTel-Arrow streams.  The exporter
+	// falls back to standard OTLP.
+	Disabled bool `mapstructure:"disabled"`
+
+	// DisableDowngrade prevents this exporter from fallback back
+	// to standard OTLP.  If the Arrow service is unavailable, it
+	// will retry and/or fail.
+	DisableDowngrade bool `mapstructure:"disable_downgrade"`
+
+	// Downgrade determines whether a downgrade to standard OTLP
+	// lasts, see DowngradePolicyConfig.
+	Downgrade DowngradePolicyConfig `mapstructure:"downgrade"`
+}
+
+// DowngradePolicy names what a client does when the server does not
+// support Arrow.
+type DowngradePolicy string
+
+const (
+	// DowngradeNever keeps retrying Arrow, like DisableDowngrade.
+	DowngradeNever DowngradePolicy = "never"
+
+	// DowngradePermanent uses standard OTLP until the client
+	// restarts, the default.
+	DowngradePermanent DowngradePolicy = "permanent"
+
+	// DowngradeRetryInterval uses standard OTLP, then tries Arrow
+	// again after the retry interval, so that a server briefly
+	// replaced by one without Arrow support does not lock the
+	// client into standard OTLP.
+	DowngradeRetryInterval DowngradePolicy = "retry_interval"
+)
+
+// DowngradePolicyConfig configures the downgrade policy.
+type DowngradePolicyConfig struct {
+	// Policy is one of "never", "permanent" and
+	// "retry_interval".  Empty means "never" with
+	// DisableDowngrade, otherwise "permanent".
+	Policy DowngradePolicy `mapstructure:"policy"`
+
+	// RetryInterval is the time after a downgrade at which the
+	// "retry_interval" policy tries Arrow again.
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+}
+
+// AdmissionConfig limits the memory used by a server for Arrow
+// streams.
+type AdmissionConfig struct {
+	// MemoryLimitMiB is the size of a shared memory region used
+	// by all Arrow streams, in MiB.  When too much load is
+	// passing through, they will see ResourceExhausted errors.
+	MemoryLimitMiB uint64 `mapstructure:"memory_limit_mib"`
+
+	// AdmissionLimitMiB limits the number of requests that are received by the stream based on
+	// request size information available. Request size is used to control how much traffic we admit
+	// for processing, but does not control how much memory is used during request processing.
+	//
+	// Deprecated: receivers configure admission::request_limit_mib.
+	AdmissionLimitMiB uint64 `mapstructure:"admission_limit_mib"`
+
+	// WaiterLimit is the limit on the number of waiters waiting to be processed and consumed.
+	// This is a dimension of memory limiting to ensure waiters are not consuming an
+	// unexpectedly large amount of memory in the arrow receiver.
+	//
+	// Deprecated: receivers configure admission::waiter_limit.
+	WaiterLimit int64 `mapstructure:"waiter_limit"`
+
+	// AdmissionPolicy orders the requests waiting for admission:
+	// "fifo" (the default) admits them in order and rejects new
+	// requests at the waiter limit, "lifo" admits the newest first
+	// and sheds the oldest at the waiter limit, and "weighted"
+	// shares admission among clients, see AdmissionClients.
+	AdmissionPolicy admission.Policy `mapstructure:"admission_policy"`
+
+	// AdmissionClients identifies the clients of the weighted
+	// admission policy and their weights.
+	AdmissionClients AdmissionClientsConfig `mapstructure:"admission_clients"`
+}
+
+// AdmissionClientsConfig identifies clients by exactly one of a
+// client metadata key and an auth attribute.  Clients have weight 1
+// unless listed in Weights.
+type AdmissionClientsConfig struct {
+	MetadataKey   string         `mapstructure:"metadata_key"`
+	AuthAttribute string         `mapstructure:"auth_attribute"`
+	Weights       map[string]int `mapstructure:"weights"`
+}
+
+var (
+	_ component.ConfigValidator = (*StreamConfig)(nil)
+	_ component.ConfigValidator = (*CompressionConfig)(nil)
+	_ component.ConfigValidator = (*PayloadZstdConfig)(nil)
+	_ component.ConfigValidator = (*DowngradeConfig)(nil)
+	_ component.ConfigValidator = (*AdmissionConfig)(nil)
+)
+
+// MinStreamLifetime is the shortest MaxStreamLifetime.
+const MinStreamLifetime = time.Second
+
+// maxMiB is the largest MiB value that converts to a byte count
+// without overflowing an int64.
+const maxMiB = math.MaxInt64 >> 20
+
+// Validate returns an error when the number of streams is less than
+// 1 or the stream lifetime is shorter than MinStreamLifetime.
+func (cfg *StreamConfig) Validate() (errs error) {
+	if cfg.NumStreams < 1 {
+		errs = multierr.Append(errs, fmt.Errorf("num_streams: stream count must be > 0: %d", cfg.NumStreams))
+	}
+	if cfg.MaxStreamLifetime < MinStreamLifetime {
+		errs = multierr.Append(errs, fmt.Errorf("max_stream_lifetime: max stream life must be >= %v: %v", MinStreamLifetime, cfg.MaxStreamLifetime))
+	}
+	return errs
+}
+
+// Validate returns an error for payload compression other than
+// Zstd or none.
+func (cfg *CompressionConfig) Validate() (errs error) {
+	// The cfg.PayloadCompression field is validated by the underlying library,
+	// but we only support Zstd or none.
+	switch cfg.PayloadCompression {
+	case "none", "", configcompression.TypeZstd:
+	default:
+		errs = multierr.Append(errs, fmt.Errorf("payload_compression: unsupported payload compression: %s, use zstd or none", cfg.PayloadCompression))
+	}
+	return multierr.Append(errs, cfg.PayloadZstd.Validate())
+}
+
+// maxZstdWindowMiB is the largest Zstd window size.
+const maxZstdWindowMiB = 512
+
+// maxZstdLevel is the highest Zstd compression level.
+const maxZstdLevel = 22
+
+// Validate returns an error when the window size is not a power of
+// two within the Zstd limits, or the level is out of range.
+func (cfg *PayloadZstdConfig) Validate() (errs error) {
+	w := cfg.WindowSizeMiB
+	if w > maxZstdWindowMiB || w&(w-1) != 0 {
+		errs = multierr.Append(errs, fmt.Errorf("payload_zstd::window_size_mib: window size must be a power of two <= %d MiB: %d", maxZstdWindowMiB, w))
+	}
+	if cfg.Level < 0 || cfg.Level > maxZstdLevel {
+		errs = multierr.Append(errs, fmt.Errorf("payload_zstd::level: level must be between 1 and %d, or 0 for the default: %d", maxZstdLevel, cfg.Level))
+	}
+	return errs
+}
+
+// DowngradePolicy returns the downgrade policy in effect.
+func (cfg *DowngradeConfig) DowngradePolicy() DowngradePolicy {
+	switch {
+	case cfg.Downgrade.Policy != "":
+		return cfg.Downgrade.Policy
+	case cfg.DisableDowngrade:
+		return DowngradeNever
+	}
+	return DowngradePermanent
+}
+
+// Validate returns an error for an unknown downgrade policy, a
+// policy that contradicts DisableDowngrade, or a retry interval
+// missing for the "retry_interval" policy.
+func (cfg *DowngradeConfig) Validate() (errs error) {
+	switch policy := cfg.Downgrade.Policy; policy {
+	case "", DowngradeNever, DowngradePermanent, DowngradeRetryInterval:
+		if cfg.DisableDowngrade && policy != "" && policy != DowngradeNever {
+			errs = multierr.Append(errs, fmt.Errorf("disable_downgrade cannot be combined with downgrade::policy %q", policy))
+		}
+	default:
+		errs = multierr.Append(errs, fmt.Errorf("downgrade::policy: unknown downgrade policy %q, use never, permanent or retry_interval", policy))
+	}
+	if cfg.DowngradePolicy() == DowngradeRetryInterval && cfg.Downgrade.RetryInterval <= 0 {
+		errs = multierr.Append(errs, fmt.Errorf("downgrade::retry_interval: retry interval must be > 0: %v", cfg.Downgrade.RetryInterval))
+	}
+	return errs
+}
+
+// Validate returns an error for negative or overflowing limits.
+func (cfg *AdmissionConfig) Validate() (errs error) {
+	if cfg.MemoryLimitMiB > maxMiB {
+		errs = multierr.Append(errs, fmt.Errorf("memory_limit_mib: memory limit too large: %d MiB", cfg.MemoryLimitMiB))
+	}
+	if cfg.AdmissionLimitMiB > maxMiB {
+		errs = multierr.Append(errs, fmt.Errorf("admission_limit_mib: admission limit too large: %d MiB", cfg.AdmissionLimitMiB))
+	}
+	if cfg.WaiterLimit < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("waiter_limit: waiter limit must be >= 0: %d", cfg.WaiterLimit))
+	}
+	if err := cfg.AdmissionPolicy.Validate(); err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("admission_policy: %w", err))
+	}
+	clients := cfg.AdmissionClients
+	if cfg.AdmissionPolicy == admission.PolicyWeighted && (clients.MetadataKey == "") == (clients.AuthAttribute == "") {
+		errs = multierr.Append(errs, fmt.Errorf("admission_clients: set exactly one of metadata_key and auth_attribute for the weighted admission policy"))
+	}
+	for client, w := range clients.Weights {
+		if w < 1 {
+			errs = multierr.Append(errs, fmt.Errorf("admission_clients::weights: weight of %q must be >= 1: %d", client, w))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrowconfig
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/otel-arrow/collector/admission"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.uber.org/multierr"
+)
+
+func TestStreamConfigValidate(t *testing.T) {
+	require.NoError(t, (&StreamConfig{NumStreams: 1, MaxStreamLifetime: time.Second}).Validate())
+	require.NoError(t, (&StreamConfig{NumStreams: math.MaxInt, MaxStreamLifetime: time.Hour}).Validate())
+
+	require.ErrorContains(t, (&StreamConfig{NumStreams: 0, MaxStreamLifetime: time.Second}).Validate(), "stream count must be")
+	require.ErrorContains(t, (&StreamConfig{NumStreams: 1, MaxStreamLifetime: time.Millisecond}).Validate(), "max stream life must be")
+}
+
+func TestCompressionConfigValidate(t *testing.T) {
+	for _, ok := range []configcompression.Type{"", "none", configcompression.TypeZstd} {
+		require.NoError(t, (&CompressionConfig{PayloadCompression: ok}).Validate())
+	}
+	require.ErrorContains(t, (&CompressionConfig{PayloadCompression: configcompression.TypeGzip}).Validate(), "unsupported payload compression")
+}
+
+func TestAdmissionConfigValidate(t *testing.T) {
+	require.NoError(t, (&AdmissionConfig{}).Validate())
+	require.NoError(t, (&AdmissionConfig{MemoryLimitMiB: 128, AdmissionLimitMiB: 64, WaiterLimit: 1000}).Validate())
+
+	require.ErrorContains(t, (&AdmissionConfig{WaiterLimit: -1}).Validate(), "waiter limit")
+	require.ErrorContains(t, (&AdmissionConfig{MemoryLimitMiB: math.MaxUint64}).Validate(), "memory limit")
+	require.ErrorContains(t, (&AdmissionConfig{AdmissionLimitMiB: math.MaxInt64}).Validate(), "admission limit")
+
+	for _, p := range []admission.Policy{admission.PolicyFIFO, admission.PolicyLIFO} {
+		require.NoError(t, (&AdmissionConfig{AdmissionPolicy: p}).Validate())
+	}
+	require.NoError(t, (&AdmissionConfig{
+		AdmissionPolicy: admission.PolicyWeighted,
+		AdmissionClients: AdmissionClientsConfig{
+			AuthAttribute: "subject",
+			Weights:       map[string]int{"tenant-a": 4},
+		},
+	}).Validate())
+	require.ErrorContains(t, (&AdmissionConfig{AdmissionPolicy: "random"}).Validate(), "admission_policy: unknown admission policy")
+	require.ErrorContains(t, (&AdmissionConfig{AdmissionPolicy: admission.PolicyWeighted}).Validate(), "admission_clients: set exactly one")
+	require.ErrorContains(t, (&AdmissionConfig{
+		AdmissionPolicy: admission.PolicyWeighted,
+		AdmissionClients: AdmissionClientsConfig{
+			MetadataKey: "x-tenant",
+			Weights:     map[string]int{"tenant-a": 0},
+		},
+	}).Validate(), `weight of "tenant-a" must be >= 1`)
+}
+
+func TestPayloadZstdConfigValidate(t *testing.T) {
+	for _, ok := range []uint32{0, 1, 8, 512} {
+		require.NoError(t, (&PayloadZstdConfig{WindowSizeMiB: ok}).Validate())
+	}
+	for _, bad := range []uint32{3, 1024} {
+		require.ErrorContains(t, (&PayloadZstdConfig{WindowSizeMiB: bad}).Validate(), "window size must be")
+		require.ErrorContains(t, (&CompressionConfig{PayloadZstd: PayloadZstdConfig{WindowSizeMiB: bad}}).Validate(), "window size must be")
+	}
+	for _, ok := range []int{0, 1, 3, 22} {
+		require.NoError(t, (&PayloadZstdConfig{Level: ok}).Validate())
+	}
+	for _, bad := range []int{-1, 23} {
+		require.ErrorContains(t, (&PayloadZstdConfig{Level: bad}).Validate(), "payload_zstd::level: level must be between 1 and 22")
+	}
+}
+
+func TestDowngradeConfig(t *testing.T) {
+	require.Equal(t, DowngradePermanent, (&DowngradeConfig{}).DowngradePolicy())
+	require.Equal(t, DowngradeNever, (&DowngradeConfig{DisableDowngrade: true}).DowngradePolicy())
+
+	retry := DowngradePolicyConfig{Policy: DowngradeRetryInterval, RetryInterval: time.Minute}
+	require.Equal(t, DowngradeRetryInterval, (&DowngradeConfig{Downgrade: retry}).DowngradePolicy())
+
+	require.NoError(t, (&DowngradeConfig{}).Validate())
+	require.NoError(t, (&DowngradeConfig{Downgrade: retry}).Validate())
+	require.NoError(t, (&DowngradeConfig{DisableDowngrade: true, Downgrade: DowngradePolicyConfig{Policy: DowngradeNever}}).Validate())
+
+	require.ErrorContains(t, (&DowngradeConfig{Downgrade: DowngradePolicyConfig{Policy: "sometimes"}}).Validate(), "downgrade::policy: unknown downgrade policy")
+	require.ErrorContains(t, (&DowngradeConfig{Downgrade: DowngradePolicyConfig{Policy: DowngradeRetryInterval}}).Validate(), "downgrade::retry_interval: retry interval must be > 0")
+	require.ErrorContains(t, (&DowngradeConfig{DisableDowngrade: true, Downgrade: retry}).Validate(), "disable_downgrade cannot be combined")
+}
+
+func TestValidateAllErrors(t *testing.T) {
+	// Every invalid field is reported, qualified by its key.
+	err := (&StreamConfig{NumStreams: 0, MaxStreamLifetime: time.Millisecond}).Validate()
+	require.Len(t, multierr.Errors(err), 2)
+	require.ErrorContains(t, err, "num_streams: ")
+	require.ErrorContains(t, err, "max_stream_lifetime: ")
+
+	err = (&CompressionConfig{
+		PayloadCompression: configcompression.TypeGzip,
+		PayloadZstd:        PayloadZstdConfig{WindowSizeMiB: 3},
+	}).Validate()
+	require.Len(t, multierr.Errors(err), 2)
+	require.ErrorContains(t, err, "payload_compression: ")
+	require.ErrorContains(t, err, "payload_zstd::window_size_mib: ")
+
+	err = (&AdmissionConfig{MemoryLimitMiB: math.MaxUint64, AdmissionLimitMiB: math.MaxUint64, WaiterLimit: -1}).Validate()
+	require.Len(t, multierr.Errors(err), 3)
+}
@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package arrowprobe verifies that an OTel-Arrow receiver is
+// reachable and accepts the caller's credentials, without sending
+// telemetry.  A probe opens an Arrow stream and sends one batch
+// without payloads, which receivers answer without consuming
+// anything, after authenticating its headers, the same as an
+// exporter's heartbeat.  The status of the batch is the result of the
+// probe.
+//
+// Exporters configured with `self_test` probe their receiver at
+// startup, and external monitoring can call Probe with its own
+// connection.
+package arrowprobe // import "github.com/open-telemetry/otel-arrow/collector/arrowprobe"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"go.opentelemetry.io/collector/component"
+	"golang.org/x/net/http2/hpack"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// BatchID is the ID of the probe batch.  Producers do not assign
+// negative IDs.
+const BatchID = -1
+
+// Stream is the client end of an Arrow stream of any signal.
+type Stream interface {
+	Send(*arrowpb.BatchArrowRecords) error
+	Recv() (*arrowpb.BatchStatus, error)
+	CloseSend() error
+}
+
+// Probe opens a stream of the Arrow service of signal on conn and
+// runs a probe on it, see Run.
+func Probe(ctx context.Context, conn grpc.ClientConnInterface, signal component.DataType, headers metadata.MD, opts ...grpc.CallOption) error {
+	var (
+		stream Stream
+		err    error
+	)
+	switch signal {
+	case component.DataTypeTraces:
+		stream, err = arrowpb.NewArrowTracesServiceClient(conn).ArrowTraces(ctx, opts...)
+	case component.DataTypeMetrics:
+		stream, err = arrowpb.NewArrowMetricsServiceClient(conn).ArrowMetrics(ctx, opts...)
+	case component.DataTypeLogs:
+		stream, err = arrowpb.NewArrowLogsServiceClient(conn).ArrowLogs(ctx, opts...)
+	default:
+		return fmt.Errorf("arrow probe: unsupported signal %q", signal)
+	}
+	if err != nil {
+		return err
+	}
+	return Run(stream, headers)
+}
+
+// Run sends the probe batch on stream, with headers, e.g., per-RPC
+// credentials, as the batch's headers, and returns nil when the
+// receiver answers OK.  A batch status other than OK is returned as a
+// gRPC status error of the same code, and the errors of the stream
+// are returned as they are.  The stream is closed afterward.
+func Run(stream Stream, headers metadata.MD) error {
+	batch := &arrowpb.BatchArrowRecords{
+		BatchId: BatchID,
+	}
+	if len(headers) != 0 {
+		var buf bytes.Buffer
+		enc := hpack.NewEncoder(&buf)
+		for key, values := range headers {
+			for _, value := range values {
+				if err := enc.WriteField(hpack.HeaderField{
+					Name:  strings.ToLower(key),
+					Value: value,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		batch.Headers = buf.Bytes()
+	}
+	// The stream's status follows a failed send, see grpc.ClientStream.
+	if err := stream.Send(batch); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	resp, err := stream.Recv()
+	_ = stream.CloseSend()
+	if err != nil {
+		return err
+	}
+	if resp.GetBatchId() != BatchID {
+		return status.Errorf(codes.Internal, "arrow probe: unexpected batch ID %d", resp.GetBatchId())
+	}
+	if resp.GetStatusCode() != arrowpb.StatusCode_OK {
+		return status.Error(codes.Code(resp.GetStatusCode()), resp.GetStatusMessage())
+	}
+	return nil
+}
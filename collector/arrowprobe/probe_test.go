@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrowprobe
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"golang.org/x/net/http2/hpack"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStream answers every batch with resp, or fails with err.
+type fakeStream struct {
+	sent    []*arrowpb.BatchArrowRecords
+	sendErr error
+	resp    *arrowpb.BatchStatus
+	err     error
+	closed  bool
+}
+
+func (f *fakeStream) Send(batch *arrowpb.BatchArrowRecords) error {
+	f.sent = append(f.sent, batch)
+	return f.sendErr
+}
+
+func (f *fakeStream) Recv() (*arrowpb.BatchStatus, error) {
+	return f.resp, f.err
+}
+
+func (f *fakeStream) CloseSend() error {
+	f.closed = true
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	stream := &fakeStream{resp: &arrowpb.BatchStatus{BatchId: BatchID}}
+	require.NoError(t, Run(stream, metadata.Pairs("Authorization", "Bearer x")))
+	require.True(t, stream.closed)
+
+	// One batch without payloads, whose headers carry the
+	// credentials.
+	require.Len(t, stream.sent, 1)
+	require.Equal(t, int64(BatchID), stream.sent[0].BatchId)
+	require.Empty(t, stream.sent[0].ArrowPayloads)
+	fields, err := hpack.NewDecoder(4096, nil).DecodeFull(stream.sent[0].Headers)
+	require.NoError(t, err)
+	require.Equal(t, []hpack.HeaderField{{Name: "authorization", Value: "Bearer x"}}, fields)
+
+	// Without headers, the batch has none.
+	stream = &fakeStream{resp: &arrowpb.BatchStatus{BatchId: BatchID}}
+	require.NoError(t, Run(stream, nil))
+	require.Nil(t, stream.sent[0].Headers)
+}
+
+func TestRunErrors(t *testing.T) {
+	// A rejected probe has the code of its status.
+	err := Run(&fakeStream{resp: &arrowpb.BatchStatus{
+		BatchId:       BatchID,
+		StatusCode:    arrowpb.StatusCode_UNAUTHENTICATED,
+		StatusMessage: "no credentials",
+	}}, nil)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.Contains(t, err.Error(), "no credentials")
+
+	// The status of a stream that fails is returned, also after
+	// the send fails.
+	err = Run(&fakeStream{sendErr: io.EOF, err: status.Error(codes.Unimplemented, "unknown service")}, nil)
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	err = Run(&fakeStream{resp: &arrowpb.BatchStatus{BatchId: 7}}, nil)
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.Contains(t, err.Error(), "unexpected batch ID 7")
+}
+
+func TestProbeUnsupportedSignal(t *testing.T) {
+	err := Probe(context.Background(), nil, component.MustNewType("profiles"), nil)
+	require.ErrorContains(t, err, `unsupported signal "profiles"`)
+}
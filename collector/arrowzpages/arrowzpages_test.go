@@ -0,0 +1,291 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrowzpages
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+)
+
+func findState(name string) (InstanceState, bool) {
+	for _, st := range Snapshot() {
+		if st.Name == name {
+			return st, true
+		}
+	}
+	return InstanceState{}, false
+}
+
+func TestInstanceStreams(t *testing.T) {
+	inst := Register(component.KindExporter, "otelarrow/streams")
+	defer inst.Unregister()
+
+	inst.StreamStarted("b", "/opentelemetry.proto.experimental.arrow.v1.ArrowTracesService/ArrowTraces")
+	inst.StreamStarted("a", "/opentelemetry.proto.experimental.arrow.v1.ArrowTracesService/ArrowTraces")
+	inst.StreamEnded("b", fmt.Errorf("stream reset"))
+	inst.SetDowngraded(true)
+
+	st, ok := findState("otelarrow/streams")
+	require.True(t, ok)
+	assert.Equal(t, component.KindExporter, st.Kind)
+	assert.Equal(t, uint64(2), st.Opened)
+	assert.True(t, st.Downgraded)
+	require.Len(t, st.Streams, 1)
+	assert.Equal(t, "a", st.Streams[0].ID)
+	require.Len(t, st.RecentErrors, 1)
+	assert.Equal(t, "b", st.RecentErrors[0].StreamID)
+	assert.Equal(t, "stream reset", st.RecentErrors[0].Message)
+
+	inst.Unregister()
+	_, ok = findState("otelarrow/streams")
+	assert.False(t, ok)
+}
+
+func TestInstanceRecentErrors(t *testing.T) {
+	inst := Register(component.KindReceiver, "otelarrow/errors")
+	defer inst.Unregister()
+
+	inst.RecordError("x", nil)
+	for i := 0; i < maxRecentErrors+5; i++ {
+		inst.RecordError("x", fmt.Errorf("error %d", i))
+	}
+
+	st, ok := findState("otelarrow/errors")
+	require.True(t, ok)
+	require.Len(t, st.RecentErrors, maxRecentErrors)
+	assert.Equal(t, "error 5", st.RecentErrors[0].Message)
+	assert.Equal(t, fmt.Sprint("error ", maxRecentErrors+4), st.RecentErrors[maxRecentErrors-1].Message)
+}
+
+func TestNilInstance(t *testing.T) {
+	var inst *Instance
+	inst.StreamStarted("a", "m")
+	inst.StreamEnded("a", fmt.Errorf("error"))
+	inst.RecordError("a", fmt.Errorf("error"))
+	inst.SetDowngraded(true)
+	inst.SetStreamsFunc(nil)
+	inst.Unregister()
+}
+
+func TestHandler(t *testing.T) {
+	inst := Register(component.KindReceiver, "otelarrow/handler")
+	defer inst.Unregister()
+
+	inst.StreamStarted("stream-7", "ArrowLogs")
+	inst.RecordError("stream-7", fmt.Errorf("<bad> batch"))
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+
+	body, err := io.ReadAll(rec.Result().Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, string(body), "otelarrow/handler")
+	assert.Contains(t, string(body), "stream-7")
+	assert.Contains(t, string(body), "ArrowLogs")
+	assert.Contains(t, string(body), "&lt;bad&gt; batch")
+}
+
+func TestExtensionLifecycle(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = "localhost:0"
+	require.NoError(t, component.ValidateConfig(cfg))
+
+	ext, err := factory.CreateExtension(context.Background(), extensiontest.NewNopCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
+
+func TestExtensionStreamAdjustment(t *testing.T) {
+	inst := Register(component.KindExporter, "otelarrow/ext")
+	defer inst.Unregister()
+	var adjusted bool
+	inst.SetStreamsFunc(func(int, time.Duration) error {
+		adjusted = true
+		return nil
+	})
+
+	// The pages are read-only unless adjustment is allowed.
+	for _, allow := range []bool{false, true} {
+		factory := NewFactory()
+		cfg := factory.CreateDefaultConfig().(*Config)
+		cfg.Endpoint = testutil.GetAvailableLocalAddress(t)
+		cfg.AllowStreamAdjustment = allow
+		ext, err := factory.CreateExtension(context.Background(), extensiontest.NewNopCreateSettings(), cfg)
+		require.NoError(t, err)
+		require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+
+		resp, err := http.PostForm("http://"+cfg.Endpoint+StreamsPath, url.Values{
+			"name":                {"otelarrow/ext"},
+			"num_streams":         {"2"},
+			"max_stream_lifetime": {"1m"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		resp, err = http.Get("http://" + cfg.Endpoint + Path)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.NoError(t, ext.Shutdown(context.Background()))
+
+		assert.Equal(t, allow, adjusted)
+		assert.Equal(t, allow, strings.Contains(string(body), "Streams adjustable"))
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.Error(t, component.ValidateConfig(&Config{}))
+}
+
+func postStreams(values url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, StreamsPath, strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	StreamsHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStreamsHandler(t *testing.T) {
+	inst := Register(component.KindExporter, "otelarrow/adjust")
+	defer inst.Unregister()
+
+	var numStreams int
+	var maxStreamLifetime time.Duration
+	inst.SetStreamsFunc(func(n int, life time.Duration) error {
+		if n > 10 {
+			return fmt.Errorf("too many streams: %d", n)
+		}
+		numStreams, maxStreamLifetime = n, life
+		return nil
+	})
+	st, ok := findState("otelarrow/adjust")
+	require.True(t, ok)
+	assert.True(t, st.Adjustable)
+
+	rec := postStreams(url.Values{
+		"name":                {"otelarrow/adjust"},
+		"num_streams":         {"4"},
+		"max_stream_lifetime": {"10m"},
+	})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, 4, numStreams)
+	assert.Equal(t, 10*time.Minute, maxStreamLifetime)
+
+	rec = postStreams(url.Values{
+		"name":                {"otelarrow/adjust"},
+		"num_streams":         {"11"},
+		"max_stream_lifetime": {"10m"},
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "too many streams: 11")
+
+	rec = postStreams(url.Values{
+		"name":                {"otelarrow/adjust"},
+		"num_streams":         {"4"},
+		"max_stream_lifetime": {"forever"},
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "max_stream_lifetime")
+
+	rec = postStreams(url.Values{
+		"name":                {"otelarrow/other"},
+		"num_streams":         {"4"},
+		"max_stream_lifetime": {"10m"},
+	})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	StreamsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, StreamsPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+type testConfig struct {
+	NumStreams int           `mapstructure:"num_streams"`
+	Lifetime   time.Duration `mapstructure:"max_stream_lifetime"`
+	Nested     struct {
+		Level int `mapstructure:"level"`
+	} `mapstructure:"zstd"`
+}
+
+func TestConfigHandler(t *testing.T) {
+	inst := Register(component.KindExporter, "otelarrow/config")
+	defer inst.Unregister()
+
+	cfg := testConfig{NumStreams: 2, Lifetime: time.Minute}
+	cfg.Nested.Level = 5
+	inst.SetConfig(cfg)
+	inst.StreamStarted("s1", "ArrowTraces")
+	inst.SetStreamParam("s1", "compression", "zstd")
+	inst.SetStreamParam("s2", "compression", "zstd")
+
+	batch := func(schemas ...string) *arrowpb.BatchArrowRecords {
+		b := &arrowpb.BatchArrowRecords{}
+		for i, id := range schemas {
+			b.ArrowPayloads = append(b.ArrowPayloads, &arrowpb.ArrowPayload{
+				Type:     arrowpb.ArrowPayloadType(i + 1),
+				SchemaId: id,
+			})
+		}
+		return b
+	}
+	sv := inst.NewSchemaVersion("s1")
+	sv.Observe(batch("a", "b"))
+	sv.Observe(batch("a", "b"))
+	sv.Observe(batch("a", "c"))
+
+	rec := httptest.NewRecorder()
+	ConfigHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ConfigPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var reports []ConfigReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reports))
+	var found bool
+	for _, rep := range reports {
+		if rep.Name != "otelarrow/config" {
+			continue
+		}
+		found = true
+		assert.Equal(t, "Exporter", rep.Kind)
+		assert.Equal(t, map[string]any{
+			"num_streams":         float64(2),
+			"max_stream_lifetime": "1m0s",
+			"zstd":                map[string]any{"level": float64(5)},
+		}, rep.Config)
+		assert.Equal(t, []StreamReport{{
+			ID:     "s1",
+			Method: "ArrowTraces",
+			Params: map[string]string{
+				"compression":      "zstd",
+				SchemaVersionParam: "2",
+			},
+		}}, rep.Streams)
+	}
+	assert.True(t, found)
+}
+
+func TestNilSchemaVersion(t *testing.T) {
+	var inst *Instance
+	inst.SetConfig(testConfig{})
+	inst.SetStreamParam("a", "k", "v")
+	inst.NewSchemaVersion("a").Observe(&arrowpb.BatchArrowRecords{})
+}
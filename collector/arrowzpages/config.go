@@ -0,0 +1,33 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrowzpages // import "github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// Config configures the Arrow zPages extension.
+type Config struct {
+	// ServerConfig is the HTTP server of the pages, with its
+	// endpoint, TLS and authentication settings.
+	confighttp.ServerConfig `mapstructure:",squash"`
+
+	// AllowStreamAdjustment serves StreamsHandler at StreamsPath,
+	// which changes the streams of exporters.  By default the
+	// pages are read-only.
+	AllowStreamAdjustment bool `mapstructure:"allow_stream_adjustment"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks that an endpoint is set.
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("arrowzpages: endpoint must be set")
+	}
+	return nil
+}
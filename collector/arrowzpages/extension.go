@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrowzpages // import "github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+type zpagesExtension struct {
+	config    *Config
+	telemetry component.TelemetrySettings
+	server    *http.Server
+	stopped   chan struct{}
+}
+
+func newExtension(cfg *Config, telemetry component.TelemetrySettings) *zpagesExtension {
+	return &zpagesExtension{
+		config:    cfg,
+		telemetry: telemetry,
+	}
+}
+
+// Start listens on the configured endpoint and serves the Arrow
+// stream page at Path and ConfigHandler at ConfigPath, and with
+// AllowStreamAdjustment, StreamsHandler at StreamsPath.
+func (z *zpagesExtension) Start(ctx context.Context, host component.Host) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, pageHandler(z.config.AllowStreamAdjustment))
+	mux.Handle(ConfigPath, ConfigHandler())
+	if z.config.AllowStreamAdjustment {
+		mux.Handle(StreamsPath, StreamsHandler())
+	}
+	var err error
+	z.server, err = z.config.ToServerContext(ctx, host, z.telemetry, mux)
+	if err != nil {
+		return err
+	}
+	ln, err := z.config.ToListenerContext(ctx)
+	if err != nil {
+		return err
+	}
+	z.stopped = make(chan struct{})
+
+	z.telemetry.Logger.Info("Starting Arrow zPages", zap.String("endpoint", ln.Addr().String()), zap.String("path", Path))
+	go func() {
+		defer close(z.stopped)
+		if err := z.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			z.telemetry.ReportStatus(component.NewFatalErrorEvent(err))
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops the server.
+func (z *zpagesExtension) Shutdown(ctx context.Context) error {
+	if z.server == nil {
+		return nil
+	}
+	err := z.server.Shutdown(ctx)
+	select {
+	case <-z.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package arrowzpages records the state of OTel-Arrow exporter and
+// receiver streams and implements a collector extension that renders
+// it as a zPage, including open streams, recent errors, and whether
+// an exporter has downgraded to standard OTLP.
+//
+// The collector's zpages extension cannot serve pages of other
+// components, since neither it nor component.Host exposes its mux,
+// so the extension runs its own server.
+package arrowzpages // import "github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/extension"
+)
+
+const defaultEndpoint = "localhost:55680"
+
+var componentType = component.MustNewType("arrowzpages")
+
+// NewFactory returns a factory for the Arrow zPages extension.
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		componentType,
+		createDefaultConfig,
+		createExtension,
+		component.StabilityLevelDevelopment,
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		ServerConfig: confighttp.ServerConfig{
+			Endpoint: defaultEndpoint,
+		},
+	}
+}
+
+func createExtension(_ context.Context, set extension.CreateSettings, cfg component.Config) (extension.Extension, error) {
+	return newExtension(cfg.(*Config), set.TelemetrySettings), nil
+}
@@ -0,0 +1,215 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrowzpages // import "github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/multierr"
+)
+
+// Path is where the extension serves the Arrow stream page.
+const Path = "/debug/arrowz"
+
+// StreamsPath is where the extension accepts requests to adjust the
+// streams of an exporter.
+const StreamsPath = Path + "/streams"
+
+// ConfigPath is where the extension serves the effective
+// configuration of every instance and the parameters of its streams,
+// as JSON.
+const ConfigPath = Path + "/config"
+
+var pageTemplate = template.Must(template.New("arrowz").Funcs(template.FuncMap{
+	"since": func(t time.Time) string {
+		return time.Since(t).Truncate(time.Second).String()
+	},
+	"ts": func(t time.Time) string {
+		return t.Format(time.RFC3339)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><title>OTel-Arrow streams</title></head>
+<body>
+<h1>OTel-Arrow streams</h1>
+{{- range .}}
+<h2>{{.Kind}} {{.Name}}</h2>
+<p>Up {{since .Started}}; {{.Opened}} streams opened; {{len .Streams}} open.
+{{- if .Downgraded}} <b>Downgraded to standard OTLP.</b>{{end}}
+{{- if .Adjustable}} Streams adjustable at <code>` + StreamsPath + `</code>.{{end}}</p>
+{{- if .Streams}}
+<table border="1">
+<tr><th>Stream</th><th>Method</th><th>Age</th><th>Parameters</th></tr>
+{{- range .Streams}}
+<tr><td>{{.ID}}</td><td>{{.Method}}</td><td>{{since .Started}}</td><td>{{range $k, $v := .Params}}{{$k}}={{$v}} {{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .RecentErrors}}
+<h3>Recent errors</h3>
+<table border="1">
+<tr><th>Time</th><th>Stream</th><th>Error</th></tr>
+{{- range .RecentErrors}}
+<tr><td>{{ts .Time}}</td><td>{{.StreamID}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- else}}
+<p>No OTel-Arrow components are registered.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// Handler returns an http.Handler rendering the state of every
+// registered instance.
+func Handler() http.Handler {
+	return pageHandler(true)
+}
+
+// pageHandler renders the page, which mentions StreamsPath for the
+// adjustable instances only when it is served.
+func pageHandler(adjustable bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		states := Snapshot()
+		for i := range states {
+			states[i].Adjustable = states[i].Adjustable && adjustable
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := pageTemplate.Execute(w, states); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// StreamsHandler returns an http.Handler adjusting the streams of the
+// registered instances named by the "name" form value.  A POST
+// request sets their number to "num_streams" and their maximum
+// lifetime to "max_stream_lifetime", a Go duration, e.g.:
+//
+//	curl -d name=otelarrow -d num_streams=4 -d max_stream_lifetime=10m \
+//	    http://localhost:55680/debug/arrowz/streams
+func StreamsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		name := r.FormValue("name")
+		numStreams, err := strconv.Atoi(r.FormValue("num_streams"))
+		if err != nil {
+			http.Error(w, fmt.Sprint("num_streams: ", err), http.StatusBadRequest)
+			return
+		}
+		maxStreamLifetime, err := time.ParseDuration(r.FormValue("max_stream_lifetime"))
+		if err != nil {
+			http.Error(w, fmt.Sprint("max_stream_lifetime: ", err), http.StatusBadRequest)
+			return
+		}
+
+		var found bool
+		var errs error
+		for _, inst := range instances() {
+			fn := inst.streamsFunc()
+			if inst.name != name || fn == nil {
+				continue
+			}
+			found = true
+			if err := fn(numStreams, maxStreamLifetime); err != nil {
+				errs = multierr.Append(errs, err)
+			}
+		}
+		switch {
+		case !found:
+			http.Error(w, fmt.Sprintf("no adjustable instance named %q", name), http.StatusNotFound)
+		case errs != nil:
+			http.Error(w, errs.Error(), http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
+
+// ConfigReport is the JSON document served at ConfigPath for each
+// instance.
+type ConfigReport struct {
+	Kind    string         `json:"kind"`
+	Name    string         `json:"name"`
+	Config  map[string]any `json:"config,omitempty"`
+	Streams []StreamReport `json:"streams"`
+}
+
+// StreamReport describes an open stream in a ConfigReport.
+type StreamReport struct {
+	ID     string            `json:"id"`
+	Method string            `json:"method"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// ConfigHandler returns an http.Handler reporting the effective
+// configuration of every registered instance and the parameters of
+// its open streams, as JSON.
+func ConfigHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		reports := []ConfigReport{}
+		for _, st := range Snapshot() {
+			cfg, err := configMap(st.Config)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("%s %s: %v", st.Kind, st.Name, err), http.StatusInternalServerError)
+				return
+			}
+			rep := ConfigReport{
+				Kind:    st.Kind.String(),
+				Name:    st.Name,
+				Config:  cfg,
+				Streams: []StreamReport{},
+			}
+			for _, s := range st.Streams {
+				rep.Streams = append(rep.Streams, StreamReport{
+					ID:     s.ID,
+					Method: s.Method,
+					Params: s.Params,
+				})
+			}
+			reports = append(reports, rep)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(reports)
+	})
+}
+
+// configMap converts a configuration to a map using the keys of its
+// mapstructure tags, with durations in their string form.
+func configMap(cfg any) (map[string]any, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	conf := confmap.New()
+	if err := conf.Marshal(cfg); err != nil {
+		return nil, err
+	}
+	m := conf.ToStringMap()
+	formatDurations(m)
+	return m, nil
+}
+
+func formatDurations(m map[string]any) {
+	for k, v := range m {
+		switch v := v.(type) {
+		case time.Duration:
+			m[k] = v.String()
+		case map[string]any:
+			formatDurations(v)
+		}
+	}
+}
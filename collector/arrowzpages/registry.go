@@ -0,0 +1,285 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrowzpages // import "github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// maxRecentErrors is the number of errors retained per instance.
+const maxRecentErrors = 10
+
+// registry holds every registered instance in the process.
+var registry struct {
+	lock      sync.Mutex
+	nextID    uint64
+	instances map[uint64]*Instance
+}
+
+// Instance records the Arrow stream state of one exporter or receiver.
+// All methods are safe to call on a nil *Instance, which records
+// nothing.
+type Instance struct {
+	key  uint64
+	kind component.Kind
+	name string
+
+	lock       sync.Mutex
+	started    time.Time
+	streams    map[string]StreamState
+	opened     uint64
+	errors     []ErrorEntry
+	downgraded bool
+	setStreams StreamsFunc
+	config     any
+}
+
+// StreamsFunc changes the number of streams of an exporter and their
+// maximum lifetime while it runs.
+type StreamsFunc func(numStreams int, maxStreamLifetime time.Duration) error
+
+// StreamState describes one open stream.
+type StreamState struct {
+	ID      string
+	Method  string
+	Started time.Time
+
+	// Params are the parameters of the stream, e.g., its
+	// compression, see SetStreamParam.
+	Params map[string]string
+}
+
+// ErrorEntry is a recently observed stream error.
+type ErrorEntry struct {
+	Time     time.Time
+	StreamID string
+	Message  string
+}
+
+// InstanceState is a point-in-time copy of an Instance.
+type InstanceState struct {
+	Kind         component.Kind
+	Name         string
+	Started      time.Time
+	Streams      []StreamState
+	Opened       uint64
+	Downgraded   bool
+	Adjustable   bool
+	RecentErrors []ErrorEntry
+
+	// Config is the effective configuration of the component, see
+	// SetConfig.
+	Config any
+}
+
+// Register adds an instance to the process-wide registry.  The name
+// identifies the component, e.g., its component.ID, and need not be
+// unique.  Call Unregister when the component shuts down.
+func Register(kind component.Kind, name string) *Instance {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	if registry.instances == nil {
+		registry.instances = map[uint64]*Instance{}
+	}
+	registry.nextID++
+	inst := &Instance{
+		key:     registry.nextID,
+		kind:    kind,
+		name:    name,
+		started: time.Now(),
+		streams: map[string]StreamState{},
+	}
+	registry.instances[inst.key] = inst
+	return inst
+}
+
+// Unregister removes the instance from the registry.
+func (i *Instance) Unregister() {
+	if i == nil {
+		return
+	}
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	delete(registry.instances, i.key)
+}
+
+// StreamStarted records a new stream.
+func (i *Instance) StreamStarted(streamID, method string) {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.opened++
+	i.streams[streamID] = StreamState{
+		ID:      streamID,
+		Method:  method,
+		Started: time.Now(),
+	}
+}
+
+// StreamEnded records the end of a stream, with its error if any.
+func (i *Instance) StreamEnded(streamID string, err error) {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	delete(i.streams, streamID)
+	if err != nil {
+		i.addErrorLocked(streamID, err)
+	}
+}
+
+// RecordError records an error not associated with the end of a
+// stream.
+func (i *Instance) RecordError(streamID string, err error) {
+	if i == nil || err == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.addErrorLocked(streamID, err)
+}
+
+func (i *Instance) addErrorLocked(streamID string, err error) {
+	if len(i.errors) == maxRecentErrors {
+		copy(i.errors, i.errors[1:])
+		i.errors = i.errors[:maxRecentErrors-1]
+	}
+	i.errors = append(i.errors, ErrorEntry{
+		Time:     time.Now(),
+		StreamID: streamID,
+		Message:  err.Error(),
+	})
+}
+
+// SetDowngraded records whether the component has stopped using
+// Arrow in favor of standard OTLP.
+func (i *Instance) SetDowngraded(downgraded bool) {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.downgraded = downgraded
+}
+
+// SetConfig records the effective configuration of the component,
+// after defaults are resolved.  It is reported at ConfigPath with the
+// keys of its mapstructure tags, so it should only contain settings
+// that are safe to show, e.g., no credentials.
+func (i *Instance) SetConfig(cfg any) {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.config = cfg
+}
+
+// SetStreamParam records a parameter of an open stream, e.g., its
+// compression or its schema version.
+func (i *Instance) SetStreamParam(streamID, key, value string) {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	st, ok := i.streams[streamID]
+	if !ok {
+		return
+	}
+	if st.Params == nil {
+		st.Params = map[string]string{}
+		i.streams[streamID] = st
+	}
+	st.Params[key] = value
+}
+
+// SetStreamsFunc lets the streams of the instance be adjusted through
+// StreamsHandler.
+func (i *Instance) SetStreamsFunc(fn StreamsFunc) {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.setStreams = fn
+}
+
+// streamsFunc returns the function set by SetStreamsFunc, if any.
+func (i *Instance) streamsFunc() StreamsFunc {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.setStreams
+}
+
+func (i *Instance) state() InstanceState {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	st := InstanceState{
+		Kind:         i.kind,
+		Name:         i.name,
+		Started:      i.started,
+		Opened:       i.opened,
+		Downgraded:   i.downgraded,
+		Adjustable:   i.setStreams != nil,
+		RecentErrors: append([]ErrorEntry(nil), i.errors...),
+		Config:       i.config,
+	}
+	for _, s := range i.streams {
+		if s.Params != nil {
+			params := make(map[string]string, len(s.Params))
+			for k, v := range s.Params {
+				params[k] = v
+			}
+			s.Params = params
+		}
+		st.Streams = append(st.Streams, s)
+	}
+	sort.Slice(st.Streams, func(a, b int) bool {
+		return st.Streams[a].ID < st.Streams[b].ID
+	})
+	return st
+}
+
+// Snapshot returns the state of every registered instance, ordered
+// by kind, name, and registration.
+func Snapshot() []InstanceState {
+	insts := instances()
+	states := make([]InstanceState, len(insts))
+	for idx, inst := range insts {
+		states[idx] = inst.state()
+	}
+	return states
+}
+
+// instances returns every registered instance, ordered by kind, name,
+// and registration.
+func instances() []*Instance {
+	registry.lock.Lock()
+	insts := make([]*Instance, 0, len(registry.instances))
+	for _, inst := range registry.instances {
+		insts = append(insts, inst)
+	}
+	registry.lock.Unlock()
+
+	sort.Slice(insts, func(a, b int) bool {
+		if insts[a].kind != insts[b].kind {
+			return insts[a].kind < insts[b].kind
+		}
+		if insts[a].name != insts[b].name {
+			return insts[a].name < insts[b].name
+		}
+		return insts[a].key < insts[b].key
+	})
+	return insts
+}
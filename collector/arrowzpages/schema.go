@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrowzpages // import "github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+
+import (
+	"strconv"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+)
+
+// SchemaVersionParam is the stream parameter counting the Arrow
+// schemas used by the stream, see SchemaVersion.
+const SchemaVersionParam = "schema_version"
+
+// SchemaVersion numbers the schemas of one stream: the version starts
+// at 1 with the first batch and increases with every batch carrying a
+// payload type with a new schema, as when the producer adds a column
+// or a type of payload appears.  Exporters and receivers report the
+// same versions for the same stream.  It is not safe for concurrent use; the methods
+// are safe to call on a nil *SchemaVersion.
+type SchemaVersion struct {
+	inst     *Instance
+	streamID string
+	schemas  map[arrowpb.ArrowPayloadType]string
+	version  int
+}
+
+// NewSchemaVersion returns the schema version of a stream of the
+// instance, nil when the instance is nil.
+func (i *Instance) NewSchemaVersion(streamID string) *SchemaVersion {
+	if i == nil {
+		return nil
+	}
+	return &SchemaVersion{
+		inst:     i,
+		streamID: streamID,
+		schemas:  map[arrowpb.ArrowPayloadType]string{},
+	}
+}
+
+// Observe records the schemas of one batch of the stream.
+func (v *SchemaVersion) Observe(batch *arrowpb.BatchArrowRecords) {
+	if v == nil {
+		return
+	}
+	changed := false
+	for _, payload := range batch.GetArrowPayloads() {
+		if v.schemas[payload.Type] != payload.SchemaId {
+			v.schemas[payload.Type] = payload.SchemaId
+			changed = true
+		}
+	}
+	if changed {
+		v.version++
+		v.inst.SetStreamParam(v.streamID, SchemaVersionParam, strconv.Itoa(v.version))
+	}
+}
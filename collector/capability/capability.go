@@ -0,0 +1,170 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package capability describes the features of OTel-Arrow exporters
+// and receivers, which they exchange when a stream opens so that they
+// negotiate features instead of relying on matching releases.  The
+// exporter declares its Set in the stream's request metadata under
+// Header; the receiver rejects a stream it cannot serve and declares
+// its own Set in the response headers.  A peer that declares nothing
+// predates the exchange, and the stream proceeds as before.
+//
+// A Set is encoded as semicolon-separated key=value pairs, e.g.,
+//
+//	versions=1;compression=none,zstd;dictionary_deltas=true;max_batch_bytes=4194304
+//
+// Keys that are not recognized are ignored, so that later releases
+// can add capabilities.
+package capability // import "github.com/open-telemetry/otel-arrow/collector/capability"
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Header is the gRPC metadata key carrying the capabilities of each
+// end of a stream.
+const Header = "otel-arrow-capabilities"
+
+// Version is the version of the OTel-Arrow stream protocol
+// implemented by this release.
+const Version = 1
+
+// CompressionZstdPayload names Zstd compression of each payload as a
+// whole, as opposed to "zstd" at the Arrow IPC level, which receivers
+// of earlier releases decode.  Exporters use it only with receivers
+// that declare it.
+const CompressionZstdPayload = "zstd_payload"
+
+// ErrIncompatible is returned by Negotiate when the peers have no
+// version or compression in common, or when one uses dictionary
+// deltas that the other does not support.
+var ErrIncompatible = errors.New("incompatible otel-arrow capabilities")
+
+// Set describes the features of one end of a stream.
+type Set struct {
+	// Versions are the supported protocol versions.
+	Versions []int
+
+	// Compression names the payload compression codecs: the
+	// codecs an exporter may use, or the codecs a receiver can
+	// decode.
+	Compression []string
+
+	// DictionaryDeltas is whether dictionary deltas are used by
+	// an exporter, or supported by a receiver.
+	DictionaryDeltas bool
+
+	// MaxBatchBytes is the largest batch that a receiver accepts,
+	// zero for no limit.
+	MaxBatchBytes int64
+}
+
+// HasCompression returns true when the Set names the compression
+// codec.
+func (s Set) HasCompression(name string) bool {
+	return contains(s.Compression, name)
+}
+
+// String encodes the Set for Header.
+func (s Set) String() string {
+	versions := make([]string, len(s.Versions))
+	for i, v := range s.Versions {
+		versions[i] = strconv.Itoa(v)
+	}
+	pairs := []string{
+		"versions=" + strings.Join(versions, ","),
+		"compression=" + strings.Join(s.Compression, ","),
+		"dictionary_deltas=" + strconv.FormatBool(s.DictionaryDeltas),
+	}
+	if s.MaxBatchBytes != 0 {
+		pairs = append(pairs, "max_batch_bytes="+strconv.FormatInt(s.MaxBatchBytes, 10))
+	}
+	return strings.Join(pairs, ";")
+}
+
+// Parse decodes a Set encoded by String.
+func Parse(value string) (s Set, _ error) {
+	for _, pair := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			if key == "" {
+				continue
+			}
+			return Set{}, fmt.Errorf("invalid capability: %q", pair)
+		}
+		var err error
+		switch key {
+		case "versions":
+			for _, v := range list(val) {
+				var n int
+				if n, err = strconv.Atoi(v); err != nil {
+					break
+				}
+				s.Versions = append(s.Versions, n)
+			}
+		case "compression":
+			s.Compression = list(val)
+		case "dictionary_deltas":
+			s.DictionaryDeltas, err = strconv.ParseBool(val)
+		case "max_batch_bytes":
+			s.MaxBatchBytes, err = strconv.ParseInt(val, 10, 64)
+		}
+		if err != nil {
+			return Set{}, fmt.Errorf("invalid capability %s: %w", key, err)
+		}
+	}
+	return s, nil
+}
+
+func list(val string) []string {
+	if val == "" {
+		return nil
+	}
+	return strings.Split(val, ",")
+}
+
+// Negotiate returns the features that an exporter and a receiver
+// have in common: their highest common version, the exporter's
+// codecs that the receiver decodes, dictionary deltas if the exporter
+// uses them, and the receiver's batch size limit.
+func Negotiate(exporter, receiver Set) (Set, error) {
+	var agreed Set
+	for _, v := range exporter.Versions {
+		if contains(receiver.Versions, v) {
+			agreed.Versions = append(agreed.Versions, v)
+		}
+	}
+	if len(agreed.Versions) == 0 {
+		return Set{}, fmt.Errorf("%w: no common version in %v and %v", ErrIncompatible, exporter.Versions, receiver.Versions)
+	}
+	sort.Ints(agreed.Versions)
+	agreed.Versions = agreed.Versions[len(agreed.Versions)-1:]
+
+	for _, c := range exporter.Compression {
+		if contains(receiver.Compression, c) {
+			agreed.Compression = append(agreed.Compression, c)
+		}
+	}
+	if len(agreed.Compression) == 0 {
+		return Set{}, fmt.Errorf("%w: receiver does not decode compression %v", ErrIncompatible, exporter.Compression)
+	}
+	if exporter.DictionaryDeltas && !receiver.DictionaryDeltas {
+		return Set{}, fmt.Errorf("%w: receiver does not support dictionary deltas", ErrIncompatible)
+	}
+	agreed.DictionaryDeltas = exporter.DictionaryDeltas
+	agreed.MaxBatchBytes = receiver.MaxBatchBytes
+	return agreed, nil
+}
+
+func contains[T comparable](list []T, item T) bool {
+	for _, x := range list {
+		if x == item {
+			return true
+		}
+	}
+	return false
+}
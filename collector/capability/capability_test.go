@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package capability
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringParse(t *testing.T) {
+	s := Set{
+		Versions:         []int{1, 2},
+		Compression:      []string{"none", "zstd"},
+		DictionaryDeltas: true,
+		MaxBatchBytes:    4 << 20,
+	}
+	require.Equal(t, "versions=1,2;compression=none,zstd;dictionary_deltas=true;max_batch_bytes=4194304", s.String())
+
+	p, err := Parse(s.String())
+	require.NoError(t, err)
+	require.Equal(t, s, p)
+
+	// Later releases may add capabilities.
+	p, err = Parse("versions=1;compression=zstd;dictionary_deltas=false;future=yes;")
+	require.NoError(t, err)
+	require.Equal(t, Set{Versions: []int{1}, Compression: []string{"zstd"}}, p)
+
+	_, err = Parse("versions=one")
+	require.ErrorContains(t, err, "invalid capability versions")
+	_, err = Parse("versions")
+	require.ErrorContains(t, err, "invalid capability")
+	_, err = Parse("max_batch_bytes=lots")
+	require.ErrorContains(t, err, "invalid capability max_batch_bytes")
+}
+
+func TestNegotiate(t *testing.T) {
+	receiver := Set{
+		Versions:         []int{1, 2},
+		Compression:      []string{"none", "zstd"},
+		DictionaryDeltas: true,
+		MaxBatchBytes:    4 << 20,
+	}
+
+	agreed, err := Negotiate(Set{
+		Versions:         []int{1, 2, 3},
+		Compression:      []string{"zstd", "lz4"},
+		DictionaryDeltas: true,
+	}, receiver)
+	require.NoError(t, err)
+	require.Equal(t, Set{
+		Versions:         []int{2},
+		Compression:      []string{"zstd"},
+		DictionaryDeltas: true,
+		MaxBatchBytes:    4 << 20,
+	}, agreed)
+
+	_, err = Negotiate(Set{Versions: []int{3}, Compression: []string{"zstd"}}, receiver)
+	require.ErrorIs(t, err, ErrIncompatible)
+	require.ErrorContains(t, err, "no common version")
+
+	_, err = Negotiate(Set{Versions: []int{1}, Compression: []string{"lz4"}}, receiver)
+	require.ErrorIs(t, err, ErrIncompatible)
+	require.ErrorContains(t, err, "compression [lz4]")
+
+	// Receivers of earlier releases do not decode whole-payload
+	// compression.
+	require.False(t, receiver.HasCompression(CompressionZstdPayload))
+	_, err = Negotiate(Set{Versions: []int{1}, Compression: []string{CompressionZstdPayload}}, receiver)
+	require.ErrorIs(t, err, ErrIncompatible)
+
+	receiver.DictionaryDeltas = false
+	_, err = Negotiate(Set{Versions: []int{1}, Compression: []string{"zstd"}, DictionaryDeltas: true}, receiver)
+	require.ErrorIs(t, err, ErrIncompatible)
+	require.ErrorContains(t, err, "dictionary deltas")
+}
@@ -8,6 +8,7 @@ require (
 	github.com/open-telemetry/opentelemetry-collector-contrib/extension/basicauthextension v0.98.0
 	github.com/open-telemetry/opentelemetry-collector-contrib/extension/headerssetterextension v0.98.0
 	github.com/open-telemetry/opentelemetry-collector-contrib/extension/pprofextension v0.98.0
+	github.com/open-telemetry/otel-arrow/collector v0.23.0
 	github.com/open-telemetry/otel-arrow/collector/connector/validationconnector v0.23.0
 	github.com/open-telemetry/otel-arrow/collector/exporter/fileexporter v0.23.0
 	github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter v0.23.0
@@ -83,7 +84,6 @@ require (
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/mostynb/go-grpc-compression v1.2.2 // indirect
 	github.com/open-telemetry/otel-arrow v0.23.0 // indirect
-	github.com/open-telemetry/otel-arrow/collector v0.23.0 // indirect
 	github.com/pierrec/lz4/v4 v4.1.18 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/power-devops/perfstat v0.0.0-20210106213030-5aafc221ea8c // indirect
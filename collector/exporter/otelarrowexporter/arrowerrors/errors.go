@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package arrowerrors defines the errors returned by the Arrow
+// streams of the OTel-Arrow exporter, possibly wrapped, so that
+// callers can tell them apart with errors.Is.
+package arrowerrors // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrStreamRestarting is returned to the sender of a batch
+	// that was not sent because its stream ended.  The exporter
+	// retries these batches on another stream.  The Aborted code
+	// makes it retryable by the exporter helper.
+	ErrStreamRestarting = status.Error(codes.Aborted, "stream is restarting")
+
+	// ErrDowngraded is returned when the endpoint does not
+	// support OTel-Arrow, in which case the exporter falls back
+	// to standard OTLP.
+	ErrDowngraded = errors.New("arrow streams downgraded")
+
+	// ErrEncode wraps the errors of the Arrow producer, or of
+	// the encoding of a batch's headers.  These errors are
+	// permanent.
+	ErrEncode = errors.New("encode")
+
+	// ErrAckTimeout wraps the context error when the deadline of
+	// the sender expires after its batch was handed to a stream,
+	// before the status of the batch was received.  It also ends
+	// streams whose watchdog expires, see the ack_timeout setting.
+	ErrAckTimeout = errors.New("timeout waiting for batch status")
+
+	// ErrEstablishTimeout ends streams that are not established in
+	// time, see the establish_timeout setting.
+	ErrEstablishTimeout = errors.New("timeout establishing stream")
+
+	// ErrBatchTooLarge is returned, permanently, for a batch whose
+	// encoding exceeds the size that the receiver declared it
+	// accepts.
+	ErrBatchTooLarge = errors.New("batch too large")
+
+	// ErrHeartbeatTimeout ends a stream whose heartbeat was not
+	// answered within the heartbeat interval.  The Unavailable
+	// code indicates that the peer is presumed unreachable.
+	ErrHeartbeatTimeout = status.Error(codes.Unavailable, "heartbeat not answered")
+)
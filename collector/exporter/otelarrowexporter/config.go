@@ -5,16 +5,27 @@ package otelarrowexporter // import "github.com/open-telemetry/otel-arrow/collec
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/open-telemetry/otel-arrow/collector/arrowconfig"
 	"github.com/open-telemetry/otel-arrow/collector/compression/zstd"
+	"github.com/open-telemetry/otel-arrow/collector/netstats"
 	"github.com/open-telemetry/otel-arrow/pkg/config"
+	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configcompression"
 	"go.opentelemetry.io/collector/config/configgrpc"
+	"go.opentelemetry.io/collector/config/configopaque"
 	"go.opentelemetry.io/collector/config/configretry"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
 
 	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
 )
@@ -35,22 +46,83 @@ type Config struct {
 	// Arrow includes settings specific to OTel Arrow.
 	Arrow ArrowConfig `mapstructure:"arrow"`
 
+	// Sharding divides data among several endpoints, each with
+	// its own connection and Arrow streams.
+	Sharding ShardingConfig `mapstructure:"sharding"`
+
+	// MetadataKeys are the client.Metadata keys whose values are
+	// sent with each request, as request headers for OTLP and as
+	// batch headers for Arrow.  Configured with the same keys as
+	// the `metadata_keys` of a batch processor upstream, the
+	// batches formed for each combination of values reach the
+	// receiver with their metadata, and Arrow batches with
+	// different values are never coalesced.  Entries are
+	// case-insensitive.
+	MetadataKeys []string `mapstructure:"metadata_keys"`
+
+	// ConnectTimeout bounds each attempt to connect to the
+	// endpoint, including the TLS handshake.  Failed attempts are
+	// retried with backoff, whose delay is capped at the same
+	// value.  Zero keeps the gRPC defaults, which allow an attempt
+	// at least 20 seconds, and up to the backoff delay of two
+	// minutes.
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+
+	// ProxyURL is the proxy through which the exporter connects
+	// to the endpoint, with the http scheme for HTTP CONNECT or
+	// socks5 for SOCKS5, and optional user credentials.  When set,
+	// it replaces the HTTPS_PROXY environment variable.  It is
+	// opaque, since the credentials it may carry are secret.
+	ProxyURL configopaque.String `mapstructure:"proxy_url"`
+
+	// SpanSizeAttributes selects the message size attributes set
+	// on the export spans: "off", "compressed", or "both", the
+	// default.
+	SpanSizeAttributes netstats.SpanSizeAttributes `mapstructure:"span_size_attributes"`
+
+	// SizeHistograms records the histograms of message sizes at
+	// the normal level of telemetry, as at the detailed level.
+	SizeHistograms bool `mapstructure:"size_histograms"`
+
 	// UserDialOptions cannot be configured via `mapstructure`
 	// schemes.  This is useful for custom purposes where the
 	// exporter is built and configured via code instead of yaml.
 	// Uses include custom dialer, custom user-agent, etc.
 	UserDialOptions []grpc.DialOption `mapstructure:"-"`
+
+	// StatsHandlers are gRPC stats handlers added to the
+	// connection after the handler of the network statistics,
+	// e.g., to account for the bytes sent to each peer.  Like
+	// UserDialOptions, they are configured via code only.
+	StatsHandlers []stats.Handler `mapstructure:"-"`
 }
 
 // ArrowConfig includes whether Arrow is enabled and the number of
 // concurrent Arrow streams.
 type ArrowConfig struct {
-	// NumStreams determines the number of OTel Arrow streams.
-	NumStreams int `mapstructure:"num_streams"`
+	// StreamConfig sets NumStreams and MaxStreamLifetime.
+	arrowconfig.StreamConfig `mapstructure:",squash"`
+
+	// CompressionConfig sets PayloadCompression.  Note that
+	// `Zstd` applies to gRPC, not Arrow compression.
+	arrowconfig.CompressionConfig `mapstructure:",squash"`
+
+	// DowngradeConfig sets Disabled, DisableDowngrade and the
+	// Downgrade policy.
+	arrowconfig.DowngradeConfig `mapstructure:",squash"`
 
-	// MaxStreamLifetime should be set to less than the value of
-	// grpc: keepalive: max_connection_age_grace plus the timeout.
-	MaxStreamLifetime time.Duration `mapstructure:"max_stream_lifetime"`
+	// Signals are the signals, of "traces", "metrics" and "logs",
+	// sent on Arrow streams.  The others are sent as standard
+	// OTLP.  Empty means every signal.
+	Signals []string `mapstructure:"signals"`
+
+	// SignalCompression replaces CompressionConfig for the
+	// streams of some signals.
+	SignalCompression SignalCompressionConfig `mapstructure:"signal_compression"`
+
+	// StreamsPerSignal replaces NumStreams for the streams of
+	// some signals.
+	StreamsPerSignal StreamsPerSignalConfig `mapstructure:"streams_per_signal"`
 
 	// Zstd settings apply to OTel-Arrow use of gRPC specifically.
 	// Note that when multiple Otel-Arrow exporters are configured
@@ -59,58 +131,556 @@ type ArrowConfig struct {
 	// OTel-Arrow exporters are in use.
 	Zstd zstd.EncoderConfig `mapstructure:"zstd"`
 
-	// PayloadCompression is applied on the Arrow IPC stream
-	// internally and may have different results from using
-	// gRPC-level compression.  This is disabled by default, since
-	// gRPC-level compression is enabled by default.  This can be
-	// set to "zstd" to turn on Arrow-Zstd compression.
-	// Note that `Zstd` applies to gRPC, not Arrow compression.
-	PayloadCompression configcompression.Type `mapstructure:"payload_compression"`
-
-	// Disabled prevents using OTel-Arrow streams.  The exporter
-	// falls back to standard OTLP.
-	Disabled bool `mapstructure:"disabled"`
-
-	// DisableDowngrade prevents this exporter from fallback back
-	// to standard OTLP.  If the Arrow service is unavailable, it
-	// will retry and/or fail.
-	DisableDowngrade bool `mapstructure:"disable_downgrade"`
-
 	// Prioritizer is a policy name for how load is distributed
 	// across streams.
 	Prioritizer arrow.PrioritizerName `mapstructure:"prioritizer"`
+
+	// AutoScale adjusts the number of streams while the exporter
+	// runs, starting from NumStreams.
+	AutoScale AutoScaleConfig `mapstructure:"auto_scale"`
+
+	// ZstdDictionary is the path of a pre-trained Zstd dictionary
+	// (see tools/zstd_dict_train) used to compress each Arrow
+	// payload.  This replaces PayloadCompression, and the
+	// receiver must be configured with the same dictionary.
+	ZstdDictionary string `mapstructure:"zstd_dictionary"`
+
+	// PipelinedEncoding uses a second goroutine per stream, so
+	// that the Arrow records of a batch are built while the
+	// previous batch is compressed and sent.
+	PipelinedEncoding bool `mapstructure:"pipelined_encoding"`
+
+	// PipelinedSend uses another goroutine per stream, so that a
+	// batch is encoded while the previous batch waits for the
+	// transport to accept it.
+	PipelinedSend bool `mapstructure:"pipelined_send"`
+
+	// CoalesceBatches is the maximum number of batches waiting
+	// for a stream that it combines into one Arrow batch, which
+	// compresses better under load.  Zero or one disables
+	// coalescing.
+	CoalesceBatches int `mapstructure:"coalesce_batches"`
+
+	// IdempotencyKeys attaches a key unique to each batch, which
+	// is kept when the batch is retried, so that receivers
+	// configured with a deduplication window recognize retried
+	// batches.
+	IdempotencyKeys bool `mapstructure:"idempotency_keys"`
+
+	// Checksums attaches a checksum of the encoded payloads to
+	// each batch, which receivers verify before decoding it, to
+	// detect batches corrupted between the exporter and the
+	// receiver, e.g., by a proxy.
+	Checksums bool `mapstructure:"checksums"`
+
+	// HeartbeatInterval is the idle time after which a stream
+	// sends a heartbeat, and the time within which the receiver
+	// must answer it, or send any other status, before the stream
+	// restarts, so that dead peers are detected on idle streams.
+	// Zero disables heartbeats.
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"`
+
+	// AckTimeout restarts a stream that has batches awaiting
+	// their status when no status has arrived for this long.
+	// Zero disables the watchdog.
+	AckTimeout time.Duration `mapstructure:"ack_timeout"`
+
+	// EstablishTimeout restarts a stream that does not open,
+	// including the exchange of capabilities, or that does not
+	// receive the status of its first batch, within the timeout.
+	// The stream restarts without downgrading to standard OTLP.
+	// Zero disables the timeout.
+	EstablishTimeout time.Duration `mapstructure:"establish_timeout"`
+
+	// RedactErrors replaces the status messages received from the
+	// receiver, and the errors logged by Arrow streams, with a
+	// digest, because they may contain client metadata or
+	// attribute values.
+	RedactErrors bool `mapstructure:"redact_errors"`
+
+	// MinCompressionRatio is the ratio of uncompressed to
+	// compressed size of a payload below which its stream sends
+	// the following payloads uncompressed for a while, which
+	// saves the CPU spent compressing data that does not
+	// compress.  It applies to whole-payload compression.  Zero
+	// disables the adaptation.
+	MinCompressionRatio float64 `mapstructure:"min_compression_ratio"`
+
+	// MaxChunkItems is the maximum number of spans, log records,
+	// or metric data points converted to Arrow records at once.
+	// Larger batches are encoded in chunks, which bounds the
+	// memory of the encoder.  The receiver must be of the same
+	// release or later.  Zero disables chunking.
+	MaxChunkItems int `mapstructure:"max_chunk_items"`
+
+	// MemoryLimitMiB limits the memory held by the Arrow streams,
+	// counting the uncompressed size of the batches queued or
+	// awaiting acknowledgement and the buffers of the Arrow
+	// encoders.  Senders wait while the limit is exceeded, so it
+	// should be set below the collector's memory limiter.  Zero
+	// disables the limit.
+	MemoryLimitMiB uint64 `mapstructure:"memory_limit_mib"`
+
+	// MemoryPressureMiB is the size of the Go heap above which the
+	// Arrow producers reset their builders, dictionaries, and IPC
+	// streams, releasing the memory they hold at the cost of
+	// larger payloads afterwards.  The heap is sampled every
+	// second.  Zero disables the reset.
+	MemoryPressureMiB uint64 `mapstructure:"memory_pressure_mib"`
+
+	// SchemaCacheSize is the number of Arrow schemas per payload
+	// type whose streams are kept open, so that batches
+	// alternating between a few shapes reuse their schemas and
+	// dictionaries.  The receiver must be of the same release or
+	// later.  Zero or one keeps only the latest schema.
+	SchemaCacheSize int `mapstructure:"schema_cache_size"`
+
+	// TrimAfterBatches is the number of consecutive batches, each
+	// less than half the size of the largest recent batch, after
+	// which a stream releases the memory its encoder retained for
+	// the largest batch.  A trim restarts the stream's Arrow
+	// dictionaries.  Zero disables trimming.
+	TrimAfterBatches int `mapstructure:"trim_after_batches"`
+
+	// SchemaChurnThreshold is the number of Arrow schema changes
+	// of a payload type, within the last 100 batches of a stream,
+	// at which the exporter logs a warning naming the attribute
+	// keys whose value types vary.  Zero disables the detection.
+	SchemaChurnThreshold int `mapstructure:"schema_churn_threshold"`
+
+	// MetadataAllowedKeys, when not empty, are the only keys of
+	// the per-RPC credentials and `metadata_keys` metadata that
+	// are sent as Arrow batch headers, and MetadataDeniedKeys
+	// are never sent.  Keys are case-insensitive.  The headers
+	// of the protocol itself are always sent.
+	MetadataAllowedKeys []string `mapstructure:"metadata_allowed_keys"`
+	MetadataDeniedKeys  []string `mapstructure:"metadata_denied_keys"`
+
+	// InstanceIdentity identifies this collector to the receiver
+	// when each stream opens.
+	InstanceIdentity InstanceIdentityConfig `mapstructure:"instance_identity"`
+
+	// SelfTest probes the receiver at startup, without sending
+	// telemetry, and logs whether it is reachable and accepts the
+	// exporter's credentials.
+	SelfTest bool `mapstructure:"self_test"`
+}
+
+// InstanceIdentityConfig identifies the collector of the exporter to
+// the receiver, which logs the identity of each stream, so that
+// problems of one agent among a large fleet can be traced to it.
+type InstanceIdentityConfig struct {
+	// Enabled sends the identity when each stream opens.
+	Enabled bool `mapstructure:"enabled"`
+
+	// ID is the instance ID, by default the collector's
+	// `service.instance.id`, or its host name when unknown.  Set
+	// it for an ID that is stable across restarts.
+	ID string `mapstructure:"id"`
+
+	// Labels are optional deployment labels sent with the ID,
+	// e.g., the region or the cluster.
+	Labels map[string]string `mapstructure:"labels"`
+}
+
+// AutoScaleConfig configures the adjustment of the number of streams
+// to the exporter's load, so that it need not be tuned for each
+// deployment.  Every interval, a stream is added when the batches
+// waiting for a stream to accept them exceed the target queue depth
+// per stream, or when the mean latency of the streams exceeds the
+// target latency, and one is removed after the streams have kept up
+// for a few intervals.
+type AutoScaleConfig struct {
+	// Enabled adjusts the number of streams.
+	Enabled bool `mapstructure:"enabled"`
+
+	// MinStreams and MaxStreams bound the number of streams.
+	// NumStreams is raised or lowered to within the bounds.
+	// With a sending queue, MaxStreams must not exceed its consumers.
+	MinStreams int `mapstructure:"min_streams"`
+	MaxStreams int `mapstructure:"max_streams"`
+
+	// Interval is the time between adjustments.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// TargetQueueDepth is the number of batches per stream
+	// waiting for a stream to accept them above which a stream is
+	// added.
+	TargetQueueDepth int `mapstructure:"target_queue_depth"`
+
+	// TargetLatency is the mean time for the streams to receive
+	// the status of a batch above which a stream is added.  Zero
+	// ignores the latency.
+	TargetLatency time.Duration `mapstructure:"target_latency"`
+}
+
+// Validate returns every invalid setting of an enabled auto-scaling,
+// qualified by its key.
+func (cfg *AutoScaleConfig) Validate() (errs error) {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.MinStreams < 1 {
+		errs = multierr.Append(errs, fmt.Errorf("min_streams: stream count must be > 0: %d", cfg.MinStreams))
+	}
+	if cfg.MaxStreams < cfg.MinStreams {
+		errs = multierr.Append(errs, fmt.Errorf("max_streams: %d is below min_streams %d", cfg.MaxStreams, cfg.MinStreams))
+	}
+	if cfg.Interval <= 0 {
+		errs = multierr.Append(errs, fmt.Errorf("interval must be positive: %v", cfg.Interval))
+	}
+	if cfg.TargetQueueDepth < 1 {
+		errs = multierr.Append(errs, fmt.Errorf("target_queue_depth must be > 0: %d", cfg.TargetQueueDepth))
+	}
+	if cfg.TargetLatency < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("target_latency must be non-negative: %v", cfg.TargetLatency))
+	}
+	return errs
+}
+
+// SignalCompressionConfig sets the payload compression of the streams
+// of each signal, when not nil, e.g., a higher level for logs, which
+// compress better.
+type SignalCompressionConfig struct {
+	Traces  *arrowconfig.CompressionConfig `mapstructure:"traces"`
+	Metrics *arrowconfig.CompressionConfig `mapstructure:"metrics"`
+	Logs    *arrowconfig.CompressionConfig `mapstructure:"logs"`
+}
+
+// forSignal returns the compression of the signal, nil when not set.
+func (cfg *SignalCompressionConfig) forSignal(signal component.DataType) *arrowconfig.CompressionConfig {
+	switch signal {
+	case component.DataTypeTraces:
+		return cfg.Traces
+	case component.DataTypeMetrics:
+		return cfg.Metrics
+	case component.DataTypeLogs:
+		return cfg.Logs
+	}
+	return nil
+}
+
+// StreamsPerSignalConfig sets the number of streams of each signal,
+// when not zero.  Each signal has its own streams, so that, e.g., a
+// high volume of metrics does not delay traces.
+type StreamsPerSignalConfig struct {
+	Traces  int `mapstructure:"traces"`
+	Metrics int `mapstructure:"metrics"`
+	Logs    int `mapstructure:"logs"`
+}
+
+// forSignal returns the number of streams of the signal, zero when
+// not set.
+func (cfg *StreamsPerSignalConfig) forSignal(signal component.DataType) int {
+	switch signal {
+	case component.DataTypeTraces:
+		return cfg.Traces
+	case component.DataTypeMetrics:
+		return cfg.Metrics
+	case component.DataTypeLogs:
+		return cfg.Logs
+	}
+	return 0
+}
+
+// ShardKey names the property of the data used to choose a shard.
+type ShardKey string
+
+const (
+	// ShardKeyTraceID keeps every span of a trace on one shard, so
+	// that a tail-sampling tier receives complete traces.  Log
+	// records are sharded by their trace ID when one is set.
+	ShardKeyTraceID ShardKey = "trace_id"
+
+	// ShardKeyResource keeps the data of each resource on one
+	// shard, identified by ResourceAttributes, which stabilizes
+	// the Arrow dictionaries of every shard.
+	ShardKeyResource ShardKey = "resource"
+)
+
+// DefaultShardResourceAttributes identify a resource for
+// ShardKeyResource, when not configured.
+var DefaultShardResourceAttributes = []string{"service.name", "k8s.pod.uid"}
+
+// DefaultShardUnhealthyDuration is how long a shard is avoided after
+// a retryable failure, when not configured.
+const DefaultShardUnhealthyDuration = 30 * time.Second
+
+// ShardingConfig configures consistent-hash sharding across several
+// OTel-Arrow endpoints.  When Endpoints is empty, sharding is
+// disabled and the exporter sends to Endpoint.
+type ShardingConfig struct {
+	// Endpoints are the destinations, each of which uses the
+	// exporter's gRPC and Arrow settings with its own
+	// connection and streams.
+	Endpoints []string `mapstructure:"endpoints"`
+
+	// Key determines which items belong together on one shard.
+	// The default is "trace_id".
+	Key ShardKey `mapstructure:"key"`
+
+	// ResourceAttributes identify a resource when Key is
+	// "resource".  The default is DefaultShardResourceAttributes.
+	ResourceAttributes []string `mapstructure:"resource_attributes"`
+
+	// UnhealthyDuration is how long a shard is avoided after it
+	// returns a retryable error.  Data that would go to an
+	// unhealthy shard goes to the next healthy shard on the hash
+	// ring.  Zero means DefaultShardUnhealthyDuration.
+	UnhealthyDuration time.Duration `mapstructure:"unhealthy_duration"`
 }
 
 var _ component.Config = (*Config)(nil)
 
-var _ component.ConfigValidator = (*ArrowConfig)(nil)
+var (
+	_ component.ConfigValidator = (*Config)(nil)
+	_ component.ConfigValidator = (*ArrowConfig)(nil)
+	_ component.ConfigValidator = (*ShardingConfig)(nil)
+)
+
+// Validate returns an error for settings of different sections that
+// conflict: a gRPC compression the exporter cannot use, duplicate
+// metadata keys, a negative connect timeout, or an invalid proxy.
+// The sections validate themselves.
+func (cfg *Config) Validate() (errs error) {
+	switch cfg.ClientConfig.Compression {
+	case "", "none", configcompression.TypeGzip, configcompression.TypeSnappy, configcompression.TypeZstd:
+	default:
+		errs = multierr.Append(errs, fmt.Errorf("compression: unsupported gRPC compression %q, use gzip, snappy, zstd or none", cfg.ClientConfig.Compression))
+	}
+	if cfg.ConnectTimeout < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("connect_timeout must be non-negative: %v", cfg.ConnectTimeout))
+	}
+	if cfg.ProxyURL != "" {
+		if _, err := parseProxyURL(string(cfg.ProxyURL)); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("proxy_url: %w", err))
+		}
+	}
+	if as := cfg.Arrow.AutoScale; as.Enabled && cfg.QueueSettings.Enabled && as.MaxStreams > cfg.QueueSettings.NumConsumers {
+		// Each queue consumer keeps at most one stream busy.
+		errs = multierr.Append(errs, fmt.Errorf("arrow::auto_scale::max_streams: %d exceeds sending_queue::num_consumers %d", as.MaxStreams, cfg.QueueSettings.NumConsumers))
+	}
+	if err := cfg.SpanSizeAttributes.Validate(); err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("span_size_attributes: %w", err))
+	}
+	uniq := map[string]bool{}
+	for _, k := range cfg.MetadataKeys {
+		l := strings.ToLower(k)
+		if uniq[l] {
+			errs = multierr.Append(errs, fmt.Errorf("metadata_keys: duplicate entry %q (case-insensitive)", l))
+		}
+		uniq[l] = true
+	}
+	return errs
+}
+
+// warnIdleStreams logs when the sending queue has fewer consumers
+// than the Arrow streams of the exporter of signal, whose
+// configuration is returned by forSignal.  Each consumer keeps at
+// most one stream busy, so the extra streams stay idle.
+func (cfg *Config) warnIdleStreams(logger *zap.Logger, signal component.DataType) {
+	if cfg.Arrow.Disabled || !cfg.QueueSettings.Enabled || cfg.QueueSettings.NumConsumers >= cfg.Arrow.NumStreams {
+		return
+	}
+	logger.Warn("sending_queue::num_consumers is less than the Arrow streams, the extra streams stay idle; raise num_consumers or lower arrow::num_streams",
+		zap.String("signal", signal.String()),
+		zap.Int("num_consumers", cfg.QueueSettings.NumConsumers),
+		zap.Int("num_streams", cfg.Arrow.NumStreams))
+}
+
+// forSignal returns the configuration of the exporter of one signal,
+// which disables Arrow unless the signal is among Arrow.Signals and
+// applies the signal's compression from Arrow.SignalCompression.
+func (cfg *Config) forSignal(signal component.DataType) *Config {
+	enabled := len(cfg.Arrow.Signals) == 0
+	for _, sig := range cfg.Arrow.Signals {
+		if sig == signal.String() {
+			enabled = true
+		}
+	}
+	compression := cfg.Arrow.SignalCompression.forSignal(signal)
+	numStreams := cfg.Arrow.StreamsPerSignal.forSignal(signal)
+	if enabled && compression == nil && numStreams == 0 {
+		return cfg
+	}
+	sigCfg := *cfg
+	sigCfg.Arrow.Disabled = sigCfg.Arrow.Disabled || !enabled
+	if compression != nil {
+		sigCfg.Arrow.CompressionConfig = *compression
+	}
+	if numStreams != 0 {
+		sigCfg.Arrow.NumStreams = numStreams
+	}
+	return &sigCfg
+}
 
-// Validate returns an error when the number of streams is less than 1.
-func (cfg *ArrowConfig) Validate() error {
-	if cfg.NumStreams < 1 {
-		return fmt.Errorf("stream count must be > 0: %d", cfg.NumStreams)
+// netstatsOptions returns the options of the network reporter.
+func (cfg *Config) netstatsOptions() []netstats.Option {
+	opts := []netstats.Option{netstats.WithSpanSizeAttributes(cfg.SpanSizeAttributes)}
+	if cfg.SizeHistograms {
+		opts = append(opts, netstats.WithSizeHistograms())
 	}
+	return opts
+}
 
-	if cfg.MaxStreamLifetime.Seconds() < 1 {
-		return fmt.Errorf("max stream life must be >= 1s: %d", cfg.MaxStreamLifetime)
+// Validate returns an error for an unknown key, a negative duration,
+// or empty and duplicate endpoints.
+func (cfg *ShardingConfig) Validate() (errs error) {
+	switch cfg.Key {
+	case "", ShardKeyTraceID, ShardKeyResource:
+	default:
+		errs = multierr.Append(errs, fmt.Errorf("key: unsupported shard key: %q, use %q or %q", cfg.Key, ShardKeyTraceID, ShardKeyResource))
+	}
+	if cfg.UnhealthyDuration < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("unhealthy_duration: unhealthy duration must be >= 0: %s", cfg.UnhealthyDuration))
+	}
+	for _, attr := range cfg.ResourceAttributes {
+		if attr == "" {
+			errs = multierr.Append(errs, fmt.Errorf("resource_attributes: shard resource attribute must not be empty"))
+		}
+	}
+	seen := map[string]bool{}
+	for _, ep := range cfg.Endpoints {
+		if ep == "" {
+			errs = multierr.Append(errs, fmt.Errorf("endpoints: shard endpoint must not be empty"))
+			continue
+		}
+		if seen[ep] {
+			errs = multierr.Append(errs, fmt.Errorf("endpoints: duplicate shard endpoint: %q", ep))
+		}
+		seen[ep] = true
 	}
+	return errs
+}
+
+// Validate returns every invalid or conflicting Arrow setting,
+// qualified by its key.
+func (cfg *ArrowConfig) Validate() (errs error) {
+	errs = multierr.Append(errs, cfg.StreamConfig.Validate())
+	errs = multierr.Append(errs, cfg.DowngradeConfig.Validate())
 
 	if err := cfg.Zstd.Validate(); err != nil {
-		return fmt.Errorf("zstd encoder: invalid configuration: %w", err)
+		errs = multierr.Append(errs, fmt.Errorf("zstd: zstd encoder: invalid configuration: %w", err))
 	}
 
 	if err := cfg.Prioritizer.Validate(); err != nil {
-		return fmt.Errorf("invalid prioritizer: %w", err)
+		errs = multierr.Append(errs, fmt.Errorf("prioritizer: invalid prioritizer: %w", err))
 	}
 
-	// The cfg.PayloadCompression field is validated by the underlying library,
-	// but we only support Zstd or none.
-	switch cfg.PayloadCompression {
-	case "none", "", configcompression.TypeZstd:
-	default:
-		return fmt.Errorf("unsupported payload compression: %s", cfg.PayloadCompression)
+	for _, err := range multierr.Errors(cfg.AutoScale.Validate()) {
+		errs = multierr.Append(errs, fmt.Errorf("auto_scale::%w", err))
 	}
-	return nil
+
+	for _, sig := range cfg.Signals {
+		switch sig {
+		case component.DataTypeTraces.String(), component.DataTypeMetrics.String(), component.DataTypeLogs.String():
+		default:
+			errs = multierr.Append(errs, fmt.Errorf("signals: unknown signal %q, use traces, metrics or logs", sig))
+		}
+	}
+
+	for _, signal := range []component.DataType{component.DataTypeTraces, component.DataTypeMetrics, component.DataTypeLogs} {
+		if n := cfg.StreamsPerSignal.forSignal(signal); n < 0 {
+			errs = multierr.Append(errs, fmt.Errorf("streams_per_signal::%s: stream count must be >= 0: %d", signal, n))
+		}
+		compression := cfg.SignalCompression.forSignal(signal)
+		if compression == nil {
+			continue
+		}
+		if err := compression.Validate(); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("signal_compression::%s::%w", signal, err))
+		}
+		if cfg.ZstdDictionary != "" && compression.PayloadCompression != "" && compression.PayloadCompression != "none" {
+			errs = multierr.Append(errs, fmt.Errorf("zstd_dictionary cannot be combined with signal_compression::%s::payload_compression %q", signal, compression.PayloadCompression))
+		}
+	}
+
+	if cfg.MaxChunkItems < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("max_chunk_items must be non-negative: %d", cfg.MaxChunkItems))
+	}
+	if cfg.MaxChunkItems > 0 && cfg.PipelinedEncoding {
+		errs = multierr.Append(errs, fmt.Errorf("max_chunk_items cannot be combined with pipelined_encoding"))
+	}
+
+	if cfg.SchemaCacheSize < 0 || cfg.SchemaCacheSize > arrowRecord.MaxSchemaCacheSize {
+		errs = multierr.Append(errs, fmt.Errorf("schema_cache_size must be between 0 and %d: %d", arrowRecord.MaxSchemaCacheSize, cfg.SchemaCacheSize))
+	}
+
+	if cfg.CoalesceBatches < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("coalesce_batches must be non-negative: %d", cfg.CoalesceBatches))
+	}
+
+	if cfg.HeartbeatInterval < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("heartbeat_interval must be non-negative: %v", cfg.HeartbeatInterval))
+	}
+
+	if cfg.AckTimeout < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("ack_timeout must be non-negative: %v", cfg.AckTimeout))
+	}
+
+	if cfg.EstablishTimeout < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("establish_timeout must be non-negative: %v", cfg.EstablishTimeout))
+	}
+
+	if cfg.MinCompressionRatio != 0 && cfg.MinCompressionRatio < 1 {
+		errs = multierr.Append(errs, fmt.Errorf("min_compression_ratio must be zero or at least 1: %v", cfg.MinCompressionRatio))
+	}
+
+	if cfg.TrimAfterBatches < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("trim_after_batches must be non-negative: %d", cfg.TrimAfterBatches))
+	}
+
+	if cfg.SchemaChurnThreshold < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("schema_churn_threshold must be non-negative: %d", cfg.SchemaChurnThreshold))
+	}
+
+	if cfg.MemoryLimitMiB > math.MaxInt64>>20 {
+		errs = multierr.Append(errs, fmt.Errorf("memory_limit_mib: memory limit too large: %d MiB", cfg.MemoryLimitMiB))
+	}
+
+	if cfg.MemoryPressureMiB > math.MaxInt64>>20 {
+		errs = multierr.Append(errs, fmt.Errorf("memory_pressure_mib: heap size too large: %d MiB", cfg.MemoryPressureMiB))
+	}
+
+	allowed := map[string]bool{}
+	for _, k := range cfg.MetadataAllowedKeys {
+		allowed[strings.ToLower(k)] = true
+	}
+	for _, k := range cfg.MetadataDeniedKeys {
+		if allowed[strings.ToLower(k)] {
+			errs = multierr.Append(errs, fmt.Errorf("metadata_denied_keys: %q is also allowed by metadata_allowed_keys", k))
+		}
+	}
+
+	for name := range cfg.InstanceIdentity.Labels {
+		if name == "" || strings.Contains(name, "=") {
+			errs = multierr.Append(errs, fmt.Errorf("instance_identity::labels: invalid label name %q", name))
+		}
+	}
+
+	if cfg.ZstdDictionary != "" {
+		if cfg.PayloadCompression != "" && cfg.PayloadCompression != "none" {
+			errs = multierr.Append(errs, fmt.Errorf("zstd_dictionary cannot be combined with payload_compression %q", cfg.PayloadCompression))
+		} else if _, _, err := cfg.loadZstdDictionary(); err != nil {
+			errs = multierr.Append(errs, err)
+		}
+	}
+
+	return multierr.Append(errs, cfg.CompressionConfig.Validate())
+}
+
+// loadZstdDictionary reads the configured Zstd dictionary and
+// returns it with its ID.
+func (cfg *ArrowConfig) loadZstdDictionary() ([]byte, uint32, error) {
+	dict, err := os.ReadFile(cfg.ZstdDictionary)
+	if err != nil {
+		return nil, 0, fmt.Errorf("zstd_dictionary: %w", err)
+	}
+	id, err := zstddict.ID(dict)
+	if err != nil {
+		return nil, 0, fmt.Errorf("zstd_dictionary: %s: %w", cfg.ZstdDictionary, err)
+	}
+	return dict, id, nil
 }
 
 func (cfg *ArrowConfig) toArrowProducerOptions() (arrowOpts []config.Option) {
@@ -122,5 +692,17 @@ func (cfg *ArrowConfig) toArrowProducerOptions() (arrowOpts []config.Option) {
 	default:
 		// Should have failed in validate, nothing we can do.
 	}
+	arrowOpts = append(arrowOpts,
+		config.WithZstdConcurrency(int(cfg.PayloadZstd.Concurrency)),
+		config.WithZstdWindowSize(uint64(cfg.PayloadZstd.WindowSizeMiB)<<20),
+		config.WithZstdLevel(cfg.PayloadZstd.Level),
+		config.WithMaxChunkItems(cfg.MaxChunkItems),
+		config.WithSchemaCacheSize(cfg.SchemaCacheSize),
+		config.WithTrimAfterBatches(cfg.TrimAfterBatches),
+		config.WithMinCompressionRatio(cfg.MinCompressionRatio),
+	)
+	if !dictionaryDeltasGate.IsEnabled() {
+		arrowOpts = append(arrowOpts, config.WithNoDictionaryDeltas())
+	}
 	return
 }
@@ -4,12 +4,15 @@
 package otelarrowexporter
 
 import (
+	"fmt"
 	"math"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/open-telemetry/otel-arrow/collector/arrowconfig"
 	"github.com/open-telemetry/otel-arrow/collector/compression/zstd"
+	"github.com/open-telemetry/otel-arrow/collector/netstats"
 	"github.com/open-telemetry/otel-arrow/pkg/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,6 +25,10 @@ import (
 	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/featuregate"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
 )
@@ -85,21 +92,66 @@ func TestUnmarshalConfig(t *testing.T) {
 				Auth:            &configauth.Authentication{AuthenticatorID: component.NewID(component.MustNewType("nop"))},
 			},
 			Arrow: ArrowConfig{
-				NumStreams:         2,
-				MaxStreamLifetime:  2 * time.Hour,
-				PayloadCompression: configcompression.TypeZstd,
-				Zstd:               zstd.DefaultEncoderConfig(),
-				Prioritizer:        "leastloaded8",
+				StreamConfig: arrowconfig.StreamConfig{
+					NumStreams:        2,
+					MaxStreamLifetime: 2 * time.Hour,
+				},
+				CompressionConfig: arrowconfig.CompressionConfig{
+					PayloadCompression: configcompression.TypeZstd,
+					PayloadZstd: arrowconfig.PayloadZstdConfig{
+						WholePayload:  true,
+						WindowSizeMiB: 4,
+						Level:         6,
+					},
+					DisablePayloadCompressionWithGRPC: true,
+				},
+				DowngradeConfig: arrowconfig.DowngradeConfig{
+					Downgrade: arrowconfig.DowngradePolicyConfig{
+						Policy:        arrowconfig.DowngradeRetryInterval,
+						RetryInterval: 5 * time.Minute,
+					},
+				},
+				Zstd:        zstd.DefaultEncoderConfig(),
+				Prioritizer: "leastloaded8",
+				AutoScale: AutoScaleConfig{
+					Enabled:          true,
+					MinStreams:       1,
+					MaxStreams:       2,
+					Interval:         10 * time.Second,
+					TargetQueueDepth: 2,
+					TargetLatency:    500 * time.Millisecond,
+				},
+				PipelinedEncoding: true,
+				PipelinedSend:     true,
+				IdempotencyKeys:   true,
+				Checksums:         true,
+
+				SchemaChurnThreshold: 20,
+				MetadataDeniedKeys:   []string{"x-internal-route"},
+				SignalCompression: SignalCompressionConfig{
+					Logs: &arrowconfig.CompressionConfig{
+						PayloadCompression: configcompression.TypeZstd,
+						PayloadZstd:        arrowconfig.PayloadZstdConfig{Level: 9},
+					},
+				},
+				StreamsPerSignal: StreamsPerSignalConfig{
+					Traces: 1,
+				},
 			},
+			MetadataKeys: []string{"x-tenant"},
 		}, cfg)
 }
 
 func TestArrowConfigValidate(t *testing.T) {
 	settings := func(enabled bool, numStreams int, maxStreamLifetime time.Duration, level zstd.Level) *ArrowConfig {
 		return &ArrowConfig{
-			Disabled:          !enabled,
-			NumStreams:        numStreams,
-			MaxStreamLifetime: maxStreamLifetime,
+			DowngradeConfig: arrowconfig.DowngradeConfig{
+				Disabled: !enabled,
+			},
+			StreamConfig: arrowconfig.StreamConfig{
+				NumStreams:        numStreams,
+				MaxStreamLifetime: maxStreamLifetime,
+			},
 			Zstd: zstd.EncoderConfig{
 				Level: level,
 			},
@@ -132,7 +184,9 @@ func TestDefaultConfigValid(t *testing.T) {
 
 func TestArrowConfigPayloadCompressionZstd(t *testing.T) {
 	settings := ArrowConfig{
-		PayloadCompression: configcompression.TypeZstd,
+		CompressionConfig: arrowconfig.CompressionConfig{
+			PayloadCompression: configcompression.TypeZstd,
+		},
 	}
 	var config config.Config
 	for _, opt := range settings.toArrowProducerOptions() {
@@ -141,10 +195,226 @@ func TestArrowConfigPayloadCompressionZstd(t *testing.T) {
 	require.True(t, config.Zstd)
 }
 
+func TestArrowConfigPayloadZstd(t *testing.T) {
+	settings := ArrowConfig{
+		CompressionConfig: arrowconfig.CompressionConfig{
+			PayloadCompression: configcompression.TypeZstd,
+			PayloadZstd: arrowconfig.PayloadZstdConfig{
+				Concurrency:   4,
+				WindowSizeMiB: 8,
+			},
+		},
+	}
+	var config config.Config
+	for _, opt := range settings.toArrowProducerOptions() {
+		opt(&config)
+	}
+	require.Equal(t, 4, config.ZstdConcurrency)
+	require.Equal(t, uint64(8<<20), config.ZstdWindowSize)
+}
+
+func TestArrowConfigMaxChunkItems(t *testing.T) {
+	settings := ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: time.Minute,
+		},
+		Zstd:          zstd.DefaultEncoderConfig(),
+		MaxChunkItems: 1000,
+	}
+	require.NoError(t, settings.Validate())
+
+	var config config.Config
+	for _, opt := range settings.toArrowProducerOptions() {
+		opt(&config)
+	}
+	require.Equal(t, 1000, config.MaxChunkItems)
+
+	settings.PipelinedEncoding = true
+	require.ErrorContains(t, settings.Validate(), "cannot be combined with pipelined_encoding")
+
+	settings.PipelinedEncoding = false
+	settings.MaxChunkItems = -1
+	require.ErrorContains(t, settings.Validate(), "max_chunk_items must be non-negative")
+}
+
+func TestArrowConfigCoalesceBatches(t *testing.T) {
+	settings := ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: time.Minute,
+		},
+		Zstd:            zstd.DefaultEncoderConfig(),
+		CoalesceBatches: 8,
+	}
+	require.NoError(t, settings.Validate())
+
+	settings.CoalesceBatches = -1
+	require.ErrorContains(t, settings.Validate(), "coalesce_batches must be non-negative")
+}
+
+func TestArrowConfigHeartbeatInterval(t *testing.T) {
+	settings := ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: time.Minute,
+		},
+		Zstd:              zstd.DefaultEncoderConfig(),
+		HeartbeatInterval: 30 * time.Second,
+	}
+	require.NoError(t, settings.Validate())
+
+	settings.HeartbeatInterval = -time.Second
+	require.ErrorContains(t, settings.Validate(), "heartbeat_interval must be non-negative")
+}
+
+func TestArrowConfigAutoScale(t *testing.T) {
+	settings := NewFactory().CreateDefaultConfig().(*Config).Arrow
+	settings.AutoScale.Enabled = true
+	require.NoError(t, settings.Validate())
+
+	settings.AutoScale.MinStreams = 4
+	settings.AutoScale.MaxStreams = 2
+	settings.AutoScale.Interval = 0
+	settings.AutoScale.TargetQueueDepth = 0
+	settings.AutoScale.TargetLatency = -time.Second
+	err := settings.Validate()
+	require.ErrorContains(t, err, "auto_scale::max_streams: 2 is below min_streams 4")
+	require.ErrorContains(t, err, "auto_scale::interval must be positive")
+	require.ErrorContains(t, err, "auto_scale::target_queue_depth must be > 0")
+	require.ErrorContains(t, err, "auto_scale::target_latency must be non-negative")
+
+	settings.AutoScale.MinStreams = 0
+	require.ErrorContains(t, settings.Validate(), "auto_scale::min_streams: stream count must be > 0")
+
+	// The settings of a disabled auto-scaling are not used.
+	settings.AutoScale.Enabled = false
+	require.NoError(t, settings.Validate())
+}
+
+func TestConfigAutoScaleQueue(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.AutoScale.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	// More streams than queue consumers would stay idle.
+	cfg.Arrow.AutoScale.MaxStreams = cfg.QueueSettings.NumConsumers + 1
+	require.EqualError(t, cfg.Validate(), fmt.Sprintf("arrow::auto_scale::max_streams: %d exceeds sending_queue::num_consumers %d",
+		cfg.QueueSettings.NumConsumers+1, cfg.QueueSettings.NumConsumers))
+
+	// Without a sending queue, the callers wait for the streams.
+	cfg.QueueSettings.Enabled = false
+	require.NoError(t, cfg.Validate())
+
+	cfg.Arrow.AutoScale.Enabled = false
+	require.NoError(t, cfg.Validate())
+}
+
+func TestArrowConfigDowngrade(t *testing.T) {
+	settings := NewFactory().CreateDefaultConfig().(*Config).Arrow
+	settings.Downgrade.Policy = arrowconfig.DowngradeRetryInterval
+	require.ErrorContains(t, settings.Validate(), "downgrade::retry_interval: retry interval must be > 0")
+
+	settings.Downgrade.RetryInterval = time.Minute
+	require.NoError(t, settings.Validate())
+
+	settings.DisableDowngrade = true
+	require.ErrorContains(t, settings.Validate(), "disable_downgrade cannot be combined with downgrade::policy")
+}
+
+func TestArrowConfigAckTimeout(t *testing.T) {
+	settings := ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: time.Minute,
+		},
+		Zstd:       zstd.DefaultEncoderConfig(),
+		AckTimeout: 30 * time.Second,
+	}
+	require.NoError(t, settings.Validate())
+
+	settings.AckTimeout = -time.Second
+	require.ErrorContains(t, settings.Validate(), "ack_timeout must be non-negative")
+}
+
+func TestArrowConfigEstablishTimeout(t *testing.T) {
+	settings := ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: time.Minute,
+		},
+		Zstd:             zstd.DefaultEncoderConfig(),
+		EstablishTimeout: 10 * time.Second,
+	}
+	require.NoError(t, settings.Validate())
+
+	settings.EstablishTimeout = -time.Second
+	require.ErrorContains(t, settings.Validate(), "establish_timeout must be non-negative")
+}
+
+func TestConfigConnectTimeout(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ConnectTimeout = 5 * time.Second
+	require.NoError(t, cfg.Validate())
+
+	cfg.ConnectTimeout = -time.Second
+	require.ErrorContains(t, cfg.Validate(), "connect_timeout must be non-negative")
+}
+
+func TestArrowConfigMinCompressionRatio(t *testing.T) {
+	settings := ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: time.Minute,
+		},
+		Zstd:                zstd.DefaultEncoderConfig(),
+		MinCompressionRatio: 1.2,
+	}
+	require.NoError(t, settings.Validate())
+
+	settings.MinCompressionRatio = 0.5
+	require.ErrorContains(t, settings.Validate(), "min_compression_ratio must be zero or at least 1")
+}
+
+func TestArrowConfigSchemaChurnThreshold(t *testing.T) {
+	settings := NewFactory().CreateDefaultConfig().(*Config).Arrow
+	require.Equal(t, defaultSchemaChurnThreshold, settings.SchemaChurnThreshold)
+	require.NoError(t, settings.Validate())
+
+	settings.SchemaChurnThreshold = 0
+	require.NoError(t, settings.Validate())
+
+	settings.SchemaChurnThreshold = -1
+	require.ErrorContains(t, settings.Validate(), "schema_churn_threshold must be non-negative")
+}
+
+func TestArrowConfigTrimAfterBatches(t *testing.T) {
+	settings := ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: time.Minute,
+		},
+		Zstd:             zstd.DefaultEncoderConfig(),
+		TrimAfterBatches: 100,
+	}
+	require.NoError(t, settings.Validate())
+
+	var config config.Config
+	for _, opt := range settings.toArrowProducerOptions() {
+		opt(&config)
+	}
+	require.Equal(t, 100, config.TrimAfterBatches)
+
+	settings.TrimAfterBatches = -1
+	require.ErrorContains(t, settings.Validate(), "trim_after_batches must be non-negative")
+}
+
 func TestArrowConfigPayloadCompressionNone(t *testing.T) {
 	for _, value := range []string{"", "none"} {
 		settings := ArrowConfig{
-			PayloadCompression: configcompression.Type(value),
+			CompressionConfig: arrowconfig.CompressionConfig{
+				PayloadCompression: configcompression.Type(value),
+			},
 		}
 		var config config.Config
 		for _, opt := range settings.toArrowProducerOptions() {
@@ -153,3 +423,239 @@ func TestArrowConfigPayloadCompressionNone(t *testing.T) {
 		require.False(t, config.Zstd)
 	}
 }
+
+func TestArrowConfigZstdDictionary(t *testing.T) {
+	settings := ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: time.Minute,
+		},
+		Zstd:           zstd.DefaultEncoderConfig(),
+		ZstdDictionary: filepath.Join("testdata", "zstd.dict"),
+	}
+	require.NoError(t, settings.Validate())
+
+	dict, id, err := settings.loadZstdDictionary()
+	require.NoError(t, err)
+	require.NotEmpty(t, dict)
+	require.NotZero(t, id)
+
+	settings.PayloadCompression = configcompression.TypeZstd
+	require.ErrorContains(t, settings.Validate(), "cannot be combined")
+
+	settings.PayloadCompression = ""
+	settings.ZstdDictionary = filepath.Join("testdata", "config.yaml")
+	require.ErrorContains(t, settings.Validate(), "invalid zstd dictionary")
+
+	settings.ZstdDictionary = filepath.Join("testdata", "missing.dict")
+	require.ErrorContains(t, settings.Validate(), "zstd_dictionary")
+}
+
+func TestArrowConfigMemoryLimit(t *testing.T) {
+	settings := ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: time.Minute,
+		},
+		Zstd:           zstd.DefaultEncoderConfig(),
+		MemoryLimitMiB: 256,
+	}
+	require.NoError(t, settings.Validate())
+
+	settings.MemoryLimitMiB = math.MaxUint64
+	require.ErrorContains(t, settings.Validate(), "memory limit too large")
+
+	settings.MemoryLimitMiB = 256
+	settings.MemoryPressureMiB = math.MaxUint64
+	require.ErrorContains(t, settings.Validate(), "heap size too large")
+}
+
+func TestArrowConfigSchemaCacheSize(t *testing.T) {
+	settings := ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: time.Minute,
+		},
+		Zstd:            zstd.DefaultEncoderConfig(),
+		SchemaCacheSize: 4,
+	}
+	require.NoError(t, settings.Validate())
+
+	var config config.Config
+	for _, opt := range settings.toArrowProducerOptions() {
+		opt(&config)
+	}
+	require.Equal(t, 4, config.SchemaCacheSize)
+
+	settings.SchemaCacheSize = 5
+	require.ErrorContains(t, settings.Validate(), "schema_cache_size must be between 0 and 4")
+	settings.SchemaCacheSize = -1
+	require.ErrorContains(t, settings.Validate(), "schema_cache_size must be between 0 and 4")
+}
+
+func TestConfigValidateConflicts(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Compression = configcompression.TypeDeflate
+	require.ErrorContains(t, cfg.Validate(), "compression: unsupported gRPC compression \"deflate\"")
+	cfg.Compression = configcompression.TypeSnappy
+	require.NoError(t, cfg.Validate())
+
+	// Streams without a queue consumer are never used, which is
+	// logged at startup instead of rejected.
+	cfg.QueueSettings.NumConsumers = 2
+	cfg.Arrow.NumStreams = 3
+	require.NoError(t, cfg.Validate())
+	core, logs := observer.New(zap.WarnLevel)
+	cfg.warnIdleStreams(zap.New(core), component.DataTypeTraces)
+	require.Equal(t, 1, logs.FilterField(zap.Int("num_streams", 3)).Len())
+	cfg.QueueSettings.Enabled = false
+	cfg.warnIdleStreams(zap.New(core), component.DataTypeTraces)
+	cfg.QueueSettings.Enabled = true
+	cfg.Arrow.Disabled = true
+	cfg.warnIdleStreams(zap.New(core), component.DataTypeTraces)
+	require.Equal(t, 1, logs.Len())
+
+	cfg.MetadataKeys = []string{"X-Tenant", "x-tenant"}
+	require.ErrorContains(t, cfg.Validate(), "metadata_keys: duplicate entry \"x-tenant\"")
+	cfg.MetadataKeys = nil
+
+	cfg.SpanSizeAttributes = netstats.SpanSizeAttributesCompressed
+	require.NoError(t, cfg.Validate())
+	cfg.SpanSizeAttributes = "uncompressed"
+	require.ErrorContains(t, cfg.Validate(), "span_size_attributes: unknown span size attributes \"uncompressed\"")
+}
+
+func TestArrowConfigMetadataFilter(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Arrow.MetadataAllowedKeys = []string{"authorization", "x-tenant"}
+	cfg.Arrow.MetadataDeniedKeys = []string{"x-internal-route"}
+	require.NoError(t, cfg.Arrow.Validate())
+
+	cfg.Arrow.MetadataDeniedKeys = []string{"X-Tenant"}
+	require.ErrorContains(t, cfg.Arrow.Validate(), "metadata_denied_keys: \"X-Tenant\" is also allowed by metadata_allowed_keys")
+}
+
+func TestArrowConfigInstanceIdentity(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Arrow.InstanceIdentity = InstanceIdentityConfig{
+		Enabled: true,
+		Labels:  map[string]string{"region": "us-east-1"},
+	}
+	require.NoError(t, cfg.Arrow.Validate())
+
+	cfg.Arrow.InstanceIdentity.Labels["zone=a"] = "b"
+	require.ErrorContains(t, cfg.Arrow.Validate(), "instance_identity::labels: invalid label name \"zone=a\"")
+}
+
+func TestArrowConfigSignals(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	require.Same(t, cfg, cfg.forSignal(component.DataTypeTraces))
+
+	cfg.Arrow.Signals = []string{"metrics", "logs"}
+	require.NoError(t, cfg.Arrow.Validate())
+	require.Same(t, cfg, cfg.forSignal(component.DataTypeLogs))
+	require.False(t, cfg.forSignal(component.DataTypeMetrics).Arrow.Disabled)
+
+	// Traces are sent as standard OTLP.
+	traces := cfg.forSignal(component.DataTypeTraces)
+	require.True(t, traces.Arrow.Disabled)
+	require.False(t, cfg.Arrow.Disabled)
+
+	cfg.Arrow.Signals = []string{"spans"}
+	require.ErrorContains(t, cfg.Arrow.Validate(), "signals: unknown signal \"spans\"")
+}
+
+func TestArrowConfigSignalCompression(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Arrow.PayloadCompression = configcompression.TypeZstd
+	cfg.Arrow.SignalCompression.Logs = &arrowconfig.CompressionConfig{
+		PayloadCompression: configcompression.TypeZstd,
+		PayloadZstd:        arrowconfig.PayloadZstdConfig{Level: 9},
+	}
+	cfg.Arrow.SignalCompression.Traces = &arrowconfig.CompressionConfig{
+		PayloadCompression: "none",
+	}
+	require.NoError(t, cfg.Arrow.Validate())
+
+	require.Equal(t, 9, cfg.forSignal(component.DataTypeLogs).Arrow.PayloadZstd.Level)
+	require.Equal(t, configcompression.Type("none"), cfg.forSignal(component.DataTypeTraces).Arrow.PayloadCompression)
+	require.Same(t, cfg, cfg.forSignal(component.DataTypeMetrics))
+	require.Equal(t, configcompression.TypeZstd, cfg.Arrow.PayloadCompression)
+
+	cfg.Arrow.SignalCompression.Logs.PayloadZstd.Level = 30
+	require.ErrorContains(t, cfg.Arrow.Validate(), "signal_compression::logs::payload_zstd::level: level must be between 1 and 22")
+}
+
+func TestArrowConfigStreamsPerSignal(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Arrow.NumStreams = 2
+	cfg.Arrow.StreamsPerSignal.Metrics = 6
+	require.NoError(t, cfg.Arrow.Validate())
+
+	require.Equal(t, 6, cfg.forSignal(component.DataTypeMetrics).Arrow.NumStreams)
+	require.Same(t, cfg, cfg.forSignal(component.DataTypeTraces))
+	require.Equal(t, 2, cfg.Arrow.NumStreams)
+
+	// The consumers are compared to the streams of each signal.
+	cfg.QueueSettings.NumConsumers = 4
+	core, logs := observer.New(zap.WarnLevel)
+	cfg.forSignal(component.DataTypeTraces).warnIdleStreams(zap.New(core), component.DataTypeTraces)
+	cfg.forSignal(component.DataTypeMetrics).warnIdleStreams(zap.New(core), component.DataTypeMetrics)
+	require.Equal(t, 1, logs.FilterField(zap.String("signal", "metrics")).Len())
+	require.Equal(t, 1, logs.Len())
+
+	cfg.Arrow.StreamsPerSignal.Logs = -1
+	require.ErrorContains(t, cfg.Arrow.Validate(), "streams_per_signal::logs: stream count must be >= 0")
+}
+
+func TestArrowConfigValidateAllErrors(t *testing.T) {
+	settings := ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        0,
+			MaxStreamLifetime: time.Hour,
+		},
+		Zstd:              zstd.DefaultEncoderConfig(),
+		MaxChunkItems:     -1,
+		CoalesceBatches:   -1,
+		HeartbeatInterval: -time.Second,
+	}
+	// Every invalid setting is reported at once.
+	err := settings.Validate()
+	require.Len(t, multierr.Errors(err), 4)
+	require.ErrorContains(t, err, "num_streams: stream count must be > 0")
+	require.ErrorContains(t, err, "max_chunk_items must be non-negative")
+	require.ErrorContains(t, err, "coalesce_batches must be non-negative")
+	require.ErrorContains(t, err, "heartbeat_interval must be non-negative")
+
+	sharding := ShardingConfig{
+		Key:       "span_id",
+		Endpoints: []string{"a:4317", "", "a:4317"},
+	}
+	err = sharding.Validate()
+	require.Len(t, multierr.Errors(err), 3)
+	require.ErrorContains(t, err, "key: unsupported shard key")
+	require.ErrorContains(t, err, "endpoints: shard endpoint must not be empty")
+	require.ErrorContains(t, err, "endpoints: duplicate shard endpoint")
+}
+
+func TestArrowConfigDictionaryDeltasGate(t *testing.T) {
+	var settings ArrowConfig
+	var enabled config.Config
+	for _, opt := range settings.toArrowProducerOptions() {
+		opt(&enabled)
+	}
+	require.False(t, enabled.NoDictionaryDeltas)
+
+	// Disabling the gate resends whole dictionaries.
+	require.NoError(t, featuregate.GlobalRegistry().Set(dictionaryDeltasGate.ID(), false))
+	defer func() {
+		require.NoError(t, featuregate.GlobalRegistry().Set(dictionaryDeltasGate.ID(), true))
+	}()
+	var disabled config.Config
+	for _, opt := range settings.toArrowProducerOptions() {
+		opt(&disabled)
+	}
+	require.True(t, disabled.NoDictionaryDeltas)
+}
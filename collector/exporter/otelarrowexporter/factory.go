@@ -9,6 +9,7 @@ import (
 	"time"
 
 	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/arrowconfig"
 	"github.com/open-telemetry/otel-arrow/collector/compression/zstd"
 	"github.com/open-telemetry/otel-arrow/collector/netstats"
 	"go.opentelemetry.io/collector/component"
@@ -36,11 +37,43 @@ func NewFactory() exporter.Factory {
 	)
 }
 
+// maxDefaultNumStreams caps the default number of streams.  Each
+// stream holds its own encoder state, so that a large gateway would
+// otherwise hold many dictionaries without using them efficiently.
+const maxDefaultNumStreams = 16
+
+// defaultNumStreams returns the default number of streams, one per
+// processor that may run Go code, up to maxDefaultNumStreams.
+func defaultNumStreams() int {
+	return min(max(runtime.GOMAXPROCS(0), 1), maxDefaultNumStreams)
+}
+
+// defaultAutoScaleInterval and defaultAutoScaleQueueDepth are the
+// defaults of an enabled auto-scaling of the streams: a stream is
+// added when more than two batches per stream wait to be sent.
+const (
+	defaultAutoScaleInterval   = 10 * time.Second
+	defaultAutoScaleQueueDepth = 2
+)
+
+// defaultSchemaChurnThreshold is the default number of schema
+// changes within the last 100 batches of a stream that is reported,
+// one every ten batches.
+const defaultSchemaChurnThreshold = 10
+
+// defaultQueueSettings returns the exporterhelper defaults, with at
+// least one queue consumer per default stream.
+func defaultQueueSettings() exporterhelper.QueueSettings {
+	qs := exporterhelper.NewDefaultQueueSettings()
+	qs.NumConsumers = max(qs.NumConsumers, defaultNumStreams())
+	return qs
+}
+
 func createDefaultConfig() component.Config {
 	return &Config{
 		TimeoutSettings: exporterhelper.NewDefaultTimeoutSettings(),
 		RetryConfig:     configretry.NewDefaultBackOffConfig(),
-		QueueSettings:   exporterhelper.NewDefaultQueueSettings(),
+		QueueSettings:   defaultQueueSettings(),
 		ClientConfig: configgrpc.ClientConfig{
 			Headers: map[string]configopaque.String{},
 			// Default to zstd compression
@@ -54,25 +87,39 @@ func createDefaultConfig() component.Config {
 			BalancerName: "round_robin",
 		},
 		Arrow: ArrowConfig{
-			NumStreams:        runtime.NumCPU(),
-			MaxStreamLifetime: time.Hour,
+			StreamConfig: arrowconfig.StreamConfig{
+				NumStreams:        defaultNumStreams(),
+				MaxStreamLifetime: time.Hour,
+			},
 
 			Zstd:        zstd.DefaultEncoderConfig(),
 			Prioritizer: arrow.DefaultPrioritizer,
 
+			// Each queue consumer keeps at most one stream busy.
+			AutoScale: AutoScaleConfig{
+				MinStreams:       1,
+				MaxStreams:       defaultQueueSettings().NumConsumers,
+				Interval:         defaultAutoScaleInterval,
+				TargetQueueDepth: defaultAutoScaleQueueDepth,
+			},
+
+			SchemaChurnThreshold: defaultSchemaChurnThreshold,
+
 			// PayloadCompression is off by default because gRPC
 			// compression is on by default, above.
-			PayloadCompression: "",
+			CompressionConfig: arrowconfig.CompressionConfig{
+				PayloadCompression: "",
+			},
 		},
 	}
 }
 
-func (exp *baseExporter) helperOptions() []exporterhelper.Option {
+func helperOptions(cfg *Config, exp exporterPusher) []exporterhelper.Option {
 	return []exporterhelper.Option{
 		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
-		exporterhelper.WithTimeout(exp.config.TimeoutSettings),
-		exporterhelper.WithRetry(exp.config.RetryConfig),
-		exporterhelper.WithQueue(exp.config.QueueSettings),
+		exporterhelper.WithTimeout(cfg.TimeoutSettings),
+		exporterhelper.WithRetry(cfg.RetryConfig),
+		exporterhelper.WithQueue(cfg.QueueSettings),
 		exporterhelper.WithStart(exp.start),
 		exporterhelper.WithShutdown(exp.shutdown),
 	}
@@ -97,13 +144,13 @@ func createTracesExporter(
 	set exporter.CreateSettings,
 	cfg component.Config,
 ) (exporter.Traces, error) {
-	exp, err := newExporter(cfg, set, createArrowTracesStream)
+	exp, err := newPusher(cfg.(*Config).forSignal(component.DataTypeTraces), set, component.DataTypeTraces, createArrowTracesStream)
 	if err != nil {
 		return nil, err
 	}
-	return exporterhelper.NewTracesExporter(ctx, exp.settings, exp.config,
+	return exporterhelper.NewTracesExporter(ctx, set, cfg,
 		exp.pushTraces,
-		exp.helperOptions()...,
+		helperOptions(cfg.(*Config), exp)...,
 	)
 }
 
@@ -116,13 +163,13 @@ func createMetricsExporter(
 	set exporter.CreateSettings,
 	cfg component.Config,
 ) (exporter.Metrics, error) {
-	exp, err := newExporter(cfg, set, createArrowMetricsStream)
+	exp, err := newPusher(cfg.(*Config).forSignal(component.DataTypeMetrics), set, component.DataTypeMetrics, createArrowMetricsStream)
 	if err != nil {
 		return nil, err
 	}
-	return exporterhelper.NewMetricsExporter(ctx, exp.settings, exp.config,
+	return exporterhelper.NewMetricsExporter(ctx, set, cfg,
 		exp.pushMetrics,
-		exp.helperOptions()...,
+		helperOptions(cfg.(*Config), exp)...,
 	)
 }
 
@@ -135,12 +182,12 @@ func createLogsExporter(
 	set exporter.CreateSettings,
 	cfg component.Config,
 ) (exporter.Logs, error) {
-	exp, err := newExporter(cfg, set, createArrowLogsStream)
+	exp, err := newPusher(cfg.(*Config).forSignal(component.DataTypeLogs), set, component.DataTypeLogs, createArrowLogsStream)
 	if err != nil {
 		return nil, err
 	}
-	return exporterhelper.NewLogsExporter(ctx, exp.settings, exp.config,
+	return exporterhelper.NewLogsExporter(ctx, set, cfg,
 		exp.pushLogs,
-		exp.helperOptions()...,
+		helperOptions(cfg.(*Config), exp)...,
 	)
 }
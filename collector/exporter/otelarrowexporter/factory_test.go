@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/open-telemetry/otel-arrow/collector/arrowconfig"
 	"github.com/open-telemetry/otel-arrow/collector/compression/zstd"
 	"github.com/open-telemetry/otel-arrow/collector/testutil"
 	"github.com/stretchr/testify/assert"
@@ -34,19 +35,45 @@ func TestCreateDefaultConfig(t *testing.T) {
 	ocfg, ok := factory.CreateDefaultConfig().(*Config)
 	assert.True(t, ok)
 	assert.Equal(t, ocfg.RetryConfig, configretry.NewDefaultBackOffConfig())
-	assert.Equal(t, ocfg.QueueSettings, exporterhelper.NewDefaultQueueSettings())
+	expectQueue := exporterhelper.NewDefaultQueueSettings()
+	// Every default stream has a queue consumer.
+	expectQueue.NumConsumers = max(expectQueue.NumConsumers, ocfg.Arrow.NumStreams)
+	assert.Equal(t, ocfg.QueueSettings, expectQueue)
 	assert.Equal(t, ocfg.TimeoutSettings, exporterhelper.NewDefaultTimeoutSettings())
 	assert.Equal(t, ocfg.Compression, configcompression.TypeZstd)
 	assert.Equal(t, ocfg.Arrow, ArrowConfig{
-		Disabled:           false,
-		NumStreams:         runtime.NumCPU(),
-		MaxStreamLifetime:  time.Hour,
-		PayloadCompression: "",
-		Zstd:               zstd.DefaultEncoderConfig(),
-		Prioritizer:        arrow.DefaultPrioritizer,
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        defaultNumStreams(),
+			MaxStreamLifetime: time.Hour,
+		},
+		CompressionConfig: arrowconfig.CompressionConfig{
+			PayloadCompression: "",
+		},
+		DowngradeConfig: arrowconfig.DowngradeConfig{
+			Disabled: false,
+		},
+		Zstd:        zstd.DefaultEncoderConfig(),
+		Prioritizer: arrow.DefaultPrioritizer,
+		AutoScale: AutoScaleConfig{
+			MinStreams:       1,
+			MaxStreams:       expectQueue.NumConsumers,
+			Interval:         defaultAutoScaleInterval,
+			TargetQueueDepth: defaultAutoScaleQueueDepth,
+		},
+
+		SchemaChurnThreshold: defaultSchemaChurnThreshold,
 	})
 }
 
+func TestDefaultNumStreams(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	runtime.GOMAXPROCS(2)
+	assert.Equal(t, 2, defaultNumStreams())
+	runtime.GOMAXPROCS(64)
+	assert.Equal(t, maxDefaultNumStreams, defaultNumStreams())
+}
+
 func TestCreateMetricsExporter(t *testing.T) {
 	factory := NewFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
@@ -229,7 +256,9 @@ func TestCreateArrowTracesExporter(t *testing.T) {
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.ClientConfig.Endpoint = testutil.GetAvailableLocalAddress(t)
 	cfg.Arrow = ArrowConfig{
-		NumStreams: 1,
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams: 1,
+		},
 	}
 	set := exportertest.NewNopCreateSettings()
 	oexp, err := factory.CreateTracesExporter(context.Background(), set, cfg)
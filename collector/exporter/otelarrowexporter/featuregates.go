@@ -0,0 +1,17 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowexporter // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+
+import "go.opentelemetry.io/collector/featuregate"
+
+// dictionaryDeltasGate controls whether the Arrow encoders send only
+// the entries added to a dictionary since the previous batch.
+// Disabling it resends whole dictionaries, for receivers or proxies
+// that mishandle deltas, at the cost of compression.
+var dictionaryDeltasGate = featuregate.GlobalRegistry().MustRegister(
+	"exporter.otelarrow.DictionaryDeltas",
+	featuregate.StageBeta,
+	featuregate.WithRegisterDescription("When enabled, OTel-Arrow exporters send Arrow dictionary deltas instead of replacement dictionaries."),
+	featuregate.WithRegisterFromVersion("v0.24.0"),
+)
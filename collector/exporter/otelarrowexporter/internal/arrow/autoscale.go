@@ -0,0 +1,150 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AutoScale bounds and paces the adjustment of the number of streams,
+// see WithAutoScale.
+type AutoScale struct {
+	// MinStreams and MaxStreams bound the number of streams.
+	MinStreams int
+	MaxStreams int
+
+	// Interval is the time between adjustments.
+	Interval time.Duration
+
+	// TargetQueueDepth is the number of batches per stream
+	// waiting for a stream to accept them above which a stream is
+	// added.
+	TargetQueueDepth int
+
+	// TargetLatency is the mean time for the streams to receive
+	// the status of a batch above which a stream is added.  Zero
+	// ignores the latency.
+	TargetLatency time.Duration
+}
+
+// scaleDownIntervals is the number of consecutive intervals in which
+// the streams keep up before one is removed, so that the number of
+// streams does not oscillate under a steady load.
+const scaleDownIntervals = 3
+
+// latencyWeight is the weight, as a power of two, of the previous
+// average in the moving average of a stream's latency.
+const latencyWeight = 3
+
+// WithAutoScale lets the stream controller adjust the number of
+// streams every interval, between the bounds of as, starting from
+// the number given to NewExporter.  One stream is added when more
+// than the target depth of batches per stream wait in SendAndWait
+// for a stream to accept them, or when the streams' mean latency
+// exceeds the target, and one is removed after the streams kept up
+// with no batch waiting, and within half the target latency, for
+// several intervals.  Streams are added and removed as by SetStreams.
+func WithAutoScale(as AutoScale) Option {
+	return func(e *Exporter) {
+		e.autoScale = &autoScaler{AutoScale: as}
+	}
+}
+
+// autoScaler is the auto-scaling state of the stream controller.  A
+// nil *autoScaler does not scale.
+type autoScaler struct {
+	AutoScale
+
+	// keptUp counts the consecutive intervals in which the streams
+	// kept up.
+	keptUp int
+}
+
+// ticker returns the ticker of the adjustments and its channel, both
+// nil when auto-scaling is disabled.
+func (a *autoScaler) ticker() (*time.Ticker, <-chan time.Time) {
+	if a == nil {
+		return nil, nil
+	}
+	t := time.NewTicker(a.Interval)
+	return t, t.C
+}
+
+// clamp returns the number of streams within the bounds.
+func (a *autoScaler) clamp(numStreams int) int {
+	if a == nil {
+		return numStreams
+	}
+	return min(max(numStreams, a.MinStreams), a.MaxStreams)
+}
+
+// next returns the number of streams for the next interval, given
+// the streams in use and the number of batches waiting for them.
+func (a *autoScaler) next(streams []*streamWorkState, depth int64) (numStreams int, latency time.Duration) {
+	numStreams = len(streams)
+	measured := 0
+	for _, ws := range streams {
+		if l := ws.ackLatency.Load(); l != 0 {
+			latency += time.Duration(l)
+			measured++
+		}
+	}
+	if measured != 0 {
+		latency /= time.Duration(measured)
+	}
+
+	switch {
+	case numStreams != a.clamp(numStreams):
+		// SetStreams changed the number beyond the bounds.
+		a.keptUp = 0
+		return a.clamp(numStreams), latency
+	case depth > int64(a.TargetQueueDepth*numStreams) || (a.TargetLatency > 0 && latency > a.TargetLatency):
+		a.keptUp = 0
+		return min(numStreams+1, a.MaxStreams), latency
+	case depth == 0 && (a.TargetLatency == 0 || latency <= a.TargetLatency/2):
+		a.keptUp++
+		if a.keptUp >= scaleDownIntervals && numStreams > a.MinStreams {
+			a.keptUp = 0
+			return numStreams - 1, latency
+		}
+	default:
+		a.keptUp = 0
+	}
+	return numStreams, latency
+}
+
+// autoScaleStreams adjusts the number of streams in the stream
+// controller, returning the change in the number of running streams,
+// like setStreams.
+func (e *Exporter) autoScaleStreams(downCtx context.Context) (added int) {
+	depth := e.ready.Load().waiting()
+	numStreams, latency := e.autoScale.next(e.streams, depth)
+	if numStreams == len(e.streams) {
+		return 0
+	}
+	e.telemetry.Logger.Info("arrow streams scaled",
+		zap.Int("from", len(e.streams)),
+		zap.Int("to", numStreams),
+		zap.Int64("queue_depth", depth),
+		zap.Duration("latency", latency),
+	)
+	return e.setStreams(downCtx, streamsRequest{
+		numStreams:        numStreams,
+		maxStreamLifetime: e.maxStreamLifetime,
+	})
+}
+
+// observeLatency adds the latency of one batch status to the moving
+// average of the stream.  Only the stream's reader calls it.
+func (sws *streamWorkState) observeLatency(latency time.Duration) {
+	prev := sws.ackLatency.Load()
+	if prev == 0 {
+		sws.ackLatency.Store(max(int64(latency), 1))
+		return
+	}
+	sws.ackLatency.Store(max(prev+(int64(latency)-prev)>>latencyWeight, 1))
+}
@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+)
+
+func testStreams(n int) []*streamWorkState {
+	var streams []*streamWorkState
+	for i := 0; i < n; i++ {
+		streams = append(streams, newStreamWorkState(strconv.Itoa(i), time.Hour))
+	}
+	return streams
+}
+
+func TestAutoScalerNext(t *testing.T) {
+	a := &autoScaler{AutoScale: AutoScale{
+		MinStreams:       1,
+		MaxStreams:       3,
+		TargetQueueDepth: 2,
+		TargetLatency:    time.Second,
+	}}
+	streams := testStreams(2)
+
+	// More than two batches per stream wait for a stream.
+	n, _ := a.next(streams, 5)
+	require.Equal(t, 3, n)
+	n, _ = a.next(streams, 4)
+	require.Equal(t, 2, n)
+
+	// Slow streams are added to, up to the maximum.
+	streams[0].observeLatency(3 * time.Second)
+	n, latency := a.next(streams, 0)
+	require.Equal(t, 3, n)
+	require.Equal(t, 3*time.Second, latency)
+	n, _ = a.next(testStreams(3), 100)
+	require.Equal(t, 3, n)
+
+	// Streams that keep up are removed after a few intervals,
+	// down to the minimum.
+	streams = testStreams(2)
+	for i := 1; i < scaleDownIntervals; i++ {
+		n, _ = a.next(streams, 0)
+		require.Equal(t, 2, n)
+	}
+	n, _ = a.next(streams, 0)
+	require.Equal(t, 1, n)
+	for i := 0; i < 2*scaleDownIntervals; i++ {
+		n, _ = a.next(streams[:1], 0)
+		require.Equal(t, 1, n)
+	}
+
+	// Within the target latency, but above its half, the
+	// streams are kept.
+	streams[0].observeLatency(700 * time.Millisecond)
+	for i := 0; i < 2*scaleDownIntervals; i++ {
+		n, _ = a.next(streams, 0)
+		require.Equal(t, 2, n)
+	}
+
+	// A number set beyond the bounds returns within them.
+	n, _ = a.next(testStreams(5), 0)
+	require.Equal(t, 3, n)
+}
+
+func TestStreamLatency(t *testing.T) {
+	ws := newStreamWorkState("0", time.Hour)
+	ws.observeLatency(time.Second)
+	require.Equal(t, int64(time.Second), ws.ackLatency.Load())
+
+	// The average moves by an eighth of the difference.
+	ws.observeLatency(9 * time.Second)
+	require.Equal(t, int64(2*time.Second), ws.ackLatency.Load())
+}
+
+// TestArrowExporterAutoScale verifies that the stream controller adds
+// streams while batches wait for a stream and removes them when the
+// streams keep up.
+func TestArrowExporterAutoScale(t *testing.T) {
+	tc := newExporterTestCaseCommon(t, DefaultPrioritizer, NotNoisy, time.Hour, 1, false, nil)
+	WithAutoScale(AutoScale{
+		MinStreams:       1,
+		MaxStreams:       3,
+		Interval:         5 * time.Millisecond,
+		TargetQueueDepth: 1,
+	})(tc.exporter)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var opened, closed atomic.Int64
+	release := make(chan struct{})
+
+	tc.traceCall.AnyTimes().DoAndReturn(func(ctx context.Context, opts ...grpc.CallOption) (
+		arrowpb.ArrowTracesService_ArrowTracesClient,
+		error,
+	) {
+		wg.Add(1)
+		opened.Add(1)
+		channel := newHealthyTestChannel()
+
+		go func() {
+			defer wg.Done()
+			for data := range channel.sendChannel() {
+				// Batches wait to be answered until
+				// released.
+				<-release
+				channel.recv <- statusOKFor(data.BatchId)
+			}
+			closed.Add(1)
+			close(channel.recv)
+		}()
+
+		return tc.returnNewStream(channel)(ctx, opts...)
+	})
+
+	require.NoError(t, tc.exporter.Start(ctx))
+
+	const senders = 16
+	var swg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		swg.Add(1)
+		go func() {
+			defer swg.Done()
+			ok, err := tc.exporter.SendAndWait(ctx, testdata.GenerateTraces(2))
+			require.NoError(t, err)
+			require.True(t, ok)
+		}()
+	}
+
+	// The streams block on their first batch while the others
+	// wait, so streams are added up to the maximum.
+	require.Eventually(t, func() bool {
+		return opened.Load() == 3
+	}, 5*time.Second, time.Millisecond)
+
+	close(release)
+	swg.Wait()
+
+	// Idle streams are removed down to the minimum.
+	require.Eventually(t, func() bool {
+		return closed.Load() == 2
+	}, 5*time.Second, time.Millisecond)
+	require.Len(t, tc.observedLogs.FilterMessage("arrow streams scaled").All(), 4)
+
+	require.NoError(t, tc.exporter.Shutdown(ctx))
+	cancel()
+	wg.Wait()
+}
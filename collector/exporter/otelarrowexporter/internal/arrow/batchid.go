@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// Batch IDs are scoped to their stream.  Each stream has a new
+// producer, whose batch IDs count up from zero, heartbeats count down
+// from -1, and the receiver answers each batch on the stream that
+// sent it, so that a status is identified by the stream's ID and the
+// batch ID together.  The waiters of a stream are answered when it
+// ends, before its successor sends a batch.
+//
+// IDs are not reused within a stream: a stream whose batch IDs reach
+// maxBatchID ends, with the reason batch_ids_exhausted, and its
+// successor starts again from zero.  The default keeps batch IDs
+// within 32 bits, which a long-lived stream sending thousands of
+// batches per second reaches in weeks.
+const defaultMaxBatchID = math.MaxUint32
+
+// batchIDKey is the log field of the last batch ID sent by a stream,
+// logged when the stream ends.
+const batchIDKey = "last_batch_id"
+
+// sentBatchIDs tracks the batch IDs sent by a stream, which tells
+// the statuses of batches already answered from statuses of batches
+// never sent.
+type sentBatchIDs struct {
+	// last is the last batch ID sent, -1 before the first.
+	last atomic.Int64
+
+	// lastHeartbeat is the last heartbeat ID sent, zero before
+	// the first.
+	lastHeartbeat atomic.Int64
+}
+
+func newSentBatchIDs() *sentBatchIDs {
+	ids := &sentBatchIDs{}
+	ids.last.Store(-1)
+	return ids
+}
+
+// sent records a batch or heartbeat ID sent.
+func (ids *sentBatchIDs) sent(batchID int64) {
+	if batchID < 0 {
+		ids.lastHeartbeat.Store(batchID)
+		return
+	}
+	ids.last.Store(batchID)
+}
+
+// unrecognized returns the error that ends stream streamID upon the
+// status of an unrecognized batch ID, telling whether the ID was
+// sent by this stream.
+func (ids *sentBatchIDs) unrecognized(streamID string, batchID int64) error {
+	if batchID < 0 {
+		if last := ids.lastHeartbeat.Load(); batchID < last || last == 0 {
+			return fmt.Errorf("unrecognized batch ID: %d: stream %s sent heartbeats down to %d", batchID, streamID, last)
+		}
+	} else if last := ids.last.Load(); batchID > last {
+		return fmt.Errorf("unrecognized batch ID: %d: stream %s sent batches up to %d", batchID, streamID, last)
+	}
+	return fmt.Errorf("unrecognized batch ID: %d: already answered on stream %s", batchID, streamID)
+}
+
+// exhausted returns whether the stream must end before its batch IDs
+// reach max.
+func (ids *sentBatchIDs) exhausted(max int64) bool {
+	return ids.last.Load() >= max
+}
+
+// fields returns the log fields of the batch IDs sent.
+func (ids *sentBatchIDs) fields() []zap.Field {
+	if last := ids.last.Load(); last >= 0 {
+		return []zap.Field{zap.Int64(batchIDKey, last)}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"math"
+	"testing"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
+)
+
+func TestSentBatchIDsUnrecognized(t *testing.T) {
+	ids := newSentBatchIDs()
+	require.Nil(t, ids.fields())
+	require.EqualError(t, ids.unrecognized("s1", 0), "unrecognized batch ID: 0: stream s1 sent batches up to -1")
+	require.EqualError(t, ids.unrecognized("s1", -1), "unrecognized batch ID: -1: stream s1 sent heartbeats down to 0")
+
+	ids.sent(0)
+	ids.sent(1)
+	ids.sent(-1)
+	require.EqualError(t, ids.unrecognized("s1", 1), "unrecognized batch ID: 1: already answered on stream s1")
+	require.EqualError(t, ids.unrecognized("s1", 2), "unrecognized batch ID: 2: stream s1 sent batches up to 1")
+	require.EqualError(t, ids.unrecognized("s1", -1), "unrecognized batch ID: -1: already answered on stream s1")
+	require.EqualError(t, ids.unrecognized("s1", -2), "unrecognized batch ID: -2: stream s1 sent heartbeats down to -1")
+	require.Equal(t, []zap.Field{zap.Int64(batchIDKey, 1)}, ids.fields())
+
+	require.False(t, ids.exhausted(2))
+	require.True(t, ids.exhausted(1))
+
+	// Streams end once their batch IDs reach 32 bits.
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	require.Equal(t, int64(math.MaxUint32), tc.stream.maxBatchID)
+	ids.sent(math.MaxUint32 - 1)
+	require.False(t, ids.exhausted(tc.stream.maxBatchID))
+	ids.sent(math.MaxUint32)
+	require.True(t, ids.exhausted(tc.stream.maxBatchID))
+}
+
+// TestStreamBatchIDsExhausted verifies that a stream ends before its
+// batch IDs reach the maximum, and logs its last batch ID.
+func TestStreamBatchIDsExhausted(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	core, logs := observer.New(zapcore.DebugLevel)
+	tc.stream.telemetry.Logger = zap.New(core)
+	tc.stream.maxBatchID = 1
+
+	tc.fromTracesCall.Times(1).Return(oneBatch, nil)
+
+	channel := newHealthyTestChannel()
+	tc.start(channel)
+
+	go func() {
+		batch := <-channel.sent
+		channel.recv <- statusOKFor(batch.BatchId)
+		// The writer closes the stream, then the receiver.
+		for range channel.sent {
+		}
+		close(channel.recv)
+	}()
+	require.NoError(t, tc.mustSendAndWait())
+	tc.waitForShutdown()
+
+	rotated := logs.FilterField(zap.String(streamevents.EventKey, streamevents.StreamRotate)).All()
+	require.Len(t, rotated, 1)
+	fields := rotated[0].ContextMap()
+	require.Equal(t, streamevents.ReasonBatchIDsExhausted, fields[streamevents.ReasonKey])
+	require.Equal(t, int64(1), fields[batchIDKey])
+}
+
+// TestStreamUnrecognizedBatchID verifies that the status of a batch
+// that the stream did not send ends the stream with its ID.
+func TestStreamUnrecognizedBatchID(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	core, logs := observer.New(zapcore.DebugLevel)
+	tc.stream.telemetry.Logger = zap.New(core)
+
+	tc.fromTracesCall.Times(1).Return(oneBatch, nil)
+
+	channel := newHealthyTestChannel()
+	tc.start(channel)
+
+	go func() {
+		batch := <-channel.sent
+		channel.recv <- statusOKFor(batch.BatchId + 7)
+	}()
+	require.ErrorIs(t, tc.mustSendAndWait(), arrowerrors.ErrStreamRestarting)
+	tc.cancelAndWaitForShutdown()
+
+	failed := logs.FilterField(zap.String(streamevents.EventKey, streamevents.StreamError)).All()
+	require.Len(t, failed, 1)
+	require.Contains(t, failed[0].ContextMap()["message"], "unrecognized batch ID: 8: stream "+tc.stream.id+" sent batches up to 1")
+}
@@ -6,32 +6,35 @@ package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter
 import (
 	"context"
 	"math/rand"
-	"runtime"
-	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // bestOfNPrioritizer is a prioritizer that selects a less-loaded stream to write.
 // https://smallrye.io/smallrye-stork/1.1.1/load-balancer/power-of-two-choices/
+//
+// Each caller selects a stream and writes to it directly.  The load
+// of a stream is read without locking, so that hundreds of concurrent
+// callers do not serialize on the prioritizer.
 type bestOfNPrioritizer struct {
 	doneCancel
+	waitCounter
 
-	// input from the pipeline, as processed data with headers and
-	// a return channel for the result.  This channel is never
-	// closed and is buffered.  At shutdown, items of telemetry can
-	// be left in this channel, but users are expected to complete
-	// their requests before calling shutdown (and the collector's
-	// graph package ensures this).
-	input chan writeItem
+	// state tracks the work being handled by all streams, it is
+	// replaced by setStreams.
+	state atomic.Pointer[[]*streamWorkState]
 
-	// state tracks the work being handled by all streams.
-	state []*streamWorkState
-
-	// numChoices is the number of streams to consder in each decision.
+	// numChoices is the number of streams to consder in each
+	// decision, at most the number of streams.
 	numChoices int
 
 	// loadFunc is the load function.
 	loadFunc loadFunc
+
+	// choices holds *[]streamSorter scratch space for streamFor.
+	choices sync.Pool
 }
 
 type loadFunc func(*streamWorkState) float64
@@ -46,78 +49,38 @@ var _ streamPrioritizer = &bestOfNPrioritizer{}
 func newBestOfNPrioritizer(dc doneCancel, numChoices, numStreams int, lf loadFunc, maxLifetime time.Duration) (*bestOfNPrioritizer, []*streamWorkState) {
 	var state []*streamWorkState
 
-	// Limit numChoices to the number of streams.
-	numChoices = min(numStreams, numChoices)
-
 	for i := 0; i < numStreams; i++ {
-		ws := &streamWorkState{
-			maxStreamLifetime: addJitter(maxLifetime),
-			waiters:           map[int64]chan<- error{},
-			toWrite:           make(chan writeItem, 1),
-		}
-
-		state = append(state, ws)
+		state = append(state, newStreamWorkState(strconv.Itoa(i), maxLifetime))
 	}
 
 	lp := &bestOfNPrioritizer{
 		doneCancel: dc,
-		input:      make(chan writeItem, runtime.NumCPU()),
-		state:      state,
 		numChoices: numChoices,
 		loadFunc:   lf,
 	}
-
-	for i := 0; i < numStreams; i++ {
-		// TODO It's not clear if/when the the prioritizer can
-		// become a bottleneck.
-		go lp.run()
+	lp.state.Store(&state)
+	lp.choices.New = func() any {
+		tmp := make([]streamSorter, numStreams)
+		return &tmp
 	}
 
 	return lp, state
 }
 
 func (lp *bestOfNPrioritizer) downgrade(ctx context.Context) {
-	for _, ws := range lp.state {
+	for _, ws := range *lp.state.Load() {
 		go drain(ws.toWrite, ctx.Done())
 	}
 }
 
-func (lp *bestOfNPrioritizer) sendOne(item writeItem, rnd *rand.Rand, tmp []streamSorter) {
-	stream := lp.streamFor(item, rnd, tmp)
-	writeCh := stream.toWrite
-	select {
-	case writeCh <- item:
-		return
-
-	case <-lp.done:
-		// All other cases: signal restart.
-	}
-	item.errCh <- ErrStreamRestarting
-}
-
-func (lp *bestOfNPrioritizer) run() {
-	tmp := make([]streamSorter, len(lp.state))
-	rnd := rand.New(rand.NewSource(rand.Int63()))
-	for {
-		select {
-		case <-lp.done:
-			return
-		case item := <-lp.input:
-			lp.sendOne(item, rnd, tmp)
-		}
-	}
+// setStreams implements streamPrioritizer.
+func (lp *bestOfNPrioritizer) setStreams(state []*streamWorkState) {
+	lp.state.Store(&state)
 }
 
 // sendAndWait implements streamWriter
 func (lp *bestOfNPrioritizer) sendAndWait(ctx context.Context, errCh <-chan error, wri writeItem) error {
-	select {
-	case <-lp.done:
-		return ErrStreamRestarting
-	case <-ctx.Done():
-		return context.Canceled
-	case lp.input <- wri:
-		return waitForWrite(ctx, errCh, lp.done)
-	}
+	return lp.offer(ctx, lp.done, lp.streamFor(wri), errCh, wri)
 }
 
 func (lp *bestOfNPrioritizer) nextWriter() streamWriter {
@@ -132,23 +95,37 @@ func (lp *bestOfNPrioritizer) nextWriter() streamWriter {
 	}
 }
 
-func (lp *bestOfNPrioritizer) streamFor(_ writeItem, rnd *rand.Rand, tmp []streamSorter) *streamWorkState {
+func (lp *bestOfNPrioritizer) streamFor(_ writeItem) *streamWorkState {
+	state := *lp.state.Load()
+	numChoices := min(lp.numChoices, len(state))
+
+	tmpp := lp.choices.Get().(*[]streamSorter)
+	defer lp.choices.Put(tmpp)
+	if cap(*tmpp) < len(state) {
+		// The number of streams grew.
+		*tmpp = make([]streamSorter, len(state))
+	}
+	tmp := (*tmpp)[:len(state)]
+
 	// Place all streams into the temporary slice.
-	for idx, item := range lp.state {
+	for idx, item := range state {
 		tmp[idx].work = item
 	}
 	// Select numChoices at random by shifting the selection into the start
-	// of the temporary slice.
-	for i := 0; i < lp.numChoices; i++ {
-		pick := rnd.Intn(lp.numChoices - i)
+	// of the temporary slice.  The global random source does not lock.
+	for i := 0; i < numChoices; i++ {
+		pick := rand.Intn(len(tmp) - i)
 		tmp[i], tmp[i+pick] = tmp[i+pick], tmp[i]
 	}
-	for i := 0; i < lp.numChoices; i++ {
+	// Choose the least loaded, the first of the choices in case of
+	// a tie.
+	best := 0
+	for i := 0; i < numChoices; i++ {
 		// TODO: skip channels w/ a pending item (maybe)
 		tmp[i].load = lp.loadFunc(tmp[i].work)
+		if tmp[i].load < tmp[best].load {
+			best = i
+		}
 	}
-	sort.Slice(tmp[0:lp.numChoices], func(i, j int) bool {
-		return tmp[i].load < tmp[j].load
-	})
-	return tmp[0].work
+	return tmp[best].work
 }
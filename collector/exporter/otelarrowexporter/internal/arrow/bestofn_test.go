@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestStreams replies to every item written to the streams of a
+// prioritizer, as a stream reader would on receiving its status.
+func startTestStreams(dc doneCancel, state []*streamWorkState) {
+	for _, ws := range state {
+		go func(ws *streamWorkState) {
+			for {
+				select {
+				case <-dc.done:
+					return
+				case wri := <-ws.toWrite:
+					wri.errCh <- nil
+				}
+			}
+		}(ws)
+	}
+}
+
+func TestBestOfNPrioritizerSpreadsLoad(t *testing.T) {
+	_, dc := newDoneCancel(context.Background())
+	defer dc.cancel()
+
+	lp, state := newBestOfNPrioritizer(dc, 2, 4, pendingRequests, time.Hour)
+
+	// Streams holding a pending item are avoided.
+	for i := 0; i < 3; i++ {
+		state[i].toWrite <- writeItem{}
+	}
+	for i := 0; i < 100; i++ {
+		ws := lp.streamFor(writeItem{})
+		if ws != state[3] {
+			// Unless both choices are loaded.
+			require.Equal(t, float64(1), pendingRequests(ws))
+		}
+	}
+	counts := map[*streamWorkState]int{}
+	for i := 0; i < 1000; i++ {
+		counts[lp.streamFor(writeItem{})]++
+	}
+	// Every stream is among the choices.
+	require.Len(t, counts, 4)
+}
+
+func TestBestOfNPrioritizerShutdown(t *testing.T) {
+	_, dc := newDoneCancel(context.Background())
+	lp, _ := newBestOfNPrioritizer(dc, 1, 1, pendingRequests, time.Hour)
+
+	dc.cancel()
+	errCh := make(chan error, 1)
+	err := lp.sendAndWait(context.Background(), errCh, writeItem{errCh: errCh})
+	require.ErrorIs(t, err, arrowerrors.ErrStreamRestarting)
+	require.Nil(t, lp.nextWriter())
+}
+
+// BenchmarkPrioritizerContention measures sendAndWait with hundreds
+// of concurrent callers.
+func BenchmarkPrioritizerContention(b *testing.B) {
+	for _, numStreams := range []int{1, 4, 16} {
+		b.Run(fmt.Sprint("streams=", numStreams), func(b *testing.B) {
+			_, dc := newDoneCancel(context.Background())
+			defer dc.cancel()
+
+			lp, state := newBestOfNPrioritizer(dc, 2, numStreams, pendingRequests, time.Hour)
+			startTestStreams(dc, state)
+
+			ctx := context.Background()
+			b.SetParallelism(100)
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				errCh := make(chan error, 1)
+				for pb.Next() {
+					if err := lp.sendAndWait(ctx, errCh, writeItem{errCh: errCh}); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
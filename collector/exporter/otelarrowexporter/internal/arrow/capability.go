@@ -0,0 +1,107 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"fmt"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/capability"
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// WithCapabilities makes every stream take part in the capability
+// exchange: the stream declares caps when it opens and negotiates
+// with the capabilities the receiver declares in its response
+// headers.  Batches larger than the receiver accepts then fail with
+// arrowerrors.ErrBatchTooLarge instead of being retried.
+func WithCapabilities(caps capability.Set) Option {
+	return func(e *Exporter) {
+		e.capabilities = &caps
+	}
+}
+
+// WithPayloadZstd makes the streams compress each payload as a whole,
+// using the producers returned by newProducer, once the receiver
+// declares capability.CompressionZstdPayload.  Until then streams use
+// the producers given to NewExporter, whose IPC-level compression
+// receivers of every release decode.  It requires WithCapabilities.
+func WithPayloadZstd(newProducer func() arrowRecord.ProducerAPI) Option {
+	return func(e *Exporter) {
+		e.newPayloadProducer = newProducer
+	}
+}
+
+// streamProducer returns the producer of a new stream and the
+// capabilities that the stream declares, which name whole-payload
+// compression when the receiver of the previous streams declared it.
+func (e *Exporter) streamProducer() (arrowRecord.ProducerAPI, *capability.Set) {
+	if e.newPayloadProducer == nil || e.capabilities == nil || !e.payloadZstd.Load() {
+		return e.newProducer(), e.capabilities
+	}
+	caps := *e.capabilities
+	caps.Compression = []string{capability.CompressionZstdPayload}
+	return e.newPayloadProducer(), &caps
+}
+
+// negotiate waits for the response headers of the stream and
+// negotiates with the capabilities of the receiver, if it declares
+// any.  The writer sends batches meanwhile.  It returns an error that
+// ends the stream when the receiver cannot serve it.
+func (s *Stream) negotiate() error {
+	md, err := s.client.Header()
+	if err != nil {
+		// Recv() returns the error of the stream.
+		return nil
+	}
+	values := md.Get(capability.Header)
+	if len(values) == 0 {
+		// The receiver predates the exchange.
+		s.setPayloadZstd(false)
+		return nil
+	}
+	peer, err := capability.Parse(values[0])
+	if err != nil {
+		s.telemetry.Logger.Warn("ignoring receiver capabilities", zap.Error(err))
+		return nil
+	}
+	// The next streams use whole-payload compression if the
+	// receiver decodes it, including after this one fails.
+	s.setPayloadZstd(peer.HasCompression(capability.CompressionZstdPayload))
+	agreed, err := capability.Negotiate(*s.capabilities, peer)
+	if err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	s.maxBatchBytes.Store(agreed.MaxBatchBytes)
+	s.status.SetStreamParam(s.id, "capabilities", agreed.String())
+	s.telemetry.Logger.Debug("arrow stream capabilities", zap.Stringer("agreed", agreed))
+	return nil
+}
+
+// setPayloadZstd records whether the receiver declares whole-payload
+// compression, see WithPayloadZstd.
+func (s *Stream) setPayloadZstd(supported bool) {
+	if s.payloadZstd != nil {
+		s.payloadZstd.Store(supported)
+	}
+}
+
+// checkBatchSize returns arrowerrors.ErrBatchTooLarge when an
+// encoded batch exceeds the size the receiver accepts.
+func (s *Stream) checkBatchSize(batch *arrowpb.BatchArrowRecords) error {
+	limit := s.maxBatchBytes.Load()
+	if limit == 0 {
+		return nil
+	}
+	if size := int64(proto.Size(batch)); size > limit {
+		return consumererror.NewPermanent(fmt.Errorf("%w: %d bytes, the receiver accepts %d", arrowerrors.ErrBatchTooLarge, size, limit))
+	}
+	return nil
+}
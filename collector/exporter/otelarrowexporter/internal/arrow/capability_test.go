@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+	"github.com/open-telemetry/otel-arrow/collector/capability"
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+	"github.com/open-telemetry/otel-arrow/collector/testutil/arrowmock"
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var testExporterCapabilities = capability.Set{
+	Versions:    []int{capability.Version},
+	Compression: []string{"zstd"},
+}
+
+// returnCapabilityStream is returnNewStream for a receiver that
+// declares caps in its response headers.  It records the capabilities
+// declared by the exporter in declared.
+func (ctc *commonTestCase) returnCapabilityStream(h testChannel, caps capability.Set, declared chan<- string) func(context.Context, ...grpc.CallOption) (
+	arrowpb.ArrowTracesService_ArrowTracesClient,
+	error,
+) {
+	return func(ctx context.Context, _ ...grpc.CallOption) (
+		arrowpb.ArrowTracesService_ArrowTracesClient,
+		error,
+	) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		select {
+		case declared <- md.Get(capability.Header)[0]:
+		default:
+		}
+		str := ctc.newMockStream(ctx)
+		str.anyStreamClient.(*arrowmock.MockAnyStreamClient).EXPECT().Header().AnyTimes().Return(
+			metadata.Pairs(capability.Header, caps.String()), nil)
+		str.sendCall.AnyTimes().DoAndReturn(h.onSend(ctx))
+		str.recvCall.AnyTimes().DoAndReturn(h.onRecv(ctx))
+		str.closeSendCall.AnyTimes().DoAndReturn(h.onCloseSend())
+		return str.anyStreamClient, nil
+	}
+}
+
+func TestArrowExporterCapabilities(t *testing.T) {
+	tc := newSingleStreamTestCase(t, DefaultPrioritizer)
+	channel := newHealthyTestChannel()
+	declared := make(chan string, 1)
+	tc.traceCall.AnyTimes().DoAndReturn(tc.returnCapabilityStream(channel, capability.Set{
+		Versions:         []int{capability.Version},
+		Compression:      []string{"none", "zstd"},
+		DictionaryDeltas: true,
+		MaxBatchBytes:    1 << 20,
+	}, declared))
+
+	status := arrowzpages.Register(component.KindExporter, "otelarrow/capabilities")
+	defer status.Unregister()
+	tc.exporter.status = status
+	WithCapabilities(testExporterCapabilities)(tc.exporter)
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	go func() {
+		data := <-channel.sendChannel()
+		channel.recv <- statusOKFor(data.BatchId)
+	}()
+
+	sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+	require.NoError(t, err)
+	require.True(t, sent)
+
+	// The status was received after the negotiation.
+	var params map[string]string
+	for _, st := range arrowzpages.Snapshot() {
+		if st.Name == "otelarrow/capabilities" && len(st.Streams) == 1 {
+			params = st.Streams[0].Params
+		}
+	}
+	require.NoError(t, tc.exporter.Shutdown(bg))
+
+	require.Equal(t, "versions=1;compression=zstd;dictionary_deltas=false", <-declared)
+	require.Equal(t, "versions=1;compression=zstd;dictionary_deltas=false;max_batch_bytes=1048576", params["capabilities"])
+}
+
+// TestArrowExporterPayloadZstd verifies that streams compress whole
+// payloads only after a receiver declares support for it.
+func TestArrowExporterPayloadZstd(t *testing.T) {
+	for _, supported := range []bool{false, true} {
+		tc := newSingleStreamTestCase(t, DefaultPrioritizer)
+		channel := newHealthyTestChannel()
+		declared := make(chan string, 1)
+		receiverCaps := capability.Set{
+			Versions:    []int{capability.Version},
+			Compression: []string{"none", "zstd"},
+		}
+		if supported {
+			receiverCaps.Compression = append(receiverCaps.Compression, capability.CompressionZstdPayload)
+		}
+		tc.traceCall.AnyTimes().DoAndReturn(tc.returnCapabilityStream(channel, receiverCaps, declared))
+
+		var payloadProducers atomic.Int32
+		WithCapabilities(testExporterCapabilities)(tc.exporter)
+		WithPayloadZstd(func() arrowRecord.ProducerAPI {
+			payloadProducers.Add(1)
+			return arrowRecord.NewProducerWithOptions(config.WithPayloadZstd())
+		})(tc.exporter)
+
+		bg := context.Background()
+		require.NoError(t, tc.exporter.Start(bg))
+
+		go func() {
+			data := <-channel.sendChannel()
+			channel.recv <- statusOKFor(data.BatchId)
+		}()
+		sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+		require.NoError(t, err)
+		require.True(t, sent)
+
+		// The first stream compresses at the IPC level.
+		require.Equal(t, "versions=1;compression=zstd;dictionary_deltas=false", <-declared)
+		require.Zero(t, payloadProducers.Load())
+
+		// The next stream compresses whole payloads if the
+		// receiver decodes them.
+		require.Equal(t, supported, tc.exporter.payloadZstd.Load())
+		producer, caps := tc.exporter.streamProducer()
+		if supported {
+			require.Equal(t, []string{capability.CompressionZstdPayload}, caps.Compression)
+			require.Equal(t, int32(1), payloadProducers.Load())
+		} else {
+			require.Equal(t, testExporterCapabilities.Compression, caps.Compression)
+			require.Zero(t, payloadProducers.Load())
+		}
+		require.NoError(t, producer.Close())
+		require.NoError(t, tc.exporter.Shutdown(bg))
+	}
+}
+
+func TestArrowExporterCapabilitiesIncompatible(t *testing.T) {
+	tc := newSingleStreamDowngradeDisabledTestCase(t, DefaultPrioritizer)
+	var connects atomic.Int32
+	restarted := make(chan struct{})
+	receiverCaps := capability.Set{
+		Versions:    []int{capability.Version},
+		Compression: []string{"none"},
+	}
+	tc.traceCall.AnyTimes().DoAndReturn(func(ctx context.Context, opts ...grpc.CallOption) (
+		arrowpb.ArrowTracesService_ArrowTracesClient,
+		error,
+	) {
+		if connects.Add(1) == 2 {
+			close(restarted)
+		}
+		return tc.returnCapabilityStream(newHealthyTestChannel(), receiverCaps, nil)(ctx, opts...)
+	})
+	WithCapabilities(testExporterCapabilities)(tc.exporter)
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	// The receiver does not decode zstd, so the stream restarts.
+	<-restarted
+	require.NoError(t, tc.exporter.Shutdown(bg))
+}
+
+func TestStreamCheckBatchSize(t *testing.T) {
+	batch := &arrowpb.BatchArrowRecords{
+		BatchId: 1,
+		ArrowPayloads: []*arrowpb.ArrowPayload{{
+			Record: make([]byte, 100),
+		}},
+	}
+	var s Stream
+
+	// No limit before the negotiation.
+	require.NoError(t, s.checkBatchSize(batch))
+
+	s.maxBatchBytes.Store(1000)
+	require.NoError(t, s.checkBatchSize(batch))
+
+	s.maxBatchBytes.Store(10)
+	err := s.checkBatchSize(batch)
+	require.ErrorIs(t, err, arrowerrors.ErrBatchTooLarge)
+	require.True(t, consumererror.IsPermanent(err))
+}
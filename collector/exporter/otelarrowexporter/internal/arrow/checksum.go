@@ -0,0 +1,37 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"strconv"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/zeebo/xxh3"
+)
+
+// checksumHeader conveys the checksum of the payloads of a batch to
+// the receiver, which verifies it before decoding the batch.
+const checksumHeader = "otlp-arrow-checksum"
+
+// WithChecksums attaches a checksum of the encoded payloads to every
+// batch, which lets receivers detect batches corrupted on the way,
+// e.g., by a faulty proxy that terminates TLS, which the checks of
+// gRPC and TCP do not cover end to end.
+func WithChecksums() Option {
+	return func(e *Exporter) {
+		e.checksums = true
+	}
+}
+
+// batchChecksum returns the xxh3 hash of the schema IDs, types, and
+// records of the payloads of a batch, in hexadecimal.
+func batchChecksum(batch *arrowpb.BatchArrowRecords) string {
+	h := xxh3.New()
+	for _, payload := range batch.ArrowPayloads {
+		_, _ = h.WriteString(payload.SchemaId)
+		_, _ = h.WriteString(strconv.Itoa(int(payload.Type)))
+		_, _ = h.Write(payload.Record)
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
@@ -0,0 +1,227 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/open-telemetry/otel-arrow/collector/pdatasize"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// pdataSizeHeader conveys the uncompressed size of a batch to the
+// receiver, see Exporter.SendAndWait.
+const pdataSizeHeader = "otlp-pdata-size"
+
+// WithCoalescing lets the writer of every stream combine up to
+// maxBatches pending batches of the same signal and metadata into
+// one Arrow batch, which compresses better than the batches
+// encoded one by one.  Batches are combined only when they are
+// already waiting for the stream, so coalescing adds no latency.
+// The status of the combined batch is returned to each of its
+// senders.  The combined batch is a copy, which is charged to the
+// memory limiter until it is encoded; batches are not combined
+// when the copy does not fit.  Values below 2 disable coalescing.
+func WithCoalescing(maxBatches int) Option {
+	return func(e *Exporter) {
+		e.maxCoalesce = maxBatches
+	}
+}
+
+// producer is a batch combined into a coalesced one, whose sender
+// is traced and told of the compression.
+type producer struct {
+	ctx        context.Context
+	uncompSize int
+}
+
+// coalesce combines wri with the batches waiting for the stream,
+// while they have the same signal and metadata and their copy fits
+// within the memory limit, up to the stream's coalescing limit.  A
+// batch that cannot be combined is returned to be written after
+// the combined batch.
+func (s *Stream) coalesce(wri writeItem) (combined writeItem, next *writeItem) {
+	if s.maxCoalesce < 2 {
+		return wri, nil
+	}
+	items := []writeItem{wri}
+	copied := 0
+	for len(items) < s.maxCoalesce {
+		select {
+		case more := <-s.workState.toWrite:
+			if !coalescible(wri, more) {
+				return s.combine(items, copied), &more
+			}
+			size := more.uncompSize
+			if len(items) == 1 {
+				// The first batch is copied as well.
+				size += wri.uncompSize
+			}
+			if !s.memory.tryAcquire(int64(size)) {
+				return s.combine(items, copied), &more
+			}
+			copied += size
+			items = append(items, more)
+		default:
+			return s.combine(items, copied), nil
+		}
+	}
+	return s.combine(items, copied), nil
+}
+
+// combine returns the combination of items, which is charged
+// copied bytes that the writer releases once it is encoded.
+func (s *Stream) combine(items []writeItem, copied int) writeItem {
+	combined := combineWriteItems(items)
+	combined.copied = copied
+	return combined
+}
+
+// coalescible returns true if two batches can be sent as one: they
+// are of the same signal and have the same metadata, apart from
+// the value of their size header, which both carry or neither.
+func coalescible(a, b writeItem) bool {
+	switch a.records.(type) {
+	case ptrace.Traces:
+		if _, ok := b.records.(ptrace.Traces); !ok {
+			return false
+		}
+	case plog.Logs:
+		if _, ok := b.records.(plog.Logs); !ok {
+			return false
+		}
+	case pmetric.Metrics:
+		if _, ok := b.records.(pmetric.Metrics); !ok {
+			return false
+		}
+	default:
+		return false
+	}
+	if len(a.md) != len(b.md) {
+		return false
+	}
+	if _, ok := a.md[pdataSizeHeader]; ok {
+		if _, ok := b.md[pdataSizeHeader]; !ok {
+			return false
+		}
+	}
+	for key, val := range a.md {
+		if key == pdataSizeHeader {
+			continue
+		}
+		if bval, ok := b.md[key]; !ok || bval != val {
+			return false
+		}
+	}
+	return true
+}
+
+// combineWriteItems returns a batch with the records of all items.
+// The records are copied, since the senders retain theirs in case
+// the batch is retried.  The size header, when present, is the sum
+// of the sizes.  The status of the combined batch is returned to
+// every sender.
+func combineWriteItems(items []writeItem) writeItem {
+	first := items[0]
+	if len(items) == 1 {
+		return first
+	}
+	combined := writeItem{
+		md:          make(map[string]string, len(first.md)),
+		producerCtx: first.producerCtx,
+		producers:   make([]producer, len(items)),
+	}
+	for key, val := range first.md {
+		combined.md[key] = val
+	}
+
+	errChs := make([]chan<- error, len(items))
+	switch first.records.(type) {
+	case ptrace.Traces:
+		traces := ptrace.NewTraces()
+		for _, item := range items {
+			rss := item.records.(ptrace.Traces).ResourceSpans()
+			for i := 0; i < rss.Len(); i++ {
+				rss.At(i).CopyTo(traces.ResourceSpans().AppendEmpty())
+			}
+		}
+		combined.records = traces
+	case plog.Logs:
+		logs := plog.NewLogs()
+		for _, item := range items {
+			rls := item.records.(plog.Logs).ResourceLogs()
+			for i := 0; i < rls.Len(); i++ {
+				rls.At(i).CopyTo(logs.ResourceLogs().AppendEmpty())
+			}
+		}
+		combined.records = logs
+	case pmetric.Metrics:
+		metrics := pmetric.NewMetrics()
+		for _, item := range items {
+			rms := item.records.(pmetric.Metrics).ResourceMetrics()
+			for i := 0; i < rms.Len(); i++ {
+				rms.At(i).CopyTo(metrics.ResourceMetrics().AppendEmpty())
+			}
+		}
+		combined.records = metrics
+	}
+	for i, item := range items {
+		combined.uncompSize += item.uncompSize
+		combined.producers[i] = producer{ctx: item.producerCtx, uncompSize: item.uncompSize}
+		errChs[i] = item.errCh
+	}
+	if _, ok := first.md[pdataSizeHeader]; ok {
+		combined.md[pdataSizeHeader] = strconv.Itoa(combined.uncompSize)
+	}
+	combined.errCh = fanOut(errChs)
+	return combined
+}
+
+// producerLinks returns links to the spans of the senders of a
+// coalesced batch other than the first, whose span is the parent
+// of the batch's.
+func (wri writeItem) producerLinks() []trace.Link {
+	var links []trace.Link
+	for _, p := range wri.producers[min(1, len(wri.producers)):] {
+		if sc := trace.SpanContextFromContext(p.ctx); sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+	return links
+}
+
+// reportCompression reports the compressed size of the batch to
+// its sender, or to each sender of a coalesced batch its share, in
+// proportion to its uncompressed size.
+func (wri writeItem) reportCompression(compressed int) {
+	if len(wri.producers) == 0 {
+		pdatasize.ReportCompression(wri.producerCtx, wri.uncompSize, compressed)
+		return
+	}
+	for _, p := range wri.producers {
+		share := compressed
+		if wri.uncompSize != 0 {
+			share = int(int64(compressed) * int64(p.uncompSize) / int64(wri.uncompSize))
+		}
+		pdatasize.ReportCompression(p.ctx, p.uncompSize, share)
+	}
+}
+
+// fanOut returns a channel whose one value is forwarded to each of
+// errChs.  Every batch receives exactly one status, from the stream
+// reader or when the stream ends, so the goroutine returns.
+func fanOut(errChs []chan<- error) chan<- error {
+	ch := make(chan error, 1)
+	go func() {
+		err := <-ch
+		for _, errCh := range errChs {
+			errCh <- err
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,179 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/open-telemetry/otel-arrow/collector/pdatasize"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func coalesceTestItem(records any, size string) (writeItem, chan error) {
+	errCh := make(chan error, 1)
+	return writeItem{
+		records:     records,
+		md:          map[string]string{"tenant": "a", pdataSizeHeader: size},
+		uncompSize:  len(size),
+		errCh:       errCh,
+		producerCtx: context.Background(),
+	}, errCh
+}
+
+func TestStreamCoalesce(t *testing.T) {
+	ws := &streamWorkState{
+		toWrite: make(chan writeItem, 4),
+	}
+	stream := newStream(nil, nil, newCommonTestCase(t, NotNoisy).telset, nil, ws)
+	stream.maxCoalesce = 2
+
+	first, firstCh := coalesceTestItem(twoTraces, "1")
+	second, secondCh := coalesceTestItem(twoTraces, "22")
+	third, thirdCh := coalesceTestItem(twoTraces, "333")
+	logs, _ := coalesceTestItem(twoLogs, "4444")
+
+	// The third batch exceeds the limit.
+	ws.toWrite <- second
+	ws.toWrite <- third
+	combined, next := stream.coalesce(first)
+	require.Nil(t, next)
+	require.Equal(t, 2*twoTraces.SpanCount(), combined.records.(ptrace.Traces).SpanCount())
+	require.Equal(t, 3, combined.uncompSize)
+	require.Equal(t, map[string]string{"tenant": "a", pdataSizeHeader: "3"}, combined.md)
+
+	// The status is returned to every sender.
+	testErr := errors.New("test")
+	combined.errCh <- testErr
+	require.ErrorIs(t, <-firstCh, testErr)
+	require.ErrorIs(t, <-secondCh, testErr)
+
+	// A batch of another signal is written next.
+	ws.toWrite <- logs
+	combined, next = stream.coalesce(<-ws.toWrite)
+	require.Equal(t, third.records, combined.records)
+	require.Equal(t, logs.records, next.records)
+	combined.errCh <- nil
+	require.NoError(t, <-thirdCh)
+
+	// Nothing is pending.
+	combined, next = stream.coalesce(logs)
+	require.Nil(t, next)
+	require.Equal(t, logs.records, combined.records)
+}
+
+func TestCoalescible(t *testing.T) {
+	a, _ := coalesceTestItem(twoTraces, "1")
+	b, _ := coalesceTestItem(twoTraces, "2")
+	require.True(t, coalescible(a, b))
+
+	b.md["tenant"] = "b"
+	require.False(t, coalescible(a, b))
+
+	c, _ := coalesceTestItem(twoMetrics, "1")
+	require.False(t, coalescible(a, c))
+
+	// Both or neither carry the size header.
+	d, _ := coalesceTestItem(twoTraces, "1")
+	delete(d.md, pdataSizeHeader)
+	d.md["other"] = "x"
+	require.False(t, coalescible(a, d))
+	require.False(t, coalescible(d, a))
+}
+
+func TestCombineSizeHeader(t *testing.T) {
+	a, _ := coalesceTestItem(twoTraces, "10")
+	a.uncompSize = 10
+	b, _ := coalesceTestItem(twoTraces, "200")
+	b.uncompSize = 200
+	require.True(t, coalescible(a, b))
+
+	combined := combineWriteItems([]writeItem{a, b})
+	require.Equal(t, 210, combined.uncompSize)
+	require.Equal(t, map[string]string{"tenant": "a", pdataSizeHeader: "210"}, combined.md)
+
+	// Without the header, none is added.
+	delete(a.md, pdataSizeHeader)
+	delete(b.md, pdataSizeHeader)
+	require.True(t, coalescible(a, b))
+	combined = combineWriteItems([]writeItem{a, b})
+	require.Equal(t, map[string]string{"tenant": "a"}, combined.md)
+}
+
+func TestStreamCoalesceMemory(t *testing.T) {
+	ws := &streamWorkState{
+		toWrite: make(chan writeItem, 4),
+	}
+	stream := newStream(nil, nil, newCommonTestCase(t, NotNoisy).telset, nil, ws)
+	stream.maxCoalesce = 4
+	stream.memory = NewMemoryLimiter(10)
+
+	first, _ := coalesceTestItem(twoTraces, "1")
+	second, _ := coalesceTestItem(twoTraces, "22")
+	third, _ := coalesceTestItem(twoTraces, "333")
+	fourth, _ := coalesceTestItem(twoTraces, "4444")
+
+	// The senders hold their batches, and the copy of the first
+	// three fits within the limit, but not the fourth.
+	require.NoError(t, stream.memory.acquire(context.Background(), 4))
+	ws.toWrite <- second
+	ws.toWrite <- third
+	ws.toWrite <- fourth
+	combined, next := stream.coalesce(first)
+	require.Equal(t, 3*twoTraces.SpanCount(), combined.records.(ptrace.Traces).SpanCount())
+	require.Equal(t, 6, combined.copied)
+	require.Equal(t, int64(10), stream.memory.Inuse())
+	require.Equal(t, fourth.records, next.records)
+
+	stream.memory.release(int64(combined.copied))
+	require.Equal(t, int64(4), stream.memory.Inuse())
+}
+
+func TestCoalesceReportCompression(t *testing.T) {
+	var reported [][2]int
+	ctx := pdatasize.ContextWithCompressionReporter(context.Background(), func(uncompressed, compressed int) {
+		reported = append(reported, [2]int{uncompressed, compressed})
+	})
+	a, _ := coalesceTestItem(twoTraces, "100")
+	a.uncompSize = 100
+	a.producerCtx = ctx
+	b, _ := coalesceTestItem(twoTraces, "300")
+	b.uncompSize = 300
+	b.producerCtx = ctx
+
+	// Each sender is told its share of the compressed size.
+	combineWriteItems([]writeItem{a, b}).reportCompression(40)
+	require.Equal(t, [][2]int{{100, 10}, {300, 30}}, reported)
+
+	reported = nil
+	a.reportCompression(40)
+	require.Equal(t, [][2]int{{100, 40}}, reported)
+}
+
+func TestCoalesceProducerLinks(t *testing.T) {
+	spanCtx := func(id byte) context.Context {
+		return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: trace.TraceID{id},
+			SpanID:  trace.SpanID{id},
+		}))
+	}
+	a, _ := coalesceTestItem(twoTraces, "1")
+	a.producerCtx = spanCtx(1)
+	b, _ := coalesceTestItem(twoTraces, "2")
+	b.producerCtx = spanCtx(2)
+	c, _ := coalesceTestItem(twoTraces, "3")
+
+	// The first sender's span is the parent, the other traced
+	// senders are linked.
+	combined := combineWriteItems([]writeItem{a, b, c})
+	require.Equal(t, a.producerCtx, combined.producerCtx)
+	links := combined.producerLinks()
+	require.Len(t, links, 1)
+	require.Equal(t, trace.SpanID{2}, links[0].SpanContext.SpanID())
+
+	require.Empty(t, a.producerLinks())
+}
@@ -22,8 +22,10 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow/grpcmock"
+	"github.com/open-telemetry/otel-arrow/collector/testutil/arrowmock"
 )
 
 var (
@@ -112,7 +114,7 @@ type commonTestStream struct {
 }
 
 func (ctc *commonTestCase) newMockStream(ctx context.Context) *commonTestStream {
-	client := arrowCollectorMock.NewMockArrowTracesService_ArrowTracesClient(ctc.ctrl)
+	client := arrowmock.NewMockAnyStreamClient(ctc.ctrl)
 
 	testStream := &commonTestStream{
 		anyStreamClient: client,
@@ -205,6 +207,9 @@ func (tc *healthyTestChannel) onCloseSend() func() error {
 
 func (tc *healthyTestChannel) onSend(ctx context.Context) func(*arrowpb.BatchArrowRecords) error {
 	return func(req *arrowpb.BatchArrowRecords) error {
+		// Like gRPC, which serializes the message, copy it:
+		// the stream reuses its buffers after Send() returns.
+		req = proto.Clone(req).(*arrowpb.BatchArrowRecords)
 		select {
 		case tc.sendChannel() <- req:
 			return nil
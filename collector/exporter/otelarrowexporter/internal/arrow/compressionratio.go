@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// WithCompressionRatio records, in the histogram, the ratio of the
+// OTLP size of each batch to the size of its encoded and compressed
+// Arrow payloads, with the signal as the "signal" attribute and the
+// stream prioritizer as the "prioritizer" attribute.
+func WithCompressionRatio(hist metric.Float64Histogram) Option {
+	return func(e *Exporter) {
+		e.compressionRatio = hist
+		e.ratioAttrs = newRatioAttrs(e.prioritizerName)
+	}
+}
+
+// ratioAttrs are the attributes of the compression ratio of each
+// signal.
+type ratioAttrs struct {
+	traces  metric.RecordOption
+	metrics metric.RecordOption
+	logs    metric.RecordOption
+}
+
+func newRatioAttrs(prioritizer PrioritizerName) ratioAttrs {
+	attrs := func(signal string) metric.RecordOption {
+		return metric.WithAttributeSet(attribute.NewSet(
+			attribute.String("signal", signal),
+			attribute.String("prioritizer", prioritizer.String()),
+		))
+	}
+	return ratioAttrs{
+		traces:  attrs("traces"),
+		metrics: attrs("metrics"),
+		logs:    attrs("logs"),
+	}
+}
+
+// recordCompressionRatio records the compression of a batch, if
+// configured.
+func (s *Stream) recordCompressionRatio(wri writeItem, compressed int) {
+	if s.compressionRatio == nil || wri.uncompSize == 0 {
+		return
+	}
+	var attrs metric.RecordOption
+	switch wri.records.(type) {
+	case ptrace.Traces:
+		attrs = s.ratioAttrs.traces
+	case pmetric.Metrics:
+		attrs = s.ratioAttrs.metrics
+	case plog.Logs:
+		attrs = s.ratioAttrs.logs
+	default:
+		return
+	}
+	s.compressionRatio.Record(context.Background(), float64(wri.uncompSize)/float64(compressed), attrs)
+}
@@ -0,0 +1,49 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestArrowExporterCompressionRatio(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	hist, err := mp.Meter("test").Float64Histogram("ratio")
+	require.NoError(t, err)
+
+	tc := newSingleStreamTestCase(t, DefaultPrioritizer)
+	channel := newHealthyTestChannel()
+	tc.traceCall.AnyTimes().DoAndReturn(tc.returnNewStream(channel))
+	WithCompressionRatio(hist)(tc.exporter)
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	go func() {
+		data := <-channel.sendChannel()
+		channel.recv <- statusOKFor(data.BatchId)
+	}()
+
+	sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+	require.NoError(t, err)
+	require.True(t, sent)
+	require.NoError(t, tc.exporter.Shutdown(bg))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(bg, &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	data := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.Len(t, data.DataPoints, 1)
+	dp := data.DataPoints[0]
+	require.Equal(t, uint64(1), dp.Count)
+	require.Greater(t, dp.Sum, 0.0)
+	require.Equal(t, attribute.NewSet(attribute.String("signal", "traces"), attribute.String("prioritizer", "leastloaded")), dp.Attributes)
+}
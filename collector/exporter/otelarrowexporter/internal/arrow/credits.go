@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+)
+
+// streamCredits is the flow control credit window granted to a
+// stream by the receiver, see arrowpb.BatchStatus.CreditBatches.
+// The stream's writer acquires credit for each batch before sending
+// it, and the reader releases it when the status of the batch
+// arrives, with the receiver's latest window.  Until a status
+// carries a window, and with receivers that grant no credits, the
+// stream is not limited.  A nil *streamCredits does not limit the
+// stream.
+type streamCredits struct {
+	// done ends the writer's waits when the stream ends.
+	done <-chan struct{}
+
+	lock sync.Mutex
+	// batches and bytes are the receiver's latest window, zero
+	// when not limited.
+	batches int64
+	bytes   int64
+	// sent is the encoded size of each batch awaiting its
+	// status, which total sentBytes.
+	sent      map[int64]int64
+	sentBytes int64
+
+	// wake is closed when credit is released, then replaced.
+	wake chan struct{}
+}
+
+// newStreamCredits returns the credits of a stream that ends with
+// ctx.
+func newStreamCredits(ctx context.Context) *streamCredits {
+	return &streamCredits{
+		done: ctx.Done(),
+		sent: map[int64]int64{},
+		wake: make(chan struct{}),
+	}
+}
+
+// allows reports whether a batch of size bytes may be sent.  With no
+// batch awaiting its status, one may always be sent, so that a window
+// smaller than a batch does not stop the stream.  The caller holds
+// the lock.
+func (c *streamCredits) allows(size int64) bool {
+	if len(c.sent) == 0 {
+		return true
+	}
+	if c.batches > 0 && int64(len(c.sent)) >= c.batches {
+		return false
+	}
+	return c.bytes <= 0 || c.sentBytes+size <= c.bytes
+}
+
+// acquire waits for the credit to send batch.  Batches without
+// payloads, i.e., heartbeats, need no credit.  It returns an error
+// when the stream ends first.
+func (c *streamCredits) acquire(batch *arrowpb.BatchArrowRecords) error {
+	if c == nil || len(batch.ArrowPayloads) == 0 {
+		return nil
+	}
+	size := int64(proto.Size(batch))
+	for {
+		c.lock.Lock()
+		if c.allows(size) {
+			c.sent[batch.BatchId] = size
+			c.sentBytes += size
+			c.lock.Unlock()
+			return nil
+		}
+		wake := c.wake
+		c.lock.Unlock()
+
+		select {
+		case <-c.done:
+			return context.Canceled
+		case <-wake:
+		}
+	}
+}
+
+// release returns the credit of the batch whose status arrived and
+// updates the window from the status.
+func (c *streamCredits) release(bs *arrowpb.BatchStatus) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if size, ok := c.sent[bs.BatchId]; ok {
+		delete(c.sent, bs.BatchId)
+		c.sentBytes -= size
+	}
+	c.batches = bs.CreditBatches
+	c.bytes = bs.CreditBytes
+	close(c.wake)
+	c.wake = make(chan struct{})
+}
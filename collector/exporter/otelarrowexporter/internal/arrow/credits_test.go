@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+)
+
+func creditTestBatch(id int64) *arrowpb.BatchArrowRecords {
+	return &arrowpb.BatchArrowRecords{
+		BatchId: id,
+		ArrowPayloads: []*arrowpb.ArrowPayload{{
+			Type:   arrowpb.ArrowPayloadType_SPANS,
+			Record: make([]byte, 100),
+		}},
+	}
+}
+
+func TestStreamCredits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := newStreamCredits(ctx)
+	size := int64(proto.Size(creditTestBatch(1)))
+
+	// Until a status carries a window, the stream is not limited.
+	for id := int64(1); id <= 3; id++ {
+		require.NoError(t, c.acquire(creditTestBatch(id)))
+	}
+	s := statusOKFor(1)
+	s.CreditBatches = 3
+	s.CreditBytes = 2 * size
+	c.release(s)
+
+	// Two batches fill the bytes of the window.
+	acquired := make(chan error, 1)
+	go func() { acquired <- c.acquire(creditTestBatch(4)) }()
+	select {
+	case <-acquired:
+		t.Fatal("acquired beyond the window")
+	case <-time.After(10 * time.Millisecond):
+	}
+	s = statusOKFor(2)
+	s.CreditBatches = 3
+	s.CreditBytes = 2 * size
+	c.release(s)
+	require.NoError(t, <-acquired)
+
+	// Heartbeats need no credit, and one batch may always be
+	// sent.
+	require.NoError(t, c.acquire(&arrowpb.BatchArrowRecords{BatchId: -1}))
+	for _, id := range []int64{3, 4} {
+		s = statusOKFor(id)
+		s.CreditBatches = 1
+		s.CreditBytes = 1
+		c.release(s)
+	}
+	require.NoError(t, c.acquire(creditTestBatch(5)))
+
+	// The stream's end stops the wait.
+	go func() { acquired <- c.acquire(creditTestBatch(6)) }()
+	cancel()
+	require.ErrorIs(t, <-acquired, context.Canceled)
+
+	// A nil window does not limit.
+	var none *streamCredits
+	require.NoError(t, none.acquire(creditTestBatch(7)))
+	none.release(statusOKFor(7))
+}
+
+// TestStreamHonorsCredits verifies that the stream holds a batch
+// while the receiver's window is full.
+func TestStreamHonorsCredits(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+
+	var next int64
+	tc.fromTracesCall.Times(3).DoAndReturn(func(any) (*arrowpb.BatchArrowRecords, error) {
+		next++
+		return creditTestBatch(next), nil
+	})
+
+	channel := newHealthyTestChannel()
+	tc.start(channel)
+	defer tc.cancelAndWaitForShutdown()
+
+	withCredit := func(id int64) *arrowpb.BatchStatus {
+		s := statusOKFor(id)
+		s.CreditBatches = 1
+		return s
+	}
+
+	// The first status grants one batch at a time.
+	go func() {
+		batch := <-channel.sent
+		channel.recv <- withCredit(batch.BatchId)
+	}()
+	require.NoError(t, tc.mustSendAndWait())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, tc.mustSendAndWait())
+		}()
+	}
+	batch := <-channel.sent
+	require.Equal(t, int64(2), batch.BatchId)
+	select {
+	case <-channel.sent:
+		t.Fatal("sent beyond the window")
+	case <-time.After(20 * time.Millisecond):
+	}
+	channel.recv <- withCredit(batch.BatchId)
+	batch = <-channel.sent
+	require.Equal(t, int64(3), batch.BatchId)
+	channel.recv <- withCredit(batch.BatchId)
+	wg.Wait()
+}
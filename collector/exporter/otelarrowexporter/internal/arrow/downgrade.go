@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"context"
+	"time"
+
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
+)
+
+// WithDowngradeRetry lets a downgrade last for the interval, instead
+// of until the exporter restarts.  After the interval, the stream
+// controller starts a new set of streams, which downgrade again if
+// the receiver still does not support Arrow, so that a receiver
+// briefly replaced by one without Arrow support does not lock the
+// exporter into standard OTLP.  Zero keeps the downgrade.
+func WithDowngradeRetry(interval time.Duration) Option {
+	return func(e *Exporter) {
+		e.downgradeRetry = interval
+	}
+}
+
+// retryArrow waits for the retry interval after a downgrade, then
+// starts new streams and returns their downgradeable context, like
+// Start.  It returns false when the exporter shuts down first.
+func (e *Exporter) retryArrow(exportCtx context.Context) (context.Context, doneCancel, bool) {
+	// Batches given to the old streams fail until the retry.
+	drainCtx, drainDc := newDoneCancel(exportCtx)
+	defer drainDc.cancel()
+	e.ready.Load().downgrade(drainCtx)
+
+	timer := time.NewTimer(e.downgradeRetry)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-exportCtx.Done():
+		return nil, doneCancel{}, false
+	}
+
+	streamevents.Upgraded(e.telemetry.Logger, "retrying arrow streams after downgrade")
+	e.status.SetDowngraded(false)
+	e.health.setUpgraded()
+	downCtx, downDc := e.startArrowStreams(exportCtx)
+	return downCtx, downDc, true
+}
@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"google.golang.org/grpc"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
+)
+
+// TestArrowExporterDowngradeRetry verifies that a downgraded exporter
+// with WithDowngradeRetry tries Arrow again after the interval, and
+// uses it once the receiver supports it.
+func TestArrowExporterDowngradeRetry(t *testing.T) {
+	for _, pname := range AllPrioritizers {
+		t.Run(string(pname), func(t *testing.T) {
+			tc := newSingleStreamTestCase(t, pname)
+			WithDowngradeRetry(10 * time.Millisecond)(tc.exporter)
+			var se statusEvents
+			tc.exporter.health = newStreamHealth(se.report)
+
+			badChannel := newArrowUnsupportedTestChannel()
+			goodChannel := newHealthyTestChannel()
+
+			var calls atomic.Int32
+			tc.traceCall.AnyTimes().DoAndReturn(func(ctx context.Context, opts ...grpc.CallOption) (
+				arrowpb.ArrowTracesService_ArrowTracesClient,
+				error,
+			) {
+				if calls.Add(1) == 1 {
+					return tc.returnNewStream(badChannel)(ctx, opts...)
+				}
+				return tc.returnNewStream(goodChannel)(ctx, opts...)
+			})
+
+			go func() {
+				for data := range goodChannel.sendChannel() {
+					goodChannel.recv <- statusOKFor(data.BatchId)
+				}
+			}()
+
+			bg := context.Background()
+			require.NoError(t, tc.exporter.Start(bg))
+
+			sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+			require.False(t, sent)
+			require.ErrorIs(t, err, arrowerrors.ErrDowngraded)
+			require.Equal(t, 0, tc.exporter.Streams())
+
+			// Batches use standard OTLP until the retry.
+			require.Eventually(t, func() bool {
+				sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+				if !sent {
+					require.ErrorIs(t, err, arrowerrors.ErrDowngraded)
+					return false
+				}
+				require.NoError(t, err)
+				return true
+			}, 5*time.Second, time.Millisecond)
+			require.Equal(t, 1, tc.exporter.Streams())
+			require.NoError(t, tc.exporter.SetStreams(1, defaultMaxStreamLifetime))
+
+			require.NoError(t, tc.exporter.Shutdown(bg))
+
+			upgrades := tc.observedLogs.FilterMessage("retrying arrow streams after downgrade").All()
+			require.Len(t, upgrades, 1)
+			require.Equal(t, streamevents.Upgrade, upgrades[0].ContextMap()[streamevents.EventKey])
+			require.Equal(t, []component.Status{component.StatusRecoverableError, component.StatusOK}, se.statuses())
+		})
+	}
+}
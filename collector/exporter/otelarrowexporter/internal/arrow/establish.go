@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+)
+
+// WithEstablishTimeout restarts a stream that is not established
+// within the timeout: the stream must open, including the exchange
+// of capabilities, and, once its first batch is sent, receive the
+// first status.  Unlike a failure to open, which indicates that the
+// receiver does not support Arrow, an expired stream is restarted
+// without downgrading.  Zero disables the timeout.
+func WithEstablishTimeout(timeout time.Duration) Option {
+	return func(e *Exporter) {
+		e.establishTimeout = timeout
+	}
+}
+
+// Phases of the establishment of a stream.
+const (
+	establishOpening     = "opening"
+	establishFirstStatus = "first status"
+)
+
+// establishment bounds the establishment of one stream, see
+// WithEstablishTimeout.  A nil establishment does nothing.
+type establishment struct {
+	timeout time.Duration
+	cancel  func()
+
+	lock sync.Mutex
+	// timer cancels the stream when the current phase expires,
+	// unless gen changed since the phase started.
+	timer *time.Timer
+	gen   int
+	// phase is the current phase, empty while none is timed.
+	phase string
+	// opened is set when the stream has opened, sent when its
+	// first batch is sent.
+	opened, sent bool
+	// expired is the phase that expired, if any.
+	expired string
+}
+
+// startEstablishment starts the opening phase of a stream, which
+// calls cancel when a phase expires.
+func startEstablishment(timeout time.Duration, cancel func()) *establishment {
+	if timeout <= 0 {
+		return nil
+	}
+	est := &establishment{
+		timeout: timeout,
+		cancel:  cancel,
+	}
+	est.lock.Lock()
+	defer est.lock.Unlock()
+	est.arm(establishOpening)
+	return est
+}
+
+// arm starts timing a phase, called with the lock held.
+func (est *establishment) arm(phase string) {
+	est.disarm()
+	est.phase = phase
+	gen := est.gen
+	est.timer = time.AfterFunc(est.timeout, func() {
+		est.lock.Lock()
+		if est.gen != gen {
+			est.lock.Unlock()
+			return
+		}
+		est.expired = est.phase
+		est.lock.Unlock()
+		est.cancel()
+	})
+}
+
+// disarm stops timing the current phase, called with the lock held.
+func (est *establishment) disarm() {
+	est.gen++
+	est.phase = ""
+	if est.timer != nil {
+		est.timer.Stop()
+	}
+}
+
+// streamOpened ends the opening phase, which continues into the
+// first status phase if the first batch was already sent.
+func (est *establishment) streamOpened() {
+	if est == nil {
+		return
+	}
+	est.lock.Lock()
+	defer est.lock.Unlock()
+	est.opened = true
+	if est.sent {
+		est.arm(establishFirstStatus)
+	} else {
+		est.disarm()
+	}
+}
+
+// batchSent starts the first status phase when the first batch is
+// sent on an open stream.
+func (est *establishment) batchSent() {
+	if est == nil {
+		return
+	}
+	est.lock.Lock()
+	defer est.lock.Unlock()
+	if est.sent {
+		return
+	}
+	est.sent = true
+	if est.opened {
+		est.arm(establishFirstStatus)
+	}
+}
+
+// statusReceived establishes the stream.
+func (est *establishment) statusReceived() {
+	if est == nil {
+		return
+	}
+	est.lock.Lock()
+	defer est.lock.Unlock()
+	est.sent = true
+	est.disarm()
+}
+
+// stop stops timing when the stream ends.
+func (est *establishment) stop() {
+	if est == nil {
+		return
+	}
+	est.lock.Lock()
+	defer est.lock.Unlock()
+	est.disarm()
+}
+
+// err returns an error wrapping arrowerrors.ErrEstablishTimeout
+// when a phase expired, otherwise nil.
+func (est *establishment) err() error {
+	if est == nil {
+		return nil
+	}
+	est.lock.Lock()
+	defer est.lock.Unlock()
+	if est.expired == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s not complete after %v", arrowerrors.ErrEstablishTimeout, est.expired, est.timeout)
+}
+
+// restartable returns whether the stream restarts after it returns:
+// streams that opened, and streams whose establishment expired, do.
+// The others indicate that the receiver does not support Arrow.
+func (s *Stream) restartable() bool {
+	return s.client != nil || s.establish.err() != nil
+}
@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestEstablishment(t *testing.T) {
+	// Disabled.
+	var est *establishment
+	require.Nil(t, startEstablishment(0, nil))
+	est.batchSent()
+	require.NoError(t, est.err())
+
+	// Established in time.
+	canceled := make(chan struct{})
+	est = startEstablishment(20*time.Millisecond, func() { close(canceled) })
+	est.streamOpened()
+	est.batchSent()
+	est.statusReceived()
+	time.Sleep(40 * time.Millisecond)
+	require.NoError(t, est.err())
+
+	// The first status phase starts when the stream opens after
+	// the first batch was sent.
+	canceled = make(chan struct{})
+	est = startEstablishment(20*time.Millisecond, func() { close(canceled) })
+	est.batchSent()
+	est.streamOpened()
+	<-canceled
+	require.ErrorIs(t, est.err(), arrowerrors.ErrEstablishTimeout)
+	require.Contains(t, est.err().Error(), "first status not complete after 20ms")
+	est.stop()
+}
+
+// TestStreamEstablishTimeoutOpening verifies that a stream that does
+// not open in time is restarted, not downgraded.
+func TestStreamEstablishTimeoutOpening(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	tc.stream.establishTimeout = 20 * time.Millisecond
+
+	tc.traceCall.Times(1).DoAndReturn(func(ctx context.Context, _ ...grpc.CallOption) (
+		arrowpb.ArrowTracesService_ArrowTracesClient,
+		error,
+	) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	tc.stream.run(tc.bgctx, tc.doneCancel, tc.traceClient, nil)
+
+	require.Nil(t, tc.stream.client)
+	require.True(t, tc.stream.restartable())
+
+	logs := tc.observedLogs.FilterMessage("arrow stream error").All()
+	require.Len(t, logs, 1)
+	require.Contains(t, logs[0].ContextMap()["message"], "opening not complete")
+}
+
+// TestStreamEstablishTimeoutFirstStatus verifies that a stream that
+// receives no status for its first batch in time is restarted.
+func TestStreamEstablishTimeoutFirstStatus(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	tc.stream.establishTimeout = 20 * time.Millisecond
+
+	tc.fromTracesCall.Times(1).Return(oneBatch, nil)
+
+	tc.start(newUnresponsiveTestChannel())
+
+	// The sender retries on another stream.
+	err := tc.mustSendAndWait()
+	require.ErrorIs(t, err, arrowerrors.ErrStreamRestarting)
+	tc.waitForShutdown()
+
+	require.True(t, tc.stream.restartable())
+	logs := tc.observedLogs.FilterMessage("arrow stream error").All()
+	require.Len(t, logs, 1)
+	require.Contains(t, logs[0].ContextMap()["message"], "first status not complete")
+}
+
+// TestStreamEstablishTimeoutIdle verifies that an open stream with
+// no batches is not restarted.
+func TestStreamEstablishTimeoutIdle(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	tc.stream.establishTimeout = 10 * time.Millisecond
+
+	tc.start(newUnresponsiveTestChannel())
+	time.Sleep(50 * time.Millisecond)
+	tc.cancelAndWaitForShutdown()
+
+	require.Empty(t, tc.observedLogs.FilterMessage("arrow stream error").All())
+}
@@ -6,18 +6,25 @@ package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/rand"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+	"github.com/open-telemetry/otel-arrow/collector/capability"
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
 	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/collector/pdatasize"
+	"github.com/open-telemetry/otel-arrow/collector/redact"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
 	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
 	"go.opentelemetry.io/collector/component"
-	"go.opentelemetry.io/collector/pdata/plog"
-	"go.opentelemetry.io/collector/pdata/pmetric"
-	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -60,8 +67,10 @@ type Exporter struct {
 	// and otherwise to the stream controller.
 	returning chan *Stream
 
-	// ready prioritizes streams that are ready to send
-	ready streamPrioritizer
+	// ready prioritizes streams that are ready to send, it is
+	// replaced when the exporter tries Arrow again after a
+	// downgrade, see WithDowngradeRetry.
+	ready atomic.Pointer[readyStreams]
 
 	// doneCancel refers to and cancels the background context of
 	// this exporter.
@@ -81,6 +90,113 @@ type Exporter struct {
 
 	// netReporter measures network traffic.
 	netReporter netstats.Interface
+
+	// status records stream state for the arrowzpages extension,
+	// may be nil.
+	status *arrowzpages.Instance
+
+	// health reports the component status of the streams.
+	health *streamHealth
+
+	// faults are optional hooks for testing.
+	faults StreamFaults
+
+	// recorder optionally records batch statuses, may be nil.
+	recorder *StatusRecorder
+
+	// pipelined is set by WithPipelinedEncoding.
+	pipelined bool
+
+	// pipelinedSend is set by WithPipelinedSend.
+	pipelinedSend bool
+
+	// maxCoalesce is set by WithCoalescing.
+	maxCoalesce int
+
+	// idempotency is set by WithIdempotencyKeys, may be nil.
+	idempotency *idempotencyKeys
+
+	// checksums is set by WithChecksums.
+	checksums bool
+
+	// heartbeatInterval is set by WithHeartbeat.
+	heartbeatInterval time.Duration
+
+	// ackTimeout is set by WithAckTimeout.
+	ackTimeout time.Duration
+
+	// establishTimeout is set by WithEstablishTimeout.
+	establishTimeout time.Duration
+
+	// redactErrors is set by WithRedaction.
+	redactErrors bool
+
+	// compressionRatio is set by WithCompressionRatio, with
+	// ratioAttrs.
+	compressionRatio metric.Float64Histogram
+	ratioAttrs       ratioAttrs
+
+	// sendReporter is set by WithSendReporter, may be nil.
+	sendReporter SendReporter
+
+	// queued counts the batches held by SendAndWait.
+	queued queuedBatches
+
+	// streamParams is set by WithStreamParams.
+	streamParams map[string]string
+
+	// capabilities are set by WithCapabilities, may be nil.
+	capabilities *capability.Set
+
+	// newPayloadProducer is set by WithPayloadZstd, may be nil.
+	// payloadZstd is whether the receiver last declared
+	// whole-payload compression, see streamProducer.
+	newPayloadProducer func() arrowRecord.ProducerAPI
+	payloadZstd        atomic.Bool
+
+	// metadataKeys are set by WithMetadataKeys, lower case.
+	metadataKeys []string
+
+	// metadataFilter is set by WithMetadataFilter, may be nil.
+	metadataFilter *metadataFilter
+
+	// memory accounts for the memory held by the streams, may be
+	// nil.
+	memory *MemoryLimiter
+
+	// adjust passes SetStreams requests to the stream controller.
+	adjust chan streamsRequest
+
+	// autoScale is set by WithAutoScale, may be nil.
+	autoScale *autoScaler
+
+	// downgradeRetry is set by WithDowngradeRetry.
+	downgradeRetry time.Duration
+
+	// streams are the states of the streams in use, and
+	// nextStreamID identifies the next stream added, both owned
+	// by the stream controller after Start.
+	streams      []*streamWorkState
+	nextStreamID int
+
+	// inUse is the number of streams in use, see Streams.
+	inUse atomic.Int64
+}
+
+// readyStreams is the prioritizer of the streams in use.
+type readyStreams struct {
+	streamPrioritizer
+
+	// downgraded is closed when the exporter stops using these
+	// streams, including when it shuts down.
+	downgraded <-chan struct{}
+}
+
+// streamsRequest is a SetStreams request.
+type streamsRequest struct {
+	numStreams        int
+	maxStreamLifetime time.Duration
+	done              chan struct{}
 }
 
 // doneCancel is used to store the done signal and cancelation
@@ -125,8 +241,10 @@ func NewExporter(
 	streamClient StreamClientFunc,
 	perRPCCredentials credentials.PerRPCCredentials,
 	netReporter netstats.Interface,
+	status *arrowzpages.Instance,
+	opts ...Option,
 ) *Exporter {
-	return &Exporter{
+	e := &Exporter{
 		maxStreamLifetime: maxStreamLifetime,
 		numStreams:        numStreams,
 		prioritizerName:   prioritizerName,
@@ -138,7 +256,17 @@ func NewExporter(
 		perRPCCredentials: perRPCCredentials,
 		returning:         make(chan *Stream, numStreams),
 		netReporter:       netReporter,
+		status:            status,
+		health:            newStreamHealth(telemetry.ReportStatus),
+		adjust:            make(chan streamsRequest),
+	}
+	for _, opt := range opts {
+		opt(e)
 	}
+	if e.redactErrors {
+		e.telemetry.Logger = redact.Logger(e.telemetry.Logger)
+	}
+	return e
 }
 
 // Start creates the background context used by all streams and starts
@@ -150,19 +278,31 @@ func (e *Exporter) Start(ctx context.Context) error {
 	// Starting N+1 goroutines
 	e.wg.Add(1)
 
+	e.numStreams = e.autoScale.clamp(e.numStreams)
+	downCtx, downDc := e.startArrowStreams(ctx)
+
+	go e.runStreamController(ctx, downCtx, downDc)
+
+	return nil
+}
+
+// startArrowStreams starts a new set of streams and returns their
+// downgradeable context.
+func (e *Exporter) startArrowStreams(ctx context.Context) (context.Context, doneCancel) {
 	// this is the downgradeable context
 	downCtx, downDc := newDoneCancel(ctx)
 
-	var sws []*streamWorkState
-	e.ready, sws = newStreamPrioritizer(downDc, e.prioritizerName, e.numStreams, e.maxStreamLifetime)
+	var ready readyStreams
+	ready.streamPrioritizer, e.streams = newStreamPrioritizer(downDc, e.prioritizerName, e.numStreams, e.maxStreamLifetime)
+	ready.downgraded = downDc.done
+	e.nextStreamID = len(e.streams)
+	e.ready.Store(&ready)
+	e.inUse.Store(int64(len(e.streams)))
 
-	for _, ws := range sws {
+	for _, ws := range e.streams {
 		e.startArrowStream(downCtx, ws)
 	}
-
-	go e.runStreamController(ctx, downCtx, downDc)
-
-	return nil
+	return downCtx, downDc
 }
 
 func (e *Exporter) startArrowStream(ctx context.Context, ws *streamWorkState) {
@@ -177,17 +317,37 @@ func (e *Exporter) startArrowStream(ctx context.Context, ws *streamWorkState) {
 // runStreamController starts the initial set of streams, then waits for streams to
 // terminate one at a time and restarts them.  If streams come back with a nil
 // client (meaning that OTel-Arrow was not supported by the endpoint), it will
-// not be restarted.
+// not be restarted.  With WithAutoScale, it also adjusts the number of streams,
+// and with WithDowngradeRetry, it starts new streams after a downgrade.
 func (e *Exporter) runStreamController(exportCtx, downCtx context.Context, downDc doneCancel) {
 	defer e.cancel()
 	defer e.wg.Done()
 
 	running := e.numStreams
 
+	scaleTicker, scaleC := e.autoScale.ticker()
+	if scaleTicker != nil {
+		defer scaleTicker.Stop()
+	}
+
 	for {
 		select {
+		case req := <-e.adjust:
+			running += e.setStreams(downCtx, req)
+			close(req.done)
+
+		case <-scaleC:
+			running += e.autoScaleStreams(downCtx)
+
 		case stream := <-e.returning:
-			if stream.client != nil || e.disableDowngrade {
+			if stream.workState.isRetired() {
+				// Batches still given to the removed
+				// stream are retried on another.
+				running--
+				go drain(stream.workState.toWrite, exportCtx.Done())
+				continue
+			}
+			if stream.restartable() || e.disableDowngrade {
 				// The stream closed or broken.  Restart it.
 				e.startArrowStream(downCtx, stream.workState)
 				continue
@@ -199,12 +359,22 @@ func (e *Exporter) runStreamController(exportCtx, downCtx context.Context, downD
 			// None of the streams were able to connect to
 			// an Arrow endpoint.
 			if running == 0 {
-				e.telemetry.Logger.Info("could not establish arrow streams, downgrading to standard OTLP export")
+				streamevents.Downgraded(e.telemetry.Logger, "could not establish arrow streams, downgrading to standard OTLP export", streamevents.ReasonUnsupported)
+				e.status.SetDowngraded(true)
+				e.health.setDowngraded()
+				e.inUse.Store(0)
 				downDc.cancel()
-				// this call is allowed to block indefinitely,
-				// as to call drain().
-				e.ready.downgrade(exportCtx)
-				return
+				if e.downgradeRetry == 0 {
+					// this call is allowed to block indefinitely,
+					// as to call drain().
+					e.ready.Load().downgrade(exportCtx)
+					return
+				}
+				var ok bool
+				if downCtx, downDc, ok = e.retryArrow(exportCtx); !ok {
+					return
+				}
+				running = e.numStreams
 			}
 
 		case <-exportCtx.Done():
@@ -214,6 +384,70 @@ func (e *Exporter) runStreamController(exportCtx, downCtx context.Context, downD
 	}
 }
 
+// SetStreams changes the number of streams and their maximum
+// lifetime while the exporter runs, after Start.  Streams beyond the
+// new number finish gracefully and are not restarted; the batches
+// given to them but not sent are retried on the remaining streams.  A
+// new lifetime applies once each stream restarts, which happens at
+// once, also gracefully.  SetStreams returns
+// arrowerrors.ErrDowngraded when the exporter stopped using Arrow or
+// is shutting down.
+func (e *Exporter) SetStreams(numStreams int, maxStreamLifetime time.Duration) error {
+	if numStreams < 1 {
+		return fmt.Errorf("stream count must be > 0: %d", numStreams)
+	}
+	req := streamsRequest{
+		numStreams:        numStreams,
+		maxStreamLifetime: maxStreamLifetime,
+		done:              make(chan struct{}),
+	}
+	select {
+	case e.adjust <- req:
+	case <-e.ready.Load().downgraded:
+		return arrowerrors.ErrDowngraded
+	}
+	<-req.done
+	return nil
+}
+
+// Streams returns the number of streams in use, as set by Start,
+// SetStreams or WithAutoScale, and zero while the exporter is
+// downgraded.
+func (e *Exporter) Streams() int {
+	return int(e.inUse.Load())
+}
+
+// setStreams applies a SetStreams request in the stream controller,
+// returning the change in the number of running streams.
+func (e *Exporter) setStreams(downCtx context.Context, req streamsRequest) (added int) {
+	if req.maxStreamLifetime != e.maxStreamLifetime {
+		e.maxStreamLifetime = req.maxStreamLifetime
+		for _, ws := range e.streams {
+			ws.setLifetime(req.maxStreamLifetime)
+			ws.requestRecycle()
+		}
+	}
+	for len(e.streams) < req.numStreams {
+		ws := newStreamWorkState(strconv.Itoa(e.nextStreamID), e.maxStreamLifetime)
+		e.nextStreamID++
+		e.streams = append(e.streams, ws)
+		e.startArrowStream(downCtx, ws)
+		added++
+	}
+	retired := e.streams[req.numStreams:]
+	e.streams = e.streams[:req.numStreams:req.numStreams]
+	e.numStreams = req.numStreams
+	e.inUse.Store(int64(req.numStreams))
+
+	// Writers choose among the new streams before the others
+	// finish.
+	e.ready.Load().setStreams(e.streams)
+	for _, ws := range retired {
+		close(ws.retired)
+	}
+	return added
+}
+
 // addJitter is used to subtract 0-5% from max_stream_lifetime.  Since
 // the max_stream_lifetime value is expected to be close to the
 // receiver's max_connection_age_grace setting, we do not add jitter,
@@ -231,16 +465,48 @@ func addJitter(v time.Duration) time.Duration {
 // down this call synchronously waits for and unblocks the consumers.
 func (e *Exporter) runArrowStream(ctx context.Context, dc doneCancel, state *streamWorkState) {
 	defer dc.cancel()
-	producer := e.newProducer()
+	producer, caps := e.streamProducer()
+
+	// A request to recycle the previous stream is satisfied by
+	// this one, which reads the current lifetime below.
+	select {
+	case <-state.recycle:
+	default:
+	}
 
-	stream := newStream(producer, e.ready, e.telemetry, e.netReporter, state)
+	stream := newStream(producer, e.ready.Load(), e.telemetry, e.netReporter, state)
+	stream.status = e.status
+	stream.health = e.health
+	stream.faults = e.faults
+	stream.recorder = e.recorder
+	stream.pipelined = e.pipelined
+	stream.pipelinedSend = e.pipelinedSend
+	stream.maxCoalesce = e.maxCoalesce
+	stream.memory = e.memory
+	stream.heartbeatInterval = e.heartbeatInterval
+	stream.ackTimeout = e.ackTimeout
+	stream.establishTimeout = e.establishTimeout
+	stream.redactErrors = e.redactErrors
+	stream.compressionRatio = e.compressionRatio
+	stream.ratioAttrs = e.ratioAttrs
+	stream.params = e.streamParams
+	stream.capabilities = caps
+	if e.newPayloadProducer != nil {
+		stream.payloadZstd = &e.payloadZstd
+	}
+	stream.checksums = e.checksums
 
 	defer func() {
 		if err := producer.Close(); err != nil {
-			e.telemetry.Logger.Error("arrow producer close:", zap.Error(err))
+			stream.telemetry.Logger.Error("arrow producer close:", zap.Error(err))
 		}
 		e.wg.Done()
-		e.returning <- stream
+		select {
+		case e.returning <- stream:
+		case <-e.done:
+			// Streams added by SetStreams may exceed
+			// the channel's capacity after shutdown.
+		}
 	}()
 
 	stream.run(ctx, dc, e.streamClient, e.grpcOptions)
@@ -248,15 +514,21 @@ func (e *Exporter) runArrowStream(ctx context.Context, dc doneCancel, state *str
 
 // SendAndWait tries to send using an Arrow stream.  The results are:
 //
-// (true, nil):      Arrow send: success at consumer
-// (false, nil):     Arrow is not supported by the server, caller expected to fallback.
-// (true, non-nil):  Arrow send: server response may be permanent or allow retry.
-// (false, non-nil): Context timeout prevents retry.
+// (true, nil):                       Arrow send: success at consumer
+// (false, arrowerrors.ErrDowngraded): Arrow is not supported by the server, caller expected to fallback.
+// (true, non-nil):                   Arrow send: server response may be permanent or allow retry.
+// (false, non-nil):                  Context timeout prevents retry.
+//
+// Errors of the Arrow encoder wrap arrowerrors.ErrEncode, and a
+// deadline expiring while waiting for the server's response wraps
+// arrowerrors.ErrAckTimeout.
 //
 // consumer should fall back to standard OTLP, (true, nil)
 func (e *Exporter) SendAndWait(ctx context.Context, data any) (bool, error) {
 	errCh := make(chan error, 1)
 
+	defer e.enqueue(data)()
+
 	// Note that if the OTLP exporter's gRPC Headers field was
 	// set, those (static) headers were used to establish the
 	// stream.  The caller's context was returned by
@@ -273,31 +545,44 @@ func (e *Exporter) SendAndWait(ctx context.Context, data any) (bool, error) {
 		var err error
 		md, err = e.perRPCCredentials.GetRequestMetadata(ctx)
 		if err != nil {
+			e.enqueueFailed(ctx, data, ReasonCredentials)
 			return false, err
 		}
 	}
+	md = e.addClientMetadata(ctx, md)
+	e.metadataFilter.apply(md)
 
 	// Note that the uncompressed size as measured by the receiver
 	// will be different than uncompressed size as measured by the
 	// exporter, because of the optimization phase performed in the
-	// conversion to Arrow.
-	var uncompSize int
-	switch data := data.(type) {
-	case ptrace.Traces:
-		var sizer ptrace.ProtoMarshaler
-		uncompSize = sizer.TracesSize(data)
-	case plog.Logs:
-		var sizer plog.ProtoMarshaler
-		uncompSize = sizer.LogsSize(data)
-	case pmetric.Metrics:
-		var sizer pmetric.ProtoMarshaler
-		uncompSize = sizer.MetricsSize(data)
-	}
+	// conversion to Arrow.  When a batch processor has already
+	// measured this payload, the size is taken from the context.
+	uncompSize := pdatasize.Size(ctx, data)
 
 	if md == nil {
 		md = make(map[string]string)
 	}
-	md["otlp-pdata-size"] = strconv.Itoa(uncompSize)
+	md[pdataSizeHeader] = strconv.Itoa(uncompSize)
+
+	var idemKey, idemHash string
+	if e.idempotency != nil {
+		var err error
+		idemKey, idemHash, err = e.idempotency.key(data)
+		if err != nil {
+			e.enqueueFailed(ctx, data, ReasonIdempotencyKey)
+			return true, consumererror.NewPermanent(err)
+		}
+		md[idempotencyKeyHeader] = idemKey
+	}
+
+	// The batch is accounted for until it is acknowledged or
+	// fails, which applies backpressure when the streams hold
+	// too much memory.
+	if err := e.memory.acquire(ctx, int64(uncompSize)); err != nil {
+		e.enqueueFailed(ctx, data, ReasonMemoryLimit)
+		return true, err
+	}
+	defer e.memory.release(int64(uncompSize))
 
 	wri := writeItem{
 		records:     data,
@@ -308,17 +593,26 @@ func (e *Exporter) SendAndWait(ctx context.Context, data any) (bool, error) {
 	}
 
 	for {
-		writer := e.ready.nextWriter()
+		writer := e.ready.Load().nextWriter()
 
 		if writer == nil {
-			return false, nil // a downgraded connection
+			return false, arrowerrors.ErrDowngraded
 		}
 
 		err := writer.sendAndWait(ctx, errCh, wri)
-		if err != nil && errors.Is(err, ErrStreamRestarting) {
+		if err != nil && errors.Is(err, arrowerrors.ErrStreamRestarting) {
+			if !errors.Is(err, errNotEnqueued) {
+				e.sendFailed(ctx, data, ReasonStreamRestarting)
+			}
 			continue // an internal retry
 
 		}
+		if errors.Is(err, errNotEnqueued) {
+			e.enqueueFailed(ctx, data, ReasonCanceled)
+		}
+		if e.idempotency != nil {
+			e.idempotency.done(idemKey, idemHash, err)
+		}
 		// result from arrow server (may be nil, may be
 		// permanent, etc.)
 		return true, err
@@ -340,9 +634,12 @@ func waitForWrite(ctx context.Context, errCh <-chan error, down <-chan struct{})
 	select {
 	case <-ctx.Done():
 		// This caller's context timed out.
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %w", arrowerrors.ErrAckTimeout, ctx.Err())
+		}
 		return ctx.Err()
 	case <-down:
-		return ErrStreamRestarting
+		return arrowerrors.ErrStreamRestarting
 	case err := <-errCh:
 		// Note: includes err == nil and err != nil cases.
 		return err
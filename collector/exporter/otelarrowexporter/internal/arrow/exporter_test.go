@@ -14,13 +14,18 @@ import (
 	"time"
 
 	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
 	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/collector/pdatasize"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
 	"github.com/open-telemetry/otel-arrow/collector/testdata"
 	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
 	arrowRecordMock "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record/mock"
 	otelAssert "github.com/open-telemetry/otel-arrow/pkg/otel/assert"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -34,7 +39,7 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
-var AllPrioritizers = []PrioritizerName{LeastLoadedPrioritizer, LeastLoadedTwoPrioritizer}
+var AllPrioritizers = []PrioritizerName{LeastLoadedPrioritizer, LeastLoadedTwoPrioritizer, RoundRobinPrioritizer}
 
 const defaultMaxStreamLifetime = 11 * time.Second
 
@@ -152,7 +157,7 @@ func newExporterTestCaseCommon(t zaptest.TestingT, pname PrioritizerName, noisy
 		})
 	}
 
-	exp := NewExporter(maxLifetime, numStreams, pname, disableDowngrade, ctc.telset, nil, mockArrowProducer(ctc), ctc.traceClient, ctc.perRPCCredentials, netstats.Noop{})
+	exp := NewExporter(maxLifetime, numStreams, pname, disableDowngrade, ctc.telset, nil, mockArrowProducer(ctc), ctc.traceClient, ctc.perRPCCredentials, netstats.Noop{}, nil)
 
 	return &exporterTestCase{
 		commonTestCase: ctc,
@@ -300,7 +305,7 @@ func TestArrowExporterStreamConnectError(t *testing.T) {
 
 			sent, err := tc.exporter.SendAndWait(bg, twoTraces)
 			require.False(t, sent)
-			require.NoError(t, err)
+			require.ErrorIs(t, err, arrowerrors.ErrDowngraded)
 
 			require.NoError(t, tc.exporter.Shutdown(bg))
 
@@ -326,13 +331,14 @@ func TestArrowExporterDowngrade(t *testing.T) {
 
 			sent, err := tc.exporter.SendAndWait(bg, twoTraces)
 			require.False(t, sent)
-			require.NoError(t, err)
+			require.ErrorIs(t, err, arrowerrors.ErrDowngraded)
 
 			require.NoError(t, tc.exporter.Shutdown(bg))
 
 			require.Less(t, 1, len(tc.observedLogs.All()), "should have at least two logs: %v", tc.observedLogs.All())
 			require.Equal(t, tc.observedLogs.All()[0].Message, "arrow is not supported")
 			require.Contains(t, tc.observedLogs.All()[1].Message, "downgrading")
+			require.Equal(t, streamevents.Downgrade, tc.observedLogs.All()[1].ContextMap()[streamevents.EventKey])
 		})
 	}
 }
@@ -556,6 +562,89 @@ func TestArrowExporterStreaming(t *testing.T) {
 }
 
 // TestArrowExporterHeaders tests a mix of outgoing context headers.
+// TestArrowExporterStreamID verifies that each stream sends a new
+// identifier to the receiver.
+func TestArrowExporterStreamID(t *testing.T) {
+	tc := newSingleStreamTestCase(t, DefaultPrioritizer)
+	channel0 := newUnresponsiveTestChannel()
+	channel1 := newHealthyTestChannel()
+
+	var lock sync.Mutex
+	var ids []string
+	returnNew := tc.returnNewStream(channel0, channel1)
+	tc.traceCall.AnyTimes().DoAndReturn(func(ctx context.Context, opts ...grpc.CallOption) (
+		arrowpb.ArrowTracesService_ArrowTracesClient,
+		error,
+	) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		lock.Lock()
+		ids = append(ids, md.Get(streamevents.StreamIDHeader)...)
+		lock.Unlock()
+		return returnNew(ctx, opts...)
+	})
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	// The first stream ends, the second replaces it.
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		channel0.unblock()
+	}()
+	go func() {
+		data := <-channel1.sendChannel()
+		channel1.recv <- statusOKFor(data.BatchId)
+	}()
+
+	sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+	require.NoError(t, err)
+	require.True(t, sent)
+	require.NoError(t, tc.exporter.Shutdown(bg))
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Len(t, ids, 2)
+	require.Len(t, ids[0], 12)
+	require.NotEqual(t, ids[0], ids[1])
+}
+
+func TestArrowExporterStreamParams(t *testing.T) {
+	tc := newSingleStreamTestCase(t, DefaultPrioritizer)
+	channel := newHealthyTestChannel()
+	tc.traceCall.AnyTimes().DoAndReturn(tc.returnNewStream(channel))
+
+	status := arrowzpages.Register(component.KindExporter, "otelarrow/params")
+	defer status.Unregister()
+	tc.exporter.status = status
+	WithStreamParams(map[string]string{"compression": "zstd"})(tc.exporter)
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	// The parameters are observed while the stream is open.
+	paramsCh := make(chan map[string]string, 1)
+	go func() {
+		data := <-channel.sendChannel()
+		for _, st := range arrowzpages.Snapshot() {
+			if st.Name == "otelarrow/params" && len(st.Streams) == 1 {
+				paramsCh <- st.Streams[0].Params
+			}
+		}
+		close(paramsCh)
+		channel.recv <- statusOKFor(data.BatchId)
+	}()
+
+	sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+	require.NoError(t, err)
+	require.True(t, sent)
+	require.NoError(t, tc.exporter.Shutdown(bg))
+
+	require.Equal(t, map[string]string{
+		"compression":                  "zstd",
+		arrowzpages.SchemaVersionParam: "1",
+	}, <-paramsCh)
+}
+
 func TestArrowExporterHeaders(t *testing.T) {
 	tc := newSingleStreamMetadataTestCase(t)
 	channel := newHealthyTestChannel()
@@ -618,6 +707,61 @@ func TestArrowExporterHeaders(t *testing.T) {
 	require.NoError(t, tc.exporter.Shutdown(ctx))
 }
 
+// TestArrowExporterCachedSize tests that the uncompressed size is
+// taken from the context when the caller has already measured the
+// payload.
+func TestArrowExporterCachedSize(t *testing.T) {
+	tc := newSingleStreamTestCase(t, DefaultPrioritizer)
+	channel := newHealthyTestChannel()
+
+	tc.traceCall.AnyTimes().DoAndReturn(tc.returnNewStream(channel))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, tc.exporter.Start(ctx))
+
+	var actualOutput []metadata.MD
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		md := metadata.MD{}
+		hpd := hpack.NewDecoder(4096, func(f hpack.HeaderField) {
+			md[f.Name] = append(md[f.Name], f.Value)
+		})
+		for data := range channel.sendChannel() {
+			_, err := hpd.Write(data.Headers)
+			require.NoError(t, err)
+			actualOutput = append(actualOutput, md)
+			md = metadata.MD{}
+			channel.recv <- statusOKFor(data.BatchId)
+		}
+	}()
+
+	input := testdata.GenerateTraces(2)
+
+	// The cached size is used for the same payload.
+	sent, err := tc.exporter.SendAndWait(pdatasize.ContextWithSize(context.Background(), input, 1000), input)
+	require.NoError(t, err)
+	require.True(t, sent)
+
+	// The cached size of another payload is not.
+	sent, err = tc.exporter.SendAndWait(pdatasize.ContextWithSize(context.Background(), testdata.GenerateTraces(2), 1000), input)
+	require.NoError(t, err)
+	require.True(t, sent)
+
+	// Stop the test conduit started above.
+	cancel()
+	wg.Wait()
+
+	require.Equal(t, []metadata.MD{
+		{"otlp-pdata-size": []string{"1000"}},
+		{"otlp-pdata-size": []string{"329"}},
+	}, actualOutput)
+	require.NoError(t, tc.exporter.Shutdown(ctx))
+}
+
 // TestArrowExporterIsTraced tests whether trace and span ID are
 // propagated.
 func TestArrowExporterIsTraced(t *testing.T) {
@@ -778,6 +922,101 @@ func TestArrowExporterStreamLifetimeAndShutdown(t *testing.T) {
 	}
 }
 
+// TestArrowExporterSetStreams verifies that the number of streams and
+// their lifetime change while the exporter runs, without failing
+// exports.
+func TestArrowExporterSetStreams(t *testing.T) {
+	for _, pname := range AllPrioritizers {
+		t.Run(string(pname), func(t *testing.T) {
+			tc := newExporterTestCaseCommon(t, pname, NotNoisy, time.Hour, 1, false, nil)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var wg sync.WaitGroup
+			var opened, closed, received atomic.Int64
+
+			tc.traceCall.AnyTimes().DoAndReturn(func(ctx context.Context, opts ...grpc.CallOption) (
+				arrowpb.ArrowTracesService_ArrowTracesClient,
+				error,
+			) {
+				wg.Add(1)
+				opened.Add(1)
+				channel := newHealthyTestChannel()
+
+				go func() {
+					defer wg.Done()
+					for data := range channel.sendChannel() {
+						received.Add(1)
+						channel.recv <- statusOKFor(data.BatchId)
+					}
+					closed.Add(1)
+
+					// Closing the recv channel causes the exporter to see EOF.
+					close(channel.recv)
+				}()
+
+				return tc.returnNewStream(channel)(ctx, opts...)
+			})
+
+			require.NoError(t, tc.exporter.Start(ctx))
+
+			var sent int64
+			send := func() {
+				const senders = 8
+				var swg sync.WaitGroup
+				for i := 0; i < senders; i++ {
+					swg.Add(1)
+					go func() {
+						defer swg.Done()
+						ok, err := tc.exporter.SendAndWait(ctx, testdata.GenerateTraces(2))
+						require.NoError(t, err)
+						require.True(t, ok)
+					}()
+				}
+				swg.Wait()
+				sent += senders
+			}
+			streamsEqual := func(open, done int64) {
+				require.Eventually(t, func() bool {
+					return opened.Load() == open && closed.Load() == done
+				}, 5*time.Second, time.Millisecond)
+			}
+
+			send()
+			streamsEqual(1, 0)
+			require.Equal(t, 1, tc.exporter.Streams())
+
+			// Added streams start at once.
+			require.NoError(t, tc.exporter.SetStreams(4, time.Hour))
+			streamsEqual(4, 0)
+			require.Equal(t, 4, tc.exporter.Streams())
+			send()
+
+			// Removed streams finish and are not restarted.
+			require.NoError(t, tc.exporter.SetStreams(2, time.Hour))
+			streamsEqual(4, 2)
+			require.Equal(t, 2, tc.exporter.Streams())
+			send()
+
+			// A new lifetime restarts the remaining streams.
+			require.NoError(t, tc.exporter.SetStreams(2, time.Minute))
+			streamsEqual(6, 4)
+			send()
+
+			require.Error(t, tc.exporter.SetStreams(0, time.Minute))
+
+			require.NoError(t, tc.exporter.Shutdown(ctx))
+			require.Equal(t, arrowerrors.ErrDowngraded, tc.exporter.SetStreams(2, time.Minute))
+
+			cancel()
+			wg.Wait()
+
+			require.Equal(t, sent, received.Load())
+			require.Empty(t, tc.observedLogs.All())
+		})
+	}
+}
+
 func BenchmarkLeastLoadedTwo4(b *testing.B) {
 	benchmarkPrioritizer(b, 4, LeastLoadedTwoPrioritizer)
 }
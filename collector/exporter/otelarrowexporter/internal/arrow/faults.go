@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+)
+
+// Option configures optional Exporter behavior.
+type Option func(*Exporter)
+
+// StreamFaults are hooks into the stream reader and writer that
+// inject faults, so that tests can exercise retry and downgrade
+// logic deterministically.  The hooks are called from the stream's
+// own goroutines, with the stable stream identifier.  They are not
+// intended for production use.
+type StreamFaults struct {
+	// Send, if set, is called before each batch is sent.  A
+	// non-nil error is returned by the writer in place of
+	// sending, as if the stream had been reset.  The batch's
+	// buffers are reused after the hook returns.
+	Send func(streamID string, batch *arrowpb.BatchArrowRecords) error
+
+	// Recv, if set, is called with each result of Recv() before
+	// the reader processes it.  It may sleep to delay an
+	// acknowledgement, return a modified status (e.g., with a
+	// corrupted status code or batch ID), or replace the error.
+	Recv func(streamID string, status *arrowpb.BatchStatus, err error) (*arrowpb.BatchStatus, error)
+}
+
+// WithStreamFaults installs fault-injection hooks in every stream.
+func WithStreamFaults(faults StreamFaults) Option {
+	return func(e *Exporter) {
+		e.faults = faults
+	}
+}
+
+func (f StreamFaults) beforeSend(streamID string, batch *arrowpb.BatchArrowRecords) error {
+	if f.Send == nil {
+		return nil
+	}
+	return f.Send(streamID, batch)
+}
+
+func (f StreamFaults) afterRecv(streamID string, status *arrowpb.BatchStatus, err error) (*arrowpb.BatchStatus, error) {
+	if f.Recv == nil {
+		return status, err
+	}
+	return f.Recv(streamID, status, err)
+}
@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newFaultsTestCase returns a single-stream exporter with the given
+// faults, connected to healthy channels that acknowledge every batch.
+func newFaultsTestCase(t *testing.T, faults StreamFaults) *exporterTestCase {
+	ctc := newCommonTestCase(t, NotNoisy)
+	ctc.requestMetadataCall.AnyTimes().Return(nil, nil)
+	ctc.traceCall.AnyTimes().DoAndReturn(ctc.repeatedNewStream(func() testChannel {
+		ch := newHealthyTestChannel()
+		go func() {
+			for batch := range ch.sent {
+				ch.recv <- statusOKFor(batch.BatchId)
+			}
+		}()
+		return ch
+	}))
+
+	exp := NewExporter(defaultMaxStreamLifetime, 1, DefaultPrioritizer, false, ctc.telset, nil, mockArrowProducer(ctc), ctc.traceClient, ctc.perRPCCredentials, netstats.Noop{}, nil, WithStreamFaults(faults))
+	return &exporterTestCase{
+		commonTestCase: ctc,
+		exporter:       exp,
+	}
+}
+
+// TestStreamFaultsSendReset injects a stream reset on the first send
+// and checks that the request is retried on a new stream.
+func TestStreamFaultsSendReset(t *testing.T) {
+	var sends atomic.Int64
+	tc := newFaultsTestCase(t, StreamFaults{
+		Send: func(_ string, _ *arrowpb.BatchArrowRecords) error {
+			if sends.Add(1) == 1 {
+				return status.Error(codes.Unavailable, "injected reset")
+			}
+			return nil
+		},
+	})
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+	require.True(t, sent)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), sends.Load())
+
+	require.NoError(t, tc.exporter.Shutdown(bg))
+}
+
+// TestStreamFaultsCorruptStatus replaces the status code of the
+// acknowledgement with one the exporter does not recognize.
+func TestStreamFaultsCorruptStatus(t *testing.T) {
+	tc := newFaultsTestCase(t, StreamFaults{
+		Recv: func(_ string, st *arrowpb.BatchStatus, err error) (*arrowpb.BatchStatus, error) {
+			if err == nil {
+				st = statusUnrecognizedFor(st.BatchId)
+			}
+			return st, err
+		},
+	})
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+	require.True(t, sent)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "test unrecognized")
+
+	require.NoError(t, tc.exporter.Shutdown(bg))
+}
+
+// TestStreamFaultsDelayedAck delays acknowledgements past the
+// caller's deadline.
+func TestStreamFaultsDelayedAck(t *testing.T) {
+	tc := newFaultsTestCase(t, StreamFaults{
+		Recv: func(_ string, st *arrowpb.BatchStatus, err error) (*arrowpb.BatchStatus, error) {
+			time.Sleep(200 * time.Millisecond)
+			return st, err
+		},
+	})
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	ctx, cancel := context.WithTimeout(bg, 50*time.Millisecond)
+	defer cancel()
+	sent, err := tc.exporter.SendAndWait(ctx, twoTraces)
+	require.True(t, sent)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.ErrorIs(t, err, arrowerrors.ErrAckTimeout)
+
+	require.NoError(t, tc.exporter.Shutdown(bg))
+}
+
+// TestStreamFaultsRecvUnimplemented injects the error returned by a
+// server without Arrow support, which causes downgrade.
+func TestStreamFaultsRecvUnimplemented(t *testing.T) {
+	tc := newFaultsTestCase(t, StreamFaults{
+		Recv: func(_ string, _ *arrowpb.BatchStatus, _ error) (*arrowpb.BatchStatus, error) {
+			return nil, status.Error(codes.Unimplemented, "injected unimplemented")
+		},
+	})
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+	require.False(t, sent)
+	require.ErrorIs(t, err, arrowerrors.ErrDowngraded)
+
+	require.NoError(t, tc.exporter.Shutdown(bg))
+}
@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+)
+
+// streamHealth reports the health of the exporter's streams through
+// the collector's component status API: StatusRecoverableError when
+// a stream fails while no other stream is open, or when the exporter
+// downgrades, and StatusOK once a stream receives its first OK
+// status, including after the exporter tries Arrow again following a
+// downgrade, since a stream may open and then have every batch
+// rejected, e.g., when unauthenticated.  Only changes
+// are reported.  Streams that end without error, e.g., at their
+// maximum lifetime, do not affect the status.  The methods are safe
+// to call on a nil *streamHealth.
+type streamHealth struct {
+	report func(*component.StatusEvent)
+
+	lock sync.Mutex
+	// open is the number of open streams.
+	open int
+	// failing is set after StatusRecoverableError is reported.
+	failing bool
+	// downgraded is set when the exporter stops using Arrow,
+	// until it tries Arrow again, see WithDowngradeRetry.
+	downgraded bool
+}
+
+// newStreamHealth returns the health of streams reported by report,
+// nil when report is nil.
+func newStreamHealth(report func(*component.StatusEvent)) *streamHealth {
+	if report == nil {
+		return nil
+	}
+	return &streamHealth{report: report}
+}
+
+// streamStarted is called when a stream opens.
+func (h *streamHealth) streamStarted() {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.open++
+}
+
+// streamSucceeded is called when an open stream receives its first
+// OK status.
+func (h *streamHealth) streamSucceeded() {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.failing && !h.downgraded {
+		h.failing = false
+		h.report(component.NewStatusEvent(component.StatusOK))
+	}
+}
+
+// streamEnded is called when an open stream ends, with the error
+// that ended it, if any.
+func (h *streamHealth) streamEnded(err error) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.open--
+	h.failedLocked(err)
+}
+
+// streamFailed is called when a stream fails to open.
+func (h *streamHealth) streamFailed(err error) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.failedLocked(err)
+}
+
+func (h *streamHealth) failedLocked(err error) {
+	if err == nil || h.open != 0 || h.failing || h.downgraded {
+		return
+	}
+	h.failing = true
+	h.report(component.NewRecoverableErrorEvent(err))
+}
+
+// setDowngraded is called when the exporter downgrades to standard
+// OTLP.
+func (h *streamHealth) setDowngraded() {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.downgraded {
+		return
+	}
+	h.downgraded = true
+	h.failing = true
+	h.report(component.NewRecoverableErrorEvent(arrowerrors.ErrDowngraded))
+}
+
+// setUpgraded is called when the exporter tries Arrow again after a
+// downgrade.  The status remains StatusRecoverableError until a
+// stream receives an OK status.
+func (h *streamHealth) setUpgraded() {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.downgraded = false
+}
@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"google.golang.org/grpc"
+)
+
+// statusEvents records the reported component statuses.
+type statusEvents struct {
+	lock   sync.Mutex
+	events []*component.StatusEvent
+}
+
+func (se *statusEvents) report(ev *component.StatusEvent) {
+	se.lock.Lock()
+	defer se.lock.Unlock()
+	se.events = append(se.events, ev)
+}
+
+func (se *statusEvents) statuses() (st []component.Status) {
+	se.lock.Lock()
+	defer se.lock.Unlock()
+	for _, ev := range se.events {
+		st = append(st, ev.Status())
+	}
+	return st
+}
+
+func TestStreamHealth(t *testing.T) {
+	var se statusEvents
+	h := newStreamHealth(se.report)
+	testErr := errors.New("test")
+
+	// Two streams open, one fails.
+	h.streamStarted()
+	h.streamStarted()
+	h.streamEnded(testErr)
+	require.Empty(t, se.statuses())
+
+	// The last one ends gracefully, then fails to restart.
+	h.streamEnded(nil)
+	require.Empty(t, se.statuses())
+	h.streamFailed(testErr)
+	h.streamFailed(testErr)
+	require.Equal(t, []component.Status{component.StatusRecoverableError}, se.statuses())
+	require.Equal(t, testErr, se.events[0].Err())
+
+	// A stream opens again, which is OK only once it receives an
+	// OK status.
+	h.streamStarted()
+	h.streamStarted()
+	require.Equal(t, []component.Status{component.StatusRecoverableError}, se.statuses())
+	h.streamSucceeded()
+	h.streamSucceeded()
+	require.Equal(t, []component.Status{component.StatusRecoverableError, component.StatusOK}, se.statuses())
+
+	// Downgrade is final.
+	h.streamEnded(nil)
+	h.streamEnded(nil)
+	h.setDowngraded()
+	h.setDowngraded()
+	h.streamStarted()
+	h.streamSucceeded()
+	require.Equal(t, []component.Status{component.StatusRecoverableError, component.StatusOK, component.StatusRecoverableError}, se.statuses())
+	require.ErrorIs(t, se.events[2].Err(), arrowerrors.ErrDowngraded)
+
+	// A nil health reports nothing.
+	require.Nil(t, newStreamHealth(nil))
+	var nh *streamHealth
+	nh.streamStarted()
+	nh.streamSucceeded()
+	nh.streamFailed(testErr)
+	nh.setDowngraded()
+}
+
+func TestArrowExporterHealthRecovers(t *testing.T) {
+	tc := newSingleStreamDowngradeDisabledTestCase(t, DefaultPrioritizer)
+	var se statusEvents
+	tc.exporter.health = newStreamHealth(se.report)
+
+	badChannel := newConnectErrorTestChannel()
+	goodChannel := newHealthyTestChannel()
+
+	fails := 0
+	tc.traceCall.AnyTimes().DoAndReturn(func(ctx context.Context, opts ...grpc.CallOption) (
+		arrowpb.ArrowTracesService_ArrowTracesClient,
+		error,
+	) {
+		defer func() { fails++ }()
+
+		if fails < 2 {
+			return tc.returnNewStream(badChannel)(ctx, opts...)
+		}
+		return tc.returnNewStream(goodChannel)(ctx, opts...)
+	})
+
+	go func() {
+		data := <-goodChannel.sendChannel()
+		goodChannel.recv <- statusOKFor(data.BatchId)
+	}()
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+	require.NoError(t, err)
+	require.True(t, sent)
+	require.NoError(t, tc.exporter.Shutdown(bg))
+
+	// The failures are reported once, shutdown is not.
+	require.Equal(t, []component.Status{component.StatusRecoverableError, component.StatusOK}, se.statuses())
+}
+
+func TestArrowExporterHealthRejected(t *testing.T) {
+	tc := newSingleStreamDowngradeDisabledTestCase(t, DefaultPrioritizer)
+	var se statusEvents
+	tc.exporter.health = newStreamHealth(se.report)
+
+	badChannel := newConnectErrorTestChannel()
+	rejectChannel := newHealthyTestChannel()
+
+	fails := 0
+	tc.traceCall.AnyTimes().DoAndReturn(func(ctx context.Context, opts ...grpc.CallOption) (
+		arrowpb.ArrowTracesService_ArrowTracesClient,
+		error,
+	) {
+		defer func() { fails++ }()
+
+		if fails < 1 {
+			return tc.returnNewStream(badChannel)(ctx, opts...)
+		}
+		return tc.returnNewStream(rejectChannel)(ctx, opts...)
+	})
+
+	go func() {
+		data := <-rejectChannel.sendChannel()
+		rejectChannel.recv <- statusInvalidFor(data.BatchId)
+	}()
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	// The stream opened, but its batch was rejected.
+	sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+	require.Error(t, err)
+	require.True(t, sent)
+	require.NoError(t, tc.exporter.Shutdown(bg))
+
+	require.Equal(t, []component.Status{component.StatusRecoverableError}, se.statuses())
+}
+
+func TestArrowExporterHealthDowngrade(t *testing.T) {
+	tc := newSingleStreamTestCase(t, DefaultPrioritizer)
+	var se statusEvents
+	tc.exporter.health = newStreamHealth(se.report)
+	channel := newArrowUnsupportedTestChannel()
+
+	tc.traceCall.AnyTimes().DoAndReturn(tc.returnNewStream(channel))
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+	require.False(t, sent)
+	require.ErrorIs(t, err, arrowerrors.ErrDowngraded)
+	require.NoError(t, tc.exporter.Shutdown(bg))
+
+	require.Equal(t, []component.Status{component.StatusRecoverableError}, se.statuses())
+}
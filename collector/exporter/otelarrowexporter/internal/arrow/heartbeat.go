@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+)
+
+// WithHeartbeat sends a heartbeat on every stream that has not sent
+// a batch for the interval.  A heartbeat is a batch without
+// payloads, which the receiver answers like any other batch, so its
+// answer may queue behind the batches being consumed.  When no
+// status at all arrives by the next interval after a heartbeat, the
+// peer is presumed dead, e.g., behind a half-open TCP connection,
+// and the stream restarts.  Zero disables heartbeats.
+func WithHeartbeat(interval time.Duration) Option {
+	return func(e *Exporter) {
+		e.heartbeatInterval = interval
+	}
+}
+
+// heartbeat is the heartbeat state of one stream, used by its
+// writer.  A nil *heartbeat sends no heartbeats.
+type heartbeat struct {
+	interval time.Duration
+	timer    *time.Timer
+
+	// batchID is the ID of the last heartbeat.  Heartbeats use
+	// negative IDs, which the producer does not assign.
+	batchID int64
+
+	// errCh receives the response to the last heartbeat, nil
+	// before the first.
+	errCh chan error
+
+	// statusCount is the stream's count of statuses received, and
+	// lastCount its value when the last heartbeat was sent or
+	// found unanswered.
+	statusCount *atomic.Uint64
+	lastCount   uint64
+}
+
+// newHeartbeat returns the heartbeat of the stream, nil when
+// heartbeats are disabled.
+func (s *Stream) newHeartbeat() *heartbeat {
+	if s.heartbeatInterval <= 0 {
+		return nil
+	}
+	return &heartbeat{
+		interval:    s.heartbeatInterval,
+		timer:       time.NewTimer(s.heartbeatInterval),
+		statusCount: &s.statusCount,
+	}
+}
+
+// C returns the channel that fires when a heartbeat is due.
+func (h *heartbeat) C() <-chan time.Time {
+	if h == nil {
+		return nil
+	}
+	return h.timer.C
+}
+
+// stop releases the heartbeat's timer.
+func (h *heartbeat) stop() {
+	if h == nil {
+		return
+	}
+	h.timer.Stop()
+}
+
+// reset restarts the interval, after a batch was sent.
+func (h *heartbeat) reset() {
+	if h == nil {
+		return
+	}
+	if !h.timer.Stop() {
+		select {
+		case <-h.timer.C:
+		default:
+		}
+	}
+	h.timer.Reset(h.interval)
+}
+
+// next returns the next heartbeat to send, after C() fired.  Any
+// response counts as an answer, including the error returned by
+// receivers that do not recognize heartbeats.  When the last
+// heartbeat was not answered but other statuses arrived since, the
+// receiver is alive and its answer is queued behind batches, so
+// next returns false to send nothing and wait another interval.
+// Without any status, next returns arrowerrors.ErrHeartbeatTimeout.
+func (h *heartbeat) next() (writeItem, bool, error) {
+	count := h.statusCount.Load()
+	if h.errCh != nil {
+		select {
+		case <-h.errCh:
+		default:
+			if count == h.lastCount {
+				return writeItem{}, false, arrowerrors.ErrHeartbeatTimeout
+			}
+			h.lastCount = count
+			h.timer.Reset(h.interval)
+			return writeItem{}, false, nil
+		}
+	}
+	h.lastCount = count
+	h.batchID--
+	h.errCh = make(chan error, 1)
+	h.timer.Reset(h.interval)
+	return writeItem{
+		heartbeatID: h.batchID,
+		errCh:       h.errCh,
+		producerCtx: context.Background(),
+	}, true, nil
+}
+
+// sendHeartbeat sends a batch without payloads.
+func (s *Stream) sendHeartbeat(wri writeItem) error {
+	batch := &arrowpb.BatchArrowRecords{
+		BatchId: wri.heartbeatID,
+	}
+	s.setBatchChannel(batch.BatchId, wri.errCh)
+	s.recorder.recordSend(s.workState.id, batch.BatchId)
+
+	return s.send(batch)
+}
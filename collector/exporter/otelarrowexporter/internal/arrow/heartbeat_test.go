@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+)
+
+func TestStreamHeartbeat(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	tc.stream.heartbeatInterval = 10 * time.Millisecond
+
+	channel := newHealthyTestChannel()
+	tc.start(channel)
+	defer tc.cancelAndWaitForShutdown()
+
+	for id := int64(-1); id >= -3; id-- {
+		batch := <-channel.sent
+		require.Equal(t, id, batch.BatchId)
+		require.Empty(t, batch.ArrowPayloads)
+
+		channel.recv <- statusOKFor(id)
+	}
+}
+
+func TestStreamHeartbeatTimeout(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	tc.stream.heartbeatInterval = 10 * time.Millisecond
+
+	// The stream ends itself when its heartbeat is not answered.
+	tc.start(newUnresponsiveTestChannel())
+	tc.waitForShutdown()
+
+	logs := tc.observedLogs.FilterMessage("arrow stream error").All()
+	require.Len(t, logs, 1)
+	require.Equal(t, "heartbeat not answered", logs[0].ContextMap()["message"])
+}
+
+func TestHeartbeatQueuedAnswer(t *testing.T) {
+	s := &Stream{heartbeatInterval: time.Hour}
+	hb := s.newHeartbeat()
+	defer hb.stop()
+
+	beat, ok, err := hb.next()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int64(-1), beat.heartbeatID)
+
+	// The answer is queued behind batches whose statuses arrive,
+	// so the heartbeat is not missed and none is sent.
+	s.statusCount.Add(1)
+	_, ok, err = hb.next()
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Without any status for an interval, the heartbeat is missed.
+	_, _, err = hb.next()
+	require.ErrorIs(t, err, arrowerrors.ErrHeartbeatTimeout)
+
+	// Once answered, the next heartbeat is sent.
+	beat.errCh <- nil
+	beat, ok, err = hb.next()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int64(-2), beat.heartbeatID)
+}
+
+func TestHeartbeatDisabled(t *testing.T) {
+	var hb *heartbeat
+	require.Nil(t, hb.C())
+	hb.reset()
+	hb.stop()
+
+	require.Nil(t, (&Stream{}).newHeartbeat())
+}
@@ -0,0 +1,122 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+)
+
+// idempotencyKeyHeader conveys the idempotency key of a batch to the
+// receiver, which uses it to recognize retried batches.
+const idempotencyKeyHeader = "otlp-idempotency-key"
+
+// maxRetryingKeys bounds the keys remembered for batches awaiting a
+// retry.  Beyond it, the retries of some batches get new keys.
+const maxRetryingKeys = 4096
+
+// WithIdempotencyKeys attaches an idempotency key to every batch.
+// The key is a nonce, random per exporter, and a sequence number,
+// so that batches of identical content, and the batches of other
+// exporters, have different keys.  A batch that fails with a
+// retryable error keeps its key for the next attempt, on any
+// stream.  Batches with keys are not coalesced, since their keys
+// differ.
+func WithIdempotencyKeys() Option {
+	return func(e *Exporter) {
+		e.idempotency = newIdempotencyKeys()
+	}
+}
+
+// idempotencyKeys assigns the keys of batches.  The exporter helper
+// retries a batch without telling the exporter, so an attempt is
+// recognized as a retry by the content hash of a batch whose last
+// attempt failed with a retryable error.
+type idempotencyKeys struct {
+	nonce string
+
+	lock sync.Mutex
+	seq  uint64
+	// retrying maps the content hash of the batches that await
+	// a retry to the key of their last attempt.
+	retrying map[string]string
+}
+
+func newIdempotencyKeys() *idempotencyKeys {
+	var nonce [8]byte
+	_, _ = rand.Read(nonce[:])
+	return &idempotencyKeys{
+		nonce:    hex.EncodeToString(nonce[:]),
+		retrying: map[string]string{},
+	}
+}
+
+// key returns the idempotency key of data and its content hash, to
+// be passed to done with the result of the attempt.  The key of a
+// retried batch is taken once, so that an identical batch sent
+// concurrently gets a new key.
+func (k *idempotencyKeys) key(data any) (key, hash string, err error) {
+	hash, err = contentHash(data)
+	if err != nil {
+		return "", "", err
+	}
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if key, ok := k.retrying[hash]; ok {
+		delete(k.retrying, hash)
+		return key, hash, nil
+	}
+	k.seq++
+	return fmt.Sprintf("%s-%d", k.nonce, k.seq), hash, nil
+}
+
+// done remembers the key of a batch that failed with a retryable
+// error, for its next attempt.
+func (k *idempotencyKeys) done(key, hash string, err error) {
+	if err == nil || consumererror.IsPermanent(err) {
+		return
+	}
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if len(k.retrying) >= maxRetryingKeys {
+		// Evict an arbitrary key; its batch is sent again
+		// with a new key, as without deduplication.
+		for h := range k.retrying {
+			delete(k.retrying, h)
+			break
+		}
+	}
+	k.retrying[hash] = key
+}
+
+// contentHash returns a hash of the OTLP encoding of data.
+func contentHash(data any) (string, error) {
+	var buf []byte
+	var err error
+	switch items := data.(type) {
+	case ptrace.Traces:
+		buf, err = (&ptrace.ProtoMarshaler{}).MarshalTraces(items)
+	case plog.Logs:
+		buf, err = (&plog.ProtoMarshaler{}).MarshalLogs(items)
+	case pmetric.Metrics:
+		buf, err = (&pmetric.ProtoMarshaler{}).MarshalMetrics(items)
+	default:
+		return "", fmt.Errorf("%w: unsupported type %T", arrowerrors.ErrEncode, data)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", arrowerrors.ErrEncode, err)
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:16]), nil
+}
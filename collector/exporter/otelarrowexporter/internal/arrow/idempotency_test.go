@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	keys := newIdempotencyKeys()
+
+	first, hash, err := keys.key(twoTraces)
+	require.NoError(t, err)
+	keys.done(first, hash, nil)
+
+	// A batch of identical content sent again is a new batch.
+	copied := ptrace.NewTraces()
+	twoTraces.CopyTo(copied)
+	second, hash, err := keys.key(copied)
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+
+	// A retryable failure keeps the key for the retry, once.
+	keys.done(second, hash, errors.New("unavailable"))
+	retry, hash, err := keys.key(twoTraces)
+	require.NoError(t, err)
+	require.Equal(t, second, retry)
+	concurrent, _, err := keys.key(twoTraces)
+	require.NoError(t, err)
+	require.NotEqual(t, retry, concurrent)
+
+	// A permanent failure is not retried.
+	keys.done(retry, hash, consumererror.NewPermanent(errors.New("invalid")))
+	next, _, err := keys.key(twoTraces)
+	require.NoError(t, err)
+	require.NotEqual(t, retry, next)
+
+	// Other exporters use other nonces.
+	other, _, err := newIdempotencyKeys().key(twoTraces)
+	require.NoError(t, err)
+	require.NotEqual(t, first, other)
+
+	_, _, err = keys.key("unknown")
+	require.ErrorIs(t, err, arrowerrors.ErrEncode)
+}
+
+func TestIdempotencyKeysBounded(t *testing.T) {
+	keys := newIdempotencyKeys()
+	for i := 0; i < maxRetryingKeys+10; i++ {
+		keys.done("key", string(rune(i)), errors.New("unavailable"))
+	}
+	require.Len(t, keys.retrying, maxRetryingKeys)
+}
@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v14/arrow/memory"
+)
+
+// MemoryLimiter accounts for the memory held by an exporter's Arrow
+// streams and applies backpressure to senders when it exceeds a
+// limit.  Two quantities are counted:
+//
+//   - the uncompressed size of every batch from the time it is
+//     queued for a stream until it is acknowledged or fails, which
+//     covers queued write items and encoded but unacknowledged
+//     batches, since the sender holds its data until then;
+//   - the Arrow buffers allocated by the producers through
+//     Allocator, including builders, dictionaries and retained
+//     buffers.
+//
+// A sender waits until the sum of both, plus the size of its batch,
+// is within the limit.  A batch larger than the limit is admitted
+// when no other batch is held, so that it cannot wait forever.
+type MemoryLimiter struct {
+	limit int64
+
+	// alloc counts the producers' Arrow buffers.
+	alloc *countingAllocator
+
+	lock sync.Mutex
+	// held is the size of the batches admitted and not yet
+	// released.
+	held int64
+	// released is closed and replaced each time memory is
+	// released, to wake the waiting senders.
+	released chan struct{}
+}
+
+// NewMemoryLimiter returns a MemoryLimiter with a limit in bytes.
+func NewMemoryLimiter(limit int64) *MemoryLimiter {
+	return &MemoryLimiter{
+		limit:    limit,
+		alloc:    &countingAllocator{Allocator: memory.NewGoAllocator()},
+		released: make(chan struct{}),
+	}
+}
+
+// WithMemoryLimiter accounts for the memory of the exporter's
+// streams with a limiter.  The producers should allocate with the
+// limiter's Allocator.
+func WithMemoryLimiter(ml *MemoryLimiter) Option {
+	return func(e *Exporter) {
+		e.memory = ml
+	}
+}
+
+// Allocator returns the allocator for Arrow producers, which counts
+// their buffers against the limit.
+func (ml *MemoryLimiter) Allocator() memory.Allocator {
+	return ml.alloc
+}
+
+// Inuse returns the number of bytes currently accounted for.
+func (ml *MemoryLimiter) Inuse() int64 {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+	return ml.held + ml.alloc.inuse.Load()
+}
+
+// acquire waits until size bytes fit within the limit, or the
+// context is done.  Each successful call must be paired with
+// release.  A nil limiter admits everything.
+func (ml *MemoryLimiter) acquire(ctx context.Context, size int64) error {
+	if ml == nil {
+		return nil
+	}
+	for {
+		ml.lock.Lock()
+		if ml.held == 0 || ml.held+ml.alloc.inuse.Load()+size <= ml.limit {
+			ml.held += size
+			ml.lock.Unlock()
+			return nil
+		}
+		// Producer buffers are freed without notice, but each
+		// batch that is released re-checks the limit.
+		released := ml.released
+		ml.lock.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// tryAcquire is acquire without waiting: it returns false when
+// size bytes do not fit within the limit.  A nil limiter admits
+// everything.
+func (ml *MemoryLimiter) tryAcquire(size int64) bool {
+	if ml == nil {
+		return true
+	}
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+	if ml.held+ml.alloc.inuse.Load()+size > ml.limit {
+		return false
+	}
+	ml.held += size
+	return true
+}
+
+// release returns size bytes and wakes the waiting senders.
+func (ml *MemoryLimiter) release(size int64) {
+	if ml == nil {
+		return
+	}
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+	ml.held -= size
+	close(ml.released)
+	ml.released = make(chan struct{})
+}
+
+// countingAllocator counts the bytes allocated and not freed.  It is
+// shared by the producers of all streams.
+type countingAllocator struct {
+	memory.Allocator
+	inuse atomic.Int64
+}
+
+var _ memory.Allocator = &countingAllocator{}
+
+func (c *countingAllocator) Allocate(size int) []byte {
+	b := c.Allocator.Allocate(size)
+	c.inuse.Add(int64(len(b)))
+	return b
+}
+
+func (c *countingAllocator) Reallocate(size int, b []byte) []byte {
+	old := len(b)
+	b = c.Allocator.Reallocate(size, b)
+	c.inuse.Add(int64(len(b) - old))
+	return b
+}
+
+func (c *countingAllocator) Free(b []byte) {
+	c.inuse.Add(-int64(len(b)))
+	c.Allocator.Free(b)
+}
@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiterBackpressure(t *testing.T) {
+	ml := NewMemoryLimiter(1000)
+	ctx := context.Background()
+
+	require.NoError(t, ml.acquire(ctx, 600))
+	require.Equal(t, int64(600), ml.Inuse())
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- ml.acquire(ctx, 600)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired beyond the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	ml.release(600)
+	require.NoError(t, <-acquired)
+	require.Equal(t, int64(600), ml.Inuse())
+
+	ml.release(600)
+	require.Equal(t, int64(0), ml.Inuse())
+}
+
+func TestMemoryLimiterCanceled(t *testing.T) {
+	ml := NewMemoryLimiter(1000)
+	require.NoError(t, ml.acquire(context.Background(), 1000))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, ml.acquire(ctx, 1), context.DeadlineExceeded)
+
+	ml.release(1000)
+	require.Equal(t, int64(0), ml.Inuse())
+}
+
+func TestMemoryLimiterOversized(t *testing.T) {
+	ml := NewMemoryLimiter(1000)
+
+	// A batch larger than the limit is admitted alone.
+	require.NoError(t, ml.acquire(context.Background(), 5000))
+	ml.release(5000)
+}
+
+func TestMemoryLimiterAllocator(t *testing.T) {
+	ml := NewMemoryLimiter(1000)
+	alloc := ml.Allocator()
+
+	b := alloc.Allocate(400)
+	require.Equal(t, int64(400), ml.Inuse())
+	b = alloc.Reallocate(700, b)
+	require.Equal(t, int64(700), ml.Inuse())
+
+	// The producer buffers count against the limit.
+	require.NoError(t, ml.acquire(context.Background(), 100))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, ml.acquire(ctx, 300), context.DeadlineExceeded)
+	ml.release(100)
+
+	alloc.Free(b)
+	require.Equal(t, int64(0), ml.Inuse())
+}
+
+func TestMemoryLimiterNil(t *testing.T) {
+	var ml *MemoryLimiter
+	require.NoError(t, ml.acquire(context.Background(), 1<<40))
+	ml.release(1 << 40)
+}
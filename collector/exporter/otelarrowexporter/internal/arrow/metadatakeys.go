@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/collector/client"
+)
+
+// WithMetadataKeys attaches the values of the client.Metadata keys
+// of each request to its batch, as headers.  Batches with different
+// values are not coalesced.
+func WithMetadataKeys(keys []string) Option {
+	return func(e *Exporter) {
+		for _, k := range keys {
+			e.metadataKeys = append(e.metadataKeys, strings.ToLower(k))
+		}
+	}
+}
+
+// addClientMetadata adds the values of the metadata keys in the client
+// information of ctx to md, except for the keys that md has already,
+// e.g., set by the per-RPC credentials.  Multiple values of a key are
+// joined with commas, as in an HTTP field.
+func (e *Exporter) addClientMetadata(ctx context.Context, md map[string]string) map[string]string {
+	if len(e.metadataKeys) == 0 {
+		return md
+	}
+	info := client.FromContext(ctx)
+	for _, k := range e.metadataKeys {
+		vs := info.Metadata.Get(k)
+		if len(vs) == 0 {
+			continue
+		}
+		if _, ok := md[k]; ok {
+			continue
+		}
+		if md == nil {
+			md = make(map[string]string)
+		}
+		md[k] = strings.Join(vs, ",")
+	}
+	return md
+}
+
+// metadataFilter restricts the metadata keys sent as batch headers.  A
+// nil filter sends every key.
+type metadataFilter struct {
+	// allow, when not empty, are the only keys sent.
+	allow map[string]bool
+	// deny are never sent.
+	deny map[string]bool
+}
+
+// WithMetadataFilter restricts the metadata of the per-RPC credentials
+// and of WithMetadataKeys that is sent as batch headers to the
+// allowed keys, if any, except the denied keys.  Keys are
+// case-insensitive.  The headers of the protocol itself, e.g., the
+// uncompressed size, are always sent.
+func WithMetadataFilter(allowed, denied []string) Option {
+	return func(e *Exporter) {
+		if len(allowed) == 0 && len(denied) == 0 {
+			return
+		}
+		e.metadataFilter = &metadataFilter{
+			allow: lowerSet(allowed),
+			deny:  lowerSet(denied),
+		}
+	}
+}
+
+func lowerSet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = true
+	}
+	return set
+}
+
+// apply removes the keys that the filter does not permit from md.
+func (f *metadataFilter) apply(md map[string]string) {
+	if f == nil {
+		return
+	}
+	for k := range md {
+		lk := strings.ToLower(k)
+		if f.deny[lk] || (len(f.allow) != 0 && !f.allow[lk]) {
+			delete(md, k)
+		}
+	}
+}
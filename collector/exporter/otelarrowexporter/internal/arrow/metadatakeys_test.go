@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"golang.org/x/net/http2/hpack"
+	"google.golang.org/grpc/metadata"
+)
+
+func withTenant(ctx context.Context, tenants ...string) context.Context {
+	return client.NewContext(ctx, client.Info{
+		Metadata: client.NewMetadata(map[string][]string{"x-tenant": tenants}),
+	})
+}
+
+func TestAddClientMetadata(t *testing.T) {
+	e := &Exporter{}
+	WithMetadataKeys([]string{"X-Tenant", "x-region"})(e)
+	require.Equal(t, []string{"x-tenant", "x-region"}, e.metadataKeys)
+
+	bg := context.Background()
+	require.Nil(t, e.addClientMetadata(bg, nil))
+	require.Equal(t, map[string]string{"x-tenant": "a"}, e.addClientMetadata(withTenant(bg, "a"), nil))
+	require.Equal(t, map[string]string{"x-tenant": "a,b"}, e.addClientMetadata(withTenant(bg, "a", "b"), nil))
+
+	// The per-RPC credentials take precedence.
+	require.Equal(t, map[string]string{"x-tenant": "creds"}, e.addClientMetadata(withTenant(bg, "a"), map[string]string{"x-tenant": "creds"}))
+}
+
+func TestArrowExporterMetadataKeys(t *testing.T) {
+	tc := newSingleStreamTestCase(t, DefaultPrioritizer)
+	channel := newHealthyTestChannel()
+	tc.traceCall.AnyTimes().DoAndReturn(tc.returnNewStream(channel))
+	WithMetadataKeys([]string{"X-Tenant"})(tc.exporter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, tc.exporter.Start(ctx))
+
+	var actualOutput []string
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		hpd := hpack.NewDecoder(4096, nil)
+		for data := range channel.sendChannel() {
+			md := metadata.MD{}
+			hpd.SetEmitFunc(func(f hpack.HeaderField) {
+				md[f.Name] = append(md[f.Name], f.Value)
+			})
+			_, err := hpd.Write(data.Headers)
+			require.NoError(t, err)
+			actualOutput = append(actualOutput, md.Get("x-tenant")...)
+			channel.recv <- statusOKFor(data.BatchId)
+		}
+	}()
+
+	for _, tenant := range []string{"a", "b", "a"} {
+		sent, err := tc.exporter.SendAndWait(withTenant(ctx, tenant), testdata.GenerateTraces(2))
+		require.NoError(t, err)
+		require.True(t, sent)
+	}
+	cancel()
+	wg.Wait()
+
+	require.Equal(t, []string{"a", "b", "a"}, actualOutput)
+	require.NoError(t, tc.exporter.Shutdown(ctx))
+}
+
+func TestMetadataFilter(t *testing.T) {
+	md := func() map[string]string {
+		return map[string]string{"Authorization": "x", "x-tenant": "a", "x-internal-route": "r"}
+	}
+	var e Exporter
+
+	// Without keys, there is no filter.
+	WithMetadataFilter(nil, nil)(&e)
+	require.Nil(t, e.metadataFilter)
+	m := md()
+	e.metadataFilter.apply(m)
+	require.Equal(t, md(), m)
+
+	WithMetadataFilter(nil, []string{"X-Internal-Route"})(&e)
+	m = md()
+	e.metadataFilter.apply(m)
+	require.Equal(t, map[string]string{"Authorization": "x", "x-tenant": "a"}, m)
+
+	WithMetadataFilter([]string{"authorization", "x-internal-route"}, []string{"x-internal-route"})(&e)
+	m = md()
+	e.metadataFilter.apply(m)
+	require.Equal(t, map[string]string{"Authorization": "x"}, m)
+}
+
+func TestArrowExporterMetadataFilter(t *testing.T) {
+	tc := newSingleStreamTestCase(t, DefaultPrioritizer)
+	channel := newHealthyTestChannel()
+	tc.traceCall.AnyTimes().DoAndReturn(tc.returnNewStream(channel))
+	WithMetadataKeys([]string{"x-tenant", "x-internal-route"})(tc.exporter)
+	WithMetadataFilter(nil, []string{"x-internal-route"})(tc.exporter)
+
+	ctx := context.Background()
+	require.NoError(t, tc.exporter.Start(ctx))
+
+	go func() {
+		data := <-channel.sendChannel()
+		md := metadata.MD{}
+		hpd := hpack.NewDecoder(4096, func(f hpack.HeaderField) {
+			md[f.Name] = append(md[f.Name], f.Value)
+		})
+		_, err := hpd.Write(data.Headers)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"a"}, md.Get("x-tenant"))
+		require.Empty(t, md.Get("x-internal-route"))
+		// The protocol's own headers are not filtered.
+		require.NotEmpty(t, md.Get("otlp-pdata-size"))
+		channel.recv <- statusOKFor(data.BatchId)
+	}()
+
+	sendCtx := client.NewContext(ctx, client.Info{
+		Metadata: client.NewMetadata(map[string][]string{
+			"x-tenant":         {"a"},
+			"x-internal-route": {"r"},
+		}),
+	})
+	sent, err := tc.exporter.SendAndWait(sendCtx, testdata.GenerateTraces(2))
+	require.NoError(t, err)
+	require.True(t, sent)
+	require.NoError(t, tc.exporter.Shutdown(ctx))
+}
@@ -0,0 +1,21 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+// WithStreamParams sets parameters that every stream reports to the
+// arrowzpages extension when it starts, e.g., its compression.  The
+// streams also report their schema version as they send batches.
+func WithStreamParams(params map[string]string) Option {
+	return func(e *Exporter) {
+		e.streamParams = params
+	}
+}
+
+// startParams reports the parameters of a new stream.
+func (s *Stream) startParams() {
+	for key, value := range s.params {
+		s.status.SetStreamParam(s.id, key, value)
+	}
+	s.schemas = s.status.NewSchemaVersion(s.id)
+}
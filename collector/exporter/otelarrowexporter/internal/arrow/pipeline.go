@@ -0,0 +1,256 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
+	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	acommon "github.com/open-telemetry/otel-arrow/pkg/otel/common/arrow"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"golang.org/x/net/http2/hpack"
+)
+
+// WithPipelinedEncoding splits the writer of every stream in two
+// goroutines: one builds the Arrow records of a batch while the other
+// writes the records of the previous batch to the stream's IPC
+// streams and sends them.  Batches are sent in the order they were
+// taken by the stream.  This has no effect with a producer that does
+// not implement arrowRecord.PipelinedProducer.
+func WithPipelinedEncoding() Option {
+	return func(e *Exporter) {
+		e.pipelined = true
+	}
+}
+
+// pipelinedProducer returns the stream's producer if encoding is
+// pipelined, otherwise nil.
+func (s *Stream) pipelinedProducer() arrowRecord.PipelinedProducer {
+	if !s.pipelined {
+		return nil
+	}
+	pp, _ := s.producer.(arrowRecord.PipelinedProducer)
+	return pp
+}
+
+// writePipelined is the write loop of a stream with pipelined
+// encoding.  The calling goroutine builds the records of each batch,
+// a second goroutine writes and sends them.  One built batch waits
+// while another is sent.
+func (s *Stream) writePipelined(ctx context.Context, pp arrowRecord.PipelinedProducer, timerCh <-chan time.Time, hb *heartbeat, hdrsBuf *bytes.Buffer, hdrsEnc *hpack.Encoder) (retErr error) {
+	builtCh := make(chan writeItem, 1)
+	// failed is closed by the sender when it fails, so that the
+	// builder stops taking work.
+	failed := make(chan struct{})
+
+	var sendErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for wri := range builtCh {
+			if sendErr != nil {
+				// The caller retries on another stream.
+				if wri.built != nil {
+					wri.built.Release()
+				}
+				wri.errCh <- arrowerrors.ErrStreamRestarting
+				continue
+			}
+			if wri.heartbeatID != 0 {
+				sendErr = s.sendHeartbeat(wri)
+			} else {
+				sendErr = s.encodeAndSend(wri, hdrsBuf, hdrsEnc)
+			}
+			if sendErr != nil {
+				close(failed)
+			}
+		}
+	}()
+
+	defer func() {
+		close(builtCh)
+		wg.Wait()
+		if retErr == nil {
+			retErr = sendErr
+		}
+	}()
+
+	// next is a batch taken while coalescing, as in write.
+	var next *writeItem
+	defer func() {
+		if next != nil {
+			next.errCh <- arrowerrors.ErrStreamRestarting
+		}
+	}()
+
+	for {
+		if s.sentIDs.exhausted(s.maxBatchID) {
+			s.endReason = streamevents.ReasonBatchIDsExhausted
+			return nil
+		}
+
+		var wri writeItem
+		if next != nil {
+			wri, next = *next, nil
+		} else {
+			select {
+			case <-timerCh:
+				s.endReason = streamevents.ReasonLifetime
+				return nil
+			case <-s.workState.recycle:
+				s.endReason = streamevents.ReasonReconfigured
+				return nil
+			case <-s.workState.retired:
+				s.endReason = streamevents.ReasonReconfigured
+				return nil
+			case <-failed:
+				return nil
+			case <-s.sendFailed():
+				return nil
+			case <-hb.C():
+				// The heartbeat is sent in order with the
+				// batches being built.
+				beat, ok, err := hb.next()
+				if err != nil {
+					return err
+				}
+				if ok {
+					builtCh <- beat
+				}
+				continue
+			case wri = <-s.workState.toWrite:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		wri, next = s.coalesce(wri)
+
+		built, err := s.build(pp, wri.records)
+		if err != nil {
+			// As in encodeAndSend, this is an internal error.
+			err = fmt.Errorf("%w: %w", arrowerrors.ErrEncode, err)
+			wri.errCh <- consumererror.NewPermanent(err)
+			if errors.Is(err, acommon.ErrUnsupported) {
+				continue
+			}
+			return err
+		}
+		wri.built = built
+		builtCh <- wri
+		hb.reset()
+	}
+}
+
+// build performs the first stage of a pipelined encoding.
+func (s *Stream) build(pp arrowRecord.PipelinedProducer, records any) (_ *arrowRecord.BuiltRecords, retErr error) {
+	defer s.recoverProducerPanic(&retErr)
+	return pp.BuildRecords(records)
+}
+
+// WithPipelinedSend adds a goroutine to every stream that sends the
+// encoded batches, so that the next batch is encoded while the
+// previous call to Send waits for the transport, e.g., for gRPC flow
+// control on a high-latency link.  Batches and heartbeats are sent in
+// the order they were encoded.  This combines with
+// WithPipelinedEncoding.
+func WithPipelinedSend() Option {
+	return func(e *Exporter) {
+		e.pipelinedSend = true
+	}
+}
+
+// sender is the last stage of a stream with pipelined sending.  One
+// encoded batch waits while another is sent.
+type sender struct {
+	sendCh chan *arrowpb.BatchArrowRecords
+	// failed is closed when a Send fails, after err is set.
+	failed chan struct{}
+	err    error
+	wg     sync.WaitGroup
+}
+
+// startSender starts the sending goroutine of a stream.
+func (s *Stream) startSender() *sender {
+	snd := &sender{
+		sendCh: make(chan *arrowpb.BatchArrowRecords, 1),
+		failed: make(chan struct{}),
+	}
+	snd.wg.Add(1)
+	go func() {
+		defer snd.wg.Done()
+		for batch := range snd.sendCh {
+			if snd.err != nil {
+				// The batch is registered, the waiter is
+				// released when the stream ends.
+				s.release(batch)
+				continue
+			}
+			if snd.err = s.sendBatch(batch); snd.err != nil {
+				close(snd.failed)
+			}
+		}
+	}()
+	return snd
+}
+
+// stop waits for the batches already encoded to be sent, and returns
+// the error of the first Send that failed.
+func (snd *sender) stop() error {
+	close(snd.sendCh)
+	snd.wg.Wait()
+	return snd.err
+}
+
+// sendFailed returns a channel closed when pipelined sending fails,
+// nil without pipelined sending.
+func (s *Stream) sendFailed() <-chan struct{} {
+	if s.sender == nil {
+		return nil
+	}
+	return s.sender.failed
+}
+
+// send sends an encoded batch, or passes it to the sending goroutine
+// with pipelined sending.  The batch is released once sent.
+func (s *Stream) send(batch *arrowpb.BatchArrowRecords) error {
+	if s.sender == nil {
+		return s.sendBatch(batch)
+	}
+	select {
+	case s.sender.sendCh <- batch:
+		return nil
+	case <-s.sender.failed:
+		s.release(batch)
+		return s.sender.err
+	}
+}
+
+// sendBatch performs the blocking Send of an encoded batch.
+func (s *Stream) sendBatch(batch *arrowpb.BatchArrowRecords) error {
+	// The batch's payload buffers are reused once Send()
+	// returns, having serialized the message.
+	defer s.release(batch)
+
+	if err := s.faults.beforeSend(s.workState.id, batch); err != nil {
+		return err
+	}
+	// The receiver's credit window may hold the batch while
+	// earlier batches await their status.
+	if err := s.credits.acquire(batch); err != nil {
+		return err
+	}
+	// Sends are not concurrent, like the calls to Observe.
+	s.schemas.Observe(batch)
+	// Note: do not wrap this error, it may contain a Status.
+	return s.client.Send(batch)
+}
@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	"github.com/stretchr/testify/require"
+)
+
+// pipelinedOptions are the combinations of pipelined stages tested.
+var pipelinedOptions = map[string][]Option{
+	"encoding":      {WithPipelinedEncoding()},
+	"send":          {WithPipelinedSend()},
+	"encoding+send": {WithPipelinedEncoding(), WithPipelinedSend()},
+}
+
+func newPipelinedTestCase(t *testing.T, opts ...Option) *exporterTestCase {
+	ctc := newCommonTestCase(t, NotNoisy)
+	ctc.requestMetadataCall.AnyTimes().Return(nil, nil)
+
+	exp := NewExporter(defaultMaxStreamLifetime, 1, DefaultPrioritizer, false, ctc.telset, nil, func() arrowRecord.ProducerAPI {
+		return arrowRecord.NewProducer()
+	}, ctc.traceClient, ctc.perRPCCredentials, netstats.Noop{}, nil, opts...)
+
+	return &exporterTestCase{
+		commonTestCase: ctc,
+		exporter:       exp,
+	}
+}
+
+// consumeInOrder decodes the batches sent on a channel, which fails
+// unless they arrive in the order they were encoded, and acknowledges
+// them.  It returns the number of spans received.
+func consumeInOrder(t *testing.T, channel *healthyTestChannel) func() int {
+	var spans int
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		consumer := arrowRecord.NewConsumer()
+		defer func() {
+			require.NoError(t, consumer.Close())
+		}()
+		var nextID int64
+		for data := range channel.sendChannel() {
+			require.Equal(t, nextID, data.BatchId)
+			nextID++
+
+			traces, err := consumer.TracesFrom(data)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(traces))
+			spans += traces[0].SpanCount()
+			channel.recv <- statusOKFor(data.BatchId)
+		}
+	}()
+	return func() int {
+		wg.Wait()
+		return spans
+	}
+}
+
+// TestArrowExporterPipelined verifies that concurrent senders on a
+// pipelined stream see their batches sent in order.
+func TestArrowExporterPipelined(t *testing.T) {
+	for name, opts := range pipelinedOptions {
+		t.Run(name, func(t *testing.T) {
+			testArrowExporterPipelined(t, opts)
+		})
+	}
+}
+
+func testArrowExporterPipelined(t *testing.T, opts []Option) {
+	tc := newPipelinedTestCase(t, opts...)
+	channel := newHealthyTestChannel()
+	tc.traceCall.AnyTimes().DoAndReturn(tc.returnNewStream(channel))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, tc.exporter.Start(ctx))
+
+	received := consumeInOrder(t, channel)
+
+	const senders, sends = 8, 10
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < sends; j++ {
+				sent, err := tc.exporter.SendAndWait(context.Background(), testdata.GenerateTraces(1+i))
+				require.NoError(t, err)
+				require.True(t, sent)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.NoError(t, tc.exporter.Shutdown(ctx))
+	require.Equal(t, sends*senders*(senders+1)/2, received())
+}
+
+// TestArrowExporterPipelinedFailure verifies that a failed send
+// restarts the stream, and that a batch built or encoded behind it is
+// retried.
+func TestArrowExporterPipelinedFailure(t *testing.T) {
+	for name, opts := range pipelinedOptions {
+		t.Run(name, func(t *testing.T) {
+			testArrowExporterPipelinedFailure(t, opts)
+		})
+	}
+}
+
+func testArrowExporterPipelinedFailure(t *testing.T, opts []Option) {
+	var failed atomic.Bool
+	opts = append(opts[:len(opts):len(opts)], WithStreamFaults(StreamFaults{
+		Send: func(_ string, _ *arrowpb.BatchArrowRecords) error {
+			if failed.CompareAndSwap(false, true) {
+				return errors.New("injected")
+			}
+			return nil
+		},
+	}))
+	tc := newPipelinedTestCase(t, opts...)
+	channel0 := newHealthyTestChannel()
+	channel1 := newHealthyTestChannel()
+	tc.traceCall.AnyTimes().DoAndReturn(tc.returnNewStream(channel0, channel1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, tc.exporter.Start(ctx))
+
+	received0 := consumeInOrder(t, channel0)
+	received1 := consumeInOrder(t, channel1)
+
+	const senders = 4
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Batches of the failed stream, sent or
+			// not, are retried internally.
+			sent, err := tc.exporter.SendAndWait(context.Background(), testdata.GenerateTraces(1))
+			require.NoError(t, err)
+			require.True(t, sent)
+		}()
+	}
+	wg.Wait()
+
+	require.NoError(t, tc.exporter.Shutdown(ctx))
+	require.Equal(t, 0, received0())
+	require.Equal(t, senders, received1())
+}
@@ -6,16 +6,16 @@ package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter
 import (
 	"context"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-)
 
-var ErrStreamRestarting = status.Error(codes.Aborted, "stream is restarting")
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+)
 
 type PrioritizerName string
 
@@ -26,6 +26,8 @@ const (
 	LeastLoadedPrioritizer     PrioritizerName = llPrefix
 	LeastLoadedTwoPrioritizer  PrioritizerName = llPrefix + "2"
 	LeastLoadedFourPrioritizer PrioritizerName = llPrefix + "4"
+	RoundRobinPrioritizer      PrioritizerName = "roundrobin"
+	RandomPrioritizer          PrioritizerName = "random"
 	unsetPrioritizer           PrioritizerName = ""
 
 	llPrefix = "leastloaded"
@@ -42,6 +44,14 @@ type streamPrioritizer interface {
 	// and may block indefinitely.  this allows the prioritizer to
 	// drain its channel(s) until the exporter shuts down.
 	downgrade(context.Context)
+
+	// setStreams replaces the streams that writers are chosen
+	// from, see Exporter.SetStreams.
+	setStreams([]*streamWorkState)
+
+	// waiting returns the number of writers whose item no stream
+	// has accepted yet, see WithAutoScale.
+	waiting() int64
 }
 
 // streamWriter is the caller's interface to a stream.
@@ -52,8 +62,14 @@ type streamWriter interface {
 }
 
 func newStreamPrioritizer(dc doneCancel, name PrioritizerName, numStreams int, maxLifetime time.Duration) (streamPrioritizer, []*streamWorkState) {
-	if name == unsetPrioritizer {
+	switch name {
+	case unsetPrioritizer:
 		name = DefaultPrioritizer
+	case RoundRobinPrioritizer:
+		return newRoundRobinPrioritizer(dc, numStreams, maxLifetime)
+	case RandomPrioritizer:
+		// The least loaded of one random choice.
+		return newBestOfNPrioritizer(dc, 1, numStreams, pendingRequests, maxLifetime)
 	}
 	if strings.HasPrefix(string(name), llPrefix) {
 		// error was checked and reported in Validate
@@ -62,21 +78,58 @@ func newStreamPrioritizer(dc doneCancel, name PrioritizerName, numStreams int, m
 			return newBestOfNPrioritizer(dc, n, numStreams, pendingRequests, maxLifetime)
 		}
 	}
-	return newBestOfNPrioritizer(dc, numStreams, numStreams, pendingRequests, maxLifetime)
+	// Consider every stream, however many there are.
+	return newBestOfNPrioritizer(dc, math.MaxInt, numStreams, pendingRequests, maxLifetime)
+}
+
+// waitCounter counts the writers of a prioritizer that wait for a
+// stream to accept their item.
+type waitCounter struct {
+	count atomic.Int64
+}
+
+// waiting implements streamPrioritizer.
+func (w *waitCounter) waiting() int64 {
+	return w.count.Load()
+}
+
+// offer hands wri to stream and waits for its response, as
+// streamWriter.sendAndWait, counting the writer until the stream
+// accepts the item.
+func (w *waitCounter) offer(ctx context.Context, done <-chan struct{}, stream *streamWorkState, errCh <-chan error, wri writeItem) error {
+	w.count.Add(1)
+	select {
+	case <-done:
+		w.count.Add(-1)
+		return fmt.Errorf("%w: %w", errNotEnqueued, arrowerrors.ErrStreamRestarting)
+	case <-ctx.Done():
+		w.count.Add(-1)
+		return fmt.Errorf("%w: %w", errNotEnqueued, context.Canceled)
+	case stream.toWrite <- wri:
+		w.count.Add(-1)
+		return waitForWrite(ctx, errCh, done)
+	}
 }
 
 // pendingRequests is the load function used by leastloadedN.
 func pendingRequests(sws *streamWorkState) float64 {
-	sws.lock.Lock()
-	defer sws.lock.Unlock()
-	return float64(len(sws.waiters) + len(sws.toWrite))
+	return float64(sws.waiters.len() + len(sws.toWrite))
+}
+
+// String returns the name of the prioritizer in effect, the default
+// when unset.
+func (p PrioritizerName) String() string {
+	if p == unsetPrioritizer {
+		return string(DefaultPrioritizer)
+	}
+	return string(p)
 }
 
 // Validate implements component.ConfigValidator
 func (p PrioritizerName) Validate() error {
 	switch p {
 	// Exact match cases
-	case LeastLoadedPrioritizer, unsetPrioritizer:
+	case LeastLoadedPrioritizer, RoundRobinPrioritizer, RandomPrioritizer, unsetPrioritizer:
 		return nil
 	}
 	// "leastloadedN" cases
@@ -91,8 +144,9 @@ func (p PrioritizerName) Validate() error {
 }
 
 // drain helps avoid a race condition when downgrade happens, it ensures that
-// any late-arriving work will immediately see ErrStreamRestarting, and this
-// continues until the exporter shuts down.
+// any late-arriving work will immediately see
+// arrowerrors.ErrStreamRestarting, and this continues until the
+// exporter shuts down.
 //
 // Note: the downgrade function is a major source of complexity and it is
 // probably best removed, instead of having this level of complexity.
@@ -102,7 +156,7 @@ func drain(ch <-chan writeItem, done <-chan struct{}) {
 		case <-done:
 			return
 		case item := <-ch:
-			item.errCh <- ErrStreamRestarting
+			item.errCh <- arrowerrors.ErrStreamRestarting
 		}
 	}
 }
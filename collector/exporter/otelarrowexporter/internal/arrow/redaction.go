@@ -0,0 +1,15 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+// WithRedaction replaces the messages of the statuses received from
+// the receiver, before they are logged, recorded or returned to the
+// caller, and the error messages logged by streams, with a digest of
+// the message, since they may contain client metadata or attribute
+// values, see the redact package.  Status codes are kept.
+func WithRedaction() Option {
+	return func(e *Exporter) {
+		e.redactErrors = true
+	}
+}
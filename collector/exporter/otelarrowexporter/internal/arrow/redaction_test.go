@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/collector/redact"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
+)
+
+// TestExporterRedaction verifies that the message of a rejected batch
+// is redacted in the error returned to the sender and in the logs.
+func TestExporterRedaction(t *testing.T) {
+	const secret = "bad attribute user.email=someone@example.com"
+	ctc := newCommonTestCase(t, NotNoisy)
+	ctc.requestMetadataCall.AnyTimes().Return(nil, nil)
+	ctc.traceCall.AnyTimes().DoAndReturn(ctc.repeatedNewStream(func() testChannel {
+		ch := newHealthyTestChannel()
+		go func() {
+			for batch := range ch.sent {
+				ss := statusInvalidFor(batch.BatchId)
+				ss.StatusMessage = secret
+				ch.recv <- ss
+			}
+		}()
+		return ch
+	}))
+	exp := NewExporter(defaultMaxStreamLifetime, 1, DefaultPrioritizer, false, ctc.telset, nil, mockArrowProducer(ctc), ctc.traceClient, ctc.perRPCCredentials, netstats.Noop{}, nil, WithRedaction())
+
+	bg := context.Background()
+	require.NoError(t, exp.Start(bg))
+
+	sent, err := exp.SendAndWait(bg, twoTraces)
+	require.True(t, sent)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "someone@example.com")
+	require.Contains(t, err.Error(), redact.Message(secret))
+
+	require.NoError(t, exp.Shutdown(bg))
+
+	rejected := ctc.observedLogs.FilterMessage("batch rejected").All()
+	require.Len(t, rejected, 1)
+	require.Equal(t, redact.Message(secret), rejected[0].ContextMap()[redact.MessageKey])
+	require.Equal(t, arrowpb.StatusCode_INVALID_ARGUMENT.String(), rejected[0].ContextMap()[streamevents.CodeKey])
+}
+
+// TestExporterRedactionLogger verifies that the exporter's logger
+// redacts errors.
+func TestExporterRedactionLogger(t *testing.T) {
+	ctc := newCommonTestCase(t, NotNoisy)
+	exp := NewExporter(defaultMaxStreamLifetime, 1, DefaultPrioritizer, false, ctc.telset, nil, mockArrowProducer(ctc), ctc.traceClient, ctc.perRPCCredentials, netstats.Noop{}, nil, WithRedaction())
+
+	exp.telemetry.Logger.Error("arrow producer close:", zap.Error(context.DeadlineExceeded))
+
+	logs := ctc.observedLogs.FilterLevelExact(zapcore.ErrorLevel).All()
+	require.Len(t, logs, 1)
+	require.Equal(t, redact.Message(context.DeadlineExceeded.Error()), logs[0].ContextMap()["error"])
+}
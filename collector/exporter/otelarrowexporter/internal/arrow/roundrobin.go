@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// roundRobinPrioritizer is a prioritizer that writes to each stream
+// in turn, regardless of its load.
+type roundRobinPrioritizer struct {
+	doneCancel
+	waitCounter
+
+	// state tracks the work being handled by all streams, it is
+	// replaced by setStreams.
+	state atomic.Pointer[[]*streamWorkState]
+
+	// next counts the writes, selecting the next stream.
+	next atomic.Uint64
+}
+
+var _ streamPrioritizer = &roundRobinPrioritizer{}
+
+func newRoundRobinPrioritizer(dc doneCancel, numStreams int, maxLifetime time.Duration) (*roundRobinPrioritizer, []*streamWorkState) {
+	var state []*streamWorkState
+
+	for i := 0; i < numStreams; i++ {
+		state = append(state, newStreamWorkState(strconv.Itoa(i), maxLifetime))
+	}
+
+	rr := &roundRobinPrioritizer{
+		doneCancel: dc,
+	}
+	rr.state.Store(&state)
+
+	return rr, state
+}
+
+func (rr *roundRobinPrioritizer) downgrade(ctx context.Context) {
+	for _, ws := range *rr.state.Load() {
+		go drain(ws.toWrite, ctx.Done())
+	}
+}
+
+// setStreams implements streamPrioritizer.
+func (rr *roundRobinPrioritizer) setStreams(state []*streamWorkState) {
+	rr.state.Store(&state)
+}
+
+// sendAndWait implements streamWriter
+func (rr *roundRobinPrioritizer) sendAndWait(ctx context.Context, errCh <-chan error, wri writeItem) error {
+	return rr.offer(ctx, rr.done, rr.streamFor(), errCh, wri)
+}
+
+func (rr *roundRobinPrioritizer) nextWriter() streamWriter {
+	select {
+	case <-rr.done:
+		// In case of downgrade, return nil to return into a
+		// non-Arrow code path.
+		return nil
+	default:
+		// Fall through to sendAndWait().
+		return rr
+	}
+}
+
+func (rr *roundRobinPrioritizer) streamFor() *streamWorkState {
+	state := *rr.state.Load()
+	return state[(rr.next.Add(1)-1)%uint64(len(state))]
+}
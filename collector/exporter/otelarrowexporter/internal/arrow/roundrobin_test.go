@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundRobinPrioritizerTakesTurns(t *testing.T) {
+	_, dc := newDoneCancel(context.Background())
+	defer dc.cancel()
+
+	rr, state := newRoundRobinPrioritizer(dc, 3, time.Hour)
+
+	// Loaded streams take their turn all the same.
+	state[0].toWrite <- writeItem{}
+	for i := 0; i < 6; i++ {
+		require.Same(t, state[i%3], rr.streamFor())
+	}
+
+	// Added streams join the rotation.
+	state = append(state, newStreamWorkState("3", time.Hour))
+	rr.setStreams(state)
+	counts := map[*streamWorkState]int{}
+	for i := 0; i < 8; i++ {
+		counts[rr.streamFor()]++
+	}
+	require.Len(t, counts, 4)
+	for _, n := range counts {
+		require.Equal(t, 2, n)
+	}
+}
+
+func TestRoundRobinPrioritizerShutdown(t *testing.T) {
+	_, dc := newDoneCancel(context.Background())
+	rr, _ := newRoundRobinPrioritizer(dc, 1, time.Hour)
+
+	dc.cancel()
+	errCh := make(chan error, 1)
+	err := rr.sendAndWait(context.Background(), errCh, writeItem{errCh: errCh})
+	require.ErrorIs(t, err, arrowerrors.ErrStreamRestarting)
+	require.Nil(t, rr.nextWriter())
+}
+
+// TestPrioritizerWaiting verifies that writers are counted as
+// waiting until a stream accepts their item.
+func TestPrioritizerWaiting(t *testing.T) {
+	_, dc := newDoneCancel(context.Background())
+	defer dc.cancel()
+	rr, state := newRoundRobinPrioritizer(dc, 1, time.Hour)
+
+	// The first item fills the stream's channel, the second
+	// waits for the stream to take the first.
+	errCh := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			errCh <- rr.sendAndWait(context.Background(), errCh, writeItem{errCh: errCh})
+		}()
+	}
+	require.Eventually(t, func() bool {
+		return rr.waiting() == 1
+	}, 5*time.Second, time.Millisecond)
+
+	<-state[0].toWrite
+	require.Eventually(t, func() bool {
+		return rr.waiting() == 0
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestPrioritizerValidate(t *testing.T) {
+	for _, p := range []PrioritizerName{"", LeastLoadedPrioritizer, LeastLoadedTwoPrioritizer, RoundRobinPrioritizer, RandomPrioritizer} {
+		require.NoError(t, p.Validate())
+	}
+	require.ErrorContains(t, PrioritizerName("fifo").Validate(), `unrecognized prioritizer: "fifo"`)
+	require.Equal(t, "leastloaded", PrioritizerName("").String())
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// SendReporter is notified of the failures of the Arrow send path
+// that the exporter helper does not count as failed sends, see
+// WithSendReporter.  The reason is one of the Reason constants.
+type SendReporter interface {
+	// EnqueueFailed reports a batch that failed before a stream
+	// accepted it.
+	EnqueueFailed(ctx context.Context, data any, reason string)
+
+	// SendFailed reports an attempt to send a batch that failed
+	// because its stream ended.  The batch is retried on another
+	// stream.
+	SendFailed(ctx context.Context, data any, reason string)
+}
+
+// The reasons of the failures passed to a SendReporter.
+const (
+	ReasonCredentials      = "credentials"
+	ReasonIdempotencyKey   = "idempotency_key"
+	ReasonMemoryLimit      = "memory_limit"
+	ReasonCanceled         = "canceled"
+	ReasonStreamRestarting = "stream_restarting"
+)
+
+// errNotEnqueued wraps the error of a caller whose context ended
+// before a stream accepted its batch.
+var errNotEnqueued = errors.New("batch not enqueued")
+
+// WithSendReporter reports the failures of the Arrow send path to r.
+func WithSendReporter(r SendReporter) Option {
+	return func(e *Exporter) {
+		e.sendReporter = r
+	}
+}
+
+// queuedBatches counts the batches of each signal held by SendAndWait.
+type queuedBatches struct {
+	traces  atomic.Int64
+	metrics atomic.Int64
+	logs    atomic.Int64
+}
+
+func (q *queuedBatches) counter(data any) *atomic.Int64 {
+	switch data.(type) {
+	case ptrace.Traces:
+		return &q.traces
+	case pmetric.Metrics:
+		return &q.metrics
+	case plog.Logs:
+		return &q.logs
+	}
+	return nil
+}
+
+// Queued returns the number of batches of each signal held by the
+// Arrow send path, from the call to SendAndWait until it returns.
+func (e *Exporter) Queued() (traces, metrics, logs int64) {
+	return e.queued.traces.Load(), e.queued.metrics.Load(), e.queued.logs.Load()
+}
+
+// enqueue counts a batch held by SendAndWait and returns the function
+// that forgets it.
+func (e *Exporter) enqueue(data any) func() {
+	c := e.queued.counter(data)
+	if c == nil {
+		return func() {}
+	}
+	c.Add(1)
+	return func() { c.Add(-1) }
+}
+
+func (e *Exporter) enqueueFailed(ctx context.Context, data any, reason string) {
+	if e.sendReporter != nil {
+		e.sendReporter.EnqueueFailed(ctx, data, reason)
+	}
+}
+
+func (e *Exporter) sendFailed(ctx context.Context, data any, reason string) {
+	if e.sendReporter != nil {
+		e.sendReporter.SendFailed(ctx, data, reason)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testSendReporter records the failures reported to it.
+type testSendReporter struct {
+	lock     sync.Mutex
+	enqueue  []string
+	sendFail []string
+}
+
+func (r *testSendReporter) EnqueueFailed(_ context.Context, _ any, reason string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.enqueue = append(r.enqueue, reason)
+}
+
+func (r *testSendReporter) SendFailed(_ context.Context, _ any, reason string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.sendFail = append(r.sendFail, reason)
+}
+
+func TestArrowExporterSendReporter(t *testing.T) {
+	tc := newSingleStreamTestCase(t, DefaultPrioritizer)
+	channel := newHealthyTestChannel()
+	tc.traceCall.AnyTimes().DoAndReturn(tc.returnNewStream(channel))
+
+	reporter := &testSendReporter{}
+	ml := NewMemoryLimiter(1000)
+	WithSendReporter(reporter)(tc.exporter)
+	WithMemoryLimiter(ml)(tc.exporter)
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	// Hold the memory, so the next batch waits and then fails
+	// before a stream accepts it.
+	require.NoError(t, ml.acquire(bg, 1000))
+
+	ctx, cancel := context.WithTimeout(bg, 100*time.Millisecond)
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := tc.exporter.SendAndWait(ctx, twoTraces)
+		errCh <- err
+	}()
+
+	require.Eventually(t, func() bool {
+		traces, metrics, logs := tc.exporter.Queued()
+		return traces == 1 && metrics == 0 && logs == 0
+	}, time.Second, time.Millisecond)
+
+	require.ErrorIs(t, <-errCh, context.DeadlineExceeded)
+	ml.release(1000)
+
+	traces, _, _ := tc.exporter.Queued()
+	require.Equal(t, int64(0), traces)
+	require.Equal(t, []string{ReasonMemoryLimit}, reporter.enqueue)
+	require.Empty(t, reporter.sendFail)
+
+	require.NoError(t, tc.exporter.Shutdown(bg))
+}
@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+)
+
+// Kinds of StatusEvent.
+const (
+	// StatusEventSend records a batch ID registered for a response.
+	StatusEventSend = "send"
+	// StatusEventRecv records a BatchStatus received.
+	StatusEventRecv = "recv"
+	// StatusEventError records an error returned by Recv().
+	StatusEventError = "error"
+)
+
+// StatusEvent is one step in the recorded life of a stream.  A
+// recording is a sequence of StatusEvents encoded as JSON lines.
+// Stream is the unique ID of the stream, since batch IDs are scoped
+// to their stream.
+type StatusEvent struct {
+	Kind    string             `json:"kind"`
+	Stream  string             `json:"stream"`
+	Elapsed time.Duration      `json:"elapsed"`
+	BatchID int64              `json:"batch_id"`
+	Code    arrowpb.StatusCode `json:"code,omitempty"`
+	Message string             `json:"message,omitempty"`
+}
+
+// BatchStatus returns the status carried by a StatusEventRecv event.
+func (ev StatusEvent) BatchStatus() *arrowpb.BatchStatus {
+	return &arrowpb.BatchStatus{
+		BatchId:       ev.BatchID,
+		StatusCode:    ev.Code,
+		StatusMessage: ev.Message,
+	}
+}
+
+// StatusRecorder writes the sequence of batches sent and statuses
+// received by every stream of an exporter, so that ack-ordering
+// problems observed in a live run can be replayed against
+// processBatchStatus in tests.  Methods are safe to call on a nil
+// *StatusRecorder, which records nothing.
+type StatusRecorder struct {
+	lock  sync.Mutex
+	enc   *json.Encoder
+	start time.Time
+	err   error
+}
+
+// NewStatusRecorder returns a recorder that writes to w.
+func NewStatusRecorder(w io.Writer) *StatusRecorder {
+	return &StatusRecorder{
+		enc:   json.NewEncoder(w),
+		start: time.Now(),
+	}
+}
+
+// WithStatusRecorder records the batches sent and statuses received
+// by every stream.
+func WithStatusRecorder(r *StatusRecorder) Option {
+	return func(e *Exporter) {
+		e.recorder = r
+	}
+}
+
+// Err returns the first error encountered writing the recording.
+func (r *StatusRecorder) Err() error {
+	if r == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.err
+}
+
+func (r *StatusRecorder) recordSend(streamID string, batchID int64) {
+	r.record(StatusEvent{
+		Kind:    StatusEventSend,
+		Stream:  streamID,
+		BatchID: batchID,
+	})
+}
+
+func (r *StatusRecorder) recordRecv(streamID string, status *arrowpb.BatchStatus, err error) {
+	if err != nil {
+		r.record(StatusEvent{
+			Kind:    StatusEventError,
+			Stream:  streamID,
+			Message: err.Error(),
+		})
+		return
+	}
+	r.record(StatusEvent{
+		Kind:    StatusEventRecv,
+		Stream:  streamID,
+		BatchID: status.BatchId,
+		Code:    status.StatusCode,
+		Message: status.StatusMessage,
+	})
+}
+
+func (r *StatusRecorder) record(ev StatusEvent) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.err != nil {
+		return
+	}
+	ev.Elapsed = time.Since(r.start)
+	r.err = r.enc.Encode(ev)
+}
+
+// ReadStatusEvents decodes a recording written by a StatusRecorder.
+func ReadStatusEvents(rd io.Reader) ([]StatusEvent, error) {
+	var events []StatusEvent
+	dec := json.NewDecoder(rd)
+	for {
+		var ev StatusEvent
+		err := dec.Decode(&ev)
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, ev)
+	}
+}
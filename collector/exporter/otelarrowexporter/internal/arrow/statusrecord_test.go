@@ -0,0 +1,168 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// replayResult is the outcome of replaying a recording.
+type replayResult struct {
+	// outcomes are the errors delivered to each batch's sender,
+	// in delivery order.
+	outcomes map[int64][]error
+	// streamErrs are the errors that would have broken the
+	// stream, indexed by recv event position.
+	streamErrs map[int]error
+	// pending are batch IDs never acknowledged.
+	pending []int64
+}
+
+// replayStatusEvents drives processBatchStatus with the recorded
+// events, one stream at a time.
+func replayStatusEvents(t *testing.T, events []StatusEvent) replayResult {
+	res := replayResult{
+		outcomes:   map[int64][]error{},
+		streamErrs: map[int]error{},
+	}
+	streams := map[string]*Stream{}
+	chans := map[int64]chan error{}
+
+	drain := func() {
+		for id, ch := range chans {
+			select {
+			case err := <-ch:
+				res.outcomes[id] = append(res.outcomes[id], err)
+			default:
+			}
+		}
+	}
+
+	for i, ev := range events {
+		s, ok := streams[ev.Stream]
+		if !ok {
+			s = newStream(nil, nil, componenttest.NewNopTelemetrySettings(), netstats.Noop{}, &streamWorkState{
+				id: ev.Stream,
+			})
+			streams[ev.Stream] = s
+		}
+		switch ev.Kind {
+		case StatusEventSend:
+			ch := make(chan error, 1)
+			chans[ev.BatchID] = ch
+			s.setBatchChannel(ev.BatchID, ch)
+		case StatusEventRecv:
+			if err := s.processBatchStatus(ev.BatchStatus()); err != nil {
+				res.streamErrs[i] = err
+			}
+		case StatusEventError:
+			// The stream would break here, but the recording
+			// continues with whatever came next.
+		default:
+			t.Fatalf("unknown event kind %q", ev.Kind)
+		}
+		drain()
+	}
+	for _, s := range streams {
+		s.workState.waiters.drain(func(id int64, _ chan<- error) {
+			res.pending = append(res.pending, id)
+		})
+	}
+	return res
+}
+
+func TestStatusReplayOutOfOrder(t *testing.T) {
+	f, err := os.Open("testdata/status_out_of_order.jsonl")
+	require.NoError(t, err)
+	defer f.Close()
+
+	events, err := ReadStatusEvents(f)
+	require.NoError(t, err)
+	require.Len(t, events, 7)
+
+	res := replayStatusEvents(t, events)
+
+	require.Empty(t, res.pending)
+	require.Equal(t, []error{nil}, res.outcomes[0])
+	require.Equal(t, []error{nil}, res.outcomes[2])
+
+	// Batch 1 receives UNAVAILABLE, which is retryable, then a
+	// duplicate acknowledgement that the stream does not recognize.
+	require.Len(t, res.outcomes[1], 1)
+	require.Equal(t, codes.Unavailable, status.Code(res.outcomes[1][0]))
+	require.Contains(t, res.outcomes[1][0].Error(), "backend flapped")
+
+	require.Len(t, res.streamErrs, 1)
+	require.Contains(t, res.streamErrs[6].Error(), "unrecognized batch ID: 1")
+}
+
+func TestStatusRecorderRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewStatusRecorder(&buf)
+
+	rec.recordSend("1", 10)
+	rec.recordRecv("1", statusUnavailableFor(10), nil)
+	rec.recordRecv("1", nil, status.Error(codes.Canceled, "done"))
+	require.NoError(t, rec.Err())
+
+	events, err := ReadStatusEvents(&buf)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	require.Equal(t, StatusEventSend, events[0].Kind)
+	require.Equal(t, int64(10), events[0].BatchID)
+	require.Equal(t, StatusEventRecv, events[1].Kind)
+	require.Equal(t, arrowpb.StatusCode_UNAVAILABLE, events[1].Code)
+	require.Equal(t, StatusEventError, events[2].Kind)
+	require.Contains(t, events[2].Message, "done")
+	require.LessOrEqual(t, events[0].Elapsed, events[2].Elapsed)
+
+	// A nil recorder records nothing.
+	var nilRec *StatusRecorder
+	nilRec.recordSend("1", 1)
+	require.NoError(t, nilRec.Err())
+}
+
+// TestStatusRecordLiveReplay records a live exporter run and replays
+// it.
+func TestStatusRecordLiveReplay(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewStatusRecorder(&buf)
+
+	tc := newFaultsTestCase(t, StreamFaults{})
+	WithStatusRecorder(rec)(tc.exporter)
+
+	bg := context.Background()
+	require.NoError(t, tc.exporter.Start(bg))
+
+	for i := 0; i < 3; i++ {
+		sent, err := tc.exporter.SendAndWait(bg, twoTraces)
+		require.True(t, sent)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tc.exporter.Shutdown(bg))
+	require.NoError(t, rec.Err())
+
+	events, err := ReadStatusEvents(&buf)
+	require.NoError(t, err)
+
+	res := replayStatusEvents(t, events)
+	require.Empty(t, res.pending)
+	require.Empty(t, res.streamErrs)
+	require.Len(t, res.outcomes, 3)
+	for _, errs := range res.outcomes {
+		require.Equal(t, []error{nil}, errs)
+	}
+}
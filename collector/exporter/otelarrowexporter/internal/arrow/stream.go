@@ -10,18 +10,27 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+	"github.com/open-telemetry/otel-arrow/collector/capability"
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
 	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/collector/redact"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
 	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	acommon "github.com/open-telemetry/otel-arrow/pkg/otel/common/arrow"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
@@ -29,11 +38,23 @@ import (
 	"golang.org/x/net/http2/hpack"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
+// hpackMaxDynamicSize is the size of the hpack dynamic table of the
+// headers of each stream, the default of both encoder and decoder.
+const hpackMaxDynamicSize = 4096
+
 // Stream is 1:1 with gRPC stream.
 type Stream struct {
+	// id uniquely identifies the stream, unlike workState.id,
+	// which is shared by the successive streams of one slot.  It
+	// is sent to the receiver, and attached to the stream's logs,
+	// spans and detailed metrics.
+	id string
+
 	// maxStreamLifetime is the max timeout before stream
 	// should be closed on the client side. This ensures a
 	// graceful shutdown before max_connection_age is reached
@@ -60,9 +81,106 @@ type Stream struct {
 	// method the gRPC method name, used for additional instrumentation.
 	method string
 
+	// started is when the stream opened.  endReason explains why
+	// the writer ended the stream without error, it is set by the
+	// writer and read after it returns.
+	started   time.Time
+	endReason string
+
 	// netReporter provides network-level metrics.
 	netReporter netstats.Interface
 
+	// status records stream state for the arrowzpages extension,
+	// may be nil.  params are the parameters reported when the
+	// stream starts, and schemas numbers its schemas, see
+	// WithStreamParams.
+	status  *arrowzpages.Instance
+	params  map[string]string
+	schemas *arrowzpages.SchemaVersion
+
+	// health reports the component status, may be nil.
+	// succeeded is set by the reader at the first OK status.
+	health    *streamHealth
+	succeeded bool
+
+	// capabilities are declared when the stream opens, may be
+	// nil.  maxBatchBytes is the size limit of the receiver, zero
+	// until it is known, see negotiate.
+	capabilities  *capability.Set
+	maxBatchBytes atomic.Int64
+
+	// payloadZstd records whether the receiver declares
+	// whole-payload compression, nil without WithPayloadZstd.
+	payloadZstd *atomic.Bool
+
+	// faults are optional hooks for testing.
+	faults StreamFaults
+
+	// recorder optionally records batch statuses, may be nil.
+	recorder *StatusRecorder
+
+	// pipelined is set to build each batch while the previous
+	// one is sent, see WithPipelinedEncoding.
+	pipelined bool
+
+	// pipelinedSend is set to send each batch while the next one
+	// is encoded, see WithPipelinedSend.  sender is the sending
+	// stage of the current writer, if set.
+	pipelinedSend bool
+	sender        *sender
+
+	// maxCoalesce is the maximum number of pending batches combined
+	// into one, see WithCoalescing.  The copies made to combine
+	// them are charged to memory, which may be nil.
+	maxCoalesce int
+	memory      *MemoryLimiter
+
+	// heartbeatInterval is the idle time after which the stream
+	// sends a heartbeat, see WithHeartbeat.  statusCount counts
+	// the statuses received, by which the heartbeat tells a
+	// receiver that is busy from one that is unreachable.
+	heartbeatInterval time.Duration
+	statusCount       atomic.Uint64
+
+	// ackTimeout is the time without progress after which the
+	// stream restarts, see WithAckTimeout.  lastProgress is the
+	// time of the last progress, in Unix nanoseconds.
+	ackTimeout   time.Duration
+	lastProgress atomic.Int64
+
+	// compressionRatio records the compression of each batch,
+	// may be nil, see WithCompressionRatio.
+	compressionRatio metric.Float64Histogram
+	ratioAttrs       ratioAttrs
+
+	// establishTimeout bounds the establishment of the stream,
+	// see WithEstablishTimeout, timed by establish.
+	establishTimeout time.Duration
+	establish        *establishment
+
+	// redactErrors redacts the status messages received, see
+	// WithRedaction.
+	redactErrors bool
+
+	// checksums attaches the checksum of the payloads to each
+	// batch, see WithChecksums.
+	checksums bool
+
+	// resetHeaders is set when a batch is rejected with
+	// InvalidArgument, which is how a receiver reports header
+	// blocks that it failed to decode.  The writer then empties
+	// the hpack dynamic table, since the receiver's was reset.
+	resetHeaders atomic.Bool
+
+	// credits is the flow control window granted by the
+	// receiver, set when the stream runs.
+	credits *streamCredits
+
+	// sentIDs are the batch IDs sent, which the stream ends
+	// before reaching maxBatchID, see defaultMaxBatchID.
+	sentIDs    *sentBatchIDs
+	maxBatchID int64
+
 	// streamWorkState is the interface to prioritizer/balancer, contains
 	// outstanding request (by batch ID) and the write channel used by
 	// the stream.  All of this state will be inherited by the successor
@@ -73,20 +191,73 @@ type Stream struct {
 // streamWorkState contains the state assigned to an Arrow stream.  When
 // a stream shuts down, the work state is handed to the replacement stream.
 type streamWorkState struct {
+	// id identifies this work state among the exporter's
+	// streams, it is stable across stream restarts.
+	id string
+
 	// toWrite is used to pass pending data between a caller, the
 	// prioritizer and a stream.
 	toWrite chan writeItem
 
-	// maxStreamLifetime is a limit on duration for streams.  A
-	// slight "jitter" is applied relative to this value on a
-	// per-stream basis.
-	maxStreamLifetime time.Duration
+	// maxStreamLifetime is a limit on duration for streams, in
+	// nanoseconds, read when each stream starts.  A slight
+	// "jitter" is applied relative to this value on a per-stream
+	// basis.
+	maxStreamLifetime atomic.Int64
 
-	// lock protects waiters
-	lock sync.Mutex
+	// recycle asks the running stream to finish gracefully, as
+	// when its lifetime expires, so that it restarts with a new
+	// lifetime.
+	recycle chan struct{}
+
+	// retired is closed when the stream is removed by
+	// Exporter.SetStreams.  The running stream finishes
+	// gracefully and is not restarted.
+	retired chan struct{}
 
 	// waiters is the response channel for each active batch.
-	waiters map[int64]chan<- error
+	waiters waiterMap
+
+	// ackLatency is a moving average of the time from sending a
+	// batch to receiving its status, in nanoseconds, zero before
+	// the first status, see WithAutoScale.
+	ackLatency atomic.Int64
+}
+
+// newStreamWorkState returns the state of a new stream.
+func newStreamWorkState(id string, maxLifetime time.Duration) *streamWorkState {
+	ws := &streamWorkState{
+		id:      id,
+		toWrite: make(chan writeItem, 1),
+		recycle: make(chan struct{}, 1),
+		retired: make(chan struct{}),
+	}
+	ws.setLifetime(maxLifetime)
+	return ws
+}
+
+// setLifetime sets the lifetime of the next stream, with jitter.
+func (sws *streamWorkState) setLifetime(maxLifetime time.Duration) {
+	sws.maxStreamLifetime.Store(int64(addJitter(maxLifetime)))
+}
+
+// isRetired returns whether the stream was removed.
+func (sws *streamWorkState) isRetired() bool {
+	select {
+	case <-sws.retired:
+		return true
+	default:
+		return false
+	}
+}
+
+// requestRecycle asks the running stream to finish gracefully.
+func (sws *streamWorkState) requestRecycle() {
+	select {
+	case sws.recycle <- struct{}{}:
+	default:
+		// A request is already pending.
+	}
 }
 
 // writeItem is passed from the sender (a pipeline consumer) to the
@@ -105,6 +276,16 @@ type writeItem struct {
 	uncompSize int
 	// producerCtx is used for tracing purposes.
 	producerCtx context.Context
+	// producers are the batches combined into this one, when it
+	// was coalesced, see combineWriteItems.  copied is the size
+	// of the copy charged to the memory limiter.
+	producers []producer
+	copied    int
+	// built is set by the first stage of a pipelined encoding.
+	built *arrowRecord.BuiltRecords
+	// heartbeatID is set, always negative, when the item is a
+	// heartbeat instead of data.
+	heartbeatID int64
 }
 
 // newStream constructs a stream
@@ -116,28 +297,39 @@ func newStream(
 	workState *streamWorkState,
 ) *Stream {
 	tracer := telemetry.TracerProvider.Tracer("otel-arrow-exporter")
+	id := streamevents.NewStreamID()
+	telemetry.Logger = streamevents.With(telemetry.Logger, id, "")
 	return &Stream{
-		producer:    producer,
-		prioritizer: prioritizer,
-		telemetry:   telemetry,
-		tracer:      tracer,
-		netReporter: netReporter,
-		workState:   workState,
+		id:                id,
+		maxStreamLifetime: time.Duration(workState.maxStreamLifetime.Load()),
+		producer:          producer,
+		prioritizer:       prioritizer,
+		telemetry:         telemetry,
+		tracer:            tracer,
+		netReporter:       netReporter,
+		sentIDs:           newSentBatchIDs(),
+		maxBatchID:        defaultMaxBatchID,
+		workState:         workState,
 	}
 }
 
 // setBatchChannel places a waiting consumer's batchID into the waiters map, where
 // the stream reader may find it.
 func (s *Stream) setBatchChannel(batchID int64, errCh chan<- error) {
-	s.workState.lock.Lock()
-	defer s.workState.lock.Unlock()
-
-	s.workState.waiters[batchID] = errCh
+	if s.workState.waiters.len() == 0 {
+		// The watchdog measures from the first batch
+		// awaiting status.
+		s.markProgress()
+	}
+	s.establish.batchSent()
+	s.sentIDs.sent(batchID)
+	s.workState.waiters.set(batchID, errCh)
 }
 
 // logStreamError decides how to log an error.  `which` indicates the
-// stream direction, will be "reader" or "writer".
-func (s *Stream) logStreamError(which string, err error) {
+// stream direction, will be "reader" or "writer".  Returns true when
+// the error was not an intentional shutdown.
+func (s *Stream) logStreamError(which string, err error) bool {
 	var code codes.Code
 	var msg string
 	// gRPC tends to supply status-wrapped errors, so we always
@@ -157,16 +349,31 @@ func (s *Stream) logStreamError(which string, err error) {
 	}
 	if code == codes.Canceled {
 		s.telemetry.Logger.Debug("arrow stream shutdown", zap.String("which", which), zap.String("message", msg))
-	} else {
-		s.telemetry.Logger.Error("arrow stream error", zap.String("which", which), zap.String("message", msg), zap.Int("code", int(code)))
+		return false
 	}
+	streamevents.Failed(s.telemetry.Logger, code, msg, zap.String("which", which))
+	return true
 }
 
 // run blocks the calling goroutine while executing stream logic.  run
 // will return when the reader and writer are finished.  errors will be logged.
 func (s *Stream) run(ctx context.Context, dc doneCancel, streamClient StreamClientFunc, grpcOptions []grpc.CallOption) {
-	sc, method, err := streamClient(ctx, grpcOptions...)
+	// The receiver attaches the stream ID to its own telemetry.
+	openCtx := metadata.AppendToOutgoingContext(ctx, streamevents.StreamIDHeader, s.id)
+	if s.capabilities != nil {
+		openCtx = metadata.AppendToOutgoingContext(openCtx, capability.Header, s.capabilities.String())
+	}
+	s.establish = startEstablishment(s.establishTimeout, dc.cancel)
+	defer s.establish.stop()
+	sc, method, err := streamClient(openCtx, grpcOptions...)
 	if err != nil {
+		if estErr := s.establish.err(); estErr != nil {
+			// The receiver is unresponsive, not lacking
+			// support for Arrow, see restartable.
+			streamevents.Failed(s.telemetry.Logger, codes.DeadlineExceeded, estErr.Error())
+			s.health.streamFailed(estErr)
+			return
+		}
 		// Returning with stream.client == nil signals the
 		// lack of an Arrow stream endpoint.  When all the
 		// streams return with .client == nil, the ready
@@ -181,6 +388,7 @@ func (s *Stream) run(ctx context.Context, dc doneCancel, streamClient StreamClie
 		// generally delivered to the Recv() call below, so
 		// this code path is not taken for an ordinary downgrade.
 		s.telemetry.Logger.Error("cannot start arrow stream", zap.Error(err))
+		s.health.streamFailed(err)
 		return
 	}
 	// Setting .client != nil indicates that the endpoint was valid,
@@ -188,6 +396,14 @@ func (s *Stream) run(ctx context.Context, dc doneCancel, streamClient StreamClie
 	// restarted.
 	s.method = method
 	s.client = sc
+	s.started = time.Now()
+	s.telemetry.Logger = streamevents.With(s.telemetry.Logger, "", method)
+	s.status.StreamStarted(s.id, method)
+	s.health.streamStarted()
+	s.startParams()
+	streamevents.Started(s.telemetry.Logger)
+
+	s.credits = newStreamCredits(ctx)
 
 	// ww is used to wait for the writer.  Since we wait for the writer,
 	// the writer's goroutine is not added to exporter waitgroup (e.wg).
@@ -203,14 +419,31 @@ func (s *Stream) run(ctx context.Context, dc doneCancel, streamClient StreamClie
 		}
 	}()
 
+	var watchErr error
+	if s.ackTimeout > 0 {
+		s.markProgress()
+		ww.Add(1)
+		go func() {
+			defer ww.Done()
+			watchErr = s.watchdog(ctx)
+			if watchErr != nil {
+				dc.cancel()
+			}
+		}()
+	}
+
 	// the result from read() is processed after cancel and wait,
 	// so we can set s.client = nil in case of a delayed Unimplemented.
 	err = s.read(ctx)
+	shutdown := ctx.Err() != nil
 
-	// Wait for the writer to ensure that all waiters are known.
+	// Wait for the writer to ensure that all waiters are known,
+	// and for the watchdog.
 	dc.cancel()
 	ww.Wait()
+	s.telemetry.Logger = s.telemetry.Logger.With(s.sentIDs.fields()...)
 
+	var endErr error
 	if err != nil {
 		// This branch is reached with an unimplemented status
 		// with or without the WaitForReady flag.
@@ -227,24 +460,42 @@ func (s *Stream) run(ctx context.Context, dc doneCancel, streamClient StreamClie
 			)
 		} else {
 			// All other cases, use the standard log handler.
-			s.logStreamError("reader", err)
+			if s.logStreamError("reader", err) {
+				endErr = err
+			}
 		}
 	}
-	if writeErr != nil {
-		s.logStreamError("writer", writeErr)
+	if writeErr != nil && s.logStreamError("writer", writeErr) && endErr == nil {
+		endErr = writeErr
+	}
+	if watchErr != nil && s.logStreamError("watchdog", watchErr) && endErr == nil {
+		endErr = watchErr
+	}
+	if estErr := s.establish.err(); estErr != nil {
+		// The reader and writer see the cancelation.
+		streamevents.Failed(s.telemetry.Logger, codes.DeadlineExceeded, estErr.Error())
+		endErr = estErr
+	}
+	s.status.StreamEnded(s.id, endErr)
+	s.health.streamEnded(endErr)
+	if endErr == nil && s.client != nil {
+		reason := s.endReason
+		switch {
+		case reason != "":
+		case shutdown:
+			reason = streamevents.ReasonShutdown
+		default:
+			reason = streamevents.ReasonClosed
+		}
+		streamevents.Rotated(s.telemetry.Logger, s.started, reason)
 	}
-
-	s.workState.lock.Lock()
-	defer s.workState.lock.Unlock()
 
 	// The reader and writer have both finished; respond to any
 	// outstanding waiters.
-	for _, ch := range s.workState.waiters {
+	s.workState.waiters.drain(func(_ int64, ch chan<- error) {
 		// Note: the top-level OTLP exporter will retry.
-		ch <- ErrStreamRestarting
-	}
-
-	s.workState.waiters = map[int64]chan<- error{}
+		ch <- arrowerrors.ErrStreamRestarting
+	})
 }
 
 // write repeatedly places this stream into the next-available queue, then
@@ -265,30 +516,93 @@ func (s *Stream) write(ctx context.Context) (retErr error) {
 		defer timer.Stop()
 	}
 
+	hb := s.newHeartbeat()
+	defer hb.stop()
+
+	if s.pipelinedSend {
+		s.sender = s.startSender()
+		defer func() {
+			if err := s.sender.stop(); retErr == nil {
+				retErr = err
+			}
+			s.sender = nil
+		}()
+	}
+
+	if pp := s.pipelinedProducer(); pp != nil {
+		return s.writePipelined(ctx, pp, timerCh, hb, &hdrsBuf, hdrsEnc)
+	}
+
+	// next is a batch taken while coalescing that could not be
+	// combined with the previous ones.
+	var next *writeItem
+	defer func() {
+		if next != nil {
+			// The caller retries on another stream.
+			next.errCh <- arrowerrors.ErrStreamRestarting
+		}
+	}()
+
 	for {
+		if s.sentIDs.exhausted(s.maxBatchID) {
+			s.endReason = streamevents.ReasonBatchIDsExhausted
+			return nil
+		}
+
 		// this can block, and if the context is canceled we
 		// wait for the reader to find this stream.
 		var wri writeItem
-		select {
-		case <-timerCh:
-			return nil
-		case wri = <-s.workState.toWrite:
-		case <-ctx.Done():
-			return ctx.Err()
+		if next != nil {
+			wri, next = *next, nil
+		} else {
+			select {
+			case <-timerCh:
+				s.endReason = streamevents.ReasonLifetime
+				return nil
+			case <-s.workState.recycle:
+				s.endReason = streamevents.ReasonReconfigured
+				return nil
+			case <-s.workState.retired:
+				s.endReason = streamevents.ReasonReconfigured
+				return nil
+			case <-s.sendFailed():
+				return nil
+			case <-hb.C():
+				beat, ok, err := hb.next()
+				if ok {
+					err = s.sendHeartbeat(beat)
+				}
+				if err != nil {
+					return err
+				}
+				continue
+			case wri = <-s.workState.toWrite:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		}
+		wri, next = s.coalesce(wri)
 
 		err := s.encodeAndSend(wri, &hdrsBuf, hdrsEnc)
+		if wri.copied != 0 {
+			// The copy of coalesced batches is not needed
+			// once encoded.
+			s.memory.release(int64(wri.copied))
+		}
 		if err != nil {
 			// Note: For the return statement below, there is no potential
 			// sender race because the stream is not available, as indicated by
 			// the successful <-stream.toWrite above
 			return err
 		}
+		hb.reset()
 	}
 }
 
 func (s *Stream) encodeAndSend(wri writeItem, hdrsBuf *bytes.Buffer, hdrsEnc *hpack.Encoder) (retErr error) {
-	ctx, span := s.tracer.Start(wri.producerCtx, "otel_arrow_stream_send")
+	ctx, span := s.tracer.Start(wri.producerCtx, "otel_arrow_stream_send",
+		trace.WithAttributes(attribute.String(streamevents.StreamIDKey, s.id)),
+		trace.WithLinks(wri.producerLinks()...))
 	defer span.End()
 
 	defer func() {
@@ -315,18 +629,43 @@ func (s *Stream) encodeAndSend(wri writeItem, hdrsBuf *bytes.Buffer, hdrsEnc *hp
 		otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(wri.md))
 	}
 
-	batch, err := s.encode(wri.records)
+	batch, err := s.encode(wri)
 	if err != nil {
 		// This is some kind of internal error.  We will restart the
 		// stream and mark this record as a permanent one.
-		err = fmt.Errorf("encode: %w", err)
+		err = fmt.Errorf("%w: %w", arrowerrors.ErrEncode, err)
 		wri.errCh <- consumererror.NewPermanent(err)
+		if errors.Is(err, acommon.ErrUnsupported) {
+			// The producer rejected the data before encoding
+			// it, and resets itself, so the stream continues.
+			return nil
+		}
 		return err
 	}
+	// The caller, e.g., a batch processor adapting its batch size,
+	// may want to know how well the batch compressed.
+	if compressed := payloadSize(batch); compressed != 0 {
+		wri.reportCompression(compressed)
+		s.recordCompressionRatio(wri, compressed)
+	}
+
+	if s.checksums && len(batch.ArrowPayloads) != 0 {
+		if wri.md == nil {
+			wri.md = map[string]string{}
+		}
+		wri.md[checksumHeader] = batchChecksum(batch)
+	}
 
 	// Optionally include outgoing metadata, if present.
 	if len(wri.md) != 0 {
 		hdrsBuf.Reset()
+		if s.resetHeaders.CompareAndSwap(true, false) {
+			// Begins the block with dynamic table size
+			// updates to zero and back, which evict the
+			// entries of both tables.
+			hdrsEnc.SetMaxDynamicTableSize(0)
+			hdrsEnc.SetMaxDynamicTableSize(hpackMaxDynamicSize)
+		}
 		for key, val := range wri.md {
 			err := hdrsEnc.WriteField(hpack.HeaderField{
 				Name:  key,
@@ -336,16 +675,30 @@ func (s *Stream) encodeAndSend(wri writeItem, hdrsBuf *bytes.Buffer, hdrsEnc *hp
 				// This case is like the encode-failure case
 				// above, we will restart the stream but consider
 				// this a permenent error.
-				err = fmt.Errorf("hpack: %w", err)
+				err = fmt.Errorf("%w: hpack: %w", arrowerrors.ErrEncode, err)
 				wri.errCh <- consumererror.NewPermanent(err)
+				s.release(batch)
 				return err
 			}
 		}
 		batch.Headers = hdrsBuf.Bytes()
+		if s.sender != nil {
+			// The buffer is reused before the batch is sent.
+			batch.Headers = bytes.Clone(batch.Headers)
+		}
+	}
+
+	if err := s.checkBatchSize(batch); err != nil {
+		// The producer's state includes the batch, so the
+		// stream restarts, as for encode failures.
+		wri.errCh <- err
+		s.release(batch)
+		return err
 	}
 
 	// Let the receiver knows what to look for.
 	s.setBatchChannel(batch.BatchId, wri.errCh)
+	s.recorder.recordSend(s.id, batch.BatchId)
 
 	// The netstats code knows that uncompressed size is
 	// unreliable for arrow transport, so we instrument it
@@ -353,60 +706,92 @@ func (s *Stream) encodeAndSend(wri writeItem, hdrsBuf *bytes.Buffer, hdrsEnc *hp
 	// is instrumented this way.
 	var sized netstats.SizesStruct
 	sized.Method = s.method
+	sized.Stream = s.id
 	sized.Length = int64(wri.uncompSize)
 	s.netReporter.CountSend(ctx, sized)
 
-	if err := s.client.Send(batch); err != nil {
-		// The error will be sent to errCh during cleanup for this stream.
-		// Note: do not wrap this error, it may contain a Status.
-		return err
+	// On error, the waiter is released during cleanup for this
+	// stream.
+	return s.send(batch)
+}
+
+// release returns the buffers of a sent batch to the producer, if it
+// pools them.
+func (s *Stream) release(batch *arrowpb.BatchArrowRecords) {
+	if r, ok := s.producer.(arrowRecord.BatchReleaser); ok {
+		r.Release(batch)
 	}
+}
 
-	return nil
+// payloadSize returns the size of the Arrow payloads of a batch.
+func payloadSize(batch *arrowpb.BatchArrowRecords) (size int) {
+	for _, payload := range batch.ArrowPayloads {
+		size += len(payload.Record)
+	}
+	return size
 }
 
 // read repeatedly reads a batch status and releases the consumers waiting for
 // a response.
-func (s *Stream) read(_ context.Context) error {
-	// Note we do not use the context, the stream context might
-	// cancel a call to Recv() but the call to processBatchStatus
-	// is non-blocking.
+func (s *Stream) read(ctx context.Context) error {
+	// Note we do not use the context to interrupt, the stream
+	// context might cancel a call to Recv() but the call to
+	// processBatchStatus is non-blocking.
+	if s.capabilities != nil {
+		if err := s.negotiate(); err != nil {
+			return err
+		}
+	}
+	s.establish.streamOpened()
 	for {
 		// Note: if the client has called CloseSend() and is waiting for a response from the server.
 		// And if the server fails for some reason, we will wait until some other condition, such as a context
-		// timeout.  TODO: possibly, improve to wait for no outstanding requests and then stop reading.
+		// timeout, or the watchdog configured by WithAckTimeout.
 		resp, err := s.client.Recv()
+		resp, err = s.faults.afterRecv(s.workState.id, resp, err)
+		if s.redactErrors && resp != nil {
+			resp.StatusMessage = redact.Message(resp.StatusMessage)
+		}
+		s.recorder.recordRecv(s.id, resp, err)
 		if err != nil {
 			// Note: do not wrap, contains a Status.
 			return err
 		}
 
+		// As for the data sent in encodeAndSend, the netstats
+		// code does not see the uncompressed size of the
+		// acknowledgements, so we instrument it here.
+		var sized netstats.SizesStruct
+		sized.Method = s.method
+		sized.Stream = s.id
+		sized.Length = int64(proto.Size(resp))
+		s.netReporter.CountReceive(ctx, sized)
+
+		s.markProgress()
+		s.statusCount.Add(1)
+		s.establish.statusReceived()
 		if err = s.processBatchStatus(resp); err != nil {
 			return fmt.Errorf("process: %w", err)
 		}
 	}
 }
 
-// getSenderChannel takes the stream lock and removes the corresonding
-// sender channel.
-func (sws *streamWorkState) getSenderChannel(status *arrowpb.BatchStatus) (chan<- error, error) {
-	sws.lock.Lock()
-	defer sws.lock.Unlock()
-
-	ch, ok := sws.waiters[status.BatchId]
+// getSenderChannel removes the corresonding sender channel.
+func (s *Stream) getSenderChannel(status *arrowpb.BatchStatus) (chan<- error, error) {
+	ch, sent, ok := s.workState.waiters.take(status.BatchId)
 	if !ok {
 		// Will break the stream.
-		return nil, fmt.Errorf("unrecognized batch ID: %d", status.BatchId)
+		return nil, s.sentIDs.unrecognized(s.id, status.BatchId)
 	}
-
-	delete(sws.waiters, status.BatchId)
+	s.workState.observeLatency(time.Since(sent))
 	return ch, nil
 }
 
 // processBatchStatus processes a single response from the server and unblocks the
 // associated sender.
 func (s *Stream) processBatchStatus(ss *arrowpb.BatchStatus) error {
-	ch, ret := s.workState.getSenderChannel(ss)
+	s.credits.release(ss)
+	ch, ret := s.getSenderChannel(ss)
 
 	if ch == nil {
 		// In case getSenderChannels encounters a problem, the
@@ -415,6 +800,10 @@ func (s *Stream) processBatchStatus(ss *arrowpb.BatchStatus) error {
 	}
 
 	if ss.StatusCode == arrowpb.StatusCode_OK {
+		if !s.succeeded {
+			s.succeeded = true
+			s.health.streamSucceeded()
+		}
 		ch <- nil
 		return nil
 	}
@@ -428,6 +817,7 @@ func (s *Stream) processBatchStatus(ss *arrowpb.BatchStatus) error {
 		err = status.Errorf(codes.Unavailable, "destination unavailable: %d: %s", ss.BatchId, ss.StatusMessage)
 	case arrowpb.StatusCode_INVALID_ARGUMENT:
 		// Not retryable
+		s.resetHeaders.Store(true)
 		err = status.Errorf(codes.InvalidArgument, "invalid argument: %d: %s", ss.BatchId, ss.StatusMessage)
 	case arrowpb.StatusCode_RESOURCE_EXHAUSTED:
 		// Retry behavior is configurable
@@ -444,28 +834,32 @@ func (s *Stream) processBatchStatus(ss *arrowpb.BatchStatus) error {
 		// Will break the stream.
 		ret = multierr.Append(ret, err)
 	}
+	fields := []zap.Field{
+		zap.Int64("batch_id", ss.BatchId),
+		zap.Stringer(streamevents.CodeKey, ss.StatusCode),
+		zap.String("message", ss.StatusMessage),
+	}
+	if ss.ReceiverId != "" {
+		// Tells which collector rejected the batch, e.g.,
+		// behind a load balancer.
+		fields = append(fields, zap.String(streamevents.ReceiverIDKey, ss.ReceiverId))
+	}
+	s.telemetry.Logger.Debug("batch rejected", fields...)
 	ch <- err
 	return ret
 }
 
 // encode produces the next batch of Arrow records.
-func (s *Stream) encode(records any) (_ *arrowpb.BatchArrowRecords, retErr error) {
+func (s *Stream) encode(wri writeItem) (_ *arrowpb.BatchArrowRecords, retErr error) {
 	// Defensively, protect against panics in the Arrow producer function.
-	defer func() {
-		if err := recover(); err != nil {
-			// When this happens, the stacktrace is
-			// important and lost if we don't capture it
-			// here.
-			s.telemetry.Logger.Debug("panic detail in otel-arrow-adapter",
-				zap.Reflect("recovered", err),
-				zap.Stack("stacktrace"),
-			)
-			retErr = fmt.Errorf("panic in otel-arrow-adapter: %v", err)
-		}
-	}()
+	defer s.recoverProducerPanic(&retErr)
+
+	if wri.built != nil {
+		return s.producer.(arrowRecord.PipelinedProducer).BatchArrowRecordsFromBuilt(wri.built)
+	}
 	var batch *arrowpb.BatchArrowRecords
 	var err error
-	switch data := records.(type) {
+	switch data := wri.records.(type) {
 	case ptrace.Traces:
 		batch, err = s.producer.BatchArrowRecordsFromTraces(data)
 	case plog.Logs:
@@ -473,7 +867,22 @@ func (s *Stream) encode(records any) (_ *arrowpb.BatchArrowRecords, retErr error
 	case pmetric.Metrics:
 		batch, err = s.producer.BatchArrowRecordsFromMetrics(data)
 	default:
-		return nil, fmt.Errorf("unsupported OTLP type: %T", records)
+		return nil, fmt.Errorf("unsupported OTLP type: %T", wri.records)
 	}
 	return batch, err
 }
+
+// recoverProducerPanic is deferred by calls into the Arrow producer,
+// it converts a panic into an error.
+func (s *Stream) recoverProducerPanic(retErr *error) {
+	if err := recover(); err != nil {
+		// When this happens, the stacktrace is
+		// important and lost if we don't capture it
+		// here.
+		s.telemetry.Logger.Debug("panic detail in otel-arrow-adapter",
+			zap.Reflect("recovered", err),
+			zap.Stack("stacktrace"),
+		)
+		*retErr = fmt.Errorf("panic in otel-arrow-adapter: %v", err)
+	}
+}
@@ -4,19 +4,30 @@
 package arrow
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"testing"
 	"time"
 
 	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
 	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/collector/pdatasize"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
 	arrowRecordMock "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record/mock"
+	acommon "github.com/open-telemetry/otel-arrow/pkg/otel/common/arrow"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"golang.org/x/net/http2/hpack"
 	"google.golang.org/grpc"
 )
 
@@ -183,11 +194,42 @@ func TestStreamEncodeError(t *testing.T) {
 			err := tc.mustSendAndWait()
 			require.Error(t, err)
 			require.True(t, errors.Is(err, testErr))
+			require.ErrorIs(t, err, arrowerrors.ErrEncode)
 			require.True(t, consumererror.IsPermanent(err))
 		})
 	}
 }
 
+// TestStreamUnsupportedData verifies that data rejected by the
+// producer yields a permanent error without restarting the stream.
+func TestStreamUnsupportedData(t *testing.T) {
+	for _, pname := range AllPrioritizers {
+		t.Run(string(pname), func(t *testing.T) {
+			tc := newStreamTestCase(t, pname)
+
+			unsupported := acommon.NewLimitError("span-attrs", math.MaxUint16)
+			tc.fromTracesCall.Times(1).Return(nil, unsupported)
+			tc.producer.EXPECT().BatchArrowRecordsFromTraces(gomock.Any()).Times(1).Return(oneBatch, nil)
+
+			channel := newHealthyTestChannel()
+			tc.start(channel)
+			defer tc.cancelAndWaitForShutdown()
+
+			err := tc.mustSendAndWait()
+			require.ErrorIs(t, err, acommon.ErrUnsupported)
+			require.ErrorIs(t, err, arrowerrors.ErrEncode)
+			require.True(t, consumererror.IsPermanent(err))
+
+			// The same stream sends the next batch.
+			go func() {
+				batch := <-channel.sent
+				channel.recv <- statusOKFor(batch.BatchId)
+			}()
+			require.NoError(t, tc.mustSendAndWait())
+		})
+	}
+}
+
 // TestStreamUnknownBatchError verifies that the stream reader handles
 // a unknown BatchID.
 func TestStreamUnknownBatchError(t *testing.T) {
@@ -209,10 +251,10 @@ func TestStreamUnknownBatchError(t *testing.T) {
 				<-channel.sent
 				channel.recv <- statusOKFor(-1 /*unknown*/)
 			}()
-			// sender should get ErrStreamRestarting
+			// sender should get arrowerrors.ErrStreamRestarting
 			err := tc.mustSendAndWait()
 			require.Error(t, err)
-			require.True(t, errors.Is(err, ErrStreamRestarting))
+			require.True(t, errors.Is(err, arrowerrors.ErrStreamRestarting))
 		})
 	}
 }
@@ -257,6 +299,183 @@ func TestStreamStatusUnavailableInvalid(t *testing.T) {
 	}
 }
 
+// TestStreamStatusReceiverID verifies that a rejected batch is logged
+// with the identity of the receiver.
+func TestStreamStatusReceiverID(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	core, logs := observer.New(zapcore.DebugLevel)
+	tc.stream.telemetry.Logger = zap.New(core)
+
+	tc.fromTracesCall.Times(1).Return(oneBatch, nil)
+
+	channel := newHealthyTestChannel()
+	tc.start(channel)
+	defer tc.cancelAndWaitForShutdown()
+
+	go func() {
+		batch := <-channel.sent
+		ss := statusInvalidFor(batch.BatchId)
+		ss.ReceiverId = "collector-1"
+		channel.recv <- ss
+	}()
+	err := tc.mustSendAndWait()
+	require.Error(t, err)
+
+	rejected := logs.FilterMessage("batch rejected").All()
+	require.Len(t, rejected, 1)
+	require.Equal(t, "collector-1", rejected[0].ContextMap()[streamevents.ReceiverIDKey])
+	require.Equal(t, "INVALID_ARGUMENT", rejected[0].ContextMap()[streamevents.CodeKey])
+}
+
+// TestStreamResetHeadersOnInvalid verifies that a batch rejected with
+// InvalidArgument empties the hpack dynamic table before the next
+// header block, so that a receiver that reset its decoder can decode
+// it.
+func TestStreamResetHeadersOnInvalid(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+
+	var nextID int64
+	tc.fromTracesCall.Times(3).DoAndReturn(func(_ ptrace.Traces) (*arrowpb.BatchArrowRecords, error) {
+		nextID++
+		return &arrowpb.BatchArrowRecords{BatchId: nextID}, nil
+	})
+
+	channel := newHealthyTestChannel()
+	tc.start(channel)
+	defer tc.cancelAndWaitForShutdown()
+
+	var headers [][]byte
+	var wg sync.WaitGroup
+	wg.Add(1)
+	defer wg.Wait()
+	go func() {
+		defer wg.Done()
+		for _, status := range []func(int64) *arrowpb.BatchStatus{statusOKFor, statusInvalidFor, statusOKFor} {
+			batch := <-channel.sent
+			headers = append(headers, bytes.Clone(batch.Headers))
+			channel.recv <- status(batch.BatchId)
+		}
+	}()
+
+	send := func() error {
+		ch := make(chan error, 1)
+		return tc.mustGet().sendAndWait(context.Background(), ch, writeItem{
+			producerCtx: context.Background(),
+			records:     twoTraces,
+			md:          map[string]string{"tenant": "a-rather-long-tenant-identifier"},
+			errCh:       ch,
+		})
+	}
+	require.NoError(t, send())
+	require.ErrorContains(t, send(), "test invalid")
+	require.NoError(t, send())
+	wg.Wait()
+
+	decode := func(dec *hpack.Decoder, block []byte) error {
+		_, err := dec.Write(block)
+		if err == nil {
+			err = dec.Close()
+		}
+		return err
+	}
+	// The second block refers to the dynamic table, the third
+	// does not.
+	require.Error(t, decode(hpack.NewDecoder(hpackMaxDynamicSize, nil), headers[1]))
+	var fields []hpack.HeaderField
+	dec := hpack.NewDecoder(hpackMaxDynamicSize, func(hf hpack.HeaderField) {
+		fields = append(fields, hf)
+	})
+	require.NoError(t, decode(dec, headers[2]))
+	require.Equal(t, []hpack.HeaderField{{Name: "tenant", Value: "a-rather-long-tenant-identifier"}}, fields)
+}
+
+// TestStreamChecksums verifies that the stream attaches the checksum
+// of the payloads to the headers of a batch.
+func TestStreamChecksums(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	tc.stream.checksums = true
+
+	withPayloads := &arrowpb.BatchArrowRecords{
+		BatchId: 1,
+		ArrowPayloads: []*arrowpb.ArrowPayload{{
+			SchemaId: "0",
+			Type:     arrowpb.ArrowPayloadType_SPANS,
+			Record:   []byte("not really arrow"),
+		}},
+	}
+	expected := batchChecksum(withPayloads)
+	tc.fromTracesCall.Times(1).Return(withPayloads, nil)
+
+	channel := newHealthyTestChannel()
+	tc.start(channel)
+	defer tc.cancelAndWaitForShutdown()
+
+	var headers []byte
+	var wg sync.WaitGroup
+	wg.Add(1)
+	defer wg.Wait()
+	go func() {
+		defer wg.Done()
+		batch := <-channel.sent
+		headers = bytes.Clone(batch.Headers)
+		channel.recv <- statusOKFor(batch.BatchId)
+	}()
+
+	require.NoError(t, tc.mustSendAndWait())
+	wg.Wait()
+
+	var fields []hpack.HeaderField
+	dec := hpack.NewDecoder(hpackMaxDynamicSize, func(hf hpack.HeaderField) {
+		fields = append(fields, hf)
+	})
+	_, err := dec.Write(headers)
+	require.NoError(t, err)
+	require.Equal(t, []hpack.HeaderField{{Name: checksumHeader, Value: expected}}, fields)
+
+	// The checksum covers every field of the payloads.
+	withPayloads.ArrowPayloads[0].SchemaId = "1"
+	require.NotEqual(t, expected, batchChecksum(withPayloads))
+}
+
+// TestStreamReportCompression verifies that the size of the encoded
+// payloads is reported to the caller.
+func TestStreamReportCompression(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+
+	withPayloads := &arrowpb.BatchArrowRecords{
+		BatchId: 1,
+		ArrowPayloads: []*arrowpb.ArrowPayload{{
+			SchemaId: "0",
+			Type:     arrowpb.ArrowPayloadType_SPANS,
+			Record:   []byte("not really arrow"),
+		}},
+	}
+	tc.fromTracesCall.Times(1).Return(withPayloads, nil)
+
+	channel := newHealthyTestChannel()
+	tc.start(channel)
+	defer tc.cancelAndWaitForShutdown()
+
+	go func() {
+		batch := <-channel.sent
+		channel.recv <- statusOKFor(batch.BatchId)
+	}()
+
+	var uncompressed, compressed int
+	ch := make(chan error, 1)
+	wri := writeItem{
+		producerCtx: pdatasize.ContextWithCompressionReporter(context.Background(), func(u, c int) {
+			uncompressed, compressed = u, c
+		}),
+		records:    twoTraces,
+		uncompSize: 1000,
+		errCh:      ch,
+	}
+	require.NoError(t, tc.mustGet().sendAndWait(context.Background(), ch, wri))
+	require.Equal(t, 1000, uncompressed)
+	require.Equal(t, len("not really arrow"), compressed)
+}
+
 // TestStreamStatusUnrecognized verifies that the stream reader handles
 // an unrecognized status by breaking the stream.
 func TestStreamStatusUnrecognized(t *testing.T) {
@@ -313,7 +532,7 @@ func TestStreamUnsupported(t *testing.T) {
 			}()
 
 			err := tc.mustSendAndWait()
-			require.Equal(t, ErrStreamRestarting, err)
+			require.Equal(t, arrowerrors.ErrStreamRestarting, err)
 
 			tc.waitForShutdown()
 
@@ -340,10 +559,10 @@ func TestStreamSendError(t *testing.T) {
 				time.Sleep(200 * time.Millisecond)
 				channel.unblock()
 			}()
-			// sender should get ErrStreamRestarting
+			// sender should get arrowerrors.ErrStreamRestarting
 			err := tc.mustSendAndWait()
 			require.Error(t, err)
-			require.True(t, errors.Is(err, ErrStreamRestarting))
+			require.True(t, errors.Is(err, arrowerrors.ErrStreamRestarting))
 		})
 	}
 }
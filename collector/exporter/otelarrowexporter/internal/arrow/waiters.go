@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// waiterShards is the number of independently locked parts of a
+// waiterMap.  Batch IDs are sequential, so consecutive batches use
+// different shards.
+const waiterShards = 16
+
+// waiterMap holds the response channel of each batch awaiting a
+// status, with the time it was sent.  The stream writer adds batches while the stream reader
+// removes them; sharding by batch ID keeps the two from contending
+// at high batch rates.  The zero value is ready to use.
+type waiterMap struct {
+	shards [waiterShards]waiterShard
+
+	// count is the number of waiters, read without locking by
+	// the load-balancing prioritizer.
+	count atomic.Int64
+}
+
+type waiterShard struct {
+	lock    sync.Mutex
+	waiters map[int64]waiter
+
+	// pad places each shard in its own cache line.
+	_ [48]byte
+}
+
+// waiter is the response channel of one batch and its send time.
+type waiter struct {
+	errCh chan<- error
+	sent  time.Time
+}
+
+func (w *waiterMap) shard(batchID int64) *waiterShard {
+	return &w.shards[uint64(batchID)%waiterShards]
+}
+
+// set adds the response channel of a batch sent now.
+func (w *waiterMap) set(batchID int64, errCh chan<- error) {
+	sent := time.Now()
+	sh := w.shard(batchID)
+	sh.lock.Lock()
+	defer sh.lock.Unlock()
+
+	if sh.waiters == nil {
+		sh.waiters = map[int64]waiter{}
+	}
+	if _, ok := sh.waiters[batchID]; !ok {
+		w.count.Add(1)
+	}
+	sh.waiters[batchID] = waiter{errCh: errCh, sent: sent}
+}
+
+// take removes and returns the response channel of a batch, with
+// the time it was sent.
+func (w *waiterMap) take(batchID int64) (chan<- error, time.Time, bool) {
+	sh := w.shard(batchID)
+	sh.lock.Lock()
+	defer sh.lock.Unlock()
+
+	wt, ok := sh.waiters[batchID]
+	if ok {
+		delete(sh.waiters, batchID)
+		w.count.Add(-1)
+	}
+	return wt.errCh, wt.sent, ok
+}
+
+// len returns the number of waiters.
+func (w *waiterMap) len() int {
+	return int(w.count.Load())
+}
+
+// drain removes every waiter, calling f for each.
+func (w *waiterMap) drain(f func(batchID int64, errCh chan<- error)) {
+	for i := range w.shards {
+		sh := &w.shards[i]
+		sh.lock.Lock()
+		for id, wt := range sh.waiters {
+			delete(sh.waiters, id)
+			w.count.Add(-1)
+			f(id, wt.errCh)
+		}
+		sh.lock.Unlock()
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaiterMap(t *testing.T) {
+	var w waiterMap
+	chans := map[int64]chan error{}
+	for id := int64(0); id < 100; id++ {
+		ch := make(chan error, 1)
+		chans[id] = ch
+		w.set(id, ch)
+	}
+	require.Equal(t, 100, w.len())
+
+	// Setting an existing ID replaces it without counting twice.
+	w.set(5, chans[5])
+	require.Equal(t, 100, w.len())
+
+	before := time.Now()
+	w.set(7, chans[7])
+	ch, sent, ok := w.take(7)
+	require.True(t, ok)
+	require.Equal(t, chan<- error(chans[7]), ch)
+	require.False(t, sent.Before(before))
+	_, _, ok = w.take(7)
+	require.False(t, ok)
+	require.Equal(t, 99, w.len())
+
+	var drained []int64
+	w.drain(func(id int64, _ chan<- error) {
+		drained = append(drained, id)
+	})
+	require.Len(t, drained, 99)
+	require.NotContains(t, drained, int64(7))
+	require.Equal(t, 0, w.len())
+}
+
+func TestWaiterMapConcurrent(t *testing.T) {
+	var w waiterMap
+	const n = 10000
+	ids := make(chan int64, n)
+
+	var wg sync.WaitGroup
+	var taken atomic.Int64
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer close(ids)
+		ch := make(chan error)
+		for id := int64(0); id < n; id++ {
+			w.set(id, ch)
+			ids <- id
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for id := range ids {
+			if _, _, ok := w.take(id); ok {
+				taken.Add(1)
+			}
+		}
+	}()
+	wg.Wait()
+	require.Equal(t, int64(n), taken.Load())
+	require.Equal(t, 0, w.len())
+}
+
+// mutexWaiterMap is the single-lock structure that waiterMap
+// replaced, for comparison.
+type mutexWaiterMap struct {
+	lock    sync.Mutex
+	waiters map[int64]waiter
+}
+
+func (m *mutexWaiterMap) set(id int64, ch chan<- error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.waiters[id] = waiter{errCh: ch, sent: time.Now()}
+}
+
+func (m *mutexWaiterMap) take(id int64) (chan<- error, time.Time, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	wt, ok := m.waiters[id]
+	delete(m.waiters, id)
+	return wt.errCh, wt.sent, ok
+}
+
+func (m *mutexWaiterMap) len() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.waiters)
+}
+
+type waiters interface {
+	set(int64, chan<- error)
+	take(int64) (chan<- error, time.Time, bool)
+	len() int
+}
+
+// benchmarkWaiters has parallel goroutines each acting as a writer
+// setting, a reader taking, and a prioritizer reading the length.
+func benchmarkWaiters(b *testing.B, w waiters) {
+	var next atomic.Int64
+	ch := make(chan error)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			id := next.Add(1)
+			w.set(id, ch)
+			_ = w.len()
+			w.take(id)
+		}
+	})
+}
+
+func BenchmarkWaitersSharded(b *testing.B) {
+	benchmarkWaiters(b, &waiterMap{})
+}
+
+func BenchmarkWaitersMutex(b *testing.B) {
+	benchmarkWaiters(b, &mutexWaiterMap{waiters: map[int64]waiter{}})
+}
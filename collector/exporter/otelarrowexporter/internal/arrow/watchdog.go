@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+)
+
+// WithAckTimeout restarts a stream when it has batches awaiting
+// their status and no status has arrived for the timeout.  Without
+// it, a stream whose receiver stopped responding waits until the
+// senders' deadlines expire or the stream lifetime ends.  The
+// senders of the batches awaiting status retry them on another
+// stream.  Zero disables the watchdog.
+func WithAckTimeout(timeout time.Duration) Option {
+	return func(e *Exporter) {
+		e.ackTimeout = timeout
+	}
+}
+
+// watchdogChecks is the number of times per timeout that the
+// watchdog checks for progress, at most once per
+// minWatchdogPeriod, so that short timeouts do not spin, nor yield
+// the zero period that time.NewTicker rejects.
+const (
+	watchdogChecks    = 4
+	minWatchdogPeriod = time.Millisecond
+)
+
+// markProgress records that the stream received a status, or that
+// it sent a batch when none were awaiting status.
+func (s *Stream) markProgress() {
+	if s.ackTimeout > 0 {
+		s.lastProgress.Store(time.Now().UnixNano())
+	}
+}
+
+// watchdog returns an error wrapping arrowerrors.ErrAckTimeout when
+// the stream has batches awaiting their status and has made no
+// progress for the ack timeout, or nil when ctx is done.
+func (s *Stream) watchdog(ctx context.Context) error {
+	ticker := time.NewTicker(max(s.ackTimeout/watchdogChecks, minWatchdogPeriod))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			if s.workState.waiters.len() == 0 {
+				continue
+			}
+			idle := now.Sub(time.Unix(0, s.lastProgress.Load()))
+			if idle >= s.ackTimeout {
+				return fmt.Errorf("%w: no status received for %v with %d batches pending",
+					arrowerrors.ErrAckTimeout, idle.Round(time.Millisecond), s.workState.waiters.len())
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestStreamAckTimeout verifies that a stream whose receiver stops
+// responding is restarted by the watchdog.
+func TestStreamAckTimeout(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	tc.stream.ackTimeout = 20 * time.Millisecond
+
+	tc.fromTracesCall.Times(1).Return(oneBatch, nil)
+
+	tc.start(newUnresponsiveTestChannel())
+
+	// The sender retries on another stream.
+	err := tc.mustSendAndWait()
+	require.ErrorIs(t, err, arrowerrors.ErrStreamRestarting)
+	tc.waitForShutdown()
+
+	logs := tc.observedLogs.FilterMessage("arrow stream error").FilterField(zap.String("which", "watchdog")).All()
+	require.Len(t, logs, 1)
+	require.Contains(t, logs[0].ContextMap()["message"], arrowerrors.ErrAckTimeout.Error())
+	require.Equal(t, streamevents.StreamError, logs[0].ContextMap()[streamevents.EventKey])
+}
+
+// TestStreamAckTimeoutIdle verifies that the watchdog does not
+// restart a stream with no batches awaiting status.
+func TestStreamAckTimeoutIdle(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	tc.stream.ackTimeout = 10 * time.Millisecond
+
+	tc.start(newUnresponsiveTestChannel())
+	time.Sleep(50 * time.Millisecond)
+	tc.cancelAndWaitForShutdown()
+
+	require.Empty(t, tc.observedLogs.FilterMessage("arrow stream error").All())
+}
+
+// TestStreamAckTimeoutShort verifies that a timeout shorter than
+// watchdogChecks nanoseconds does not yield a zero ticker period.
+func TestStreamAckTimeoutShort(t *testing.T) {
+	tc := newStreamTestCase(t, DefaultPrioritizer)
+	tc.stream.ackTimeout = watchdogChecks - 1
+
+	tc.fromTracesCall.Times(1).Return(oneBatch, nil)
+
+	tc.start(newUnresponsiveTestChannel())
+
+	err := tc.mustSendAndWait()
+	require.ErrorIs(t, err, arrowerrors.ErrStreamRestarting)
+	tc.waitForShutdown()
+}
@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowexporter // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+
+import (
+	"context"
+	"time"
+
+	"github.com/open-telemetry/otel-arrow/pkg/memorypressure"
+)
+
+// memoryPressureInterval is the period at which the Go heap is
+// compared with memory_pressure_mib.
+const memoryPressureInterval = time.Second
+
+// startMemoryPressure watches the Go heap until shutdown, signaling
+// the Arrow producers each time it exceeds memory_pressure_mib.
+func (e *baseExporter) startMemoryPressure() {
+	var ctx context.Context
+	ctx, e.memoryPressureCancel = context.WithCancel(context.Background())
+	e.memoryPressure = memorypressure.NewNotifier()
+	e.memoryPressureDone = make(chan struct{})
+
+	go func() {
+		defer close(e.memoryPressureDone)
+		e.memoryPressure.Watch(ctx, memoryPressureInterval, e.config.Arrow.MemoryPressureMiB<<20)
+	}()
+}
+
+// stopMemoryPressure stops the heap watcher and waits for it to
+// return.
+func (e *baseExporter) stopMemoryPressure() {
+	if e.memoryPressureCancel == nil {
+		return
+	}
+	e.memoryPressureCancel()
+	<-e.memoryPressureDone
+}
@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowexporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+)
+
+// TestMemoryPressure verifies that memory_pressure_mib signals the
+// producers while the heap exceeds it, until shutdown.
+func TestMemoryPressure(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "localhost:4317"
+	cfg.Arrow.NumStreams = 1
+	// The heap of the test exceeds 1 MiB.
+	cfg.Arrow.MemoryPressureMiB = 1
+
+	e, err := newExporter(cfg, exportertest.NewNopCreateSettings(), component.DataTypeTraces, createArrowTracesStream)
+	require.NoError(t, err)
+	require.NoError(t, e.start(context.Background(), componenttest.NewNopHost()))
+	require.NotNil(t, e.memoryPressure)
+
+	signaled := make(chan struct{}, 1)
+	e.memoryPressure.Register(func() {
+		select {
+		case signaled <- struct{}{}:
+		default:
+		}
+	})
+	select {
+	case <-signaled:
+	case <-time.After(10 * time.Second):
+		t.Fatal("no memory-pressure signal")
+	}
+
+	require.NoError(t, e.shutdown(context.Background()))
+	select {
+	case <-e.memoryPressureDone:
+	default:
+		t.Fatal("heap watcher running after shutdown")
+	}
+}
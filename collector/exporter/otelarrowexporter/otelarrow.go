@@ -7,15 +7,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	arrowPkg "github.com/apache/arrow/go/v14/arrow"
+	"github.com/open-telemetry/otel-arrow/collector/arrowconfig"
+	"github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+	"github.com/open-telemetry/otel-arrow/collector/capability"
 	"github.com/open-telemetry/otel-arrow/collector/compression/zstd"
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/arrowerrors"
 	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/memorypressure"
 	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
+	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/config/configtelemetry"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
@@ -25,10 +39,13 @@ import (
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
@@ -54,24 +71,63 @@ type baseExporter struct {
 	// Default user-agent header.
 	userAgent string
 
+	// signal is the signal of the exporter.
+	signal component.DataType
+
 	// OTel-Arrow optional state
 	arrow *arrow.Exporter
+	// status is registered with the arrowzpages extension.
+	status *arrowzpages.Instance
 	// streamClientFunc is the stream constructor
 	streamClientFactory streamClientFactory
+
+	// emptyBatches counts the batches without items that were
+	// sent without Arrow payloads.
+	emptyBatches metric.Int64Counter
+
+	// schemaChurn counts the schema churn reports of the Arrow
+	// producers, see schemaChurnReporter.
+	schemaChurn metric.Int64Counter
+
+	// compressionRatio records the compression of each Arrow
+	// batch by signal, nil below the normal level of telemetry.
+	compressionRatio metric.Float64Histogram
+
+	// sendMetrics reports the Arrow send path with the metrics
+	// of the exporter helper.
+	sendMetrics *sendMetrics
+
+	// streamsGauge reports the number of streams, see
+	// registerStreams, nil before start.
+	streamsGauge metric.Registration
+
+	// selfTestCancel and selfTestDone stop the self-test, see
+	// startSelfTest, nil without one.
+	selfTestCancel context.CancelFunc
+	selfTestDone   chan struct{}
+
+	// memoryPressure signals the Arrow producers to reset, see
+	// startMemoryPressure, nil without memory_pressure_mib.
+	memoryPressure       *memorypressure.Notifier
+	memoryPressureCancel context.CancelFunc
+	memoryPressureDone   chan struct{}
 }
 
+const scopeName = "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+
 type streamClientFactory func(conn *grpc.ClientConn) arrow.StreamClientFunc
 
 // Crete new exporter and start it. The exporter will begin connecting but
 // this function may return before the connection is established.
-func newExporter(cfg component.Config, set exporter.CreateSettings, streamClientFactory streamClientFactory) (*baseExporter, error) {
+func newExporter(cfg component.Config, set exporter.CreateSettings, signal component.DataType, streamClientFactory streamClientFactory) (*baseExporter, error) {
 	oCfg := cfg.(*Config)
 
 	if oCfg.Endpoint == "" {
 		return nil, errors.New("OTLP exporter config requires an Endpoint")
 	}
+	oCfg.warnIdleStreams(set.Logger, signal)
 
-	netReporter, err := netstats.NewExporterNetworkReporter(set)
+	netReporter, err := netstats.NewExporterNetworkReporter(set, oCfg.netstatsOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -85,15 +141,67 @@ func newExporter(cfg component.Config, set exporter.CreateSettings, streamClient
 		userAgent += fmt.Sprintf(" ApacheArrow/%s (NumStreams/%d)", arrowPkg.PkgVersion, oCfg.Arrow.NumStreams)
 	}
 
+	meter := set.TelemetrySettings.MeterProvider.Meter(scopeName)
+	emptyBatches, err := meter.Int64Counter(
+		"otel_arrow_exporter_empty_batches",
+		metric.WithDescription("Number of batches without items sent without Arrow payloads"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	schemaChurn, err := meter.Int64Counter(
+		"otel_arrow_exporter_schema_churn",
+		metric.WithDescription("Number of times a stream changed the Arrow schema of a payload type frequently"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var compressionRatio metric.Float64Histogram
+	if set.TelemetrySettings.MetricsLevel >= configtelemetry.LevelNormal {
+		compressionRatio, err = meter.Float64Histogram(
+			"otel_arrow_exporter_compression_ratio",
+			metric.WithDescription("Ratio of the OTLP size of each batch to the size of its compressed Arrow payloads"),
+			metric.WithUnit("1"),
+			metric.WithExplicitBucketBoundaries(1, 2, 4, 6, 8, 12, 16, 24, 32, 48, 64),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sendMetrics, err := newSendMetrics(set, oCfg.Arrow.Prioritizer)
+	if err != nil {
+		return nil, err
+	}
+
 	return &baseExporter{
 		config:              oCfg,
 		settings:            set,
+		signal:              signal,
 		userAgent:           userAgent,
 		netReporter:         netReporter,
 		streamClientFactory: streamClientFactory,
+		emptyBatches:        emptyBatches,
+		schemaChurn:         schemaChurn,
+		compressionRatio:    compressionRatio,
+		sendMetrics:         sendMetrics,
 	}, nil
 }
 
+// connectParams bounds each attempt to connect by timeout.  gRPC
+// allows an attempt the larger of MinConnectTimeout and the current
+// backoff delay, so the delay, jitter included, is capped at timeout.
+func connectParams(timeout time.Duration) grpc.ConnectParams {
+	bo := backoff.DefaultConfig
+	bo.MaxDelay = min(bo.MaxDelay, time.Duration(float64(timeout)/(1+bo.Jitter)))
+	bo.BaseDelay = min(bo.BaseDelay, bo.MaxDelay)
+	return grpc.ConnectParams{
+		Backoff:           bo,
+		MinConnectTimeout: timeout,
+	}
+}
+
 // start actually creates the gRPC connection. The client construction is deferred till this point as this
 // is the only place we get hold of Extensions which are required to construct auth round tripper.
 func (e *baseExporter) start(ctx context.Context, host component.Host) (err error) {
@@ -103,6 +211,19 @@ func (e *baseExporter) start(ctx context.Context, host component.Host) (err erro
 	if e.netReporter != nil {
 		dialOpts = append(dialOpts, grpc.WithStatsHandler(e.netReporter.Handler()))
 	}
+	for _, h := range e.config.StatsHandlers {
+		dialOpts = append(dialOpts, grpc.WithStatsHandler(h))
+	}
+	if e.config.ConnectTimeout > 0 {
+		dialOpts = append(dialOpts, grpc.WithConnectParams(connectParams(e.config.ConnectTimeout)))
+	}
+	if e.config.ProxyURL != "" {
+		proxyOpt, err := proxyDialOption(string(e.config.ProxyURL))
+		if err != nil {
+			return err
+		}
+		dialOpts = append(dialOpts, proxyOpt)
+	}
 	dialOpts = append(dialOpts, e.config.UserDialOptions...)
 	if e.clientConn, err = e.config.ClientConfig.ToClientConn(ctx, host, e.settings.TelemetrySettings, dialOpts...); err != nil {
 		return err
@@ -137,7 +258,50 @@ func (e *baseExporter) start(ctx context.Context, host component.Host) (err erro
 			}
 		}
 
-		arrowOpts := e.config.Arrow.toArrowProducerOptions()
+		arrowCfg := e.effectiveConfig().Arrow
+		arrowOpts := arrowCfg.toArrowProducerOptions()
+
+		if e.config.Arrow.SchemaChurnThreshold > 0 {
+			arrowOpts = append(arrowOpts, config.WithSchemaChurnDetection(e.config.Arrow.SchemaChurnThreshold, &schemaChurnReporter{
+				logger:  e.settings.TelemetrySettings.Logger,
+				counter: e.schemaChurn,
+				level:   e.settings.TelemetrySettings.MetricsLevel,
+			}))
+		}
+
+		// Streams report their compression with their schema
+		// version.
+		streamParams := map[string]string{
+			"compression":         string(e.config.ClientConfig.Compression),
+			"payload_compression": string(e.effectiveConfig().Arrow.PayloadCompression),
+		}
+
+		if e.config.Arrow.ZstdDictionary != "" {
+			dict, id, err := e.config.Arrow.loadZstdDictionary()
+			if err != nil {
+				return err
+			}
+			arrowOpts = append(arrowOpts, config.WithZstdDictionary(dict))
+			// Declare the dictionary at stream start, so the
+			// receiver can reject a stream it cannot decode.
+			ctx = metadata.AppendToOutgoingContext(ctx, zstddict.Header, strconv.FormatUint(uint64(id), 10))
+			streamParams["zstd_dictionary"] = strconv.FormatUint(uint64(id), 10)
+		}
+
+		if e.config.Arrow.InstanceIdentity.Enabled {
+			id := e.instanceID()
+			ctx = metadata.AppendToOutgoingContext(ctx, streamevents.InstanceIDHeader, id)
+			labels := e.config.Arrow.InstanceIdentity.Labels
+			names := make([]string, 0, len(labels))
+			for name := range labels {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				ctx = metadata.AppendToOutgoingContext(ctx, streamevents.InstanceLabelsHeader, streamevents.FormatLabel(name, labels[name]))
+			}
+			streamParams["instance_id"] = id
+		}
 
 		arrowCallOpts := e.callOptions
 
@@ -148,23 +312,196 @@ func (e *baseExporter) start(ctx context.Context, host component.Host) (err erro
 			arrowCallOpts = append(arrowCallOpts, e.config.Arrow.Zstd.CallOption())
 		}
 
-		e.arrow = arrow.NewExporter(e.config.Arrow.MaxStreamLifetime, e.config.Arrow.NumStreams, e.config.Arrow.Prioritizer, e.config.Arrow.DisableDowngrade, e.settings.TelemetrySettings, arrowCallOpts, func() arrowRecord.ProducerAPI {
+		var arrowExpOpts []arrow.Option
+		if e.config.Arrow.PipelinedEncoding {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithPipelinedEncoding())
+		}
+		if e.config.Arrow.PipelinedSend {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithPipelinedSend())
+		}
+		if e.config.Arrow.CoalesceBatches > 1 {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithCoalescing(e.config.Arrow.CoalesceBatches))
+		}
+		if e.config.Arrow.IdempotencyKeys {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithIdempotencyKeys())
+		}
+		if e.config.Arrow.Checksums {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithChecksums())
+		}
+		if e.config.Arrow.HeartbeatInterval > 0 {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithHeartbeat(e.config.Arrow.HeartbeatInterval))
+		}
+		if e.config.Arrow.AckTimeout > 0 {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithAckTimeout(e.config.Arrow.AckTimeout))
+		}
+		if e.compressionRatio != nil {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithCompressionRatio(e.compressionRatio))
+		}
+		if e.config.Arrow.EstablishTimeout > 0 {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithEstablishTimeout(e.config.Arrow.EstablishTimeout))
+		}
+		if e.config.Arrow.RedactErrors {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithRedaction())
+		}
+		if as := e.config.Arrow.AutoScale; as.Enabled {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithAutoScale(arrow.AutoScale{
+				MinStreams:       as.MinStreams,
+				MaxStreams:       as.MaxStreams,
+				Interval:         as.Interval,
+				TargetQueueDepth: as.TargetQueueDepth,
+				TargetLatency:    as.TargetLatency,
+			}))
+		}
+		if e.config.Arrow.DowngradePolicy() == arrowconfig.DowngradeRetryInterval {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithDowngradeRetry(e.config.Arrow.Downgrade.RetryInterval))
+		}
+		arrowExpOpts = append(arrowExpOpts, arrow.WithSendReporter(e.sendMetrics))
+
+		if e.config.Arrow.MemoryLimitMiB != 0 {
+			ml := arrow.NewMemoryLimiter(int64(e.config.Arrow.MemoryLimitMiB << 20))
+			arrowOpts = append(arrowOpts, config.WithAllocator(ml.Allocator()))
+			arrowExpOpts = append(arrowExpOpts, arrow.WithMemoryLimiter(ml))
+		}
+		if e.config.Arrow.MemoryPressureMiB != 0 {
+			e.startMemoryPressure()
+			arrowOpts = append(arrowOpts, config.WithMemoryPressureNotifier(e.memoryPressure))
+		}
+
+		if len(e.config.MetadataKeys) != 0 {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithMetadataKeys(e.config.MetadataKeys))
+		}
+		arrowExpOpts = append(arrowExpOpts, arrow.WithMetadataFilter(e.config.Arrow.MetadataAllowedKeys, e.config.Arrow.MetadataDeniedKeys))
+		arrowExpOpts = append(arrowExpOpts, arrow.WithStreamParams(streamParams))
+		if arrowCfg.PayloadCompression == configcompression.TypeZstd && arrowCfg.PayloadZstd.WholePayload && e.config.Arrow.ZstdDictionary == "" {
+			arrowExpOpts = append(arrowExpOpts, arrow.WithPayloadZstd(func() arrowRecord.ProducerAPI {
+				return arrowRecord.NewProducerWithOptions(append(arrowOpts[:len(arrowOpts):len(arrowOpts)], config.WithPayloadZstd())...)
+			}))
+		}
+		arrowExpOpts = append(arrowExpOpts, arrow.WithCapabilities(capability.Set{
+			Versions:         []int{capability.Version},
+			Compression:      []string{streamParams["payload_compression"]},
+			DictionaryDeltas: dictionaryDeltasGate.IsEnabled(),
+		}))
+
+		e.status = arrowzpages.Register(component.KindExporter, e.settings.ID.String())
+		e.status.SetConfig(e.effectiveConfig())
+		e.arrow = arrow.NewExporter(e.config.Arrow.MaxStreamLifetime, e.config.Arrow.NumStreams, e.config.Arrow.Prioritizer, e.config.Arrow.DowngradePolicy() == arrowconfig.DowngradeNever, e.settings.TelemetrySettings, arrowCallOpts, func() arrowRecord.ProducerAPI {
 			return arrowRecord.NewProducerWithOptions(arrowOpts...)
-		}, e.streamClientFactory(e.clientConn), perRPCCreds, e.netReporter)
+		}, e.streamClientFactory(e.clientConn), perRPCCreds, e.netReporter, e.status, arrowExpOpts...)
 
+		if err := e.registerStreams(e.arrow); err != nil {
+			return err
+		}
+		if !e.config.QueueSettings.Enabled {
+			// The streams are the exporter's queue.
+			if err := e.sendMetrics.registerQueue(e.settings, e.arrow, e.config.Arrow.NumStreams); err != nil {
+				return err
+			}
+		}
 		if err := e.arrow.Start(ctx); err != nil {
 			return err
 		}
+		e.status.SetStreamsFunc(e.setStreams)
+
+		if e.config.Arrow.SelfTest {
+			e.startSelfTest(ctx, arrowCallOpts, perRPCCreds)
+		}
 	}
 
 	return nil
 }
 
+// instanceID identifies this collector to the receiver, by default
+// by its service.instance.id or, when unknown, its host name.
+func (e *baseExporter) instanceID() string {
+	if id := e.config.Arrow.InstanceIdentity.ID; id != "" {
+		return id
+	}
+	if inst, ok := e.settings.TelemetrySettings.Resource.Attributes().Get("service.instance.id"); ok {
+		return inst.AsString()
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return host
+}
+
+// effectiveConfig is the configuration reported to the arrowzpages
+// extension.
+type effectiveConfig struct {
+	Compression      configcompression.Type `mapstructure:"compression"`
+	Arrow            ArrowConfig            `mapstructure:"arrow"`
+	DictionaryDeltas bool                   `mapstructure:"dictionary_deltas"`
+}
+
+// effectiveConfig returns the Arrow settings in effect, with their
+// defaults resolved.
+func (e *baseExporter) effectiveConfig() effectiveConfig {
+	arrowCfg := e.config.Arrow
+	if arrowCfg.PayloadCompression == "" {
+		arrowCfg.PayloadCompression = "none"
+	}
+	if arrowCfg.DisablePayloadCompressionWithGRPC && e.config.ClientConfig.Compression.IsCompressed() {
+		// gRPC compresses the payloads already.
+		arrowCfg.PayloadCompression = "none"
+	}
+	return effectiveConfig{
+		Compression:      e.config.ClientConfig.Compression,
+		Arrow:            arrowCfg,
+		DictionaryDeltas: dictionaryDeltasGate.IsEnabled(),
+	}
+}
+
+// setStreams validates and applies a new number of streams and
+// maximum stream lifetime, see arrow.Exporter.SetStreams.
+func (e *baseExporter) setStreams(numStreams int, maxStreamLifetime time.Duration) error {
+	sc := arrowconfig.StreamConfig{
+		NumStreams:        numStreams,
+		MaxStreamLifetime: maxStreamLifetime,
+	}
+	if err := sc.Validate(); err != nil {
+		return err
+	}
+	return e.arrow.SetStreams(numStreams, maxStreamLifetime)
+}
+
+// registerStreams reports the number of Arrow streams of the
+// exporter's signal in use, until shutdown.
+func (e *baseExporter) registerStreams(exp *arrow.Exporter) error {
+	meter := e.settings.TelemetrySettings.MeterProvider.Meter(scopeName)
+	attrs := metric.WithAttributeSet(attribute.NewSet(
+		attribute.String("exporter", e.settings.ID.String()),
+		attribute.String("signal", e.signal.String()),
+	))
+	gauge, err := meter.Int64ObservableGauge(
+		"otel_arrow_exporter_streams",
+		metric.WithDescription("Number of Arrow streams in use"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+	// The exporters of each signal share the instrument, each
+	// with its own callback.
+	e.streamsGauge, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(gauge, int64(exp.Streams()), attrs)
+		return nil
+	}, gauge)
+	return err
+}
+
 func (e *baseExporter) shutdown(ctx context.Context) error {
 	var err error
+	e.stopSelfTest()
 	if e.arrow != nil {
 		err = multierr.Append(err, e.arrow.Shutdown(ctx))
 	}
+	e.stopMemoryPressure()
+	if e.streamsGauge != nil {
+		err = multierr.Append(err, e.streamsGauge.Unregister())
+	}
+	e.status.Unregister()
 	if e.clientConn != nil {
 		err = multierr.Append(err, e.clientConn.Close())
 	}
@@ -173,7 +510,7 @@ func (e *baseExporter) shutdown(ctx context.Context) error {
 
 // arrowSendAndWait gets an available stream and tries to send using
 // Arrow if it is configured.  A (false, nil) result indicates for the
-// caller to fall back to ordinary OTLP.
+// caller to fall back to ordinary OTLP, including after a downgrade.
 //
 // Note that ctx is has not had enhanceContext() called, meaning it
 // will have outgoing gRPC metadata only when an upstream processor or
@@ -183,12 +520,35 @@ func (e *baseExporter) arrowSendAndWait(ctx context.Context, data any) (sent boo
 		return false, nil
 	}
 	sent, err := e.arrow.SendAndWait(ctx, data)
+	if errors.Is(err, arrowerrors.ErrDowngraded) {
+		return false, nil
+	}
+	if sent && err == nil && itemCount(data) == 0 {
+		// Batches without items, e.g., with only the resources
+		// and scopes left behind by a filtering processor, are
+		// sent without Arrow payloads.
+		e.emptyBatches.Add(ctx, 1)
+	}
 	if err != nil {
 		return sent, processError(err)
 	}
 	return sent, nil
 }
 
+// itemCount returns the number of spans, data points, or log records
+// of a batch.
+func itemCount(data any) int {
+	switch data := data.(type) {
+	case ptrace.Traces:
+		return data.SpanCount()
+	case pmetric.Metrics:
+		return data.DataPointCount()
+	case plog.Logs:
+		return data.LogRecordCount()
+	}
+	return 0
+}
+
 func (e *baseExporter) pushTraces(ctx context.Context, td ptrace.Traces) error {
 	if sent, err := e.arrowSendAndWait(ctx, td); err != nil {
 		return err
@@ -255,9 +615,22 @@ func (e *baseExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
 	return nil
 }
 
+// enhanceContext returns ctx with the outgoing metadata of an OTLP
+// request: the static headers and the values of the metadata keys in
+// the client information of ctx.
 func (e *baseExporter) enhanceContext(ctx context.Context) context.Context {
-	if e.metadata.Len() > 0 {
-		ctx = metadata.NewOutgoingContext(ctx, e.metadata)
+	md := e.metadata
+	if len(e.config.MetadataKeys) != 0 {
+		md = md.Copy()
+		info := client.FromContext(ctx)
+		for _, k := range e.config.MetadataKeys {
+			if vs := info.Metadata.Get(k); len(vs) != 0 {
+				md.Append(strings.ToLower(k), vs...)
+			}
+		}
+	}
+	if md.Len() > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, md)
 	}
 	return ctx
 }
@@ -5,10 +5,13 @@ package otelarrowexporter
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"runtime"
 	"sync"
@@ -18,6 +21,10 @@ import (
 
 	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
 	arrowpbMock "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1/mock"
+	"github.com/open-telemetry/otel-arrow/collector/arrowconfig"
+	"github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
 	"github.com/open-telemetry/otel-arrow/collector/testdata"
 	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
 	"github.com/stretchr/testify/assert"
@@ -26,8 +33,10 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configcompression"
 	"go.opentelemetry.io/collector/config/configgrpc"
 	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configtelemetry"
 	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/exporter/exportertest"
@@ -39,6 +48,9 @@ import (
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap/zaptest"
 	"golang.org/x/net/http2/hpack"
@@ -47,6 +59,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
 
@@ -925,8 +938,10 @@ func testSendArrowTraces(t *testing.T, clientWaitForReady, streamServiceAvailabl
 	}
 	// Arrow client is enabled, but the server doesn't support it.
 	cfg.Arrow = ArrowConfig{
-		NumStreams:        1,
-		MaxStreamLifetime: 100 * time.Second,
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: 100 * time.Second,
+		},
 	}
 
 	set := exportertest.NewNopCreateSettings()
@@ -1094,8 +1109,10 @@ func TestSendArrowFailedTraces(t *testing.T) {
 	}
 	// Arrow client is enabled, but the server doesn't support it.
 	cfg.Arrow = ArrowConfig{
-		NumStreams:        1,
-		MaxStreamLifetime: 100 * time.Second,
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: 100 * time.Second,
+		},
 	}
 	cfg.QueueSettings.Enabled = false
 
@@ -1139,6 +1156,118 @@ func TestSendArrowFailedTraces(t *testing.T) {
 	assert.EqualValues(t, td, rcv.getLastRequest())
 }
 
+func TestSendArrowInstanceIdentity(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err, "Failed to find an available address to run the gRPC server: %v", err)
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.ClientConfig = configgrpc.ClientConfig{
+		Endpoint: ln.Addr().String(),
+		TLSSetting: configtls.ClientConfig{
+			Insecure: true,
+		},
+		WaitForReady: true,
+	}
+	cfg.Arrow = ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: 100 * time.Second,
+		},
+		InstanceIdentity: InstanceIdentityConfig{
+			Enabled: true,
+			Labels:  map[string]string{"region": "us-east-1", "cluster": "a"},
+		},
+	}
+	cfg.QueueSettings.Enabled = false
+
+	set := exportertest.NewNopCreateSettings()
+	set.TelemetrySettings.Logger = zaptest.NewLogger(t)
+	set.TelemetrySettings.Resource.Attributes().PutStr("service.instance.id", "agent-42")
+	exp, err := factory.CreateTracesExporter(context.Background(), set, cfg)
+	require.NoError(t, err)
+
+	host := componenttest.NewNopHost()
+	require.NoError(t, exp.Start(context.Background(), host))
+
+	rcv, _ := otelArrowTracesReceiverOnGRPCServer(ln, false)
+	rcv.startStreamMockArrowTraces(t, okStatusFor)
+	rcv.start()
+	defer func() {
+		assert.NoError(t, exp.Shutdown(context.Background()))
+		rcv.srv.GracefulStop()
+	}()
+
+	require.NoError(t, exp.ConsumeTraces(context.Background(), testdata.GenerateTraces(2)))
+
+	// The identity is sent with the stream.
+	md := rcv.getMetadata()
+	require.Equal(t, []string{"agent-42"}, md.Get(streamevents.InstanceIDHeader))
+	require.Equal(t, []string{"cluster=a", "region=us-east-1"}, md.Get(streamevents.InstanceLabelsHeader))
+}
+
+// TestStreamsPerSignal verifies that each signal starts its own
+// number of streams, reported by otel_arrow_exporter_streams.
+func TestStreamsPerSignal(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.ClientConfig = configgrpc.ClientConfig{
+		Endpoint: ln.Addr().String(),
+		TLSSetting: configtls.ClientConfig{
+			Insecure: true,
+		},
+		WaitForReady: true,
+	}
+	cfg.Arrow.NumStreams = 1
+	cfg.Arrow.StreamsPerSignal.Metrics = 3
+	cfg.QueueSettings.Enabled = false
+
+	reader := sdkmetric.NewManualReader()
+	set := exportertest.NewNopCreateSettings()
+	set.TelemetrySettings.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	ctx := context.Background()
+	host := componenttest.NewNopHost()
+	traces, err := factory.CreateTracesExporter(ctx, set, cfg)
+	require.NoError(t, err)
+	require.NoError(t, traces.Start(ctx, host))
+	metrics, err := factory.CreateMetricsExporter(ctx, set, cfg)
+	require.NoError(t, err)
+	require.NoError(t, metrics.Start(ctx, host))
+
+	streams := map[string]int64{}
+	for _, dp := range collectSendMetrics(t, reader)["otel_arrow_exporter_streams"] {
+		signal, _ := dp.Attributes.Value(attribute.Key("signal"))
+		streams[signal.AsString()] = dp.Value
+	}
+	require.Equal(t, map[string]int64{"traces": 1, "metrics": 3}, streams)
+
+	// Shutdown stops the reports.
+	require.NoError(t, traces.Shutdown(ctx))
+	require.NoError(t, metrics.Shutdown(ctx))
+	require.Empty(t, collectSendMetrics(t, reader)["otel_arrow_exporter_streams"])
+}
+
+func TestInstanceID(t *testing.T) {
+	set := exportertest.NewNopCreateSettings()
+	e := &baseExporter{config: createDefaultConfig().(*Config), settings: set}
+
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	require.Equal(t, host, e.instanceID())
+
+	set.TelemetrySettings.Resource.Attributes().PutStr("service.instance.id", "agent-42")
+	e.settings = set
+	require.Equal(t, "agent-42", e.instanceID())
+
+	e.config.Arrow.InstanceIdentity.ID = "edge-7"
+	require.Equal(t, "edge-7", e.instanceID())
+}
+
 func TestUserDialOptions(t *testing.T) {
 	// Start an OTel-Arrow receiver.
 	ln, err := net.Listen("tcp", "127.0.0.1:")
@@ -1189,3 +1318,320 @@ func TestUserDialOptions(t *testing.T) {
 	require.Equal(t, len(rcv.getMetadata().Get("User-Agent")), 1)
 	require.Contains(t, rcv.getMetadata().Get("User-Agent")[0], testAgent)
 }
+
+// TestConnectTimeoutStalledHandshake verifies that connect_timeout
+// bounds each attempt to connect to an endpoint whose TLS handshake
+// never completes.
+func TestConnectTimeoutStalledHandshake(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	// Accept connections without ever answering, and measure how
+	// long each attempt lasts before the exporter gives up.
+	attempts := make(chan time.Duration, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				start := time.Now()
+				_, _ = io.Copy(io.Discard, conn)
+				attempts <- time.Since(start)
+			}()
+		}
+	}()
+
+	const timeout = 200 * time.Millisecond
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.ClientConfig = configgrpc.ClientConfig{
+		Endpoint: ln.Addr().String(),
+	}
+	cfg.ConnectTimeout = timeout
+	cfg.Arrow.Disabled = true
+	cfg.QueueSettings.Enabled = false
+	cfg.RetryConfig.Enabled = false
+
+	set := exportertest.NewNopCreateSettings()
+	set.TelemetrySettings.Logger = zaptest.NewLogger(t)
+	exp, err := factory.CreateTracesExporter(context.Background(), set, cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, exp.Shutdown(context.Background()))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		_ = exp.ConsumeTraces(ctx, testdata.GenerateTraces(1))
+	}()
+
+	// The first attempt would otherwise last the initial backoff
+	// delay of one second, and later ones longer.
+	for i := 0; i < 3; i++ {
+		select {
+		case d := <-attempts:
+			require.Less(t, d, timeout+300*time.Millisecond)
+		case <-ctx.Done():
+			t.Fatal("connection attempts did not end")
+		}
+	}
+}
+
+// methodStatsHandler is a stats.Handler that records the methods of
+// the RPCs it handles.
+type methodStatsHandler struct {
+	lock    sync.Mutex
+	methods []string
+}
+
+func (h *methodStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.methods = append(h.methods, info.FullMethodName)
+	return ctx
+}
+
+func (h *methodStatsHandler) HandleRPC(context.Context, stats.RPCStats) {}
+
+func (h *methodStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *methodStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+func (h *methodStatsHandler) getMethods() []string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]string(nil), h.methods...)
+}
+
+func TestStatsHandlers(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err, "Failed to find an available address to run the gRPC server: %v", err)
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.ClientConfig = configgrpc.ClientConfig{
+		Endpoint: ln.Addr().String(),
+		TLSSetting: configtls.ClientConfig{
+			Insecure: true,
+		},
+		WaitForReady: true,
+	}
+	cfg.Arrow.Disabled = true
+	cfg.QueueSettings.Enabled = false
+
+	first, second := &methodStatsHandler{}, &methodStatsHandler{}
+	cfg.StatsHandlers = []stats.Handler{first, second}
+
+	set := exportertest.NewNopCreateSettings()
+	set.TelemetrySettings.Logger = zaptest.NewLogger(t)
+	exp, err := factory.CreateTracesExporter(context.Background(), set, cfg)
+	require.NoError(t, err)
+
+	defer func() {
+		assert.NoError(t, exp.Shutdown(context.Background()))
+	}()
+
+	assert.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+
+	rcv, _ := otelArrowTracesReceiverOnGRPCServer(ln, false)
+	rcv.start()
+	defer rcv.srv.GracefulStop()
+
+	assert.NoError(t, exp.ConsumeTraces(context.Background(), testdata.GenerateTraces(2)))
+
+	const method = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+	assert.Equal(t, []string{method}, first.getMethods())
+	assert.Equal(t, []string{method}, second.getMethods())
+}
+
+func TestSendArrowEmptyTraces(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err, "Failed to find an available address to run the gRPC server: %v", err)
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.ClientConfig = configgrpc.ClientConfig{
+		Endpoint: ln.Addr().String(),
+		TLSSetting: configtls.ClientConfig{
+			Insecure: true,
+		},
+		WaitForReady: true,
+	}
+	cfg.Arrow = ArrowConfig{
+		StreamConfig: arrowconfig.StreamConfig{
+			NumStreams:        1,
+			MaxStreamLifetime: 100 * time.Second,
+		},
+	}
+	cfg.QueueSettings.Enabled = false
+
+	set := exportertest.NewNopCreateSettings()
+	set.TelemetrySettings.Logger = zaptest.NewLogger(t)
+	exp, err := factory.CreateTracesExporter(context.Background(), set, cfg)
+	require.NoError(t, err)
+	require.NotNil(t, exp)
+
+	host := componenttest.NewNopHost()
+	assert.NoError(t, exp.Start(context.Background(), host))
+
+	rcv, _ := otelArrowTracesReceiverOnGRPCServer(ln, false)
+	rcv.startStreamMockArrowTraces(t, okStatusFor)
+	rcv.start()
+
+	defer func() {
+		assert.NoError(t, exp.Shutdown(context.Background()))
+		rcv.srv.GracefulStop()
+	}()
+
+	// Resources and scopes without spans are sent without
+	// payloads, the receiver has nothing to export.
+	td := ptrace.NewTraces()
+	td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty()
+	assert.NoError(t, exp.ConsumeTraces(context.Background(), td))
+
+	// The next batch with spans is the first request received.
+	td = testdata.GenerateTraces(2)
+	assert.NoError(t, exp.ConsumeTraces(context.Background(), td))
+
+	assert.Eventually(t, func() bool {
+		return rcv.requestCount.Load() > 0
+	}, 10*time.Second, 5*time.Millisecond)
+
+	assert.EqualValues(t, int32(2), rcv.totalItems.Load())
+	assert.EqualValues(t, int32(1), rcv.requestCount.Load())
+	assert.EqualValues(t, td, rcv.getLastRequest())
+}
+
+func TestEffectiveConfig(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.ClientConfig = configgrpc.ClientConfig{
+		Endpoint: ln.Addr().String(),
+		TLSSetting: configtls.ClientConfig{
+			Insecure: true,
+		},
+		Compression: "zstd",
+	}
+	cfg.Arrow.NumStreams = 2
+	cfg.Arrow.PayloadCompression = ""
+	cfg.QueueSettings.Enabled = false
+
+	set := exportertest.NewNopCreateSettings()
+	set.TelemetrySettings.Logger = zaptest.NewLogger(t)
+	exp, err := factory.CreateTracesExporter(context.Background(), set, cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, exp.Shutdown(context.Background()))
+	}()
+
+	rec := httptest.NewRecorder()
+	arrowzpages.ConfigHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, arrowzpages.ConfigPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var reports []arrowzpages.ConfigReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reports))
+	var report *arrowzpages.ConfigReport
+	for i := range reports {
+		if reports[i].Kind == "Exporter" && reports[i].Name == set.ID.String() {
+			report = &reports[i]
+		}
+	}
+	require.NotNil(t, report)
+
+	// Unset settings are reported with their effective values.
+	require.Equal(t, "zstd", report.Config["compression"])
+	require.Equal(t, dictionaryDeltasGate.IsEnabled(), report.Config["dictionary_deltas"])
+	arrowCfg := report.Config["arrow"].(map[string]any)
+	require.Equal(t, "none", arrowCfg["payload_compression"])
+	require.EqualValues(t, 2, arrowCfg["num_streams"])
+	require.Equal(t, cfg.Arrow.MaxStreamLifetime.String(), arrowCfg["max_stream_lifetime"])
+}
+
+func TestEffectiveConfigPayloadCompressionWithGRPC(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.PayloadCompression = "zstd"
+	cfg.Arrow.DisablePayloadCompressionWithGRPC = true
+	e := &baseExporter{config: cfg}
+
+	// gRPC compresses the payloads.
+	cfg.ClientConfig.Compression = "zstd"
+	require.Equal(t, configcompression.Type("none"), e.effectiveConfig().Arrow.PayloadCompression)
+
+	cfg.ClientConfig.Compression = "none"
+	require.Equal(t, configcompression.TypeZstd, e.effectiveConfig().Arrow.PayloadCompression)
+
+	// Both compress unless disabled.
+	cfg.ClientConfig.Compression = "zstd"
+	cfg.Arrow.DisablePayloadCompressionWithGRPC = false
+	require.Equal(t, configcompression.TypeZstd, e.effectiveConfig().Arrow.PayloadCompression)
+}
+
+func TestEnhanceContextMetadataKeys(t *testing.T) {
+	e := &baseExporter{
+		config:   &Config{MetadataKeys: []string{"X-Tenant"}},
+		metadata: metadata.New(map[string]string{"static": "1"}),
+	}
+	ctx := client.NewContext(context.Background(), client.Info{
+		Metadata: client.NewMetadata(map[string][]string{
+			"x-tenant": {"a", "b"},
+			"other":    {"c"},
+		}),
+	})
+	md, ok := metadata.FromOutgoingContext(e.enhanceContext(ctx))
+	require.True(t, ok)
+	require.Equal(t, metadata.MD{
+		"static":   {"1"},
+		"x-tenant": {"a", "b"},
+	}, md)
+
+	// The static headers are not modified.
+	require.Equal(t, metadata.MD{"static": {"1"}}, e.metadata)
+}
+
+func TestExporterSizeHistograms(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		reader := sdkmetric.NewManualReader()
+		set := exportertest.NewNopCreateSettings()
+		set.TelemetrySettings.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+		set.TelemetrySettings.MetricsLevel = configtelemetry.LevelNormal
+
+		cfg := createDefaultConfig().(*Config)
+		cfg.Endpoint = "localhost:4317"
+		cfg.SizeHistograms = enabled
+		e, err := newExporter(cfg, set, component.DataTypeTraces, nil)
+		require.NoError(t, err)
+		e.netReporter.CountSend(context.Background(), netstats.SizesStruct{
+			Method: "Hello",
+			Length: 100,
+		})
+
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+		var histograms []string
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if _, ok := m.Data.(metricdata.Histogram[int64]); ok {
+					histograms = append(histograms, m.Name)
+				}
+			}
+		}
+		if enabled {
+			require.Equal(t, []string{"exporter_sent_size"}, histograms)
+		} else {
+			require.Empty(t, histograms)
+		}
+	}
+}
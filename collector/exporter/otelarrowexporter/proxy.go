@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowexporter // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+	"google.golang.org/grpc"
+)
+
+// parseProxyURL returns the proxy URL, which must use the http,
+// socks5 or socks5h scheme.  The errors of this file do not include
+// the password of the proxy URL, see redactedError.
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		// The error of url.Parse quotes the whole URL.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, use http, socks5 or socks5h", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy URL has no host: %q", u.Redacted())
+	}
+	return u, nil
+}
+
+// proxyDialOption returns a dial option that connects to the
+// endpoint through the proxy, which replaces the proxy that gRPC
+// takes from the environment.
+func proxyDialOption(proxyURL string) (grpc.DialOption, error) {
+	u, err := parseProxyURL(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "http" {
+		return grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			conn, err := dialHTTPConnect(ctx, u, addr)
+			if err != nil {
+				return nil, redactedError(u, err)
+			}
+			return conn, nil
+		}), nil
+	}
+	d, err := proxy.FromURL(u, proxy.Direct)
+	if err != nil {
+		return nil, redactedError(u, err)
+	}
+	cd := d.(proxy.ContextDialer)
+	return grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		conn, err := cd.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, redactedError(u, err)
+		}
+		return conn, nil
+	}), nil
+}
+
+// redactedError qualifies err with the proxy URL, whose password is
+// redacted, and removes the password from the message of err, in
+// case it was quoted, in which case err is no longer wrapped.
+func redactedError(u *url.URL, err error) error {
+	if pass, ok := u.User.Password(); ok && pass != "" && strings.Contains(err.Error(), pass) {
+		return fmt.Errorf("proxy %s: %s", u.Redacted(), strings.ReplaceAll(err.Error(), pass, "xxxxx"))
+	}
+	return fmt.Errorf("proxy %s: %w", u.Redacted(), err)
+}
+
+// dialHTTPConnect opens a tunnel to addr through the HTTP proxy with
+// the CONNECT method, authenticating with the user of the proxy URL,
+// if any.
+func dialHTTPConnect(ctx context.Context, proxyURL *url.URL, addr string) (_ net.Conn, retErr error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, fmt.Errorf("dial proxy: %w", err)
+	}
+	defer func() {
+		if retErr != nil {
+			_ = conn.Close()
+		}
+	}()
+	// The handshake is bounded by the dial context.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if user := proxyURL.User; user != nil {
+		pass, _ := user.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+pass)))
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("proxy CONNECT: %w", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, fmt.Errorf("proxy CONNECT: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy CONNECT: %s", resp.Status)
+	}
+	if br.Buffered() != 0 {
+		// The endpoint may have spoken first, e.g., an HTTP/2
+		// server sends its settings at once.
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn reads the data that was buffered with the response of
+// the proxy before the rest of the connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
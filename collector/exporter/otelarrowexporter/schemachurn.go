@@ -0,0 +1,43 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowexporter // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+
+import (
+	"context"
+
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// schemaChurnReporter logs and counts the schema churn detected by
+// the Arrow producers of the streams.
+type schemaChurnReporter struct {
+	logger  *zap.Logger
+	counter metric.Int64Counter
+	// level of telemetry, the payload type is counted above
+	// the basic level.
+	level configtelemetry.Level
+}
+
+var _ config.SchemaChurnReporter = (*schemaChurnReporter)(nil)
+
+// ReportSchemaChurn implements config.SchemaChurnReporter.
+func (r *schemaChurnReporter) ReportSchemaChurn(report config.SchemaChurn) {
+	if r.level > configtelemetry.LevelBasic {
+		r.counter.Add(context.Background(), 1, metric.WithAttributes(
+			attribute.String("payload_type", report.PayloadType),
+		))
+	} else {
+		r.counter.Add(context.Background(), 1)
+	}
+	r.logger.Warn("arrow schema churn, compression suffers from frequent schema changes",
+		zap.String("payload_type", report.PayloadType),
+		zap.Int("changes", report.Changes),
+		zap.Int("batches", report.Batches),
+		zap.Strings("unstable_keys", report.UnstableKeys),
+	)
+}
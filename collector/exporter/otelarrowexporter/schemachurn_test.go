@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowexporter
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSchemaChurnReporter(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	counter, err := noop.NewMeterProvider().Meter(scopeName).Int64Counter("test")
+	require.NoError(t, err)
+
+	reporter := &schemaChurnReporter{
+		logger:  zap.New(core),
+		counter: counter,
+	}
+	reporter.ReportSchemaChurn(config.SchemaChurn{
+		PayloadType:  "SPAN_ATTRS",
+		Changes:      10,
+		Batches:      42,
+		UnstableKeys: []string{"http.status_code"},
+	})
+
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	require.Equal(t, "SPAN_ATTRS", fields["payload_type"])
+	require.EqualValues(t, 10, fields["changes"])
+	require.EqualValues(t, 42, fields["batches"])
+	require.Equal(t, []any{"http.status_code"}, fields["unstable_keys"])
+}
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package selftelemetry // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/selftelemetry"
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func timestamp(t time.Time) pcommon.Timestamp {
+	if t.IsZero() {
+		return 0
+	}
+	return pcommon.NewTimestampFromTime(t)
+}
+
+func copyAttributes(dest pcommon.Map, kvs []attribute.KeyValue) {
+	dest.EnsureCapacity(len(kvs))
+	for _, kv := range kvs {
+		copyValue(dest.PutEmpty(string(kv.Key)), kv.Value)
+	}
+}
+
+func copyValue(dest pcommon.Value, v attribute.Value) {
+	switch v.Type() {
+	case attribute.BOOL:
+		dest.SetBool(v.AsBool())
+	case attribute.INT64:
+		dest.SetInt(v.AsInt64())
+	case attribute.FLOAT64:
+		dest.SetDouble(v.AsFloat64())
+	case attribute.STRING:
+		dest.SetStr(v.AsString())
+	case attribute.BOOLSLICE:
+		s := dest.SetEmptySlice()
+		for _, b := range v.AsBoolSlice() {
+			s.AppendEmpty().SetBool(b)
+		}
+	case attribute.INT64SLICE:
+		s := dest.SetEmptySlice()
+		for _, i := range v.AsInt64Slice() {
+			s.AppendEmpty().SetInt(i)
+		}
+	case attribute.FLOAT64SLICE:
+		s := dest.SetEmptySlice()
+		for _, f := range v.AsFloat64Slice() {
+			s.AppendEmpty().SetDouble(f)
+		}
+	case attribute.STRINGSLICE:
+		s := dest.SetEmptySlice()
+		for _, str := range v.AsStringSlice() {
+			s.AppendEmpty().SetStr(str)
+		}
+	default:
+		dest.SetStr(v.Emit())
+	}
+}
+
+func copyResource(dest pcommon.Resource, res *resource.Resource) {
+	if res == nil {
+		return
+	}
+	copyAttributes(dest.Attributes(), res.Attributes())
+}
+
+func copyScope(dest pcommon.InstrumentationScope, scope instrumentation.Scope) {
+	dest.SetName(scope.Name)
+	dest.SetVersion(scope.Version)
+}
@@ -0,0 +1,220 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package selftelemetry // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/selftelemetry"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// metricExporter is an OTel SDK metric exporter that delivers
+// metrics to a collector consumer.
+type metricExporter struct {
+	next consumer.Metrics
+}
+
+var _ sdkmetric.Exporter = (*metricExporter)(nil)
+
+// NewMetricExporter returns an OTel SDK metric exporter that converts
+// metrics to pdata and passes them to next.  It uses the SDK default
+// temporality and aggregation.
+func NewMetricExporter(next consumer.Metrics) sdkmetric.Exporter {
+	return &metricExporter{next: next}
+}
+
+// Temporality implements sdkmetric.Exporter.
+func (e *metricExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(kind)
+}
+
+// Aggregation implements sdkmetric.Exporter.
+func (e *metricExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+// Export implements sdkmetric.Exporter.
+func (e *metricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	md := resourceMetricsToMetrics(rm)
+	if md.DataPointCount() == 0 {
+		return nil
+	}
+	return e.next.ConsumeMetrics(ctx, md)
+}
+
+// ForceFlush implements sdkmetric.Exporter.
+func (e *metricExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+// Shutdown implements sdkmetric.Exporter.
+func (e *metricExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func resourceMetricsToMetrics(rm *metricdata.ResourceMetrics) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	drm := md.ResourceMetrics().AppendEmpty()
+	copyResource(drm.Resource(), rm.Resource)
+
+	for _, sm := range rm.ScopeMetrics {
+		dsm := drm.ScopeMetrics().AppendEmpty()
+		copyScope(dsm.Scope(), sm.Scope)
+		for _, m := range sm.Metrics {
+			dm := dsm.Metrics().AppendEmpty()
+			dm.SetName(m.Name)
+			dm.SetDescription(m.Description)
+			dm.SetUnit(m.Unit)
+			copyAggregation(dm, m.Data)
+		}
+	}
+	return md
+}
+
+func copyAggregation(dest pmetric.Metric, data metricdata.Aggregation) {
+	switch a := data.(type) {
+	case metricdata.Gauge[int64]:
+		copyNumberPoints(dest.SetEmptyGauge().DataPoints(), a.DataPoints)
+	case metricdata.Gauge[float64]:
+		copyNumberPoints(dest.SetEmptyGauge().DataPoints(), a.DataPoints)
+	case metricdata.Sum[int64]:
+		copySum(dest.SetEmptySum(), a)
+	case metricdata.Sum[float64]:
+		copySum(dest.SetEmptySum(), a)
+	case metricdata.Histogram[int64]:
+		copyHistogram(dest.SetEmptyHistogram(), a)
+	case metricdata.Histogram[float64]:
+		copyHistogram(dest.SetEmptyHistogram(), a)
+	case metricdata.ExponentialHistogram[int64]:
+		copyExponentialHistogram(dest.SetEmptyExponentialHistogram(), a)
+	case metricdata.ExponentialHistogram[float64]:
+		copyExponentialHistogram(dest.SetEmptyExponentialHistogram(), a)
+	case metricdata.Summary:
+		copySummary(dest.SetEmptySummary(), a)
+	}
+}
+
+func temporality(t metricdata.Temporality) pmetric.AggregationTemporality {
+	switch t {
+	case metricdata.CumulativeTemporality:
+		return pmetric.AggregationTemporalityCumulative
+	case metricdata.DeltaTemporality:
+		return pmetric.AggregationTemporalityDelta
+	default:
+		return pmetric.AggregationTemporalityUnspecified
+	}
+}
+
+func setNumber[N int64 | float64](dest pmetric.NumberDataPoint, v N) {
+	switch n := any(v).(type) {
+	case int64:
+		dest.SetIntValue(n)
+	case float64:
+		dest.SetDoubleValue(n)
+	}
+}
+
+func copyExemplars[N int64 | float64](dest pmetric.ExemplarSlice, exemplars []metricdata.Exemplar[N]) {
+	for _, ex := range exemplars {
+		de := dest.AppendEmpty()
+		copyAttributes(de.FilteredAttributes(), ex.FilteredAttributes)
+		de.SetTimestamp(timestamp(ex.Time))
+		switch v := any(ex.Value).(type) {
+		case int64:
+			de.SetIntValue(v)
+		case float64:
+			de.SetDoubleValue(v)
+		}
+		var tid pcommon.TraceID
+		var sid pcommon.SpanID
+		copy(tid[:], ex.TraceID)
+		copy(sid[:], ex.SpanID)
+		de.SetTraceID(tid)
+		de.SetSpanID(sid)
+	}
+}
+
+func copyNumberPoints[N int64 | float64](dest pmetric.NumberDataPointSlice, points []metricdata.DataPoint[N]) {
+	for _, pt := range points {
+		dp := dest.AppendEmpty()
+		copyAttributes(dp.Attributes(), pt.Attributes.ToSlice())
+		dp.SetStartTimestamp(timestamp(pt.StartTime))
+		dp.SetTimestamp(timestamp(pt.Time))
+		setNumber(dp, pt.Value)
+		copyExemplars(dp.Exemplars(), pt.Exemplars)
+	}
+}
+
+func copySum[N int64 | float64](dest pmetric.Sum, sum metricdata.Sum[N]) {
+	dest.SetAggregationTemporality(temporality(sum.Temporality))
+	dest.SetIsMonotonic(sum.IsMonotonic)
+	copyNumberPoints(dest.DataPoints(), sum.DataPoints)
+}
+
+func copyHistogram[N int64 | float64](dest pmetric.Histogram, hist metricdata.Histogram[N]) {
+	dest.SetAggregationTemporality(temporality(hist.Temporality))
+	for _, pt := range hist.DataPoints {
+		dp := dest.DataPoints().AppendEmpty()
+		copyAttributes(dp.Attributes(), pt.Attributes.ToSlice())
+		dp.SetStartTimestamp(timestamp(pt.StartTime))
+		dp.SetTimestamp(timestamp(pt.Time))
+		dp.SetCount(pt.Count)
+		dp.SetSum(float64(pt.Sum))
+		if v, ok := pt.Min.Value(); ok {
+			dp.SetMin(float64(v))
+		}
+		if v, ok := pt.Max.Value(); ok {
+			dp.SetMax(float64(v))
+		}
+		dp.ExplicitBounds().FromRaw(pt.Bounds)
+		dp.BucketCounts().FromRaw(pt.BucketCounts)
+		copyExemplars(dp.Exemplars(), pt.Exemplars)
+	}
+}
+
+func copyExponentialHistogram[N int64 | float64](dest pmetric.ExponentialHistogram, hist metricdata.ExponentialHistogram[N]) {
+	dest.SetAggregationTemporality(temporality(hist.Temporality))
+	for _, pt := range hist.DataPoints {
+		dp := dest.DataPoints().AppendEmpty()
+		copyAttributes(dp.Attributes(), pt.Attributes.ToSlice())
+		dp.SetStartTimestamp(timestamp(pt.StartTime))
+		dp.SetTimestamp(timestamp(pt.Time))
+		dp.SetCount(pt.Count)
+		dp.SetSum(float64(pt.Sum))
+		if v, ok := pt.Min.Value(); ok {
+			dp.SetMin(float64(v))
+		}
+		if v, ok := pt.Max.Value(); ok {
+			dp.SetMax(float64(v))
+		}
+		dp.SetScale(pt.Scale)
+		dp.SetZeroCount(pt.ZeroCount)
+		dp.SetZeroThreshold(pt.ZeroThreshold)
+		dp.Positive().SetOffset(pt.PositiveBucket.Offset)
+		dp.Positive().BucketCounts().FromRaw(pt.PositiveBucket.Counts)
+		dp.Negative().SetOffset(pt.NegativeBucket.Offset)
+		dp.Negative().BucketCounts().FromRaw(pt.NegativeBucket.Counts)
+		copyExemplars(dp.Exemplars(), pt.Exemplars)
+	}
+}
+
+func copySummary(dest pmetric.Summary, sum metricdata.Summary) {
+	for _, pt := range sum.DataPoints {
+		dp := dest.DataPoints().AppendEmpty()
+		copyAttributes(dp.Attributes(), pt.Attributes.ToSlice())
+		dp.SetStartTimestamp(timestamp(pt.StartTime))
+		dp.SetTimestamp(timestamp(pt.Time))
+		dp.SetCount(pt.Count)
+		dp.SetSum(pt.Sum)
+		for _, qv := range pt.QuantileValues {
+			dq := dp.QuantileValues().AppendEmpty()
+			dq.SetQuantile(qv.Quantile)
+			dq.SetValue(qv.Value)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package selftelemetry sends a collector's own traces and metrics
+// through an OTel-Arrow exporter.  Pointing the exporter at the
+// collector's own OTel-Arrow receiver forms a loopback, so that the
+// Arrow path carries, and is monitored by, the telemetry it produces.
+package selftelemetry // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/selftelemetry"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/multierr"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+)
+
+// Loopback holds OTel-Arrow exporters for self-telemetry and the SDK
+// providers that feed them.
+type Loopback struct {
+	traces  exporter.Traces
+	metrics exporter.Metrics
+
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+}
+
+// NewLoopback creates a Loopback exporting with cfg, whose endpoint
+// is typically the address of the collector's own OTel-Arrow
+// receiver.  The exporters themselves use no-op telemetry, so that
+// exporting self-telemetry does not produce more of it.  Metrics are
+// collected every interval.
+func NewLoopback(ctx context.Context, cfg *otelarrowexporter.Config, res *resource.Resource, interval time.Duration) (*Loopback, error) {
+	factory := otelarrowexporter.NewFactory()
+	set := exporter.CreateSettings{
+		ID:                component.NewIDWithName(factory.Type(), "selftelemetry"),
+		TelemetrySettings: componenttest.NewNopTelemetrySettings(),
+	}
+
+	traces, err := factory.CreateTracesExporter(ctx, set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := factory.CreateMetricsExporter(ctx, set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		res = resource.Default()
+	}
+	return &Loopback{
+		traces:  traces,
+		metrics: metrics,
+		tracerProvider: sdktrace.NewTracerProvider(
+			sdktrace.WithResource(res),
+			sdktrace.WithBatcher(NewSpanExporter(traces)),
+		),
+		meterProvider: sdkmetric.NewMeterProvider(
+			sdkmetric.WithResource(res),
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(
+				NewMetricExporter(metrics),
+				sdkmetric.WithInterval(interval),
+			)),
+		),
+	}, nil
+}
+
+// Start starts the exporters.
+func (l *Loopback) Start(ctx context.Context, host component.Host) error {
+	if err := l.traces.Start(ctx, host); err != nil {
+		return err
+	}
+	return l.metrics.Start(ctx, host)
+}
+
+// Shutdown flushes the providers, then stops the exporters.
+func (l *Loopback) Shutdown(ctx context.Context) error {
+	return multierr.Combine(
+		l.tracerProvider.Shutdown(ctx),
+		l.meterProvider.Shutdown(ctx),
+		l.traces.Shutdown(ctx),
+		l.metrics.Shutdown(ctx),
+	)
+}
+
+// TracerProvider returns the provider of spans sent through the
+// loopback.
+func (l *Loopback) TracerProvider() *sdktrace.TracerProvider {
+	return l.tracerProvider
+}
+
+// MeterProvider returns the provider of metrics sent through the
+// loopback.
+func (l *Loopback) MeterProvider() *sdkmetric.MeterProvider {
+	return l.meterProvider
+}
+
+// TelemetrySettings returns a copy of set that uses the loopback's
+// tracer and meter providers, for use by components whose telemetry
+// should travel over Arrow.
+func (l *Loopback) TelemetrySettings(set component.TelemetrySettings) component.TelemetrySettings {
+	set.TracerProvider = l.tracerProvider
+	set.MeterProvider = l.meterProvider
+	return set
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package selftelemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+	"github.com/open-telemetry/otel-arrow/collector/testutil"
+)
+
+func TestSpanExporter(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "test"))),
+		sdktrace.WithSyncer(NewSpanExporter(sink)),
+	)
+	ctx, parent := tp.Tracer("scope", trace.WithInstrumentationVersion("v1")).Start(context.Background(), "parent")
+	_, child := tp.Tracer("scope", trace.WithInstrumentationVersion("v1")).Start(ctx, "child",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("count", 3), attribute.StringSlice("list", []string{"a", "b"})),
+	)
+	child.AddEvent("happened", trace.WithAttributes(attribute.Bool("ok", true)))
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+	parent.End()
+	require.NoError(t, tp.Shutdown(context.Background()))
+
+	require.Equal(t, 2, sink.SpanCount())
+	td := sink.AllTraces()[0]
+	rs := td.ResourceSpans().At(0)
+	name, _ := rs.Resource().Attributes().Get("service.name")
+	assert.Equal(t, "test", name.Str())
+	assert.Equal(t, "scope", rs.ScopeSpans().At(0).Scope().Name())
+	assert.Equal(t, "v1", rs.ScopeSpans().At(0).Scope().Version())
+
+	span := rs.ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, "child", span.Name())
+	assert.Equal(t, ptrace.SpanKindClient, span.Kind())
+	assert.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+	assert.Equal(t, "failed", span.Status().Message())
+	assert.False(t, span.ParentSpanID().IsEmpty())
+	assert.Equal(t, map[string]any{"count": int64(3), "list": []any{"a", "b"}}, span.Attributes().AsRaw())
+	assert.Equal(t, 1, span.Events().Len())
+	assert.Equal(t, "happened", span.Events().At(0).Name())
+
+	parentSpan := sink.AllTraces()[1].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, span.TraceID(), parentSpan.TraceID())
+	assert.Equal(t, span.ParentSpanID(), parentSpan.SpanID())
+}
+
+func TestMetricExporter(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	reader := sdkmetric.NewPeriodicReader(NewMetricExporter(sink), sdkmetric.WithInterval(time.Hour))
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	meter := mp.Meter("scope")
+
+	counter, err := meter.Int64Counter("requests")
+	require.NoError(t, err)
+	hist, err := meter.Float64Histogram("latency")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	counter.Add(ctx, 2)
+	counter.Add(ctx, 3)
+	hist.Record(ctx, 1.5)
+
+	require.NoError(t, mp.Shutdown(ctx))
+
+	require.Len(t, sink.AllMetrics(), 1)
+	ms := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, ms.Len())
+
+	byName := map[string]pmetric.Metric{}
+	for i := 0; i < ms.Len(); i++ {
+		byName[ms.At(i).Name()] = ms.At(i)
+	}
+	sum := byName["requests"].Sum()
+	assert.True(t, sum.IsMonotonic())
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, sum.AggregationTemporality())
+	assert.Equal(t, int64(5), sum.DataPoints().At(0).IntValue())
+
+	hdp := byName["latency"].Histogram().DataPoints().At(0)
+	assert.Equal(t, uint64(1), hdp.Count())
+	assert.Equal(t, 1.5, hdp.Sum())
+	assert.Equal(t, 1.5, hdp.Max())
+}
+
+func TestLoopbackLifecycle(t *testing.T) {
+	cfg := otelarrowexporter.NewFactory().CreateDefaultConfig().(*otelarrowexporter.Config)
+	cfg.ClientConfig.Endpoint = testutil.GetAvailableLocalAddress(t)
+	cfg.ClientConfig.TLSSetting.Insecure = true
+
+	ctx := context.Background()
+	lb, err := NewLoopback(ctx, cfg, nil, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, lb.Start(ctx, componenttest.NewNopHost()))
+
+	set := lb.TelemetrySettings(componenttest.NewNopTelemetrySettings())
+	assert.Equal(t, lb.TracerProvider(), set.TracerProvider)
+	assert.Equal(t, lb.MeterProvider(), set.MeterProvider)
+
+	require.NoError(t, lb.Shutdown(ctx))
+}
@@ -0,0 +1,127 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package selftelemetry // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/selftelemetry"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanExporter is an OTel SDK span exporter that delivers spans to
+// a collector consumer.
+type spanExporter struct {
+	next consumer.Traces
+}
+
+var _ sdktrace.SpanExporter = (*spanExporter)(nil)
+
+// NewSpanExporter returns an OTel SDK span exporter that converts
+// spans to pdata and passes them to next.
+func NewSpanExporter(next consumer.Traces) sdktrace.SpanExporter {
+	return &spanExporter{next: next}
+}
+
+// ExportSpans implements sdktrace.SpanExporter.
+func (e *spanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	return e.next.ConsumeTraces(ctx, spansToTraces(spans))
+}
+
+// Shutdown implements sdktrace.SpanExporter.
+func (e *spanExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// spansToTraces converts SDK spans to pdata.  The SDK batches spans
+// from one provider, so all spans share a resource; scopes are
+// grouped in order of first appearance.
+func spansToTraces(spans []sdktrace.ReadOnlySpan) ptrace.Traces {
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	copyResource(rs.Resource(), spans[0].Resource())
+
+	scopes := map[string]ptrace.SpanSlice{}
+	for _, span := range spans {
+		lib := span.InstrumentationScope()
+		key := lib.Name + "/" + lib.Version
+		dest, ok := scopes[key]
+		if !ok {
+			ss := rs.ScopeSpans().AppendEmpty()
+			copyScope(ss.Scope(), lib)
+			dest = ss.Spans()
+			scopes[key] = dest
+		}
+		copySpan(dest.AppendEmpty(), span)
+	}
+	return td
+}
+
+func copySpan(dest ptrace.Span, span sdktrace.ReadOnlySpan) {
+	sc := span.SpanContext()
+	dest.SetTraceID(pcommon.TraceID(sc.TraceID()))
+	dest.SetSpanID(pcommon.SpanID(sc.SpanID()))
+	dest.TraceState().FromRaw(sc.TraceState().String())
+	if parent := span.Parent(); parent.IsValid() {
+		dest.SetParentSpanID(pcommon.SpanID(parent.SpanID()))
+	}
+	dest.SetName(span.Name())
+	dest.SetKind(spanKind(span.SpanKind()))
+	dest.SetStartTimestamp(timestamp(span.StartTime()))
+	dest.SetEndTimestamp(timestamp(span.EndTime()))
+	copyAttributes(dest.Attributes(), span.Attributes())
+	dest.SetDroppedAttributesCount(uint32(span.DroppedAttributes()))
+
+	for _, ev := range span.Events() {
+		de := dest.Events().AppendEmpty()
+		de.SetName(ev.Name)
+		de.SetTimestamp(timestamp(ev.Time))
+		copyAttributes(de.Attributes(), ev.Attributes)
+		de.SetDroppedAttributesCount(uint32(ev.DroppedAttributeCount))
+	}
+	dest.SetDroppedEventsCount(uint32(span.DroppedEvents()))
+
+	for _, link := range span.Links() {
+		dl := dest.Links().AppendEmpty()
+		dl.SetTraceID(pcommon.TraceID(link.SpanContext.TraceID()))
+		dl.SetSpanID(pcommon.SpanID(link.SpanContext.SpanID()))
+		dl.TraceState().FromRaw(link.SpanContext.TraceState().String())
+		copyAttributes(dl.Attributes(), link.Attributes)
+		dl.SetDroppedAttributesCount(uint32(link.DroppedAttributeCount))
+	}
+	dest.SetDroppedLinksCount(uint32(span.DroppedLinks()))
+
+	status := span.Status()
+	switch status.Code {
+	case codes.Ok:
+		dest.Status().SetCode(ptrace.StatusCodeOk)
+	case codes.Error:
+		dest.Status().SetCode(ptrace.StatusCodeError)
+	}
+	dest.Status().SetMessage(status.Description)
+}
+
+func spanKind(kind trace.SpanKind) ptrace.SpanKind {
+	switch kind {
+	case trace.SpanKindInternal:
+		return ptrace.SpanKindInternal
+	case trace.SpanKindServer:
+		return ptrace.SpanKindServer
+	case trace.SpanKindClient:
+		return ptrace.SpanKindClient
+	case trace.SpanKindProducer:
+		return ptrace.SpanKindProducer
+	case trace.SpanKindConsumer:
+		return ptrace.SpanKindConsumer
+	default:
+		return ptrace.SpanKindUnspecified
+	}
+}
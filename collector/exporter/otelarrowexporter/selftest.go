@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowexporter // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/otel-arrow/collector/arrowprobe"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
+)
+
+// defaultSelfTestTimeout bounds the self-test when the establish
+// timeout is not set.
+const defaultSelfTestTimeout = 30 * time.Second
+
+// startSelfTest probes the receiver in the background, with the
+// stream context, call options and credentials of the Arrow streams,
+// and logs the result.  A failed self-test does not stop the
+// exporter, whose streams retry as usual.
+func (e *baseExporter) startSelfTest(ctx context.Context, callOpts []grpc.CallOption, creds credentials.PerRPCCredentials) {
+	timeout := e.config.Arrow.EstablishTimeout
+	if timeout <= 0 {
+		timeout = defaultSelfTestTimeout
+	}
+	ctx, e.selfTestCancel = context.WithTimeout(ctx, timeout)
+	e.selfTestDone = make(chan struct{})
+
+	go func() {
+		defer close(e.selfTestDone)
+		defer e.selfTestCancel()
+
+		logger := e.settings.TelemetrySettings.Logger
+		if err := e.selfTest(ctx, callOpts, creds); err != nil {
+			logger.Warn("arrow self-test failed",
+				zap.Stringer(streamevents.CodeKey, status.Code(err)),
+				zap.Error(err),
+			)
+			return
+		}
+		logger.Info("arrow self-test passed")
+	}()
+}
+
+// stopSelfTest cancels the self-test and waits for it to return.
+func (e *baseExporter) stopSelfTest() {
+	if e.selfTestCancel == nil {
+		return
+	}
+	e.selfTestCancel()
+	<-e.selfTestDone
+}
+
+// selfTest sends an Arrow probe, see the arrowprobe package.  The
+// per-RPC credentials are sent as the headers of the probe batch,
+// the same as for every Arrow batch.
+func (e *baseExporter) selfTest(ctx context.Context, callOpts []grpc.CallOption, creds credentials.PerRPCCredentials) error {
+	var headers metadata.MD
+	if creds != nil {
+		md, err := creds.GetRequestMetadata(ctx)
+		if err != nil {
+			return err
+		}
+		headers = metadata.New(md)
+	}
+	stream, _, err := e.streamClientFactory(e.clientConn)(ctx, callOpts...)
+	if err != nil {
+		return err
+	}
+	return arrowprobe.Run(stream, headers)
+}
@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowexporter
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configgrpc"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+
+	"github.com/open-telemetry/otel-arrow/collector/arrowprobe"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
+)
+
+// probeServer answers every Arrow batch with statusCode, and records
+// the probe batches it receives.
+type probeServer struct {
+	arrowpb.UnimplementedArrowTracesServiceServer
+
+	statusCode arrowpb.StatusCode
+	probes     chan *arrowpb.BatchArrowRecords
+}
+
+func (s *probeServer) ArrowTraces(stream arrowpb.ArrowTracesService_ArrowTracesServer) error {
+	for {
+		batch, err := stream.Recv()
+		if err != nil {
+			return nil
+		}
+		if batch.BatchId == arrowprobe.BatchID {
+			s.probes <- batch
+		}
+		if err := stream.Send(&arrowpb.BatchStatus{BatchId: batch.BatchId, StatusCode: s.statusCode}); err != nil {
+			return nil
+		}
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		statusCode arrowpb.StatusCode
+		message    string
+		code       string
+	}{
+		{"passed", arrowpb.StatusCode_OK, "arrow self-test passed", ""},
+		{"failed", arrowpb.StatusCode_UNAUTHENTICATED, "arrow self-test failed", "Unauthenticated"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:")
+			require.NoError(t, err)
+			srv := grpc.NewServer()
+			ps := &probeServer{statusCode: test.statusCode, probes: make(chan *arrowpb.BatchArrowRecords, 1)}
+			arrowpb.RegisterArrowTracesServiceServer(srv, ps)
+			go func() { _ = srv.Serve(ln) }()
+			defer srv.Stop()
+
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.ClientConfig = configgrpc.ClientConfig{
+				Endpoint: ln.Addr().String(),
+				TLSSetting: configtls.ClientConfig{
+					Insecure: true,
+				},
+			}
+			cfg.Arrow.NumStreams = 1
+			cfg.Arrow.SelfTest = true
+
+			core, logs := observer.New(zapcore.InfoLevel)
+			set := exportertest.NewNopCreateSettings()
+			set.TelemetrySettings.Logger = zap.New(core)
+			exp, err := factory.CreateTracesExporter(context.Background(), set, cfg)
+			require.NoError(t, err)
+			require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+			defer func() { require.NoError(t, exp.Shutdown(context.Background())) }()
+
+			// The probe is a batch without payloads.
+			probe := <-ps.probes
+			require.Empty(t, probe.ArrowPayloads)
+
+			require.Eventually(t, func() bool {
+				return logs.FilterMessage(test.message).Len() == 1
+			}, 10*time.Second, 5*time.Millisecond)
+			if test.code != "" {
+				fields := logs.FilterMessage(test.message).All()[0].ContextMap()
+				require.Equal(t, test.code, fields[streamevents.CodeKey])
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowexporter // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/multierr"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+)
+
+// The instrumentation scopes of the exporter helper.  The send path
+// metrics use the same scopes, names, and descriptions as the
+// exporter helper, so that its failure counts add to the same series.
+const (
+	exporterHelperScope = "go.opentelemetry.io/collector/exporterhelper"
+	obsReportScope      = "go.opentelemetry.io/collector/obsreport/exporter"
+)
+
+// sendMetrics reports the Arrow send path with the metrics of the
+// exporter helper: the failed sends and enqueues of each signal and,
+// without a sending queue, the batches held by the Arrow streams as
+// the queue.  Above the normal level of telemetry, measurements carry
+// the reason of a failure or the signal of the queued batches, and
+// the stream prioritizer.
+type sendMetrics struct {
+	id          string
+	prioritizer string
+	attrs       attribute.Set
+	detailed    bool
+
+	sendFailed    signalCounters
+	enqueueFailed signalCounters
+}
+
+// signalCounters count the items of each signal.
+type signalCounters struct {
+	spans        metric.Int64Counter
+	metricPoints metric.Int64Counter
+	logRecords   metric.Int64Counter
+}
+
+var _ arrow.SendReporter = (*sendMetrics)(nil)
+
+func newSendMetrics(set exporter.CreateSettings, prioritizer arrow.PrioritizerName) (*sendMetrics, error) {
+	meter := set.TelemetrySettings.MeterProvider.Meter(obsReportScope)
+	m := &sendMetrics{
+		id:          set.ID.String(),
+		prioritizer: prioritizer.String(),
+		attrs:       attribute.NewSet(attribute.String("exporter", set.ID.String())),
+		detailed:    set.TelemetrySettings.MetricsLevel >= configtelemetry.LevelDetailed,
+	}
+
+	var errs, err error
+	counter := func(name, desc string) metric.Int64Counter {
+		var c metric.Int64Counter
+		c, err = meter.Int64Counter(name, metric.WithDescription(desc), metric.WithUnit("1"))
+		errs = multierr.Append(errs, err)
+		return c
+	}
+	m.sendFailed = signalCounters{
+		spans:        counter("exporter_send_failed_spans", "Number of spans in failed attempts to send to destination."),
+		metricPoints: counter("exporter_send_failed_metric_points", "Number of metric points in failed attempts to send to destination."),
+		logRecords:   counter("exporter_send_failed_log_records", "Number of log records in failed attempts to send to destination."),
+	}
+	m.enqueueFailed = signalCounters{
+		spans:        counter("exporter_enqueue_failed_spans", "Number of spans failed to be added to the sending queue."),
+		metricPoints: counter("exporter_enqueue_failed_metric_points", "Number of metric points failed to be added to the sending queue."),
+		logRecords:   counter("exporter_enqueue_failed_log_records", "Number of log records failed to be added to the sending queue."),
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return m, nil
+}
+
+// EnqueueFailed implements arrow.SendReporter.
+func (m *sendMetrics) EnqueueFailed(ctx context.Context, data any, reason string) {
+	m.add(ctx, m.enqueueFailed, data, reason)
+}
+
+// SendFailed implements arrow.SendReporter.
+func (m *sendMetrics) SendFailed(ctx context.Context, data any, reason string) {
+	m.add(ctx, m.sendFailed, data, reason)
+}
+
+func (m *sendMetrics) add(ctx context.Context, counters signalCounters, data any, reason string) {
+	var counter metric.Int64Counter
+	switch data.(type) {
+	case ptrace.Traces:
+		counter = counters.spans
+	case pmetric.Metrics:
+		counter = counters.metricPoints
+	case plog.Logs:
+		counter = counters.logRecords
+	default:
+		return
+	}
+	attrs := metric.WithAttributeSet(m.attrs)
+	if m.detailed {
+		attrs = metric.WithAttributes(
+			attribute.String("exporter", m.id),
+			attribute.String("reason", reason),
+			attribute.String("prioritizer", m.prioritizer),
+		)
+	}
+	counter.Add(ctx, int64(itemCount(data)), attrs)
+}
+
+// registerQueue reports the batches held by the Arrow send path as
+// the exporter's queue, and the number of streams as its capacity.
+// This is called only without a sending queue, whose own metrics
+// have the same names.
+func (m *sendMetrics) registerQueue(set exporter.CreateSettings, exp *arrow.Exporter, numStreams int) error {
+	meter := set.TelemetrySettings.MeterProvider.Meter(exporterHelperScope)
+	attrs := metric.WithAttributeSet(m.attrs)
+
+	_, err := meter.Int64ObservableGauge(
+		"exporter/queue_size",
+		metric.WithDescription("Current size of the retry queue (in batches)"),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			traces, metrics, logs := exp.Queued()
+			if !m.detailed {
+				o.Observe(traces+metrics+logs, attrs)
+				return nil
+			}
+			for _, q := range []struct {
+				signal string
+				size   int64
+			}{{"traces", traces}, {"metrics", metrics}, {"logs", logs}} {
+				o.Observe(q.size, metric.WithAttributes(
+					attribute.String("exporter", m.id),
+					attribute.String("signal", q.signal),
+					attribute.String("prioritizer", m.prioritizer),
+				))
+			}
+			return nil
+		}),
+	)
+	errs := err
+
+	_, err = meter.Int64ObservableGauge(
+		"exporter/queue_capacity",
+		metric.WithDescription("Fixed capacity of the retry queue (in batches)"),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(int64(numStreams), attrs)
+			return nil
+		}),
+	)
+	return multierr.Append(errs, err)
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter/internal/arrow"
+)
+
+// collectSendMetrics returns the data points of the named sums and
+// gauges.
+func collectSendMetrics(t *testing.T, reader sdkmetric.Reader) map[string][]metricdata.DataPoint[int64] {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	points := map[string][]metricdata.DataPoint[int64]{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				points[m.Name] = data.DataPoints
+			case metricdata.Gauge[int64]:
+				points[m.Name] = data.DataPoints
+			}
+		}
+	}
+	return points
+}
+
+func TestArrowSendMetrics(t *testing.T) {
+	for _, level := range []configtelemetry.Level{configtelemetry.LevelNormal, configtelemetry.LevelDetailed} {
+		t.Run(level.String(), func(t *testing.T) {
+			reader := sdkmetric.NewManualReader()
+			set := exportertest.NewNopCreateSettings()
+			set.TelemetrySettings.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+			set.TelemetrySettings.MetricsLevel = level
+
+			m, err := newSendMetrics(set, arrow.RoundRobinPrioritizer)
+			require.NoError(t, err)
+
+			td := ptrace.NewTraces()
+			td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+			m.EnqueueFailed(context.Background(), td, arrow.ReasonMemoryLimit)
+			m.SendFailed(context.Background(), td, arrow.ReasonStreamRestarting)
+
+			exp := arrow.NewExporter(0, 3, arrow.DefaultPrioritizer, false, set.TelemetrySettings, nil, nil, nil, nil, nil, nil)
+			require.NoError(t, m.registerQueue(set, exp, 3))
+
+			points := collectSendMetrics(t, reader)
+			exporterAttr := attribute.String("exporter", set.ID.String())
+
+			enqueue := points["exporter_enqueue_failed_spans"]
+			require.Len(t, enqueue, 1)
+			require.Equal(t, int64(1), enqueue[0].Value)
+			send := points["exporter_send_failed_spans"]
+			require.Len(t, send, 1)
+			require.Equal(t, int64(1), send[0].Value)
+
+			capacity := points["exporter/queue_capacity"]
+			require.Len(t, capacity, 1)
+			require.Equal(t, int64(3), capacity[0].Value)
+			require.Equal(t, attribute.NewSet(exporterAttr), capacity[0].Attributes)
+
+			size := points["exporter/queue_size"]
+			if level < configtelemetry.LevelDetailed {
+				require.Equal(t, attribute.NewSet(exporterAttr), enqueue[0].Attributes)
+				require.Equal(t, attribute.NewSet(exporterAttr), send[0].Attributes)
+				require.Len(t, size, 1)
+				require.Equal(t, int64(0), size[0].Value)
+				return
+			}
+			prioritizerAttr := attribute.String("prioritizer", "roundrobin")
+			require.Equal(t, attribute.NewSet(exporterAttr, prioritizerAttr, attribute.String("reason", arrow.ReasonMemoryLimit)), enqueue[0].Attributes)
+			require.Equal(t, attribute.NewSet(exporterAttr, prioritizerAttr, attribute.String("reason", arrow.ReasonStreamRestarting)), send[0].Attributes)
+			require.Len(t, size, 3)
+			for _, dp := range size {
+				signal, ok := dp.Attributes.Value("signal")
+				require.True(t, ok)
+				require.Contains(t, []string{"traces", "metrics", "logs"}, signal.AsString())
+				require.True(t, dp.Attributes.HasValue("prioritizer"))
+			}
+		})
+	}
+}
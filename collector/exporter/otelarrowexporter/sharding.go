@@ -0,0 +1,584 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowexporter // import "github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// ringReplicas is the number of points each shard occupies on the
+// hash ring, which evens out the share of keys per shard.
+const ringReplicas = 128
+
+// exporterPusher is implemented by baseExporter and shardedExporter.
+type exporterPusher interface {
+	start(ctx context.Context, host component.Host) error
+	shutdown(ctx context.Context) error
+	pushTraces(ctx context.Context, td ptrace.Traces) error
+	pushMetrics(ctx context.Context, md pmetric.Metrics) error
+	pushLogs(ctx context.Context, ld plog.Logs) error
+}
+
+var (
+	_ exporterPusher = (*baseExporter)(nil)
+	_ exporterPusher = (*shardedExporter)(nil)
+)
+
+// newPusher returns a shardedExporter when sharding endpoints are
+// configured, otherwise a baseExporter.
+func newPusher(cfg component.Config, set exporter.CreateSettings, signal component.DataType, streamClientFactory streamClientFactory) (exporterPusher, error) {
+	oCfg := cfg.(*Config)
+	if len(oCfg.Sharding.Endpoints) == 0 {
+		return newExporter(cfg, set, signal, streamClientFactory)
+	}
+	return newShardedExporter(oCfg, set, signal, streamClientFactory)
+}
+
+// shardedExporter divides data among several baseExporters using a
+// consistent hash of the configured key.  Each shard has its own
+// connection and Arrow stream pool.
+type shardedExporter struct {
+	shards []*baseExporter
+	health []shardHealth
+	ring   shardRing
+	logger *zap.Logger
+
+	// key selects how data is assigned to shards.
+	key ShardKey
+
+	// resourceAttributes are the identifying attributes used
+	// with ShardKeyResource.
+	resourceAttributes []string
+
+	// unhealthyDuration is how long a shard is avoided after a
+	// retryable failure.
+	unhealthyDuration time.Duration
+
+	// next selects shards round-robin for data without a key.
+	next atomic.Uint64
+
+	// now is time.Now, except in tests.
+	now func() time.Time
+}
+
+// shardHealth records until when a shard is considered unhealthy.
+type shardHealth struct {
+	unhealthyUntil atomic.Int64
+}
+
+func newShardedExporter(cfg *Config, set exporter.CreateSettings, signal component.DataType, streamClientFactory streamClientFactory) (*shardedExporter, error) {
+	se := &shardedExporter{
+		health:             make([]shardHealth, len(cfg.Sharding.Endpoints)),
+		ring:               newShardRing(cfg.Sharding.Endpoints),
+		logger:             set.Logger,
+		unhealthyDuration:  cfg.Sharding.UnhealthyDuration,
+		now:                time.Now,
+		key:                cfg.Sharding.Key,
+		resourceAttributes: cfg.Sharding.ResourceAttributes,
+	}
+	if se.unhealthyDuration == 0 {
+		se.unhealthyDuration = DefaultShardUnhealthyDuration
+	}
+	if se.key == "" {
+		se.key = ShardKeyTraceID
+	}
+	if len(se.resourceAttributes) == 0 {
+		se.resourceAttributes = DefaultShardResourceAttributes
+	}
+	for i, endpoint := range cfg.Sharding.Endpoints {
+		shardCfg := *cfg
+		shardCfg.Endpoint = endpoint
+		shardCfg.Sharding = ShardingConfig{}
+
+		shardSet := set
+		shardSet.ID = shardID(set.ID, i)
+		shardSet.Logger = set.Logger.With(zap.String("shard", endpoint))
+
+		exp, err := newExporter(&shardCfg, shardSet, signal, streamClientFactory)
+		if err != nil {
+			return nil, fmt.Errorf("shard %q: %w", endpoint, err)
+		}
+		se.shards = append(se.shards, exp)
+	}
+	return se, nil
+}
+
+// shardID names the shard's telemetry after the exporter.
+func shardID(id component.ID, shard int) component.ID {
+	name := "shard" + strconv.Itoa(shard)
+	if id.Name() != "" {
+		name = id.Name() + "_" + name
+	}
+	return component.NewIDWithName(id.Type(), name)
+}
+
+func (se *shardedExporter) start(ctx context.Context, host component.Host) error {
+	for _, shard := range se.shards {
+		if err := shard.start(ctx, host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (se *shardedExporter) shutdown(ctx context.Context) error {
+	var err error
+	for _, shard := range se.shards {
+		err = multierr.Append(err, shard.shutdown(ctx))
+	}
+	return err
+}
+
+// healthy returns true unless the shard failed recently.
+func (se *shardedExporter) healthy(shard int) bool {
+	return se.now().UnixNano() >= se.health[shard].unhealthyUntil.Load()
+}
+
+// markUnhealthy causes new data to avoid the shard for a while.
+func (se *shardedExporter) markUnhealthy(shard int) {
+	se.health[shard].unhealthyUntil.Store(se.now().Add(se.unhealthyDuration).UnixNano())
+}
+
+// pickHash returns the shard for a key's hash.
+func (se *shardedExporter) pickHash(h uint64) int {
+	return se.ring.lookup(h, se.healthy)
+}
+
+// pickAny returns a healthy shard, round-robin, for data without a
+// key.
+func (se *shardedExporter) pickAny() int {
+	start := int(se.next.Add(1) % uint64(len(se.shards)))
+	for i := 0; i < len(se.shards); i++ {
+		shard := (start + i) % len(se.shards)
+		if se.healthy(shard) {
+			return shard
+		}
+	}
+	return start
+}
+
+func (se *shardedExporter) pushTraces(ctx context.Context, td ptrace.Traces) error {
+	var parts map[int]ptrace.Traces
+	if se.key == ShardKeyResource {
+		parts = se.splitTracesByResource(td)
+	} else {
+		parts = se.splitTracesByTraceID(td)
+	}
+	failed, err := pushParts(ctx, se, parts, (*baseExporter).pushTraces)
+	switch len(failed) {
+	case 0:
+		return err
+	case 1:
+		// This may be the caller's data, which is not modified.
+		return consumererror.NewTraces(err, failed[0])
+	}
+	retry := ptrace.NewTraces()
+	for _, part := range failed {
+		part.ResourceSpans().MoveAndAppendTo(retry.ResourceSpans())
+	}
+	return consumererror.NewTraces(err, retry)
+}
+
+func (se *shardedExporter) pushMetrics(ctx context.Context, md pmetric.Metrics) error {
+	var parts map[int]pmetric.Metrics
+	if se.key == ShardKeyResource {
+		parts = se.splitMetricsByResource(md)
+	} else {
+		// Metrics do not carry a trace ID.
+		parts = map[int]pmetric.Metrics{se.pickAny(): md}
+	}
+	failed, err := pushParts(ctx, se, parts, (*baseExporter).pushMetrics)
+	if len(failed) == 0 {
+		return err
+	}
+	return consumererror.NewMetrics(err, md)
+}
+
+func (se *shardedExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
+	var parts map[int]plog.Logs
+	if se.key == ShardKeyResource {
+		parts = se.splitLogsByResource(ld)
+	} else {
+		parts = se.splitLogsByTraceID(ld)
+	}
+	failed, err := pushParts(ctx, se, parts, (*baseExporter).pushLogs)
+	switch len(failed) {
+	case 0:
+		return err
+	case 1:
+		// This may be the caller's data, which is not modified.
+		return consumererror.NewLogs(err, failed[0])
+	}
+	retry := plog.NewLogs()
+	for _, part := range failed {
+		part.ResourceLogs().MoveAndAppendTo(retry.ResourceLogs())
+	}
+	return consumererror.NewLogs(err, retry)
+}
+
+// pushParts sends each part to its shard concurrently.  Shards that
+// return a retryable error are marked unhealthy, so that a retry of
+// the returned failed parts goes to the next healthy shard.  Data
+// permanently rejected by a shard is dropped and, when other parts
+// can be retried, only logged.
+func pushParts[T any](ctx context.Context, se *shardedExporter, parts map[int]T, push func(*baseExporter, context.Context, T) error) (failed []T, _ error) {
+	var (
+		lock      sync.Mutex
+		wg        sync.WaitGroup
+		retryErr  error
+		permErr   error
+		numShards = len(parts)
+	)
+	for shard, data := range parts {
+		shard, data := shard, data
+		push1 := func() {
+			err := push(se.shards[shard], ctx, data)
+			if err == nil {
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			if consumererror.IsPermanent(err) {
+				permErr = multierr.Append(permErr, err)
+				return
+			}
+			se.markUnhealthy(shard)
+			retryErr = multierr.Append(retryErr, err)
+			failed = append(failed, data)
+		}
+		if numShards == 1 {
+			push1()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			push1()
+		}()
+	}
+	wg.Wait()
+
+	if retryErr == nil {
+		return nil, permErr
+	}
+	if permErr != nil {
+		se.logger.Error("dropping data permanently rejected by shard", zap.Error(permErr))
+	}
+	return failed, retryErr
+}
+
+// splitTracesByTraceID divides spans by trace ID.
+func (se *shardedExporter) splitTracesByTraceID(td ptrace.Traces) map[int]ptrace.Traces {
+	if shard, ok := se.singleTracesShard(td); ok {
+		return map[int]ptrace.Traces{shard: td}
+	}
+	out := map[int]ptrace.Traces{}
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		dstRS := map[int]ptrace.ResourceSpans{}
+		for j := 0; j < rs.ScopeSpans().Len(); j++ {
+			ss := rs.ScopeSpans().At(j)
+			dstSS := map[int]ptrace.ScopeSpans{}
+			for k := 0; k < ss.Spans().Len(); k++ {
+				span := ss.Spans().At(k)
+				shard := se.pickHash(hashTraceID(span.TraceID()))
+
+				dss, ok := dstSS[shard]
+				if !ok {
+					drs, ok := dstRS[shard]
+					if !ok {
+						t, ok := out[shard]
+						if !ok {
+							t = ptrace.NewTraces()
+							out[shard] = t
+						}
+						drs = t.ResourceSpans().AppendEmpty()
+						rs.Resource().CopyTo(drs.Resource())
+						drs.SetSchemaUrl(rs.SchemaUrl())
+						dstRS[shard] = drs
+					}
+					dss = drs.ScopeSpans().AppendEmpty()
+					ss.Scope().CopyTo(dss.Scope())
+					dss.SetSchemaUrl(ss.SchemaUrl())
+					dstSS[shard] = dss
+				}
+				span.CopyTo(dss.Spans().AppendEmpty())
+			}
+		}
+	}
+	return out
+}
+
+// singleTracesShard returns true when every span belongs on one
+// shard, so the data can be sent without copying.
+func (se *shardedExporter) singleTracesShard(td ptrace.Traces) (int, bool) {
+	first := -1
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		sss := rss.At(i).ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				shard := se.pickHash(hashTraceID(spans.At(k).TraceID()))
+				if first == -1 {
+					first = shard
+				} else if shard != first {
+					return 0, false
+				}
+			}
+		}
+	}
+	if first == -1 {
+		return se.pickAny(), true
+	}
+	return first, true
+}
+
+// splitLogsByTraceID divides log records by trace ID.  Records
+// without a trace ID are kept together on one shard.
+func (se *shardedExporter) splitLogsByTraceID(ld plog.Logs) map[int]plog.Logs {
+	noTrace := -1
+	pick := func(lr plog.LogRecord) int {
+		if lr.TraceID().IsEmpty() {
+			if noTrace == -1 {
+				noTrace = se.pickAny()
+			}
+			return noTrace
+		}
+		return se.pickHash(hashTraceID(lr.TraceID()))
+	}
+
+	out := map[int]plog.Logs{}
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		dstRL := map[int]plog.ResourceLogs{}
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			sl := rl.ScopeLogs().At(j)
+			dstSL := map[int]plog.ScopeLogs{}
+			for k := 0; k < sl.LogRecords().Len(); k++ {
+				lr := sl.LogRecords().At(k)
+				shard := pick(lr)
+
+				dsl, ok := dstSL[shard]
+				if !ok {
+					drl, ok := dstRL[shard]
+					if !ok {
+						l, ok := out[shard]
+						if !ok {
+							l = plog.NewLogs()
+							out[shard] = l
+						}
+						drl = l.ResourceLogs().AppendEmpty()
+						rl.Resource().CopyTo(drl.Resource())
+						drl.SetSchemaUrl(rl.SchemaUrl())
+						dstRL[shard] = drl
+					}
+					dsl = drl.ScopeLogs().AppendEmpty()
+					sl.Scope().CopyTo(dsl.Scope())
+					dsl.SetSchemaUrl(sl.SchemaUrl())
+					dstSL[shard] = dsl
+				}
+				lr.CopyTo(dsl.LogRecords().AppendEmpty())
+			}
+		}
+	}
+	if len(out) == 0 {
+		out[se.pickAny()] = ld
+	}
+	return out
+}
+
+// resourceShards returns the shard of each of n resources and
+// whether they are all the same.
+func (se *shardedExporter) resourceShards(n int, resource func(int) pcommon.Resource) ([]int, bool) {
+	shards := make([]int, n)
+	single := true
+	for i := range shards {
+		shards[i] = se.pickHash(hashResource(resource(i), se.resourceAttributes))
+		if shards[i] != shards[0] {
+			single = false
+		}
+	}
+	return shards, single
+}
+
+// splitTracesByResource divides resources by identity.
+func (se *shardedExporter) splitTracesByResource(td ptrace.Traces) map[int]ptrace.Traces {
+	rss := td.ResourceSpans()
+	shards, single := se.resourceShards(rss.Len(), func(i int) pcommon.Resource {
+		return rss.At(i).Resource()
+	})
+	if single {
+		if len(shards) == 0 {
+			return map[int]ptrace.Traces{se.pickAny(): td}
+		}
+		return map[int]ptrace.Traces{shards[0]: td}
+	}
+	out := map[int]ptrace.Traces{}
+	for i, shard := range shards {
+		t, ok := out[shard]
+		if !ok {
+			t = ptrace.NewTraces()
+			out[shard] = t
+		}
+		rss.At(i).CopyTo(t.ResourceSpans().AppendEmpty())
+	}
+	return out
+}
+
+// splitMetricsByResource divides resources by identity.
+func (se *shardedExporter) splitMetricsByResource(md pmetric.Metrics) map[int]pmetric.Metrics {
+	rms := md.ResourceMetrics()
+	shards, single := se.resourceShards(rms.Len(), func(i int) pcommon.Resource {
+		return rms.At(i).Resource()
+	})
+	if single {
+		if len(shards) == 0 {
+			return map[int]pmetric.Metrics{se.pickAny(): md}
+		}
+		return map[int]pmetric.Metrics{shards[0]: md}
+	}
+	out := map[int]pmetric.Metrics{}
+	for i, shard := range shards {
+		m, ok := out[shard]
+		if !ok {
+			m = pmetric.NewMetrics()
+			out[shard] = m
+		}
+		rms.At(i).CopyTo(m.ResourceMetrics().AppendEmpty())
+	}
+	return out
+}
+
+// splitLogsByResource divides resources by identity.
+func (se *shardedExporter) splitLogsByResource(ld plog.Logs) map[int]plog.Logs {
+	rls := ld.ResourceLogs()
+	shards, single := se.resourceShards(rls.Len(), func(i int) pcommon.Resource {
+		return rls.At(i).Resource()
+	})
+	if single {
+		if len(shards) == 0 {
+			return map[int]plog.Logs{se.pickAny(): ld}
+		}
+		return map[int]plog.Logs{shards[0]: ld}
+	}
+	out := map[int]plog.Logs{}
+	for i, shard := range shards {
+		l, ok := out[shard]
+		if !ok {
+			l = plog.NewLogs()
+			out[shard] = l
+		}
+		rls.At(i).CopyTo(l.ResourceLogs().AppendEmpty())
+	}
+	return out
+}
+
+// hashResource hashes the identifying attributes of a resource.  A
+// resource with none of them is identified by all its attributes.
+func hashResource(res pcommon.Resource, keys []string) uint64 {
+	var buf []byte
+	attrs := res.Attributes()
+	for _, key := range keys {
+		if v, ok := attrs.Get(key); ok {
+			buf = append(buf, key...)
+			buf = append(buf, '=')
+			buf = append(buf, v.AsString()...)
+			buf = append(buf, 0)
+		}
+	}
+	if buf == nil {
+		all := make([]string, 0, attrs.Len())
+		attrs.Range(func(k string, v pcommon.Value) bool {
+			all = append(all, k+"="+v.AsString())
+			return true
+		})
+		sort.Strings(all)
+		for _, kv := range all {
+			buf = append(buf, kv...)
+			buf = append(buf, 0)
+		}
+	}
+	return hashBytes(buf)
+}
+
+func hashTraceID(id pcommon.TraceID) uint64 {
+	return hashBytes(id[:])
+}
+
+// hashBytes is FNV-1a followed by the MurmurHash3 finalizer, which
+// spreads keys that differ only in their last bytes (e.g.,
+// sequential trace IDs) around the ring.
+func hashBytes(b []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// shardRing is a consistent-hash ring.  Points are derived from the
+// endpoint names, so adding or removing an endpoint moves only the
+// keys that belong to it.
+type shardRing struct {
+	points []ringPoint
+}
+
+type ringPoint struct {
+	hash  uint64
+	shard int
+}
+
+func newShardRing(endpoints []string) shardRing {
+	var r shardRing
+	for shard, endpoint := range endpoints {
+		for v := 0; v < ringReplicas; v++ {
+			h := hashBytes([]byte(endpoint + "#" + strconv.Itoa(v)))
+			r.points = append(r.points, ringPoint{hash: h, shard: shard})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		return r.points[i].hash < r.points[j].hash
+	})
+	return r
+}
+
+// lookup returns the first shard at or after h on the ring for which
+// usable returns true.  When no shard is usable, it returns the
+// shard that owns h.
+func (r shardRing) lookup(h uint64, usable func(int) bool) int {
+	start := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= h
+	})
+	for i := 0; i < len(r.points); i++ {
+		p := r.points[(start+i)%len(r.points)]
+		if usable(p.shard) {
+			return p.shard
+		}
+	}
+	return r.points[start%len(r.points)].shard
+}
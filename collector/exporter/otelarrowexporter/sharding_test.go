@@ -0,0 +1,259 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowexporter
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func testTraceID(i int) pcommon.TraceID {
+	var id pcommon.TraceID
+	binary.BigEndian.PutUint64(id[8:], uint64(i))
+	return id
+}
+
+func TestShardRing(t *testing.T) {
+	endpoints := []string{"a:4317", "b:4317", "c:4317"}
+	ring := newShardRing(endpoints)
+	all := func(int) bool { return true }
+
+	counts := make([]int, len(endpoints))
+	owner := map[int]int{}
+	for i := 0; i < 3000; i++ {
+		shard := ring.lookup(hashTraceID(testTraceID(i)), all)
+		counts[shard]++
+		owner[i] = shard
+	}
+	for _, c := range counts {
+		require.InDelta(t, 1000, c, 250)
+	}
+
+	// Removing an endpoint moves only the keys it owned.
+	smaller := newShardRing(endpoints[:2])
+	for i := 0; i < 3000; i++ {
+		if owner[i] == 2 {
+			continue
+		}
+		require.Equal(t, owner[i], smaller.lookup(hashTraceID(testTraceID(i)), all))
+	}
+
+	// Keys of an unusable shard go elsewhere, others stay.
+	noB := func(shard int) bool { return shard != 1 }
+	for i := 0; i < 3000; i++ {
+		shard := ring.lookup(hashTraceID(testTraceID(i)), noB)
+		require.NotEqual(t, 1, shard)
+		if owner[i] != 1 {
+			require.Equal(t, owner[i], shard)
+		}
+	}
+
+	// When no shard is usable, the owner is returned.
+	none := func(int) bool { return false }
+	require.Equal(t, owner[7], ring.lookup(hashTraceID(testTraceID(7)), none))
+}
+
+func TestShardingConfigValidate(t *testing.T) {
+	require.NoError(t, (&ShardingConfig{}).Validate())
+	require.NoError(t, (&ShardingConfig{Endpoints: []string{"a", "b"}, Key: ShardKeyTraceID}).Validate())
+	require.Error(t, (&ShardingConfig{Key: "span_id"}).Validate())
+	require.Error(t, (&ShardingConfig{UnhealthyDuration: -time.Second}).Validate())
+	require.Error(t, (&ShardingConfig{Endpoints: []string{"a", ""}}).Validate())
+	require.Error(t, (&ShardingConfig{Endpoints: []string{"a", "a"}}).Validate())
+}
+
+func newShardedTracesTest(t *testing.T, n int) ([]*mockTracesReceiver, *Config) {
+	var rcvs []*mockTracesReceiver
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.QueueSettings.Enabled = false
+	cfg.RetryConfig.Enabled = false
+	cfg.ClientConfig.TLSSetting = configtls.ClientConfig{Insecure: true}
+	cfg.Arrow.Disabled = true
+
+	for i := 0; i < n; i++ {
+		ln, err := net.Listen("tcp", "localhost:")
+		require.NoError(t, err)
+		rcv, _ := otelArrowTracesReceiverOnGRPCServer(ln, false)
+		rcv.start()
+		t.Cleanup(rcv.srv.GracefulStop)
+		rcvs = append(rcvs, rcv)
+		cfg.Sharding.Endpoints = append(cfg.Sharding.Endpoints, ln.Addr().String())
+	}
+	return rcvs, cfg
+}
+
+func manyTraces(numTraces, spansPerTrace int) ptrace.Traces {
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "test")
+	ss := rs.ScopeSpans().AppendEmpty()
+	ss.Scope().SetName("scope")
+	for i := 0; i < numTraces; i++ {
+		for j := 0; j < spansPerTrace; j++ {
+			span := ss.Spans().AppendEmpty()
+			span.SetTraceID(testTraceID(i))
+			span.SetName("span")
+		}
+	}
+	return td
+}
+
+func traceIDsOf(td ptrace.Traces) map[pcommon.TraceID]int {
+	ids := map[pcommon.TraceID]int{}
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		if v, ok := rs.Resource().Attributes().Get("service.name"); !ok || v.Str() != "test" {
+			panic("resource not copied")
+		}
+		sss := rs.ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				ids[spans.At(k).TraceID()]++
+			}
+		}
+	}
+	return ids
+}
+
+func TestShardedTracesByTraceID(t *testing.T) {
+	rcvs, cfg := newShardedTracesTest(t, 3)
+
+	exp, err := NewFactory().CreateTracesExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	require.NoError(t, err)
+	host := newHostWithExtensions(nil)
+	require.NoError(t, exp.Start(context.Background(), host))
+	defer func() {
+		require.NoError(t, exp.Shutdown(context.Background()))
+	}()
+
+	const numTraces, spansPerTrace = 60, 3
+	require.NoError(t, exp.ConsumeTraces(context.Background(), manyTraces(numTraces, spansPerTrace)))
+
+	seen := map[pcommon.TraceID]bool{}
+	total := 0
+	for _, rcv := range rcvs {
+		require.Equal(t, int32(1), rcv.requestCount.Load())
+		for id, count := range traceIDsOf(rcv.getLastRequest()) {
+			// Every span of a trace arrives at one shard.
+			require.Equal(t, spansPerTrace, count)
+			require.False(t, seen[id])
+			seen[id] = true
+			total += count
+		}
+	}
+	require.Equal(t, numTraces*spansPerTrace, total)
+}
+
+func TestShardedTracesFallback(t *testing.T) {
+	rcvs, cfg := newShardedTracesTest(t, 2)
+	rcvs[1].setExportError(status.Error(codes.Unavailable, "shard down"))
+
+	exp, err := NewFactory().CreateTracesExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	require.NoError(t, err)
+	host := newHostWithExtensions(nil)
+	require.NoError(t, exp.Start(context.Background(), host))
+	defer func() {
+		require.NoError(t, exp.Shutdown(context.Background()))
+	}()
+
+	const numTraces = 40
+	err = exp.ConsumeTraces(context.Background(), manyTraces(numTraces, 1))
+	require.Error(t, err)
+	require.False(t, consumererror.IsPermanent(err))
+
+	// Only the data of the failed shard is returned for retry.
+	var failed consumererror.Traces
+	require.True(t, errors.As(err, &failed))
+	failedIDs := traceIDsOf(failed.Data())
+	require.Equal(t, failedIDs, traceIDsOf(rcvs[1].getLastRequest()))
+	require.Equal(t, numTraces-len(failedIDs), rcvs[0].getLastRequest().SpanCount())
+
+	// The retry goes to the healthy shard.
+	require.NoError(t, exp.ConsumeTraces(context.Background(), failed.Data()))
+	require.Equal(t, failedIDs, traceIDsOf(rcvs[0].getLastRequest()))
+	require.Equal(t, int32(1), rcvs[1].requestCount.Load())
+}
+
+func TestShardedSplitByResource(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Sharding = ShardingConfig{
+		Endpoints: []string{"a:4317", "b:4317", "c:4317", "d:4317"},
+		Key:       ShardKeyResource,
+	}
+	require.NoError(t, cfg.Sharding.Validate())
+	se, err := newShardedExporter(cfg, exportertest.NewNopCreateSettings(), component.DataTypeMetrics, createArrowMetricsStream)
+	require.NoError(t, err)
+
+	md := pmetric.NewMetrics()
+	for i := 0; i < 40; i++ {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().PutStr("service.name", fmt.Sprint("svc", i%10))
+		rm.Resource().Attributes().PutInt("ignored", int64(i))
+		rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetName(fmt.Sprint("svc", i%10))
+	}
+
+	parts := se.splitMetricsByResource(md)
+	require.Greater(t, len(parts), 1)
+
+	// Every resource of a service is on one shard, regardless of
+	// other attributes, and shards are stable across calls.
+	owner := map[string]int{}
+	total := 0
+	for shard, part := range parts {
+		rms := part.ResourceMetrics()
+		total += rms.Len()
+		for i := 0; i < rms.Len(); i++ {
+			svc, _ := rms.At(i).Resource().Attributes().Get("service.name")
+			require.Equal(t, svc.Str(), rms.At(i).ScopeMetrics().At(0).Metrics().At(0).Name())
+			if prev, ok := owner[svc.Str()]; ok {
+				require.Equal(t, prev, shard)
+			}
+			owner[svc.Str()] = shard
+		}
+	}
+	require.Equal(t, 40, total)
+	require.Len(t, owner, 10)
+	for shard, part := range se.splitMetricsByResource(md) {
+		svc, _ := part.ResourceMetrics().At(0).Resource().Attributes().Get("service.name")
+		require.Equal(t, owner[svc.Str()], shard)
+	}
+
+	// A single-resource batch is passed through.
+	one := pmetric.NewMetrics()
+	one.ResourceMetrics().AppendEmpty().Resource().Attributes().PutStr("service.name", "svc3")
+	single := se.splitMetricsByResource(one)
+	require.Len(t, single, 1)
+	require.Equal(t, one, single[owner["svc3"]])
+
+	// Resources without identifying attributes are hashed on all
+	// attributes.
+	r1, r2 := pcommon.NewResource(), pcommon.NewResource()
+	r1.Attributes().PutStr("host.name", "h")
+	r1.Attributes().PutStr("zone", "z")
+	r2.Attributes().PutStr("zone", "z")
+	r2.Attributes().PutStr("host.name", "h")
+	require.Equal(t, hashResource(r1, DefaultShardResourceAttributes), hashResource(r2, DefaultShardResourceAttributes))
+	r2.Attributes().PutStr("zone", "y")
+	require.NotEqual(t, hashResource(r1, DefaultShardResourceAttributes), hashResource(r2, DefaultShardResourceAttributes))
+
+	require.Error(t, (&ShardingConfig{Key: ShardKeyResource, ResourceAttributes: []string{""}}).Validate())
+}
@@ -5,6 +5,7 @@ package netstats // import "github.com/open-telemetry/otel-arrow/collector/netst
 
 import (
 	"context"
+	"fmt"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
@@ -12,6 +13,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
 
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configtelemetry"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/receiver"
@@ -45,19 +47,137 @@ const (
 	// CompSize is used for compressed size histogram metrics.
 	CompSize = "compressed_size"
 
+	// SentSize is used for the optional histogram of bytes sent
+	// by exporters and receivers, see WithSizeHistograms.
+	SentSize = "sent_size"
+
+	// SentWireSize is used for the optional histogram of bytes
+	// sent on the wire by exporters and receivers.
+	SentWireSize = "sent_wire_size"
+
+	// RecvSize is used for the optional histogram of bytes
+	// received by exporters and receivers.
+	RecvSize = "recv_size"
+
+	// RecvWireSize is used for the optional histogram of bytes
+	// received on the wire by exporters and receivers.
+	RecvWireSize = "recv_wire_size"
+
 	scopeName = "github.com/open-telemetry/otel-arrow/collector/netstats"
 )
 
 // NetworkReporter is a helper to add network-level observability to
-// an exporter or receiver.
+// an exporter or receiver.  The detail of its metrics follows the
+// level of telemetry:
+//
+//   - basic: totals of the bytes in the component's direction, with
+//     only the component attribute;
+//   - normal: adds the gRPC method, which identifies the signal;
+//   - detailed: adds the stream, the bytes in the other direction,
+//     and the size histograms.
 type NetworkReporter struct {
 	isExporter    bool
+	level         configtelemetry.Level
+	pluginsOnly   bool
 	staticAttr    attribute.KeyValue
 	sentBytes     metric.Int64Counter
 	sentWireBytes metric.Int64Counter
 	recvBytes     metric.Int64Counter
 	recvWireBytes metric.Int64Counter
 	compSizeHisto metric.Int64Histogram
+
+	// The size histograms are recorded at the detailed level, or
+	// the normal level with WithSizeHistograms.
+	sentSizeHisto     metric.Int64Histogram
+	sentWireSizeHisto metric.Int64Histogram
+	recvSizeHisto     metric.Int64Histogram
+	recvWireSizeHisto metric.Int64Histogram
+
+	// plugins are the registered reporters, see RegisterReporter.
+	plugins []Interface
+
+	// spanSizes selects the size attributes set on the span of
+	// each message.
+	spanSizes SpanSizeAttributes
+}
+
+// Option configures optional NetworkReporter behavior.
+type Option func(*options)
+
+type options struct {
+	withSizeHistograms bool
+	spanSizes          SpanSizeAttributes
+}
+
+// SpanSizeAttributes selects the message size attributes that a
+// NetworkReporter sets on the span of the context passed to CountSend
+// and CountReceive.
+type SpanSizeAttributes string
+
+const (
+	// SpanSizeAttributesOff sets no size attributes.
+	SpanSizeAttributesOff SpanSizeAttributes = "off"
+	// SpanSizeAttributesCompressed sets only the compressed
+	// sizes, `sent_compressed` and `received_compressed`.
+	SpanSizeAttributesCompressed SpanSizeAttributes = "compressed"
+	// SpanSizeAttributesBoth sets the uncompressed sizes as well,
+	// `sent_uncompressed` and `received_uncompressed`.  This is
+	// the default.
+	SpanSizeAttributesBoth SpanSizeAttributes = "both"
+)
+
+// Validate returns an error for an unknown setting.  The empty
+// setting is SpanSizeAttributesBoth.
+func (s SpanSizeAttributes) Validate() error {
+	switch s {
+	case "", SpanSizeAttributesOff, SpanSizeAttributesCompressed, SpanSizeAttributesBoth:
+		return nil
+	}
+	return fmt.Errorf("unknown span size attributes %q, use %s, %s or %s", s, SpanSizeAttributesOff, SpanSizeAttributesCompressed, SpanSizeAttributesBoth)
+}
+
+// uncompressed returns whether the uncompressed sizes are set.
+func (s SpanSizeAttributes) uncompressed() bool {
+	return s == "" || s == SpanSizeAttributesBoth
+}
+
+// compressed returns whether the compressed sizes are set.
+func (s SpanSizeAttributes) compressed() bool {
+	return s != SpanSizeAttributesOff
+}
+
+// sizeHistograms returns whether the size histograms are recorded at
+// the level.
+func (o options) sizeHistograms(level configtelemetry.Level) bool {
+	return level > configtelemetry.LevelNormal || (o.withSizeHistograms && level == configtelemetry.LevelNormal)
+}
+
+// WithSizeHistograms enables a histogram of message sizes alongside
+// each of the byte counters at the normal level of telemetry, as at
+// the detailed level, allowing percentile analysis of batch sizes.
+// Measurements are recorded using the context passed to CountSend
+// and CountReceive, so an SDK that samples exemplars will link the
+// histogram points to the corresponding export span.
+func WithSizeHistograms() Option {
+	return func(o *options) {
+		o.withSizeHistograms = true
+	}
+}
+
+// WithSpanSizeAttributes selects the size attributes set on spans,
+// which some tracing backends bill per attribute.
+func WithSpanSizeAttributes(s SpanSizeAttributes) Option {
+	return func(o *options) {
+		o.spanSizes = s
+	}
+}
+
+func makeOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
 }
 
 var _ Interface = &NetworkReporter{}
@@ -71,9 +191,14 @@ type SizesStruct struct {
 	Length int64
 	// WireLength is compressed size
 	WireLength int64
+	// Stream optionally identifies the stream within the method,
+	// recorded as an attribute at the detailed level of telemetry.
+	Stream string
 }
 
-// Interface describes a *NetworkReporter or a Noop.
+// Interface describes a *NetworkReporter or a Noop.  Additional
+// implementations may be registered with RegisterReporter to observe
+// the same sizes as every NetworkReporter.
 type Interface interface {
 	// CountSend reports outbound bytes.
 	CountSend(ctx context.Context, ss SizesStruct)
@@ -104,123 +229,222 @@ const (
 	recvDescription     = "Number of bytes received by the component."
 	recvWireDescription = "Number of bytes received on the wire by the component."
 	compSizeDescription = "Size of compressed payload"
+
+	sentSizeDescription     = "Size of messages sent by the component."
+	sentWireSizeDescription = "Size of messages sent on the wire by the component."
+	recvSizeDescription     = "Size of messages received by the component."
+	recvWireSizeDescription = "Size of messages received on the wire by the component."
 )
 
 // makeSentMetrics builds the sent and sent-wire metric instruments
 // for an exporter or receiver using the corresponding `prefix`.
-// major` indicates the major direction of the pipeline,
-// which is true when sending for exporters, receiving for receivers.
-func makeSentMetrics(prefix string, meter metric.Meter, major bool) (sent, sentWire metric.Int64Counter, _ error) {
-	var sentBytes metric.Int64Counter = noopmetric.Int64Counter{}
-	var err1 error
-	if major {
-		sentBytes, err1 = meter.Int64Counter(prefix+"_"+SentBytes, metric.WithDescription(sentDescription), metric.WithUnit(bytesUnit))
-	}
+func makeSentMetrics(prefix string, meter metric.Meter) (sent, sentWire metric.Int64Counter, _ error) {
+	sentBytes, err1 := meter.Int64Counter(prefix+"_"+SentBytes, metric.WithDescription(sentDescription), metric.WithUnit(bytesUnit))
 	sentWireBytes, err2 := meter.Int64Counter(prefix+"_"+SentWireBytes, metric.WithDescription(sentWireDescription), metric.WithUnit(bytesUnit))
 	return sentBytes, sentWireBytes, multierr.Append(err1, err2)
 }
 
 // makeRecvMetrics builds the received and received-wire metric
 // instruments for an exporter or receiver using the corresponding
-// `prefix`.  `major` indicates the major direction of the pipeline,
-// which is true when sending for exporters, receiving for receivers.
-func makeRecvMetrics(prefix string, meter metric.Meter, major bool) (recv, recvWire metric.Int64Counter, _ error) {
-	var recvBytes metric.Int64Counter = noopmetric.Int64Counter{}
-	var err1 error
-	if major {
-		recvBytes, err1 = meter.Int64Counter(prefix+"_"+RecvBytes, metric.WithDescription(recvDescription), metric.WithUnit(bytesUnit))
-	}
+// `prefix`.
+func makeRecvMetrics(prefix string, meter metric.Meter) (recv, recvWire metric.Int64Counter, _ error) {
+	recvBytes, err1 := meter.Int64Counter(prefix+"_"+RecvBytes, metric.WithDescription(recvDescription), metric.WithUnit(bytesUnit))
 	recvWireBytes, err2 := meter.Int64Counter(prefix+"_"+RecvWireBytes, metric.WithDescription(recvWireDescription), metric.WithUnit(bytesUnit))
 	return recvBytes, recvWireBytes, multierr.Append(err1, err2)
 }
 
+// makeSentHistograms builds the optional sent and sent-wire size
+// histograms.
+func makeSentHistograms(prefix string, meter metric.Meter) (sent, sentWire metric.Int64Histogram, _ error) {
+	sentSize, err1 := meter.Int64Histogram(prefix+"_"+SentSize, metric.WithDescription(sentSizeDescription), metric.WithUnit(bytesUnit))
+	sentWireSize, err2 := meter.Int64Histogram(prefix+"_"+SentWireSize, metric.WithDescription(sentWireSizeDescription), metric.WithUnit(bytesUnit))
+	return sentSize, sentWireSize, multierr.Append(err1, err2)
+}
+
+// makeRecvHistograms builds the optional received and received-wire
+// size histograms.
+func makeRecvHistograms(prefix string, meter metric.Meter) (recv, recvWire metric.Int64Histogram, _ error) {
+	recvSize, err1 := meter.Int64Histogram(prefix+"_"+RecvSize, metric.WithDescription(recvSizeDescription), metric.WithUnit(bytesUnit))
+	recvWireSize, err2 := meter.Int64Histogram(prefix+"_"+RecvWireSize, metric.WithDescription(recvWireSizeDescription), metric.WithUnit(bytesUnit))
+	return recvSize, recvWireSize, multierr.Append(err1, err2)
+}
+
 // NewExporterNetworkReporter creates a new NetworkReporter configured for an exporter.
-func NewExporterNetworkReporter(settings exporter.CreateSettings) (*NetworkReporter, error) {
+func NewExporterNetworkReporter(settings exporter.CreateSettings, opts ...Option) (*NetworkReporter, error) {
 	level := settings.TelemetrySettings.MetricsLevel
+	o := makeOptions(opts)
+
+	plugins, err := makePlugins(ReporterSettings{
+		ID:                settings.ID,
+		Kind:              component.KindExporter,
+		TelemetrySettings: settings.TelemetrySettings,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	if level <= configtelemetry.LevelBasic {
-		// Note: NetworkReporter implements nil a check.
-		return nil, nil
+	if level == configtelemetry.LevelNone {
+		if len(plugins) == 0 {
+			// Note: NetworkReporter implements nil a check.
+			return nil, nil
+		}
+		// Only the registered reporters are used.
+		return &NetworkReporter{
+			isExporter:  true,
+			pluginsOnly: true,
+			plugins:     plugins,
+		}, nil
 	}
 
 	meter := settings.TelemetrySettings.MeterProvider.Meter(scopeName)
 	rep := &NetworkReporter{
 		isExporter:    true,
+		level:         level,
 		staticAttr:    attribute.String(ExporterKey, settings.ID.String()),
 		compSizeHisto: noopmetric.Int64Histogram{},
+		plugins:       plugins,
+		spanSizes:     o.spanSizes,
 	}
 
-	var errors, err error
+	var errors error
 	if level > configtelemetry.LevelNormal {
 		rep.compSizeHisto, err = meter.Int64Histogram(ExporterKey+"_"+CompSize, metric.WithDescription(compSizeDescription), metric.WithUnit(bytesUnit))
 		errors = multierr.Append(errors, err)
 	}
 
-	rep.sentBytes, rep.sentWireBytes, err = makeSentMetrics(ExporterKey, meter, true)
+	rep.sentBytes, rep.sentWireBytes, err = makeSentMetrics(ExporterKey, meter)
 	errors = multierr.Append(errors, err)
 
+	if o.sizeHistograms(level) {
+		rep.sentSizeHisto, rep.sentWireSizeHisto, err = makeSentHistograms(ExporterKey, meter)
+		errors = multierr.Append(errors, err)
+	}
+
 	// Normally, an exporter counts sent bytes, and skips received
-	// bytes.  LevelDetailed will reveal exporter-received bytes.
+	// bytes.  LevelDetailed will reveal exporter-received bytes,
+	// which for Arrow streams are the batch acknowledgements.
 	if level > configtelemetry.LevelNormal {
-		rep.recvBytes, rep.recvWireBytes, err = makeRecvMetrics(ExporterKey, meter, false)
+		rep.recvBytes, rep.recvWireBytes, err = makeRecvMetrics(ExporterKey, meter)
 		errors = multierr.Append(errors, err)
+
+		if o.sizeHistograms(level) {
+			rep.recvSizeHisto, rep.recvWireSizeHisto, err = makeRecvHistograms(ExporterKey, meter)
+			errors = multierr.Append(errors, err)
+		}
 	}
 
 	return rep, errors
 }
 
 // NewReceiverNetworkReporter creates a new NetworkReporter configured for an exporter.
-func NewReceiverNetworkReporter(settings receiver.CreateSettings) (*NetworkReporter, error) {
+func NewReceiverNetworkReporter(settings receiver.CreateSettings, opts ...Option) (*NetworkReporter, error) {
 	level := settings.TelemetrySettings.MetricsLevel
+	o := makeOptions(opts)
+
+	plugins, err := makePlugins(ReporterSettings{
+		ID:                settings.ID,
+		Kind:              component.KindReceiver,
+		TelemetrySettings: settings.TelemetrySettings,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	if level <= configtelemetry.LevelBasic {
-		// Note: NetworkReporter implements nil a check.
-		return nil, nil
+	if level == configtelemetry.LevelNone {
+		if len(plugins) == 0 {
+			// Note: NetworkReporter implements nil a check.
+			return nil, nil
+		}
+		// Only the registered reporters are used.
+		return &NetworkReporter{
+			isExporter:  false,
+			pluginsOnly: true,
+			plugins:     plugins,
+		}, nil
 	}
 
 	meter := settings.MeterProvider.Meter(scopeName)
 	rep := &NetworkReporter{
 		isExporter:    false,
+		level:         level,
 		staticAttr:    attribute.String(ReceiverKey, settings.ID.String()),
 		compSizeHisto: noopmetric.Int64Histogram{},
+		plugins:       plugins,
+		spanSizes:     o.spanSizes,
 	}
 
-	var errors, err error
+	var errors error
 	if level > configtelemetry.LevelNormal {
 		rep.compSizeHisto, err = meter.Int64Histogram(ReceiverKey+"_"+CompSize, metric.WithDescription(compSizeDescription), metric.WithUnit(bytesUnit))
 		errors = multierr.Append(errors, err)
 	}
 
-	rep.recvBytes, rep.recvWireBytes, err = makeRecvMetrics(ReceiverKey, meter, true)
+	rep.recvBytes, rep.recvWireBytes, err = makeRecvMetrics(ReceiverKey, meter)
 	errors = multierr.Append(errors, err)
 
+	if o.sizeHistograms(level) {
+		rep.recvSizeHisto, rep.recvWireSizeHisto, err = makeRecvHistograms(ReceiverKey, meter)
+		errors = multierr.Append(errors, err)
+	}
+
 	// Normally, a receiver counts received bytes, and skips sent
-	// bytes.  LevelDetailed will reveal receiver-sent bytes.
+	// bytes.  LevelDetailed will reveal receiver-sent bytes,
+	// which for Arrow streams are the batch acknowledgements.
 	if level > configtelemetry.LevelNormal {
-		rep.sentBytes, rep.sentWireBytes, err = makeSentMetrics(ReceiverKey, meter, false)
+		rep.sentBytes, rep.sentWireBytes, err = makeSentMetrics(ReceiverKey, meter)
 		errors = multierr.Append(errors, err)
+
+		if o.sizeHistograms(level) {
+			rep.sentSizeHisto, rep.sentWireSizeHisto, err = makeSentHistograms(ReceiverKey, meter)
+			errors = multierr.Append(errors, err)
+		}
 	}
 
 	return rep, errors
 }
 
+// attributes returns the measurement attributes for a message: the
+// component, the method above the basic level of telemetry, and the
+// stream identifier at the detailed level.
+func (rep *NetworkReporter) attributes(ss SizesStruct) metric.MeasurementOption {
+	switch {
+	case rep.level <= configtelemetry.LevelBasic:
+		return metric.WithAttributes(rep.staticAttr)
+	case rep.level > configtelemetry.LevelNormal && ss.Stream != "":
+		return metric.WithAttributes(rep.staticAttr, attribute.String("method", ss.Method), attribute.String("stream", ss.Stream))
+	}
+	return metric.WithAttributes(rep.staticAttr, attribute.String("method", ss.Method))
+}
+
 // CountSend is used to report a message sent by the component.  For
 // exporters, SizesStruct indicates the size of a request.  For
 // receivers, SizesStruct indicates the size of a response.
 func (rep *NetworkReporter) CountSend(ctx context.Context, ss SizesStruct) {
-	// Indicates basic level telemetry, not counting bytes.
+	// Indicates no telemetry, not counting bytes.
 	if rep == nil {
 		return
 	}
 
+	for _, plugin := range rep.plugins {
+		plugin.CountSend(ctx, ss)
+	}
+
+	// Indicates no telemetry but the registered reporters.
+	if rep.pluginsOnly {
+		return
+	}
+
 	span := trace.SpanFromContext(ctx)
-	attrs := metric.WithAttributes(rep.staticAttr, attribute.String("method", ss.Method))
+	attrs := rep.attributes(ss)
 
 	if ss.Length > 0 {
 		if rep.sentBytes != nil {
 			rep.sentBytes.Add(ctx, ss.Length, attrs)
 		}
-		if span.IsRecording() {
+		if rep.sentSizeHisto != nil {
+			rep.sentSizeHisto.Record(ctx, ss.Length, attrs)
+		}
+		if rep.spanSizes.uncompressed() && span.IsRecording() {
 			span.SetAttributes(attribute.Int64("sent_uncompressed", ss.Length))
 		}
 	}
@@ -231,7 +455,10 @@ func (rep *NetworkReporter) CountSend(ctx context.Context, ss SizesStruct) {
 		if rep.sentWireBytes != nil {
 			rep.sentWireBytes.Add(ctx, ss.WireLength, attrs)
 		}
-		if span.IsRecording() {
+		if rep.sentWireSizeHisto != nil {
+			rep.sentWireSizeHisto.Record(ctx, ss.WireLength, attrs)
+		}
+		if rep.spanSizes.compressed() && span.IsRecording() {
 			span.SetAttributes(attribute.Int64("sent_compressed", ss.WireLength))
 		}
 	}
@@ -241,19 +468,31 @@ func (rep *NetworkReporter) CountSend(ctx context.Context, ss SizesStruct) {
 // exporters, SizesStruct indicates the size of a response.  For
 // receivers, SizesStruct indicates the size of a request.
 func (rep *NetworkReporter) CountReceive(ctx context.Context, ss SizesStruct) {
-	// Indicates basic level telemetry, not counting bytes.
+	// Indicates no telemetry, not counting bytes.
 	if rep == nil {
 		return
 	}
 
+	for _, plugin := range rep.plugins {
+		plugin.CountReceive(ctx, ss)
+	}
+
+	// Indicates no telemetry but the registered reporters.
+	if rep.pluginsOnly {
+		return
+	}
+
 	span := trace.SpanFromContext(ctx)
-	attrs := metric.WithAttributes(rep.staticAttr, attribute.String("method", ss.Method))
+	attrs := rep.attributes(ss)
 
 	if ss.Length > 0 {
 		if rep.recvBytes != nil {
 			rep.recvBytes.Add(ctx, ss.Length, attrs)
 		}
-		if span.IsRecording() {
+		if rep.recvSizeHisto != nil {
+			rep.recvSizeHisto.Record(ctx, ss.Length, attrs)
+		}
+		if rep.spanSizes.uncompressed() && span.IsRecording() {
 			span.SetAttributes(attribute.Int64("received_uncompressed", ss.Length))
 		}
 	}
@@ -264,7 +503,10 @@ func (rep *NetworkReporter) CountReceive(ctx context.Context, ss SizesStruct) {
 		if rep.recvWireBytes != nil {
 			rep.recvWireBytes.Add(ctx, ss.WireLength, attrs)
 		}
-		if span.IsRecording() {
+		if rep.recvWireSizeHisto != nil {
+			rep.recvWireSizeHisto.Record(ctx, ss.WireLength, attrs)
+		}
+		if rep.spanSizes.compressed() && span.IsRecording() {
 			span.SetAttributes(attribute.Int64("received_compressed", ss.WireLength))
 		}
 	}
@@ -13,6 +13,7 @@ import (
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/stats"
 
 	"go.opentelemetry.io/collector/component"
@@ -57,6 +58,13 @@ func TestNetStatsExporterNone(t *testing.T) {
 	testNetStatsExporter(t, configtelemetry.LevelNone, map[string]interface{}{})
 }
 
+func TestNetStatsExporterBasic(t *testing.T) {
+	testNetStatsExporter(t, configtelemetry.LevelBasic, map[string]interface{}{
+		"exporter_sent":      int64(1000),
+		"exporter_sent_wire": int64(100),
+	})
+}
+
 func TestNetStatsExporterNormal(t *testing.T) {
 	testNetStatsExporter(t, configtelemetry.LevelNormal, map[string]interface{}{
 		"exporter_sent":      int64(1000),
@@ -68,12 +76,34 @@ func TestNetStatsExporterDetailed(t *testing.T) {
 	testNetStatsExporter(t, configtelemetry.LevelDetailed, map[string]interface{}{
 		"exporter_sent":            int64(1000),
 		"exporter_sent_wire":       int64(100),
+		"exporter_recv":            int64(100),
 		"exporter_recv_wire":       int64(10),
 		"exporter_compressed_size": int64(100), // same as sent_wire b/c sum metricValue uses histogram sum
+		// the histograms are tested as the sum, equal to the counters.
+		"exporter_sent_size":      int64(1000),
+		"exporter_sent_wire_size": int64(100),
+		"exporter_recv_size":      int64(100),
+		"exporter_recv_wire_size": int64(10),
 	})
 }
 
-func testNetStatsExporter(t *testing.T, level configtelemetry.Level, expect map[string]interface{}) {
+func TestNetStatsExporterSizeHistograms(t *testing.T) {
+	testNetStatsExporter(t, configtelemetry.LevelNormal, map[string]interface{}{
+		"exporter_sent":      int64(1000),
+		"exporter_sent_wire": int64(100),
+		// the histograms are tested as the sum, equal to the counters.
+		"exporter_sent_size":      int64(1000),
+		"exporter_sent_wire_size": int64(100),
+	}, WithSizeHistograms())
+
+	// Basic telemetry has only the totals.
+	testNetStatsExporter(t, configtelemetry.LevelBasic, map[string]interface{}{
+		"exporter_sent":      int64(1000),
+		"exporter_sent_wire": int64(100),
+	}, WithSizeHistograms())
+}
+
+func testNetStatsExporter(t *testing.T, level configtelemetry.Level, expect map[string]interface{}, opts ...Option) {
 	for _, apiDirect := range []bool{true, false} {
 		t.Run(func() string {
 			if apiDirect {
@@ -92,7 +122,7 @@ func testNetStatsExporter(t *testing.T, level configtelemetry.Level, expect map[
 					MeterProvider: mp,
 					MetricsLevel:  level,
 				},
-			})
+			}, opts...)
 			require.NoError(t, err)
 			handler := enr.Handler()
 
@@ -130,7 +160,12 @@ func testNetStatsExporter(t *testing.T, level configtelemetry.Level, expect map[
 			err = rdr.Collect(ctx, &rm)
 			require.NoError(t, err)
 
-			require.Equal(t, expect, metricValues(t, rm, "Hello"))
+			// Basic telemetry omits the method.
+			expectMethod := "Hello"
+			if level == configtelemetry.LevelBasic {
+				expectMethod = ""
+			}
+			require.Equal(t, expect, metricValues(t, rm, expectMethod))
 		})
 	}
 }
@@ -140,6 +175,7 @@ func TestNetStatsSetSpanAttrs(t *testing.T) {
 		name       string
 		attrs      []attribute.KeyValue
 		isExporter bool
+		spanSizes  SpanSizeAttributes
 		length     int
 		wireLength int
 	}{
@@ -167,11 +203,30 @@ func TestNetStatsSetSpanAttrs(t *testing.T) {
 				attribute.Int("received_compressed", 890*2),
 			},
 		},
+		{
+			name:       "set compressed attributes",
+			isExporter: true,
+			spanSizes:  SpanSizeAttributesCompressed,
+			length:     1234567,
+			wireLength: 123,
+			attrs: []attribute.KeyValue{
+				attribute.Int("sent_compressed", 123),
+				attribute.Int("received_compressed", 123*2),
+			},
+		},
+		{
+			name:       "set no attributes",
+			isExporter: false,
+			spanSizes:  SpanSizeAttributesOff,
+			length:     8901234,
+			wireLength: 890,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			enr := &NetworkReporter{
 				isExporter: tc.isExporter,
+				spanSizes:  tc.spanSizes,
 			}
 
 			tp := sdktrace.NewTracerProvider()
@@ -193,10 +248,24 @@ func TestNetStatsSetSpanAttrs(t *testing.T) {
 	}
 }
 
+func TestSpanSizeAttributesValidate(t *testing.T) {
+	for _, s := range []SpanSizeAttributes{"", SpanSizeAttributesOff, SpanSizeAttributesCompressed, SpanSizeAttributesBoth} {
+		require.NoError(t, s.Validate())
+	}
+	require.ErrorContains(t, SpanSizeAttributes("uncompressed").Validate(), `unknown span size attributes "uncompressed"`)
+}
+
 func TestNetStatsReceiverNone(t *testing.T) {
 	testNetStatsReceiver(t, configtelemetry.LevelNone, map[string]interface{}{})
 }
 
+func TestNetStatsReceiverBasic(t *testing.T) {
+	testNetStatsReceiver(t, configtelemetry.LevelBasic, map[string]interface{}{
+		"receiver_recv":      int64(1000),
+		"receiver_recv_wire": int64(100),
+	})
+}
+
 func TestNetStatsReceiverNormal(t *testing.T) {
 	testNetStatsReceiver(t, configtelemetry.LevelNormal, map[string]interface{}{
 		"receiver_recv":      int64(1000),
@@ -208,12 +277,27 @@ func TestNetStatsReceiverDetailed(t *testing.T) {
 	testNetStatsReceiver(t, configtelemetry.LevelDetailed, map[string]interface{}{
 		"receiver_recv":            int64(1000),
 		"receiver_recv_wire":       int64(100),
+		"receiver_sent":            int64(100),
 		"receiver_sent_wire":       int64(10),
 		"receiver_compressed_size": int64(100), // same as recv_wire b/c sum metricValue uses histogram sum
+		// the histograms are tested as the sum, equal to the counters.
+		"receiver_recv_size":      int64(1000),
+		"receiver_recv_wire_size": int64(100),
+		"receiver_sent_size":      int64(100),
+		"receiver_sent_wire_size": int64(10),
 	})
 }
 
-func testNetStatsReceiver(t *testing.T, level configtelemetry.Level, expect map[string]interface{}) {
+func TestNetStatsReceiverSizeHistograms(t *testing.T) {
+	testNetStatsReceiver(t, configtelemetry.LevelNormal, map[string]interface{}{
+		"receiver_recv":           int64(1000),
+		"receiver_recv_wire":      int64(100),
+		"receiver_recv_size":      int64(1000),
+		"receiver_recv_wire_size": int64(100),
+	}, WithSizeHistograms())
+}
+
+func testNetStatsReceiver(t *testing.T, level configtelemetry.Level, expect map[string]interface{}, opts ...Option) {
 	for _, apiDirect := range []bool{true, false} {
 		t.Run(func() string {
 			if apiDirect {
@@ -232,7 +316,7 @@ func testNetStatsReceiver(t *testing.T, level configtelemetry.Level, expect map[
 					MeterProvider: mp,
 					MetricsLevel:  level,
 				},
-			})
+			}, opts...)
 			require.NoError(t, err)
 			handler := rer.Handler()
 
@@ -270,7 +354,12 @@ func testNetStatsReceiver(t *testing.T, level configtelemetry.Level, expect map[
 			err = rdr.Collect(ctx, &rm)
 			require.NoError(t, err)
 
-			require.Equal(t, expect, metricValues(t, rm, "Hello"))
+			// Basic telemetry omits the method.
+			expectMethod := "Hello"
+			if level == configtelemetry.LevelBasic {
+				expectMethod = ""
+			}
+			require.Equal(t, expect, metricValues(t, rm, expectMethod))
 		})
 	}
 }
@@ -322,6 +411,104 @@ func TestUncompressedSizeBypass(t *testing.T) {
 		"exporter_sent_wire":       int64(100),
 		"exporter_recv_wire":       int64(10),
 		"exporter_compressed_size": int64(100),
+		"exporter_sent_size":       int64(1000),
+		"exporter_sent_wire_size":  int64(100),
+		"exporter_recv_wire_size":  int64(10),
 	}
 	require.Equal(t, expect, metricValues(t, rm, "my.arrow.v1.method"))
 }
+
+func TestSizeHistogramExemplars(t *testing.T) {
+	// Exemplars are an experimental feature of the SDK in this version.
+	t.Setenv("OTEL_GO_X_EXEMPLAR", "true")
+
+	rdr := metric.NewManualReader()
+	mp := metric.NewMeterProvider(
+		metric.WithResource(resource.Empty()),
+		metric.WithReader(rdr),
+	)
+	enr, err := NewExporterNetworkReporter(exporter.CreateSettings{
+		ID: component.NewID(component.MustNewType("test")),
+		TelemetrySettings: component.TelemetrySettings{
+			MeterProvider: mp,
+			MetricsLevel:  configtelemetry.LevelNormal,
+		},
+	}, WithSizeHistograms())
+	require.NoError(t, err)
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, sp := tp.Tracer("test/span").Start(context.Background(), "export")
+	defer sp.End()
+
+	enr.CountSend(ctx, SizesStruct{
+		Method:     "Hello",
+		Length:     100,
+		WireLength: 10,
+	})
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, rdr.Collect(ctx, &rm))
+
+	found := 0
+	for _, sm := range rm.ScopeMetrics {
+		for _, mm := range sm.Metrics {
+			histo, ok := mm.Data.(metricdata.Histogram[int64])
+			if !ok {
+				continue
+			}
+			for _, dp := range histo.DataPoints {
+				require.Len(t, dp.Exemplars, 1, "metric %s", mm.Name)
+				require.Equal(t, sp.SpanContext().TraceID(), trace.TraceID(dp.Exemplars[0].TraceID))
+				require.Equal(t, sp.SpanContext().SpanID(), trace.SpanID(dp.Exemplars[0].SpanID))
+				found++
+			}
+		}
+	}
+	require.Equal(t, 2, found)
+}
+
+func TestNetStatsStreamAttribute(t *testing.T) {
+	for _, level := range []configtelemetry.Level{configtelemetry.LevelBasic, configtelemetry.LevelNormal, configtelemetry.LevelDetailed} {
+		t.Run(level.String(), func(t *testing.T) {
+			rdr := metric.NewManualReader()
+			mp := metric.NewMeterProvider(
+				metric.WithResource(resource.Empty()),
+				metric.WithReader(rdr),
+			)
+			rer, err := NewReceiverNetworkReporter(receiver.CreateSettings{
+				ID: component.NewID(component.MustNewType("test")),
+				TelemetrySettings: component.TelemetrySettings{
+					MeterProvider: mp,
+					MetricsLevel:  level,
+				},
+			})
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			rer.CountReceive(ctx, SizesStruct{
+				Method: "Hello",
+				Stream: "7",
+				Length: 100,
+			})
+
+			var rm metricdata.ResourceMetrics
+			require.NoError(t, rdr.Collect(ctx, &rm))
+			require.Len(t, rm.ScopeMetrics, 1)
+			require.Equal(t, "receiver_recv", rm.ScopeMetrics[0].Metrics[0].Name)
+
+			dps := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64]).DataPoints
+			require.Len(t, dps, 1)
+
+			_, ok := dps[0].Attributes.Value("method")
+			require.Equal(t, level > configtelemetry.LevelBasic, ok)
+
+			stream, ok := dps[0].Attributes.Value("stream")
+			if level == configtelemetry.LevelDetailed {
+				require.True(t, ok)
+				require.Equal(t, "7", stream.AsString())
+			} else {
+				require.False(t, ok)
+			}
+		})
+	}
+}
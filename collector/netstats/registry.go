@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package netstats // import "github.com/open-telemetry/otel-arrow/collector/netstats"
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/multierr"
+)
+
+// ReporterSettings are passed to a ReporterFactory when an exporter
+// or receiver constructs its NetworkReporter.
+type ReporterSettings struct {
+	// ID is the exporter or receiver component ID.
+	ID component.ID
+
+	// Kind is component.KindExporter or component.KindReceiver.
+	Kind component.Kind
+
+	// TelemetrySettings are the component's telemetry settings.
+	component.TelemetrySettings
+}
+
+// ReporterFactory constructs an additional Interface that will
+// receive every size observation of a NetworkReporter, for example to
+// feed a billing pipeline.  A factory may return a nil Interface to
+// decline observing the component.
+type ReporterFactory func(ReporterSettings) (Interface, error)
+
+var registry = struct {
+	lock      sync.Mutex
+	factories map[string]ReporterFactory
+}{
+	factories: map[string]ReporterFactory{},
+}
+
+// RegisterReporter registers a named ReporterFactory.  Registration
+// is meant to happen during program initialization, before
+// components are created; components created earlier will not see
+// the new reporter.  It is an error to register the same name twice.
+func RegisterReporter(name string, factory ReporterFactory) error {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	if _, ok := registry.factories[name]; ok {
+		return fmt.Errorf("netstats reporter already registered: %q", name)
+	}
+	registry.factories[name] = factory
+	return nil
+}
+
+// makePlugins constructs the registered reporters for one component,
+// in order of registered name.
+func makePlugins(settings ReporterSettings) ([]Interface, error) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var plugins []Interface
+	var errors error
+	for _, name := range names {
+		plugin, err := registry.factories[name](settings)
+		if err != nil {
+			errors = multierr.Append(errors, fmt.Errorf("netstats reporter %q: %w", name, err))
+			continue
+		}
+		if plugin != nil {
+			plugins = append(plugins, plugin)
+		}
+	}
+	return plugins, errors
+}
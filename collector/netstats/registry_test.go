@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package netstats
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/stats"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/receiver"
+)
+
+type recordingReporter struct {
+	Noop
+
+	lock sync.Mutex
+	sent []SizesStruct
+	recv []SizesStruct
+}
+
+func (r *recordingReporter) CountSend(_ context.Context, ss SizesStruct) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.sent = append(r.sent, ss)
+}
+
+func (r *recordingReporter) CountReceive(_ context.Context, ss SizesStruct) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.recv = append(r.recv, ss)
+}
+
+// registerForTest registers a reporter factory and removes it at
+// the end of the test.
+func registerForTest(t *testing.T, name string, factory ReporterFactory) {
+	require.NoError(t, RegisterReporter(name, factory))
+	t.Cleanup(func() {
+		registry.lock.Lock()
+		defer registry.lock.Unlock()
+		delete(registry.factories, name)
+	})
+}
+
+func TestRegisterReporterDuplicate(t *testing.T) {
+	registerForTest(t, "dup", func(ReporterSettings) (Interface, error) { return nil, nil })
+	require.Error(t, RegisterReporter("dup", func(ReporterSettings) (Interface, error) { return nil, nil }))
+}
+
+func TestRegisteredReporters(t *testing.T) {
+	for _, level := range []configtelemetry.Level{configtelemetry.LevelBasic, configtelemetry.LevelDetailed} {
+		t.Run(level.String(), func(t *testing.T) {
+			exp := &recordingReporter{}
+			rcv := &recordingReporter{}
+			var settings []ReporterSettings
+
+			registerForTest(t, "test", func(set ReporterSettings) (Interface, error) {
+				settings = append(settings, set)
+				if set.Kind == component.KindExporter {
+					return exp, nil
+				}
+				return rcv, nil
+			})
+			registerForTest(t, "declines", func(ReporterSettings) (Interface, error) {
+				return nil, nil
+			})
+
+			tset := componenttest.NewNopTelemetrySettings()
+			tset.MetricsLevel = level
+			id := component.NewID(component.MustNewType("test"))
+
+			enr, err := NewExporterNetworkReporter(exporter.CreateSettings{
+				ID:                id,
+				TelemetrySettings: tset,
+			})
+			require.NoError(t, err)
+			require.NotNil(t, enr)
+
+			rer, err := NewReceiverNetworkReporter(receiver.CreateSettings{
+				ID:                id,
+				TelemetrySettings: tset,
+			})
+			require.NoError(t, err)
+			require.NotNil(t, rer)
+
+			require.Len(t, settings, 2)
+			require.Equal(t, id, settings[0].ID)
+			require.Equal(t, component.KindExporter, settings[0].Kind)
+			require.Equal(t, id, settings[1].ID)
+			require.Equal(t, component.KindReceiver, settings[1].Kind)
+
+			ctx := context.Background()
+			ss := SizesStruct{
+				Method:     "Hello",
+				Length:     100,
+				WireLength: 10,
+			}
+			enr.CountSend(ctx, ss)
+			rer.CountReceive(ctx, ss)
+
+			// The gRPC stats handler reaches the plugins, too.
+			handler := rer.Handler()
+			handler.HandleRPC(handler.TagRPC(ctx, &stats.RPCTagInfo{
+				FullMethodName: "Hello",
+			}), &stats.OutPayload{
+				Length:     10,
+				WireLength: 1,
+			})
+
+			require.Equal(t, []SizesStruct{ss}, exp.sent)
+			require.Nil(t, exp.recv)
+			require.Equal(t, []SizesStruct{ss}, rcv.recv)
+			require.Equal(t, []SizesStruct{{Method: "Hello", Length: 10, WireLength: 1}}, rcv.sent)
+		})
+	}
+}
+
+func TestRegisteredReporterError(t *testing.T) {
+	registerForTest(t, "fails", func(ReporterSettings) (Interface, error) {
+		return nil, fmt.Errorf("not today")
+	})
+
+	_, err := NewExporterNetworkReporter(exporter.CreateSettings{
+		ID:                component.NewID(component.MustNewType("test")),
+		TelemetrySettings: componenttest.NewNopTelemetrySettings(),
+	})
+	require.ErrorContains(t, err, "not today")
+}
@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package passthrough // import "github.com/open-telemetry/otel-arrow/collector/passthrough"
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/grpc"
+)
+
+// The OTLP gRPC export methods.
+const (
+	tracesExportMethod  = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+	metricsExportMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+	logsExportMethod    = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+)
+
+// Forwarder consumes traces, metrics, and logs by exporting them to an
+// OTLP gRPC endpoint.  Marshaled requests are sent as they are, and
+// pdata is marshaled first.  The responses of the endpoint, including
+// partial successes, are not interpreted; only errors are returned.
+type Forwarder struct {
+	conn grpc.ClientConnInterface
+	opts []grpc.CallOption
+}
+
+var (
+	_ Traces  = (*Forwarder)(nil)
+	_ Metrics = (*Forwarder)(nil)
+	_ Logs    = (*Forwarder)(nil)
+)
+
+// NewForwarder returns a Forwarder exporting over conn, with the call
+// options opts.
+func NewForwarder(conn grpc.ClientConnInterface, opts ...grpc.CallOption) *Forwarder {
+	return &Forwarder{
+		conn: conn,
+		opts: append([]grpc.CallOption{grpc.ForceCodec(rawCodec{})}, opts...),
+	}
+}
+
+// Capabilities implements consumer.Traces, consumer.Metrics, and
+// consumer.Logs.
+func (f *Forwarder) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+// ConsumeTraces implements consumer.Traces.
+func (f *Forwarder) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	request, err := ptraceotlp.NewExportRequestFromTraces(td).MarshalProto()
+	if err != nil {
+		return consumererror.NewPermanent(err)
+	}
+	return f.ConsumeTracesRequest(ctx, request)
+}
+
+// ConsumeTracesRequest implements Traces.
+func (f *Forwarder) ConsumeTracesRequest(ctx context.Context, request []byte) error {
+	return f.export(ctx, tracesExportMethod, request)
+}
+
+// ConsumeMetrics implements consumer.Metrics.
+func (f *Forwarder) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	request, err := pmetricotlp.NewExportRequestFromMetrics(md).MarshalProto()
+	if err != nil {
+		return consumererror.NewPermanent(err)
+	}
+	return f.ConsumeMetricsRequest(ctx, request)
+}
+
+// ConsumeMetricsRequest implements Metrics.
+func (f *Forwarder) ConsumeMetricsRequest(ctx context.Context, request []byte) error {
+	return f.export(ctx, metricsExportMethod, request)
+}
+
+// ConsumeLogs implements consumer.Logs.
+func (f *Forwarder) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	request, err := plogotlp.NewExportRequestFromLogs(ld).MarshalProto()
+	if err != nil {
+		return consumererror.NewPermanent(err)
+	}
+	return f.ConsumeLogsRequest(ctx, request)
+}
+
+// ConsumeLogsRequest implements Logs.
+func (f *Forwarder) ConsumeLogsRequest(ctx context.Context, request []byte) error {
+	return f.export(ctx, logsExportMethod, request)
+}
+
+func (f *Forwarder) export(ctx context.Context, method string, request []byte) error {
+	var response rawMessage
+	return f.conn.Invoke(ctx, method, rawMessage(request), &response, f.opts...)
+}
+
+// rawMessage is a marshaled protobuf message.
+type rawMessage []byte
+
+// rawCodec passes marshaled messages through gRPC as they are.  It is
+// named like the default codec, whose content type OTLP servers
+// expect.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	switch msg := v.(type) {
+	case rawMessage:
+		return msg, nil
+	case *rawMessage:
+		return *msg, nil
+	}
+	return nil, fmt.Errorf("passthrough: cannot marshal %T", v)
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(*rawMessage)
+	if !ok {
+		return fmt.Errorf("passthrough: cannot unmarshal into %T", v)
+	}
+	*msg = append((*msg)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package passthrough
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+)
+
+// testServer is an OTLP server recording the requests it receives.
+type testServer struct {
+	err     error
+	traces  []ptrace.Traces
+	metrics []pmetric.Metrics
+	logs    []plog.Logs
+}
+
+type traceServer struct {
+	ptraceotlp.UnimplementedGRPCServer
+	*testServer
+}
+
+func (s *traceServer) Export(_ context.Context, req ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error) {
+	s.traces = append(s.traces, req.Traces())
+	return ptraceotlp.NewExportResponse(), s.err
+}
+
+type metricsServer struct {
+	pmetricotlp.UnimplementedGRPCServer
+	*testServer
+}
+
+func (s *metricsServer) Export(_ context.Context, req pmetricotlp.ExportRequest) (pmetricotlp.ExportResponse, error) {
+	s.metrics = append(s.metrics, req.Metrics())
+	return pmetricotlp.NewExportResponse(), s.err
+}
+
+type logsServer struct {
+	plogotlp.UnimplementedGRPCServer
+	*testServer
+}
+
+func (s *logsServer) Export(_ context.Context, req plogotlp.ExportRequest) (plogotlp.ExportResponse, error) {
+	s.logs = append(s.logs, req.Logs())
+	return plogotlp.NewExportResponse(), s.err
+}
+
+func newTestForwarder(t *testing.T, srv *testServer) *Forwarder {
+	ln, err := net.Listen("tcp", "localhost:")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	ptraceotlp.RegisterGRPCServer(server, &traceServer{testServer: srv})
+	pmetricotlp.RegisterGRPCServer(server, &metricsServer{testServer: srv})
+	plogotlp.RegisterGRPCServer(server, &logsServer{testServer: srv})
+	go func() {
+		_ = server.Serve(ln)
+	}()
+	t.Cleanup(server.Stop)
+
+	cc, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, cc.Close())
+	})
+	return NewForwarder(cc)
+}
+
+func TestForwarderRequests(t *testing.T) {
+	srv := &testServer{}
+	fwd := newTestForwarder(t, srv)
+	ctx := context.Background()
+
+	td := testdata.GenerateTraces(2)
+	request, err := ptraceotlp.NewExportRequestFromTraces(td).MarshalProto()
+	require.NoError(t, err)
+	require.NoError(t, fwd.ConsumeTracesRequest(ctx, request))
+
+	md := testdata.GenerateMetrics(2)
+	request, err = pmetricotlp.NewExportRequestFromMetrics(md).MarshalProto()
+	require.NoError(t, err)
+	require.NoError(t, fwd.ConsumeMetricsRequest(ctx, request))
+
+	ld := testdata.GenerateLogs(2)
+	request, err = plogotlp.NewExportRequestFromLogs(ld).MarshalProto()
+	require.NoError(t, err)
+	require.NoError(t, fwd.ConsumeLogsRequest(ctx, request))
+
+	assert.Equal(t, []ptrace.Traces{td}, srv.traces)
+	assert.Equal(t, []pmetric.Metrics{md}, srv.metrics)
+	assert.Equal(t, []plog.Logs{ld}, srv.logs)
+}
+
+func TestForwarderPdata(t *testing.T) {
+	srv := &testServer{}
+	fwd := newTestForwarder(t, srv)
+	ctx := context.Background()
+
+	td := testdata.GenerateTraces(2)
+	require.NoError(t, fwd.ConsumeTraces(ctx, td))
+	md := testdata.GenerateMetrics(2)
+	require.NoError(t, fwd.ConsumeMetrics(ctx, md))
+	ld := testdata.GenerateLogs(2)
+	require.NoError(t, fwd.ConsumeLogs(ctx, ld))
+
+	assert.Equal(t, []ptrace.Traces{td}, srv.traces)
+	assert.Equal(t, []pmetric.Metrics{md}, srv.metrics)
+	assert.Equal(t, []plog.Logs{ld}, srv.logs)
+	assert.False(t, fwd.Capabilities().MutatesData)
+}
+
+func TestForwarderError(t *testing.T) {
+	srv := &testServer{
+		err: status.Error(codes.ResourceExhausted, "too much"),
+	}
+	fwd := newTestForwarder(t, srv)
+
+	err := fwd.ConsumeLogs(context.Background(), testdata.GenerateLogs(1))
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,49 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package passthrough defines the interfaces of consumers that accept
+// marshaled OTLP export requests, so that a receiver forwarding data
+// it does not modify can skip constructing or marshaling pdata.  These
+// are meant for exporters that forward the requests as they are, such
+// as the Forwarder, wired directly to a receiver; consumers of a
+// collector pipeline are wrapped, and do not implement them.
+package passthrough // import "github.com/open-telemetry/otel-arrow/collector/passthrough"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/consumer"
+)
+
+// Traces is a consumer.Traces that also consumes marshaled OTLP
+// ExportTraceServiceRequest messages.
+type Traces interface {
+	consumer.Traces
+
+	// ConsumeTracesRequest consumes a marshaled OTLP
+	// ExportTraceServiceRequest.  The request must not be
+	// modified or retained after the call returns.
+	ConsumeTracesRequest(ctx context.Context, request []byte) error
+}
+
+// Metrics is a consumer.Metrics that also consumes marshaled OTLP
+// ExportMetricsServiceRequest messages.
+type Metrics interface {
+	consumer.Metrics
+
+	// ConsumeMetricsRequest consumes a marshaled OTLP
+	// ExportMetricsServiceRequest.  The request must not be
+	// modified or retained after the call returns.
+	ConsumeMetricsRequest(ctx context.Context, request []byte) error
+}
+
+// Logs is a consumer.Logs that also consumes marshaled OTLP
+// ExportLogsServiceRequest messages.
+type Logs interface {
+	consumer.Logs
+
+	// ConsumeLogsRequest consumes a marshaled OTLP
+	// ExportLogsServiceRequest.  The request must not be
+	// modified or retained after the call returns.
+	ConsumeLogsRequest(ctx context.Context, request []byte) error
+}
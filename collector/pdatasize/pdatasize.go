@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pdatasize computes the OTLP-encoded size of pdata payloads,
+// letting a component that already measured a payload (e.g., a batch
+// processor) pass the size to the components it calls (e.g., an
+// exporter) through the context, so that each payload is measured
+// once.  In the other direction, an exporter that compresses the
+// payload reports its compressed size to the caller through the
+// context.
+package pdatasize // import "github.com/open-telemetry/otel-arrow/collector/pdatasize"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+type sizeKey struct{}
+
+// cachedSize is the size of one payload.  The payload is compared
+// by identity, and its item count guards against the common case of
+// a payload modified in place after it was measured.
+type cachedSize struct {
+	data  any
+	items int
+	size  int
+}
+
+// ContextWithSize returns a context that carries the size of the
+// payload, which must be a ptrace.Traces, plog.Logs, or
+// pmetric.Metrics.  Components calling a consumer with a payload
+// they have already measured use this to save the consumer from
+// measuring it again.
+func ContextWithSize(ctx context.Context, data any, size int) context.Context {
+	items, ok := itemCount(data)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, sizeKey{}, cachedSize{
+		data:  data,
+		items: items,
+		size:  size,
+	})
+}
+
+// FromContext returns the size of the payload recorded by
+// ContextWithSize, if the context carries the size of this payload.
+func FromContext(ctx context.Context, data any) (int, bool) {
+	cs, ok := ctx.Value(sizeKey{}).(cachedSize)
+	if !ok || !samePayload(cs.data, data) {
+		return 0, false
+	}
+	if items, _ := itemCount(data); items != cs.items {
+		return 0, false
+	}
+	return cs.size, true
+}
+
+// Size returns the OTLP-encoded size of the payload, which is
+// taken from the context when possible and computed otherwise.
+// Payloads of other types have size zero.
+func Size(ctx context.Context, data any) int {
+	if size, ok := FromContext(ctx, data); ok {
+		return size
+	}
+	switch data := data.(type) {
+	case ptrace.Traces:
+		var sizer ptrace.ProtoMarshaler
+		return sizer.TracesSize(data)
+	case plog.Logs:
+		var sizer plog.ProtoMarshaler
+		return sizer.LogsSize(data)
+	case pmetric.Metrics:
+		var sizer pmetric.ProtoMarshaler
+		return sizer.MetricsSize(data)
+	}
+	return 0
+}
+
+type compressionKey struct{}
+
+// CompressionReporter receives the size of a payload before and after
+// an exporter encoded and compressed it.
+type CompressionReporter func(uncompressed, compressed int)
+
+// ContextWithCompressionReporter returns a context that carries
+// report, which exporters call through ReportCompression.  A batch
+// processor uses this to learn how well its batches compress.
+func ContextWithCompressionReporter(ctx context.Context, report CompressionReporter) context.Context {
+	return context.WithValue(ctx, compressionKey{}, report)
+}
+
+// ReportCompression calls the reporter carried by the context, if
+// any, with the size of a payload before and after compression.
+func ReportCompression(ctx context.Context, uncompressed, compressed int) {
+	if report, ok := ctx.Value(compressionKey{}).(CompressionReporter); ok {
+		report(uncompressed, compressed)
+	}
+}
+
+func itemCount(data any) (int, bool) {
+	switch data := data.(type) {
+	case ptrace.Traces:
+		return data.SpanCount(), true
+	case plog.Logs:
+		return data.LogRecordCount(), true
+	case pmetric.Metrics:
+		return data.DataPointCount(), true
+	}
+	return 0, false
+}
+
+// samePayload compares the payloads by identity, checking types
+// first so that the comparison cannot panic.
+func samePayload(a, b any) bool {
+	switch a := a.(type) {
+	case ptrace.Traces:
+		b, ok := b.(ptrace.Traces)
+		return ok && a == b
+	case plog.Logs:
+		b, ok := b.(plog.Logs)
+		return ok && a == b
+	case pmetric.Metrics:
+		b, ok := b.(pmetric.Metrics)
+		return ok && a == b
+	}
+	return false
+}
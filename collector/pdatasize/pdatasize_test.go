@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package pdatasize
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func testTraces(n int) ptrace.Traces {
+	td := ptrace.NewTraces()
+	ss := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty()
+	for i := 0; i < n; i++ {
+		ss.Spans().AppendEmpty().SetName("span")
+	}
+	return td
+}
+
+func TestSizeComputed(t *testing.T) {
+	ctx := context.Background()
+
+	td := testTraces(3)
+	var tsizer ptrace.ProtoMarshaler
+	require.Equal(t, tsizer.TracesSize(td), Size(ctx, td))
+
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("log")
+	var lsizer plog.ProtoMarshaler
+	require.Equal(t, lsizer.LogsSize(ld), Size(ctx, ld))
+
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+	var msizer pmetric.ProtoMarshaler
+	require.Equal(t, msizer.MetricsSize(md), Size(ctx, md))
+
+	require.Equal(t, 0, Size(ctx, "unknown"))
+}
+
+func TestSizeFromContext(t *testing.T) {
+	td := testTraces(3)
+	ctx := ContextWithSize(context.Background(), td, 12345)
+
+	size, ok := FromContext(ctx, td)
+	require.True(t, ok)
+	require.Equal(t, 12345, size)
+	require.Equal(t, 12345, Size(ctx, td))
+
+	// A different payload, even with equal contents, is measured.
+	other := testTraces(3)
+	_, ok = FromContext(ctx, other)
+	require.False(t, ok)
+	var sizer ptrace.ProtoMarshaler
+	require.Equal(t, sizer.TracesSize(other), Size(ctx, other))
+
+	// A payload of another type is measured.
+	_, ok = FromContext(ctx, plog.NewLogs())
+	require.False(t, ok)
+
+	// A payload modified in place is measured.
+	td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().AppendEmpty()
+	_, ok = FromContext(ctx, td)
+	require.False(t, ok)
+	require.Equal(t, sizer.TracesSize(td), Size(ctx, td))
+}
+
+func TestContextWithSizeUnknownType(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, ctx, ContextWithSize(ctx, "unknown", 10))
+	_, ok := FromContext(ctx, "unknown")
+	require.False(t, ok)
+}
+
+func TestReportCompression(t *testing.T) {
+	// Without a reporter, nothing happens.
+	ReportCompression(context.Background(), 100, 10)
+
+	var reported [][2]int
+	ctx := ContextWithCompressionReporter(context.Background(), func(uncompressed, compressed int) {
+		reported = append(reported, [2]int{uncompressed, compressed})
+	})
+	ctx = ContextWithSize(ctx, testTraces(1), 100)
+	ReportCompression(ctx, 100, 10)
+	ReportCompression(ctx, 200, 40)
+	require.Equal(t, [][2]int{{100, 10}, {200, 40}}, reported)
+}
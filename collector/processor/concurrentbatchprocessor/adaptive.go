@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package concurrentbatchprocessor // import "github.com/open-telemetry/otel-arrow/collector/processor/concurrentbatchprocessor"
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// adaptiveWindow is the number of exports observed between
+	// two adjustments of the send batch size.
+	adaptiveWindow = 8
+
+	// adaptiveStep is the fraction, as a divisor, by which the send
+	// batch size changes in one adjustment.
+	adaptiveStep = 4
+
+	// adaptiveMinGain is the relative improvement of the
+	// compression ratio that justifies larger batches.
+	adaptiveMinGain = 0.01
+
+	// adaptiveHoldWindows is the number of windows the size holds
+	// after growing did not improve the compression ratio, before
+	// trying again.
+	adaptiveHoldWindows = 4
+)
+
+// adaptiveSize controls the send batch size of a batch processor,
+// shared by its shards.  Every adaptiveWindow exports, it shrinks
+// the size if their mean latency exceeds the target and grows it
+// otherwise, unless the previous growth did not improve the
+// compression ratio reported by the exporter, in which case the
+// growth is undone.
+type adaptiveSize struct {
+	minSize       int
+	maxSize       int
+	targetLatency time.Duration
+
+	// size is read by the shards without locking.
+	size atomic.Int64
+
+	lock sync.Mutex
+
+	// count, latency, uncompressed and compressed sum the exports
+	// observed in the current window.
+	count        int
+	latency      time.Duration
+	uncompressed int64
+	compressed   int64
+
+	// prevSize and prevRatio are the size and compression ratio of
+	// the previous window, grew is set when the size grew since.
+	prevSize  int
+	prevRatio float64
+	grew      bool
+
+	// hold counts the windows left before growing again.
+	hold int
+}
+
+func newAdaptiveSize(cfg *Config) *adaptiveSize {
+	a := &adaptiveSize{
+		minSize:       int(cfg.Adaptive.MinSendBatchSize),
+		maxSize:       int(cfg.Adaptive.MaxSendBatchSize),
+		targetLatency: cfg.Adaptive.TargetLatency,
+	}
+	a.size.Store(int64(cfg.SendBatchSize))
+	return a
+}
+
+// sendBatchSize returns the current send batch size.
+func (a *adaptiveSize) sendBatchSize() int {
+	return int(a.size.Load())
+}
+
+// observe records one successful export.  The sizes are zero when
+// the exporter did not report the compression of the batch.
+func (a *adaptiveSize) observe(latency time.Duration, uncompressed, compressed int) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.count++
+	a.latency += latency
+	if compressed > 0 {
+		a.uncompressed += int64(uncompressed)
+		a.compressed += int64(compressed)
+	}
+	if a.count < adaptiveWindow {
+		return
+	}
+
+	var ratio float64
+	if a.compressed > 0 {
+		ratio = float64(a.uncompressed) / float64(a.compressed)
+	}
+	size := a.sendBatchSize()
+	next := size
+
+	switch {
+	case a.latency/time.Duration(a.count) > a.targetLatency:
+		next -= size / adaptiveStep
+		a.hold = 0
+	case a.grew && ratio != 0 && a.prevRatio != 0 && ratio < a.prevRatio*(1+adaptiveMinGain):
+		// Larger batches only add latency.
+		next = a.prevSize
+		a.hold = adaptiveHoldWindows
+	case a.hold > 0:
+		a.hold--
+	default:
+		next += max(1, size/adaptiveStep)
+	}
+	next = min(max(next, a.minSize), a.maxSize)
+
+	a.grew = next > size
+	a.prevSize = size
+	a.prevRatio = ratio
+	a.size.Store(int64(next))
+
+	a.count = 0
+	a.latency = 0
+	a.uncompressed = 0
+	a.compressed = 0
+}
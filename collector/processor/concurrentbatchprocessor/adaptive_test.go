@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package concurrentbatchprocessor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processortest"
+
+	"github.com/open-telemetry/otel-arrow/collector/pdatasize"
+)
+
+func testAdaptiveConfig() *Config {
+	return &Config{
+		SendBatchSize:      100,
+		MaxInFlightSizeMiB: 1,
+		Adaptive: AdaptiveConfig{
+			Enabled:          true,
+			MinSendBatchSize: 50,
+			MaxSendBatchSize: 200,
+			TargetLatency:    time.Second,
+		},
+	}
+}
+
+// observeWindow observes one window of exports with the same latency
+// and compression.
+func observeWindow(a *adaptiveSize, latency time.Duration, uncompressed, compressed int) int {
+	for i := 0; i < adaptiveWindow; i++ {
+		a.observe(latency, uncompressed, compressed)
+	}
+	return a.sendBatchSize()
+}
+
+func TestAdaptiveSizeLatency(t *testing.T) {
+	a := newAdaptiveSize(testAdaptiveConfig())
+	require.Equal(t, 100, a.sendBatchSize())
+
+	// Sizes change once per window.
+	for i := 0; i < adaptiveWindow-1; i++ {
+		a.observe(time.Millisecond, 0, 0)
+	}
+	require.Equal(t, 100, a.sendBatchSize())
+	a.observe(time.Millisecond, 0, 0)
+	require.Equal(t, 125, a.sendBatchSize())
+
+	// Without compression feedback, fast exports grow the size up
+	// to the maximum.
+	require.Equal(t, 156, observeWindow(a, time.Millisecond, 0, 0))
+	require.Equal(t, 195, observeWindow(a, time.Millisecond, 0, 0))
+	require.Equal(t, 200, observeWindow(a, time.Millisecond, 0, 0))
+	require.Equal(t, 200, observeWindow(a, time.Millisecond, 0, 0))
+
+	// Slow exports shrink it down to the minimum.
+	require.Equal(t, 150, observeWindow(a, 2*time.Second, 0, 0))
+	require.Equal(t, 113, observeWindow(a, 2*time.Second, 0, 0))
+	require.Equal(t, 85, observeWindow(a, 2*time.Second, 0, 0))
+	require.Equal(t, 64, observeWindow(a, 2*time.Second, 0, 0))
+	require.Equal(t, 50, observeWindow(a, 2*time.Second, 0, 0))
+	require.Equal(t, 50, observeWindow(a, 2*time.Second, 0, 0))
+}
+
+func TestAdaptiveSizeCompression(t *testing.T) {
+	a := newAdaptiveSize(testAdaptiveConfig())
+
+	// Growing while the compression ratio improves.
+	require.Equal(t, 125, observeWindow(a, time.Millisecond, 1000, 200))
+	require.Equal(t, 156, observeWindow(a, time.Millisecond, 1000, 150))
+
+	// The last growth did not improve the ratio: it is undone and
+	// the size holds for a while.
+	require.Equal(t, 125, observeWindow(a, time.Millisecond, 1000, 150))
+	for i := 0; i < adaptiveHoldWindows; i++ {
+		require.Equal(t, 125, observeWindow(a, time.Millisecond, 1000, 150))
+	}
+	require.Equal(t, 156, observeWindow(a, time.Millisecond, 1000, 150))
+
+	// Slow exports shrink the size regardless of compression.
+	require.Equal(t, 117, observeWindow(a, 2*time.Second, 1000, 100))
+}
+
+// reportingTraces is an exporter that reports compressing each batch
+// by ratio.
+type reportingTraces struct {
+	consumer.Traces
+	ratio   int
+	batches atomic.Int64
+}
+
+func (rt *reportingTraces) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	rt.batches.Add(1)
+	size := pdatasize.Size(ctx, td)
+	pdatasize.ReportCompression(ctx, size, size/rt.ratio)
+	return nil
+}
+
+func testSpans(n int) ptrace.Traces {
+	td := ptrace.NewTraces()
+	spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	for i := 0; i < n; i++ {
+		spans.AppendEmpty().SetName("span")
+	}
+	return td
+}
+
+func TestBatchProcessorAdaptiveSize(t *testing.T) {
+	cfg := testAdaptiveConfig()
+	cfg.Timeout = time.Hour
+	next := &reportingTraces{ratio: 5}
+	bp, err := newBatchTracesProcessor(processortest.NewNopCreateSettings(), next, cfg)
+	require.NoError(t, err)
+	require.Equal(t, 100, bp.currentSendBatchSize())
+
+	// Each batch of the send batch size is exported at once.  The
+	// exports are observed after they return to the producer.
+	for i := 0; i < adaptiveWindow; i++ {
+		require.NoError(t, bp.ConsumeTraces(context.Background(), testSpans(100)))
+	}
+	require.Eventually(t, func() bool {
+		return bp.currentSendBatchSize() == 125
+	}, time.Second, time.Millisecond)
+
+	// Larger batches compress as well, so the size goes back.
+	for i := 0; i < adaptiveWindow; i++ {
+		require.NoError(t, bp.ConsumeTraces(context.Background(), testSpans(125)))
+	}
+	require.Eventually(t, func() bool {
+		return bp.currentSendBatchSize() == 100
+	}, time.Second, time.Millisecond)
+
+	require.Equal(t, int64(2*adaptiveWindow), next.batches.Load())
+	require.NoError(t, bp.Shutdown(context.Background()))
+}
@@ -11,6 +11,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -28,6 +29,8 @@ import (
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/processor"
+
+	"github.com/open-telemetry/otel-arrow/collector/pdatasize"
 )
 
 var (
@@ -49,6 +52,9 @@ type batchProcessor struct {
 	sendBatchSize    int
 	sendBatchMaxSize int
 
+	// adaptive adjusts sendBatchSize when configured, otherwise nil.
+	adaptive *adaptiveSize
+
 	// batchFunc is a factory for new batch objects corresponding
 	// with the appropriate signal.
 	batchFunc func() batch
@@ -65,6 +71,11 @@ type batchProcessor struct {
 	shutdownC  chan struct{}
 	goroutines sync.WaitGroup
 
+	// exports counts one per in-flight call to the next consumer.
+	// Shutdown waits (bounded by its context) for these to return
+	// so that data flushed at shutdown is delivered downstream.
+	exports sync.WaitGroup
+
 	telemetry *batchProcessorTelemetry
 
 	//  batcher will be either *singletonBatcher or *multiBatcher
@@ -181,6 +192,9 @@ func newBatchProcessor(set processor.CreateSettings, cfg *Config, batchFunc func
 		sem:              semaphore.NewWeighted(limitBytes),
 		tracer:           otel.GetTracerProvider(),
 	}
+	if cfg.Adaptive.Enabled {
+		bp.adaptive = newAdaptiveSize(cfg)
+	}
 	if len(bp.metadataKeys) == 0 {
 		bp.batcher = &singleShardBatcher{batcher: bp.newShard(nil)}
 	} else {
@@ -189,7 +203,7 @@ func newBatchProcessor(set processor.CreateSettings, cfg *Config, batchFunc func
 		}
 	}
 
-	bpt, err := newBatchProcessorTelemetry(set, bp.batcher.currentMetadataCardinality)
+	bpt, err := newBatchProcessorTelemetry(set, bp.batcher.currentMetadataCardinality, bp.currentSendBatchSize)
 	if err != nil {
 		return nil, fmt.Errorf("error creating batch processor telemetry: %w", err)
 	}
@@ -216,6 +230,15 @@ func (bp *batchProcessor) newShard(md map[string][]string) *shard {
 	return b
 }
 
+// currentSendBatchSize returns the configured send batch size, or
+// the one chosen by the adaptive controller.
+func (bp *batchProcessor) currentSendBatchSize() int {
+	if bp.adaptive != nil {
+		return bp.adaptive.sendBatchSize()
+	}
+	return bp.sendBatchSize
+}
+
 func (bp *batchProcessor) Capabilities() consumer.Capabilities {
 	return consumer.Capabilities{MutatesData: true}
 }
@@ -225,13 +248,28 @@ func (bp *batchProcessor) Start(context.Context, component.Host) error {
 	return nil
 }
 
-// Shutdown is invoked during service shutdown.
-func (bp *batchProcessor) Shutdown(context.Context) error {
+// Shutdown is invoked during service shutdown.  Each shard flushes
+// its partial batch, then Shutdown waits for the in-flight exports to
+// return or for the context to be canceled, whichever comes first.
+func (bp *batchProcessor) Shutdown(ctx context.Context) error {
 	close(bp.shutdownC)
 
 	// Wait until all goroutines are done.
 	bp.goroutines.Wait()
-	return nil
+
+	// Wait until all in-flight exports are acknowledged.
+	exportsDone := make(chan struct{})
+	go func() {
+		bp.exports.Wait()
+		close(exportsDone)
+	}()
+
+	select {
+	case <-exportsDone:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("shutdown with exports in flight: %w", ctx.Err())
+	}
 }
 
 func (b *shard) start() {
@@ -256,10 +294,11 @@ func (b *shard) start() {
 					break DONE
 				}
 			}
-			// This is the close of the channel
-			if b.batch.itemCount() > 0 {
-				// TODO: Set a timeout on sendTraces or
-				// make it cancellable using the context that Shutdown gets as a parameter
+			// This is the close of the channel.  Flush
+			// everything, which may take several sends
+			// when sendBatchMaxSize is set.  Shutdown
+			// waits for these exports with its context.
+			for b.batch.itemCount() > 0 {
 				b.sendItems(triggerShutdown)
 			}
 			return
@@ -295,7 +334,8 @@ func (b *shard) processItem(item dataItem) {
 func (b *shard) flushItems() {
 	sent := false
 
-	for b.batch.itemCount() > 0 && (!b.hasTimer() || b.batch.itemCount() >= b.processor.sendBatchSize) {
+	sendBatchSize := b.processor.currentSendBatchSize()
+	for b.batch.itemCount() > 0 && (!b.hasTimer() || b.batch.itemCount() >= sendBatchSize) {
 		b.sendItems(triggerBatchSize)
 		sent = true
 	}
@@ -358,7 +398,10 @@ func (b *shard) sendItems(trigger trigger) {
 		}
 	}
 
+	b.processor.exports.Add(1)
 	go func() {
+		defer b.processor.exports.Done()
+
 		before := time.Now()
 		var err error
 
@@ -377,7 +420,19 @@ func (b *shard) sendItems(trigger trigger) {
 			parent, sp = b.tracer.Tracer("otel").Start(b.exportCtx, "concurrent_batch_processor/export", trace.WithLinks(links...))
 			sp.End()
 		}
-		err = b.batch.export(parent, req)
+		// The exporter may reuse the size measured above.
+		ctx := pdatasize.ContextWithSize(parent, req, int(bytes))
+
+		// The adaptive controller learns how well the batch
+		// compressed, if the exporter reports it.
+		var uncompressed, compressed atomic.Int64
+		if b.processor.adaptive != nil {
+			ctx = pdatasize.ContextWithCompressionReporter(ctx, func(u, c int) {
+				uncompressed.Add(int64(u))
+				compressed.Add(int64(c))
+			})
+		}
+		err = b.batch.export(ctx, req)
 
 		latency := time.Since(before)
 		for i := range waiters {
@@ -390,6 +445,9 @@ func (b *shard) sendItems(trigger trigger) {
 			b.processor.logger.Warn("Sender failed", zap.Error(err))
 		} else {
 			b.processor.telemetry.record(latency, trigger, int64(sent), bytes)
+			if b.processor.adaptive != nil {
+				b.processor.adaptive.observe(latency, int(uncompressed.Load()), int(compressed.Load()))
+			}
 		}
 	}()
 
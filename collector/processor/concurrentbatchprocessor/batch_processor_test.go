@@ -17,6 +17,7 @@ import (
 	"go.uber.org/zap"
 	"golang.org/x/sync/semaphore"
 
+	"github.com/open-telemetry/otel-arrow/collector/pdatasize"
 	"github.com/open-telemetry/otel-arrow/collector/processor/concurrentbatchprocessor/testdata"
 	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
@@ -666,6 +667,41 @@ func testBatchProcessorSentBySize(t *testing.T, tel testTelemetry) {
 	})
 }
 
+// TestBatchProcessorExportSize tests that each exported batch carries
+// its size in the context, for the exporter to reuse.
+func TestBatchProcessorExportSize(t *testing.T) {
+	sizer := &ptrace.ProtoMarshaler{}
+	var lock sync.Mutex
+	var exported int
+	next, err := consumer.NewTraces(func(ctx context.Context, td ptrace.Traces) error {
+		size, ok := pdatasize.FromContext(ctx, td)
+		assert.True(t, ok)
+		assert.Equal(t, sizer.TracesSize(td), size)
+		lock.Lock()
+		defer lock.Unlock()
+		exported++
+		return nil
+	})
+	require.NoError(t, err)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.SendBatchSize = 10
+	cfg.SendBatchMaxSize = 10
+	creationSet := processortest.NewNopCreateSettings()
+	batcher, err := newBatchTracesProcessor(creationSet, next, cfg)
+	require.NoError(t, err)
+	require.NoError(t, batcher.Start(context.Background(), componenttest.NewNopHost()))
+
+	for requestNum := 0; requestNum < 5; requestNum++ {
+		require.NoError(t, batcher.ConsumeTraces(context.Background(), testdata.GenerateTraces(7)))
+	}
+	require.NoError(t, batcher.Shutdown(context.Background()))
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Less(t, 0, exported)
+}
+
 func TestBatchProcessorSentBySizeWithMaxSize(t *testing.T) {
 	telemetryTest(t, testBatchProcessorSentBySizeWithMaxSize)
 }
@@ -796,6 +832,70 @@ func TestBatchProcessorTraceSendWhenClosing(t *testing.T) {
 	require.Equal(t, 1, len(sink.AllTraces()))
 }
 
+// This test verifies that Shutdown flushes the partial batch and
+// waits for in-flight exports, bounded by the Shutdown context.
+func TestBatchProcessorShutdownWaitsForInFlight(t *testing.T) {
+	for _, timeout := range []bool{true, false} {
+		t.Run(fmt.Sprint("timeout=", timeout), func(t *testing.T) {
+			cfg := Config{
+				Timeout:            10 * time.Second,
+				SendBatchSize:      10,
+				SendBatchMaxSize:   10,
+				MaxInFlightSizeMiB: defaultMaxInFlightSizeMiB,
+			}
+			bc := &blockingConsumer{
+				blocking: make(chan struct{}, 1),
+				sem:      semaphore.NewWeighted(int64(cfg.MaxInFlightSizeMiB << 20)),
+				szr:      &ptrace.ProtoMarshaler{},
+			}
+			bp, err := newBatchTracesProcessor(processortest.NewNopCreateSettings(), bc, &cfg)
+			require.NoError(t, err)
+			require.NoError(t, bp.Start(context.Background(), componenttest.NewNopHost()))
+
+			// 25 spans produce two full batches and leave
+			// five spans waiting for the timeout.
+			consumeErr := make(chan error, 1)
+			go func() {
+				consumeErr <- bp.ConsumeTraces(context.Background(), testdata.GenerateTraces(25))
+			}()
+
+			require.Eventually(t, func() bool {
+				return bc.getItemsWaiting() == 20
+			}, 5*time.Second, 10*time.Millisecond)
+
+			if timeout {
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				defer cancel()
+
+				err = bp.Shutdown(ctx)
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+				require.Equal(t, 25, bc.getItemsWaiting())
+
+				bc.unblock()
+			} else {
+				shutdownErr := make(chan error, 1)
+				go func() {
+					shutdownErr <- bp.Shutdown(context.Background())
+				}()
+
+				require.Eventually(t, func() bool {
+					return bc.getItemsWaiting() == 25
+				}, 5*time.Second, 10*time.Millisecond)
+
+				select {
+				case err = <-shutdownErr:
+					t.Fatalf("shutdown returned before exports finished: %v", err)
+				case <-time.After(50 * time.Millisecond):
+				}
+
+				bc.unblock()
+				require.NoError(t, <-shutdownErr)
+			}
+			require.NoError(t, <-consumeErr)
+		})
+	}
+}
+
 func TestBatchMetricProcessor_ReceivingData(t *testing.T) {
 	// Instantiate the batch processor with low config values to test data
 	// gets sent through the processor.
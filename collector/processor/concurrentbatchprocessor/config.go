@@ -48,6 +48,30 @@ type Config struct {
 	// MaxInFlightSizeMiB limits the number of bytes in queue waiting to be
 	// processed by the senders.
 	MaxInFlightSizeMiB uint32 `mapstructure:"max_in_flight_size_mib"`
+
+	// Adaptive configures adjusting the send batch size to the
+	// observed export latency and compression ratio.
+	Adaptive AdaptiveConfig `mapstructure:"adaptive"`
+}
+
+// AdaptiveConfig configures the send batch size controller.  Starting
+// from SendBatchSize, the controller shrinks batches whose exports
+// take longer than TargetLatency and grows the others, as long as
+// growing improves their compression ratio when the exporter reports
+// it (e.g., the OTel-Arrow exporter).
+type AdaptiveConfig struct {
+	// Enabled turns the controller on.
+	Enabled bool `mapstructure:"enabled"`
+
+	// MinSendBatchSize and MaxSendBatchSize bound the send batch
+	// size.  They must be set around SendBatchSize, and
+	// MaxSendBatchSize must not exceed SendBatchMaxSize, if set.
+	MinSendBatchSize uint32 `mapstructure:"min_send_batch_size"`
+	MaxSendBatchSize uint32 `mapstructure:"max_send_batch_size"`
+
+	// TargetLatency is the export latency above which batches
+	// shrink.
+	TargetLatency time.Duration `mapstructure:"target_latency"`
 }
 
 var _ component.Config = (*Config)(nil)
@@ -71,5 +95,27 @@ func (cfg *Config) Validate() error {
 	if cfg.MaxInFlightSizeMiB <= 0 {
 		return errors.New("max_in_flight_size_mib must be greater than 0")
 	}
+	return cfg.Adaptive.validate(cfg)
+}
+
+func (acfg *AdaptiveConfig) validate(cfg *Config) error {
+	if !acfg.Enabled {
+		return nil
+	}
+	if cfg.SendBatchSize == 0 {
+		return errors.New("adaptive requires send_batch_size to be greater than 0")
+	}
+	if acfg.MinSendBatchSize == 0 || acfg.MinSendBatchSize > cfg.SendBatchSize {
+		return errors.New("adaptive::min_send_batch_size must be greater than 0 and at most send_batch_size")
+	}
+	if acfg.MaxSendBatchSize < cfg.SendBatchSize {
+		return errors.New("adaptive::max_send_batch_size must be greater or equal to send_batch_size")
+	}
+	if cfg.SendBatchMaxSize > 0 && acfg.MaxSendBatchSize > cfg.SendBatchMaxSize {
+		return errors.New("adaptive::max_send_batch_size must be at most send_batch_max_size")
+	}
+	if acfg.TargetLatency <= 0 {
+		return errors.New("adaptive::target_latency must be greater than 0")
+	}
 	return nil
 }
@@ -36,6 +36,9 @@ func TestUnmarshalConfig(t *testing.T) {
 			Timeout:                  time.Second * 10,
 			MetadataCardinalityLimit: 1000,
 			MaxInFlightSizeMiB:       12345,
+			Adaptive: AdaptiveConfig{
+				TargetLatency: defaultAdaptiveTargetLatency,
+			},
 		}, cfg)
 }
 
@@ -79,3 +82,39 @@ func TestValidateConfig_InvalidZero(t *testing.T) {
 	cfg := &Config{}
 	assert.Error(t, cfg.Validate())
 }
+
+func TestValidateConfig_Adaptive(t *testing.T) {
+	valid := func() *Config {
+		return &Config{
+			SendBatchSize:      100,
+			SendBatchMaxSize:   1000,
+			MaxInFlightSizeMiB: 1,
+			Adaptive: AdaptiveConfig{
+				Enabled:          true,
+				MinSendBatchSize: 10,
+				MaxSendBatchSize: 1000,
+				TargetLatency:    time.Second,
+			},
+		}
+	}
+	assert.NoError(t, valid().Validate())
+
+	for name, modify := range map[string]func(*Config){
+		"no send_batch_size": func(cfg *Config) { cfg.SendBatchSize = 0 },
+		"zero minimum":       func(cfg *Config) { cfg.Adaptive.MinSendBatchSize = 0 },
+		"minimum too large":  func(cfg *Config) { cfg.Adaptive.MinSendBatchSize = 101 },
+		"maximum too small":  func(cfg *Config) { cfg.Adaptive.MaxSendBatchSize = 99 },
+		"maximum too large":  func(cfg *Config) { cfg.Adaptive.MaxSendBatchSize = 1001 },
+		"no target latency":  func(cfg *Config) { cfg.Adaptive.TargetLatency = 0 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid()
+			modify(cfg)
+			assert.Error(t, cfg.Validate())
+
+			// Nothing is checked unless enabled.
+			cfg.Adaptive.Enabled = false
+			assert.NoError(t, cfg.Validate())
+		})
+	}
+}
@@ -26,6 +26,10 @@ const (
 	// of metadata configurations the user expects to submit to
 	// the collector.
 	defaultMetadataCardinalityLimit = 1000
+
+	// defaultAdaptiveTargetLatency is the export latency above
+	// which the adaptive send batch size shrinks.
+	defaultAdaptiveTargetLatency = time.Second
 )
 
 // NewFactory returns a new factory for the Batch processor.
@@ -44,6 +48,9 @@ func createDefaultConfig() component.Config {
 		Timeout:                  defaultTimeout,
 		MaxInFlightSizeMiB:       defaultMaxInFlightSizeMiB,
 		MetadataCardinalityLimit: defaultMetadataCardinalityLimit,
+		Adaptive: AdaptiveConfig{
+			TargetLatency: defaultAdaptiveTargetLatency,
+		},
 	}
 }
 
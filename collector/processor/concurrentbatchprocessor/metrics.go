@@ -52,6 +52,7 @@ type batchProcessorTelemetry struct {
 	batchSendSizeBytes       metric.Int64Histogram
 	batchSendLatency         metric.Float64Histogram
 	batchMetadataCardinality metric.Int64ObservableUpDownCounter
+	sendBatchSize            metric.Int64ObservableGauge
 
 	// Note: since the semaphore does not provide access to its current
 	// value, we instrument the number of in-flight bytes using parallel
@@ -59,7 +60,7 @@ type batchProcessorTelemetry struct {
 	batchInFlightBytes metric.Int64UpDownCounter
 }
 
-func newBatchProcessorTelemetry(set processor.CreateSettings, currentMetadataCardinality func() int) (*batchProcessorTelemetry, error) {
+func newBatchProcessorTelemetry(set processor.CreateSettings, currentMetadataCardinality, currentSendBatchSize func() int) (*batchProcessorTelemetry, error) {
 	exportCtx := context.WithValue(context.Background(), processorTagKey, set.ID.String())
 
 	bpt := &batchProcessorTelemetry{
@@ -69,14 +70,14 @@ func newBatchProcessorTelemetry(set processor.CreateSettings, currentMetadataCar
 		detailed:      set.MetricsLevel == configtelemetry.LevelDetailed,
 	}
 
-	if err := bpt.createOtelMetrics(set.MeterProvider, currentMetadataCardinality); err != nil {
+	if err := bpt.createOtelMetrics(set.MeterProvider, currentMetadataCardinality, currentSendBatchSize); err != nil {
 		return nil, err
 	}
 
 	return bpt, nil
 }
 
-func (bpt *batchProcessorTelemetry) createOtelMetrics(mp metric.MeterProvider, currentMetadataCardinality func() int) error {
+func (bpt *batchProcessorTelemetry) createOtelMetrics(mp metric.MeterProvider, currentMetadataCardinality, currentSendBatchSize func() int) error {
 	bpt.processorAttrOption = metric.WithAttributes(bpt.processorAttr...)
 
 	var errors, err error
@@ -128,6 +129,17 @@ func (bpt *batchProcessorTelemetry) createOtelMetrics(mp metric.MeterProvider, c
 	)
 	errors = multierr.Append(errors, err)
 
+	bpt.sendBatchSize, err = meter.Int64ObservableGauge(
+		processorhelper.BuildCustomMetricName(metricTypeStr, "send_batch_size"),
+		metric.WithDescription("Number of units that triggers sending a batch, adjusted when adaptive"),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(_ context.Context, obs metric.Int64Observer) error {
+			obs.Observe(int64(currentSendBatchSize()), bpt.processorAttrOption)
+			return nil
+		}),
+	)
+	errors = multierr.Append(errors, err)
+
 	bpt.batchInFlightBytes, err = meter.Int64UpDownCounter(
 		processorhelper.BuildCustomMetricName(metricTypeStr, "in_flight_bytes"),
 		metric.WithDescription("Number of bytes in flight"),
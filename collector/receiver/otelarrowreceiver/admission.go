@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowreceiver // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver"
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/collector/client"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/otel-arrow/collector/admission"
+)
+
+// admissionLimits returns the limits of the bounded queue, from the
+// deprecated arrow::admission_limit_mib and arrow::waiter_limit when
+// they are set, otherwise from the admission section.
+func (cfg *Config) admissionLimits() (requestLimitBytes, waiterLimit int64) {
+	requestLimitMiB, waiterLimit := cfg.Admission.RequestLimitMiB, cfg.Admission.WaiterLimit
+	if cfg.Arrow.AdmissionLimitMiB != 0 {
+		requestLimitMiB = cfg.Arrow.AdmissionLimitMiB
+	}
+	if cfg.Arrow.WaiterLimit != 0 {
+		waiterLimit = cfg.Arrow.WaiterLimit
+	}
+	return int64(requestLimitMiB << 20), waiterLimit
+}
+
+// warnDeprecatedAdmission logs the deprecated admission settings
+// that are set.
+func (cfg *Config) warnDeprecatedAdmission(logger *zap.Logger) {
+	if cfg.Arrow.AdmissionLimitMiB != 0 {
+		logger.Warn("arrow::admission_limit_mib is deprecated, use admission::request_limit_mib instead")
+	}
+	if cfg.Arrow.WaiterLimit != 0 {
+		logger.Warn("arrow::waiter_limit is deprecated, use admission::waiter_limit instead")
+	}
+}
+
+// admissionOptions returns the options of the bounded queue that
+// implement the admission policy.
+func (cfg *ArrowConfig) admissionOptions() []admission.Option {
+	if cfg.AdmissionPolicy != admission.PolicyWeighted {
+		return []admission.Option{admission.WithPolicy(cfg.AdmissionPolicy)}
+	}
+	clients := cfg.AdmissionClients
+	return []admission.Option{admission.WithWeights(clientIdentity(clients.MetadataKey, clients.AuthAttribute), clients.Weights)}
+}
+
+// clientIdentity returns the function that identifies the client of
+// a request by the metadata key, if set, otherwise by the auth
+// attribute.  Multiple values are joined with commas.
+func clientIdentity(metadataKey, authAttribute string) func(context.Context) string {
+	return func(ctx context.Context) string {
+		return strings.Join(clientValues(client.FromContext(ctx), metadataKey, authAttribute), ",")
+	}
+}
@@ -5,51 +5,453 @@ package otelarrowreceiver // import "github.com/open-telemetry/otel-arrow/collec
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/open-telemetry/otel-arrow/collector/arrowconfig"
 	"github.com/open-telemetry/otel-arrow/collector/compression/zstd"
+	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configgrpc"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/multierr"
+)
+
+const (
+	// httpKey is the key of the HTTP protocol, see Config.Unmarshal.
+	httpKey = "protocols::http"
+
+	defaultHTTPEndpoint   = "0.0.0.0:4318"
+	defaultTracesURLPath  = "/v1/arrow/traces"
+	defaultMetricsURLPath = "/v1/arrow/metrics"
+	defaultLogsURLPath    = "/v1/arrow/logs"
+
+	defaultOTLPTracesURLPath  = "/v1/traces"
+	defaultOTLPMetricsURLPath = "/v1/metrics"
+	defaultOTLPLogsURLPath    = "/v1/logs"
 )
 
 // Protocols is the configuration for the supported protocols.
 type Protocols struct {
 	GRPC  configgrpc.ServerConfig `mapstructure:"grpc"`
+	HTTP  *HTTPConfig             `mapstructure:"http"`
 	Arrow ArrowConfig             `mapstructure:"arrow"`
 }
 
+// HTTPConfig serves Arrow streams over HTTP/2 without gRPC, for
+// environments that cannot terminate gRPC at the edge.  Each stream
+// is one POST request whose body carries the stream's batches and
+// whose response carries their statuses, framed as in gRPC.  Nil
+// disables the protocol.  The streams share the Arrow settings and
+// the gRPC settings that apply to batches, i.e. the per-batch auth,
+// include_metadata and max_recv_msg_size_mib of protocols::grpc.
+// The server also accepts standard OTLP/HTTP requests, with protobuf
+// or JSON bodies.
+type HTTPConfig struct {
+	confighttp.ServerConfig `mapstructure:",squash"`
+
+	// TracesURLPath, MetricsURLPath and LogsURLPath are the
+	// paths of the streams of each signal.
+	TracesURLPath  string `mapstructure:"traces_url_path"`
+	MetricsURLPath string `mapstructure:"metrics_url_path"`
+	LogsURLPath    string `mapstructure:"logs_url_path"`
+
+	// OTLPTracesURLPath, OTLPMetricsURLPath and OTLPLogsURLPath
+	// are the paths of the OTLP/HTTP requests of each signal.
+	OTLPTracesURLPath  string `mapstructure:"otlp_traces_url_path"`
+	OTLPMetricsURLPath string `mapstructure:"otlp_metrics_url_path"`
+	OTLPLogsURLPath    string `mapstructure:"otlp_logs_url_path"`
+}
+
+// defaultHTTPConfig returns the HTTP protocol settings used when its
+// key is present.
+func defaultHTTPConfig() *HTTPConfig {
+	return &HTTPConfig{
+		ServerConfig: confighttp.ServerConfig{
+			Endpoint: defaultHTTPEndpoint,
+		},
+		TracesURLPath:  defaultTracesURLPath,
+		MetricsURLPath: defaultMetricsURLPath,
+		LogsURLPath:    defaultLogsURLPath,
+
+		OTLPTracesURLPath:  defaultOTLPTracesURLPath,
+		OTLPMetricsURLPath: defaultOTLPMetricsURLPath,
+		OTLPLogsURLPath:    defaultOTLPLogsURLPath,
+	}
+}
+
 // ArrowConfig support configuring the Arrow receiver.
 type ArrowConfig struct {
-	// MemoryLimitMiB is the size of a shared memory region used
-	// by all Arrow streams, in MiB.  When too much load is
-	// passing through, they will see ResourceExhausted errors.
-	MemoryLimitMiB uint64 `mapstructure:"memory_limit_mib"`
-
-	// AdmissionLimitMiB limits the number of requests that are received by the stream based on
-	// request size information available. Request size is used to control how much traffic we admit
-	// for processing, but does not control how much memory is used during request processing.
-	AdmissionLimitMiB uint64 `mapstructure:"admission_limit_mib"`
-
-	// WaiterLimit is the limit on the number of waiters waiting to be processed and consumed.
-	// This is a dimension of memory limiting to ensure waiters are not consuming an
-	// unexpectedly large amount of memory in the arrow receiver.
-	WaiterLimit int64 `mapstructure:"waiter_limit"`
+	// AdmissionConfig sets MemoryLimitMiB and the admission
+	// policy.  Its AdmissionLimitMiB and WaiterLimit are
+	// deprecated in favor of Config.Admission, and override it
+	// when set.
+	arrowconfig.AdmissionConfig `mapstructure:",squash"`
 
 	// Zstd settings apply to OTel-Arrow use of gRPC specifically.
 	Zstd zstd.DecoderConfig `mapstructure:"zstd"`
+
+	// PayloadZstd configures the Zstd decoder of Arrow payloads
+	// compressed by exporters.
+	PayloadZstd arrowconfig.PayloadZstdConfig `mapstructure:"payload_zstd"`
+
+	// ZstdDictionaries are paths of pre-trained Zstd dictionaries
+	// (see tools/zstd_dict_train) that exporters may use to
+	// compress Arrow payloads.
+	ZstdDictionaries []string `mapstructure:"zstd_dictionaries"`
+
+	// Passthrough decodes batches into marshaled OTLP requests,
+	// logs without constructing pdata, when the next consumer of
+	// their signal accepts them (see the passthrough package).
+	Passthrough bool `mapstructure:"passthrough"`
+
+	// OrderedResponses sends the responses of each stream in the
+	// order its batches were received, so that exporters observe
+	// acknowledgments in send order.
+	OrderedResponses bool `mapstructure:"ordered_responses"`
+
+	// DedupWindow is the number of idempotency keys of recently
+	// consumed batches that are remembered, so that retried
+	// batches are not consumed again.  Keys are scoped by the
+	// authenticated principal of each batch.  Zero disables
+	// deduplication.
+	DedupWindow int `mapstructure:"dedup_window"`
+
+	// StreamInFlightLimitMiB is the uncompressed size of the
+	// batches that one stream may be consuming before the
+	// receiver stops reading from it, which applies gRPC flow
+	// control to the exporter.  Zero disables the limit.
+	StreamInFlightLimitMiB uint64 `mapstructure:"stream_in_flight_limit_mib"`
+
+	// MaxConcurrentBatchesPerStream is the number of batches that
+	// one stream may have outstanding before the receiver stops
+	// reading from it, so that one client cannot queue unbounded
+	// work.  Zero disables the limit.
+	MaxConcurrentBatchesPerStream int `mapstructure:"max_concurrent_batches_per_stream"`
+
+	// CreditBatches and CreditMiB bound the flow control credit
+	// window granted to each stream: the batches, and their
+	// encoded size, that an exporter may send before receiving
+	// their status.  The window shrinks while the pipeline pushes
+	// back, so that exporters block before sending instead of
+	// having batches rejected.  Zero disables each bound, and
+	// both zero grant no credits.
+	CreditBatches int    `mapstructure:"credit_batches"`
+	CreditMiB     uint64 `mapstructure:"credit_mib"`
+
+	// StreamWorkers is the number of goroutines that deliver the
+	// decoded batches of each stream to the pipeline.  Zero
+	// starts a goroutine per batch.
+	StreamWorkers int `mapstructure:"stream_workers"`
+
+	// MaxSchemas is the number of Arrow schemas, with their
+	// dictionaries, that each stream keeps.  Beyond it, the least
+	// recently used schema is released and an exporter that uses
+	// it again is asked to restart its stream.  Zero selects the
+	// default.
+	MaxSchemas int `mapstructure:"max_schemas"`
+
+	// IncludeReceiverID adds the host name and the instance ID of
+	// this collector to every response, so that exporters behind
+	// a load balancer can tell which collector responded.
+	IncludeReceiverID bool `mapstructure:"include_receiver_id"`
+
+	// MetadataAllowedKeys, when not empty, are the only Arrow
+	// batch and stream header keys that `include_metadata` copies
+	// into the client metadata of each batch, and
+	// MetadataDeniedKeys are never copied.  Keys are
+	// case-insensitive.  The auth extension sees every header.
+	MetadataAllowedKeys []string `mapstructure:"metadata_allowed_keys"`
+	MetadataDeniedKeys  []string `mapstructure:"metadata_denied_keys"`
+
+	// RedactErrors replaces the messages of the statuses returned
+	// to exporters, and the errors logged by Arrow streams, with a
+	// digest, because they may contain client metadata or
+	// attribute values.
+	RedactErrors bool `mapstructure:"redact_errors"`
+
+	// Audit records every batch rejected by the Arrow streams.
+	Audit AuditConfig `mapstructure:"audit"`
+
+	// PeerInstanceMetrics counts the batches received from each
+	// exporter that sends its collector's instance identity, by
+	// its instance ID and deployment labels.  The number of
+	// series grows with the number of exporting collectors.
+	PeerInstanceMetrics bool `mapstructure:"peer_instance_metrics"`
+}
+
+// AuditConfig writes a record of every rejected batch, with the
+// client's identity, the status and the size of the batch, to a
+// file, for compliance and capacity investigations.
+type AuditConfig struct {
+	// Path is the file that the records are appended to, as
+	// JSON lines.  Empty disables the audit log.
+	Path string `mapstructure:"path"`
+
+	// MetadataKey or AuthAttribute, at most one, identifies the
+	// client of each batch.
+	MetadataKey   string `mapstructure:"metadata_key"`
+	AuthAttribute string `mapstructure:"auth_attribute"`
+
+	// Sampling limits the records written during overloads.
+	Sampling AuditSamplingConfig `mapstructure:"sampling"`
+}
+
+// AuditSamplingConfig writes, within each Tick, the first Initial
+// records of each status code, then every Thereafter-th.  Zero
+// Initial and Thereafter write every record.
+type AuditSamplingConfig struct {
+	Initial    int           `mapstructure:"initial"`
+	Thereafter int           `mapstructure:"thereafter"`
+	Tick       time.Duration `mapstructure:"tick"`
 }
 
 // Config defines configuration for OTel Arrow receiver.
 type Config struct {
 	// Protocols is the configuration for gRPC and Arrow.
 	Protocols `mapstructure:"protocols"`
+
+	// ResourceAttributes are set on every resource received from
+	// the client metadata or the auth attributes of the request,
+	// so that processors can act on them without client.Info.
+	ResourceAttributes []ResourceAttributeConfig `mapstructure:"resource_attributes"`
+
+	// SpanSizeAttributes selects the message size attributes set
+	// on the receive spans: "off", "compressed", or "both", the
+	// default.
+	SpanSizeAttributes netstats.SpanSizeAttributes `mapstructure:"span_size_attributes"`
+
+	// SizeHistograms records the histograms of message sizes at
+	// the normal level of telemetry, as at the detailed level.
+	SizeHistograms bool `mapstructure:"size_histograms"`
+
+	// Admission bounds the batches that the Arrow streams consume
+	// at once.
+	Admission AdmissionConfig `mapstructure:"admission"`
+}
+
+// AdmissionConfig bounds the uncompressed size of the batches in
+// flight across all the Arrow streams of the receiver, and the
+// number of batches waiting for admission.  Beyond them, batches
+// fail with ResourceExhausted.
+type AdmissionConfig struct {
+	// RequestLimitMiB is the uncompressed size of the batches
+	// admitted at once, in MiB.  It controls how much traffic is
+	// admitted, not how much memory decoding it uses, see
+	// arrow::memory_limit_mib.
+	RequestLimitMiB uint64 `mapstructure:"request_limit_mib"`
+
+	// WaiterLimit is the number of batches waiting for
+	// admission once RequestLimitMiB is reached, which limits the
+	// memory held by the waiting batches.
+	WaiterLimit int64 `mapstructure:"waiter_limit"`
+}
+
+// ResourceAttributeConfig sets one resource attribute from either a
+// client metadata key, which requires `include_metadata`, or an
+// attribute set by the auth extension.
+type ResourceAttributeConfig struct {
+	// MetadataKey is the client metadata key, case-insensitive.
+	MetadataKey string `mapstructure:"metadata_key"`
+
+	// AuthAttribute is the name of the auth attribute.
+	AuthAttribute string `mapstructure:"auth_attribute"`
+
+	// Attribute is the resource attribute to set, by default the
+	// lower-case metadata key or the auth attribute.
+	Attribute string `mapstructure:"attribute"`
+}
+
+// attribute returns the name of the resource attribute to set.
+func (ra ResourceAttributeConfig) attribute() string {
+	switch {
+	case ra.Attribute != "":
+		return ra.Attribute
+	case ra.MetadataKey != "":
+		return strings.ToLower(ra.MetadataKey)
+	}
+	return ra.AuthAttribute
 }
 
 var _ component.Config = (*Config)(nil)
+var _ confmap.Unmarshaler = (*Config)(nil)
+var _ component.ConfigValidator = (*Config)(nil)
+var _ component.ConfigValidator = (*HTTPConfig)(nil)
 var _ component.ConfigValidator = (*ArrowConfig)(nil)
+var _ component.ConfigValidator = (*AdmissionConfig)(nil)
+
+// Unmarshal enables the HTTP protocol, with its defaults, when its
+// key is present, even without settings.
+func (cfg *Config) Unmarshal(conf *confmap.Conf) error {
+	if conf.IsSet(httpKey) && cfg.HTTP == nil {
+		cfg.HTTP = defaultHTTPConfig()
+	}
+	return conf.Unmarshal(cfg)
+}
+
+// Validate returns an error for settings of different sections that
+// conflict, such as resource attributes that need metadata which is
+// not included.  The sections validate themselves.
+func (cfg *Config) Validate() (errs error) {
+	for i, ra := range cfg.ResourceAttributes {
+		switch {
+		case (ra.MetadataKey == "") == (ra.AuthAttribute == ""):
+			errs = multierr.Append(errs, fmt.Errorf("resource_attributes[%d]: set exactly one of metadata_key and auth_attribute", i))
+		case ra.MetadataKey != "" && !cfg.GRPC.IncludeMetadata:
+			errs = multierr.Append(errs, fmt.Errorf("resource_attributes[%d]: metadata_key %q requires protocols::grpc::include_metadata", i, ra.MetadataKey))
+		}
+	}
+	if err := cfg.SpanSizeAttributes.Validate(); err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("span_size_attributes: %w", err))
+	}
+	if key := cfg.Arrow.AdmissionClients.MetadataKey; key != "" && !cfg.GRPC.IncludeMetadata {
+		errs = multierr.Append(errs, fmt.Errorf("arrow::admission_clients::metadata_key %q requires protocols::grpc::include_metadata", key))
+	}
+	if key := cfg.Arrow.Audit.MetadataKey; key != "" && !cfg.GRPC.IncludeMetadata {
+		errs = multierr.Append(errs, fmt.Errorf("arrow::audit::metadata_key %q requires protocols::grpc::include_metadata", key))
+	}
+	errs = multierr.Append(errs, cfg.Admission.Validate())
+	return errs
+}
+
+// netstatsOptions returns the options of the network reporter.
+func (cfg *Config) netstatsOptions() []netstats.Option {
+	opts := []netstats.Option{netstats.WithSpanSizeAttributes(cfg.SpanSizeAttributes)}
+	if cfg.SizeHistograms {
+		opts = append(opts, netstats.WithSizeHistograms())
+	}
+	return opts
+}
+
+// Validate returns an error for a missing endpoint or for URL paths
+// that are not absolute or that serve several signals.
+func (cfg *HTTPConfig) Validate() (errs error) {
+	if cfg.Endpoint == "" {
+		errs = multierr.Append(errs, fmt.Errorf("protocols::http::endpoint: endpoint must be set"))
+	}
+	seen := map[string]string{}
+	for _, p := range []struct{ key, path string }{
+		{"traces_url_path", cfg.TracesURLPath},
+		{"metrics_url_path", cfg.MetricsURLPath},
+		{"logs_url_path", cfg.LogsURLPath},
+		{"otlp_traces_url_path", cfg.OTLPTracesURLPath},
+		{"otlp_metrics_url_path", cfg.OTLPMetricsURLPath},
+		{"otlp_logs_url_path", cfg.OTLPLogsURLPath},
+	} {
+		if !strings.HasPrefix(p.path, "/") {
+			errs = multierr.Append(errs, fmt.Errorf("protocols::http::%s: path must start with /: %q", p.key, p.path))
+			continue
+		}
+		if other, ok := seen[p.path]; ok {
+			errs = multierr.Append(errs, fmt.Errorf("protocols::http::%s: path %q is also the %s", p.key, p.path, other))
+		}
+		seen[p.path] = p.key
+	}
+	return errs
+}
+
+// Validate returns an error for negative or overflowing limits.
+func (cfg *AdmissionConfig) Validate() (errs error) {
+	if cfg.RequestLimitMiB > math.MaxInt64>>20 {
+		errs = multierr.Append(errs, fmt.Errorf("admission::request_limit_mib: request limit too large: %d MiB", cfg.RequestLimitMiB))
+	}
+	if cfg.WaiterLimit < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("admission::waiter_limit: waiter limit must be >= 0: %d", cfg.WaiterLimit))
+	}
+	return errs
+}
 
-func (cfg *ArrowConfig) Validate() error {
+// Validate returns every invalid or conflicting Arrow setting,
+// qualified by its key.
+func (cfg *ArrowConfig) Validate() (errs error) {
+	errs = multierr.Append(errs, cfg.AdmissionConfig.Validate())
 	if err := cfg.Zstd.Validate(); err != nil {
-		return fmt.Errorf("zstd decoder: invalid configuration: %w", err)
+		errs = multierr.Append(errs, fmt.Errorf("zstd: zstd decoder: invalid configuration: %w", err))
+	}
+	errs = multierr.Append(errs, cfg.PayloadZstd.Validate())
+	if _, _, err := cfg.loadZstdDictionaries(); err != nil {
+		errs = multierr.Append(errs, err)
+	}
+	if cfg.DedupWindow < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("dedup_window must be non-negative: %d", cfg.DedupWindow))
+	}
+	if cfg.MaxSchemas < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("max_schemas must be non-negative: %d", cfg.MaxSchemas))
+	}
+	if cfg.MaxConcurrentBatchesPerStream < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("max_concurrent_batches_per_stream must be non-negative: %d", cfg.MaxConcurrentBatchesPerStream))
+	}
+	if cfg.CreditBatches < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("credit_batches must be non-negative: %d", cfg.CreditBatches))
+	}
+	if cfg.CreditMiB > math.MaxInt64>>20 {
+		errs = multierr.Append(errs, fmt.Errorf("credit_mib is too large: %d", cfg.CreditMiB))
+	}
+	if cfg.StreamWorkers < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("stream_workers must be non-negative: %d", cfg.StreamWorkers))
+	}
+	if cfg.StreamInFlightLimitMiB > math.MaxInt64>>20 {
+		errs = multierr.Append(errs, fmt.Errorf("stream_in_flight_limit_mib is too large: %d", cfg.StreamInFlightLimitMiB))
+	} else if cfg.MemoryLimitMiB != 0 && cfg.StreamInFlightLimitMiB > cfg.MemoryLimitMiB {
+		// A stream would never reach its limit.
+		errs = multierr.Append(errs, fmt.Errorf("stream_in_flight_limit_mib: %d exceeds memory_limit_mib %d, lower it to at most the memory limit",
+			cfg.StreamInFlightLimitMiB, cfg.MemoryLimitMiB))
+	}
+	allowed := map[string]bool{}
+	for _, k := range cfg.MetadataAllowedKeys {
+		allowed[strings.ToLower(k)] = true
+	}
+	for _, k := range cfg.MetadataDeniedKeys {
+		if allowed[strings.ToLower(k)] {
+			errs = multierr.Append(errs, fmt.Errorf("metadata_denied_keys: %q is also allowed by metadata_allowed_keys", k))
+		}
+	}
+	errs = multierr.Append(errs, cfg.Audit.Validate())
+	return errs
+}
+
+// Validate returns an error when the client is identified twice or
+// the sampling settings are negative.
+func (cfg *AuditConfig) Validate() (errs error) {
+	if cfg.MetadataKey != "" && cfg.AuthAttribute != "" {
+		errs = multierr.Append(errs, fmt.Errorf("audit: set at most one of metadata_key and auth_attribute"))
+	}
+	s := cfg.Sampling
+	if s.Initial < 0 || s.Thereafter < 0 || s.Tick < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("audit::sampling: initial, thereafter and tick must be non-negative"))
+	}
+	return errs
+}
+
+// passthrough returns whether batches are decoded in passthrough mode,
+// when configured or enabled by the feature gate.
+func (cfg *ArrowConfig) passthrough() bool {
+	return cfg.Passthrough || passthroughGate.IsEnabled()
+}
+
+// loadZstdDictionaries reads the configured Zstd dictionaries and
+// returns them with their IDs.
+func (cfg *ArrowConfig) loadZstdDictionaries() (dicts [][]byte, ids []uint32, _ error) {
+	seen := map[uint32]string{}
+	for _, path := range cfg.ZstdDictionaries {
+		dict, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("zstd_dictionaries: %w", err)
+		}
+		id, err := zstddict.ID(dict)
+		if err != nil {
+			return nil, nil, fmt.Errorf("zstd_dictionaries: %s: %w", path, err)
+		}
+		if other, ok := seen[id]; ok {
+			return nil, nil, fmt.Errorf("zstd_dictionaries: %s and %s have the same ID %d", other, path, id)
+		}
+		seen[id] = path
+		dicts = append(dicts, dict)
+		ids = append(ids, id)
 	}
-	return nil
+	return dicts, ids, nil
 }
@@ -4,17 +4,31 @@
 package otelarrowreceiver
 
 import (
+	"fmt"
+	"math"
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/open-telemetry/otel-arrow/collector/admission"
+	"github.com/open-telemetry/otel-arrow/collector/arrowconfig"
+	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configgrpc"
+	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/featuregate"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestUnmarshalDefaultConfig(t *testing.T) {
@@ -76,16 +90,70 @@ func TestUnmarshalConfig(t *testing.T) {
 						},
 					},
 				},
+				HTTP: &HTTPConfig{
+					ServerConfig: confighttp.ServerConfig{
+						Endpoint: "0.0.0.0:4318",
+					},
+					TracesURLPath:  "/arrow/traces",
+					MetricsURLPath: "/v1/arrow/metrics",
+					LogsURLPath:    "/v1/arrow/logs",
+
+					OTLPTracesURLPath:  "/v1/traces",
+					OTLPMetricsURLPath: "/v1/metrics",
+					OTLPLogsURLPath:    "/v1/logs",
+				},
 				Arrow: ArrowConfig{
-					MemoryLimitMiB:    123,
-					AdmissionLimitMiB: 80,
-					WaiterLimit:       100,
+					AdmissionConfig: arrowconfig.AdmissionConfig{
+						MemoryLimitMiB:  123,
+						AdmissionPolicy: admission.PolicyWeighted,
+						AdmissionClients: arrowconfig.AdmissionClientsConfig{
+							AuthAttribute: "tenant",
+							Weights:       map[string]int{"tenant-a": 4},
+						},
+					},
+					Passthrough:                   true,
+					OrderedResponses:              true,
+					DedupWindow:                   1000,
+					StreamInFlightLimitMiB:        16,
+					MaxConcurrentBatchesPerStream: 8,
+					CreditBatches:                 16,
+					CreditMiB:                     32,
+					StreamWorkers:                 4,
+					MaxSchemas:                    64,
+					PayloadZstd: arrowconfig.PayloadZstdConfig{
+						Concurrency: 2,
+					},
 				},
 			},
+			Admission: AdmissionConfig{
+				RequestLimitMiB: 80,
+				WaiterLimit:     100,
+			},
 		}, cfg)
 
 }
 
+func TestUnmarshalConfigHTTP(t *testing.T) {
+	// The HTTP protocol is disabled by default, and enabled with
+	// its defaults by its key.
+	cm := confmap.NewFromStringMap(map[string]any{
+		"protocols": map[string]any{"grpc": nil, "http": nil},
+	})
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	require.Nil(t, cfg.HTTP)
+	require.NoError(t, component.UnmarshalConfig(cm, cfg))
+	require.Equal(t, defaultHTTPConfig(), cfg.HTTP)
+	require.NoError(t, component.ValidateConfig(cfg))
+
+	cfg.HTTP.Endpoint = ""
+	cfg.HTTP.MetricsURLPath = "v1/arrow/metrics"
+	cfg.HTTP.LogsURLPath = cfg.HTTP.TracesURLPath
+	err := cfg.HTTP.Validate()
+	require.ErrorContains(t, err, "protocols::http::endpoint: endpoint must be set")
+	require.ErrorContains(t, err, `protocols::http::metrics_url_path: path must start with /: "v1/arrow/metrics"`)
+	require.ErrorContains(t, err, `protocols::http::logs_url_path: path "/v1/arrow/traces" is also the traces_url_path`)
+}
+
 func TestUnmarshalConfigUnix(t *testing.T) {
 	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "uds.yaml"))
 	require.NoError(t, err)
@@ -103,14 +171,49 @@ func TestUnmarshalConfigUnix(t *testing.T) {
 					ReadBufferSize: 512 * 1024,
 				},
 				Arrow: ArrowConfig{
-					MemoryLimitMiB:    defaultMemoryLimitMiB,
-					AdmissionLimitMiB: defaultAdmissionLimitMiB,
-					WaiterLimit:       defaultWaiterLimit,
+					AdmissionConfig: arrowconfig.AdmissionConfig{
+						MemoryLimitMiB: defaultMemoryLimitMiB,
+					},
 				},
 			},
+			Admission: AdmissionConfig{
+				RequestLimitMiB: defaultRequestLimitMiB,
+				WaiterLimit:     defaultWaiterLimit,
+			},
 		}, cfg)
 }
 
+func TestUnmarshalConfigDeprecatedAdmission(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "deprecated_admission.yaml"))
+	require.NoError(t, err)
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	assert.NoError(t, component.UnmarshalConfig(cm, cfg))
+
+	// The deprecated settings override the admission section.
+	requestLimit, waiterLimit := cfg.admissionLimits()
+	assert.Equal(t, int64(80<<20), requestLimit)
+	assert.Equal(t, int64(100), waiterLimit)
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	cfg.warnDeprecatedAdmission(zap.New(core))
+	assert.Equal(t, 2, logs.Len())
+
+	requestLimit, waiterLimit = factory.CreateDefaultConfig().(*Config).admissionLimits()
+	assert.Equal(t, int64(defaultRequestLimitMiB<<20), requestLimit)
+	assert.Equal(t, int64(defaultWaiterLimit), waiterLimit)
+}
+
+func TestConfigValidateAdmission(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Admission.RequestLimitMiB = math.MaxUint64
+	cfg.Admission.WaiterLimit = -1
+	err := cfg.Validate()
+	require.Len(t, multierr.Errors(err), 2)
+	require.ErrorContains(t, err, "admission::request_limit_mib: ")
+	require.ErrorContains(t, err, "admission::waiter_limit: ")
+}
+
 func TestUnmarshalConfigTypoDefaultProtocol(t *testing.T) {
 	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "typo_default_proto_config.yaml"))
 	require.NoError(t, err)
@@ -133,3 +236,197 @@ func TestUnmarshalConfigNoProtocols(t *testing.T) {
 	// https://github.com/open-telemetry/opentelemetry-collector/pull/9385
 	assert.ErrorContains(t, component.ValidateConfig(cfg), "invalid transport type")
 }
+
+func TestArrowConfigZstdDictionaries(t *testing.T) {
+	var samples [][]byte
+	for i := 0; i < 200; i++ {
+		samples = append(samples, []byte(fmt.Sprintf(
+			"schema{resource:{service.name:string},spans:{trace_id:fixed16,name:string}} row=%d name=GET /api/v1/items/%d", i, i%7)))
+	}
+	dict, err := zstddict.Train(samples, 4096)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.dict")
+	bad := filepath.Join(dir, "bad.dict")
+	require.NoError(t, os.WriteFile(good, dict, 0o600))
+	require.NoError(t, os.WriteFile(bad, []byte("not a dictionary"), 0o600))
+
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.ZstdDictionaries = []string{good}
+	require.NoError(t, cfg.Arrow.Validate())
+
+	dicts, ids, err := cfg.Arrow.loadZstdDictionaries()
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{dict}, dicts)
+	require.Len(t, ids, 1)
+
+	cfg.Arrow.ZstdDictionaries = []string{good, good}
+	require.ErrorContains(t, cfg.Arrow.Validate(), "same ID")
+
+	cfg.Arrow.ZstdDictionaries = []string{bad}
+	require.ErrorContains(t, cfg.Arrow.Validate(), "invalid zstd dictionary")
+
+	cfg.Arrow.ZstdDictionaries = []string{filepath.Join(dir, "missing.dict")}
+	require.ErrorContains(t, cfg.Arrow.Validate(), "zstd_dictionaries")
+}
+
+func TestArrowConfigDedupWindow(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.DedupWindow = 100
+	require.NoError(t, cfg.Arrow.Validate())
+
+	cfg.Arrow.DedupWindow = -1
+	require.ErrorContains(t, cfg.Arrow.Validate(), "dedup_window must be non-negative")
+}
+
+func TestArrowConfigMaxConcurrentBatchesPerStream(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.MaxConcurrentBatchesPerStream = 8
+	require.NoError(t, cfg.Arrow.Validate())
+
+	cfg.Arrow.MaxConcurrentBatchesPerStream = -1
+	require.ErrorContains(t, cfg.Arrow.Validate(), "max_concurrent_batches_per_stream must be non-negative")
+}
+
+func TestArrowConfigStreamWorkers(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.StreamWorkers = 4
+	require.NoError(t, cfg.Arrow.Validate())
+
+	cfg.Arrow.StreamWorkers = -1
+	require.ErrorContains(t, cfg.Arrow.Validate(), "stream_workers must be non-negative")
+}
+
+func TestArrowConfigCredits(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.CreditBatches = 16
+	cfg.Arrow.CreditMiB = 32
+	require.NoError(t, cfg.Arrow.Validate())
+
+	cfg.Arrow.CreditBatches = -1
+	cfg.Arrow.CreditMiB = math.MaxUint64
+	err := cfg.Arrow.Validate()
+	require.ErrorContains(t, err, "credit_batches must be non-negative")
+	require.ErrorContains(t, err, "credit_mib is too large")
+}
+
+func TestArrowConfigStreamInFlightLimit(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.StreamInFlightLimitMiB = 16
+	require.NoError(t, cfg.Arrow.Validate())
+
+	cfg.Arrow.StreamInFlightLimitMiB = math.MaxUint64
+	require.ErrorContains(t, cfg.Arrow.Validate(), "stream_in_flight_limit_mib is too large")
+
+	// A stream could not reach a limit above the memory limit.
+	cfg.Arrow.StreamInFlightLimitMiB = cfg.Arrow.MemoryLimitMiB + 1
+	require.ErrorContains(t, cfg.Arrow.Validate(), "stream_in_flight_limit_mib: 129 exceeds memory_limit_mib 128")
+	cfg.Arrow.MemoryLimitMiB = 0
+	require.NoError(t, cfg.Arrow.Validate())
+}
+
+func TestArrowConfigValidateAllErrors(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.WaiterLimit = -1
+	cfg.Arrow.DedupWindow = -1
+	cfg.Arrow.MaxSchemas = -1
+	cfg.Arrow.PayloadZstd.WindowSizeMiB = 3
+
+	// Every invalid setting is reported at once.
+	err := cfg.Arrow.Validate()
+	require.Len(t, multierr.Errors(err), 4)
+	require.ErrorContains(t, err, "waiter_limit: ")
+	require.ErrorContains(t, err, "dedup_window must be non-negative")
+	require.ErrorContains(t, err, "max_schemas must be non-negative")
+	require.ErrorContains(t, err, "payload_zstd::window_size_mib: ")
+}
+
+func TestConfigValidateResourceAttributes(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.ResourceAttributes = []ResourceAttributeConfig{
+		{AuthAttribute: "tenant", Attribute: "tenant.id"},
+	}
+	require.NoError(t, cfg.Validate())
+
+	cfg.ResourceAttributes = append(cfg.ResourceAttributes, ResourceAttributeConfig{MetadataKey: "x-region"})
+	require.ErrorContains(t, cfg.Validate(), "resource_attributes[1]: metadata_key \"x-region\" requires protocols::grpc::include_metadata")
+	cfg.GRPC.IncludeMetadata = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.ResourceAttributes[0].MetadataKey = "x-tenant"
+	require.ErrorContains(t, cfg.Validate(), "resource_attributes[0]: set exactly one of metadata_key and auth_attribute")
+}
+
+func TestConfigValidateSpanSizeAttributes(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.SpanSizeAttributes = netstats.SpanSizeAttributesOff
+	require.NoError(t, cfg.Validate())
+
+	cfg.SpanSizeAttributes = "all"
+	require.ErrorContains(t, cfg.Validate(), "span_size_attributes: unknown span size attributes \"all\"")
+}
+
+func TestConfigValidateAdmissionClients(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.AdmissionPolicy = admission.PolicyWeighted
+	cfg.Arrow.AdmissionClients.MetadataKey = "x-tenant"
+	require.NoError(t, cfg.Arrow.Validate())
+	require.ErrorContains(t, cfg.Validate(), `arrow::admission_clients::metadata_key "x-tenant" requires protocols::grpc::include_metadata`)
+
+	cfg.GRPC.IncludeMetadata = true
+	require.NoError(t, cfg.Validate())
+}
+
+func TestConfigValidateAudit(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.Audit = AuditConfig{
+		Path:          "audit.jsonl",
+		AuthAttribute: "tenant",
+		Sampling:      AuditSamplingConfig{Initial: 10, Thereafter: 100, Tick: time.Second},
+	}
+	require.NoError(t, cfg.Validate())
+	require.NoError(t, cfg.Arrow.Validate())
+
+	cfg.Arrow.Audit.MetadataKey = "x-tenant"
+	require.ErrorContains(t, cfg.Arrow.Validate(), "audit: set at most one of metadata_key and auth_attribute")
+	require.ErrorContains(t, cfg.Validate(), `arrow::audit::metadata_key "x-tenant" requires protocols::grpc::include_metadata`)
+
+	cfg.Arrow.Audit.MetadataKey = ""
+	cfg.Arrow.Audit.Sampling.Thereafter = -1
+	require.ErrorContains(t, cfg.Arrow.Validate(), "audit::sampling: initial, thereafter and tick must be non-negative")
+}
+
+func TestArrowConfigMaxSchemas(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.MaxSchemas = 64
+	require.NoError(t, cfg.Arrow.Validate())
+
+	cfg.Arrow.MaxSchemas = -1
+	require.ErrorContains(t, cfg.Arrow.Validate(), "max_schemas must be non-negative")
+}
+
+func TestArrowConfigMetadataKeys(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Arrow.MetadataAllowedKeys = []string{"x-tenant", "Authorization"}
+	cfg.Arrow.MetadataDeniedKeys = []string{"x-debug"}
+	require.NoError(t, cfg.Arrow.Validate())
+
+	cfg.Arrow.MetadataDeniedKeys = []string{"authorization"}
+	require.ErrorContains(t, cfg.Arrow.Validate(), "metadata_denied_keys: \"authorization\" is also allowed by metadata_allowed_keys")
+}
+
+func TestArrowConfigPassthroughGate(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	require.False(t, cfg.Arrow.passthrough())
+	cfg.Arrow.Passthrough = true
+	require.True(t, cfg.Arrow.passthrough())
+
+	// The gate enables passthrough without configuration.
+	require.NoError(t, featuregate.GlobalRegistry().Set(passthroughGate.ID(), true))
+	defer func() {
+		require.NoError(t, featuregate.GlobalRegistry().Set(passthroughGate.ID(), false))
+	}()
+	cfg.Arrow.Passthrough = false
+	require.True(t, cfg.Arrow.passthrough())
+}
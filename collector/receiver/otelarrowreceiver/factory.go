@@ -6,6 +6,7 @@ package otelarrowreceiver // import "github.com/open-telemetry/otel-arrow/collec
 import (
 	"context"
 
+	"github.com/open-telemetry/otel-arrow/collector/arrowconfig"
 	"github.com/open-telemetry/otel-arrow/collector/sharedcomponent"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configgrpc"
@@ -19,19 +20,30 @@ import (
 const (
 	defaultGRPCEndpoint = "0.0.0.0:4317"
 
-	defaultMemoryLimitMiB    = 128
-	defaultAdmissionLimitMiB = defaultMemoryLimitMiB / 2
-	defaultWaiterLimit       = 1000
+	defaultMemoryLimitMiB  = 128
+	defaultRequestLimitMiB = defaultMemoryLimitMiB / 2
+	defaultWaiterLimit     = 1000
 )
 
 // NewFactory creates a new OTel-Arrow receiver factory.
 func NewFactory() receiver.Factory {
+	return NewFactoryWithOptions()
+}
+
+// NewFactoryWithOptions creates a new OTel-Arrow receiver factory
+// with extensions that cannot be configured, for collector
+// distributions that build their own components.
+func NewFactoryWithOptions(opts ...FactoryOption) receiver.Factory {
+	var fo factoryOptions
+	for _, opt := range opts {
+		opt(&fo)
+	}
 	return receiver.NewFactory(
 		metadata.Type,
 		createDefaultConfig,
-		receiver.WithTraces(createTraces, metadata.TracesStability),
-		receiver.WithMetrics(createMetrics, metadata.MetricsStability),
-		receiver.WithLogs(createLog, metadata.LogsStability))
+		receiver.WithTraces(fo.createTraces, metadata.TracesStability),
+		receiver.WithMetrics(fo.createMetrics, metadata.MetricsStability),
+		receiver.WithLogs(fo.createLog, metadata.LogsStability))
 }
 
 // createDefaultConfig creates the default configuration for receiver.
@@ -47,16 +59,20 @@ func createDefaultConfig() component.Config {
 				ReadBufferSize: 512 * 1024,
 			},
 			Arrow: ArrowConfig{
-				MemoryLimitMiB:    defaultMemoryLimitMiB,
-				AdmissionLimitMiB: defaultAdmissionLimitMiB,
-				WaiterLimit:       defaultWaiterLimit,
+				AdmissionConfig: arrowconfig.AdmissionConfig{
+					MemoryLimitMiB: defaultMemoryLimitMiB,
+				},
 			},
 		},
+		Admission: AdmissionConfig{
+			RequestLimitMiB: defaultRequestLimitMiB,
+			WaiterLimit:     defaultWaiterLimit,
+		},
 	}
 }
 
 // createTraces creates a trace receiver based on provided config.
-func createTraces(
+func (fo factoryOptions) createTraces(
 	_ context.Context,
 	set receiver.CreateSettings,
 	cfg component.Config,
@@ -64,7 +80,7 @@ func createTraces(
 ) (receiver.Traces, error) {
 	oCfg := cfg.(*Config)
 	r, err := receivers.GetOrAdd(oCfg, func() (*otelArrowReceiver, error) {
-		return newOTelArrowReceiver(oCfg, set)
+		return newOTelArrowReceiver(oCfg, set, fo)
 	})
 	if err != nil {
 		return nil, err
@@ -75,7 +91,7 @@ func createTraces(
 }
 
 // createMetrics creates a metrics receiver based on provided config.
-func createMetrics(
+func (fo factoryOptions) createMetrics(
 	_ context.Context,
 	set receiver.CreateSettings,
 	cfg component.Config,
@@ -83,7 +99,7 @@ func createMetrics(
 ) (receiver.Metrics, error) {
 	oCfg := cfg.(*Config)
 	r, err := receivers.GetOrAdd(oCfg, func() (*otelArrowReceiver, error) {
-		return newOTelArrowReceiver(oCfg, set)
+		return newOTelArrowReceiver(oCfg, set, fo)
 	})
 	if err != nil {
 		return nil, err
@@ -94,7 +110,7 @@ func createMetrics(
 }
 
 // createLog creates a log receiver based on provided config.
-func createLog(
+func (fo factoryOptions) createLog(
 	_ context.Context,
 	set receiver.CreateSettings,
 	cfg component.Config,
@@ -102,7 +118,7 @@ func createLog(
 ) (receiver.Logs, error) {
 	oCfg := cfg.(*Config)
 	r, err := receivers.GetOrAdd(oCfg, func() (*otelArrowReceiver, error) {
-		return newOTelArrowReceiver(oCfg, set)
+		return newOTelArrowReceiver(oCfg, set, fo)
 	})
 	if err != nil {
 		return nil, err
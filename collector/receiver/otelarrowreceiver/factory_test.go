@@ -7,6 +7,7 @@ import (
 	"context"
 	"testing"
 
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
 	"github.com/open-telemetry/otel-arrow/collector/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -40,6 +41,31 @@ func TestCreateReceiver(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCreateReceiverWithOptions(t *testing.T) {
+	var calls []string
+	factory := NewFactoryWithOptions(
+		WithBatchStatusInterceptor(BatchStatusInterceptorFunc(func(context.Context, *arrowpb.BatchStatus, error) {
+			calls = append(calls, "first")
+		})),
+		WithBatchStatusInterceptor(BatchStatusInterceptorFunc(func(context.Context, *arrowpb.BatchStatus, error) {
+			calls = append(calls, "second")
+		})),
+	)
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.GRPC.NetAddr.Endpoint = testutil.GetAvailableLocalAddress(t)
+
+	tReceiver, err := factory.CreateTracesReceiver(context.Background(), receivertest.NewNopCreateSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, tReceiver.Shutdown(context.Background())) }()
+
+	r, err := receivers.GetOrAdd(cfg, nil)
+	require.NoError(t, err)
+	for _, ic := range r.Unwrap().options.interceptors {
+		ic.InterceptBatchStatus(context.Background(), &arrowpb.BatchStatus{}, nil)
+	}
+	require.Equal(t, []string{"first", "second"}, calls)
+}
+
 func TestCreateTracesReceiver(t *testing.T) {
 	factory := NewFactory()
 	defaultGRPCSettings := configgrpc.ServerConfig{
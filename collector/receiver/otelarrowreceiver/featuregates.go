@@ -0,0 +1,15 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowreceiver // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver"
+
+import "go.opentelemetry.io/collector/featuregate"
+
+// passthroughGate enables passthrough in every OTel-Arrow receiver,
+// as if each were configured with `passthrough: true`.
+var passthroughGate = featuregate.GlobalRegistry().MustRegister(
+	"receiver.otelarrow.Passthrough",
+	featuregate.StageAlpha,
+	featuregate.WithRegisterDescription("When enabled, OTel-Arrow receivers decode batches into marshaled OTLP requests when the next consumer accepts them, regardless of their passthrough setting."),
+	featuregate.WithRegisterFromVersion("v0.24.0"),
+)
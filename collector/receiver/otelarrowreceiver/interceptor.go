@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowreceiver // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver"
+
+import (
+	"context"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"google.golang.org/grpc/stats"
+)
+
+// BatchStatusInterceptor observes, and may modify, every BatchStatus
+// before the receiver writes it to an Arrow stream, e.g., to report
+// a tenant's remaining quota in the status message.
+type BatchStatusInterceptor interface {
+	// InterceptBatchStatus is called with the context of the
+	// stream, which carries the client information of the
+	// exporter, the response, and the error that the pipeline
+	// returned for the batch, nil for success.  It is called
+	// from the stream's sending goroutine, so it must not block.
+	InterceptBatchStatus(ctx context.Context, status *arrowpb.BatchStatus, err error)
+}
+
+// BatchStatusInterceptorFunc is a BatchStatusInterceptor function.
+type BatchStatusInterceptorFunc func(ctx context.Context, status *arrowpb.BatchStatus, err error)
+
+// InterceptBatchStatus calls f.
+func (f BatchStatusInterceptorFunc) InterceptBatchStatus(ctx context.Context, status *arrowpb.BatchStatus, err error) {
+	f(ctx, status, err)
+}
+
+// FactoryOption is an option of NewFactoryWithOptions.
+type FactoryOption func(*factoryOptions)
+
+type factoryOptions struct {
+	interceptors  []BatchStatusInterceptor
+	statsHandlers []stats.Handler
+}
+
+// WithBatchStatusInterceptor adds an interceptor of the responses
+// of the receivers created by the factory.  Interceptors are called
+// in the order they are added.
+func WithBatchStatusInterceptor(ic BatchStatusInterceptor) FactoryOption {
+	return func(fo *factoryOptions) {
+		fo.interceptors = append(fo.interceptors, ic)
+	}
+}
+
+// WithStatsHandler adds a gRPC stats handler to the servers of the
+// receivers created by the factory, after the handler of the network
+// statistics, e.g., to account for the bytes received from each
+// peer.  Note that the sizes of Arrow stream messages are those of
+// the compressed batches.
+func WithStatsHandler(h stats.Handler) FactoryOption {
+	return func(fo *factoryOptions) {
+		fo.statsHandlers = append(fo.statsHandlers, h)
+	}
+}
@@ -14,10 +14,16 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+	"github.com/open-telemetry/otel-arrow/collector/capability"
 	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/collector/redact"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
 	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
 	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configgrpc"
@@ -30,6 +36,7 @@ import (
 	"go.opentelemetry.io/collector/receiver"
 	"go.opentelemetry.io/collector/receiver/receiverhelper"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	otelcodes "go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
@@ -50,13 +57,39 @@ const (
 	streamFormat        = "arrow"
 	hpackMaxDynamicSize = 4096
 	scopeName           = "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver"
+
+	// minPendingResponses and maxPendingResponses bound the
+	// number of responses of a stream that its consumers may
+	// complete before the stream sends them, see
+	// pendingResponses.
+	minPendingResponses = 4
+	maxPendingResponses = 64
+
+	// hpackTableReset is the first byte of a header block that
+	// begins with a dynamic table size update to zero, which
+	// exporters send after a batch is rejected with
+	// InvalidArgument (RFC 7541, section 6.3).
+	hpackTableReset = 0x20
 )
 
+// pendingResponses returns the capacity of a stream's channel of
+// pending responses.  Batches are consumed concurrently, so that the
+// capacity scales with the processors that may run Go code, within
+// bounds that keep small agents from stalling their consumers and
+// large gateways from buffering responses without bound.
+func pendingResponses() int {
+	return min(max(runtime.GOMAXPROCS(0), minPendingResponses), maxPendingResponses)
+}
+
 var (
 	ErrNoMetricsConsumer   = fmt.Errorf("no metrics consumer")
 	ErrNoLogsConsumer      = fmt.Errorf("no logs consumer")
 	ErrNoTracesConsumer    = fmt.Errorf("no traces consumer")
 	ErrUnrecognizedPayload = consumererror.NewPermanent(fmt.Errorf("unrecognized OTel-Arrow payload"))
+
+	// errStaleHeaders is the error of a header block encoded
+	// before the exporter reset its dynamic table, see reset.
+	errStaleHeaders = errors.New("header block refers to the dynamic table before its reset")
 )
 
 type Consumers interface {
@@ -79,11 +112,77 @@ type Receiver struct {
 	authServer           auth.Server
 	newConsumer          func() arrowRecord.ConsumerAPI
 	netReporter          netstats.Interface
+	status               *arrowzpages.Instance
 	recvInFlightBytes    metric.Int64UpDownCounter
 	recvInFlightItems    metric.Int64UpDownCounter
 	recvInFlightRequests metric.Int64UpDownCounter
+	checksumFailures     metric.Int64Counter
+	peerBatches          metric.Int64Counter
 	boundedQueue         *admission.BoundedQueue
 	inFlightWG           sync.WaitGroup
+
+	// zstdDictIDs are the Zstd dictionaries that streams may
+	// declare, see checkZstdDictionary().
+	zstdDictIDs map[uint32]bool
+
+	// passthrough enables consuming batches as marshaled OTLP
+	// requests, see WithPassthrough().
+	passthrough bool
+
+	// orderedResponses sends the responses of each stream in the
+	// order its batches were received, see WithOrderedResponses().
+	orderedResponses bool
+
+	// dedup holds the idempotency keys of recently consumed
+	// batches, see WithDeduplication(), may be nil.
+	dedup *dedupWindow
+
+	// streamInFlightLimit is the number of uncompressed bytes
+	// each stream may be consuming before it stops receiving,
+	// see WithStreamInFlightLimit().
+	streamInFlightLimit int64
+
+	// streamBatchLimit is the number of batches each stream may
+	// be consuming before it stops receiving, see
+	// WithMaxConcurrentBatchesPerStream().
+	streamBatchLimit int
+
+	// streamWorkers is the number of goroutines delivering the
+	// batches of each stream, see WithStreamWorkers().
+	streamWorkers int
+
+	// receiverID is included in every BatchStatus, see
+	// WithReceiverID().
+	receiverID string
+
+	// creditLimit is the largest flow control credit window
+	// granted to each stream, see WithFlowControlCredits(), may
+	// be nil.
+	creditLimit *creditWindow
+
+	// capabilities are declared to exporters, see
+	// WithCapabilities(), may be nil.
+	capabilities *capability.Set
+
+	// interceptors modify every BatchStatus before it is sent,
+	// see WithBatchStatusInterceptor().
+	interceptors []BatchStatusInterceptor
+
+	// metadataFilter restricts the client metadata of batches,
+	// see WithMetadataFilter(), may be nil.
+	metadataFilter *metadataFilter
+
+	// redactErrors removes user data from statuses and stream logs,
+	// see WithRedaction().
+	redactErrors bool
+
+	// auditLog records rejected batches, see WithAuditLog(), may
+	// be nil.
+	auditLog *AuditLog
+
+	// peerInstanceMetrics counts the batches of each exporter's
+	// collector, see WithPeerInstanceMetrics().
+	peerInstanceMetrics bool
 }
 
 // New creates a new Receiver reference.
@@ -96,6 +195,8 @@ func New(
 	newConsumer func() arrowRecord.ConsumerAPI,
 	bq *admission.BoundedQueue,
 	netReporter netstats.Interface,
+	status *arrowzpages.Instance,
+	opts ...Option,
 ) (*Receiver, error) {
 	tracer := set.TelemetrySettings.TracerProvider.Tracer("otel-arrow-receiver")
 	var errors, err error
@@ -108,8 +209,15 @@ func New(
 		newConsumer:  newConsumer,
 		gsettings:    gsettings,
 		netReporter:  netReporter,
+		status:       status,
 		boundedQueue: bq,
 	}
+	for _, opt := range opts {
+		opt(recv)
+	}
+	if recv.redactErrors {
+		recv.telemetry.Logger = redact.Logger(recv.telemetry.Logger)
+	}
 
 	meter := recv.telemetry.MeterProvider.Meter(scopeName)
 	recv.recvInFlightBytes, err = meter.Int64UpDownCounter(
@@ -131,6 +239,20 @@ func New(
 	)
 	errors = multierr.Append(errors, err)
 
+	recv.checksumFailures, err = meter.Int64Counter(
+		"otel_arrow_receiver_checksum_failures",
+		metric.WithDescription("Number of batches whose payloads did not match their checksum"),
+	)
+	errors = multierr.Append(errors, err)
+
+	if recv.peerInstanceMetrics {
+		recv.peerBatches, err = meter.Int64Counter(
+			"otel_arrow_receiver_peer_batches",
+			metric.WithDescription("Number of batches received from each exporter's collector"),
+		)
+		errors = multierr.Append(errors, err)
+	}
+
 	if errors != nil {
 		return nil, errors
 	}
@@ -141,12 +263,17 @@ func New(
 // headerReceiver contains the state necessary to decode per-request metadata
 // from an arrow stream.
 type headerReceiver struct {
-	// decoder maintains state across the stream.
+	// decoder maintains state across the stream, so that the
+	// exporter's hpack encoder can refer to header fields of
+	// earlier batches in its dynamic table.
 	decoder *hpack.Decoder
 
 	// includeMetadata as configured by gRPC settings.
 	includeMetadata bool
 
+	// filter restricts the included metadata, may be nil.
+	filter *metadataFilter
+
 	// hasAuthServer indicates that headers must be produced
 	// independent of includeMetadata.
 	hasAuthServer bool
@@ -164,11 +291,16 @@ type headerReceiver struct {
 
 	// tmpHdrs is used by the decoder's emit function during Write.
 	tmpHdrs map[string][]string
+
+	// resyncing is set by reset until a header block begins with
+	// the exporter's reset of its dynamic table.
+	resyncing bool
 }
 
-func newHeaderReceiver(streamCtx context.Context, as auth.Server, includeMetadata bool) *headerReceiver {
+func newHeaderReceiver(streamCtx context.Context, as auth.Server, includeMetadata bool, filter *metadataFilter) *headerReceiver {
 	hr := &headerReceiver{
 		includeMetadata: includeMetadata,
+		filter:          filter,
 		hasAuthServer:   as != nil,
 		connInfo:        client.FromContext(streamCtx),
 	}
@@ -189,6 +321,20 @@ func newHeaderReceiver(streamCtx context.Context, as auth.Server, includeMetadat
 	return hr
 }
 
+// reset replaces the decoder with one whose dynamic table is empty,
+// after a header block failed to decode.  The failed block may have
+// left the dynamic table partially updated, unlike the exporter's.
+// Exporters reset their encoder when a batch is rejected with
+// InvalidArgument, beginning the next header block with a dynamic
+// table size update to zero, after which the tables agree again.
+// The blocks received before then were encoded against the
+// exporter's old table, and fail with errStaleHeaders undecoded.
+func (h *headerReceiver) reset() {
+	h.decoder = hpack.NewDecoder(hpackMaxDynamicSize, h.tmpHdrsAppend)
+	h.tmpHdrs = nil
+	h.resyncing = true
+}
+
 // combineHeaders calculates per-request Metadata by combining the stream's
 // client.Info with additional key:values associated with the arrow batch.
 func (h *headerReceiver) combineHeaders(ctx context.Context, hdrsBytes []byte) (context.Context, map[string][]string, error) {
@@ -200,6 +346,13 @@ func (h *headerReceiver) combineHeaders(ctx context.Context, hdrsBytes []byte) (
 		return h.newContext(ctx, h.streamHdrs), h.streamHdrs, nil
 	}
 
+	if h.resyncing {
+		if hdrsBytes[0] != hpackTableReset {
+			return ctx, nil, errStaleHeaders
+		}
+		h.resyncing = false
+	}
+
 	// Note that we will parse the headers even if they are not
 	// used, to check for validity and/or trace context.  Also
 	// note this code was once optimized to avoid the following
@@ -214,6 +367,13 @@ func (h *headerReceiver) combineHeaders(ctx context.Context, hdrsBytes []byte) (
 	if _, err := h.decoder.Write(hdrsBytes); err != nil {
 		return ctx, nil, err
 	}
+	// Each batch carries one complete header block.  Close()
+	// checks this and readies the decoder for the next block,
+	// which may begin with a dynamic table size update, while
+	// the dynamic table is retained for the life of the stream.
+	if err := h.decoder.Close(); err != nil {
+		return ctx, nil, err
+	}
 
 	// Get the global propagator, to extract context.  When there
 	// are no fields, it's a no-op propagator implementation and
@@ -276,7 +436,7 @@ func (h *headerReceiver) newContext(ctx context.Context, hdrs map[string][]strin
 	// per-request metadata from the Arrow batch.
 	var md client.Metadata
 	if h.includeMetadata && hdrs != nil {
-		md = client.NewMetadata(hdrs)
+		md = client.NewMetadata(h.filter.apply(hdrs))
 	}
 	return client.NewContext(ctx, client.Info{
 		Addr:     h.connInfo.Addr,
@@ -285,8 +445,9 @@ func (h *headerReceiver) newContext(ctx context.Context, hdrs map[string][]strin
 	})
 }
 
-// logStreamError decides how to log an error.
-func (r *Receiver) logStreamError(err error, where string) {
+// logStreamError decides how to log an error that ends a stream, to
+// the stream's logger.
+func logStreamError(logger *zap.Logger, err error, where string) {
 	var code codes.Code
 	var msg string
 	// gRPC tends to supply status-wrapped errors, so we always
@@ -306,9 +467,9 @@ func (r *Receiver) logStreamError(err error, where string) {
 	}
 
 	if code == codes.Canceled {
-		r.telemetry.Logger.Debug("arrow stream shutdown", zap.String("message", msg))
+		logger.Debug("arrow stream shutdown", zap.String("message", msg))
 	} else {
-		r.telemetry.Logger.Error("arrow stream error", zap.String("message", msg), zap.Int("code", int(code)), zap.String("where", where))
+		streamevents.Failed(logger, code, msg, zap.String("where", where))
 	}
 }
 
@@ -342,6 +503,7 @@ type anyStreamServer interface {
 
 type batchResp struct {
 	id  int64
+	seq uint64
 	err error
 }
 
@@ -360,11 +522,61 @@ func (r *Receiver) recoverErr(retErr *error) {
 
 func (r *Receiver) anyStream(serverStream anyStreamServer, method string) (retErr error) {
 	streamCtx := serverStream.Context()
+	if err := r.checkZstdDictionary(streamCtx); err != nil {
+		r.telemetry.Logger.Debug("arrow stream rejected", zap.Error(err))
+		return err
+	}
+	agreed, err := r.negotiate(serverStream)
+	if err != nil {
+		r.telemetry.Logger.Debug("arrow stream rejected", zap.Error(err))
+		return err
+	}
+	streamID := streamevents.NewStreamID()
+	logger := streamevents.With(r.telemetry.Logger, streamID, method)
+	peer := newStreamPeer(streamCtx)
+	logger = logger.With(peer.logFields()...)
 	ac := r.newConsumer()
 
+	started := time.Now()
+	r.status.StreamStarted(streamID, method)
+	if peer.streamID != "" {
+		r.status.SetStreamParam(streamID, streamevents.PeerStreamIDKey, peer.streamID)
+	}
+	if peer.instanceID != "" {
+		r.status.SetStreamParam(streamID, streamevents.PeerInstanceIDKey, peer.instanceID)
+	}
+	if len(peer.labels) != 0 {
+		r.status.SetStreamParam(streamID, "peer_labels", peer.labelString())
+	}
+	for _, id := range metadata.ValueFromIncomingContext(streamCtx, zstddict.Header) {
+		r.status.SetStreamParam(streamID, "zstd_dictionary", id)
+	}
+	if agreed.Versions != nil {
+		r.status.SetStreamParam(streamID, "capabilities", agreed.String())
+	}
+	streamevents.Started(logger)
+	defer func() {
+		// Canceled indicates an ordinary shutdown.
+		endErr := retErr
+		if status.Code(endErr) == codes.Canceled {
+			endErr = nil
+		}
+		r.status.StreamEnded(streamID, endErr)
+		if endErr == nil {
+			streamevents.Rotated(logger, started, streamevents.ReasonClosed)
+		}
+	}()
+	if r.redactErrors {
+		// The status that ends the stream is returned to the
+		// exporter.
+		defer func() {
+			retErr = redact.Error(retErr)
+		}()
+	}
+
 	defer func() {
 		if err := ac.Close(); err != nil {
-			r.telemetry.Logger.Error("arrow stream close", zap.Error(err))
+			logger.Error("arrow stream close", zap.Error(err))
 		}
 	}()
 	defer r.recoverErr(&retErr)
@@ -377,7 +589,7 @@ func (r *Receiver) anyStream(serverStream anyStreamServer, method string) (retEr
 	// streamErrCh returns up to two errors from the sender and
 	// receiver threads started below.
 	streamErrCh := make(chan error, 2)
-	pendingCh := make(chan batchResp, runtime.NumCPU())
+	pendingCh := make(chan batchResp, pendingResponses())
 
 	// wg is used to ensure this thread returns after both
 	// sender and recevier threads return.
@@ -395,7 +607,7 @@ func (r *Receiver) anyStream(serverStream anyStreamServer, method string) (retEr
 		defer wg.Done()
 		defer r.recoverErr(&err)
 		defer r.inFlightWG.Done()
-		err = r.srvReceiveLoop(doneCtx, serverStream, pendingCh, method, ac)
+		err = r.srvReceiveLoop(doneCtx, serverStream, pendingCh, method, streamID, peer, logger, ac)
 		streamErrCh <- err
 	}()
 
@@ -403,7 +615,7 @@ func (r *Receiver) anyStream(serverStream anyStreamServer, method string) (retEr
 		var err error
 		defer wg.Done()
 		defer r.recoverErr(&err)
-		err = r.srvSendLoop(doneCtx, serverStream, pendingCh)
+		err = r.srvSendLoop(doneCtx, serverStream, pendingCh, method, streamID, logger)
 		streamErrCh <- err
 	}()
 
@@ -419,15 +631,19 @@ func (r *Receiver) anyStream(serverStream anyStreamServer, method string) (retEr
 	}
 }
 
-func (r *Receiver) newInFlightData(ctx context.Context, method string, batchID int64, pendingCh chan<- batchResp) (context.Context, *inFlightData) {
-	ctx, span := r.tracer.Start(ctx, "otel_arrow_stream_inflight")
+func (r *Receiver) newInFlightData(ctx context.Context, method, streamID string, peer streamPeer, logger *zap.Logger, batchID int64, seq uint64, pendingCh chan<- batchResp) (context.Context, *inFlightData) {
+	attrs := append([]attribute.KeyValue{attribute.String(streamevents.StreamIDKey, streamID)}, peer.attributes()...)
+	ctx, span := r.tracer.Start(ctx, "otel_arrow_stream_inflight", trace.WithAttributes(attrs...))
 
 	r.inFlightWG.Add(1)
 	r.recvInFlightRequests.Add(ctx, 1)
 	id := &inFlightData{
 		Receiver:  r,
 		method:    method,
+		streamID:  streamID,
+		logger:    logger,
 		batchID:   batchID,
+		seq:       seq,
 		pendingCh: pendingCh,
 		span:      span,
 	}
@@ -441,10 +657,21 @@ type inFlightData struct {
 	*Receiver
 
 	method    string
+	streamID  string
+	logger    *zap.Logger // the stream's logger
 	batchID   int64
+	seq       uint64 // position of the batch in its stream
 	pendingCh chan<- batchResp
 	span      trace.Span
 
+	// idempotencyKey is remembered when the batch is consumed
+	// successfully, see WithDeduplication() and dedupKey().
+	idempotencyKey string
+
+	// flow counts the batch and its uncompSize against the
+	// stream's in-flight limits, may be nil.
+	flow *streamFlow
+
 	// refs counts the number of goroutines holding this object.
 	// initially the recvOne() body, on success the
 	// consumeAndRespond() function.
@@ -453,6 +680,11 @@ type inFlightData struct {
 	numAcquired int64 // how many bytes held in the semaphore
 	numItems    int   // how many items
 	uncompSize  int64 // uncompressed data size
+
+	// received is set when the batch was received, and
+	// encodedSize is its size when audited.
+	received    bool
+	encodedSize int64
 }
 
 func (id *inFlightData) recvDone(ctx context.Context, recvErrPtr *error) {
@@ -460,8 +692,11 @@ func (id *inFlightData) recvDone(ctx context.Context, recvErrPtr *error) {
 
 	if retErr != nil {
 		// logStreamError because this response will break the stream.
-		id.logStreamError(retErr, "recv")
+		logStreamError(id.logger, retErr, "recv")
 		id.span.SetStatus(otelcodes.Error, retErr.Error())
+		if id.received {
+			id.audit(ctx, retErr)
+		}
 	}
 
 	id.anyDone(ctx)
@@ -472,17 +707,24 @@ func (id *inFlightData) consumeDone(ctx context.Context, consumeErrPtr *error) {
 
 	if retErr != nil {
 		// debug-level because the error was external from the pipeline.
-		id.telemetry.Logger.Debug("otel-arrow consume", zap.Error(retErr))
+		id.logger.Debug("otel-arrow consume", zap.Error(retErr))
 		id.span.SetStatus(otelcodes.Error, retErr.Error())
+	} else {
+		id.dedup.add(id.idempotencyKey)
 	}
 
-	id.replyToCaller(retErr)
+	id.replyToCaller(ctx, retErr)
 	id.anyDone(ctx)
 }
 
-func (id *inFlightData) replyToCaller(callerErr error) {
+// replyToCaller sends the response of the batch, auditing errors.
+func (id *inFlightData) replyToCaller(ctx context.Context, callerErr error) {
+	if callerErr != nil {
+		id.audit(ctx, callerErr)
+	}
 	id.pendingCh <- batchResp{
 		id:  id.batchID,
+		seq: id.seq,
 		err: callerErr,
 	}
 }
@@ -505,6 +747,7 @@ func (id *inFlightData) anyDone(ctx context.Context) {
 	if id.uncompSize != 0 {
 		id.recvInFlightBytes.Add(ctx, -id.uncompSize)
 	}
+	id.flow.done(id.uncompSize)
 	if id.numItems != 0 {
 		id.recvInFlightItems.Add(ctx, int64(-id.numItems))
 	}
@@ -515,6 +758,7 @@ func (id *inFlightData) anyDone(ctx context.Context) {
 	// is instrumented this way.
 	var sized netstats.SizesStruct
 	sized.Method = id.method
+	sized.Stream = id.streamID
 	sized.Length = id.uncompSize
 	id.netReporter.CountReceive(ctx, sized)
 
@@ -539,15 +783,16 @@ func (id *inFlightData) anyDone(ctx context.Context) {
 // This handles constructing an inFlightData object, which itself
 // tracks everything that needs to be used by instrumention when the
 // batch finishes.
-func (r *Receiver) recvOne(streamCtx context.Context, serverStream anyStreamServer, hrcv *headerReceiver, pendingCh chan<- batchResp, method string, ac arrowRecord.ConsumerAPI) (retErr error) {
+func (r *Receiver) recvOne(streamCtx context.Context, serverStream anyStreamServer, hrcv *headerReceiver, pendingCh chan<- batchResp, method, streamID string, peer streamPeer, logger *zap.Logger, seq uint64, flow *streamFlow, workers *streamWorkers, schemas *arrowzpages.SchemaVersion, ac arrowRecord.ConsumerAPI) (retErr error) {
 
 	// Receive a batch corresponding with one ptrace.Traces, pmetric.Metrics,
 	// or plog.Logs item.
 	req, err := serverStream.Recv()
 
 	// inflightCtx is carried through into consumeAndProcess on the success path.
-	inflightCtx, flight := r.newInFlightData(streamCtx, method, req.GetBatchId(), pendingCh)
-	defer flight.recvDone(inflightCtx, &retErr)
+	inflightCtx, flight := r.newInFlightData(streamCtx, method, streamID, peer, logger, req.GetBatchId(), seq, pendingCh)
+	// inflightCtx gains the client's metadata and auth below.
+	defer func() { flight.recvDone(inflightCtx, &retErr) }()
 
 	// this span is a child of the inflight, covering the Arrow decode, Auth, etc.
 	_, span := r.tracer.Start(inflightCtx, "otel_arrow_stream_recv")
@@ -562,12 +807,33 @@ func (r *Receiver) recvOne(streamCtx context.Context, serverStream anyStreamServ
 		// Note: err is directly from gRPC, should already have status.
 		return err
 	}
+	schemas.Observe(req)
+	flight.received = true
+	if r.auditLog != nil {
+		flight.encodedSize = int64(proto.Size(req))
+	}
 
 	// Check for optional headers and set the incoming context.
 	inflightCtx, authHdrs, err := hrcv.combineHeaders(inflightCtx, req.GetHeaders())
 	if err != nil {
-		// Failing to parse the incoming headers breaks the stream.
-		return status.Errorf(codes.Internal, "arrow metadata error: %v", err)
+		// Failing to parse the incoming headers rejects only
+		// this batch.  Its payloads are decoded anyway, since
+		// the Arrow state of the stream depends on every batch.
+		// A batch encoded before the exporter reset its table
+		// was not at fault, and is retried as Unavailable.
+		code := codes.Unavailable
+		if !errors.Is(err, errStaleHeaders) {
+			code = codes.InvalidArgument
+			hrcv.reset()
+		}
+		decErr, data, _, _ := r.consumeBatch(ac, req)
+		if decErr != nil {
+			return status.Errorf(codes.Internal, "otel-arrow decode: %v", decErr)
+		}
+		releaseData(ac, data)
+		logger.Debug("arrow metadata error", zap.Error(err))
+		flight.replyToCaller(inflightCtx, status.Errorf(code, "arrow metadata error: %v", err))
+		return nil
 	}
 
 	// Authorize the request, if configured, prior to acquiring resources.
@@ -575,11 +841,27 @@ func (r *Receiver) recvOne(streamCtx context.Context, serverStream anyStreamServ
 		var authErr error
 		inflightCtx, authErr = r.authServer.Authenticate(inflightCtx, authHdrs)
 		if authErr != nil {
-			flight.replyToCaller(status.Error(codes.Unauthenticated, authErr.Error()))
+			flight.replyToCaller(inflightCtx, status.Error(codes.Unauthenticated, authErr.Error()))
 			return nil
 		}
 	}
 
+	// A batch without payloads is an exporter's heartbeat, which
+	// is answered without consuming anything.
+	if len(req.GetArrowPayloads()) == 0 {
+		flight.replyToCaller(inflightCtx, nil)
+		return nil
+	}
+
+	// A batch corrupted on the way cannot be decoded safely, since
+	// its payloads may update the Arrow state of the stream.
+	// Ending the stream resets the exporter's producer, which
+	// retries the batch on a new stream.
+	if sums := authHdrs[checksumHeader]; len(sums) != 0 && sums[0] != batchChecksum(req) {
+		r.checksumFailures.Add(inflightCtx, 1)
+		return status.Errorf(codes.DataLoss, "otel-arrow checksum mismatch: batch %d", req.GetBatchId())
+	}
+
 	var prevAcquiredBytes int64
 	uncompSizeHeaderStr, uncompSizeHeaderFound := authHdrs["otlp-pdata-size"]
 	if !uncompSizeHeaderFound || len(uncompSizeHeaderStr) == 0 {
@@ -603,18 +885,36 @@ func (r *Receiver) recvOne(streamCtx context.Context, serverStream anyStreamServ
 	}
 	flight.numAcquired = prevAcquiredBytes
 
+	// The exporter announces the Arrow streams it reset, whose
+	// state the consumer releases after this batch.
+	if closed := req.GetClosedSchemaIds(); len(closed) != 0 {
+		logger.Debug("arrow schema reset",
+			zap.Int64("batch_id", req.GetBatchId()),
+			zap.Strings("closed_schema_ids", closed),
+		)
+	}
+
 	err, data, numItems, uncompSize := r.consumeBatch(ac, req)
 
 	if err != nil {
 		if errors.Is(err, arrowRecord.ErrConsumerMemoryLimit) {
 			return status.Errorf(codes.ResourceExhausted, "otel-arrow decode: %v", err)
+		} else if errors.Is(err, arrowRecord.ErrSchemaReset) {
+			// Ending the stream resets the exporter's producer.
+			return status.Errorf(codes.Unavailable, "otel-arrow decode: %v", err)
 		} else {
 			return status.Errorf(codes.Internal, "otel-arrow decode: %v", err)
 		}
 	}
 
+	if r.peerBatches != nil {
+		r.peerBatches.Add(inflightCtx, 1, peer.metricAttrs)
+	}
+
 	flight.uncompSize = uncompSize
 	flight.numItems = numItems
+	flight.flow = flow
+	flow.add(uncompSize)
 
 	r.recvInFlightBytes.Add(inflightCtx, uncompSize)
 	r.recvInFlightItems.Add(inflightCtx, int64(numItems))
@@ -623,14 +923,30 @@ func (r *Receiver) recvOne(streamCtx context.Context, serverStream anyStreamServ
 
 	flight.numAcquired = numAcquired
 	if err != nil {
+		releaseData(ac, data)
 		return status.Errorf(codes.ResourceExhausted, "otel-arrow bounded queue re-acquire: %v", err)
 	}
 
+	// A retried batch that was already consumed is acknowledged.
+	// It was decoded above, since the Arrow state of the stream
+	// depends on every batch.
+	if keys := authHdrs[idempotencyKeyHeader]; r.dedup != nil && len(keys) != 0 && keys[0] != "" {
+		flight.idempotencyKey = dedupKey(client.FromContext(inflightCtx), keys[0])
+		if r.dedup.contains(flight.idempotencyKey) {
+			logger.Debug("arrow duplicate batch", zap.String("key", keys[0]))
+			releaseData(ac, data)
+			flight.replyToCaller(inflightCtx, nil)
+			return nil
+		}
+	}
+
 	// Recognize that the request is still in-flight via consumeAndRespond()
 	flight.refs.Add(1)
 
 	// consumeAndRespond consumes the data and returns control to the sender loop.
-	go r.consumeAndRespond(inflightCtx, data, flight)
+	workers.run(streamCtx, func() {
+		r.consumeAndRespond(inflightCtx, data, flight)
+	})
 
 	return nil
 }
@@ -650,14 +966,26 @@ func (r *Receiver) consumeAndRespond(ctx context.Context, data any, flight *inFl
 }
 
 // srvReceiveLoop repeatedly receives one batch of data.
-func (r *Receiver) srvReceiveLoop(ctx context.Context, serverStream anyStreamServer, pendingCh chan<- batchResp, method string, ac arrowRecord.ConsumerAPI) (retErr error) {
-	hrcv := newHeaderReceiver(ctx, r.authServer, r.gsettings.IncludeMetadata)
-	for {
+func (r *Receiver) srvReceiveLoop(ctx context.Context, serverStream anyStreamServer, pendingCh chan<- batchResp, method, streamID string, peer streamPeer, logger *zap.Logger, ac arrowRecord.ConsumerAPI) (retErr error) {
+	hrcv := newHeaderReceiver(ctx, r.authServer, r.gsettings.IncludeMetadata, r.metadataFilter)
+	flow := r.newStreamFlow()
+	workers := r.newStreamWorkers()
+	defer workers.stop()
+	schemas := r.status.NewSchemaVersion(streamID)
+	// A failure to receive a batch ends the stream, so that the
+	// sequence numbers of replied-to batches have no gaps.
+	for seq := uint64(0); ; seq++ {
 		select {
 		case <-ctx.Done():
 			return status.Error(codes.Canceled, "server stream shutdown")
 		default:
-			if err := r.recvOne(ctx, serverStream, hrcv, pendingCh, method, ac); err != nil {
+			// Receive nothing more while the stream's
+			// consumers are behind, see WithStreamInFlightLimit()
+			// and WithMaxConcurrentBatchesPerStream().
+			if err := flow.wait(ctx); err != nil {
+				return status.Error(codes.Canceled, "server stream shutdown")
+			}
+			if err := r.recvOne(ctx, serverStream, hrcv, pendingCh, method, streamID, peer, logger, seq, flow, workers, schemas, ac); err != nil {
 				return err
 			}
 		}
@@ -665,11 +993,12 @@ func (r *Receiver) srvReceiveLoop(ctx context.Context, serverStream anyStreamSer
 }
 
 // srvReceiveLoop repeatedly sends one batch data response.
-func (r *Receiver) sendOne(serverStream anyStreamServer, resp batchResp) error {
+func (r *Receiver) sendOne(serverStream anyStreamServer, method, streamID string, logger *zap.Logger, credits *creditWindow, resp batchResp) error {
 	// Note: Statuses can be batched, but we do not take
 	// advantage of this feature.
 	bs := &arrowpb.BatchStatus{
-		BatchId: resp.id,
+		BatchId:    resp.id,
+		ReceiverId: r.receiverID,
 	}
 	if resp.err == nil {
 		bs.StatusCode = arrowpb.StatusCode_OK
@@ -690,27 +1019,45 @@ func (r *Receiver) sendOne(serverStream anyStreamServer, resp batchResp) error {
 			switch {
 			case consumererror.IsPermanent(resp.err):
 				// Some kind of pipeline error, somewhere downstream.
-				r.telemetry.Logger.Error("arrow data error", zap.Error(resp.err))
+				logger.Error("arrow data error", zap.Error(resp.err))
 				bs.StatusCode = arrowpb.StatusCode_INVALID_ARGUMENT
 			default:
 				// Probably a pipeline error, retryable.
-				r.telemetry.Logger.Debug("arrow consumer error", zap.Error(resp.err))
+				logger.Debug("arrow consumer error", zap.Error(resp.err))
 				bs.StatusCode = arrowpb.StatusCode_UNAVAILABLE
 			}
 		}
 	}
 
+	if r.redactErrors {
+		bs.StatusMessage = redact.Message(bs.StatusMessage)
+	}
+	credits.update(bs)
+
+	for _, ic := range r.interceptors {
+		ic(serverStream.Context(), bs, resp.err)
+	}
+
 	if err := serverStream.Send(bs); err != nil {
 		// logStreamError because this response will break the stream.
-		r.logStreamError(err, "send")
+		logStreamError(logger, err, "send")
 		return err
 	}
 
+	// The netstats code does not see the uncompressed size of
+	// the acknowledgements, so we instrument it directly here.
+	// Note that proto.Size() caches the size in the message, so
+	// this follows Send().
+	var sized netstats.SizesStruct
+	sized.Method = method
+	sized.Stream = streamID
+	sized.Length = int64(proto.Size(bs))
+	r.netReporter.CountSend(serverStream.Context(), sized)
+
 	return nil
 }
 
-func (r *Receiver) flushSender(serverStream anyStreamServer, pendingCh <-chan batchResp) error {
-	var err error
+func (r *Receiver) flushSender(pendingCh <-chan batchResp, order *responseOrder, sendFunc func(batchResp) error) error {
 	// wait for all in flight requests to be successfully
 	// processed or fail.  this implies waiting for the receiver
 	// loop to exit, as it holds one additional wait count to
@@ -720,24 +1067,28 @@ func (r *Receiver) flushSender(serverStream anyStreamServer, pendingCh <-chan ba
 	for {
 		select {
 		case resp := <-pendingCh:
-			err = r.sendOne(serverStream, resp)
-			if err != nil {
+			if err := order.send(resp, sendFunc); err != nil {
 				return err
 			}
 		default:
 			// Currently nothing left in pendingCh.
-			return nil
+			return order.flush(sendFunc)
 		}
 	}
 }
 
-func (r *Receiver) srvSendLoop(ctx context.Context, serverStream anyStreamServer, pendingCh <-chan batchResp) error {
+func (r *Receiver) srvSendLoop(ctx context.Context, serverStream anyStreamServer, pendingCh <-chan batchResp, method, streamID string, logger *zap.Logger) error {
+	order := r.newResponseOrder()
+	credits := r.newCreditWindow()
+	sendFunc := func(resp batchResp) error {
+		return r.sendOne(serverStream, method, streamID, logger, credits, resp)
+	}
 	for {
 		select {
 		case <-ctx.Done():
-			return r.flushSender(serverStream, pendingCh)
+			return r.flushSender(pendingCh, order, sendFunc)
 		case resp := <-pendingCh:
-			if err := r.sendOne(serverStream, resp); err != nil {
+			if err := order.send(resp, sendFunc); err != nil {
 				return err
 			}
 		}
@@ -753,6 +1104,9 @@ func (r *Receiver) consumeBatch(arrowConsumer arrowRecord.ConsumerAPI, records *
 	if len(payloads) == 0 {
 		return nil, nil, 0, 0
 	}
+	if err, data, numItems, size, ok := r.requestFrom(arrowConsumer, records); ok {
+		return err, data, numItems, size
+	}
 
 	switch payloads[0].Type {
 	case arrowpb.ArrowPayloadType_UNIVARIATE_METRICS:
@@ -810,6 +1164,24 @@ func (r *Receiver) consumeBatch(arrowConsumer arrowRecord.ConsumerAPI, records *
 	return retErr, retData, numItems, uncompSize
 }
 
+// releaseData returns the data of a batch that is decoded but not
+// consumed to the Arrow Consumer, if it pools pdata.  Consumed data
+// is never released, since the pipeline may retain it.
+func releaseData(ac arrowRecord.ConsumerAPI, data any) {
+	releaser, ok := ac.(arrowRecord.PdataReleaser)
+	if !ok {
+		return
+	}
+	switch items := data.(type) {
+	case []pmetric.Metrics:
+		releaser.ReleaseMetrics(items)
+	case []plog.Logs:
+		releaser.ReleaseLogs(items)
+	case []ptrace.Traces:
+		releaser.ReleaseTraces(items)
+	}
+}
+
 // consumeData invokes the next pipeline consumer for a received batch of data.
 // it uses the standard OTel collector instrumentation (receiverhelper.ObsReport).
 //
@@ -828,6 +1200,13 @@ func (r *Receiver) consumeData(ctx context.Context, data any, flight *inFlightDa
 		}
 		final = r.obsrecv.EndMetricsOp
 
+	case metricsRequest:
+		ctx = r.obsrecv.StartMetricsOp(ctx)
+		if len(items.request) != 0 {
+			oneOp(items.next.ConsumeMetricsRequest(ctx, items.request))
+		}
+		final = r.obsrecv.EndMetricsOp
+
 	case []plog.Logs:
 		ctx = r.obsrecv.StartLogsOp(ctx)
 		for _, logs := range items {
@@ -835,6 +1214,13 @@ func (r *Receiver) consumeData(ctx context.Context, data any, flight *inFlightDa
 		}
 		final = r.obsrecv.EndLogsOp
 
+	case logsRequest:
+		ctx = r.obsrecv.StartLogsOp(ctx)
+		if len(items.request) != 0 {
+			oneOp(items.next.ConsumeLogsRequest(ctx, items.request))
+		}
+		final = r.obsrecv.EndLogsOp
+
 	case []ptrace.Traces:
 		ctx = r.obsrecv.StartTracesOp(ctx)
 		for _, traces := range items {
@@ -842,6 +1228,13 @@ func (r *Receiver) consumeData(ctx context.Context, data any, flight *inFlightDa
 		}
 		final = r.obsrecv.EndTracesOp
 
+	case tracesRequest:
+		ctx = r.obsrecv.StartTracesOp(ctx)
+		if len(items.request) != 0 {
+			oneOp(items.next.ConsumeTracesRequest(ctx, items.request))
+		}
+		final = r.obsrecv.EndTracesOp
+
 	default:
 		retErr = ErrUnrecognizedPayload
 	}
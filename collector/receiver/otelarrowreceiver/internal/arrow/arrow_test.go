@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,7 +18,9 @@ import (
 
 	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
 	arrowCollectorMock "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1/mock"
+	"github.com/open-telemetry/otel-arrow/collector/arrowzpages"
 	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
 	"github.com/open-telemetry/otel-arrow/collector/testdata"
 	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
 	arrowRecordMock "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record/mock"
@@ -39,7 +42,10 @@ import (
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 	"golang.org/x/net/http2/hpack"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -94,6 +100,12 @@ type commonTestCase struct {
 
 	ctxCall  *gomock.Call
 	recvCall *gomock.Call
+
+	// next, if set, replaces consumers as the Receiver's
+	// Consumers, and receiverOpts and status are passed to New().
+	next         Consumers
+	receiverOpts []Option
+	status       *arrowzpages.Instance
 }
 
 type testChannel interface {
@@ -227,7 +239,8 @@ func newCommonTestCase(t *testing.T, tc testChannel) *commonTestCase {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	ctx = metadata.NewIncomingContext(ctx, metadata.MD{
-		"stream_ctx": []string{"per-request"},
+		"stream_ctx":                []string{"per-request"},
+		streamevents.StreamIDHeader: []string{testPeerStreamID},
 	})
 
 	ctc := &commonTestCase{
@@ -360,8 +373,12 @@ func (ctc *commonTestCase) start(newConsumer func() arrowRecord.ConsumerAPI, bq
 	})
 	require.NoError(ctc.T, err)
 
+	var next Consumers = ctc.consumers
+	if ctc.next != nil {
+		next = ctc.next
+	}
 	rcvr, err := New(
-		ctc.consumers,
+		next,
 		rc,
 		obsrecv,
 		gsettings,
@@ -369,6 +386,8 @@ func (ctc *commonTestCase) start(newConsumer func() arrowRecord.ConsumerAPI, bq
 		newConsumer,
 		bq,
 		netstats.Noop{},
+		ctc.status,
+		ctc.receiverOpts...,
 	)
 	require.NoError(ctc.T, err)
 	go func() {
@@ -500,6 +519,17 @@ func TestBoundedQueueWithPdataHeaders(t *testing.T) {
 	}
 }
 
+func TestPendingResponses(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	runtime.GOMAXPROCS(1)
+	require.Equal(t, minPendingResponses, pendingResponses())
+	runtime.GOMAXPROCS(8)
+	require.Equal(t, 8, pendingResponses())
+	runtime.GOMAXPROCS(128)
+	require.Equal(t, maxPendingResponses, pendingResponses())
+}
+
 func TestReceiverTraces(t *testing.T) {
 	stdTesting := otelAssert.NewStdUnitTest(t)
 	tc := healthyTestChannel{}
@@ -524,6 +554,157 @@ func TestReceiverTraces(t *testing.T) {
 	requireCanceledStatus(t, err)
 }
 
+// testPeerStreamID is the exporter's identifier of the test streams.
+const testPeerStreamID = "0123456789ab"
+
+func TestReceiverStreamIDs(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctc.telset.Logger = zap.New(core)
+
+	td := testdata.GenerateTraces(2)
+	batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(td)
+	require.NoError(t, err)
+
+	ctc.stream.EXPECT().Send(statusOKFor(batch.BatchId)).Times(1).Return(nil)
+
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+	ctc.putBatch(batch, nil)
+	<-ctc.consume
+
+	err = ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+
+	// The stream's events carry its own and the exporter's
+	// identifiers.
+	started := logs.FilterField(zap.String(streamevents.EventKey, streamevents.StreamStart)).All()
+	require.Len(t, started, 1)
+	fields := started[0].ContextMap()
+	require.Len(t, fields[streamevents.StreamIDKey], 12)
+	require.Equal(t, testPeerStreamID, fields[streamevents.PeerStreamIDKey])
+
+	rotated := logs.FilterField(zap.String(streamevents.EventKey, streamevents.StreamRotate)).All()
+	require.Len(t, rotated, 1)
+	require.Equal(t, fields[streamevents.StreamIDKey], rotated[0].ContextMap()[streamevents.StreamIDKey])
+	require.Equal(t, testPeerStreamID, rotated[0].ContextMap()[streamevents.PeerStreamIDKey])
+}
+
+func TestReceiverClosedSchemaIDs(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctc.telset.Logger = zap.New(core)
+
+	batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+	require.NoError(t, err)
+	// As announced by a producer that reset its streams.
+	batch.ClosedSchemaIds = []string{"7", "8"}
+
+	ctc.stream.EXPECT().Send(statusOKFor(batch.BatchId)).Times(1).Return(nil)
+
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+	ctc.putBatch(batch, nil)
+	<-ctc.consume
+
+	err = ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+
+	reset := logs.FilterMessage("arrow schema reset").All()
+	require.Len(t, reset, 1)
+	require.Equal(t, []any{"7", "8"}, reset[0].ContextMap()["closed_schema_ids"])
+}
+
+func TestReceiverStreamParams(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+	ctc.status = arrowzpages.Register(component.KindReceiver, "otelarrow/params")
+	defer ctc.status.Unregister()
+
+	ctc.stream.EXPECT().Send(gomock.Any()).Times(2).Return(nil)
+
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+	// The second batch has the schema of the first.
+	td := testdata.GenerateTraces(2)
+	for i := 0; i < 2; i++ {
+		batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(td)
+		require.NoError(t, err)
+		ctc.putBatch(batch, nil)
+		<-ctc.consume
+	}
+
+	snap := arrowzpages.Snapshot()
+	var params map[string]string
+	for _, st := range snap {
+		if st.Name == "otelarrow/params" {
+			require.Len(t, st.Streams, 1)
+			params = st.Streams[0].Params
+		}
+	}
+	require.Equal(t, map[string]string{
+		streamevents.PeerStreamIDKey:   testPeerStreamID,
+		arrowzpages.SchemaVersionParam: "1",
+	}, params)
+
+	err := ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+}
+
+func TestReceiverID(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+	ctc.receiverOpts = append(ctc.receiverOpts, WithReceiverID("collector-1"))
+
+	td := testdata.GenerateTraces(2)
+	batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(td)
+	require.NoError(t, err)
+
+	ctc.stream.EXPECT().Send(&arrowpb.BatchStatus{
+		BatchId:    batch.BatchId,
+		StatusCode: arrowpb.StatusCode_OK,
+		ReceiverId: "collector-1",
+	}).Times(1).Return(nil)
+
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+	ctc.putBatch(batch, nil)
+	<-ctc.consume
+
+	err = ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+}
+
+func TestReceiverBatchStatusInterceptor(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+	ctc.receiverOpts = append(ctc.receiverOpts,
+		WithBatchStatusInterceptor(func(ctx context.Context, bs *arrowpb.BatchStatus, err error) {
+			require.NotNil(t, ctx)
+			require.NoError(t, err)
+			bs.StatusMessage = "quota=10"
+		}),
+		WithBatchStatusInterceptor(func(_ context.Context, bs *arrowpb.BatchStatus, _ error) {
+			bs.StatusMessage += ";interval=1m"
+		}),
+	)
+
+	td := testdata.GenerateTraces(2)
+	batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(td)
+	require.NoError(t, err)
+
+	ctc.stream.EXPECT().Send(&arrowpb.BatchStatus{
+		BatchId:       batch.BatchId,
+		StatusCode:    arrowpb.StatusCode_OK,
+		StatusMessage: "quota=10;interval=1m",
+	}).Times(1).Return(nil)
+
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+	ctc.putBatch(batch, nil)
+	<-ctc.consume
+
+	err = ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+}
+
 func TestReceiverLogs(t *testing.T) {
 	tc := healthyTestChannel{}
 	ctc := newCommonTestCase(t, tc)
@@ -567,6 +748,51 @@ func TestReceiverMetrics(t *testing.T) {
 	requireCanceledStatus(t, err)
 }
 
+// releasingConsumer records the pdata released to it.
+type releasingConsumer struct {
+	arrowRecord.ConsumerAPI
+	released []any
+}
+
+func (rc *releasingConsumer) ReleaseLogs(data []plog.Logs) {
+	rc.released = append(rc.released, data)
+}
+
+func (rc *releasingConsumer) ReleaseMetrics(data []pmetric.Metrics) {
+	rc.released = append(rc.released, data)
+}
+
+func (rc *releasingConsumer) ReleaseTraces(data []ptrace.Traces) {
+	rc.released = append(rc.released, data)
+}
+
+func TestReleaseData(t *testing.T) {
+	rc := &releasingConsumer{}
+	traces := []ptrace.Traces{testdata.GenerateTraces(1)}
+	metrics := []pmetric.Metrics{testdata.GenerateMetrics(1)}
+	logs := []plog.Logs{testdata.GenerateLogs(1)}
+
+	releaseData(rc, traces)
+	releaseData(rc, metrics)
+	releaseData(rc, logs)
+	releaseData(rc, logsRequest{})
+	releaseData(rc, nil)
+	require.Equal(t, []any{traces, metrics, logs}, rc.released)
+}
+
+func TestReceiverHeartbeat(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+
+	ctc.stream.EXPECT().Send(statusOKFor(-1)).Times(1).Return(nil)
+
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+	ctc.putBatch(&arrowpb.BatchArrowRecords{BatchId: -1}, nil)
+
+	err := ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+}
+
 func TestReceiverRecvError(t *testing.T) {
 	tc := healthyTestChannel{}
 	ctc := newCommonTestCase(t, tc)
@@ -745,6 +971,28 @@ func TestReceiverMemoryLimit(t *testing.T) {
 	}
 }
 
+func TestReceiverSchemaReset(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+
+	batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+	require.NoError(t, err)
+	batch = copyBatch(batch)
+
+	// The Recv() returns an error, there are no Send() calls.
+
+	ctc.start(func() arrowRecord.ConsumerAPI {
+		mock := arrowRecordMock.NewMockConsumerAPI(ctc.ctrl)
+		mock.EXPECT().Close().Times(1).Return(nil)
+		mock.EXPECT().TracesFrom(gomock.Any()).Return(nil, fmt.Errorf("test evicted: %w", arrowRecord.ErrSchemaReset))
+		return mock
+	}, defaultBQ())
+	ctc.putBatch(batch, nil)
+
+	err = ctc.wait()
+	requireStatus(t, codes.Unavailable, err)
+}
+
 func copyBatch(in *arrowpb.BatchArrowRecords) *arrowpb.BatchArrowRecords {
 	// Because Arrow-IPC uses zero copy, we have to copy inside the test
 	// instead of sharing pointers to BatchArrowRecords.
@@ -916,6 +1164,137 @@ func testReceiverHeaders(t *testing.T, includeMeta bool) {
 	wg.Wait()
 }
 
+// TestReceiverHeadersDynamicTable verifies that header fields are
+// decoded using the hpack dynamic table of the stream, including
+// after the encoder resizes the table.
+func TestReceiverHeadersDynamicTable(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+
+	const batches = 4
+	ctc.stream.EXPECT().Send(gomock.Any()).Times(batches).Return(nil)
+
+	ctc.start(ctc.newRealConsumer, defaultBQ(), func(gsettings *configgrpc.ServerConfig, _ *auth.Server) {
+		gsettings.IncludeMetadata = true
+	})
+
+	var hpb bytes.Buffer
+	hpe := hpack.NewEncoder(&hpb)
+	var sizes []int
+
+	for i := 0; i < batches; i++ {
+		if i == 2 {
+			// Begins the next header block with a dynamic
+			// table size update.
+			hpe.SetMaxDynamicTableSize(1024)
+		}
+		hpb.Reset()
+		require.NoError(t, hpe.WriteField(hpack.HeaderField{
+			Name:  "tenant",
+			Value: "a-rather-long-tenant-identifier",
+		}))
+		sizes = append(sizes, hpb.Len())
+
+		batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+		require.NoError(t, err)
+		batch = copyBatch(batch)
+		batch.Headers = bytes.Clone(hpb.Bytes())
+		ctc.putBatch(batch, nil)
+
+		select {
+		case res := <-ctc.consume:
+			info := client.FromContext(res.Ctx)
+			require.Equal(t, []string{"a-rather-long-tenant-identifier"}, info.Metadata.Get("tenant"))
+		case err := <-ctc.streamErr:
+			t.Fatalf("stream failed at batch %d: %v", i, err)
+		}
+	}
+	// Repeated fields are indexed in the dynamic table.
+	require.Less(t, sizes[1], sizes[0])
+	require.Less(t, sizes[3], sizes[0])
+
+	close(ctc.receive)
+	requireCanceledStatus(t, ctc.wait())
+}
+
+// TestReceiverHeadersMalformed verifies that a header block that
+// fails to decode rejects only its batch, that blocks encoded before
+// the exporter resets its dynamic table are retried, and that the
+// stream continues once the exporter resets it.
+func TestReceiverHeadersMalformed(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+
+	statusCh := make(chan *arrowpb.BatchStatus, 4)
+	ctc.stream.EXPECT().Send(gomock.Any()).Times(4).DoAndReturn(func(bs *arrowpb.BatchStatus) error {
+		statusCh <- bs
+		return nil
+	})
+
+	ctc.start(ctc.newRealConsumer, defaultBQ(), func(gsettings *configgrpc.ServerConfig, _ *auth.Server) {
+		gsettings.IncludeMetadata = true
+	})
+
+	var hpb bytes.Buffer
+	hpe := hpack.NewEncoder(&hpb)
+	sendBatch := func(hdrs []byte) int64 {
+		batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+		require.NoError(t, err)
+		batch = copyBatch(batch)
+		batch.Headers = bytes.Clone(hdrs)
+		ctc.putBatch(batch, nil)
+		return batch.BatchId
+	}
+	writeTenant := func() []byte {
+		hpb.Reset()
+		require.NoError(t, hpe.WriteField(hpack.HeaderField{
+			Name:  "tenant",
+			Value: "a-rather-long-tenant-identifier",
+		}))
+		return hpb.Bytes()
+	}
+	expectConsumed := func() {
+		select {
+		case res := <-ctc.consume:
+			info := client.FromContext(res.Ctx)
+			require.Equal(t, []string{"a-rather-long-tenant-identifier"}, info.Metadata.Get("tenant"))
+		case err := <-ctc.streamErr:
+			t.Fatalf("stream failed: %v", err)
+		}
+	}
+
+	id := sendBatch(writeTenant())
+	expectConsumed()
+	require.Equal(t, id, (<-statusCh).BatchId)
+
+	// An index beyond the dynamic table.
+	id = sendBatch([]byte{0xff, 0x7f})
+	bs := <-statusCh
+	require.Equal(t, id, bs.BatchId)
+	require.Equal(t, arrowpb.StatusCode_INVALID_ARGUMENT, bs.StatusCode)
+	require.Contains(t, bs.StatusMessage, "arrow metadata error")
+
+	// A block sent before the exporter learned of the rejection
+	// refers to its old table.
+	id = sendBatch(writeTenant())
+	bs = <-statusCh
+	require.Equal(t, id, bs.BatchId)
+	require.Equal(t, arrowpb.StatusCode_UNAVAILABLE, bs.StatusCode)
+	require.Contains(t, bs.StatusMessage, errStaleHeaders.Error())
+
+	// The exporter resets its table, as on InvalidArgument.
+	hpe.SetMaxDynamicTableSize(0)
+	hpe.SetMaxDynamicTableSize(4096)
+	id = sendBatch(writeTenant())
+	expectConsumed()
+	bs = <-statusCh
+	require.Equal(t, id, bs.BatchId)
+	require.Equal(t, arrowpb.StatusCode_OK, bs.StatusCode)
+
+	close(ctc.receive)
+	requireCanceledStatus(t, ctc.wait())
+}
+
 func TestReceiverCancel(t *testing.T) {
 	tc := healthyTestChannel{}
 	ctc := newCommonTestCase(t, tc)
@@ -947,7 +1326,7 @@ func TestHeaderReceiverStreamContextOnly(t *testing.T) {
 
 	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD(expect))
 
-	h := newHeaderReceiver(ctx, nil, true)
+	h := newHeaderReceiver(ctx, nil, true, nil)
 
 	for i := 0; i < 3; i++ {
 		cc, _, err := h.combineHeaders(ctx, nil)
@@ -965,7 +1344,7 @@ func TestHeaderReceiverNoIncludeMetadata(t *testing.T) {
 
 	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD(noExpect))
 
-	h := newHeaderReceiver(ctx, nil, false)
+	h := newHeaderReceiver(ctx, nil, false, nil)
 
 	for i := 0; i < 3; i++ {
 		cc, _, err := h.combineHeaders(ctx, nil)
@@ -989,7 +1368,7 @@ func TestHeaderReceiverAuthServerNoIncludeMetadata(t *testing.T) {
 	// The auth server is not called, it just needs to be non-nil.
 	as.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Times(0)
 
-	h := newHeaderReceiver(ctx, as, false)
+	h := newHeaderReceiver(ctx, as, false, nil)
 
 	for i := 0; i < 3; i++ {
 		cc, hdrs, err := h.combineHeaders(ctx, nil)
@@ -1019,7 +1398,7 @@ func TestHeaderReceiverRequestNoStreamMetadata(t *testing.T) {
 
 	ctx := context.Background()
 
-	h := newHeaderReceiver(ctx, nil, true)
+	h := newHeaderReceiver(ctx, nil, true, nil)
 
 	for i := 0; i < 3; i++ {
 		hpb.Reset()
@@ -1059,7 +1438,7 @@ func TestHeaderReceiverAuthServerIsSetNoIncludeMetadata(t *testing.T) {
 	// The auth server is not called, it just needs to be non-nil.
 	as.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Times(0)
 
-	h := newHeaderReceiver(ctx, as, true)
+	h := newHeaderReceiver(ctx, as, true, nil)
 
 	for i := 0; i < 3; i++ {
 		hpb.Reset()
@@ -1118,7 +1497,7 @@ func TestHeaderReceiverBothMetadata(t *testing.T) {
 
 	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD(expectK))
 
-	h := newHeaderReceiver(ctx, nil, true)
+	h := newHeaderReceiver(ctx, nil, true, nil)
 
 	for i := 0; i < 3; i++ {
 		hpb.Reset()
@@ -1164,7 +1543,7 @@ func TestHeaderReceiverDuplicateMetadata(t *testing.T) {
 
 	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD(expectStream))
 
-	h := newHeaderReceiver(ctx, nil, true)
+	h := newHeaderReceiver(ctx, nil, true, nil)
 
 	for i := 0; i < 3; i++ {
 		hpb.Reset()
@@ -1361,7 +1740,7 @@ func TestHeaderReceiverIsTraced(t *testing.T) {
 
 	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD(streamHeaders))
 
-	h := newHeaderReceiver(ctx, nil, true)
+	h := newHeaderReceiver(ctx, nil, true, nil)
 
 	for i := 0; i < 3; i++ {
 		hpb.Reset()
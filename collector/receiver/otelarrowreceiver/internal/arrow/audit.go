@@ -0,0 +1,169 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/otel-arrow/collector/redact"
+)
+
+// AuditRecord describes one rejected batch.  An audit log is a
+// sequence of AuditRecords encoded as JSON lines.
+type AuditRecord struct {
+	Time    time.Time `json:"time"`
+	Stream  string    `json:"stream"`
+	Method  string    `json:"method"`
+	BatchID int64     `json:"batch_id"`
+	// Client is the identity of the client, when configured and
+	// known, and Addr its network address.
+	Client string `json:"client,omitempty"`
+	Addr   string `json:"addr,omitempty"`
+	// Code and Reason are the status returned for the batch.
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+	// Bytes is the uncompressed size of the batch when known,
+	// otherwise its encoded size, and Items its number of spans,
+	// data points or log records, when decoded.
+	Bytes int64 `json:"bytes"`
+	Items int   `json:"items,omitempty"`
+	// Skipped counts the records of the same code dropped by
+	// sampling since the previous record of that code.
+	Skipped int `json:"skipped,omitempty"`
+}
+
+// AuditSampling limits the records of an audit log: within each Tick,
+// the first Initial records of each status code are written, then
+// every Thereafter-th.  Zero Initial and Thereafter write every
+// record, and zero Thereafter drops the records after the initial
+// ones.
+type AuditSampling struct {
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
+}
+
+// AuditLog writes an AuditRecord for every rejected batch, subject
+// to sampling.  Methods are safe to call on a nil *AuditLog, which
+// records nothing.
+type AuditLog struct {
+	identify func(context.Context) string
+	sampling AuditSampling
+
+	lock   sync.Mutex
+	enc    *json.Encoder
+	counts map[codes.Code]*auditCount
+}
+
+type auditCount struct {
+	tick    time.Time
+	n       int
+	skipped int
+}
+
+// NewAuditLog returns an audit log that writes to w.  identify, if
+// not nil, returns the identity of the client of a batch.
+func NewAuditLog(w io.Writer, identify func(context.Context) string, sampling AuditSampling) *AuditLog {
+	if sampling.Tick <= 0 {
+		sampling.Tick = time.Second
+	}
+	return &AuditLog{
+		identify: identify,
+		sampling: sampling,
+		enc:      json.NewEncoder(w),
+		counts:   map[codes.Code]*auditCount{},
+	}
+}
+
+// WithAuditLog records the batches rejected by the receiver, see
+// AuditLog.
+func WithAuditLog(a *AuditLog) Option {
+	return func(r *Receiver) {
+		r.auditLog = a
+	}
+}
+
+// sample returns whether to write a record of code at now, and the
+// number of records of code skipped since the previous one.
+func (a *AuditLog) sample(code codes.Code, now time.Time) (bool, int) {
+	s := a.sampling
+	if s.Initial <= 0 && s.Thereafter <= 0 {
+		return true, 0
+	}
+	c := a.counts[code]
+	if c == nil {
+		c = &auditCount{}
+		a.counts[code] = c
+	}
+	if tick := now.Truncate(s.Tick); !tick.Equal(c.tick) {
+		c.tick = tick
+		c.n = 0
+	}
+	c.n++
+	if c.n <= s.Initial || (s.Thereafter > 0 && (c.n-s.Initial)%s.Thereafter == 0) {
+		skipped := c.skipped
+		c.skipped = 0
+		return true, skipped
+	}
+	c.skipped++
+	return false, 0
+}
+
+// record writes a record of the batch rejected with err, whose client
+// is described by ctx.
+func (a *AuditLog) record(ctx context.Context, rec AuditRecord, err error, redactErrors bool) {
+	if a == nil {
+		return
+	}
+	st := status.Convert(err)
+	rec.Time = time.Now()
+	rec.Code = st.Code().String()
+	rec.Reason = st.Message()
+	if redactErrors {
+		rec.Reason = redact.Message(rec.Reason)
+	}
+	info := client.FromContext(ctx)
+	if info.Addr != nil {
+		rec.Addr = info.Addr.String()
+	}
+	if a.identify != nil {
+		rec.Client = a.identify(ctx)
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	ok, skipped := a.sample(st.Code(), rec.Time)
+	if !ok {
+		return
+	}
+	rec.Skipped = skipped
+	// Errors are not reported: the audit log must not fail batches.
+	_ = a.enc.Encode(rec)
+}
+
+// audit records the rejection of the batch with err.
+func (id *inFlightData) audit(ctx context.Context, err error) {
+	size := id.uncompSize
+	if size == 0 {
+		size = id.numAcquired
+	}
+	if size == 0 {
+		size = id.encodedSize
+	}
+	id.auditLog.record(ctx, AuditRecord{
+		Stream:  id.streamID,
+		Method:  id.method,
+		BatchID: id.batchID,
+		Bytes:   size,
+		Items:   id.numItems,
+	}, err, id.redactErrors)
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/testdata"
+	"google.golang.org/grpc/codes"
+)
+
+func TestAuditSampling(t *testing.T) {
+	a := NewAuditLog(&bytes.Buffer{}, nil, AuditSampling{Initial: 2, Thereafter: 3, Tick: time.Second})
+	start := time.Unix(100, 0)
+
+	var written []int
+	var skipped []int
+	for i := 1; i <= 8; i++ {
+		if ok, n := a.sample(codes.Unavailable, start); ok {
+			written = append(written, i)
+			skipped = append(skipped, n)
+		}
+	}
+	// The first two, then every third.
+	require.Equal(t, []int{1, 2, 5, 8}, written)
+	require.Equal(t, []int{0, 0, 2, 2}, skipped)
+
+	// Codes are sampled separately.
+	ok, _ := a.sample(codes.InvalidArgument, start)
+	require.True(t, ok)
+
+	// The next tick starts over, reporting the skipped records.
+	ok, n := a.sample(codes.Unavailable, start.Add(time.Second))
+	require.True(t, ok)
+	require.Equal(t, 0, n)
+
+	all := NewAuditLog(&bytes.Buffer{}, nil, AuditSampling{})
+	for i := 0; i < 10; i++ {
+		ok, _ := all.sample(codes.Unavailable, start)
+		require.True(t, ok)
+	}
+}
+
+func TestAuditSamplingSkippedAcrossTicks(t *testing.T) {
+	a := NewAuditLog(&bytes.Buffer{}, nil, AuditSampling{Initial: 1})
+	start := time.Unix(100, 0)
+
+	ok, _ := a.sample(codes.Unavailable, start)
+	require.True(t, ok)
+	for i := 0; i < 3; i++ {
+		ok, _ = a.sample(codes.Unavailable, start)
+		require.False(t, ok)
+	}
+	ok, n := a.sample(codes.Unavailable, start.Add(time.Second))
+	require.True(t, ok)
+	require.Equal(t, 3, n)
+}
+
+func TestReceiverAuditLog(t *testing.T) {
+	tc := unhealthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+	var buf bytes.Buffer
+	identify := func(context.Context) string { return "tenant-a" }
+	ctc.receiverOpts = append(ctc.receiverOpts, WithAuditLog(NewAuditLog(&buf, identify, AuditSampling{})))
+
+	td := testdata.GenerateTraces(2)
+	batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(td)
+	require.NoError(t, err)
+
+	ctc.stream.EXPECT().Send(statusUnavailableFor(batch.BatchId, "consumer unhealthy")).Times(1).Return(nil)
+
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+	ctc.putBatch(batch, nil)
+	<-ctc.consume
+
+	err = ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+
+	require.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")), "one record")
+	var rec AuditRecord
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	require.Equal(t, batch.BatchId, rec.BatchID)
+	require.Equal(t, "tenant-a", rec.Client)
+	require.Equal(t, codes.Unavailable.String(), rec.Code)
+	require.Equal(t, "consumer unhealthy", rec.Reason)
+	require.Equal(t, 2, rec.Items)
+	require.Greater(t, rec.Bytes, int64(0))
+	require.NotEmpty(t, rec.Stream)
+	require.False(t, rec.Time.IsZero())
+}
@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	"github.com/open-telemetry/otel-arrow/collector/capability"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// WithCapabilities makes the receiver take part in the capability
+// exchange: it rejects streams whose exporter declares capabilities
+// that it cannot serve, and declares caps in the response headers of
+// every stream.
+func WithCapabilities(caps capability.Set) Option {
+	return func(r *Receiver) {
+		r.capabilities = &caps
+	}
+}
+
+// negotiate performs the capability exchange of a stream, before any
+// batch is received.  It returns the capabilities agreed with the
+// exporter, which are empty when either end does not take part.
+func (r *Receiver) negotiate(serverStream anyStreamServer) (capability.Set, error) {
+	if r.capabilities == nil {
+		return capability.Set{}, nil
+	}
+	var agreed capability.Set
+	if values := metadata.ValueFromIncomingContext(serverStream.Context(), capability.Header); len(values) != 0 {
+		peer, err := capability.Parse(values[0])
+		if err != nil {
+			return capability.Set{}, status.Errorf(codes.InvalidArgument, "%s: %v", capability.Header, err)
+		}
+		if agreed, err = capability.Negotiate(peer, *r.capabilities); err != nil {
+			return capability.Set{}, status.Error(codes.FailedPrecondition, err.Error())
+		}
+	}
+	// The headers are sent at once, instead of with the first
+	// response, so that the exporter learns them before its first
+	// batch is acknowledged.
+	if err := serverStream.SendHeader(metadata.Pairs(capability.Header, r.capabilities.String())); err != nil {
+		return capability.Set{}, err
+	}
+	return agreed, nil
+}
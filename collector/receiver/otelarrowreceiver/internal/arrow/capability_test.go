@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-telemetry/otel-arrow/collector/capability"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	arrowCollectorMock "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1/mock"
+)
+
+func TestNegotiate(t *testing.T) {
+	caps := capability.Set{
+		Versions:         []int{1},
+		Compression:      []string{"none", "zstd"},
+		DictionaryDeltas: true,
+		MaxBatchBytes:    4 << 20,
+	}
+	r := &Receiver{}
+	WithCapabilities(caps)(r)
+
+	// newStream returns a stream whose exporter declares kv, and
+	// the headers sent in response.
+	newStream := func(kv ...string) (anyStreamServer, *metadata.MD) {
+		ctrl := gomock.NewController(t)
+		stream := arrowCollectorMock.NewMockArrowTracesService_ArrowTracesServer(ctrl)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+		stream.EXPECT().Context().AnyTimes().Return(ctx)
+		var sent metadata.MD
+		stream.EXPECT().SendHeader(gomock.Any()).MaxTimes(1).DoAndReturn(func(md metadata.MD) error {
+			sent = md
+			return nil
+		})
+		return stream, &sent
+	}
+
+	// An exporter that predates the exchange still learns the
+	// receiver's capabilities.
+	stream, sent := newStream()
+	agreed, err := r.negotiate(stream)
+	require.NoError(t, err)
+	require.Equal(t, capability.Set{}, agreed)
+	require.Equal(t, []string{caps.String()}, sent.Get(capability.Header))
+
+	stream, sent = newStream(capability.Header, "versions=1,2;compression=zstd;dictionary_deltas=true")
+	agreed, err = r.negotiate(stream)
+	require.NoError(t, err)
+	require.Equal(t, capability.Set{
+		Versions:         []int{1},
+		Compression:      []string{"zstd"},
+		DictionaryDeltas: true,
+		MaxBatchBytes:    4 << 20,
+	}, agreed)
+	require.Equal(t, []string{caps.String()}, sent.Get(capability.Header))
+
+	// Streams that cannot be served are rejected before the
+	// headers are sent.
+	stream, sent = newStream(capability.Header, "versions=2;compression=zstd")
+	_, err = r.negotiate(stream)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.Contains(t, err.Error(), "no common version")
+	require.Nil(t, *sent)
+
+	stream, _ = newStream(capability.Header, "versions=x")
+	_, err = r.negotiate(stream)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Without capabilities, the receiver does not take part.
+	stream, sent = newStream(capability.Header, "versions=2;compression=zstd")
+	agreed, err = (&Receiver{}).negotiate(stream)
+	require.NoError(t, err)
+	require.Equal(t, capability.Set{}, agreed)
+	require.Nil(t, *sent)
+}
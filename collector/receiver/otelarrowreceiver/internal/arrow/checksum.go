@@ -0,0 +1,28 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	"strconv"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/zeebo/xxh3"
+)
+
+// checksumHeader carries the checksum of the payloads of a batch,
+// set by exporters configured with checksums.
+const checksumHeader = "otlp-arrow-checksum"
+
+// batchChecksum returns the xxh3 hash of the schema IDs, types, and
+// records of the payloads of a batch, in hexadecimal, as computed by
+// the exporter.
+func batchChecksum(batch *arrowpb.BatchArrowRecords) string {
+	h := xxh3.New()
+	for _, payload := range batch.ArrowPayloads {
+		_, _ = h.WriteString(payload.SchemaId)
+		_, _ = h.WriteString(strconv.Itoa(int(payload.Type)))
+		_, _ = h.Write(payload.Record)
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
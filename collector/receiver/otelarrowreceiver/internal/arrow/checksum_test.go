@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/net/http2/hpack"
+	"google.golang.org/grpc/codes"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+)
+
+func TestReceiverChecksums(t *testing.T) {
+	ctc := newCommonTestCase(t, healthyTestChannel{})
+
+	// Only the intact batch is acknowledged.
+	ctc.stream.EXPECT().Send(gomock.Any()).Times(1).DoAndReturn(func(bs *arrowpb.BatchStatus) error {
+		require.Equal(t, arrowpb.StatusCode_OK, bs.StatusCode)
+		return nil
+	})
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+
+	var hpb bytes.Buffer
+	hpe := hpack.NewEncoder(&hpb)
+	putBatch := func(corrupt bool) {
+		batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+		require.NoError(t, err)
+		batch = copyBatch(batch)
+
+		hpb.Reset()
+		require.NoError(t, hpe.WriteField(hpack.HeaderField{
+			Name:  checksumHeader,
+			Value: batchChecksum(batch),
+		}))
+		batch.Headers = bytes.Clone(hpb.Bytes())
+
+		if corrupt {
+			record := batch.ArrowPayloads[0].Record
+			record[len(record)/2] ^= 0xff
+		}
+		ctc.putBatch(batch, nil)
+	}
+
+	putBatch(false)
+	<-ctc.consume
+
+	putBatch(true)
+	requireStatus(t, codes.DataLoss, ctc.wait())
+}
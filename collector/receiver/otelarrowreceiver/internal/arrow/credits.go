@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+)
+
+// creditDecreaseStatuses are the statuses of batches that the
+// pipeline pushed back on, which shrink the credit window.
+var creditDecreaseStatuses = map[arrowpb.StatusCode]bool{
+	arrowpb.StatusCode_RESOURCE_EXHAUSTED: true,
+	arrowpb.StatusCode_UNAVAILABLE:        true,
+}
+
+// creditSteps is the number of increases that grow a window from its
+// minimum to its limit.
+const creditSteps = 8
+
+// WithFlowControlCredits grants each stream a credit window of at
+// most batches batches and bytes encoded bytes, which the exporter
+// may have sent without having received their status.  The window
+// is set in every BatchStatus.  It is halved by every batch that the
+// pipeline pushes back on, with ResourceExhausted or Unavailable,
+// and grows back by an eighth of the limit with every other status,
+// so that exporters block before sending while the pipeline is
+// behind instead of having their batches rejected.  Values below 1
+// do not limit that dimension, and the option is ignored when
+// neither is limited.
+func WithFlowControlCredits(batches int, bytes int64) Option {
+	return func(r *Receiver) {
+		if batches <= 0 && bytes <= 0 {
+			r.creditLimit = nil
+			return
+		}
+		r.creditLimit = &creditWindow{
+			batches: max(int64(batches), 0),
+			bytes:   max(bytes, 0),
+		}
+	}
+}
+
+// creditWindow is the credit window of one stream.  Only the stream's
+// sender uses it.  A nil *creditWindow grants no credits.  A zero
+// dimension is not limited.
+type creditWindow struct {
+	batches int64
+	bytes   int64
+
+	// limit is the window that the stream starts with and grows
+	// back to.
+	limit *creditWindow
+}
+
+// newCreditWindow returns the credit window of a new stream.
+func (r *Receiver) newCreditWindow() *creditWindow {
+	if r.creditLimit == nil {
+		return nil
+	}
+	return &creditWindow{
+		batches: r.creditLimit.batches,
+		bytes:   r.creditLimit.bytes,
+		limit:   r.creditLimit,
+	}
+}
+
+// update adjusts the window for a batch status and sets the window
+// in it.
+func (c *creditWindow) update(bs *arrowpb.BatchStatus) {
+	if c == nil {
+		return
+	}
+	if creditDecreaseStatuses[bs.StatusCode] {
+		c.batches = max(c.batches/2, min(c.limit.batches, 1))
+		c.bytes = max(c.bytes/2, min(c.limit.bytes, 1))
+	} else {
+		c.batches = min(c.batches+creditStep(c.limit.batches), c.limit.batches)
+		c.bytes = min(c.bytes+creditStep(c.limit.bytes), c.limit.bytes)
+	}
+	bs.CreditBatches = c.batches
+	bs.CreditBytes = c.bytes
+}
+
+// creditStep returns the increase of a window dimension with limit.
+func creditStep(limit int64) int64 {
+	return max(limit/creditSteps, min(limit, 1))
+}
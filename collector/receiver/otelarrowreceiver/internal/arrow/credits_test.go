@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+)
+
+func TestCreditWindow(t *testing.T) {
+	r := &Receiver{}
+	WithFlowControlCredits(16, 0)(r)
+	c := r.newCreditWindow()
+
+	ok := &arrowpb.BatchStatus{StatusCode: arrowpb.StatusCode_OK}
+	busy := &arrowpb.BatchStatus{StatusCode: arrowpb.StatusCode_RESOURCE_EXHAUSTED}
+
+	// Streams start with the limit, which an unlimited dimension
+	// does not change.
+	c.update(ok)
+	require.Equal(t, int64(16), ok.CreditBatches)
+	require.Equal(t, int64(0), ok.CreditBytes)
+
+	// Push back halves the window, down to one batch.
+	for _, want := range []int64{8, 4, 2, 1, 1} {
+		c.update(busy)
+		require.Equal(t, want, busy.CreditBatches)
+	}
+	// Other statuses grow it by an eighth of the limit.
+	for _, want := range []int64{3, 5, 7} {
+		c.update(ok)
+		require.Equal(t, want, ok.CreditBatches)
+	}
+	invalid := &arrowpb.BatchStatus{StatusCode: arrowpb.StatusCode_INVALID_ARGUMENT}
+	c.update(invalid)
+	require.Equal(t, int64(9), invalid.CreditBatches)
+
+	// Without limits, no credits are granted.
+	WithFlowControlCredits(0, 0)(r)
+	require.Nil(t, r.newCreditWindow())
+	ok = &arrowpb.BatchStatus{}
+	r.newCreditWindow().update(ok)
+	require.Equal(t, int64(0), ok.CreditBatches)
+}
+
+// failSecondTestChannel fails the second batch consumed.
+type failSecondTestChannel struct {
+	calls atomic.Int32
+}
+
+func (tc *failSecondTestChannel) onConsume() error {
+	if tc.calls.Add(1) == 2 {
+		return status.Errorf(codes.Unavailable, "consumer busy")
+	}
+	return nil
+}
+
+func TestReceiverFlowControlCredits(t *testing.T) {
+	ctc := newCommonTestCase(t, &failSecondTestChannel{})
+	ctc.receiverOpts = append(ctc.receiverOpts, WithFlowControlCredits(4, 8<<20))
+
+	sent := make(chan *arrowpb.BatchStatus, 3)
+	ctc.stream.EXPECT().Send(gomock.Any()).Times(3).DoAndReturn(func(bs *arrowpb.BatchStatus) error {
+		sent <- bs
+		return nil
+	})
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+
+	for _, want := range []struct {
+		code           arrowpb.StatusCode
+		batches, bytes int64
+	}{
+		{arrowpb.StatusCode_OK, 4, 8 << 20},
+		{arrowpb.StatusCode_UNAVAILABLE, 2, 4 << 20},
+		{arrowpb.StatusCode_OK, 3, 5 << 20},
+	} {
+		batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+		require.NoError(t, err)
+		ctc.putBatch(batch, nil)
+		<-ctc.consume
+		bs := <-sent
+		require.Equal(t, batch.BatchId, bs.BatchId)
+		require.Equal(t, want.code, bs.StatusCode)
+		require.Equal(t, want.batches, bs.CreditBatches)
+		require.Equal(t, want.bytes, bs.CreditBytes)
+	}
+
+	requireCanceledStatus(t, ctc.cancelAndWait())
+}
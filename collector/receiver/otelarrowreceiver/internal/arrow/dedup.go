@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/client"
+)
+
+// idempotencyKeyHeader carries the idempotency key of a batch, set
+// by exporters configured with idempotency keys.
+const idempotencyKeyHeader = "otlp-idempotency-key"
+
+// WithDeduplication remembers the idempotency keys of the last
+// window batches consumed successfully, across all streams.  A
+// batch whose key is remembered is decoded, which keeps the Arrow
+// state of its stream, and then acknowledged without being
+// consumed again.  Keys are scoped by the authenticated principal
+// of the batch, see dedupKey, so that one client cannot suppress
+// the batches of another.  Values below 1 disable deduplication.
+func WithDeduplication(window int) Option {
+	return func(r *Receiver) {
+		if window > 0 {
+			r.dedup = newDedupWindow(window)
+		}
+	}
+}
+
+// dedupKey returns the key that identifies a batch in the dedup
+// window: the idempotency key, qualified by the auth attributes of
+// info, which the auth extension derived from the credentials of
+// the client.  Without an auth extension, all clients share the
+// same scope.
+func dedupKey(info client.Info, key string) string {
+	if info.Auth == nil {
+		return key
+	}
+	names := append([]string(nil), info.Auth.GetAttributeNames()...)
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%q=%q;", name, fmt.Sprint(info.Auth.GetAttribute(name)))
+	}
+	b.WriteString(key)
+	return b.String()
+}
+
+// dedupWindow is a fixed-size set of the most recently added keys.
+// A nil *dedupWindow contains no keys.
+type dedupWindow struct {
+	lock sync.Mutex
+
+	// keys is the set of keys in ring.
+	keys map[string]struct{}
+
+	// ring holds the keys in the order they were added, next is
+	// the position of the oldest key, which is replaced next.
+	ring []string
+	next int
+}
+
+func newDedupWindow(size int) *dedupWindow {
+	return &dedupWindow{
+		keys: make(map[string]struct{}, size),
+		ring: make([]string, size),
+	}
+}
+
+// contains returns true if key is in the window.
+func (w *dedupWindow) contains(key string) bool {
+	if w == nil || key == "" {
+		return false
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	_, ok := w.keys[key]
+	return ok
+}
+
+// add adds key to the window, replacing the oldest key when the
+// window is full.
+func (w *dedupWindow) add(key string) {
+	if w == nil || key == "" {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if _, ok := w.keys[key]; ok {
+		return
+	}
+	if old := w.ring[w.next]; old != "" {
+		delete(w.keys, old)
+	}
+	w.ring[w.next] = key
+	w.keys[key] = struct{}{}
+	w.next = (w.next + 1) % len(w.ring)
+}
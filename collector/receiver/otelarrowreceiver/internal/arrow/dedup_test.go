@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/net/http2/hpack"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+)
+
+func TestDedupWindow(t *testing.T) {
+	w := newDedupWindow(2)
+	require.False(t, w.contains("a"))
+
+	w.add("a")
+	w.add("b")
+	w.add("a")
+	require.True(t, w.contains("a"))
+	require.True(t, w.contains("b"))
+
+	// The oldest key is replaced.
+	w.add("c")
+	require.False(t, w.contains("a"))
+	require.True(t, w.contains("b"))
+	require.True(t, w.contains("c"))
+
+	// Empty keys are not remembered.
+	w.add("")
+	require.False(t, w.contains(""))
+	require.True(t, w.contains("b"))
+
+	// A nil window is disabled.
+	var disabled *dedupWindow
+	disabled.add("a")
+	require.False(t, disabled.contains("a"))
+}
+
+type testDedupAuth map[string]any
+
+func (a testDedupAuth) GetAttribute(name string) any {
+	return a[name]
+}
+
+func (a testDedupAuth) GetAttributeNames() []string {
+	var names []string
+	for n := range a {
+		names = append(names, n)
+	}
+	return names
+}
+
+func TestDedupKey(t *testing.T) {
+	// Without an auth extension, keys are not scoped.
+	require.Equal(t, "k", dedupKey(client.Info{}, "k"))
+
+	tenantA := client.Info{Auth: testDedupAuth{"tenant": "a", "subject": "svc"}}
+	tenantB := client.Info{Auth: testDedupAuth{"tenant": "b", "subject": "svc"}}
+	require.Equal(t, dedupKey(tenantA, "k"), dedupKey(client.Info{Auth: testDedupAuth{"subject": "svc", "tenant": "a"}}, "k"))
+	require.NotEqual(t, dedupKey(tenantA, "k"), dedupKey(tenantB, "k"))
+	require.NotEqual(t, dedupKey(tenantA, "k"), dedupKey(client.Info{}, "k"))
+
+	// One client's key does not suppress the same key of another.
+	w := newDedupWindow(2)
+	w.add(dedupKey(tenantA, "k"))
+	require.True(t, w.contains(dedupKey(tenantA, "k")))
+	require.False(t, w.contains(dedupKey(tenantB, "k")))
+}
+
+func TestReceiverDeduplication(t *testing.T) {
+	ctc := newCommonTestCase(t, healthyTestChannel{})
+	ctc.receiverOpts = append(ctc.receiverOpts, WithDeduplication(4))
+
+	// sent receives the IDs of the batches acknowledged OK.
+	sent := make(chan int64, 3)
+	ctc.stream.EXPECT().Send(gomock.Any()).Times(3).DoAndReturn(func(bs *arrowpb.BatchStatus) error {
+		require.Equal(t, arrowpb.StatusCode_OK, bs.StatusCode)
+		sent <- bs.BatchId
+		return nil
+	})
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+
+	var hpb bytes.Buffer
+	hpe := hpack.NewEncoder(&hpb)
+	putBatch := func(key string) int64 {
+		hpb.Reset()
+		require.NoError(t, hpe.WriteField(hpack.HeaderField{
+			Name:  idempotencyKeyHeader,
+			Value: key,
+		}))
+		batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+		require.NoError(t, err)
+		batch = copyBatch(batch)
+		batch.Headers = bytes.Clone(hpb.Bytes())
+		ctc.putBatch(batch, nil)
+		return batch.BatchId
+	}
+
+	id := putBatch("first")
+	<-ctc.consume
+	require.Equal(t, id, <-sent)
+
+	// The retry is acknowledged without being consumed.
+	id = putBatch("first")
+	require.Equal(t, id, <-sent)
+
+	id = putBatch("second")
+	<-ctc.consume
+	require.Equal(t, id, <-sent)
+
+	close(ctc.receive)
+	requireCanceledStatus(t, ctc.wait())
+}
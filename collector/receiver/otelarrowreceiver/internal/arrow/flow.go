@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	"context"
+	"sync"
+)
+
+// WithStreamInFlightLimit stops each stream from receiving the next
+// batch while the batches it is consuming total at least limit
+// uncompressed bytes.  While a stream does not receive, gRPC does
+// not extend its HTTP/2 flow-control window, so a slow consumer
+// pushes back on the exporter at the transport layer instead of
+// decoded batches accumulating in memory.  Values below 1 disable
+// the limit.
+func WithStreamInFlightLimit(limit int64) Option {
+	return func(r *Receiver) {
+		r.streamInFlightLimit = limit
+	}
+}
+
+// WithMaxConcurrentBatchesPerStream stops each stream from receiving
+// the next batch while it has limit batches outstanding, i.e.,
+// received and not yet answered, so that one client cannot queue
+// unbounded work on its stream.  Like WithStreamInFlightLimit, this
+// pushes back on the exporter through gRPC flow control.  Values
+// below 1 disable the limit.
+func WithMaxConcurrentBatchesPerStream(limit int) Option {
+	return func(r *Receiver) {
+		r.streamBatchLimit = limit
+	}
+}
+
+// streamFlow counts the batches and the uncompressed bytes being
+// consumed by one stream.  A nil *streamFlow does not limit the
+// stream.
+type streamFlow struct {
+	limit      int64
+	batchLimit int
+
+	lock     sync.Mutex
+	inFlight int64
+	batches  int
+
+	// wake is closed when inFlight decreases, then replaced.
+	wake chan struct{}
+}
+
+// newStreamFlow returns the flow control of a new stream.
+func (r *Receiver) newStreamFlow() *streamFlow {
+	if r.streamInFlightLimit <= 0 && r.streamBatchLimit <= 0 {
+		return nil
+	}
+	return &streamFlow{
+		limit:      r.streamInFlightLimit,
+		batchLimit: r.streamBatchLimit,
+		wake:       make(chan struct{}),
+	}
+}
+
+// below reports whether the stream is below its limits.  The caller
+// holds the lock.
+func (f *streamFlow) below() bool {
+	if f.limit > 0 && f.inFlight >= f.limit {
+		return false
+	}
+	return f.batchLimit <= 0 || f.batches < f.batchLimit
+}
+
+// wait returns when the stream is below its limits or ctx is done.
+func (f *streamFlow) wait(ctx context.Context) error {
+	if f == nil {
+		return nil
+	}
+	for {
+		f.lock.Lock()
+		if f.below() {
+			f.lock.Unlock()
+			return nil
+		}
+		wake := f.wake
+		f.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+// add counts one batch of size bytes being consumed.
+func (f *streamFlow) add(size int64) {
+	if f == nil {
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.inFlight += size
+	f.batches++
+}
+
+// done counts one batch of size bytes no longer being consumed and
+// wakes the stream's receiver.
+func (f *streamFlow) done(size int64) {
+	if f == nil {
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.inFlight -= size
+	f.batches--
+	close(f.wake)
+	f.wake = make(chan struct{})
+}
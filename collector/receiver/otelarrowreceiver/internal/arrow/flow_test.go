@@ -0,0 +1,143 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+)
+
+func TestStreamFlow(t *testing.T) {
+	f := (&Receiver{streamInFlightLimit: 10}).newStreamFlow()
+	ctx := context.Background()
+	require.NoError(t, f.wait(ctx))
+
+	f.add(6)
+	require.NoError(t, f.wait(ctx))
+	f.add(6)
+
+	waited := make(chan error)
+	go func() {
+		waited <- f.wait(ctx)
+	}()
+	select {
+	case <-waited:
+		t.Fatal("wait returned above the limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+	f.done(6)
+	require.NoError(t, <-waited)
+
+	// Waiting ends with the context.
+	f.add(6)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, f.wait(canceled), context.Canceled)
+
+	// A nil flow does not limit.
+	var disabled *streamFlow
+	disabled.add(100)
+	require.NoError(t, disabled.wait(ctx))
+	disabled.done(100)
+}
+
+func TestStreamFlowBatchLimit(t *testing.T) {
+	f := (&Receiver{streamBatchLimit: 2}).newStreamFlow()
+	ctx := context.Background()
+
+	// Batches count regardless of their size.
+	f.add(0)
+	require.NoError(t, f.wait(ctx))
+	f.add(0)
+
+	waited := make(chan error)
+	go func() {
+		waited <- f.wait(ctx)
+	}()
+	select {
+	case <-waited:
+		t.Fatal("wait returned at the batch limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+	f.done(0)
+	require.NoError(t, <-waited)
+
+	// Either limit stops the stream.
+	f = (&Receiver{streamInFlightLimit: 10, streamBatchLimit: 2}).newStreamFlow()
+	f.add(10)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, f.wait(canceled), context.Canceled)
+	f.done(10)
+	f.add(1)
+	f.add(1)
+	require.ErrorIs(t, f.wait(canceled), context.Canceled)
+}
+
+func TestReceiverStreamInFlightLimit(t *testing.T) {
+	ctc := newCommonTestCase(t, healthyTestChannel{})
+	ctc.receiverOpts = append(ctc.receiverOpts, WithStreamInFlightLimit(1))
+	ctc.stream.EXPECT().Send(gomock.Any()).Times(2).Return(nil)
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+
+	first, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+	require.NoError(t, err)
+	ctc.putBatch(first, nil)
+
+	// The consumer is blocked on the first batch, so the
+	// stream does not receive the second.
+	second, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+	require.NoError(t, err)
+	select {
+	case ctc.receive <- recvResult{payload: second}:
+		t.Fatal("stream received above its in-flight limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	<-ctc.consume
+	ctc.putBatch(second, nil)
+	<-ctc.consume
+
+	close(ctc.receive)
+	requireCanceledStatus(t, ctc.wait())
+}
+
+func TestReceiverMaxConcurrentBatchesPerStream(t *testing.T) {
+	ctc := newCommonTestCase(t, healthyTestChannel{})
+	ctc.receiverOpts = append(ctc.receiverOpts, WithMaxConcurrentBatchesPerStream(2))
+	ctc.stream.EXPECT().Send(gomock.Any()).Times(3).Return(nil)
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+
+	var batches []*arrowpb.BatchArrowRecords
+	for i := 0; i < 3; i++ {
+		batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+		require.NoError(t, err)
+		batches = append(batches, batch)
+	}
+	ctc.putBatch(batches[0], nil)
+	ctc.putBatch(batches[1], nil)
+
+	// The consumer is blocked on two batches, so the stream
+	// does not receive the third.
+	select {
+	case ctc.receive <- recvResult{payload: batches[2]}:
+		t.Fatal("stream received above its batch limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	<-ctc.consume
+	ctc.putBatch(batches[2], nil)
+	<-ctc.consume
+	<-ctc.consume
+
+	close(ctc.receive)
+	requireCanceledStatus(t, ctc.wait())
+}
@@ -0,0 +1,289 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// This file carries Arrow streams over HTTP/2 without gRPC, for
+// environments that cannot terminate gRPC at the edge.  An exporter
+// POSTs one request per stream with the HTTPContentType, whose body
+// is a sequence of BatchArrowRecords and whose response is a sequence
+// of BatchStatus, both framed as gRPC messages: a zero flag byte, the
+// big-endian 32-bit length of the message, then the message.  The
+// request headers are the stream's metadata.  The status that ends
+// the stream is returned in the HTTPStatusTrailer and
+// HTTPMessageTrailer trailers, or as the HTTP status of the response
+// when the stream is rejected before it starts.
+
+// HTTPContentType is the content type of the requests and responses
+// of Arrow streams over HTTP/2.
+const HTTPContentType = "application/x-otel-arrow"
+
+const (
+	// HTTPStatusTrailer is the trailer with the numeric gRPC
+	// status code that ended the stream.
+	HTTPStatusTrailer = "Otel-Arrow-Status"
+
+	// HTTPMessageTrailer is the trailer with the message of the
+	// status that ended the stream.
+	HTTPMessageTrailer = "Otel-Arrow-Message"
+)
+
+// frameHeaderLen is the size of the flag byte and the length that
+// precede each message.
+const frameHeaderLen = 5
+
+// defaultMaxRecvMsgSize is the gRPC server's default maximum message
+// size, which also limits the messages of streams over HTTP/2.
+const defaultMaxRecvMsgSize = 4 << 20
+
+// HTTPTraces, HTTPMetrics and HTTPLogs serve Arrow streams of each
+// signal over HTTP/2, like ArrowTraces, ArrowMetrics and ArrowLogs
+// over gRPC.
+func (r *Receiver) HTTPTraces(w http.ResponseWriter, req *http.Request) {
+	r.httpStream(w, req, arrowTracesMethod)
+}
+
+func (r *Receiver) HTTPMetrics(w http.ResponseWriter, req *http.Request) {
+	r.httpStream(w, req, arrowMetricsMethod)
+}
+
+func (r *Receiver) HTTPLogs(w http.ResponseWriter, req *http.Request) {
+	r.httpStream(w, req, arrowLogsMethod)
+}
+
+// httpStream serves one stream.  Streams need HTTP/2, since the
+// responses are sent while the request is received.
+func (r *Receiver) httpStream(w http.ResponseWriter, req *http.Request, method string) {
+	switch {
+	case req.Method != http.MethodPost:
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, fmt.Sprintf("%v method not allowed, supported: [POST]", req.Method), http.StatusMethodNotAllowed)
+		return
+	case req.ProtoMajor != 2:
+		http.Error(w, "arrow streams require HTTP/2", http.StatusHTTPVersionNotSupported)
+		return
+	case req.Header.Get("Content-Type") != HTTPContentType:
+		http.Error(w, fmt.Sprintf("unsupported content type, supported: [%s]", HTTPContentType), http.StatusUnsupportedMediaType)
+		return
+	}
+	maxRecv := defaultMaxRecvMsgSize
+	if r.gsettings.MaxRecvMsgSizeMiB != 0 {
+		maxRecv = int(r.gsettings.MaxRecvMsgSizeMiB << 20)
+	}
+	stream := newHTTPServerStream(w, req, maxRecv)
+	stream.finish(r.anyStream(stream, method))
+}
+
+// httpServerStream adapts an HTTP/2 request and its response to the
+// gRPC server stream of anyStream.
+type httpServerStream struct {
+	ctx     context.Context
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	body    *bufio.Reader
+	maxRecv int
+
+	// lock serializes the writes of the response, and protects
+	// wroteHeader.
+	lock        sync.Mutex
+	wroteHeader bool
+}
+
+var _ anyStreamServer = (*httpServerStream)(nil)
+
+func newHTTPServerStream(w http.ResponseWriter, req *http.Request, maxRecv int) *httpServerStream {
+	md := metadata.MD{}
+	for k, vs := range req.Header {
+		md.Append(k, vs...)
+	}
+	return &httpServerStream{
+		ctx:     metadata.NewIncomingContext(req.Context(), md),
+		w:       w,
+		rc:      http.NewResponseController(w),
+		body:    bufio.NewReader(req.Body),
+		maxRecv: maxRecv,
+	}
+}
+
+func (s *httpServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// Recv reads the next batch, returning io.EOF at the end of the
+// request.
+func (s *httpServerStream) Recv() (*arrowpb.BatchArrowRecords, error) {
+	var hdr [frameHeaderLen]byte
+	if _, err := io.ReadFull(s.body, hdr[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, status.Errorf(codes.Unavailable, "reading arrow stream: %v", err)
+	}
+	if hdr[0] != 0 {
+		return nil, status.Errorf(codes.Unimplemented, "compressed arrow stream messages are not supported, use Content-Encoding")
+	}
+	size := binary.BigEndian.Uint32(hdr[1:])
+	if int64(size) > int64(s.maxRecv) {
+		return nil, status.Errorf(codes.ResourceExhausted, "received message larger than max (%d vs. %d)", size, s.maxRecv)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(s.body, buf); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "reading arrow stream: %v", err)
+	}
+	batch := &arrowpb.BatchArrowRecords{}
+	if err := proto.Unmarshal(buf, batch); err != nil {
+		return nil, status.Errorf(codes.Internal, "grpc: failed to unmarshal the received message: %v", err)
+	}
+	return batch, nil
+}
+
+// Send writes and flushes one status.
+func (s *httpServerStream) Send(bs *arrowpb.BatchStatus) error {
+	data, err := proto.Marshal(bs)
+	if err != nil {
+		return status.Errorf(codes.Internal, "marshaling batch status: %v", err)
+	}
+	frame := make([]byte, frameHeaderLen, frameHeaderLen+len(data))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	frame = append(frame, data...)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.writeHeaderLocked()
+	if _, err := s.w.Write(frame); err != nil {
+		return status.Errorf(codes.Unavailable, "writing arrow stream: %v", err)
+	}
+	if err := s.rc.Flush(); err != nil {
+		return status.Errorf(codes.Unavailable, "flushing arrow stream: %v", err)
+	}
+	return nil
+}
+
+func (s *httpServerStream) SetHeader(md metadata.MD) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.wroteHeader {
+		return status.Error(codes.Internal, "headers already sent")
+	}
+	for k, vs := range md {
+		for _, v := range vs {
+			s.w.Header().Add(k, v)
+		}
+	}
+	return nil
+}
+
+// SendHeader sends the response headers at once, like gRPC.
+func (s *httpServerStream) SendHeader(md metadata.MD) error {
+	if err := s.SetHeader(md); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.writeHeaderLocked()
+	if err := s.rc.Flush(); err != nil {
+		return status.Errorf(codes.Unavailable, "flushing arrow stream: %v", err)
+	}
+	return nil
+}
+
+func (s *httpServerStream) SetTrailer(md metadata.MD) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for k, vs := range md {
+		for _, v := range vs {
+			s.w.Header().Add(http.TrailerPrefix+k, v)
+		}
+	}
+}
+
+func (s *httpServerStream) SendMsg(m any) error {
+	bs, ok := m.(*arrowpb.BatchStatus)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected message type %T", m)
+	}
+	return s.Send(bs)
+}
+
+func (s *httpServerStream) RecvMsg(m any) error {
+	batch, ok := m.(*arrowpb.BatchArrowRecords)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected message type %T", m)
+	}
+	received, err := s.Recv()
+	if err != nil {
+		return err
+	}
+	proto.Merge(batch, received)
+	return nil
+}
+
+// writeHeaderLocked starts the response, once.
+func (s *httpServerStream) writeHeaderLocked() {
+	if s.wroteHeader {
+		return
+	}
+	s.wroteHeader = true
+	s.w.Header().Set("Content-Type", HTTPContentType)
+	s.w.WriteHeader(http.StatusOK)
+}
+
+// finish ends the response with the status of the stream: in its
+// trailers once the stream started, or else as the HTTP status.
+func (s *httpServerStream) finish(err error) {
+	st := status.Convert(err)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.wroteHeader && st.Code() != codes.OK {
+		s.wroteHeader = true
+		http.Error(s.w, st.Message(), httpStatusFromCode(st.Code()))
+		return
+	}
+	s.writeHeaderLocked()
+	s.w.Header().Set(http.TrailerPrefix+HTTPStatusTrailer, strconv.Itoa(int(st.Code())))
+	if msg := st.Message(); msg != "" {
+		// Trailer values may not contain line breaks.
+		s.w.Header().Set(http.TrailerPrefix+HTTPMessageTrailer, strings.Join(strings.Fields(msg), " "))
+	}
+}
+
+// httpStatusFromCode returns the HTTP status of a stream rejected
+// with code, as in the gRPC-to-HTTP mapping of OTLP/HTTP.
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound, codes.Unimplemented:
+		return http.StatusNotFound
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable, codes.Canceled, codes.Aborted:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+)
+
+func httpFrame(flag byte, msg []byte) []byte {
+	return append(binary.BigEndian.AppendUint32([]byte{flag}, uint32(len(msg))), msg...)
+}
+
+func newTestHTTPStream(body []byte) (*httpServerStream, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/arrow/traces", bytes.NewReader(body))
+	req.Header.Set("X-Tenant", "a")
+	w := httptest.NewRecorder()
+	return newHTTPServerStream(w, req, 64), w
+}
+
+func TestHTTPServerStreamRecv(t *testing.T) {
+	batch := &arrowpb.BatchArrowRecords{BatchId: 7}
+	data, err := proto.Marshal(batch)
+	require.NoError(t, err)
+
+	// The request headers are the stream's metadata.
+	s, _ := newTestHTTPStream(httpFrame(0, data))
+	require.Equal(t, []string{"a"}, metadata.ValueFromIncomingContext(s.Context(), "x-tenant"))
+	recv, err := s.Recv()
+	require.NoError(t, err)
+	require.True(t, proto.Equal(batch, recv))
+	_, err = s.Recv()
+	require.Equal(t, io.EOF, err)
+
+	s, _ = newTestHTTPStream(httpFrame(1, data))
+	_, err = s.Recv()
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	s, _ = newTestHTTPStream(httpFrame(0, make([]byte, 65)))
+	_, err = s.Recv()
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	s, _ = newTestHTTPStream(httpFrame(0, data)[:4])
+	_, err = s.Recv()
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestHTTPServerStreamFinish(t *testing.T) {
+	// A stream rejected before it starts returns an HTTP status.
+	s, w := newTestHTTPStream(nil)
+	s.finish(status.Error(codes.PermissionDenied, "denied"))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "denied")
+
+	// Once started, the status is in the trailers.
+	s, w = newTestHTTPStream(nil)
+	require.NoError(t, s.SendHeader(metadata.Pairs("x-caps", "1")))
+	require.NoError(t, s.Send(&arrowpb.BatchStatus{BatchId: 3}))
+	s.finish(status.Error(codes.Unavailable, "overloaded\nretry"))
+	resp := w.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, HTTPContentType, resp.Header.Get("Content-Type"))
+	require.Equal(t, "1", resp.Header.Get("X-Caps"))
+	require.Equal(t, "14", resp.Trailer.Get(HTTPStatusTrailer))
+	require.Equal(t, "overloaded retry", resp.Trailer.Get(HTTPMessageTrailer))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var bs arrowpb.BatchStatus
+	require.NoError(t, proto.Unmarshal(body[frameHeaderLen:], &bs))
+	require.Equal(t, int64(3), bs.BatchId)
+	require.Equal(t, uint32(len(body)-frameHeaderLen), binary.BigEndian.Uint32(body[1:frameHeaderLen]))
+
+	// Headers cannot be set after the response started.
+	require.Error(t, s.SetHeader(metadata.Pairs("x-late", "1")))
+}
@@ -0,0 +1,22 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	"context"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+)
+
+// BatchStatusInterceptor may modify a BatchStatus before it is sent,
+// given the stream context and the error of the batch.
+type BatchStatusInterceptor func(ctx context.Context, status *arrowpb.BatchStatus, err error)
+
+// WithBatchStatusInterceptor adds an interceptor, called in order
+// with the others for every BatchStatus.
+func WithBatchStatusInterceptor(ic BatchStatusInterceptor) Option {
+	return func(r *Receiver) {
+		r.interceptors = append(r.interceptors, ic)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import "strings"
+
+// metadataFilter restricts the header keys that include_metadata
+// copies into the client metadata of a batch.  A nil filter copies
+// every key.
+type metadataFilter struct {
+	// allow, when not empty, are the only keys copied.
+	allow map[string]bool
+	// deny are never copied.
+	deny map[string]bool
+}
+
+// WithMetadataFilter restricts the client metadata of each batch,
+// when include_metadata is set, to the allowed header keys, if any,
+// except the denied keys.  Keys are case-insensitive.  The headers
+// are passed to the auth extension unfiltered.
+func WithMetadataFilter(allowed, denied []string) Option {
+	return func(r *Receiver) {
+		if len(allowed) == 0 && len(denied) == 0 {
+			return
+		}
+		r.metadataFilter = &metadataFilter{
+			allow: lowerSet(allowed),
+			deny:  lowerSet(denied),
+		}
+	}
+}
+
+func lowerSet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = true
+	}
+	return set
+}
+
+// permits returns whether the lower-case key is copied.
+func (f *metadataFilter) permits(key string) bool {
+	if f.deny[key] {
+		return false
+	}
+	return len(f.allow) == 0 || f.allow[key]
+}
+
+// apply returns the headers whose keys the filter permits.
+func (f *metadataFilter) apply(hdrs map[string][]string) map[string][]string {
+	if f == nil {
+		return hdrs
+	}
+	out := make(map[string][]string, len(hdrs))
+	for k, v := range hdrs {
+		if f.permits(strings.ToLower(k)) {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"golang.org/x/net/http2/hpack"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestMetadataFilter(t *testing.T) {
+	hdrs := map[string][]string{
+		"x-tenant":      {"a"},
+		"x-region":      {"eu"},
+		"authorization": {"secret"},
+	}
+
+	var r Receiver
+	WithMetadataFilter(nil, nil)(&r)
+	require.Nil(t, r.metadataFilter)
+	require.Equal(t, hdrs, r.metadataFilter.apply(hdrs))
+
+	WithMetadataFilter(nil, []string{"Authorization"})(&r)
+	require.Equal(t, map[string][]string{
+		"x-tenant": {"a"},
+		"x-region": {"eu"},
+	}, r.metadataFilter.apply(hdrs))
+
+	WithMetadataFilter([]string{"X-Tenant", "authorization"}, []string{"authorization"})(&r)
+	require.Equal(t, map[string][]string{
+		"x-tenant": {"a"},
+	}, r.metadataFilter.apply(hdrs))
+}
+
+func TestHeaderReceiverMetadataFilter(t *testing.T) {
+	var hpb bytes.Buffer
+	hpe := hpack.NewEncoder(&hpb)
+	for _, hf := range []hpack.HeaderField{
+		{Name: "x-tenant", Value: "a"},
+		{Name: "x-debug", Value: "unbounded"},
+	} {
+		require.NoError(t, hpe.WriteField(hf))
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "secret", "x-region", "eu"))
+	var r Receiver
+	WithMetadataFilter([]string{"x-tenant", "x-region"}, nil)(&r)
+	h := newHeaderReceiver(ctx, nil, true, r.metadataFilter)
+
+	cc, hdrs, err := h.combineHeaders(ctx, hpb.Bytes())
+	require.NoError(t, err)
+
+	md := client.FromContext(cc).Metadata
+	require.Equal(t, []string{"a"}, md.Get("x-tenant"))
+	require.Equal(t, []string{"eu"}, md.Get("x-region"))
+	require.Nil(t, md.Get("x-debug"))
+	require.Nil(t, md.Get("authorization"))
+
+	// The headers for the auth extension are not filtered.
+	require.Equal(t, []string{"secret"}, hdrs["authorization"])
+	require.Equal(t, []string{"unbounded"}, hdrs["x-debug"])
+}
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	"sort"
+)
+
+// WithOrderedResponses makes every stream respond to its batches in
+// the order they were received.  Batches are still consumed
+// concurrently, and the response to a batch that finishes early is
+// held until the responses to all earlier batches of the stream have
+// been sent.  The exporter delivers the responses of a stream to its
+// waiters in the order they arrive, so that acknowledgments follow
+// send order.
+func WithOrderedResponses() Option {
+	return func(r *Receiver) {
+		r.orderedResponses = true
+	}
+}
+
+// responseOrder holds the responses of a stream that completed ahead
+// of an earlier batch.  A nil *responseOrder sends responses as they
+// complete.
+type responseOrder struct {
+	// next is the sequence number of the next response to send.
+	next uint64
+
+	// held are responses waiting for an earlier response, by
+	// sequence number.
+	held map[uint64]batchResp
+}
+
+// newResponseOrder returns the response order of a new stream.
+func (r *Receiver) newResponseOrder() *responseOrder {
+	if !r.orderedResponses {
+		return nil
+	}
+	return &responseOrder{
+		held: map[uint64]batchResp{},
+	}
+}
+
+// send sends resp, followed by the held responses that it precedes,
+// or holds resp until the responses before it have been sent.
+func (o *responseOrder) send(resp batchResp, sendFunc func(batchResp) error) error {
+	if o == nil {
+		return sendFunc(resp)
+	}
+	if resp.seq != o.next {
+		o.held[resp.seq] = resp
+		return nil
+	}
+	for {
+		if err := sendFunc(resp); err != nil {
+			return err
+		}
+		o.next++
+
+		var ok bool
+		if resp, ok = o.held[o.next]; !ok {
+			return nil
+		}
+		delete(o.held, o.next)
+	}
+}
+
+// flush sends the held responses in order.  This is used when the
+// stream ends, at which point a batch that failed to be received
+// leaves a gap in the sequence that will not be filled.
+func (o *responseOrder) flush(sendFunc func(batchResp) error) error {
+	if o == nil {
+		return nil
+	}
+	seqs := make([]uint64, 0, len(o.held))
+	for seq := range o.held {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	for _, seq := range seqs {
+		resp := o.held[seq]
+		delete(o.held, seq)
+		if err := sendFunc(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/mock/gomock"
+
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	arrowRecordMock "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record/mock"
+)
+
+func TestResponseOrder(t *testing.T) {
+	var sent []int64
+	sendFunc := func(resp batchResp) error {
+		sent = append(sent, resp.id)
+		return nil
+	}
+	resp := func(seq uint64) batchResp {
+		return batchResp{id: int64(seq) + 100, seq: seq}
+	}
+
+	r := &Receiver{orderedResponses: true}
+	order := r.newResponseOrder()
+
+	// Later batches are held for the first.
+	require.NoError(t, order.send(resp(2), sendFunc))
+	require.NoError(t, order.send(resp(1), sendFunc))
+	require.Empty(t, sent)
+
+	require.NoError(t, order.send(resp(0), sendFunc))
+	require.Equal(t, []int64{100, 101, 102}, sent)
+
+	// At the end of the stream, held responses are sent in order
+	// despite the gap.
+	sent = nil
+	require.NoError(t, order.send(resp(6), sendFunc))
+	require.NoError(t, order.send(resp(5), sendFunc))
+	require.Empty(t, sent)
+	require.NoError(t, order.flush(sendFunc))
+	require.Equal(t, []int64{105, 106}, sent)
+
+	// A send error is returned.
+	testErr := errors.New("test")
+	order = r.newResponseOrder()
+	require.ErrorIs(t, order.send(resp(0), func(batchResp) error { return testErr }), testErr)
+}
+
+func TestResponseOrderDisabled(t *testing.T) {
+	var sent []int64
+	sendFunc := func(resp batchResp) error {
+		sent = append(sent, resp.id)
+		return nil
+	}
+
+	order := (&Receiver{}).newResponseOrder()
+	require.Nil(t, order)
+
+	require.NoError(t, order.send(batchResp{id: 2, seq: 2}, sendFunc))
+	require.NoError(t, order.send(batchResp{id: 1, seq: 1}, sendFunc))
+	require.NoError(t, order.flush(sendFunc))
+	require.Equal(t, []int64{2, 1}, sent)
+}
+
+// TestReceiverOrderedPipelined verifies that a stream decodes its next
+// batches while an earlier one is being consumed, and that ordered
+// responses follow the order of the batches regardless.
+func TestReceiverOrderedPipelined(t *testing.T) {
+	ctc := newCommonTestCase(t, healthyTestChannel{})
+	ctc.receiverOpts = append(ctc.receiverOpts, WithOrderedResponses())
+
+	sent := make(chan int64, 3)
+	ctc.stream.EXPECT().Send(gomock.Any()).Times(3).DoAndReturn(func(bs *arrowpb.BatchStatus) error {
+		require.Equal(t, arrowpb.StatusCode_OK, bs.StatusCode)
+		sent <- bs.BatchId
+		return nil
+	})
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+		require.NoError(t, err)
+		ids = append(ids, batch.BatchId)
+
+		// The stream receives the next batch once it has
+		// decoded this one, while none is done consuming.
+		ctc.putBatch(batch, nil)
+	}
+
+	// The batches finish consuming in any order.
+	for i := 0; i < 3; i++ {
+		<-ctc.consume
+	}
+	require.Equal(t, ids[0], <-sent)
+	require.Equal(t, ids[1], <-sent)
+	require.Equal(t, ids[2], <-sent)
+
+	close(ctc.receive)
+	requireCanceledStatus(t, ctc.wait())
+}
+
+// TestReceiverDecodeOverlapsConsume verifies that a stream decodes its
+// following batches while the pipeline is still consuming the first.
+func TestReceiverDecodeOverlapsConsume(t *testing.T) {
+	ctc := newCommonTestCase(t, healthyTestChannel{})
+	ctc.stream.EXPECT().Send(gomock.Any()).Times(3).Return(nil)
+
+	// decoded counts the batches decoded by the stream.
+	var decoded atomic.Int32
+	newConsumer := func() arrowRecord.ConsumerAPI {
+		mock := arrowRecordMock.NewMockConsumerAPI(ctc.ctrl)
+		cons := arrowRecord.NewConsumer()
+		mock.EXPECT().Close().Times(1).Return(nil)
+		mock.EXPECT().TracesFrom(gomock.Any()).Times(3).DoAndReturn(func(records *arrowpb.BatchArrowRecords) ([]ptrace.Traces, error) {
+			defer decoded.Add(1)
+			return cons.TracesFrom(records)
+		})
+		return mock
+	}
+	ctc.start(newConsumer, defaultBQ())
+
+	for i := 0; i < 3; i++ {
+		batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+		require.NoError(t, err)
+		ctc.putBatch(batch, nil)
+	}
+
+	// Every batch is decoded while the consumers, which block
+	// until ctc.consume is read, are all still busy.
+	require.Eventually(t, func() bool {
+		return decoded.Load() == 3
+	}, 5*time.Second, time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		<-ctc.consume
+	}
+
+	close(ctc.receive)
+	requireCanceledStatus(t, ctc.wait())
+}
@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/passthrough"
+	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+)
+
+// tracesRequest is a marshaled OTLP ExportTraceServiceRequest, with
+// the consumer that accepts it.
+type tracesRequest struct {
+	next    passthrough.Traces
+	request []byte
+}
+
+// metricsRequest is a marshaled OTLP ExportMetricsServiceRequest,
+// with the consumer that accepts it.
+type metricsRequest struct {
+	next    passthrough.Metrics
+	request []byte
+}
+
+// logsRequest is a marshaled OTLP ExportLogsServiceRequest, with the
+// consumer that accepts it.
+type logsRequest struct {
+	next    passthrough.Logs
+	request []byte
+}
+
+// WithPassthrough enables decoding batches into marshaled OTLP
+// requests when the consumer of their signal implements the
+// corresponding passthrough interface.
+func WithPassthrough() Option {
+	return func(r *Receiver) {
+		r.passthrough = true
+	}
+}
+
+// requestFrom decodes a batch into a marshaled OTLP request, when
+// pass-through is enabled and both the Arrow consumer and the next
+// consumer of the batch's signal support it.  ok is false otherwise,
+// and the batch is to be decoded into pdata.
+func (r *Receiver) requestFrom(arrowConsumer arrowRecord.ConsumerAPI, records *arrowpb.BatchArrowRecords) (retErr error, retData any, numItems int, size int64, ok bool) {
+	if !r.passthrough {
+		return nil, nil, 0, 0, false
+	}
+	requests, ok := arrowConsumer.(arrowRecord.RequestConsumerAPI)
+	if !ok {
+		return nil, nil, 0, 0, false
+	}
+	var request []byte
+	switch records.GetArrowPayloads()[0].Type {
+	case arrowpb.ArrowPayloadType_UNIVARIATE_METRICS:
+		next, isRequest := r.Metrics().(passthrough.Metrics)
+		if !isRequest {
+			return nil, nil, 0, 0, false
+		}
+		request, numItems, retErr = requests.MetricsRequestFrom(records)
+		retData = metricsRequest{next: next, request: request}
+
+	case arrowpb.ArrowPayloadType_LOGS:
+		next, isRequest := r.Logs().(passthrough.Logs)
+		if !isRequest {
+			return nil, nil, 0, 0, false
+		}
+		request, numItems, retErr = requests.LogsRequestFrom(records)
+		retData = logsRequest{next: next, request: request}
+
+	case arrowpb.ArrowPayloadType_SPANS:
+		next, isRequest := r.Traces().(passthrough.Traces)
+		if !isRequest {
+			return nil, nil, 0, 0, false
+		}
+		request, numItems, retErr = requests.TracesRequestFrom(records)
+		retData = tracesRequest{next: next, request: request}
+
+	default:
+		return nil, nil, 0, 0, false
+	}
+	if retErr != nil {
+		return retErr, nil, 0, 0, true
+	}
+	return nil, retData, numItems, int64(len(request)), true
+}
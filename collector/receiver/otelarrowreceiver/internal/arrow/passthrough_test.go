@@ -0,0 +1,248 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+
+	"github.com/open-telemetry/otel-arrow/collector/passthrough"
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	otelAssert "github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+)
+
+// passthroughConsumers replaces the consumers with ones that also
+// consume requests.
+type passthroughConsumers struct {
+	mockConsumers
+	next passthroughNext
+}
+
+func (p passthroughConsumers) Traces() consumer.Traces {
+	return passthroughTraces{p.next}
+}
+
+func (p passthroughConsumers) Metrics() consumer.Metrics {
+	return passthroughMetrics{p.next}
+}
+
+func (p passthroughConsumers) Logs() consumer.Logs {
+	return passthroughLogs{p.next}
+}
+
+// passthroughNext delivers the requests it consumes, unmarshaled, to
+// the test case.
+type passthroughNext struct {
+	mockConsumers
+	ctc *commonTestCase
+	tc  testChannel
+}
+
+func (p passthroughNext) consume(ctx context.Context, data any) error {
+	p.ctc.consume <- consumeResult{
+		Ctx:  ctx,
+		Data: data,
+	}
+	return p.tc.onConsume()
+}
+
+type passthroughTraces struct{ passthroughNext }
+
+var _ passthrough.Traces = passthroughTraces{}
+
+func (p passthroughTraces) Capabilities() consumer.Capabilities {
+	return p.traces.Capabilities()
+}
+
+func (p passthroughTraces) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	return p.traces.ConsumeTraces(ctx, td)
+}
+
+func (p passthroughTraces) ConsumeTracesRequest(ctx context.Context, request []byte) error {
+	req := ptraceotlp.NewExportRequest()
+	if err := req.UnmarshalProto(request); err != nil {
+		return err
+	}
+	return p.consume(ctx, req)
+}
+
+type passthroughMetrics struct{ passthroughNext }
+
+var _ passthrough.Metrics = passthroughMetrics{}
+
+func (p passthroughMetrics) Capabilities() consumer.Capabilities {
+	return p.metrics.Capabilities()
+}
+
+func (p passthroughMetrics) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	return p.metrics.ConsumeMetrics(ctx, md)
+}
+
+func (p passthroughMetrics) ConsumeMetricsRequest(ctx context.Context, request []byte) error {
+	req := pmetricotlp.NewExportRequest()
+	if err := req.UnmarshalProto(request); err != nil {
+		return err
+	}
+	return p.consume(ctx, req)
+}
+
+type passthroughLogs struct{ passthroughNext }
+
+var _ passthrough.Logs = passthroughLogs{}
+
+func (p passthroughLogs) Capabilities() consumer.Capabilities {
+	return p.logs.Capabilities()
+}
+
+func (p passthroughLogs) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return p.logs.ConsumeLogs(ctx, ld)
+}
+
+func (p passthroughLogs) ConsumeLogsRequest(ctx context.Context, request []byte) error {
+	req := plogotlp.NewExportRequest()
+	if err := req.UnmarshalProto(request); err != nil {
+		return err
+	}
+	return p.consume(ctx, req)
+}
+
+func newPassthroughTestCase(t *testing.T, tc testChannel, enable bool) *commonTestCase {
+	ctc := newCommonTestCase(t, tc)
+	ctc.next = passthroughConsumers{
+		mockConsumers: ctc.consumers,
+		next: passthroughNext{
+			mockConsumers: ctc.consumers,
+			ctc:           ctc,
+			tc:            tc,
+		},
+	}
+	if enable {
+		ctc.receiverOpts = append(ctc.receiverOpts, WithPassthrough())
+	}
+	return ctc
+}
+
+// newRequestConsumer returns a real consumer, which implements
+// arrowRecord.RequestConsumerAPI, unlike the mock.
+func (ctc *commonTestCase) newRequestConsumer() arrowRecord.ConsumerAPI {
+	return arrowRecord.NewConsumer()
+}
+
+func TestReceiverTracesPassthrough(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newPassthroughTestCase(t, tc, true)
+
+	td := testdata.GenerateTraces(2)
+	batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(td)
+	require.NoError(t, err)
+
+	ctc.stream.EXPECT().Send(statusOKFor(batch.BatchId)).Times(1).Return(nil)
+
+	ctc.start(ctc.newRequestConsumer, defaultBQ())
+	ctc.putBatch(batch, nil)
+
+	req := (<-ctc.consume).Data.(ptraceotlp.ExportRequest)
+	assert.EqualValues(t, []json.Marshaler{compareJSONTraces{td}}, []json.Marshaler{compareJSONTraces{req.Traces()}})
+
+	err = ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+}
+
+func TestReceiverMetricsPassthrough(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newPassthroughTestCase(t, tc, true)
+	stdTesting := otelAssert.NewStdUnitTest(t)
+
+	md := testdata.GenerateMetrics(2)
+	batch, err := ctc.testProducer.BatchArrowRecordsFromMetrics(md)
+	require.NoError(t, err)
+
+	ctc.stream.EXPECT().Send(statusOKFor(batch.BatchId)).Times(1).Return(nil)
+
+	ctc.start(ctc.newRequestConsumer, defaultBQ())
+	ctc.putBatch(batch, nil)
+
+	req := (<-ctc.consume).Data.(pmetricotlp.ExportRequest)
+	otelAssert.Equiv(stdTesting, []json.Marshaler{
+		compareJSONMetrics{md},
+	}, []json.Marshaler{
+		compareJSONMetrics{req.Metrics()},
+	})
+
+	err = ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+}
+
+func TestReceiverLogsPassthrough(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newPassthroughTestCase(t, tc, true)
+
+	ld := testdata.GenerateLogs(2)
+	batch, err := ctc.testProducer.BatchArrowRecordsFromLogs(ld)
+	require.NoError(t, err)
+
+	ctc.stream.EXPECT().Send(statusOKFor(batch.BatchId)).Times(1).Return(nil)
+
+	ctc.start(ctc.newRequestConsumer, defaultBQ())
+	ctc.putBatch(batch, nil)
+
+	req := (<-ctc.consume).Data.(plogotlp.ExportRequest)
+	assert.EqualValues(t, []json.Marshaler{compareJSONLogs{ld}}, []json.Marshaler{compareJSONLogs{req.Logs()}})
+
+	err = ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+}
+
+func TestReceiverLogsPassthroughDisabled(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newPassthroughTestCase(t, tc, false)
+
+	ld := testdata.GenerateLogs(2)
+	batch, err := ctc.testProducer.BatchArrowRecordsFromLogs(ld)
+	require.NoError(t, err)
+
+	ctc.stream.EXPECT().Send(statusOKFor(batch.BatchId)).Times(1).Return(nil)
+
+	ctc.start(ctc.newRequestConsumer, defaultBQ())
+	ctc.putBatch(batch, nil)
+
+	assert.EqualValues(t, []json.Marshaler{compareJSONLogs{ld}}, []json.Marshaler{compareJSONLogs{(<-ctc.consume).Data.(plog.Logs)}})
+
+	err = ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+}
+
+// TestReceiverPassthroughUnsupported verifies that with pass-through
+// enabled, a consumer that does not accept requests consumes pdata.
+func TestReceiverPassthroughUnsupported(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+	ctc.receiverOpts = append(ctc.receiverOpts, WithPassthrough())
+
+	td := testdata.GenerateTraces(2)
+	batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(td)
+	require.NoError(t, err)
+
+	ctc.stream.EXPECT().Send(statusOKFor(batch.BatchId)).Times(1).Return(nil)
+
+	ctc.start(ctc.newRequestConsumer, defaultBQ())
+	ctc.putBatch(batch, nil)
+
+	assert.EqualValues(t, []json.Marshaler{compareJSONTraces{td}}, []json.Marshaler{compareJSONTraces{(<-ctc.consume).Data.(ptrace.Traces)}})
+
+	err = ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+}
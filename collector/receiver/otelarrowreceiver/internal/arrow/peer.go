@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
+)
+
+// WithPeerInstanceMetrics counts the batches received from each
+// exporter's collector, by the instance ID and the deployment labels
+// it declares when a stream opens.  The number of series grows with
+// the number of collectors, so that this is meant for debugging.
+func WithPeerInstanceMetrics() Option {
+	return func(r *Receiver) {
+		r.peerInstanceMetrics = true
+	}
+}
+
+// streamPeer describes the exporter of a stream, as declared by the
+// metadata of the stream.  Every field is optional.
+type streamPeer struct {
+	// streamID is the exporter's identifier of the stream.
+	streamID string
+
+	// instanceID identifies the exporter's collector.
+	instanceID string
+
+	// labels are the deployment labels of the exporter's
+	// collector, in the order they were sent.
+	labels []attribute.KeyValue
+
+	// metricAttrs are the attributes of the peer metrics, see
+	// WithPeerInstanceMetrics().
+	metricAttrs metric.MeasurementOption
+}
+
+// newStreamPeer returns the peer declared by the metadata of a
+// stream.  Malformed labels are ignored.
+func newStreamPeer(streamCtx context.Context) streamPeer {
+	var p streamPeer
+	if ids := metadata.ValueFromIncomingContext(streamCtx, streamevents.StreamIDHeader); len(ids) != 0 {
+		p.streamID = ids[0]
+	}
+	if ids := metadata.ValueFromIncomingContext(streamCtx, streamevents.InstanceIDHeader); len(ids) != 0 {
+		p.instanceID = ids[0]
+	}
+	for _, label := range metadata.ValueFromIncomingContext(streamCtx, streamevents.InstanceLabelsHeader) {
+		if name, value, ok := streamevents.ParseLabel(label); ok {
+			p.labels = append(p.labels, attribute.String(streamevents.PeerLabelPrefix+name, value))
+		}
+	}
+	p.metricAttrs = metric.WithAttributeSet(attribute.NewSet(p.identity()...))
+	return p
+}
+
+// identity returns the attributes identifying the peer's collector.
+func (p streamPeer) identity() []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if p.instanceID != "" {
+		attrs = append(attrs, attribute.String(streamevents.PeerInstanceIDKey, p.instanceID))
+	}
+	return append(attrs, p.labels...)
+}
+
+// attributes returns the span attributes of the peer.
+func (p streamPeer) attributes() []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if p.streamID != "" {
+		attrs = append(attrs, attribute.String(streamevents.PeerStreamIDKey, p.streamID))
+	}
+	return append(attrs, p.identity()...)
+}
+
+// logFields returns the log fields of the peer.
+func (p streamPeer) logFields() []zap.Field {
+	var fields []zap.Field
+	for _, kv := range p.attributes() {
+		fields = append(fields, zap.String(string(kv.Key), kv.Value.AsString()))
+	}
+	return fields
+}
+
+// labelString formats the labels of the peer for display.
+func (p streamPeer) labelString() string {
+	var labels []string
+	for _, kv := range p.labels {
+		name := strings.TrimPrefix(string(kv.Key), streamevents.PeerLabelPrefix)
+		labels = append(labels, streamevents.FormatLabel(name, kv.Value.AsString()))
+	}
+	return strings.Join(labels, ",")
+}
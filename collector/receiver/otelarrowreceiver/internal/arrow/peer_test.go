@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+)
+
+func TestStreamPeer(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		streamevents.StreamIDHeader, testPeerStreamID,
+		streamevents.InstanceIDHeader, "agent-42",
+		streamevents.InstanceLabelsHeader, "cluster=a",
+		streamevents.InstanceLabelsHeader, "=malformed",
+		streamevents.InstanceLabelsHeader, "region=us-east-1",
+	))
+	peer := newStreamPeer(ctx)
+
+	require.Equal(t, testPeerStreamID, peer.streamID)
+	require.Equal(t, "agent-42", peer.instanceID)
+	require.Equal(t, "cluster=a,region=us-east-1", peer.labelString())
+	require.Equal(t, []attribute.KeyValue{
+		attribute.String(streamevents.PeerStreamIDKey, testPeerStreamID),
+		attribute.String(streamevents.PeerInstanceIDKey, "agent-42"),
+		attribute.String(streamevents.PeerLabelPrefix+"cluster", "a"),
+		attribute.String(streamevents.PeerLabelPrefix+"region", "us-east-1"),
+	}, peer.attributes())
+	require.Equal(t, zap.String(streamevents.PeerInstanceIDKey, "agent-42"), peer.logFields()[1])
+
+	// Exporters that do not send their identity have none.
+	peer = newStreamPeer(context.Background())
+	require.Empty(t, peer.attributes())
+	require.Empty(t, peer.logFields())
+	require.Equal(t, "", peer.labelString())
+}
+
+func TestReceiverPeerInstanceMetrics(t *testing.T) {
+	tc := healthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+	ctc.receiverOpts = append(ctc.receiverOpts, WithPeerInstanceMetrics())
+
+	// The batch is counted without the identity of the exporter.
+	batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+	require.NoError(t, err)
+	ctc.stream.EXPECT().Send(statusOKFor(batch.BatchId)).Times(1).Return(nil)
+
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+	ctc.putBatch(batch, nil)
+	<-ctc.consume
+
+	err = ctc.cancelAndWait()
+	requireCanceledStatus(t, err)
+}
@@ -0,0 +1,13 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+// WithReceiverID includes id in every BatchStatus, so that exporters
+// can tell which collector responded to their batches, e.g., behind
+// a load balancer.
+func WithReceiverID(id string) Option {
+	return func(r *Receiver) {
+		r.receiverID = id
+	}
+}
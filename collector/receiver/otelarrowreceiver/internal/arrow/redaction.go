@@ -0,0 +1,14 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+// WithRedaction replaces the messages of the statuses returned to
+// exporters, and the error messages logged by streams, with a digest
+// of the message, since they may contain client metadata or
+// attribute values, see the redact package.  Status codes are kept.
+func WithRedaction() Option {
+	return func(r *Receiver) {
+		r.redactErrors = true
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/testdata"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/otel-arrow/collector/redact"
+	"github.com/open-telemetry/otel-arrow/collector/streamevents"
+)
+
+func TestReceiverRedaction(t *testing.T) {
+	tc := unhealthyTestChannel{}
+	ctc := newCommonTestCase(t, tc)
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctc.telset.Logger = zap.New(core)
+	ctc.receiverOpts = append(ctc.receiverOpts, WithRedaction())
+
+	td := testdata.GenerateTraces(2)
+	batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(td)
+	require.NoError(t, err)
+
+	// The consumer's error and the error that ends the stream
+	// are redacted, keeping their codes.
+	ctc.stream.EXPECT().Send(statusUnavailableFor(batch.BatchId, redact.Message("consumer unhealthy"))).
+		Times(1).Return(status.Errorf(codes.Unavailable, "tenant=abc send error"))
+
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+	ctc.putBatch(batch, nil)
+	<-ctc.consume
+
+	start := time.Now()
+	for time.Since(start) < 10*time.Second && !ctc.ctrl.Satisfied() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(ctc.receive)
+	err = ctc.wait()
+	requireUnavailableStatus(t, err)
+	require.True(t, redact.IsRedacted(status.Convert(err).Message()))
+
+	failed := logs.FilterField(zap.String(streamevents.EventKey, streamevents.StreamError)).All()
+	require.Len(t, failed, 1)
+	require.Equal(t, redact.Message("tenant=abc send error"), failed[0].ContextMap()[redact.MessageKey])
+}
@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	"context"
+)
+
+// WithStreamWorkers delivers the batches of each stream to the
+// pipeline with a pool of n worker goroutines, instead of a goroutine
+// per batch.  Batches are still decoded in order by the stream's
+// reader, since each depends on the Arrow state left by the previous
+// one, then delivered concurrently, and their statuses carry their
+// batch IDs.  While every worker is busy, the reader holds the next
+// decoded batch and stops receiving, so that a slow consumer blocks
+// at most n batches of the stream.  Values below 1 start a goroutine
+// per batch.
+func WithStreamWorkers(n int) Option {
+	return func(r *Receiver) {
+		r.streamWorkers = n
+	}
+}
+
+// streamWorkers is the worker pool of one stream.  A nil
+// *streamWorkers starts a goroutine per batch.
+type streamWorkers struct {
+	work chan func()
+}
+
+// newStreamWorkers starts the worker pool of a new stream.
+func (r *Receiver) newStreamWorkers() *streamWorkers {
+	if r.streamWorkers <= 0 {
+		return nil
+	}
+	w := &streamWorkers{
+		work: make(chan func()),
+	}
+	for i := 0; i < r.streamWorkers; i++ {
+		go func() {
+			for f := range w.work {
+				f()
+			}
+		}()
+	}
+	return w
+}
+
+// run calls f on a worker, waiting for one to be free.  When ctx
+// ends first, f runs on its own goroutine, so that the batch is
+// answered as the stream shuts down.
+func (w *streamWorkers) run(ctx context.Context, f func()) {
+	if w == nil {
+		go f()
+		return
+	}
+	select {
+	case w.work <- f:
+	case <-ctx.Done():
+		go f()
+	}
+}
+
+// stop ends the workers once they finish their batches.  The stream's
+// reader calls it after its last call to run.
+func (w *streamWorkers) stop() {
+	if w == nil {
+		return
+	}
+	close(w.work)
+}
@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+)
+
+func TestStreamWorkers(t *testing.T) {
+	w := (&Receiver{streamWorkers: 2}).newStreamWorkers()
+	ctx := context.Background()
+
+	var running atomic.Int32
+	var wg sync.WaitGroup
+	release := make(chan struct{})
+	work := func() {
+		defer wg.Done()
+		running.Add(1)
+		<-release
+	}
+
+	wg.Add(2)
+	w.run(ctx, work)
+	w.run(ctx, work)
+
+	// Both workers are busy at once.
+	require.Eventually(t, func() bool {
+		return running.Load() == 2
+	}, time.Second, time.Millisecond)
+	ran := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		w.run(ctx, work)
+		close(ran)
+	}()
+	select {
+	case <-ran:
+		t.Fatal("ran without a free worker")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	<-ran
+	wg.Wait()
+	w.stop()
+	require.Equal(t, int32(3), running.Load())
+
+	// Without a free worker, a canceled stream runs the batch on
+	// its own goroutine.
+	w = (&Receiver{streamWorkers: 1}).newStreamWorkers()
+	defer w.stop()
+	block := make(chan struct{})
+	defer close(block)
+	w.run(ctx, func() { <-block })
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	done := make(chan struct{})
+	w.run(canceled, func() { close(done) })
+	<-done
+
+	// A nil pool starts a goroutine per batch.
+	var disabled *streamWorkers
+	done = make(chan struct{})
+	disabled.run(ctx, func() { close(done) })
+	<-done
+	disabled.stop()
+}
+
+func TestReceiverStreamWorkers(t *testing.T) {
+	ctc := newCommonTestCase(t, healthyTestChannel{})
+	ctc.receiverOpts = append(ctc.receiverOpts, WithStreamWorkers(1))
+	ctc.stream.EXPECT().Send(gomock.Any()).Times(3).Return(nil)
+	ctc.start(ctc.newRealConsumer, defaultBQ())
+
+	var batches []*arrowpb.BatchArrowRecords
+	for i := 0; i < 3; i++ {
+		batch, err := ctc.testProducer.BatchArrowRecordsFromTraces(testdata.GenerateTraces(2))
+		require.NoError(t, err)
+		batches = append(batches, batch)
+	}
+
+	// The worker is blocked on the first batch, and the stream
+	// holds the second, so it does not receive the third.
+	ctc.putBatch(batches[0], nil)
+	ctc.putBatch(batches[1], nil)
+	select {
+	case ctc.receive <- recvResult{payload: batches[2]}:
+		t.Fatal("stream received without a free worker")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	<-ctc.consume
+	ctc.putBatch(batches[2], nil)
+	<-ctc.consume
+	<-ctc.consume
+
+	close(ctc.receive)
+	requireCanceledStatus(t, ctc.wait())
+}
@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Option configures optional Receiver behavior.
+type Option func(*Receiver)
+
+// WithZstdDictionaryIDs sets the IDs of the Zstd dictionaries that
+// the Receiver's consumers are configured with.
+func WithZstdDictionaryIDs(ids ...uint32) Option {
+	return func(r *Receiver) {
+		r.zstdDictIDs = map[uint32]bool{}
+		for _, id := range ids {
+			r.zstdDictIDs[id] = true
+		}
+	}
+}
+
+// checkZstdDictionary rejects a stream that declares a Zstd
+// dictionary this receiver does not have, before any payload is
+// read, so that the client sees the misconfiguration at stream
+// start instead of as failed batches.
+func (r *Receiver) checkZstdDictionary(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get(zstddict.Header) {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid %s: %q", zstddict.Header, value)
+		}
+		if !r.zstdDictIDs[uint32(id)] {
+			return status.Errorf(codes.FailedPrecondition, "unknown zstd dictionary ID: %d", id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package arrow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheckZstdDictionary(t *testing.T) {
+	r := &Receiver{}
+	WithZstdDictionaryIDs(7, 9)(r)
+
+	streamCtx := func(kv ...string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+	}
+
+	require.NoError(t, r.checkZstdDictionary(context.Background()))
+	require.NoError(t, r.checkZstdDictionary(streamCtx("other", "1")))
+	require.NoError(t, r.checkZstdDictionary(streamCtx(zstddict.Header, "9")))
+
+	err := r.checkZstdDictionary(streamCtx(zstddict.Header, "8"))
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.Contains(t, err.Error(), "unknown zstd dictionary ID: 8")
+
+	err = r.checkZstdDictionary(streamCtx(zstddict.Header, "x"))
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Without configured dictionaries, any declaration fails.
+	err = (&Receiver{}).checkZstdDictionary(streamCtx(zstddict.Header, "7"))
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -6,9 +6,14 @@ package otelarrowreceiver // import "github.com/open-telemetry/otel-arrow/collec
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"os"
 	"sync"
 
 	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/arrowzpages"
+	"github.com/open-telemetry/otel-arrow/collector/capability"
 	"github.com/open-telemetry/otel-arrow/collector/compression/zstd"
 	"github.com/open-telemetry/otel-arrow/collector/netstats"
 	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
@@ -22,6 +27,8 @@ import (
 	"go.opentelemetry.io/collector/receiver"
 	"go.opentelemetry.io/collector/receiver/receiverhelper"
 	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"google.golang.org/grpc"
 
 	"github.com/open-telemetry/otel-arrow/collector/admission"
@@ -35,6 +42,7 @@ import (
 type otelArrowReceiver struct {
 	cfg        *Config
 	serverGRPC *grpc.Server
+	serverHTTP *http.Server
 
 	tracesReceiver  *trace.Receiver
 	metricsReceiver *metrics.Receiver
@@ -43,27 +51,35 @@ type otelArrowReceiver struct {
 	shutdownWG      sync.WaitGroup
 
 	obsrepGRPC  *receiverhelper.ObsReport
+	obsrepHTTP  *receiverhelper.ObsReport
 	netReporter *netstats.NetworkReporter
+	// status is registered with the arrowzpages extension.
+	status *arrowzpages.Instance
+	// auditFile receives the audit log, see AuditConfig.
+	auditFile *os.File
 
 	settings receiver.CreateSettings
+	options  factoryOptions
 }
 
 // newOTelArrowReceiver just creates the OpenTelemetry receiver services. It is the caller's
 // responsibility to invoke the respective Start*Reception methods as well
 // as the various Stop*Reception methods to end it.
-func newOTelArrowReceiver(cfg *Config, set receiver.CreateSettings) (*otelArrowReceiver, error) {
-	netReporter, err := netstats.NewReceiverNetworkReporter(set)
+func newOTelArrowReceiver(cfg *Config, set receiver.CreateSettings, fo factoryOptions) (*otelArrowReceiver, error) {
+	netReporter, err := netstats.NewReceiverNetworkReporter(set, cfg.netstatsOptions()...)
 	if err != nil {
 		return nil, err
 	}
 	r := &otelArrowReceiver{
 		cfg:         cfg,
 		settings:    set,
+		options:     fo,
 		netReporter: netReporter,
 	}
 	if err = zstd.SetDecoderConfig(cfg.Arrow.Zstd); err != nil {
 		return nil, err
 	}
+	cfg.warnDeprecatedAdmission(set.Logger)
 
 	r.obsrepGRPC, err = receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{
 		ReceiverID:             set.ID,
@@ -73,6 +89,14 @@ func newOTelArrowReceiver(cfg *Config, set receiver.CreateSettings) (*otelArrowR
 	if err != nil {
 		return nil, err
 	}
+	r.obsrepHTTP, err = receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{
+		ReceiverID:             set.ID,
+		Transport:              "http",
+		ReceiverCreateSettings: set,
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	return r, nil
 }
@@ -95,6 +119,58 @@ func (r *otelArrowReceiver) startGRPCServer(cfg configgrpc.ServerConfig, _ compo
 	return nil
 }
 
+// startHTTPServer serves the Arrow streams of the signals with a
+// consumer over HTTP/2, with or without TLS, and their OTLP/HTTP
+// requests.
+func (r *otelArrowReceiver) startHTTPServer(ctx context.Context, cfg *HTTPConfig, host component.Host) error {
+	mux := http.NewServeMux()
+	if r.tracesReceiver != nil {
+		mux.HandleFunc(cfg.TracesURLPath, r.arrowReceiver.HTTPTraces)
+		mux.HandleFunc(cfg.OTLPTracesURLPath, otlpHandler(ptraceotlp.NewExportRequest,
+			trace.New(r.tracesReceiver.Consumer(), r.obsrepHTTP).Export))
+	}
+	if r.metricsReceiver != nil {
+		mux.HandleFunc(cfg.MetricsURLPath, r.arrowReceiver.HTTPMetrics)
+		mux.HandleFunc(cfg.OTLPMetricsURLPath, otlpHandler(pmetricotlp.NewExportRequest,
+			metrics.New(r.metricsReceiver.Consumer(), r.obsrepHTTP).Export))
+	}
+	if r.logsReceiver != nil {
+		mux.HandleFunc(cfg.LogsURLPath, r.arrowReceiver.HTTPLogs)
+		mux.HandleFunc(cfg.OTLPLogsURLPath, otlpHandler(plogotlp.NewExportRequest,
+			logs.New(r.logsReceiver.Consumer(), r.obsrepHTTP).Export))
+	}
+
+	var err error
+	r.serverHTTP, err = cfg.ToServerContext(ctx, host, r.settings.TelemetrySettings, mux)
+	if err != nil {
+		return err
+	}
+	// Without TLS, HTTP/2 is served as h2c, outside of the
+	// server's handlers so that each stream passes through them.
+	// ConfigureServer sends GOAWAY to the streams' connections on
+	// Shutdown.
+	h2s := &http2.Server{}
+	r.serverHTTP.Handler = h2c.NewHandler(r.serverHTTP.Handler, h2s)
+	if err = http2.ConfigureServer(r.serverHTTP, h2s); err != nil {
+		return err
+	}
+
+	r.settings.Logger.Info("Starting HTTP server", zap.String("endpoint", cfg.Endpoint))
+	hln, err := cfg.ToListenerContext(ctx)
+	if err != nil {
+		return err
+	}
+	r.shutdownWG.Add(1)
+	go func() {
+		defer r.shutdownWG.Done()
+
+		if errHTTP := r.serverHTTP.Serve(hln); errHTTP != nil && !errors.Is(errHTTP, http.ErrServerClosed) {
+			r.settings.ReportStatus(component.NewFatalErrorEvent(errHTTP))
+		}
+	}()
+	return nil
+}
+
 func (r *otelArrowReceiver) startProtocolServers(host component.Host) error {
 	var err error
 	var serverOpts []grpc.ServerOption
@@ -102,6 +178,9 @@ func (r *otelArrowReceiver) startProtocolServers(host component.Host) error {
 	if r.netReporter != nil {
 		serverOpts = append(serverOpts, grpc.StatsHandler(r.netReporter.Handler()))
 	}
+	for _, h := range r.options.statsHandlers {
+		serverOpts = append(serverOpts, grpc.StatsHandler(h))
+	}
 	r.serverGRPC, err = r.cfg.GRPC.ToServer(context.Background(), host, r.settings.TelemetrySettings, serverOpts...)
 	if err != nil {
 		return err
@@ -114,8 +193,72 @@ func (r *otelArrowReceiver) startProtocolServers(host component.Host) error {
 			return err
 		}
 	}
-	bq :=  admission.NewBoundedQueue(int64(r.cfg.Arrow.AdmissionLimitMiB<<20), r.cfg.Arrow.WaiterLimit)
+	requestLimit, waiterLimit := r.cfg.admissionLimits()
+	bq :=  admission.NewBoundedQueue(requestLimit, waiterLimit, r.cfg.Arrow.admissionOptions()...)
+
+	dicts, dictIDs, err := r.cfg.Arrow.loadZstdDictionaries()
+	if err != nil {
+		return err
+	}
+
+	arrowOpts := []arrow.Option{
+		arrow.WithZstdDictionaryIDs(dictIDs...),
+		arrow.WithCapabilities(r.capabilities()),
+	}
+	if r.cfg.Arrow.passthrough() {
+		arrowOpts = append(arrowOpts, arrow.WithPassthrough())
+	}
+	if r.cfg.Arrow.OrderedResponses {
+		arrowOpts = append(arrowOpts, arrow.WithOrderedResponses())
+	}
+	if r.cfg.Arrow.DedupWindow > 0 {
+		arrowOpts = append(arrowOpts, arrow.WithDeduplication(r.cfg.Arrow.DedupWindow))
+	}
+	if r.cfg.Arrow.StreamInFlightLimitMiB != 0 {
+		arrowOpts = append(arrowOpts, arrow.WithStreamInFlightLimit(int64(r.cfg.Arrow.StreamInFlightLimitMiB<<20)))
+	}
+	if r.cfg.Arrow.MaxConcurrentBatchesPerStream > 0 {
+		arrowOpts = append(arrowOpts, arrow.WithMaxConcurrentBatchesPerStream(r.cfg.Arrow.MaxConcurrentBatchesPerStream))
+	}
+	if r.cfg.Arrow.CreditBatches > 0 || r.cfg.Arrow.CreditMiB != 0 {
+		arrowOpts = append(arrowOpts, arrow.WithFlowControlCredits(r.cfg.Arrow.CreditBatches, int64(r.cfg.Arrow.CreditMiB<<20)))
+	}
+	if r.cfg.Arrow.StreamWorkers > 0 {
+		arrowOpts = append(arrowOpts, arrow.WithStreamWorkers(r.cfg.Arrow.StreamWorkers))
+	}
+	if r.cfg.Arrow.IncludeReceiverID {
+		arrowOpts = append(arrowOpts, arrow.WithReceiverID(r.receiverID()))
+	}
+	if r.cfg.GRPC.IncludeMetadata {
+		arrowOpts = append(arrowOpts, arrow.WithMetadataFilter(r.cfg.Arrow.MetadataAllowedKeys, r.cfg.Arrow.MetadataDeniedKeys))
+	}
+	if r.cfg.Arrow.RedactErrors {
+		arrowOpts = append(arrowOpts, arrow.WithRedaction())
+	}
+	if r.cfg.Arrow.PeerInstanceMetrics {
+		arrowOpts = append(arrowOpts, arrow.WithPeerInstanceMetrics())
+	}
+	if audit := r.cfg.Arrow.Audit; audit.Path != "" {
+		r.auditFile, err = os.OpenFile(audit.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+		var identify func(context.Context) string
+		if audit.MetadataKey != "" || audit.AuthAttribute != "" {
+			identify = clientIdentity(audit.MetadataKey, audit.AuthAttribute)
+		}
+		arrowOpts = append(arrowOpts, arrow.WithAuditLog(arrow.NewAuditLog(r.auditFile, identify, arrow.AuditSampling{
+			Initial:    audit.Sampling.Initial,
+			Thereafter: audit.Sampling.Thereafter,
+			Tick:       audit.Sampling.Tick,
+		})))
+	}
+	for _, ic := range r.options.interceptors {
+		arrowOpts = append(arrowOpts, arrow.WithBatchStatusInterceptor(ic.InterceptBatchStatus))
+	}
 
+	r.status = arrowzpages.Register(component.KindReceiver, r.settings.ID.String())
+	r.status.SetConfig(r.effectiveConfig())
 	r.arrowReceiver, err = arrow.New(arrow.Consumers(r), r.settings, r.obsrepGRPC, r.cfg.GRPC, authServer, func() arrowRecord.ConsumerAPI {
 		var opts []arrowRecord.Option
 		if r.cfg.Arrow.MemoryLimitMiB != 0 {
@@ -125,8 +268,20 @@ func (r *otelArrowReceiver) startProtocolServers(host component.Host) error {
 		if r.settings.TelemetrySettings.MeterProvider != nil {
 			opts = append(opts, arrowRecord.WithMeterProvider(r.settings.TelemetrySettings.MeterProvider, r.settings.TelemetrySettings.MetricsLevel))
 		}
+		if len(dicts) != 0 {
+			opts = append(opts, arrowRecord.WithZstdDictionaries(dicts...))
+		}
+		if r.cfg.Arrow.MaxSchemas != 0 {
+			opts = append(opts, arrowRecord.WithMaxSchemas(r.cfg.Arrow.MaxSchemas))
+		}
+		opts = append(opts,
+			arrowRecord.WithZstdDecoderConcurrency(int(r.cfg.Arrow.PayloadZstd.Concurrency)),
+			arrowRecord.WithZstdMaxWindow(uint64(r.cfg.Arrow.PayloadZstd.WindowSizeMiB)<<20),
+			// Batches decoded but not consumed are released.
+			arrowRecord.WithPooledPdata(),
+		)
 		return arrowRecord.NewConsumer(opts...)
-	}, bq, r.netReporter)
+	}, bq, r.netReporter, r.status, arrowOpts...)
 
 	if err != nil {
 		return err
@@ -155,6 +310,9 @@ func (r *otelArrowReceiver) startProtocolServers(host component.Host) error {
 		return err
 	}
 
+	if r.cfg.HTTP != nil {
+		err = r.startHTTPServer(context.Background(), r.cfg.HTTP, host)
+	}
 	return err
 }
 
@@ -165,27 +323,34 @@ func (r *otelArrowReceiver) Start(_ context.Context, host component.Host) error
 }
 
 // Shutdown is a method to turn off receiving.
-func (r *otelArrowReceiver) Shutdown(_ context.Context) error {
+func (r *otelArrowReceiver) Shutdown(ctx context.Context) error {
 	var err error
 
+	if r.serverHTTP != nil {
+		err = r.serverHTTP.Shutdown(ctx)
+	}
 	if r.serverGRPC != nil {
 		r.serverGRPC.GracefulStop()
 	}
 
 	r.shutdownWG.Wait()
+	r.status.Unregister()
+	if r.auditFile != nil {
+		err = errors.Join(err, r.auditFile.Close())
+	}
 	return err
 }
 
 func (r *otelArrowReceiver) registerTraceConsumer(tc consumer.Traces) {
-	r.tracesReceiver = trace.New(tc, r.obsrepGRPC)
+	r.tracesReceiver = trace.New(resourceAttributes(r.cfg.ResourceAttributes).traces(tc), r.obsrepGRPC)
 }
 
 func (r *otelArrowReceiver) registerMetricsConsumer(mc consumer.Metrics) {
-	r.metricsReceiver = metrics.New(mc, r.obsrepGRPC)
+	r.metricsReceiver = metrics.New(resourceAttributes(r.cfg.ResourceAttributes).metrics(mc), r.obsrepGRPC)
 }
 
 func (r *otelArrowReceiver) registerLogsConsumer(lc consumer.Logs) {
-	r.logsReceiver = logs.New(lc, r.obsrepGRPC)
+	r.logsReceiver = logs.New(resourceAttributes(r.cfg.ResourceAttributes).logs(lc), r.obsrepGRPC)
 }
 
 var _ arrow.Consumers = &otelArrowReceiver{}
@@ -210,3 +375,54 @@ func (r *otelArrowReceiver) Logs() consumer.Logs {
 	}
 	return r.logsReceiver.Consumer()
 }
+
+// effectiveConfig is the configuration reported to the arrowzpages
+// extension.
+type effectiveConfig struct {
+	Arrow ArrowConfig `mapstructure:"arrow"`
+}
+
+// effectiveConfig returns the Arrow settings in effect, with their
+// defaults resolved.
+func (r *otelArrowReceiver) effectiveConfig() effectiveConfig {
+	arrowCfg := r.cfg.Arrow
+	arrowCfg.Passthrough = arrowCfg.passthrough()
+	if arrowCfg.MaxSchemas == 0 {
+		arrowCfg.MaxSchemas = arrowRecord.DefaultMaxSchemas
+	}
+	return effectiveConfig{Arrow: arrowCfg}
+}
+
+// receiverID identifies this collector in responses by its host name
+// and, when known, its service.instance.id.
+func (r *otelArrowReceiver) receiverID() string {
+	id, err := os.Hostname()
+	if err != nil {
+		id = "unknown"
+	}
+	if inst, ok := r.settings.TelemetrySettings.Resource.Attributes().Get("service.instance.id"); ok {
+		id += "/" + inst.AsString()
+	}
+	return id
+}
+
+// defaultMaxRecvMsgSize is the gRPC server's default maximum message
+// size.
+const defaultMaxRecvMsgSize = 4 << 20
+
+// capabilities are declared to exporters when their streams open.
+// The consumer decodes every codec, including whole-payload
+// compression, and dictionary deltas, and batches
+// are limited by the gRPC server's maximum message size.
+func (r *otelArrowReceiver) capabilities() capability.Set {
+	maxBytes := int64(defaultMaxRecvMsgSize)
+	if r.cfg.GRPC.MaxRecvMsgSizeMiB != 0 {
+		maxBytes = int64(r.cfg.GRPC.MaxRecvMsgSizeMiB << 20)
+	}
+	return capability.Set{
+		Versions:         []int{capability.Version},
+		Compression:      []string{"none", "zstd", capability.CompressionZstdPayload},
+		DictionaryDeltas: true,
+		MaxBatchBytes:    maxBytes,
+	}
+}
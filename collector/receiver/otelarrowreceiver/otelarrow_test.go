@@ -6,15 +6,26 @@ package otelarrowreceiver
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"testing"
 	"time"
 
 	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/collector/arrowprobe"
+	"github.com/open-telemetry/otel-arrow/collector/capability"
+	"github.com/open-telemetry/otel-arrow/collector/netstats"
+	"github.com/open-telemetry/otel-arrow/collector/passthrough"
 	"github.com/open-telemetry/otel-arrow/collector/testdata"
 	"github.com/open-telemetry/otel-arrow/collector/testutil"
 	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
@@ -31,19 +42,28 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/extension/auth"
+	"go.opentelemetry.io/collector/featuregate"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
 	"go.opentelemetry.io/collector/receiver"
 	"go.opentelemetry.io/collector/receiver/receivertest"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.uber.org/mock/gomock"
+	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/hpack"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow"
 	"github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/arrow/mock"
 	componentMetadata "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver/internal/metadata"
 )
@@ -60,7 +80,7 @@ func TestGRPCNewPortAlreadyUsed(t *testing.T) {
 		assert.NoError(t, ln.Close())
 	})
 	tt := componenttest.NewNopTelemetrySettings()
-	r := newGRPCReceiver(t, addr, tt, consumertest.NewNop(), consumertest.NewNop())
+	r := newGRPCReceiver(t, addr, tt, consumertest.NewNop(), consumertest.NewNop(), consumertest.NewNop())
 	require.NotNil(t, r)
 
 	require.Error(t, r.Start(context.Background(), componenttest.NewNopHost()))
@@ -103,7 +123,7 @@ func TestOTelArrowReceiverGRPCTracesIngestTest(t *testing.T) {
 
 	sink := &errOrSinkConsumer{TracesSink: new(consumertest.TracesSink)}
 
-	ocr := newGRPCReceiver(t, addr, tt.TelemetrySettings(), sink, nil)
+	ocr := newGRPCReceiver(t, addr, tt.TelemetrySettings(), sink, nil, nil)
 	require.NotNil(t, ocr)
 	require.NoError(t, ocr.Start(context.Background(), componenttest.NewNopHost()))
 	t.Cleanup(func() { require.NoError(t, ocr.Shutdown(context.Background())) })
@@ -133,6 +153,41 @@ func TestOTelArrowReceiverGRPCTracesIngestTest(t *testing.T) {
 	require.NoError(t, tt.CheckReceiverTraces("grpc", int64(expectedReceivedBatches), int64(expectedIngestionBlockedRPCs)))
 }
 
+// TestOTelArrowReceiverGRPCLogsIngestTest is the logs version of
+// TestOTelArrowReceiverGRPCTracesIngestTest.
+func TestOTelArrowReceiverGRPCLogsIngestTest(t *testing.T) {
+	addr := testutil.GetAvailableLocalAddress(t)
+	ld := testdata.GenerateLogs(2)
+
+	tt, err := componenttest.SetupTelemetry(testReceiverID)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+
+	sink := &errOrSinkConsumer{LogsSink: new(consumertest.LogsSink)}
+
+	ocr := newGRPCReceiver(t, addr, tt.TelemetrySettings(), nil, nil, sink)
+	require.NotNil(t, ocr)
+	require.NoError(t, ocr.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, ocr.Shutdown(context.Background())) })
+
+	cc, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cc.Close())
+	}()
+
+	client := plogotlp.NewGRPCClient(cc)
+	_, err = client.Export(context.Background(), plogotlp.NewExportRequestFromLogs(ld))
+	require.NoError(t, err)
+
+	sink.SetConsumeError(errors.New("consumer error"))
+	_, err = client.Export(context.Background(), plogotlp.NewExportRequestFromLogs(ld))
+	assert.Equal(t, codes.Unknown, status.Code(err))
+
+	require.Equal(t, 1, len(sink.AllLogs()))
+	require.NoError(t, tt.CheckReceiverLogs("grpc", 2, 2))
+}
+
 func TestGRPCInvalidTLSCredentials(t *testing.T) {
 	cfg := &Config{
 		Protocols: Protocols{
@@ -172,7 +227,7 @@ func TestGRPCMaxRecvSize(t *testing.T) {
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.GRPC.NetAddr.Endpoint = addr
 	tt := componenttest.NewNopTelemetrySettings()
-	ocr := newReceiver(t, factory, tt, cfg, testReceiverID, sink, nil)
+	ocr := newReceiver(t, factory, tt, cfg, testReceiverID, sink, nil, nil)
 
 	require.NotNil(t, ocr)
 	require.NoError(t, ocr.Start(context.Background(), componenttest.NewNopHost()))
@@ -187,7 +242,7 @@ func TestGRPCMaxRecvSize(t *testing.T) {
 
 	cfg.GRPC.MaxRecvMsgSizeMiB = 100
 
-	ocr = newReceiver(t, factory, tt, cfg, testReceiverID, sink, nil)
+	ocr = newReceiver(t, factory, tt, cfg, testReceiverID, sink, nil, nil)
 
 	require.NotNil(t, ocr)
 	require.NoError(t, ocr.Start(context.Background(), componenttest.NewNopHost()))
@@ -205,14 +260,14 @@ func TestGRPCMaxRecvSize(t *testing.T) {
 	assert.Equal(t, td, sink.AllTraces()[0])
 }
 
-func newGRPCReceiver(t *testing.T, endpoint string, settings component.TelemetrySettings, tc consumer.Traces, mc consumer.Metrics) component.Component {
+func newGRPCReceiver(t *testing.T, endpoint string, settings component.TelemetrySettings, tc consumer.Traces, mc consumer.Metrics, lc consumer.Logs) component.Component {
 	factory := NewFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.GRPC.NetAddr.Endpoint = endpoint
-	return newReceiver(t, factory, settings, cfg, testReceiverID, tc, mc)
+	return newReceiver(t, factory, settings, cfg, testReceiverID, tc, mc, lc)
 }
 
-func newReceiver(t *testing.T, factory receiver.Factory, settings component.TelemetrySettings, cfg *Config, id component.ID, tc consumer.Traces, mc consumer.Metrics) component.Component {
+func newReceiver(t *testing.T, factory receiver.Factory, settings component.TelemetrySettings, cfg *Config, id component.ID, tc consumer.Traces, mc consumer.Metrics, lc consumer.Logs) component.Component {
 	set := receivertest.NewNopCreateSettings()
 	set.TelemetrySettings = settings
 	set.TelemetrySettings.MetricsLevel = configtelemetry.LevelNormal
@@ -227,6 +282,10 @@ func newReceiver(t *testing.T, factory receiver.Factory, settings component.Tele
 		r, err = factory.CreateMetricsReceiver(context.Background(), set, cfg, mc)
 		require.NoError(t, err)
 	}
+	if lc != nil {
+		r, err = factory.CreateLogsReceiver(context.Background(), set, cfg, lc)
+		require.NoError(t, err)
+	}
 	return r
 }
 
@@ -326,6 +385,7 @@ func exportTraces(cc *grpc.ClientConn, td ptrace.Traces) error {
 type errOrSinkConsumer struct {
 	*consumertest.TracesSink
 	*consumertest.MetricsSink
+	*consumertest.LogsSink
 	mu           sync.Mutex
 	consumeError error // to be returned by ConsumeTraces, if set
 }
@@ -365,6 +425,18 @@ func (esc *errOrSinkConsumer) ConsumeMetrics(ctx context.Context, md pmetric.Met
 	return esc.MetricsSink.ConsumeMetrics(ctx, md)
 }
 
+// ConsumeLogs stores logs to this sink.
+func (esc *errOrSinkConsumer) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	esc.mu.Lock()
+	defer esc.mu.Unlock()
+
+	if esc.consumeError != nil {
+		return esc.consumeError
+	}
+
+	return esc.LogsSink.ConsumeLogs(ctx, ld)
+}
+
 // Reset deletes any stored in the sinks, resets error to nil.
 func (esc *errOrSinkConsumer) Reset() {
 	esc.mu.Lock()
@@ -416,7 +488,7 @@ func TestGRPCArrowReceiver(t *testing.T) {
 	cfg.GRPC.IncludeMetadata = true
 	id := component.NewID(component.MustNewType("arrow"))
 	tt := componenttest.NewNopTelemetrySettings()
-	ocr := newReceiver(t, factory, tt, cfg, id, sink, nil)
+	ocr := newReceiver(t, factory, tt, cfg, id, sink, nil, nil)
 
 	require.NotNil(t, ocr)
 	require.NoError(t, ocr.Start(context.Background(), componenttest.NewNopHost()))
@@ -491,6 +563,264 @@ func TestGRPCArrowReceiver(t *testing.T) {
 	}
 }
 
+func TestGRPCArrowReceiverLogs(t *testing.T) {
+	addr := testutil.GetAvailableLocalAddress(t)
+	sink := new(consumertest.LogsSink)
+
+	tt, err := componenttest.SetupTelemetry(testReceiverID)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.GRPC.NetAddr.Endpoint = addr
+	ocr := newReceiver(t, factory, tt.TelemetrySettings(), cfg, testReceiverID, nil, nil, sink)
+
+	require.NotNil(t, ocr)
+	require.NoError(t, ocr.Start(context.Background(), componenttest.NewNopHost()))
+
+	cc, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := arrowpb.NewArrowLogsServiceClient(cc).ArrowLogs(ctx, grpc.WaitForReady(true))
+	require.NoError(t, err)
+	producer := arrowRecord.NewProducer()
+	defer func() { require.NoError(t, producer.Close()) }()
+
+	var expectLogs []plog.Logs
+	for i := 0; i < 3; i++ {
+		ld := testdata.GenerateLogs(2)
+		expectLogs = append(expectLogs, ld)
+
+		batch, err := producer.BatchArrowRecordsFromLogs(ld)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(batch))
+
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, batch.BatchId, resp.BatchId)
+		require.Equal(t, arrowpb.StatusCode_OK, resp.StatusCode)
+	}
+
+	// The traces service is not registered for a logs pipeline.
+	tstream, err := arrowpb.NewArrowTracesServiceClient(cc).ArrowTraces(ctx)
+	require.NoError(t, err)
+	_, err = tstream.Recv()
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	assert.NoError(t, cc.Close())
+	require.NoError(t, ocr.Shutdown(context.Background()))
+
+	assert.Equal(t, expectLogs, sink.AllLogs())
+	require.NoError(t, tt.CheckReceiverLogs("grpc", 6, 0))
+}
+
+// TestGRPCArrowReceiverLogsPassthrough forwards logs received with
+// pass-through to a second receiver, through a passthrough.Forwarder.
+func TestGRPCArrowReceiverLogsPassthrough(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	otlpAddr := testutil.GetAvailableLocalAddress(t)
+	otlp := newGRPCReceiver(t, otlpAddr, componenttest.NewNopTelemetrySettings(), nil, nil, sink)
+	require.NoError(t, otlp.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, otlp.Shutdown(context.Background())) }()
+
+	fc, err := grpc.Dial(otlpAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, fc.Close()) }()
+
+	addr := testutil.GetAvailableLocalAddress(t)
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.GRPC.NetAddr.Endpoint = addr
+	cfg.Arrow.Passthrough = true
+	ocr := newReceiver(t, factory, componenttest.NewNopTelemetrySettings(), cfg, testReceiverID, nil, nil, passthrough.NewForwarder(fc))
+	require.NoError(t, ocr.Start(context.Background(), componenttest.NewNopHost()))
+
+	cc, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := arrowpb.NewArrowLogsServiceClient(cc).ArrowLogs(ctx, grpc.WaitForReady(true))
+	require.NoError(t, err)
+	producer := arrowRecord.NewProducer()
+	defer func() { require.NoError(t, producer.Close()) }()
+
+	var expectLogs []plog.Logs
+	for i := 0; i < 3; i++ {
+		ld := testdata.GenerateLogs(2)
+		expectLogs = append(expectLogs, ld)
+
+		batch, err := producer.BatchArrowRecordsFromLogs(ld)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(batch))
+
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, batch.BatchId, resp.BatchId)
+		require.Equal(t, arrowpb.StatusCode_OK, resp.StatusCode)
+	}
+
+	assert.NoError(t, cc.Close())
+	require.NoError(t, ocr.Shutdown(context.Background()))
+
+	assert.Equal(t, expectLogs, sink.AllLogs())
+}
+
+func TestHTTPArrowReceiver(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.GRPC.NetAddr.Endpoint = testutil.GetAvailableLocalAddress(t)
+	cfg.HTTP = defaultHTTPConfig()
+	cfg.HTTP.Endpoint = testutil.GetAvailableLocalAddress(t)
+	ocr := newReceiver(t, factory, componenttest.NewNopTelemetrySettings(), cfg, testReceiverID, nil, nil, sink)
+	require.NoError(t, ocr.Start(context.Background(), componenttest.NewNopHost()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Streams use HTTP/2 without TLS.
+	h2c := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+	body, bodyWriter := io.Pipe()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+cfg.HTTP.Endpoint+defaultLogsURLPath, body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", arrow.HTTPContentType)
+	resp, err := h2c.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, resp.Header.Get(capability.Header))
+
+	producer := arrowRecord.NewProducer()
+	defer func() { require.NoError(t, producer.Close()) }()
+
+	var expectLogs []plog.Logs
+	for i := 0; i < 3; i++ {
+		ld := testdata.GenerateLogs(2)
+		expectLogs = append(expectLogs, ld)
+
+		batch, err := producer.BatchArrowRecordsFromLogs(ld)
+		require.NoError(t, err)
+		data, err := proto.Marshal(batch)
+		require.NoError(t, err)
+		frame := binary.BigEndian.AppendUint32([]byte{0}, uint32(len(data)))
+		_, err = bodyWriter.Write(append(frame, data...))
+		require.NoError(t, err)
+
+		var hdr [5]byte
+		_, err = io.ReadFull(resp.Body, hdr[:])
+		require.NoError(t, err)
+		data = make([]byte, binary.BigEndian.Uint32(hdr[1:]))
+		_, err = io.ReadFull(resp.Body, data)
+		require.NoError(t, err)
+		var bs arrowpb.BatchStatus
+		require.NoError(t, proto.Unmarshal(data, &bs))
+		require.Equal(t, batch.BatchId, bs.BatchId)
+		require.Equal(t, arrowpb.StatusCode_OK, bs.StatusCode)
+	}
+
+	// Closing the request ends the stream, whose status is in the
+	// trailers.
+	require.NoError(t, bodyWriter.Close())
+	rest, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Empty(t, rest)
+	require.Equal(t, strconv.Itoa(int(codes.Canceled)), resp.Trailer.Get(arrow.HTTPStatusTrailer))
+	require.Equal(t, "client stream shutdown", resp.Trailer.Get(arrow.HTTPMessageTrailer))
+
+	// The traces path is not served for a logs pipeline, and
+	// streams need HTTP/2 and the Arrow content type.
+	for _, tc := range []struct {
+		client      *http.Client
+		path        string
+		contentType string
+		status      int
+	}{
+		{h2c, defaultTracesURLPath, arrow.HTTPContentType, http.StatusNotFound},
+		{h2c, defaultLogsURLPath, "application/x-protobuf", http.StatusUnsupportedMediaType},
+		{http.DefaultClient, defaultLogsURLPath, arrow.HTTPContentType, http.StatusHTTPVersionNotSupported},
+	} {
+		resp, err := tc.client.Post("http://"+cfg.HTTP.Endpoint+tc.path, tc.contentType, bytes.NewReader(nil))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, tc.status, resp.StatusCode, tc.path)
+	}
+
+	h2c.CloseIdleConnections()
+	require.NoError(t, ocr.Shutdown(context.Background()))
+	assert.Equal(t, expectLogs, sink.AllLogs())
+}
+
+func TestHTTPOTLPReceiver(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.GRPC.NetAddr.Endpoint = testutil.GetAvailableLocalAddress(t)
+	cfg.HTTP = defaultHTTPConfig()
+	cfg.HTTP.Endpoint = testutil.GetAvailableLocalAddress(t)
+	ocr := newReceiver(t, factory, componenttest.NewNopTelemetrySettings(), cfg, testReceiverID, nil, nil, sink)
+	require.NoError(t, ocr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, ocr.Shutdown(context.Background())) }()
+
+	url := "http://" + cfg.HTTP.Endpoint + defaultOTLPLogsURLPath
+	ld := testdata.GenerateLogs(2)
+	jsonBody, err := plogotlp.NewExportRequestFromLogs(ld).MarshalJSON()
+	require.NoError(t, err)
+	protoBody, err := plogotlp.NewExportRequestFromLogs(ld).MarshalProto()
+	require.NoError(t, err)
+
+	// Both encodings are accepted and answered in kind.
+	for _, tc := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"application/json", jsonBody},
+		{"application/x-protobuf", protoBody},
+	} {
+		resp, err := http.Post(url, tc.contentType, bytes.NewReader(tc.body))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, tc.contentType, resp.Header.Get("Content-Type"))
+	}
+	assert.Equal(t, []plog.Logs{ld, ld}, sink.AllLogs())
+
+	// Invalid requests return their status in the request's
+	// encoding.
+	resp, err := http.Post(url, "application/json", bytes.NewReader([]byte("{")))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	var st struct{ Code int }
+	require.NoError(t, json.Unmarshal(body, &st))
+	require.Equal(t, int(codes.InvalidArgument), st.Code)
+
+	resp, err = http.Post(url, "text/plain", bytes.NewReader(jsonBody))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+	// Only the signals with a consumer are served.
+	resp, err = http.Post("http://"+cfg.HTTP.Endpoint+defaultOTLPTracesURLPath, "application/json", bytes.NewReader(nil))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
 type hostWithExtensions struct {
 	component.Host
 	exts map[component.ID]component.Component
@@ -529,7 +859,7 @@ func TestGRPCArrowReceiverAuth(t *testing.T) {
 	}
 	id := component.NewID(component.MustNewType("arrow"))
 	tt := componenttest.NewNopTelemetrySettings()
-	ocr := newReceiver(t, factory, tt, cfg, id, sink, nil)
+	ocr := newReceiver(t, factory, tt, cfg, id, sink, nil, nil)
 
 	require.NotNil(t, ocr)
 
@@ -586,6 +916,118 @@ func TestGRPCArrowReceiverAuth(t *testing.T) {
 	assert.Equal(t, 0, len(sink.AllTraces()))
 }
 
+func TestArrowProbe(t *testing.T) {
+	addr := testutil.GetAvailableLocalAddress(t)
+	sink := new(tracesSinkWithMetadata)
+
+	authID := component.NewID(component.MustNewType("testauth"))
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.GRPC.NetAddr.Endpoint = addr
+	cfg.GRPC.Auth = &configauth.Authentication{
+		AuthenticatorID: authID,
+	}
+	id := component.NewID(component.MustNewType("arrow"))
+	tt := componenttest.NewNopTelemetrySettings()
+	ocr := newReceiver(t, factory, tt, cfg, id, sink, nil, nil)
+
+	type inStreamCtx struct{}
+
+	host := newHostWithExtensions(
+		map[component.ID]component.Component{
+			authID: newTestAuthExtension(t, func(ctx context.Context, hdrs map[string][]string) (context.Context, error) {
+				if ctx.Value(inStreamCtx{}) == nil {
+					// The stream itself is not authenticated.
+					return context.WithValue(ctx, inStreamCtx{}, t), nil
+				}
+				if len(hdrs["authorization"]) == 0 || hdrs["authorization"][0] != "secret" {
+					return ctx, fmt.Errorf("not authorized")
+				}
+				return ctx, nil
+			}),
+		},
+	)
+
+	require.NoError(t, ocr.Start(context.Background(), host))
+	defer func() { require.NoError(t, ocr.Shutdown(context.Background())) }()
+
+	cc, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	require.NoError(t, err)
+	defer cc.Close()
+
+	ctx := context.Background()
+	require.NoError(t, arrowprobe.Probe(ctx, cc, component.DataTypeTraces, metadata.Pairs("authorization", "secret")))
+
+	err = arrowprobe.Probe(ctx, cc, component.DataTypeTraces, nil)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Only the traces service is registered.
+	err = arrowprobe.Probe(ctx, cc, component.DataTypeMetrics, nil)
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// Probes consume nothing.
+	assert.Equal(t, 0, len(sink.AllTraces()))
+}
+
+// methodStatsHandler is a stats.Handler that records the methods of
+// the RPCs it handles.
+type methodStatsHandler struct {
+	lock    sync.Mutex
+	methods []string
+}
+
+func (h *methodStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.methods = append(h.methods, info.FullMethodName)
+	return ctx
+}
+
+func (h *methodStatsHandler) HandleRPC(context.Context, stats.RPCStats) {}
+
+func (h *methodStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *methodStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+func (h *methodStatsHandler) getMethods() []string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]string(nil), h.methods...)
+}
+
+func TestStatsHandler(t *testing.T) {
+	addr := testutil.GetAvailableLocalAddress(t)
+	sink := new(tracesSinkWithMetadata)
+
+	handler := &methodStatsHandler{}
+	factory := NewFactoryWithOptions(WithStatsHandler(handler))
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.GRPC.NetAddr.Endpoint = addr
+	id := component.NewID(component.MustNewType("arrow"))
+	tt := componenttest.NewNopTelemetrySettings()
+	ocr := newReceiver(t, factory, tt, cfg, id, sink, nil, nil)
+
+	require.NoError(t, ocr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, ocr.Shutdown(context.Background())) }()
+
+	cc, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	require.NoError(t, err)
+	defer cc.Close()
+
+	ctx := context.Background()
+	_, err = ptraceotlp.NewGRPCClient(cc).Export(ctx, ptraceotlp.NewExportRequestFromTraces(testdata.GenerateTraces(1)))
+	require.NoError(t, err)
+	require.NoError(t, arrowprobe.Probe(ctx, cc, component.DataTypeTraces, nil))
+
+	assert.Equal(t, []string{
+		"/opentelemetry.proto.collector.trace.v1.TraceService/Export",
+		"/opentelemetry.proto.experimental.arrow.v1.ArrowTracesService/ArrowTraces",
+	}, handler.getMethods())
+}
+
 func TestConcurrentArrowReceiver(t *testing.T) {
 	addr := testutil.GetAvailableLocalAddress(t)
 	sink := new(tracesSinkWithMetadata)
@@ -596,7 +1038,7 @@ func TestConcurrentArrowReceiver(t *testing.T) {
 	cfg.GRPC.IncludeMetadata = true
 	id := component.NewID(component.MustNewType("arrow"))
 	tt := componenttest.NewNopTelemetrySettings()
-	ocr := newReceiver(t, factory, tt, cfg, id, sink, nil)
+	ocr := newReceiver(t, factory, tt, cfg, id, sink, nil, nil)
 
 	require.NotNil(t, ocr)
 	require.NoError(t, ocr.Start(context.Background(), componenttest.NewNopHost()))
@@ -674,3 +1116,85 @@ func TestConcurrentArrowReceiver(t *testing.T) {
 		require.Equal(t, numStreams, counts[i])
 	}
 }
+
+func TestEffectiveConfig(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	r := &otelArrowReceiver{cfg: cfg}
+	require.Equal(t, arrowRecord.DefaultMaxSchemas, r.effectiveConfig().Arrow.MaxSchemas)
+	require.False(t, r.effectiveConfig().Arrow.Passthrough)
+
+	// The gate is reported as configured passthrough.
+	require.NoError(t, featuregate.GlobalRegistry().Set(passthroughGate.ID(), true))
+	defer func() {
+		require.NoError(t, featuregate.GlobalRegistry().Set(passthroughGate.ID(), false))
+	}()
+	cfg.Arrow.MaxSchemas = 64
+	require.Equal(t, 64, r.effectiveConfig().Arrow.MaxSchemas)
+	require.True(t, r.effectiveConfig().Arrow.Passthrough)
+}
+
+func TestReceiverIDIncludesInstance(t *testing.T) {
+	set := receivertest.NewNopCreateSettings()
+	set.TelemetrySettings.Resource.Attributes().PutStr("service.instance.id", "0f1e2d")
+	r := &otelArrowReceiver{settings: set}
+
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	require.Equal(t, host+"/0f1e2d", r.receiverID())
+}
+
+func TestAuditFile(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.GRPC.NetAddr.Endpoint = testutil.GetAvailableLocalAddress(t)
+	cfg.Arrow.Audit.Path = filepath.Join(t.TempDir(), "audit.jsonl")
+	set := receivertest.NewNopCreateSettings()
+	set.ID = testReceiverID
+	r, err := NewFactory().CreateTracesReceiver(context.Background(), set, cfg, new(consumertest.TracesSink))
+	require.NoError(t, err)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	info, err := os.Stat(cfg.Arrow.Audit.Path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	cfg.Arrow.Audit.Path = filepath.Join(t.TempDir(), "missing", "audit.jsonl")
+	r, err = NewFactory().CreateTracesReceiver(context.Background(), set, cfg, new(consumertest.TracesSink))
+	require.NoError(t, err)
+	require.ErrorContains(t, r.Start(context.Background(), componenttest.NewNopHost()), "audit: ")
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestReceiverSizeHistograms(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		reader := sdkmetric.NewManualReader()
+		set := receivertest.NewNopCreateSettings()
+		set.TelemetrySettings.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+		set.TelemetrySettings.MetricsLevel = configtelemetry.LevelNormal
+
+		cfg := NewFactory().CreateDefaultConfig().(*Config)
+		cfg.SizeHistograms = enabled
+		r, err := newOTelArrowReceiver(cfg, set, factoryOptions{})
+		require.NoError(t, err)
+		r.netReporter.CountReceive(context.Background(), netstats.SizesStruct{
+			Method: "Hello",
+			Length: 100,
+		})
+
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+		var histograms []string
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if _, ok := m.Data.(metricdata.Histogram[int64]); ok {
+					histograms = append(histograms, m.Name)
+				}
+			}
+		}
+		if enabled {
+			require.Equal(t, []string{"receiver_recv_size"}, histograms)
+		} else {
+			require.Empty(t, histograms)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowreceiver // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver"
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// The HTTP server of the Arrow streams also accepts OTLP/HTTP
+// requests, whose bodies are OTLP protobuf or OTLP/JSON, e.g.,
+// captured samples and debugging payloads, which are consumed like
+// the requests of the gRPC OTLP services.
+
+const (
+	otlpProtoContentType = "application/x-protobuf"
+	otlpJSONContentType  = "application/json"
+)
+
+// otlpMessage is implemented by the OTLP export requests and
+// responses of pdata.
+type otlpMessage interface {
+	MarshalProto() ([]byte, error)
+	UnmarshalProto(data []byte) error
+	MarshalJSON() ([]byte, error)
+	UnmarshalJSON(data []byte) error
+}
+
+// otlpHandler serves the OTLP/HTTP requests of one signal, which are
+// decoded into the request returned by newReq and passed to export.
+func otlpHandler[Req, Resp otlpMessage](newReq func() Req, export func(context.Context, Req) (Resp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, fmt.Sprintf("%v method not allowed, supported: [POST]", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if contentType != otlpProtoContentType && contentType != otlpJSONContentType {
+			http.Error(w, fmt.Sprintf("unsupported content type, supported: [%s, %s]", otlpJSONContentType, otlpProtoContentType), http.StatusUnsupportedMediaType)
+			return
+		}
+		isJSON := contentType == otlpJSONContentType
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			writeOTLPError(w, isJSON, status.Errorf(codes.InvalidArgument, "reading request: %v", err), http.StatusBadRequest)
+			return
+		}
+		otlpReq := newReq()
+		if isJSON {
+			err = otlpReq.UnmarshalJSON(body)
+		} else {
+			err = otlpReq.UnmarshalProto(body)
+		}
+		if err != nil {
+			writeOTLPError(w, isJSON, status.Errorf(codes.InvalidArgument, "decoding request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		resp, err := export(req.Context(), otlpReq)
+		if err != nil {
+			code := http.StatusServiceUnavailable
+			if consumererror.IsPermanent(err) {
+				code = http.StatusBadRequest
+			}
+			writeOTLPError(w, isJSON, err, code)
+			return
+		}
+		var data []byte
+		if isJSON {
+			data, err = resp.MarshalJSON()
+		} else {
+			data, err = resp.MarshalProto()
+		}
+		if err != nil {
+			writeOTLPError(w, isJSON, err, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data)
+	}
+}
+
+// writeOTLPError responds with the status of err, in the encoding of
+// the request, as OTLP/HTTP specifies.
+func writeOTLPError(w http.ResponseWriter, isJSON bool, err error, code int) {
+	st := status.Convert(err).Proto()
+	contentType := otlpProtoContentType
+	marshal := proto.Marshal
+	if isJSON {
+		contentType = otlpJSONContentType
+		marshal = protojson.Marshal
+	}
+	data, merr := marshal(st)
+	if merr != nil {
+		http.Error(w, st.GetMessage(), code)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowreceiver // import "github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver"
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// resourceAttributes sets the configured client metadata and auth
+// attributes of the request as resource attributes.
+type resourceAttributes []ResourceAttributeConfig
+
+// values returns the attributes to set for the client of ctx.
+// Multiple values of a key are joined with commas.
+func (ras resourceAttributes) values(ctx context.Context) map[string]string {
+	info := client.FromContext(ctx)
+	vals := map[string]string{}
+	for _, ra := range ras {
+		if v := clientValues(info, ra.MetadataKey, ra.AuthAttribute); len(v) != 0 {
+			vals[ra.attribute()] = strings.Join(v, ",")
+		}
+	}
+	return vals
+}
+
+// clientValues returns the values of the metadata key, if set,
+// otherwise of the auth attribute, which must be a string or a
+// []string.
+func clientValues(info client.Info, metadataKey, authAttribute string) []string {
+	if metadataKey != "" {
+		return info.Metadata.Get(metadataKey)
+	}
+	if info.Auth == nil {
+		return nil
+	}
+	switch a := info.Auth.GetAttribute(authAttribute).(type) {
+	case string:
+		return []string{a}
+	case []string:
+		return a
+	}
+	return nil
+}
+
+// set overwrites the attributes of each resource, so that clients
+// cannot impersonate another tenant by setting the attribute.
+func set(vals map[string]string, res pcommon.Resource) {
+	for k, v := range vals {
+		res.Attributes().PutStr(k, v)
+	}
+}
+
+// traces, metrics, and logs wrap next, which disables the passthrough
+// mode of the Arrow receiver because passthrough requests are never
+// decoded.
+func (ras resourceAttributes) traces(next consumer.Traces) consumer.Traces {
+	if len(ras) == 0 {
+		return next
+	}
+	tc, _ := consumer.NewTraces(func(ctx context.Context, td ptrace.Traces) error {
+		vals := ras.values(ctx)
+		for i := 0; i < td.ResourceSpans().Len(); i++ {
+			set(vals, td.ResourceSpans().At(i).Resource())
+		}
+		return next.ConsumeTraces(ctx, td)
+	}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+	return tc
+}
+
+func (ras resourceAttributes) metrics(next consumer.Metrics) consumer.Metrics {
+	if len(ras) == 0 {
+		return next
+	}
+	mc, _ := consumer.NewMetrics(func(ctx context.Context, md pmetric.Metrics) error {
+		vals := ras.values(ctx)
+		for i := 0; i < md.ResourceMetrics().Len(); i++ {
+			set(vals, md.ResourceMetrics().At(i).Resource())
+		}
+		return next.ConsumeMetrics(ctx, md)
+	}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+	return mc
+}
+
+func (ras resourceAttributes) logs(next consumer.Logs) consumer.Logs {
+	if len(ras) == 0 {
+		return next
+	}
+	lc, _ := consumer.NewLogs(func(ctx context.Context, ld plog.Logs) error {
+		vals := ras.values(ctx)
+		for i := 0; i < ld.ResourceLogs().Len(); i++ {
+			set(vals, ld.ResourceLogs().At(i).Resource())
+		}
+		return next.ConsumeLogs(ctx, ld)
+	}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+	return lc
+}
@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowreceiver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+type testAuthData map[string]any
+
+func (a testAuthData) GetAttribute(name string) any {
+	return a[name]
+}
+
+func (a testAuthData) GetAttributeNames() []string {
+	var names []string
+	for n := range a {
+		names = append(names, n)
+	}
+	return names
+}
+
+func testResourceAttributesContext() context.Context {
+	return client.NewContext(context.Background(), client.Info{
+		Metadata: client.NewMetadata(map[string][]string{
+			"x-region": {"us-east", "us-west"},
+		}),
+		Auth: testAuthData{"tenant": "acme", "groups": 1},
+	})
+}
+
+func requireResourceAttributes(t *testing.T, res pcommon.Resource) {
+	tenant, ok := res.Attributes().Get("tenant.id")
+	require.True(t, ok)
+	require.Equal(t, "acme", tenant.Str())
+	region, ok := res.Attributes().Get("x-region")
+	require.True(t, ok)
+	require.Equal(t, "us-east,us-west", region.Str())
+	_, ok = res.Attributes().Get("groups")
+	require.False(t, ok)
+}
+
+func TestResourceAttributes(t *testing.T) {
+	ras := resourceAttributes{
+		{AuthAttribute: "tenant", Attribute: "tenant.id"},
+		{MetadataKey: "X-Region"},
+		{AuthAttribute: "groups"},
+		{MetadataKey: "x-missing"},
+	}
+	ctx := testResourceAttributesContext()
+
+	traces := new(consumertest.TracesSink)
+	td := testdata.GenerateTraces(2)
+	// The client cannot set the attribute itself.
+	td.ResourceSpans().At(0).Resource().Attributes().PutStr("tenant.id", "spoofed")
+	require.True(t, ras.traces(traces).Capabilities().MutatesData)
+	require.NoError(t, ras.traces(traces).ConsumeTraces(ctx, td))
+	requireResourceAttributes(t, traces.AllTraces()[0].ResourceSpans().At(0).Resource())
+
+	metrics := new(consumertest.MetricsSink)
+	require.NoError(t, ras.metrics(metrics).ConsumeMetrics(ctx, testdata.GenerateMetrics(2)))
+	requireResourceAttributes(t, metrics.AllMetrics()[0].ResourceMetrics().At(0).Resource())
+
+	logs := new(consumertest.LogsSink)
+	require.NoError(t, ras.logs(logs).ConsumeLogs(ctx, testdata.GenerateLogs(2)))
+	requireResourceAttributes(t, logs.AllLogs()[0].ResourceLogs().At(0).Resource())
+}
+
+func TestResourceAttributesNone(t *testing.T) {
+	traces := new(consumertest.TracesSink)
+	var ras resourceAttributes
+	require.Same(t, traces, ras.traces(traces))
+}
+
+func TestClientIdentity(t *testing.T) {
+	ctx := testResourceAttributesContext()
+	require.Equal(t, "acme", clientIdentity("", "tenant")(ctx))
+	require.Equal(t, "us-east,us-west", clientIdentity("X-Region", "")(ctx))
+	require.Equal(t, "", clientIdentity("", "groups")(ctx))
+	require.Equal(t, "", clientIdentity("", "tenant")(context.Background()))
+}
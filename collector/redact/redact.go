@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redact removes user data from the status messages and logs
+// of OTel-Arrow streams.  Errors of a pipeline may echo client
+// metadata or attribute values, which would otherwise reach logging
+// systems with different retention and access control than the
+// telemetry itself.
+//
+// A redacted message is replaced by a short digest of the message, so
+// that repeated errors are still recognized, in the exporter's and the
+// receiver's logs alike, without revealing their content.
+package redact // import "github.com/open-telemetry/otel-arrow/collector/redact"
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	prefix = "[redacted "
+	suffix = "]"
+	// digestLen is the number of bytes of the digest kept.
+	digestLen = 6
+)
+
+// MessageKey is the name of the log fields that Logger redacts in
+// addition to errors.
+const MessageKey = "message"
+
+// Message returns the redacted form of msg.  Empty and already
+// redacted messages are returned unchanged.
+func Message(msg string) string {
+	if msg == "" || IsRedacted(msg) {
+		return msg
+	}
+	sum := sha256.Sum256([]byte(msg))
+	return prefix + hex.EncodeToString(sum[:digestLen]) + suffix
+}
+
+// IsRedacted returns whether msg is a redacted message.
+func IsRedacted(msg string) bool {
+	return len(msg) == len(prefix)+2*digestLen+len(suffix) &&
+		strings.HasPrefix(msg, prefix) && strings.HasSuffix(msg, suffix)
+}
+
+// Error returns err with a redacted message.  The gRPC status code
+// of err, if any, is kept.
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	if st, ok := status.FromError(err); ok {
+		return status.Error(st.Code(), Message(st.Message()))
+	}
+	return errors.New(Message(err.Error()))
+}
+
+// Logger returns a logger that redacts the errors and the MessageKey
+// fields of its entries.  Entry messages are not redacted: they are
+// constants in this repository.
+func Logger(logger *zap.Logger) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return &core{Core: c}
+	}))
+}
+
+type core struct {
+	zapcore.Core
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+// redactFields returns the fields with errors and messages redacted.
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		var r zapcore.Field
+		switch {
+		case f.Type == zapcore.ErrorType:
+			err, _ := f.Interface.(error)
+			if err == nil {
+				continue
+			}
+			r = zap.String(f.Key, Message(err.Error()))
+		case f.Type == zapcore.StringType && f.Key == MessageKey:
+			r = zap.String(f.Key, Message(f.String))
+		default:
+			continue
+		}
+		if out == nil {
+			out = append([]zapcore.Field(nil), fields...)
+		}
+		out[i] = r
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redact
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMessage(t *testing.T) {
+	const msg = "tenant x-secret-token=abc123 rejected"
+
+	red := Message(msg)
+	require.True(t, IsRedacted(red))
+	require.NotContains(t, red, "abc123")
+	require.Equal(t, red, Message(msg), "digests are stable")
+	require.NotEqual(t, red, Message("another message"))
+	require.Equal(t, red, Message(red), "redacting twice changes nothing")
+	require.Equal(t, "", Message(""))
+	require.False(t, IsRedacted(msg))
+}
+
+func TestError(t *testing.T) {
+	require.NoError(t, Error(nil))
+
+	err := Error(status.Error(codes.InvalidArgument, "bad attribute user.email=a@b.c"))
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+	require.True(t, IsRedacted(st.Message()))
+
+	err = Error(fmt.Errorf("bad attribute user.email=a@b.c"))
+	require.True(t, IsRedacted(err.Error()))
+}
+
+func TestLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := Logger(zap.New(core)).With(zap.Error(fmt.Errorf("with secret")))
+
+	logger.Info("arrow stream error",
+		zap.String(MessageKey, "secret message"),
+		zap.Error(fmt.Errorf("secret error")),
+		zap.String("which", "reader"),
+	)
+	logger.Debug("no fields")
+
+	all := logs.All()
+	require.Len(t, all, 2)
+	require.Equal(t, "arrow stream error", all[0].Message)
+	fields := all[0].ContextMap()
+	require.Equal(t, Message("secret message"), fields[MessageKey])
+	require.Equal(t, Message("secret error"), fields["error"])
+	require.Equal(t, "reader", fields["which"])
+	require.Equal(t, Message("with secret"), all[1].ContextMap()["error"])
+}
+
+func TestLoggerLevel(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := Logger(zap.New(core))
+
+	logger.Debug("dropped", zap.Error(fmt.Errorf("secret")))
+	require.Equal(t, 0, logs.Len())
+}
@@ -0,0 +1,193 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package streamevents logs the lifecycle of OTel-Arrow streams with
+// the same message and field names in the exporter and the receiver,
+// so that log-based alerts and dashboards select events by field
+// instead of parsing messages.  Every event carries the EventKey
+// field, whose values are listed below.  Events are logged to a
+// stream's logger, which carries the stream's identity, see With.
+//
+// Each stream has a short unique identifier.  The exporter sends its
+// identifier to the receiver in the StreamIDHeader of the stream, so
+// that the receiver's logs and spans of the stream carry both.
+package streamevents // import "github.com/open-telemetry/otel-arrow/collector/streamevents"
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+)
+
+// Field names.  These are stable: changing them breaks the queries
+// built on them.
+const (
+	// EventKey names the event, one of the Event values.
+	EventKey = "otelarrow.event"
+
+	// StreamIDKey identifies the stream.
+	StreamIDKey = "otelarrow.stream_id"
+
+	// PeerStreamIDKey is the identifier the exporter gave the
+	// stream, in the receiver.
+	PeerStreamIDKey = "otelarrow.peer_stream_id"
+
+	// MethodKey is the gRPC method of the stream.
+	MethodKey = "otelarrow.method"
+
+	// AgeKey is how long the stream was open when it ended.
+	AgeKey = "otelarrow.stream_age"
+
+	// ReasonKey explains a rotation or a downgrade, one of the
+	// Reason values.
+	ReasonKey = "otelarrow.reason"
+
+	// CodeKey is the gRPC status code of a stream error.
+	CodeKey = "otelarrow.code"
+
+	// ReceiverIDKey identifies the collector that responded to a
+	// batch, in the exporter.
+	ReceiverIDKey = "otelarrow.receiver_id"
+
+	// PeerInstanceIDKey identifies the collector of the exporter
+	// of a stream, in the receiver.
+	PeerInstanceIDKey = "otelarrow.peer_instance_id"
+
+	// PeerLabelPrefix prefixes the names of the deployment labels
+	// of the collector of the exporter of a stream, in the
+	// receiver.
+	PeerLabelPrefix = "otelarrow.peer_label."
+)
+
+// StreamIDHeader is the gRPC metadata key carrying the exporter's
+// identifier of a stream.
+const StreamIDHeader = "otel-arrow-stream-id"
+
+// InstanceIDHeader and InstanceLabelsHeader are the gRPC metadata
+// keys carrying the optional identity of the exporter's collector,
+// which is the same for every stream of the collector.  Each value of
+// InstanceLabelsHeader is one label, formatted by FormatLabel.
+const (
+	InstanceIDHeader     = "otel-arrow-instance-id"
+	InstanceLabelsHeader = "otel-arrow-instance-labels"
+)
+
+// FormatLabel formats a deployment label as `name=value`.
+func FormatLabel(name, value string) string {
+	return name + "=" + value
+}
+
+// ParseLabel returns the name and the value of a label formatted by
+// FormatLabel, and false for a malformed label.
+func ParseLabel(label string) (name, value string, ok bool) {
+	name, value, ok = strings.Cut(label, "=")
+	return name, value, ok && name != ""
+}
+
+// Event values.
+const (
+	// StreamStart is logged at debug level when a stream opens.
+	StreamStart = "stream_start"
+
+	// StreamRotate is logged at debug level when a stream ends
+	// without error, e.g., at the end of its lifetime.
+	StreamRotate = "stream_rotate"
+
+	// StreamError is logged at error level when an error ends a
+	// stream, possibly once for each direction of the stream.
+	StreamError = "stream_error"
+
+	// Downgrade is logged at info level when an exporter stops
+	// using Arrow in favor of standard OTLP.
+	Downgrade = "downgrade"
+
+	// Upgrade is logged at info level when a downgraded exporter
+	// tries Arrow again.
+	Upgrade = "upgrade"
+)
+
+// Reason values.
+const (
+	// ReasonLifetime means the stream reached its maximum
+	// lifetime.
+	ReasonLifetime = "max_stream_lifetime"
+
+	// ReasonReconfigured means the stream restarted or stopped
+	// because its settings changed.
+	ReasonReconfigured = "reconfigured"
+
+	// ReasonShutdown means the component is shutting down.
+	ReasonShutdown = "shutdown"
+
+	// ReasonClosed means the peer closed the stream.
+	ReasonClosed = "closed"
+
+	// ReasonBatchIDsExhausted means the stream sent the most
+	// batches that it may, see the exporter's batch IDs.
+	ReasonBatchIDsExhausted = "batch_ids_exhausted"
+
+	// ReasonUnsupported means the receiver does not support
+	// Arrow.
+	ReasonUnsupported = "unsupported"
+)
+
+// NewStreamID returns a new stream identifier, 12 hexadecimal
+// digits.
+func NewStreamID() string {
+	return fmt.Sprintf("%012x", rand.Uint64()>>16) //nolint:gosec // identifiers only need to be distinct
+}
+
+// With returns a logger for the stream identified by streamID, using
+// method.  Either may be empty when not yet known.
+func With(logger *zap.Logger, streamID, method string) *zap.Logger {
+	var fields []zap.Field
+	if streamID != "" {
+		fields = append(fields, zap.String(StreamIDKey, streamID))
+	}
+	if method != "" {
+		fields = append(fields, zap.String(MethodKey, method))
+	}
+	return logger.With(fields...)
+}
+
+// Started logs a StreamStart event to a stream's logger.
+func Started(logger *zap.Logger) {
+	logger.Debug("arrow stream started", zap.String(EventKey, StreamStart))
+}
+
+// Rotated logs a StreamRotate event to the logger of a stream opened
+// at started.
+func Rotated(logger *zap.Logger, started time.Time, reason string) {
+	logger.Debug("arrow stream rotated",
+		zap.String(EventKey, StreamRotate),
+		zap.Duration(AgeKey, time.Since(started)),
+		zap.String(ReasonKey, reason),
+	)
+}
+
+// Failed logs a StreamError event to a stream's logger, with the
+// message of the error and any other fields.
+func Failed(logger *zap.Logger, code codes.Code, msg string, fields ...zap.Field) {
+	logger.Error("arrow stream error", append([]zap.Field{
+		zap.String(EventKey, StreamError),
+		zap.Int(CodeKey, int(code)),
+		zap.String("message", msg),
+	}, fields...)...)
+}
+
+// Downgraded logs a Downgrade event.
+func Downgraded(logger *zap.Logger, msg, reason string) {
+	logger.Info(msg,
+		zap.String(EventKey, Downgrade),
+		zap.String(ReasonKey, reason),
+	)
+}
+
+// Upgraded logs an Upgrade event.
+func Upgraded(logger *zap.Logger, msg string) {
+	logger.Info(msg, zap.String(EventKey, Upgrade))
+}
@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package streamevents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/codes"
+)
+
+func TestEvents(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	Started(With(logger, "3", "/arrow.Traces"))
+	Rotated(With(logger, "3", "/arrow.Traces"), time.Now().Add(-time.Minute), ReasonLifetime)
+	Failed(With(logger, "4", ""), codes.Unavailable, "connection reset", zap.String("which", "reader"))
+	Downgraded(logger, "downgrading", ReasonUnsupported)
+	Upgraded(logger, "upgrading")
+
+	all := logs.All()
+	require.Len(t, all, 5)
+
+	require.Equal(t, zapcore.DebugLevel, all[0].Level)
+	require.Equal(t, map[string]any{
+		EventKey:    StreamStart,
+		StreamIDKey: "3",
+		MethodKey:   "/arrow.Traces",
+	}, all[0].ContextMap())
+
+	require.Equal(t, zapcore.DebugLevel, all[1].Level)
+	fields := all[1].ContextMap()
+	require.Equal(t, StreamRotate, fields[EventKey])
+	require.Equal(t, ReasonLifetime, fields[ReasonKey])
+	require.GreaterOrEqual(t, fields[AgeKey], time.Minute)
+
+	require.Equal(t, zapcore.ErrorLevel, all[2].Level)
+	require.Equal(t, map[string]any{
+		EventKey:    StreamError,
+		StreamIDKey: "4",
+		CodeKey:     int64(codes.Unavailable),
+		"message":   "connection reset",
+		"which":     "reader",
+	}, all[2].ContextMap())
+
+	require.Equal(t, zapcore.InfoLevel, all[3].Level)
+	require.Equal(t, "downgrading", all[3].Message)
+	require.Equal(t, map[string]any{
+		EventKey:  Downgrade,
+		ReasonKey: ReasonUnsupported,
+	}, all[3].ContextMap())
+
+	require.Equal(t, zapcore.InfoLevel, all[4].Level)
+	require.Equal(t, map[string]any{EventKey: Upgrade}, all[4].ContextMap())
+}
+
+func TestNewStreamID(t *testing.T) {
+	ids := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		id := NewStreamID()
+		require.Len(t, id, 12)
+		require.False(t, ids[id])
+		ids[id] = true
+	}
+}
+
+func TestLabels(t *testing.T) {
+	name, value, ok := ParseLabel(FormatLabel("region", "us-east-1"))
+	require.True(t, ok)
+	require.Equal(t, "region", name)
+	require.Equal(t, "us-east-1", value)
+
+	name, value, ok = ParseLabel(FormatLabel("selector", "app=web"))
+	require.True(t, ok)
+	require.Equal(t, "selector", name)
+	require.Equal(t, "app=web", value)
+
+	_, _, ok = ParseLabel("region")
+	require.False(t, ok)
+	_, _, ok = ParseLabel("=us-east-1")
+	require.False(t, ok)
+}
@@ -14,6 +14,8 @@ import (
 
 	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
 	"github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver"
+	"github.com/open-telemetry/otel-arrow/collector/test/harness"
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
 	"github.com/open-telemetry/otel-arrow/collector/testutil"
 	"github.com/open-telemetry/otel-arrow/pkg/otel/assert"
 	"github.com/stretchr/testify/require"
@@ -189,3 +191,48 @@ func TestIntegrationSimpleTraces(t *testing.T) {
 	asserter := assert.NewStdUnitTest(t)
 	assert.Equiv(asserter, expectJSON, receivedJSON)
 }
+
+// TestIntegrationHarnessTraces runs concurrent senders through the
+// in-process harness, with the same consumer delays as
+// TestIntegrationSimpleTraces.
+func TestIntegrationHarnessTraces(t *testing.T) {
+	const (
+		threadCount  = 4
+		requestCount = 25
+	)
+
+	h := harness.New(t,
+		// Consumers take between 0 and 2ms.
+		harness.WithFaults(harness.Faults{MaxDelay: 2 * time.Millisecond}),
+	)
+
+	ctx := context.Background()
+	var clientDoneWG sync.WaitGroup
+	var expect [threadCount][]ptrace.Traces
+
+	for num := 0; num < threadCount; num++ {
+		clientDoneWG.Add(1)
+		go func() {
+			defer clientDoneWG.Done()
+			for i := 0; i < requestCount; i++ {
+				td := testdata.GenerateTraces(1 + i%3)
+				expect[num] = append(expect[num], td)
+				require.NoError(t, h.Traces.ConsumeTraces(ctx, td))
+			}
+		}()
+	}
+	clientDoneWG.Wait()
+	require.NoError(t, h.Shutdown(ctx))
+
+	var expectJSON []json.Marshaler
+	for _, tdn := range expect {
+		for _, td := range tdn {
+			expectJSON = append(expectJSON, ptraceotlp.NewExportRequestFromTraces(td))
+		}
+	}
+	var receivedJSON []json.Marshaler
+	for _, td := range h.TracesSink.AllTraces() {
+		receivedJSON = append(receivedJSON, ptraceotlp.NewExportRequestFromTraces(td))
+	}
+	assert.Equiv(assert.NewStdUnitTest(t), expectJSON, receivedJSON)
+}
@@ -0,0 +1,364 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package harness wires an OTel-Arrow exporter to an OTel-Arrow
+// receiver in-process, so that end-to-end tests of the Arrow path can
+// run without containers or a separately deployed collector.
+package harness // import "github.com/open-telemetry/otel-arrow/collector/test/harness"
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/multierr"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+	"github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver"
+	"github.com/open-telemetry/otel-arrow/collector/testutil"
+)
+
+// ErrInjected is returned by the receiver's consumer when Faults
+// select a request for failure and Faults.Error is not set.
+var ErrInjected = errors.New("harness: injected failure")
+
+// Faults configures failures and latency injected into the
+// receiver's consumer.
+type Faults struct {
+	// ErrorRate is the fraction of requests, in [0, 1], that
+	// fail with Error.
+	ErrorRate float64
+
+	// Error is returned for failed requests.  Defaults to
+	// ErrInjected.
+	Error error
+
+	// MaxDelay is the upper bound of a uniformly random delay
+	// added to every request.
+	MaxDelay time.Duration
+
+	// Seed seeds the random source used to select failures and
+	// delays.
+	Seed int64
+}
+
+// Option configures a Harness.
+type Option func(*options)
+
+type options struct {
+	exporterConfig func(*otelarrowexporter.Config)
+	receiverConfig func(*otelarrowreceiver.Config)
+	telset         component.TelemetrySettings
+	faults         Faults
+	traces         consumer.Traces
+	metrics        consumer.Metrics
+	logs           consumer.Logs
+}
+
+// WithExporterConfig modifies the exporter configuration after the
+// harness has set its defaults.
+func WithExporterConfig(f func(*otelarrowexporter.Config)) Option {
+	return func(o *options) {
+		o.exporterConfig = f
+	}
+}
+
+// WithReceiverConfig modifies the receiver configuration after the
+// harness has set its defaults.
+func WithReceiverConfig(f func(*otelarrowreceiver.Config)) Option {
+	return func(o *options) {
+		o.receiverConfig = f
+	}
+}
+
+// WithTelemetrySettings sets the telemetry used by both components.
+func WithTelemetrySettings(telset component.TelemetrySettings) Option {
+	return func(o *options) {
+		o.telset = telset
+	}
+}
+
+// WithFaults injects failures and latency into the receiver's
+// consumer.
+func WithFaults(f Faults) Option {
+	return func(o *options) {
+		o.faults = f
+	}
+}
+
+// WithConsumers delivers received data to the given consumers in
+// place of the harness sinks, which are left nil.  Long-running tests
+// use this to avoid retaining every request.
+func WithConsumers(traces consumer.Traces, metrics consumer.Metrics, logs consumer.Logs) Option {
+	return func(o *options) {
+		o.traces = traces
+		o.metrics = metrics
+		o.logs = logs
+	}
+}
+
+// Harness is a running exporter and receiver pair connected over a
+// local address.  Data sent to the exporters is delivered to the
+// sinks.
+type Harness struct {
+	Traces  exporter.Traces
+	Metrics exporter.Metrics
+	Logs    exporter.Logs
+
+	TracesSink  *consumertest.TracesSink
+	MetricsSink *consumertest.MetricsSink
+	LogsSink    *consumertest.LogsSink
+
+	faults *faultInjector
+
+	receivers []component.Component
+
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// New starts a Harness.  By default the exporter uses one Arrow
+// stream with retries and queueing disabled, so that each call to
+// an exporter returns the outcome of its own request.  The harness
+// is shut down when the test finishes, if Shutdown was not already
+// called.
+func New(t testing.TB, opts ...Option) *Harness {
+	o := options{
+		telset: componenttest.NewNopTelemetrySettings(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	efact := otelarrowexporter.NewFactory()
+	rfact := otelarrowreceiver.NewFactory()
+
+	ecfg := efact.CreateDefaultConfig().(*otelarrowexporter.Config)
+	rcfg := rfact.CreateDefaultConfig().(*otelarrowreceiver.Config)
+
+	addr := testutil.GetAvailableLocalAddress(t)
+
+	rcfg.Protocols.GRPC.NetAddr.Endpoint = addr
+	ecfg.ClientConfig.Endpoint = addr
+	ecfg.ClientConfig.WaitForReady = true
+	ecfg.ClientConfig.TLSSetting.Insecure = true
+	ecfg.TimeoutSettings.Timeout = time.Minute
+	ecfg.QueueSettings.Enabled = false
+	ecfg.RetryConfig.Enabled = false
+	ecfg.Arrow.NumStreams = 1
+
+	if o.exporterConfig != nil {
+		o.exporterConfig(ecfg)
+	}
+	if o.receiverConfig != nil {
+		o.receiverConfig(rcfg)
+	}
+
+	h := &Harness{
+		faults: newFaultInjector(o.faults),
+	}
+	if o.traces == nil {
+		h.TracesSink = new(consumertest.TracesSink)
+		h.MetricsSink = new(consumertest.MetricsSink)
+		h.LogsSink = new(consumertest.LogsSink)
+		o.traces, o.metrics, o.logs = h.TracesSink, h.MetricsSink, h.LogsSink
+	}
+
+	ctx := context.Background()
+	host := componenttest.NewNopHost()
+
+	rset := receiver.CreateSettings{
+		ID:                component.MustNewID("otelarrow"),
+		TelemetrySettings: o.telset,
+	}
+	eset := exporter.CreateSettings{
+		ID:                component.MustNewID("otelarrow"),
+		TelemetrySettings: o.telset,
+	}
+
+	// The receivers for each signal share one server, which is
+	// started once and stopped once all have shut down.
+	tr, err := rfact.CreateTracesReceiver(ctx, rset, rcfg, &faultTraces{h.faults, o.traces})
+	if err != nil {
+		t.Fatalf("create traces receiver: %v", err)
+	}
+	mr, err := rfact.CreateMetricsReceiver(ctx, rset, rcfg, &faultMetrics{h.faults, o.metrics})
+	if err != nil {
+		t.Fatalf("create metrics receiver: %v", err)
+	}
+	lr, err := rfact.CreateLogsReceiver(ctx, rset, rcfg, &faultLogs{h.faults, o.logs})
+	if err != nil {
+		t.Fatalf("create logs receiver: %v", err)
+	}
+	h.receivers = []component.Component{tr, mr, lr}
+
+	if h.Traces, err = efact.CreateTracesExporter(ctx, eset, ecfg); err != nil {
+		t.Fatalf("create traces exporter: %v", err)
+	}
+	if h.Metrics, err = efact.CreateMetricsExporter(ctx, eset, ecfg); err != nil {
+		t.Fatalf("create metrics exporter: %v", err)
+	}
+	if h.Logs, err = efact.CreateLogsExporter(ctx, eset, ecfg); err != nil {
+		t.Fatalf("create logs exporter: %v", err)
+	}
+
+	for _, c := range h.receivers {
+		if err := c.Start(ctx, host); err != nil {
+			t.Fatalf("start receiver: %v", err)
+		}
+	}
+	for _, c := range h.exporters() {
+		if err := c.Start(ctx, host); err != nil {
+			t.Fatalf("start exporter: %v", err)
+		}
+	}
+
+	t.Cleanup(func() {
+		if err := h.Shutdown(context.Background()); err != nil {
+			t.Errorf("harness shutdown: %v", err)
+		}
+	})
+	return h
+}
+
+func (h *Harness) exporters() []component.Component {
+	return []component.Component{h.Traces, h.Metrics, h.Logs}
+}
+
+// Shutdown stops the exporters, then the receivers.  Once it
+// returns, every request accepted by the receiver has reached the
+// sinks.  Calls after the first return the first result.
+func (h *Harness) Shutdown(ctx context.Context) error {
+	h.shutdownOnce.Do(func() {
+		for _, c := range h.exporters() {
+			h.shutdownErr = multierr.Append(h.shutdownErr, c.Shutdown(ctx))
+		}
+		for _, c := range h.receivers {
+			h.shutdownErr = multierr.Append(h.shutdownErr, c.Shutdown(ctx))
+		}
+	})
+	return h.shutdownErr
+}
+
+// SetFaults replaces the faults injected into the receiver's
+// consumer, e.g., to simulate a backend that fails intermittently.
+// The random source is not reseeded.
+func (h *Harness) SetFaults(f Faults) {
+	h.faults.set(f)
+}
+
+// InjectedErrors returns the number of requests failed by Faults.
+func (h *Harness) InjectedErrors() int64 {
+	return h.faults.injected.Load()
+}
+
+type faultInjector struct {
+	lock     sync.Mutex
+	faults   Faults
+	rng      *rand.Rand
+	injected atomic.Int64
+}
+
+func newFaultInjector(f Faults) *faultInjector {
+	fi := &faultInjector{
+		rng: rand.New(rand.NewSource(f.Seed)), //nolint:gosec // test fault selection
+	}
+	fi.set(f)
+	return fi
+}
+
+func (fi *faultInjector) set(f Faults) {
+	if f.Error == nil {
+		f.Error = ErrInjected
+	}
+	fi.lock.Lock()
+	defer fi.lock.Unlock()
+	fi.faults = f
+}
+
+// apply delays and returns an error according to the configured
+// faults.
+func (fi *faultInjector) apply(ctx context.Context) error {
+	fi.lock.Lock()
+	f := fi.faults
+	var delay time.Duration
+	if f.MaxDelay > 0 {
+		delay = time.Duration(fi.rng.Int63n(int64(f.MaxDelay)))
+	}
+	fail := f.ErrorRate > 0 && fi.rng.Float64() < f.ErrorRate
+	fi.lock.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if fail {
+		fi.injected.Add(1)
+		return f.Error
+	}
+	return nil
+}
+
+type faultTraces struct {
+	*faultInjector
+	next consumer.Traces
+}
+
+func (f *faultTraces) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{}
+}
+
+func (f *faultTraces) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	if err := f.apply(ctx); err != nil {
+		return err
+	}
+	return f.next.ConsumeTraces(ctx, td)
+}
+
+type faultMetrics struct {
+	*faultInjector
+	next consumer.Metrics
+}
+
+func (f *faultMetrics) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{}
+}
+
+func (f *faultMetrics) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	if err := f.apply(ctx); err != nil {
+		return err
+	}
+	return f.next.ConsumeMetrics(ctx, md)
+}
+
+type faultLogs struct {
+	*faultInjector
+	next consumer.Logs
+}
+
+func (f *faultLogs) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{}
+}
+
+func (f *faultLogs) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	if err := f.apply(ctx); err != nil {
+		return err
+	}
+	return f.next.ConsumeLogs(ctx, ld)
+}
@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package harness
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+)
+
+func TestHarnessAllSignals(t *testing.T) {
+	h := New(t, WithExporterConfig(func(cfg *otelarrowexporter.Config) {
+		cfg.Arrow.NumStreams = 2
+	}))
+	ctx := context.Background()
+
+	require.NoError(t, h.Traces.ConsumeTraces(ctx, testdata.GenerateTraces(3)))
+	require.NoError(t, h.Metrics.ConsumeMetrics(ctx, testdata.GenerateMetrics(4)))
+	require.NoError(t, h.Logs.ConsumeLogs(ctx, testdata.GenerateLogs(5)))
+
+	require.NoError(t, h.Shutdown(ctx))
+	require.NoError(t, h.Shutdown(ctx))
+
+	assert.Equal(t, 3, h.TracesSink.SpanCount())
+	// GenerateMetrics produces two points per metric.
+	assert.Equal(t, 8, h.MetricsSink.DataPointCount())
+	assert.Equal(t, 5, h.LogsSink.LogRecordCount())
+	assert.Equal(t, int64(0), h.InjectedErrors())
+}
+
+func TestHarnessFaults(t *testing.T) {
+	h := New(t, WithFaults(Faults{ErrorRate: 1}))
+	ctx := context.Background()
+
+	err := h.Traces.ConsumeTraces(ctx, testdata.GenerateTraces(1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInjected.Error())
+	assert.Equal(t, int64(1), h.InjectedErrors())
+	assert.Equal(t, 0, h.TracesSink.SpanCount())
+}
+
+func TestHarnessSetFaultsAndConsumers(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	h := New(t, WithConsumers(sink, consumertest.NewNop(), consumertest.NewNop()))
+	ctx := context.Background()
+	assert.Nil(t, h.TracesSink)
+
+	h.SetFaults(Faults{ErrorRate: 1})
+	require.Error(t, h.Traces.ConsumeTraces(ctx, testdata.GenerateTraces(1)))
+
+	h.SetFaults(Faults{})
+	require.NoError(t, h.Traces.ConsumeTraces(ctx, testdata.GenerateTraces(2)))
+
+	require.NoError(t, h.Shutdown(ctx))
+	assert.Equal(t, 2, sink.SpanCount())
+	assert.Equal(t, int64(1), h.InjectedErrors())
+}
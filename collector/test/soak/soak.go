@@ -0,0 +1,377 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package soak runs OTel-Arrow exporter/receiver pairs for long
+// periods under realistic churn: streams are rotated by their maximum
+// lifetime, the shape of the data changes continuously so that
+// schemas and dictionaries are reset, and the backend periodically
+// flaps between healthy and failing.  At the end of the run it
+// checks invariants that unit tests tend to miss: every export call
+// returned, no data was lost or invented, and goroutines returned to
+// their baseline.
+package soak // import "github.com/open-telemetry/otel-arrow/collector/test/soak"
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+	"github.com/open-telemetry/otel-arrow/collector/test/harness"
+	"github.com/open-telemetry/otel-arrow/collector/testdata"
+)
+
+// Config describes a soak run.  Zero-valued fields take the defaults
+// noted.
+type Config struct {
+	// Duration is the length of the run, not including shutdown.
+	Duration time.Duration
+
+	// Pairs is the number of exporter/receiver pairs (default 1).
+	Pairs int
+
+	// Senders is the number of goroutines calling each pair's
+	// exporters (default 4).
+	Senders int
+
+	// NumStreams is the exporter's number of Arrow streams
+	// (default 2).
+	NumStreams int
+
+	// MaxStreamLifetime is the exporter's stream lifetime; short
+	// lifetimes rotate streams frequently (default 5s).
+	MaxStreamLifetime time.Duration
+
+	// FlapInterval is how long the backend alternates between
+	// healthy and failing.  Zero disables flapping.
+	FlapInterval time.Duration
+
+	// FlapErrorRate is the fraction of requests failed while the
+	// backend is failing (default 0.5).
+	FlapErrorRate float64
+
+	// MaxDelay bounds the backend's per-request latency
+	// (default 1ms).
+	MaxDelay time.Duration
+
+	// StuckTimeout is the export timeout.  A call that runs for
+	// this long is counted as stuck (default 30s).
+	StuckTimeout time.Duration
+
+	// GoroutineSlack is the number of goroutines allowed above
+	// the baseline after shutdown (default 10).
+	GoroutineSlack int
+
+	// ReportInterval is the period of progress reports
+	// (default 1m).
+	ReportInterval time.Duration
+
+	// Seed seeds data generation and fault selection.
+	Seed int64
+}
+
+func (c Config) withDefaults() Config {
+	if c.Pairs <= 0 {
+		c.Pairs = 1
+	}
+	if c.Senders <= 0 {
+		c.Senders = 4
+	}
+	if c.NumStreams <= 0 {
+		c.NumStreams = 2
+	}
+	if c.MaxStreamLifetime <= 0 {
+		c.MaxStreamLifetime = 5 * time.Second
+	}
+	if c.FlapErrorRate <= 0 {
+		c.FlapErrorRate = 0.5
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = time.Millisecond
+	}
+	if c.StuckTimeout <= 0 {
+		c.StuckTimeout = 30 * time.Second
+	}
+	if c.GoroutineSlack <= 0 {
+		c.GoroutineSlack = 10
+	}
+	if c.ReportInterval <= 0 {
+		c.ReportInterval = time.Minute
+	}
+	return c
+}
+
+// Result summarizes a soak run.  Items are spans, metric data points,
+// and log records.
+type Result struct {
+	Requests int64
+	Failures int64
+	Stuck    int64
+
+	ItemsSent     int64
+	ItemsAcked    int64
+	ItemsReceived int64
+
+	GoroutinesBefore int
+	GoroutinesAfter  int
+
+	HeapBefore uint64
+	HeapAfter  uint64
+}
+
+// shapes are the data shapes senders choose among.  Varying the
+// number of attributes changes the schema; unbounded cardinality
+// eventually overflows dictionaries.
+var shapes = []testdata.Shape{
+	{Resources: 1, Scopes: 1, Items: 10, Attributes: 0},
+	{Resources: 2, Scopes: 2, Items: 10, Attributes: 3, Cardinality: 10},
+	{Resources: 1, Scopes: 3, Items: 50, Attributes: 6, Cardinality: 1000},
+	{Resources: 3, Scopes: 1, Items: 20, Attributes: 8},
+}
+
+type counters struct {
+	requests      atomic.Int64
+	failures      atomic.Int64
+	stuck         atomic.Int64
+	itemsSent     atomic.Int64
+	itemsAcked    atomic.Int64
+	itemsReceived atomic.Int64
+}
+
+// Run performs a soak run and reports invariant violations as test
+// errors.
+func Run(t testing.TB, cfg Config) Result {
+	cfg = cfg.withDefaults()
+
+	var res Result
+	res.GoroutinesBefore = runtime.NumGoroutine()
+	res.HeapBefore = liveHeap()
+
+	var cnt counters
+	pairs := make([]*harness.Harness, cfg.Pairs)
+	for i := range pairs {
+		recv := &countingConsumer{count: &cnt.itemsReceived}
+		pairs[i] = harness.New(t,
+			harness.WithTelemetrySettings(componenttest.NewNopTelemetrySettings()),
+			harness.WithConsumers(recv, recv, recv),
+			harness.WithFaults(harness.Faults{
+				MaxDelay: cfg.MaxDelay,
+				Seed:     cfg.Seed + int64(i),
+			}),
+			harness.WithExporterConfig(func(ecfg *otelarrowexporter.Config) {
+				ecfg.Arrow.NumStreams = cfg.NumStreams
+				ecfg.Arrow.MaxStreamLifetime = cfg.MaxStreamLifetime
+				ecfg.TimeoutSettings.Timeout = cfg.StuckTimeout
+			}),
+		)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Duration)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i, h := range pairs {
+		for s := 0; s < cfg.Senders; s++ {
+			wg.Add(1)
+			seed := cfg.Seed + int64(i*cfg.Senders+s)
+			go func(h *harness.Harness) {
+				defer wg.Done()
+				send(ctx, h, seed, cfg, &cnt)
+			}(h)
+		}
+		if cfg.FlapInterval > 0 {
+			wg.Add(1)
+			go func(h *harness.Harness) {
+				defer wg.Done()
+				flap(ctx, h, cfg)
+			}(h)
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		report(ctx, t, cfg, &cnt)
+	}()
+
+	wg.Wait()
+
+	for _, h := range pairs {
+		if err := h.Shutdown(context.Background()); err != nil {
+			t.Errorf("soak: shutdown: %v", err)
+		}
+	}
+
+	res.Requests = cnt.requests.Load()
+	res.Failures = cnt.failures.Load()
+	res.Stuck = cnt.stuck.Load()
+	res.ItemsSent = cnt.itemsSent.Load()
+	res.ItemsAcked = cnt.itemsAcked.Load()
+	res.ItemsReceived = cnt.itemsReceived.Load()
+	res.GoroutinesAfter = settleGoroutines(res.GoroutinesBefore+cfg.GoroutineSlack, 10*time.Second)
+	res.HeapAfter = liveHeap()
+
+	t.Logf("soak: %d requests, %d failed, %d items sent, %d acked, %d received; goroutines %d -> %d; heap %d -> %d bytes",
+		res.Requests, res.Failures, res.ItemsSent, res.ItemsAcked, res.ItemsReceived,
+		res.GoroutinesBefore, res.GoroutinesAfter, res.HeapBefore, res.HeapAfter)
+
+	if res.Stuck != 0 {
+		t.Errorf("soak: %d export calls did not return within %v", res.Stuck, cfg.StuckTimeout)
+	}
+	if res.ItemsReceived < res.ItemsAcked {
+		t.Errorf("soak: %d items acknowledged but only %d received", res.ItemsAcked, res.ItemsReceived)
+	}
+	if res.ItemsReceived > res.ItemsSent {
+		t.Errorf("soak: %d items received but only %d sent", res.ItemsReceived, res.ItemsSent)
+	}
+	if res.GoroutinesAfter > res.GoroutinesBefore+cfg.GoroutineSlack {
+		t.Errorf("soak: goroutines leaked: %d before, %d after shutdown", res.GoroutinesBefore, res.GoroutinesAfter)
+	}
+	return res
+}
+
+// send exports data of randomly chosen shapes and signals until ctx
+// is done.
+func send(ctx context.Context, h *harness.Harness, seed int64, cfg Config, cnt *counters) {
+	rng := rand.New(rand.NewSource(seed)) //nolint:gosec // deterministic test data
+	gens := make([]*testdata.Generator, len(shapes))
+	for i, shape := range shapes {
+		gens[i] = testdata.NewGenerator(seed*int64(len(shapes))+int64(i), shape)
+	}
+
+	for ctx.Err() == nil {
+		gen := gens[rng.Intn(len(gens))]
+
+		var items int
+		var export func(context.Context) error
+		switch rng.Intn(3) {
+		case 0:
+			td := gen.Traces()
+			items = td.SpanCount()
+			export = func(ctx context.Context) error { return h.Traces.ConsumeTraces(ctx, td) }
+		case 1:
+			md := gen.Metrics()
+			items = md.DataPointCount()
+			export = func(ctx context.Context) error { return h.Metrics.ConsumeMetrics(ctx, md) }
+		default:
+			ld := gen.Logs()
+			items = ld.LogRecordCount()
+			export = func(ctx context.Context) error { return h.Logs.ConsumeLogs(ctx, ld) }
+		}
+
+		cnt.requests.Add(1)
+		cnt.itemsSent.Add(int64(items))
+
+		// The caller's context is not canceled at the end of the
+		// run, so that calls in progress finish normally.
+		start := time.Now()
+		err := export(context.Background())
+		if time.Since(start) >= cfg.StuckTimeout {
+			cnt.stuck.Add(1)
+		}
+		if err != nil {
+			cnt.failures.Add(1)
+			continue
+		}
+		cnt.itemsAcked.Add(int64(items))
+	}
+}
+
+// flap alternates the backend between healthy and failing.
+func flap(ctx context.Context, h *harness.Harness, cfg Config) {
+	ticker := time.NewTicker(cfg.FlapInterval)
+	defer ticker.Stop()
+
+	failing := false
+	for {
+		select {
+		case <-ctx.Done():
+			h.SetFaults(harness.Faults{MaxDelay: cfg.MaxDelay})
+			return
+		case <-ticker.C:
+		}
+		failing = !failing
+		f := harness.Faults{MaxDelay: cfg.MaxDelay}
+		if failing {
+			f.ErrorRate = cfg.FlapErrorRate
+		}
+		h.SetFaults(f)
+	}
+}
+
+func report(ctx context.Context, t testing.TB, cfg Config, cnt *counters) {
+	ticker := time.NewTicker(cfg.ReportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		t.Logf("soak: %d requests, %d failed, %d stuck, %d items acked, %d received, %d goroutines, %d heap bytes",
+			cnt.requests.Load(), cnt.failures.Load(), cnt.stuck.Load(),
+			cnt.itemsAcked.Load(), cnt.itemsReceived.Load(),
+			runtime.NumGoroutine(), liveHeap())
+	}
+}
+
+// settleGoroutines waits up to timeout for the number of goroutines
+// to fall to limit, and returns the last count.
+func settleGoroutines(limit int, timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+	for {
+		n := runtime.NumGoroutine()
+		if n <= limit || time.Now().After(deadline) {
+			return n
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func liveHeap() uint64 {
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.HeapAlloc
+}
+
+// countingConsumer counts and discards the items it receives.
+type countingConsumer struct {
+	count *atomic.Int64
+}
+
+var (
+	_ consumer.Traces  = &countingConsumer{}
+	_ consumer.Metrics = &countingConsumer{}
+	_ consumer.Logs    = &countingConsumer{}
+)
+
+func (c *countingConsumer) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{}
+}
+
+func (c *countingConsumer) ConsumeTraces(_ context.Context, td ptrace.Traces) error {
+	c.count.Add(int64(td.SpanCount()))
+	return nil
+}
+
+func (c *countingConsumer) ConsumeMetrics(_ context.Context, md pmetric.Metrics) error {
+	c.count.Add(int64(md.DataPointCount()))
+	return nil
+}
+
+func (c *countingConsumer) ConsumeLogs(_ context.Context, ld plog.Logs) error {
+	c.count.Add(int64(ld.LogRecordCount()))
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package soak
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A long soak runs with, e.g.:
+//
+//	go test ./soak -run TestSoak -timeout 0 -soak.duration 4h
+var soakDuration = flag.Duration("soak.duration", 0, "length of the soak run; short when unset")
+
+func TestSoak(t *testing.T) {
+	cfg := Config{
+		Duration:          *soakDuration,
+		Pairs:             2,
+		FlapInterval:      10 * time.Second,
+		MaxStreamLifetime: 30 * time.Second,
+	}
+	if cfg.Duration == 0 {
+		// A short run with frequent churn keeps the harness
+		// itself working.
+		cfg.Duration = 3 * time.Second
+		cfg.Pairs = 1
+		cfg.Senders = 2
+		cfg.FlapInterval = 500 * time.Millisecond
+		cfg.MaxStreamLifetime = time.Second
+		cfg.ReportInterval = time.Second
+	}
+
+	res := Run(t, cfg)
+	assert.Positive(t, res.Requests)
+	assert.Positive(t, res.ItemsAcked)
+	assert.Positive(t, res.Failures, "the backend flapped")
+}
@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-telemetry/otel-arrow/collector/exporter/otelarrowexporter"
+	"github.com/open-telemetry/otel-arrow/collector/receiver/otelarrowreceiver"
+	"github.com/open-telemetry/otel-arrow/collector/test/harness"
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegrationZstdDictionary sends traces compressed with a
+// pre-trained Zstd dictionary configured on both sides.
+func TestIntegrationZstdDictionary(t *testing.T) {
+	ent := datagen.NewTestEntropy(12345)
+	dg := datagen.NewTracesGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	)
+
+	var samples [][]byte
+	for i := 0; i < 20; i++ {
+		producer := arrow_record.NewProducerWithOptions(config.WithNoZstd())
+		batch, err := producer.BatchArrowRecordsFromTraces(dg.Generate(5, 100))
+		require.NoError(t, err)
+		for _, payload := range batch.ArrowPayloads {
+			samples = append(samples, payload.Record)
+		}
+		require.NoError(t, producer.Close())
+	}
+	dict, err := zstddict.Train(samples, 8<<10)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "zstd.dict")
+	require.NoError(t, os.WriteFile(path, dict, 0o600))
+
+	h := harness.New(t,
+		harness.WithExporterConfig(func(cfg *otelarrowexporter.Config) {
+			cfg.Arrow.ZstdDictionary = path
+			cfg.Arrow.DisableDowngrade = true
+		}),
+		harness.WithReceiverConfig(func(cfg *otelarrowreceiver.Config) {
+			cfg.Arrow.ZstdDictionaries = []string{path}
+		}),
+	)
+
+	ctx := context.Background()
+	var spans int
+	for i := 0; i < 10; i++ {
+		td := dg.Generate(5, 100)
+		spans += td.SpanCount()
+		require.NoError(t, h.Traces.ConsumeTraces(ctx, td))
+	}
+	require.NoError(t, h.Shutdown(ctx))
+	require.Equal(t, spans, h.TracesSink.SpanCount())
+}
@@ -102,6 +102,117 @@ func GenerateMetrics(count int) pmetric.Metrics {
 	return md
 }
 
+// MetricsScale configures the size of the data produced by
+// GenerateMetricsAtScale.  Zero-valued fields are treated as 1.
+type MetricsScale struct {
+	// Resources is the number of resources.
+	Resources int
+	// Scopes is the number of instrumentation scopes per resource.
+	Scopes int
+	// Metrics is the number of metrics per scope.  Metrics cycle
+	// through every metric type, as in GenerateMetrics.
+	Metrics int
+	// DataPoints is the number of points per metric.
+	DataPoints int
+}
+
+// GenerateMetricsAtScale returns metrics of every type, including
+// exponential histograms, summaries, and monotonic sums carrying
+// exemplars, replicated to the requested scale.  Replicated resources,
+// scopes, and points are distinguished by an index attribute.
+func GenerateMetricsAtScale(scale MetricsScale) pmetric.Metrics {
+	atLeastOne := func(v int) int {
+		if v <= 0 {
+			return 1
+		}
+		return v
+	}
+	scale.Resources = atLeastOne(scale.Resources)
+	scale.Scopes = atLeastOne(scale.Scopes)
+	scale.Metrics = atLeastOne(scale.Metrics)
+	scale.DataPoints = atLeastOne(scale.DataPoints)
+
+	md := pmetric.NewMetrics()
+	rms := md.ResourceMetrics()
+	rms.EnsureCapacity(scale.Resources)
+	for r := 0; r < scale.Resources; r++ {
+		rm := rms.AppendEmpty()
+		initResource(rm.Resource())
+		rm.Resource().Attributes().PutInt("resource-index", int64(r))
+		sms := rm.ScopeMetrics()
+		sms.EnsureCapacity(scale.Scopes)
+		for s := 0; s < scale.Scopes; s++ {
+			sm := sms.AppendEmpty()
+			sm.Scope().SetName("scale-test")
+			sm.Scope().Attributes().PutInt("scope-index", int64(s))
+			GenerateMetrics(scale.Metrics).ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().MoveAndAppendTo(sm.Metrics())
+			for m := 0; m < sm.Metrics().Len(); m++ {
+				scaleMetric(sm.Metrics().At(m), scale.DataPoints)
+			}
+		}
+	}
+	return md
+}
+
+// scaleMetric resizes the points of m to n, replicating the existing
+// points, and attaches exemplars to monotonic sums.
+func scaleMetric(m pmetric.Metric, n int) {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		scalePoints(m.Gauge().DataPoints(), n)
+	case pmetric.MetricTypeSum:
+		dps := m.Sum().DataPoints()
+		scalePoints(dps, n)
+		if m.Sum().IsMonotonic() {
+			for i := 0; i < dps.Len(); i++ {
+				initSumExemplar(dps.At(i).Exemplars().AppendEmpty(), i)
+			}
+		}
+	case pmetric.MetricTypeHistogram:
+		scalePoints(m.Histogram().DataPoints(), n)
+	case pmetric.MetricTypeExponentialHistogram:
+		scalePoints(m.ExponentialHistogram().DataPoints(), n)
+	case pmetric.MetricTypeSummary:
+		scalePoints(m.Summary().DataPoints(), n)
+	}
+}
+
+// pointSlice is implemented by each of the pmetric data point slices.
+type pointSlice[P point[P]] interface {
+	Len() int
+	At(int) P
+	AppendEmpty() P
+	RemoveIf(func(P) bool)
+}
+
+type point[P any] interface {
+	Attributes() pcommon.Map
+	CopyTo(P)
+}
+
+func scalePoints[P point[P], S pointSlice[P]](dps S, n int) {
+	have := dps.Len()
+	for i := have; i < n; i++ {
+		dps.At(i % have).CopyTo(dps.AppendEmpty())
+	}
+	idx := 0
+	dps.RemoveIf(func(P) bool {
+		idx++
+		return idx > n
+	})
+	for i := 0; i < dps.Len(); i++ {
+		dps.At(i).Attributes().PutInt("point-index", int64(i))
+	}
+}
+
+func initSumExemplar(ex pmetric.Exemplar, i int) {
+	ex.SetTimestamp(metricExemplarTimestamp)
+	ex.SetIntValue(int64(i + 1))
+	ex.SetTraceID([16]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, byte(i)})
+	ex.SetSpanID([8]byte{0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, byte(i)})
+	initMetricExemplarAttributes(ex.FilteredAttributes())
+}
+
 func initGaugeIntMetric(im pmetric.Metric) {
 	initMetric(im, TestGaugeIntMetricName, pmetric.MetricTypeGauge)
 
@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestGenerateMetricsAtScale(t *testing.T) {
+	md := GenerateMetricsAtScale(MetricsScale{
+		Resources:  2,
+		Scopes:     3,
+		Metrics:    14,
+		DataPoints: 5,
+	})
+
+	require.Equal(t, 2, md.ResourceMetrics().Len())
+	assert.Equal(t, 2*3*14, md.MetricCount())
+	assert.Equal(t, 2*3*14*5, md.DataPointCount())
+
+	types := map[pmetric.MetricType]int{}
+	ms := md.ResourceMetrics().At(1).ScopeMetrics().At(2).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		m := ms.At(i)
+		types[m.Type()]++
+		if m.Type() == pmetric.MetricTypeSum {
+			dps := m.Sum().DataPoints()
+			for j := 0; j < dps.Len(); j++ {
+				assert.Equal(t, 1, dps.At(j).Exemplars().Len())
+				idx, ok := dps.At(j).Attributes().Get("point-index")
+				assert.True(t, ok)
+				assert.Equal(t, int64(j), idx.Int())
+			}
+		}
+	}
+	assert.Equal(t, map[pmetric.MetricType]int{
+		pmetric.MetricTypeGauge:                4,
+		pmetric.MetricTypeSum:                  4,
+		pmetric.MetricTypeHistogram:            2,
+		pmetric.MetricTypeExponentialHistogram: 2,
+		pmetric.MetricTypeSummary:              2,
+	}, types)
+}
+
+func TestGenerateMetricsAtScaleFewerPoints(t *testing.T) {
+	md := GenerateMetricsAtScale(MetricsScale{Metrics: 7, DataPoints: 1})
+	assert.Equal(t, 7, md.DataPointCount())
+}
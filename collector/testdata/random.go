@@ -0,0 +1,263 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+var randomBaseTimestamp = time.Date(2020, 2, 11, 20, 26, 12, 0, time.UTC)
+
+// Shape describes the structure of data produced by a Generator.
+// Zero-valued fields are treated as 1.
+type Shape struct {
+	// Resources is the number of resources per batch.
+	Resources int
+	// Scopes is the number of instrumentation scopes per resource.
+	Scopes int
+	// Items is the number of spans, metrics, or log records per scope.
+	Items int
+	// Attributes is the number of attributes per item.
+	Attributes int
+	// Cardinality bounds the number of distinct values used for
+	// each attribute key.  Zero means unbounded.
+	Cardinality int
+}
+
+func (s Shape) normalize() Shape {
+	atLeastOne := func(v int) int {
+		if v <= 0 {
+			return 1
+		}
+		return v
+	}
+	s.Resources = atLeastOne(s.Resources)
+	s.Scopes = atLeastOne(s.Scopes)
+	s.Items = atLeastOne(s.Items)
+	if s.Attributes < 0 {
+		s.Attributes = 0
+	}
+	return s
+}
+
+// Generator produces pseudo-random traces, metrics, and logs from a
+// fixed seed.  Two generators constructed with the same seed and
+// shape return identical sequences of batches, independent of the
+// machine or the wall clock.  A Generator is not safe for concurrent
+// use.
+type Generator struct {
+	rng   *rand.Rand
+	shape Shape
+	clock time.Time
+}
+
+// NewGenerator returns a Generator seeded with seed.
+func NewGenerator(seed int64, shape Shape) *Generator {
+	return &Generator{
+		rng:   rand.New(rand.NewSource(seed)), //nolint:gosec // deterministic test data
+		shape: shape.normalize(),
+		clock: randomBaseTimestamp,
+	}
+}
+
+// Shape returns the shape of generated data.
+func (g *Generator) Shape() Shape {
+	return g.shape
+}
+
+// tick advances the generator's clock by a random interval and
+// returns the new time.
+func (g *Generator) tick() pcommon.Timestamp {
+	g.clock = g.clock.Add(time.Duration(1+g.rng.Intn(1000)) * time.Microsecond)
+	return pcommon.NewTimestampFromTime(g.clock)
+}
+
+func (g *Generator) value(key int) string {
+	if g.shape.Cardinality > 0 {
+		return fmt.Sprintf("value-%d-%d", key, g.rng.Intn(g.shape.Cardinality))
+	}
+	return fmt.Sprintf("value-%d-%d", key, g.rng.Int63())
+}
+
+func (g *Generator) fillAttributes(dest pcommon.Map) {
+	dest.EnsureCapacity(g.shape.Attributes)
+	for i := 0; i < g.shape.Attributes; i++ {
+		key := fmt.Sprintf("attr-%d", i)
+		switch g.rng.Intn(4) {
+		case 0:
+			dest.PutStr(key, g.value(i))
+		case 1:
+			dest.PutInt(key, g.rng.Int63n(1000))
+		case 2:
+			dest.PutDouble(key, g.rng.Float64())
+		case 3:
+			dest.PutBool(key, g.rng.Intn(2) == 0)
+		}
+	}
+}
+
+func (g *Generator) fillResource(r pcommon.Resource, i int) {
+	r.Attributes().PutStr("service.name", fmt.Sprintf("service-%d", i))
+	g.fillAttributes(r.Attributes())
+}
+
+func (g *Generator) fillScope(s pcommon.InstrumentationScope, i int) {
+	s.SetName(fmt.Sprintf("scope-%d", i))
+	s.SetVersion("1.0.0")
+}
+
+func (g *Generator) traceID() (id pcommon.TraceID) {
+	_, _ = g.rng.Read(id[:])
+	return id
+}
+
+func (g *Generator) spanID() (id pcommon.SpanID) {
+	_, _ = g.rng.Read(id[:])
+	return id
+}
+
+// Traces returns the next batch of spans.
+func (g *Generator) Traces() ptrace.Traces {
+	td := ptrace.NewTraces()
+	rss := td.ResourceSpans()
+	rss.EnsureCapacity(g.shape.Resources)
+	for r := 0; r < g.shape.Resources; r++ {
+		rs := rss.AppendEmpty()
+		g.fillResource(rs.Resource(), r)
+		for s := 0; s < g.shape.Scopes; s++ {
+			ss := rs.ScopeSpans().AppendEmpty()
+			g.fillScope(ss.Scope(), s)
+			spans := ss.Spans()
+			spans.EnsureCapacity(g.shape.Items)
+			for i := 0; i < g.shape.Items; i++ {
+				g.fillSpan(spans.AppendEmpty())
+			}
+		}
+	}
+	return td
+}
+
+func (g *Generator) fillSpan(span ptrace.Span) {
+	span.SetName(fmt.Sprintf("operation-%d", g.rng.Intn(10)))
+	span.SetTraceID(g.traceID())
+	span.SetSpanID(g.spanID())
+	span.SetKind(ptrace.SpanKind(g.rng.Intn(6)))
+	span.SetStartTimestamp(g.tick())
+	span.SetEndTimestamp(g.tick())
+	g.fillAttributes(span.Attributes())
+	if g.rng.Intn(4) == 0 {
+		ev := span.Events().AppendEmpty()
+		ev.SetName("event")
+		ev.SetTimestamp(span.EndTimestamp())
+	}
+	if g.rng.Intn(10) == 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+		span.Status().SetMessage("error")
+	}
+}
+
+// Metrics returns the next batch of metrics, cycling through gauge,
+// sum, and histogram points.
+func (g *Generator) Metrics() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rms := md.ResourceMetrics()
+	rms.EnsureCapacity(g.shape.Resources)
+	for r := 0; r < g.shape.Resources; r++ {
+		rm := rms.AppendEmpty()
+		g.fillResource(rm.Resource(), r)
+		for s := 0; s < g.shape.Scopes; s++ {
+			sm := rm.ScopeMetrics().AppendEmpty()
+			g.fillScope(sm.Scope(), s)
+			ms := sm.Metrics()
+			ms.EnsureCapacity(g.shape.Items)
+			for i := 0; i < g.shape.Items; i++ {
+				g.fillMetric(ms.AppendEmpty(), i)
+			}
+		}
+	}
+	return md
+}
+
+func (g *Generator) fillMetric(m pmetric.Metric, i int) {
+	start := pcommon.NewTimestampFromTime(randomBaseTimestamp)
+	switch i % 3 {
+	case 0:
+		m.SetName(fmt.Sprintf("gauge-%d", i))
+		dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(g.tick())
+		dp.SetDoubleValue(g.rng.Float64() * 100)
+		g.fillAttributes(dp.Attributes())
+	case 1:
+		m.SetName(fmt.Sprintf("sum-%d", i))
+		sum := m.SetEmptySum()
+		sum.SetIsMonotonic(true)
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		dp := sum.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(start)
+		dp.SetTimestamp(g.tick())
+		dp.SetIntValue(g.rng.Int63n(1 << 20))
+		g.fillAttributes(dp.Attributes())
+	case 2:
+		m.SetName(fmt.Sprintf("histogram-%d", i))
+		hist := m.SetEmptyHistogram()
+		hist.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		dp := hist.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(start)
+		dp.SetTimestamp(g.tick())
+		dp.ExplicitBounds().FromRaw([]float64{1, 10, 100})
+		var count uint64
+		var total float64
+		for b := 0; b < 4; b++ {
+			n := uint64(g.rng.Intn(100))
+			dp.BucketCounts().Append(n)
+			count += n
+			total += float64(n) * g.rng.Float64() * 100
+		}
+		dp.SetCount(count)
+		dp.SetSum(total)
+		g.fillAttributes(dp.Attributes())
+	}
+}
+
+// Logs returns the next batch of log records.
+func (g *Generator) Logs() plog.Logs {
+	ld := plog.NewLogs()
+	rls := ld.ResourceLogs()
+	rls.EnsureCapacity(g.shape.Resources)
+	for r := 0; r < g.shape.Resources; r++ {
+		rl := rls.AppendEmpty()
+		g.fillResource(rl.Resource(), r)
+		for s := 0; s < g.shape.Scopes; s++ {
+			sl := rl.ScopeLogs().AppendEmpty()
+			g.fillScope(sl.Scope(), s)
+			lrs := sl.LogRecords()
+			lrs.EnsureCapacity(g.shape.Items)
+			for i := 0; i < g.shape.Items; i++ {
+				g.fillLog(lrs.AppendEmpty())
+			}
+		}
+	}
+	return ld
+}
+
+func (g *Generator) fillLog(lr plog.LogRecord) {
+	lr.SetTimestamp(g.tick())
+	lr.SetObservedTimestamp(lr.Timestamp())
+	sev := plog.SeverityNumber(1 + g.rng.Intn(24))
+	lr.SetSeverityNumber(sev)
+	lr.SetSeverityText(sev.String())
+	if g.rng.Intn(2) == 0 {
+		lr.SetTraceID(g.traceID())
+		lr.SetSpanID(g.spanID())
+	}
+	lr.Body().SetStr(fmt.Sprintf("log message %d", g.rng.Intn(1000)))
+	g.fillAttributes(lr.Attributes())
+}
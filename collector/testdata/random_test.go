@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestGeneratorDeterministic(t *testing.T) {
+	shape := Shape{Resources: 2, Scopes: 2, Items: 5, Attributes: 3}
+
+	var tm ptrace.ProtoMarshaler
+	var mm pmetric.ProtoMarshaler
+	var lm plog.ProtoMarshaler
+
+	a := NewGenerator(42, shape)
+	b := NewGenerator(42, shape)
+	c := NewGenerator(43, shape)
+
+	for i := 0; i < 3; i++ {
+		at, err := tm.MarshalTraces(a.Traces())
+		require.NoError(t, err)
+		bt, err := tm.MarshalTraces(b.Traces())
+		require.NoError(t, err)
+		ct, err := tm.MarshalTraces(c.Traces())
+		require.NoError(t, err)
+		assert.Equal(t, at, bt)
+		assert.NotEqual(t, at, ct)
+
+		am, err := mm.MarshalMetrics(a.Metrics())
+		require.NoError(t, err)
+		bm, err := mm.MarshalMetrics(b.Metrics())
+		require.NoError(t, err)
+		assert.Equal(t, am, bm)
+
+		al, err := lm.MarshalLogs(a.Logs())
+		require.NoError(t, err)
+		bl, err := lm.MarshalLogs(b.Logs())
+		require.NoError(t, err)
+		assert.Equal(t, al, bl)
+	}
+}
+
+func TestGeneratorShape(t *testing.T) {
+	g := NewGenerator(1, Shape{Resources: 3, Scopes: 2, Items: 4, Attributes: 2, Cardinality: 1})
+
+	assert.Equal(t, 3*2*4, g.Traces().SpanCount())
+	assert.Equal(t, 3*2*4, g.Metrics().MetricCount())
+	assert.Equal(t, 3*2*4, g.Logs().LogRecordCount())
+
+	// Zero-valued shapes still produce one item.
+	g = NewGenerator(1, Shape{})
+	assert.Equal(t, 1, g.Traces().SpanCount())
+	assert.Equal(t, 0, g.Traces().ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Attributes().Len())
+}
@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package arrowmock provides gomock implementations of the Arrow
+// stream interfaces, so that components built on the OTel-Arrow
+// exporter or receiver can be unit tested without a gRPC server.
+package arrowmock // import "github.com/open-telemetry/otel-arrow/collector/testutil/arrowmock"
+
+import (
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"google.golang.org/grpc"
+)
+
+// AnyStreamClient is the client side of any Arrow stream, regardless
+// of signal.  It is satisfied by each of the generated
+// ArrowTracesService_ArrowTracesClient, ArrowLogsService_ArrowLogsClient,
+// and ArrowMetricsService_ArrowMetricsClient interfaces.
+type AnyStreamClient interface {
+	Send(*arrowpb.BatchArrowRecords) error
+	Recv() (*arrowpb.BatchStatus, error)
+	grpc.ClientStream
+}
+
+// AnyStreamServer is the server side of any Arrow stream, regardless
+// of signal.
+type AnyStreamServer interface {
+	Send(*arrowpb.BatchStatus) error
+	Recv() (*arrowpb.BatchArrowRecords, error)
+	grpc.ServerStream
+}
+
+var (
+	_ AnyStreamClient = arrowpb.ArrowTracesService_ArrowTracesClient(nil)
+	_ AnyStreamClient = arrowpb.ArrowLogsService_ArrowLogsClient(nil)
+	_ AnyStreamClient = arrowpb.ArrowMetricsService_ArrowMetricsClient(nil)
+	_ AnyStreamServer = arrowpb.ArrowTracesService_ArrowTracesServer(nil)
+	_ AnyStreamServer = arrowpb.ArrowLogsService_ArrowLogsServer(nil)
+	_ AnyStreamServer = arrowpb.ArrowMetricsService_ArrowMetricsServer(nil)
+)
@@ -0,0 +1,290 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: streams.go
+//
+// Generated by this command:
+//
+//	mockgen -source streams.go -package arrowmock -destination streams_mock.go
+//
+
+// Package arrowmock is a generated GoMock package.
+package arrowmock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	v1 "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	gomock "go.uber.org/mock/gomock"
+	metadata "google.golang.org/grpc/metadata"
+)
+
+// MockAnyStreamClient is a mock of AnyStreamClient interface.
+type MockAnyStreamClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnyStreamClientMockRecorder
+}
+
+// MockAnyStreamClientMockRecorder is the mock recorder for MockAnyStreamClient.
+type MockAnyStreamClientMockRecorder struct {
+	mock *MockAnyStreamClient
+}
+
+// NewMockAnyStreamClient creates a new mock instance.
+func NewMockAnyStreamClient(ctrl *gomock.Controller) *MockAnyStreamClient {
+	mock := &MockAnyStreamClient{ctrl: ctrl}
+	mock.recorder = &MockAnyStreamClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnyStreamClient) EXPECT() *MockAnyStreamClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockAnyStreamClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockAnyStreamClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockAnyStreamClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockAnyStreamClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAnyStreamClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAnyStreamClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockAnyStreamClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockAnyStreamClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockAnyStreamClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockAnyStreamClient) Recv() (*v1.BatchStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*v1.BatchStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockAnyStreamClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAnyStreamClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAnyStreamClient) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAnyStreamClientMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAnyStreamClient)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockAnyStreamClient) Send(arg0 *v1.BatchArrowRecords) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockAnyStreamClientMockRecorder) Send(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAnyStreamClient)(nil).Send), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAnyStreamClient) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAnyStreamClientMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAnyStreamClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockAnyStreamClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockAnyStreamClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockAnyStreamClient)(nil).Trailer))
+}
+
+// MockAnyStreamServer is a mock of AnyStreamServer interface.
+type MockAnyStreamServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnyStreamServerMockRecorder
+}
+
+// MockAnyStreamServerMockRecorder is the mock recorder for MockAnyStreamServer.
+type MockAnyStreamServerMockRecorder struct {
+	mock *MockAnyStreamServer
+}
+
+// NewMockAnyStreamServer creates a new mock instance.
+func NewMockAnyStreamServer(ctrl *gomock.Controller) *MockAnyStreamServer {
+	mock := &MockAnyStreamServer{ctrl: ctrl}
+	mock.recorder = &MockAnyStreamServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnyStreamServer) EXPECT() *MockAnyStreamServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockAnyStreamServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAnyStreamServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAnyStreamServer)(nil).Context))
+}
+
+// Recv mocks base method.
+func (m *MockAnyStreamServer) Recv() (*v1.BatchArrowRecords, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*v1.BatchArrowRecords)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockAnyStreamServerMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAnyStreamServer)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAnyStreamServer) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAnyStreamServerMockRecorder) RecvMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAnyStreamServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockAnyStreamServer) Send(arg0 *v1.BatchStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockAnyStreamServerMockRecorder) Send(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAnyStreamServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockAnyStreamServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockAnyStreamServerMockRecorder) SendHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockAnyStreamServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAnyStreamServer) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAnyStreamServerMockRecorder) SendMsg(m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAnyStreamServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockAnyStreamServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockAnyStreamServerMockRecorder) SetHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockAnyStreamServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockAnyStreamServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockAnyStreamServerMockRecorder) SetTrailer(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockAnyStreamServer)(nil).SetTrailer), arg0)
+}
@@ -9,6 +9,7 @@ require (
 	github.com/apache/arrow/go/v14 v14.0.2
 	github.com/axiomhq/hyperloglog v0.0.0-20230201085229-3ddf4bad03dc
 	github.com/brianvoe/gofakeit/v6 v6.17.0
+	github.com/cespare/xxhash/v2 v2.2.0
 	github.com/dustin/go-humanize v1.0.1
 	github.com/fxamacker/cbor/v2 v2.4.0
 	github.com/klauspost/compress v1.17.8
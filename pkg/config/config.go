@@ -113,9 +113,43 @@ type Config struct {
 	// it means that the dictionary entries are reused more often.
 	DictResetThreshold float64
 
+	// NoDictionaryDeltas makes the IPC writers resend a whole
+	// dictionary when new entries are added to it, instead of a
+	// delta holding only the new entries.
+	NoDictionaryDeltas bool
+
 	// Zstd enables the use of ZSTD compression for IPC messages.
 	Zstd bool // Use IPC ZSTD compression
 
+	// PayloadZstd replaces the IPC-level compression enabled by
+	// Zstd: each payload is compressed as a whole, by an encoder
+	// shared by producers with identical settings and reused
+	// across batches.  Consumers of earlier releases cannot
+	// decode these payloads.
+	PayloadZstd bool
+
+	// ZstdDictionary is an optional pre-trained Zstd dictionary
+	// (see package zstddict).  When set, each payload is
+	// compressed as a whole using the dictionary instead of
+	// using IPC-level compression.
+	ZstdDictionary []byte
+
+	// ZstdConcurrency is the number of payloads that the Zstd
+	// encoder shared by producers with identical settings
+	// compresses at once, zero for GOMAXPROCS.  This and the
+	// following settings apply to payload compression, see
+	// PayloadZstd and ZstdDictionary.
+	ZstdConcurrency int
+	// ZstdWindowSize is the window size of the Zstd encoder in
+	// bytes, a power of two, zero for the default of the
+	// compression level.  Consumers must accept the window size.
+	ZstdWindowSize uint64
+	// ZstdLevel is the Zstd compression level of the encoder,
+	// from 1 to 22, zero for the library default.  Levels map to
+	// the four speeds of the encoder, see
+	// zstd.EncoderLevelFromZstd.
+	ZstdLevel int
+
 	// SchemaStats enables the collection of statistics about Arrow schemas.
 	SchemaStats bool
 	// RecordStats enables the collection of statistics about Arrow records.
@@ -141,6 +175,100 @@ type Config struct {
 
 	// Observer is the optional observer to use for the producer.
 	Observer observer.ProducerObserver
+
+	// MemoryPressure is the optional source of memory-pressure
+	// signals.  When signaled, the producer resets its builders
+	// and dictionaries before encoding the next batch.
+	MemoryPressure MemoryPressureNotifier
+
+	// BufferRetention is the maximum number of bytes of Arrow
+	// buffers, freed when the records of a batch are released,
+	// that the producer retains for the builders of the next
+	// batches.  Zero disables the reuse of buffers.
+	BufferRetention uint64
+
+	// MaxChunkItems is the maximum number of spans, log records, or
+	// metric data points converted to Arrow records at once.
+	// Larger batches are encoded in chunks, each written to the
+	// IPC streams before the next is built, which bounds the
+	// memory of the builders.  Consumers must accept several
+	// chunks per batch.  Zero disables chunking.  The stages of a
+	// pipelined encoding do not chunk.
+	MaxChunkItems int
+
+	// SchemaCacheSize is the number of schemas per payload type
+	// whose IPC streams the producer keeps open.  Records are
+	// written to the stream of their schema's structure, so that
+	// batches alternating between a few shapes do not reset the
+	// streams at each change.  Consumers must keep the same number
+	// of streams open, see arrow_record.MaxSchemaCacheSize.  Zero
+	// or one keeps only the latest schema.
+	SchemaCacheSize int
+
+	// TrimAfterBatches is the number of consecutive batches,
+	// each encoded in less than half the size of the largest batch
+	// since the previous trim, after which the producer releases
+	// the capacity retained by its builders and buffers.  This
+	// keeps a single large batch from inflating the memory of the
+	// producer for its lifetime.  Like a memory-pressure reset, a
+	// trim starts new IPC streams.  Zero disables trimming.
+	TrimAfterBatches int
+
+	// MinCompressionRatio is the ratio of uncompressed to
+	// compressed size below which the producer stops compressing
+	// the payloads of a stream, when their data is poorly
+	// compressible, e.g., attributes holding compressed data.
+	// The stream is probed again after a number of uncompressed
+	// payloads.  Consumers recognize uncompressed payloads, so no
+	// coordination is needed.  This applies only to payload
+	// compression.  Zero disables the adaptation.
+	MinCompressionRatio float64
+
+	// SchemaChurnThreshold is the number of schema changes of a
+	// payload type, within the recent batches of a producer, at
+	// which the producer reports schema churn to
+	// SchemaChurnReporter.  Each schema change starts a new IPC
+	// stream, which resends the schema and the dictionaries, so
+	// frequent changes hurt compression.  Zero disables the
+	// detection.
+	SchemaChurnThreshold int
+
+	// SchemaChurnReporter receives the reports of schema churn,
+	// may be nil.
+	SchemaChurnReporter SchemaChurnReporter
+}
+
+// SchemaChurnReporter is implemented by the receivers of schema churn
+// reports, e.g., a logger.  ReportSchemaChurn is called by the
+// goroutine encoding the batch that reached the threshold.
+type SchemaChurnReporter interface {
+	ReportSchemaChurn(SchemaChurn)
+}
+
+// SchemaChurn describes a payload type whose schema changed
+// frequently.
+type SchemaChurn struct {
+	// PayloadType is the payload type, e.g., "SPAN_ATTRS".
+	PayloadType string
+	// Changes is the number of schema changes within Batches
+	// batches.
+	Changes int
+	Batches int
+	// UnstableKeys are the attribute keys seen with more than one
+	// value type in the records that changed the schema, sorted.
+	// A key whose type varies, e.g., an integer sometimes recorded
+	// as a string, adds columns to the schema each time the
+	// builders restart.  Only set for attribute payload types.
+	UnstableKeys []string
+}
+
+// MemoryPressureNotifier is implemented by sources of memory-pressure
+// signals, e.g., a collector memory limiter or a runtime/metrics
+// poller.  Register arranges for fn to be called, from any goroutine,
+// each time memory pressure is high; the returned function
+// unregisters fn.
+type MemoryPressureNotifier interface {
+	Register(fn func()) (unregister func())
 }
 
 type Option func(*Config)
@@ -151,6 +279,7 @@ type Option func(*Config)
 //   - LimitIndexSize: math.MaxUint32
 //   - SchemaStats: false
 //   - Zstd: true
+//   - BufferRetention: 32MiB
 func DefaultConfig() *Config {
 	return &Config{
 		Pool: memory.NewGoAllocator(),
@@ -170,6 +299,8 @@ func DefaultConfig() *Config {
 		OrderSpanBy:    OrderSpanByNameTraceID,
 		OrderAttrs16By: OrderAttrs16ByTypeKeyValueParentId,
 		OrderAttrs32By: OrderAttrs32ByTypeKeyValueParentId,
+
+		BufferRetention: 32 << 20,
 	}
 }
 
@@ -251,6 +382,16 @@ func WithZstd() Option {
 	}
 }
 
+// WithPayloadZstd sets the Producer to compress each payload as a
+// whole, instead of compressing at the Arrow IPC level, when Zstd
+// compression is enabled.  The consumer must be of the same release
+// or later.
+func WithPayloadZstd() Option {
+	return func(cfg *Config) {
+		cfg.PayloadZstd = true
+	}
+}
+
 // WithNoZstd sets the Producer to not use Zstd compression at the Arrow IPC level.
 func WithNoZstd() Option {
 	return func(cfg *Config) {
@@ -258,6 +399,41 @@ func WithNoZstd() Option {
 	}
 }
 
+// WithZstdDictionary sets the Producer to compress each payload
+// using a pre-trained Zstd dictionary, replacing Zstd compression at
+// the Arrow IPC level.  Consumers need the same dictionary.
+func WithZstdDictionary(dict []byte) Option {
+	return func(cfg *Config) {
+		cfg.ZstdDictionary = dict
+	}
+}
+
+// WithZstdConcurrency sets the number of payloads that the Zstd
+// encoder of the Producer, shared with other producers with
+// identical settings, compresses at once.  Zero means GOMAXPROCS.
+func WithZstdConcurrency(n int) Option {
+	return func(cfg *Config) {
+		cfg.ZstdConcurrency = n
+	}
+}
+
+// WithZstdWindowSize sets the window size of the Zstd encoder of the
+// Producer, a power of two between 1KiB and 512MiB.  Zero means the
+// default of the compression level.
+func WithZstdWindowSize(bytes uint64) Option {
+	return func(cfg *Config) {
+		cfg.ZstdWindowSize = bytes
+	}
+}
+
+// WithZstdLevel sets the Zstd compression level of the encoder of the
+// Producer, from 1 to 22.  Zero means the library default.
+func WithZstdLevel(level int) Option {
+	return func(cfg *Config) {
+		cfg.ZstdLevel = level
+	}
+}
+
 // WithSchemaStats enables the collection of statistics about Arrow schemas.
 func WithSchemaStats() Option {
 	return func(cfg *Config) {
@@ -343,3 +519,74 @@ func WithDictResetThreshold(dictResetThreshold float64) Option {
 		cfg.DictResetThreshold = dictResetThreshold
 	}
 }
+
+// WithMemoryPressureNotifier registers the producer with a source of
+// memory-pressure signals.
+func WithMemoryPressureNotifier(notifier MemoryPressureNotifier) Option {
+	return func(cfg *Config) {
+		cfg.MemoryPressure = notifier
+	}
+}
+
+// WithBufferRetention sets the maximum number of bytes of Arrow
+// buffers retained for reuse across batches.  Zero disables the
+// reuse of buffers.
+func WithBufferRetention(bytes uint64) Option {
+	return func(cfg *Config) {
+		cfg.BufferRetention = bytes
+	}
+}
+
+// WithMaxChunkItems sets the maximum number of items of a batch that
+// the Producer converts to Arrow records at once.  Larger batches are
+// encoded in chunks.  Zero disables chunking.
+func WithMaxChunkItems(items int) Option {
+	return func(cfg *Config) {
+		cfg.MaxChunkItems = items
+	}
+}
+
+// WithSchemaCacheSize sets the number of schemas per payload type
+// whose IPC streams the Producer keeps open.
+func WithSchemaCacheSize(size int) Option {
+	return func(cfg *Config) {
+		cfg.SchemaCacheSize = size
+	}
+}
+
+// WithTrimAfterBatches sets the number of consecutive batches below
+// half of the high-water mark after which the Producer releases the
+// capacity retained by its builders and buffers.  Zero disables
+// trimming.
+func WithTrimAfterBatches(batches int) Option {
+	return func(cfg *Config) {
+		cfg.TrimAfterBatches = batches
+	}
+}
+
+// WithNoDictionaryDeltas sets the Producer to send replacement
+// dictionaries instead of dictionary deltas.
+func WithNoDictionaryDeltas() Option {
+	return func(cfg *Config) {
+		cfg.NoDictionaryDeltas = true
+	}
+}
+
+// WithMinCompressionRatio sets the compression ratio below which
+// the Producer sends the payloads of a stream uncompressed.  Zero
+// disables the adaptation.
+func WithMinCompressionRatio(ratio float64) Option {
+	return func(cfg *Config) {
+		cfg.MinCompressionRatio = ratio
+	}
+}
+
+// WithSchemaChurnDetection reports to reporter the payload types
+// whose schema changed threshold times within the recent batches of
+// the Producer.  Zero disables the detection.
+func WithSchemaChurnDetection(threshold int, reporter SchemaChurnReporter) Option {
+	return func(cfg *Config) {
+		cfg.SchemaChurnThreshold = threshold
+		cfg.SchemaChurnReporter = reporter
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memorypressure distributes memory-pressure signals to
+// registered callbacks, e.g., to Arrow producers configured with
+// config.WithMemoryPressureNotifier().
+package memorypressure
+
+import (
+	"context"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+)
+
+// heapObjectsMetric is the runtime/metrics name of the memory
+// occupied by live and not-yet-swept heap objects.
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// Notifier calls every registered callback each time Notify is
+// called.  A Notifier is safe for concurrent use.
+type Notifier struct {
+	lock      sync.Mutex
+	nextID    uint64
+	callbacks map[uint64]func()
+}
+
+var _ config.MemoryPressureNotifier = &Notifier{}
+
+// NewNotifier returns a Notifier without callbacks.
+func NewNotifier() *Notifier {
+	return &Notifier{
+		callbacks: map[uint64]func(){},
+	}
+}
+
+// Register adds fn to the set of callbacks.  The returned function
+// removes it.
+func (n *Notifier) Register(fn func()) (unregister func()) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	id := n.nextID
+	n.nextID++
+	n.callbacks[id] = fn
+
+	return func() {
+		n.lock.Lock()
+		defer n.lock.Unlock()
+		delete(n.callbacks, id)
+	}
+}
+
+// Notify signals memory pressure to every registered callback.
+// Callbacks are expected to return quickly.
+func (n *Notifier) Notify() {
+	n.lock.Lock()
+	fns := make([]func(), 0, len(n.callbacks))
+	for _, fn := range n.callbacks {
+		fns = append(fns, fn)
+	}
+	n.lock.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// Watch samples the Go runtime's heap size every interval and calls
+// Notify while it exceeds limitBytes.  Watch returns when ctx is
+// done.
+func (n *Notifier) Watch(ctx context.Context, interval time.Duration, limitBytes uint64) {
+	samples := []metrics.Sample{{Name: heapObjectsMetric}}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		metrics.Read(samples)
+		if samples[0].Value.Kind() != metrics.KindUint64 {
+			// The metric is not supported by this runtime.
+			return
+		}
+		if samples[0].Value.Uint64() > limitBytes {
+			n.Notify()
+		}
+	}
+}
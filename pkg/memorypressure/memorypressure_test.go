@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorypressure
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifierRegister(t *testing.T) {
+	n := NewNotifier()
+
+	var a, b atomic.Int64
+	unregA := n.Register(func() { a.Add(1) })
+	unregB := n.Register(func() { b.Add(1) })
+
+	n.Notify()
+	require.Equal(t, int64(1), a.Load())
+	require.Equal(t, int64(1), b.Load())
+
+	unregA()
+	n.Notify()
+	require.Equal(t, int64(1), a.Load())
+	require.Equal(t, int64(2), b.Load())
+
+	unregB()
+	n.Notify()
+	require.Equal(t, int64(2), b.Load())
+}
+
+func TestNotifierWatch(t *testing.T) {
+	n := NewNotifier()
+	called := make(chan struct{}, 1)
+	n.Register(func() {
+		select {
+		case called <- struct{}{}:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Any heap exceeds a zero-byte limit.
+		n.Watch(ctx, time.Millisecond, 0)
+	}()
+
+	select {
+	case <-called:
+	case <-time.After(10 * time.Second):
+		t.Fatal("no memory-pressure notification")
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/record_message"
+)
+
+// forEachBuffer calls f for each non-empty buffer of data and its
+// children.
+func forEachBuffer(data arrow.ArrayData, f func(*memory.Buffer)) {
+	for _, buf := range data.Buffers() {
+		if buf != nil && buf.Len() != 0 {
+			f(buf)
+		}
+	}
+	for _, child := range data.Children() {
+		forEachBuffer(child, f)
+	}
+}
+
+func TestConsumerAlignedBuffers(t *testing.T) {
+	const alignment = 256
+
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	producer := NewProducerWithOptions(config.WithAllocator(pool))
+	consumer := NewConsumer(WithAlignedBuffers(alignment))
+
+	for row := 0; row < 3; row++ {
+		batch, err := producer.Produce([]*record_message.RecordMessage{shapeRecord(pool, 1, row)})
+		require.NoError(t, err)
+		records, err := consumer.Consume(batch)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+
+		buffers := 0
+		for _, col := range records[0].Record().Columns() {
+			forEachBuffer(col.Data(), func(buf *memory.Buffer) {
+				buffers++
+				require.Zero(t, uintptr(unsafe.Pointer(&buf.Bytes()[0]))%alignment)
+				require.Zero(t, cap(buf.Buf())%alignment)
+			})
+		}
+		require.NotZero(t, buffers)
+		releaseRecords(records)
+	}
+
+	require.NoError(t, producer.Close())
+	require.NoError(t, consumer.Close())
+	require.Zero(t, consumer.allocator.Inuse())
+}
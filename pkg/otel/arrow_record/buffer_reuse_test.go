@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+)
+
+// countingAllocator counts the bytes allocated by the producer.
+type countingAllocator struct {
+	memory.Allocator
+	allocated int
+}
+
+func (c *countingAllocator) Allocate(size int) []byte {
+	c.allocated += size
+	return c.Allocator.Allocate(size)
+}
+
+func (c *countingAllocator) Reallocate(size int, b []byte) []byte {
+	if size > len(b) {
+		c.allocated += size - len(b)
+	}
+	return c.Allocator.Reallocate(size, b)
+}
+
+// TestProducerBufferReuse verifies that a producer retaining buffers
+// produces the same batches as one that does not, while allocating
+// less once the first batches have been released.
+func TestProducerBufferReuse(t *testing.T) {
+	ent := datagen.NewTestEntropy(12345)
+	dg := datagen.NewTracesGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	)
+
+	const numBatches = 10
+	var inputs []ptrace.Traces
+	for i := 0; i < numBatches; i++ {
+		inputs = append(inputs, dg.Generate(100, time.Minute))
+	}
+
+	checked := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer checked.AssertSize(t, 0)
+
+	noReuseAlloc := &countingAllocator{Allocator: checked}
+	noReuse := NewProducerWithOptions(config.WithAllocator(noReuseAlloc), config.WithBufferRetention(0))
+	reuseAlloc := &countingAllocator{Allocator: checked}
+	reuse := NewProducerWithOptions(config.WithAllocator(reuseAlloc))
+
+	for i, traces := range inputs {
+		if i == 1 {
+			// Compare the steady state.
+			noReuseAlloc.allocated = 0
+			reuseAlloc.allocated = 0
+		}
+		expect, err := noReuse.BatchArrowRecordsFromTraces(traces)
+		require.NoError(t, err)
+		batch, err := reuse.BatchArrowRecordsFromTraces(traces)
+		require.NoError(t, err)
+		require.True(t, proto.Equal(expect, batch))
+	}
+	require.Less(t, reuseAlloc.allocated, noReuseAlloc.allocated/2)
+
+	require.NoError(t, noReuse.Close())
+	require.NoError(t, reuse.Close())
+}
@@ -0,0 +1,122 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+)
+
+// TestProducerCapacityTrim verifies that the producer trims its
+// capacity after a number of batches much smaller than a previous
+// large batch, and that the consumer decodes the new streams that
+// follow.
+func TestProducerCapacityTrim(t *testing.T) {
+	ent := datagen.NewTestEntropy(12345)
+	dg := datagen.NewTracesGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	)
+	stdTesting := assert.NewStdUnitTest(t)
+
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	producer := NewProducerWithOptions(config.WithAllocator(pool), config.WithTrimAfterBatches(3))
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	consumer := NewConsumer()
+	defer func() {
+		require.NoError(t, consumer.Close())
+	}()
+
+	var afterLarge uint64
+	schemaIDs := map[string]bool{}
+	for i := 0; i < 6; i++ {
+		count := 10
+		if i == 0 {
+			count = 2000
+		}
+		traces := dg.Generate(count, time.Minute)
+		batch, err := producer.BatchArrowRecordsFromTraces(traces)
+		require.NoError(t, err)
+
+		newIDs := 0
+		for _, payload := range batch.ArrowPayloads {
+			if !schemaIDs[payload.SchemaId] {
+				newIDs++
+				schemaIDs[payload.SchemaId] = true
+			}
+		}
+
+		received, err := consumer.TracesFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(received))
+		assert.Equiv(
+			stdTesting,
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(traces)},
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(received[0])},
+		)
+
+		switch i {
+		case 0:
+			afterLarge = producer.recycler.Retained()
+		case 4:
+			// Three small batches were encoded before this one.
+			require.Equal(t, len(batch.ArrowPayloads), newIDs, "trimmed batch uses new streams")
+			require.Less(t, producer.recycler.Retained(), afterLarge)
+		}
+	}
+	require.Equal(t, uint64(1), producer.GetAndResetStats().CapacityTrims)
+}
+
+func TestProducerTrackHighWater(t *testing.T) {
+	producer := NewProducerWithOptions(config.WithTrimAfterBatches(2))
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+
+	producer.trackHighWater(1000)
+	producer.trackHighWater(400)
+	// A batch above half of the high-water mark restarts the count.
+	producer.trackHighWater(600)
+	producer.trackHighWater(400)
+	require.False(t, producer.trimPending.Load())
+	producer.trackHighWater(400)
+	require.True(t, producer.trimPending.Load())
+	require.Equal(t, 0, producer.highWater)
+
+	// Trimming is disabled by default.
+	disabled := NewProducer()
+	defer func() {
+		require.NoError(t, disabled.Close())
+	}()
+	disabled.trackHighWater(1000)
+	for i := 0; i < 10; i++ {
+		disabled.trackHighWater(1)
+	}
+	require.False(t, disabled.trimPending.Load())
+}
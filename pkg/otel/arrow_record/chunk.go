@@ -0,0 +1,351 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/pkg/record_message"
+	"github.com/open-telemetry/otel-arrow/pkg/werror"
+)
+
+// This file implements the chunked encoding of large batches.  When
+// a batch has more than MaxChunkItems items, the producer copies at
+// most MaxChunkItems items at a time into a chunk, and builds and
+// writes the Arrow records of each chunk before building the next,
+// so that the builders hold the columns of one chunk at a time.  The
+// payloads of all chunks form one BatchArrowRecords, in which each
+// chunk starts with its main record, and the consumer decodes each
+// chunk separately before merging them.
+
+// chunkItems returns the number of items of a batch, as counted by
+// MaxChunkItems.
+func chunkItems(records any) int {
+	switch data := records.(type) {
+	case ptrace.Traces:
+		return data.SpanCount()
+	case plog.Logs:
+		return data.LogRecordCount()
+	case pmetric.Metrics:
+		return data.DataPointCount()
+	}
+	return 0
+}
+
+// batchChunks encodes a batch in chunks of at most MaxChunkItems
+// items.  On error, the stream producers are closed, since the
+// consumer will not see the chunks already written.
+func (p *Producer) batchChunks(records any) (*colarspb.BatchArrowRecords, error) {
+	var payloads []*colarspb.ArrowPayload
+	var produced *uint64
+
+	err := forEachChunk(records, p.conf.MaxChunkItems, func(chunk any) error {
+		built, err := p.BuildRecords(chunk)
+		if err != nil {
+			return err
+		}
+		if built.resetStreams {
+			if err := p.closeStreamProducers(); err != nil {
+				built.Release()
+				return werror.Wrap(err)
+			}
+		}
+		chunkPayloads, err := p.produce(built.rms)
+		built.rms = nil
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, chunkPayloads...)
+		produced = built.produced
+		return nil
+	})
+	if err != nil {
+		_ = p.closeStreamProducers()
+		return nil, werror.Wrap(err)
+	}
+
+	batchId := p.batchId
+	p.batchId++
+	*produced++
+
+	return &colarspb.BatchArrowRecords{
+		BatchId:         batchId,
+		ArrowPayloads:   payloads,
+		ClosedSchemaIds: p.takeClosedSchemaIDs(),
+	}, nil
+}
+
+// forEachChunk calls fn with copies of consecutive parts of a batch,
+// each with at most maxItems items, except that the data points of a
+// metric are not split.  Resources and scopes are repeated in each
+// chunk that has some of their items.
+func forEachChunk(records any, maxItems int, fn func(chunk any) error) error {
+	switch data := records.(type) {
+	case ptrace.Traces:
+		return forEachTracesChunk(data, maxItems, func(chunk ptrace.Traces) error { return fn(chunk) })
+	case plog.Logs:
+		return forEachLogsChunk(data, maxItems, func(chunk plog.Logs) error { return fn(chunk) })
+	case pmetric.Metrics:
+		return forEachMetricsChunk(data, maxItems, func(chunk pmetric.Metrics) error { return fn(chunk) })
+	default:
+		return fmt.Errorf("unsupported OTLP type: %T", records)
+	}
+}
+
+func forEachTracesChunk(traces ptrace.Traces, maxItems int, fn func(ptrace.Traces) error) error {
+	chunk := ptrace.NewTraces()
+	items := 0
+
+	// The resources and scopes are copied into a chunk when it
+	// receives their first item, or when they have no items.
+	var chunkR ptrace.ResourceSpans
+	var chunkS ptrace.ScopeSpans
+	var haveR, haveS bool
+
+	rs := traces.ResourceSpans()
+	for i := 0; i < rs.Len(); i++ {
+		r := rs.At(i)
+		newResource := func() {
+			chunkR = chunk.ResourceSpans().AppendEmpty()
+			r.Resource().CopyTo(chunkR.Resource())
+			chunkR.SetSchemaUrl(r.SchemaUrl())
+			haveR = true
+		}
+		haveR = false
+
+		ss := r.ScopeSpans()
+		for j := 0; j < ss.Len(); j++ {
+			s := ss.At(j)
+			newScope := func() {
+				if !haveR {
+					newResource()
+				}
+				chunkS = chunkR.ScopeSpans().AppendEmpty()
+				s.Scope().CopyTo(chunkS.Scope())
+				chunkS.SetSchemaUrl(s.SchemaUrl())
+				haveS = true
+			}
+			haveS = false
+
+			spans := s.Spans()
+			for k := 0; k < spans.Len(); k++ {
+				if items == maxItems {
+					if err := fn(chunk); err != nil {
+						return err
+					}
+					chunk = ptrace.NewTraces()
+					items = 0
+					haveR, haveS = false, false
+				}
+				if !haveS {
+					newScope()
+				}
+				spans.At(k).CopyTo(chunkS.Spans().AppendEmpty())
+				items++
+			}
+			if !haveS {
+				newScope()
+			}
+		}
+		if !haveR {
+			newResource()
+		}
+	}
+	return fn(chunk)
+}
+
+func forEachLogsChunk(logs plog.Logs, maxItems int, fn func(plog.Logs) error) error {
+	chunk := plog.NewLogs()
+	items := 0
+
+	// The resources and scopes are copied into a chunk when it
+	// receives their first item, or when they have no items.
+	var chunkR plog.ResourceLogs
+	var chunkS plog.ScopeLogs
+	var haveR, haveS bool
+
+	rs := logs.ResourceLogs()
+	for i := 0; i < rs.Len(); i++ {
+		r := rs.At(i)
+		newResource := func() {
+			chunkR = chunk.ResourceLogs().AppendEmpty()
+			r.Resource().CopyTo(chunkR.Resource())
+			chunkR.SetSchemaUrl(r.SchemaUrl())
+			haveR = true
+		}
+		haveR = false
+
+		ss := r.ScopeLogs()
+		for j := 0; j < ss.Len(); j++ {
+			s := ss.At(j)
+			newScope := func() {
+				if !haveR {
+					newResource()
+				}
+				chunkS = chunkR.ScopeLogs().AppendEmpty()
+				s.Scope().CopyTo(chunkS.Scope())
+				chunkS.SetSchemaUrl(s.SchemaUrl())
+				haveS = true
+			}
+			haveS = false
+
+			logRecords := s.LogRecords()
+			for k := 0; k < logRecords.Len(); k++ {
+				if items == maxItems {
+					if err := fn(chunk); err != nil {
+						return err
+					}
+					chunk = plog.NewLogs()
+					items = 0
+					haveR, haveS = false, false
+				}
+				if !haveS {
+					newScope()
+				}
+				logRecords.At(k).CopyTo(chunkS.LogRecords().AppendEmpty())
+				items++
+			}
+			if !haveS {
+				newScope()
+			}
+		}
+		if !haveR {
+			newResource()
+		}
+	}
+	return fn(chunk)
+}
+
+func forEachMetricsChunk(metrics pmetric.Metrics, maxItems int, fn func(pmetric.Metrics) error) error {
+	chunk := pmetric.NewMetrics()
+	items := 0
+
+	// The resources and scopes are copied into a chunk when it
+	// receives their first item, or when they have no items.
+	var chunkR pmetric.ResourceMetrics
+	var chunkS pmetric.ScopeMetrics
+	var haveR, haveS bool
+
+	rs := metrics.ResourceMetrics()
+	for i := 0; i < rs.Len(); i++ {
+		r := rs.At(i)
+		newResource := func() {
+			chunkR = chunk.ResourceMetrics().AppendEmpty()
+			r.Resource().CopyTo(chunkR.Resource())
+			chunkR.SetSchemaUrl(r.SchemaUrl())
+			haveR = true
+		}
+		haveR = false
+
+		ss := r.ScopeMetrics()
+		for j := 0; j < ss.Len(); j++ {
+			s := ss.At(j)
+			newScope := func() {
+				if !haveR {
+					newResource()
+				}
+				chunkS = chunkR.ScopeMetrics().AppendEmpty()
+				s.Scope().CopyTo(chunkS.Scope())
+				chunkS.SetSchemaUrl(s.SchemaUrl())
+				haveS = true
+			}
+			haveS = false
+
+			ms := s.Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				points := metricDataPoints(ms.At(k))
+				if items != 0 && items+points > maxItems {
+					if err := fn(chunk); err != nil {
+						return err
+					}
+					chunk = pmetric.NewMetrics()
+					items = 0
+					haveR, haveS = false, false
+				}
+				if !haveS {
+					newScope()
+				}
+				ms.At(k).CopyTo(chunkS.Metrics().AppendEmpty())
+				items += points
+			}
+			if !haveS {
+				newScope()
+			}
+		}
+		if !haveR {
+			newResource()
+		}
+	}
+	return fn(chunk)
+}
+
+// metricDataPoints returns the number of data points of a metric, as
+// counted by pmetric.Metrics.DataPointCount.
+func metricDataPoints(m pmetric.Metric) int {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		return m.Gauge().DataPoints().Len()
+	case pmetric.MetricTypeSum:
+		return m.Sum().DataPoints().Len()
+	case pmetric.MetricTypeHistogram:
+		return m.Histogram().DataPoints().Len()
+	case pmetric.MetricTypeExponentialHistogram:
+		return m.ExponentialHistogram().DataPoints().Len()
+	case pmetric.MetricTypeSummary:
+		return m.Summary().DataPoints().Len()
+	}
+	return 0
+}
+
+// splitChunks splits the records of a batch into its chunks, each
+// starting with a main record of the given type.  Batches that were
+// not chunked have a single chunk, empty batches none.
+func splitChunks(records []*record_message.RecordMessage, main colarspb.ArrowPayloadType) [][]*record_message.RecordMessage {
+	if len(records) == 0 {
+		return nil
+	}
+	var chunks [][]*record_message.RecordMessage
+	start := 0
+	for i, record := range records {
+		if i != 0 && record.PayloadType() == main {
+			chunks = append(chunks, records[start:i])
+			start = i
+		}
+	}
+	return append(chunks, records[start:])
+}
+
+// retainMain retains the main record of a chunk, which is used after
+// the related data decoders release the records.  The IPC reader
+// holds the last record of a stream, but not those of the previous
+// chunks.  The returned function releases the record.
+func retainMain(chunk []*record_message.RecordMessage) func() {
+	record := chunk[0].Record()
+	record.Retain()
+	return record.Release
+}
+
+// releaseChunks releases the records of chunks that will not be
+// decoded.
+func releaseChunks(chunks [][]*record_message.RecordMessage) {
+	for _, chunk := range chunks {
+		releaseRecords(chunk)
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+)
+
+// chunkTestTraces returns traces with several resources and scopes,
+// some of which are empty.
+func chunkTestTraces() ptrace.Traces {
+	traces := ptrace.NewTraces()
+	for r := 0; r < 3; r++ {
+		rs := traces.ResourceSpans().AppendEmpty()
+		rs.Resource().Attributes().PutInt("resource", int64(r))
+		rs.SetSchemaUrl(fmt.Sprint("schema-", r))
+		for s := 0; s < 3; s++ {
+			ss := rs.ScopeSpans().AppendEmpty()
+			ss.Scope().SetName(fmt.Sprint("scope-", r, "-", s))
+			for i := 0; i < (r+1)*s*3; i++ {
+				ss.Spans().AppendEmpty().SetName(fmt.Sprint("span-", i))
+			}
+		}
+	}
+	traces.ResourceSpans().AppendEmpty().Resource().Attributes().PutStr("empty", "resource")
+	return traces
+}
+
+// flattenTraces lists the spans with their resource and scope, and
+// the empty resources and scopes, to compare traces regardless of
+// how they are grouped.
+func flattenTraces(traces ptrace.Traces) []string {
+	var flat []string
+	rss := traces.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		res := fmt.Sprint(rs.Resource().Attributes().AsRaw(), rs.SchemaUrl())
+		if rs.ScopeSpans().Len() == 0 {
+			flat = append(flat, res)
+		}
+		for j := 0; j < rs.ScopeSpans().Len(); j++ {
+			ss := rs.ScopeSpans().At(j)
+			scope := fmt.Sprint(res, ss.Scope().Name())
+			if ss.Spans().Len() == 0 {
+				flat = append(flat, scope)
+			}
+			for k := 0; k < ss.Spans().Len(); k++ {
+				flat = append(flat, fmt.Sprint(scope, ss.Spans().At(k).Name()))
+			}
+		}
+	}
+	return flat
+}
+
+func TestForEachTracesChunk(t *testing.T) {
+	traces := chunkTestTraces()
+	expect := flattenTraces(traces)
+
+	for _, maxItems := range []int{1, 5, 6, 100} {
+		t.Run(fmt.Sprint(maxItems), func(t *testing.T) {
+			merged := ptrace.NewTraces()
+			chunks := 0
+			err := forEachTracesChunk(traces, maxItems, func(chunk ptrace.Traces) error {
+				require.LessOrEqual(t, chunk.SpanCount(), maxItems)
+				chunks++
+				chunk.ResourceSpans().MoveAndAppendTo(merged.ResourceSpans())
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, (traces.SpanCount()+maxItems-1)/maxItems, chunks)
+			require.Equal(t, expect, flattenTraces(merged))
+		})
+	}
+}
+
+// TestForEachMetricsChunk verifies that the data points of a metric
+// are not split across chunks.
+func TestForEachMetricsChunk(t *testing.T) {
+	ent := datagen.NewTestEntropy(12345)
+	dg := datagen.NewMetricsGenerator(ent, ent.NewStandardResourceAttributes(), ent.NewStandardInstrumentationScopes())
+	metrics := dg.GenerateAllKindOfMetrics(10, time.Minute)
+
+	merged := pmetric.NewMetrics()
+	err := forEachMetricsChunk(metrics, 5, func(chunk pmetric.Metrics) error {
+		if chunk.MetricCount() > 1 {
+			require.LessOrEqual(t, chunk.DataPointCount(), 5)
+		}
+		chunk.ResourceMetrics().MoveAndAppendTo(merged.ResourceMetrics())
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, metrics.MetricCount(), merged.MetricCount())
+	require.Equal(t, metrics.DataPointCount(), merged.DataPointCount())
+}
+
+// TestProducerConsumerChunks verifies that batches encoded in chunks
+// are decoded into one equivalent batch, interleaved with batches
+// that are not chunked.
+func TestProducerConsumerChunks(t *testing.T) {
+	stdTesting := assert.NewStdUnitTest(t)
+	ent := datagen.NewTestEntropy(12345)
+
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	producer := NewProducerWithOptions(config.WithAllocator(pool), config.WithMaxChunkItems(50))
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	consumer := NewConsumer()
+	defer func() {
+		require.NoError(t, consumer.Close())
+	}()
+	requestConsumer := NewConsumer()
+	defer func() {
+		require.NoError(t, requestConsumer.Close())
+	}()
+
+	tg := datagen.NewTracesGenerator(ent, ent.NewStandardResourceAttributes(), ent.NewStandardInstrumentationScopes())
+	lg := datagen.NewLogsGenerator(ent, ent.NewStandardResourceAttributes(), ent.NewStandardInstrumentationScopes())
+	mg := datagen.NewMetricsGenerator(ent, ent.NewStandardResourceAttributes(), ent.NewStandardInstrumentationScopes())
+
+	for _, size := range []int{100, 5, 100} {
+		traces := tg.GenerateRandomTraces(size, time.Minute)
+		batch, err := producer.BatchArrowRecordsFromTraces(traces)
+		require.NoError(t, err)
+		require.Equal(t, (traces.SpanCount()+49)/50, countPayloads(batch, colarspb.ArrowPayloadType_SPANS))
+
+		received, err := consumer.TracesFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(received))
+		assert.Equiv(stdTesting,
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(traces)},
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(received[0])},
+		)
+
+		logs := lg.Generate(size, time.Minute)
+		batch, err = producer.BatchArrowRecordsFromLogs(logs)
+		require.NoError(t, err)
+		require.Equal(t, (logs.LogRecordCount()+49)/50, countPayloads(batch, colarspb.ArrowPayloadType_LOGS))
+
+		// Decode the same batch as pdata and as a request, on
+		// separate consumers since these keep stream state.
+		requestBatch := &colarspb.BatchArrowRecords{
+			BatchId:       batch.BatchId,
+			ArrowPayloads: append([]*colarspb.ArrowPayload(nil), batch.ArrowPayloads...),
+		}
+		receivedLogs, err := consumer.LogsFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(receivedLogs))
+		assert.Equiv(stdTesting,
+			[]json.Marshaler{plogotlp.NewExportRequestFromLogs(logs)},
+			[]json.Marshaler{plogotlp.NewExportRequestFromLogs(receivedLogs[0])},
+		)
+
+		// As in TestLogsRequestFromEquiv, compare what the
+		// receiving end would see.
+		expect := plogotlp.NewExportRequest()
+		data, err := plogotlp.NewExportRequestFromLogs(receivedLogs[0]).MarshalProto()
+		require.NoError(t, err)
+		require.NoError(t, expect.UnmarshalProto(data))
+
+		request, count, err := requestConsumer.LogsRequestFrom(requestBatch)
+		require.NoError(t, err)
+		require.Equal(t, logs.LogRecordCount(), count)
+		decoded := plogotlp.NewExportRequest()
+		require.NoError(t, decoded.UnmarshalProto(request))
+		assert.Equiv(stdTesting,
+			[]json.Marshaler{expect},
+			[]json.Marshaler{decoded},
+		)
+
+		metrics := mg.GenerateAllKindOfMetrics(size/5, time.Minute)
+		batch, err = producer.BatchArrowRecordsFromMetrics(metrics)
+		require.NoError(t, err)
+
+		receivedMetrics, err := consumer.MetricsFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(receivedMetrics))
+		assert.Equiv(stdTesting,
+			[]json.Marshaler{pmetricotlp.NewExportRequestFromMetrics(metrics)},
+			[]json.Marshaler{pmetricotlp.NewExportRequestFromMetrics(receivedMetrics[0])},
+		)
+	}
+}
+
+func countPayloads(batch *colarspb.BatchArrowRecords, payloadType colarspb.ArrowPayloadType) int {
+	count := 0
+	for _, payload := range batch.ArrowPayloads {
+		if payload.Type == payloadType {
+			count++
+		}
+	}
+	return count
+}
+
+// peakAllocator records the peak number of bytes allocated.
+type peakAllocator struct {
+	memory.Allocator
+
+	lock    sync.Mutex
+	current int
+	peak    int
+}
+
+func (a *peakAllocator) Allocate(size int) []byte {
+	a.lock.Lock()
+	a.current += size
+	a.peak = max(a.peak, a.current)
+	a.lock.Unlock()
+	return a.Allocator.Allocate(size)
+}
+
+func (a *peakAllocator) Reallocate(size int, b []byte) []byte {
+	a.lock.Lock()
+	a.current += size - len(b)
+	a.peak = max(a.peak, a.current)
+	a.lock.Unlock()
+	return a.Allocator.Reallocate(size, b)
+}
+
+func (a *peakAllocator) Free(b []byte) {
+	a.lock.Lock()
+	a.current -= len(b)
+	a.lock.Unlock()
+	a.Allocator.Free(b)
+}
+
+// TestChunksBoundMemory verifies that chunked encoding lowers the
+// peak memory of the builders.
+func TestChunksBoundMemory(t *testing.T) {
+	ent := datagen.NewTestEntropy(12345)
+	lg := datagen.NewLogsGenerator(ent, ent.NewStandardResourceAttributes(), ent.NewStandardInstrumentationScopes())
+	logs := lg.Generate(10000, time.Minute)
+
+	peak := func(opts ...config.Option) int {
+		pool := &peakAllocator{Allocator: memory.NewGoAllocator()}
+		producer := NewProducerWithOptions(append(opts, config.WithAllocator(pool), config.WithBufferRetention(0))...)
+		defer func() {
+			require.NoError(t, producer.Close())
+		}()
+		_, err := producer.BatchArrowRecordsFromLogs(logs)
+		require.NoError(t, err)
+		return pool.peak
+	}
+
+	// The dictionaries, retained across batches, are a large
+	// part of the peak either way.
+	whole := peak()
+	chunked := peak(config.WithMaxChunkItems(1000))
+	require.Less(t, chunked*5, whole*4)
+}
+
+// BenchmarkProducerChunks compares encoding a large batch of logs at
+// once and in chunks.
+func BenchmarkProducerChunks(b *testing.B) {
+	ent := datagen.NewTestEntropy(12345)
+	lg := datagen.NewLogsGenerator(ent, ent.NewStandardResourceAttributes(), ent.NewStandardInstrumentationScopes())
+	logs := lg.Generate(10000, time.Minute)
+
+	for _, maxItems := range []int{0, 1000, 10000} {
+		b.Run(fmt.Sprint("max_chunk_items=", maxItems), func(b *testing.B) {
+			pool := &peakAllocator{Allocator: memory.NewGoAllocator()}
+			producer := NewProducerWithOptions(config.WithAllocator(pool), config.WithMaxChunkItems(maxItems))
+			defer producer.Close()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := producer.BatchArrowRecordsFromLogs(logs); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(pool.peak), "peak-bytes")
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+// compressionProbeInterval is the number of payloads of a stream
+// sent uncompressed, after a payload compressed below
+// Config.MinCompressionRatio, before compression is tried again.
+const compressionProbeInterval = 16
+
+// compressPayload returns a pooled copy of the IPC output of a stream
+// producer, compressed unless the producer has no encoder or the
+// stream's recent payloads were poorly compressible.  Consumers tell
+// compressed payloads apart by the Zstd frame header.
+func (p *Producer) compressPayload(sp *streamProducer, output []byte) []byte {
+	if p.zstdEncoder == nil || sp.uncompressedLeft > 0 {
+		if sp.uncompressedLeft > 0 {
+			sp.uncompressedLeft--
+			p.stats.UncompressedPayloads++
+		}
+		buf := getPayloadBuffer(len(output))
+		copy(buf, output)
+		return buf
+	}
+
+	buf := p.zstdEncoder.EncodeAll(output, getPayloadBuffer(len(output) / 2)[:0])
+
+	minRatio := p.conf.MinCompressionRatio
+	if minRatio == 0 || float64(len(output)) >= minRatio*float64(len(buf)) {
+		return buf
+	}
+	// The CPU spent compressing this stream is mostly wasted.
+	sp.uncompressedLeft = compressionProbeInterval
+	if len(buf) < len(output) {
+		return buf
+	}
+	putPayloadBuffer(buf)
+	p.stats.UncompressedPayloads++
+	buf = getPayloadBuffer(len(output))
+	copy(buf, output)
+	return buf
+}
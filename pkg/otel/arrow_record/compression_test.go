@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
+)
+
+// TestProducerMinCompressionRatio verifies that payloads compressing
+// below the minimum ratio are followed by uncompressed payloads,
+// which the consumer decodes, until compression is probed again.
+func TestProducerMinCompressionRatio(t *testing.T) {
+	ent := datagen.NewTestEntropy(12345)
+	dg := datagen.NewTracesGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	)
+	stdTesting := assert.NewStdUnitTest(t)
+
+	// No payload compresses a thousandfold.
+	producer := NewProducerWithOptions(config.WithZstd(), config.WithPayloadZstd(), config.WithMinCompressionRatio(1000))
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	consumer := NewConsumer()
+	defer func() {
+		require.NoError(t, consumer.Close())
+	}()
+
+	// seen counts the payloads of each stream.
+	seen := map[string]int{}
+	for i := 0; i < 2*compressionProbeInterval; i++ {
+		traces := dg.Generate(10, time.Minute)
+		batch, err := producer.BatchArrowRecordsFromTraces(traces)
+		require.NoError(t, err)
+
+		// The first payload of each stream, and the first
+		// after each probe interval, are compressed.
+		for _, payload := range batch.ArrowPayloads {
+			probe := seen[payload.SchemaId]%(compressionProbeInterval+1) == 0
+			seen[payload.SchemaId]++
+			require.Equal(t, probe, zstddict.IsFrame(payload.Record), "batch %d", i)
+		}
+
+		received, err := consumer.TracesFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(received))
+		assert.Equiv(
+			stdTesting,
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(traces)},
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(received[0])},
+		)
+	}
+	require.NotZero(t, producer.stats.UncompressedPayloads)
+}
+
+// TestProducerCompressionRatioDisabled verifies that every payload
+// is compressed without a minimum ratio.
+func TestProducerCompressionRatioDisabled(t *testing.T) {
+	ent := datagen.NewTestEntropy(12345)
+	dg := datagen.NewTracesGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	)
+
+	producer := NewProducerWithOptions(config.WithZstd(), config.WithPayloadZstd())
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+
+	for i := 0; i < 3; i++ {
+		batch, err := producer.BatchArrowRecordsFromTraces(dg.Generate(10, time.Minute))
+		require.NoError(t, err)
+		for _, payload := range batch.ArrowPayloads {
+			require.True(t, zstddict.IsFrame(payload.Record))
+		}
+	}
+	require.Zero(t, producer.stats.UncompressedPayloads)
+}
@@ -17,16 +17,20 @@ package arrow_record
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
 
 	"github.com/apache/arrow/go/v14/arrow/ipc"
 	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/klauspost/compress/zstd"
 	"go.opentelemetry.io/collector/config/configtelemetry"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
@@ -35,16 +39,22 @@ import (
 	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
 	"github.com/open-telemetry/otel-arrow/pkg/internal/debug"
 	common "github.com/open-telemetry/otel-arrow/pkg/otel/common/arrow"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/common/otlp"
 	logsotlp "github.com/open-telemetry/otel-arrow/pkg/otel/logs/otlp"
 	metricsotlp "github.com/open-telemetry/otel-arrow/pkg/otel/metrics/otlp"
 	"github.com/open-telemetry/otel-arrow/pkg/otel/traces/arrow"
 	tracesotlp "github.com/open-telemetry/otel-arrow/pkg/otel/traces/otlp"
 	"github.com/open-telemetry/otel-arrow/pkg/record_message"
 	"github.com/open-telemetry/otel-arrow/pkg/werror"
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
 )
 
 const defaultMemoryLimit = 70 << 20
 
+// DefaultMaxSchemas is the default number of streams that a
+// Consumer keeps open across all payload types, see WithMaxSchemas.
+const DefaultMaxSchemas = 128
+
 // This file implements a generic consumer API used to decode BatchArrowRecords messages into
 // their corresponding OTLP representations (i.e. pmetric.Metrics, plog.Logs, ptrace.Traces).
 // The consumer API is used by the OTLP Arrow receiver.
@@ -60,14 +70,48 @@ type ConsumerAPI interface {
 
 var _ ConsumerAPI = &Consumer{}
 
+// RequestConsumerAPI is implemented by consumers that decode batches
+// into marshaled OTLP export requests, for pass-through of the data.
+// Logs are decoded without constructing pdata; traces and metrics are
+// decoded into pdata and marshaled.
+type RequestConsumerAPI interface {
+	// LogsRequestFrom returns the marshaled OTLP
+	// ExportLogsServiceRequest and its number of log records, or
+	// nil if the batch has no logs.
+	LogsRequestFrom(*colarspb.BatchArrowRecords) ([]byte, int, error)
+
+	// TracesRequestFrom returns the marshaled OTLP
+	// ExportTraceServiceRequest and its number of spans, or nil
+	// if the batch has no traces.
+	TracesRequestFrom(*colarspb.BatchArrowRecords) ([]byte, int, error)
+
+	// MetricsRequestFrom returns the marshaled OTLP
+	// ExportMetricsServiceRequest and its number of data points,
+	// or nil if the batch has no metrics.
+	MetricsRequestFrom(*colarspb.BatchArrowRecords) ([]byte, int, error)
+}
+
+var _ RequestConsumerAPI = &Consumer{}
+
 var ErrConsumerMemoryLimit = fmt.Errorf(
 	"The number of decoded records is smaller than the number of received payloads. " +
 		"Please increase the memory limit of the consumer.")
 
+// ErrSchemaReset is returned when a payload continues a stream that
+// the consumer does not know, usually because it was evicted to
+// respect the schema limit.  The producer must reset its streams,
+// e.g., by restarting the gRPC stream, before sending again.
+var ErrSchemaReset = errors.New("payload of an unknown Arrow stream, the producer must reset")
+
 // Consumer is a BatchArrowRecords consumer.
 type Consumer struct {
 	// streamConsumers is a map of reader state by SchemaID.
 	streamConsumers map[string]*streamConsumer
+	// useSeq orders the uses of stream consumers.
+	useSeq uint64
+	// capacityHints pre-size the pdata and the related data of a
+	// batch after the recent batches of the stream.
+	capacityHints *otlp.CapacityHints
 
 	// Config embeds the configurable parameters.
 	Config
@@ -87,23 +131,56 @@ type Consumer struct {
 	recordsCounter metric.Int64Counter
 	// counts of the number of schema resets by data type.
 	schemaResetCounter metric.Int64Counter
+	// counts of the number of streams closed by the producer,
+	// by data type.
+	schemaCloseCounter metric.Int64Counter
 	// tracks allocator.Inuse()
 	memoryCounter metric.Int64UpDownCounter
 
 	// uniqueAttr is set to an 8-byte hex digit string with
 	// 32-bits of randomness, applied to all metric events
-	// when MetricsLevel is > Detailed (i.e., above detailed).
+	// when MetricsLevel is Detailed.
 	uniqueAttr attribute.KeyValue
+
+	// zstdDecoder decodes Zstd-compressed payloads, acquired on
+	// first use.  It is shared with the consumers configured with
+	// identical zstdOptions.
+	zstdDecoder *zstd.Decoder
+	zstdOptions zstdOptions
 }
 
 type Config struct {
 	memLimit uint64
 
+	// maxSchemas is the number of streams kept open across all
+	// payload types, see WithMaxSchemas().
+	maxSchemas int
+
 	tracesConfig *arrow.Config
 
 	// from component.TelemetrySettings
 	meterProvider metric.MeterProvider
 	metricsLevel  configtelemetry.Level
+
+	// zstdDictionaries are pre-trained dictionaries for
+	// payloads compressed by producers configured with
+	// config.WithZstdDictionary().
+	zstdDictionaries [][]byte
+
+	// zstdConcurrency and zstdMaxWindow configure the Zstd
+	// decoder, see WithZstdDecoderConcurrency() and
+	// WithZstdMaxWindow().
+	zstdConcurrency int
+	zstdMaxWindow   uint64
+
+	// bufferAlignment is the alignment of the buffers of decoded
+	// records, see WithAlignedBuffers().  Zero means the
+	// alignment of the Go allocator.
+	bufferAlignment int
+
+	// pooledPdata decodes into released pdata, see
+	// WithPooledPdata().
+	pooledPdata bool
 }
 
 // WithMemoryLimit configures the Arrow limited memory allocator.
@@ -113,6 +190,19 @@ func WithMemoryLimit(bytes uint64) Option {
 	}
 }
 
+// WithMaxSchemas limits the number of streams, each with a schema
+// and dictionaries, that the consumer keeps open across all payload
+// types.  Beyond the limit, the least recently used stream is
+// released, and a producer that continues it is asked to reset, see
+// ErrSchemaReset.  This bounds the memory of the consumer when
+// producers send many or constantly changing schemas.  Values below
+// 1 select DefaultMaxSchemas.
+func WithMaxSchemas(n int) Option {
+	return func(cfg *Config) {
+		cfg.maxSchemas = n
+	}
+}
+
 // WithTracesConfig configures trace-specific Arrow encoding options.
 func WithTracesConfig(tcfg *arrow.Config) Option {
 	return func(cfg *Config) {
@@ -129,10 +219,56 @@ func WithMeterProvider(p metric.MeterProvider, l configtelemetry.Level) Option {
 	}
 }
 
+// WithZstdDictionaries configures the pre-trained Zstd dictionaries
+// that payloads may refer to.  Payloads compressed without a
+// dictionary are decoded regardless.
+func WithZstdDictionaries(dicts ...[]byte) Option {
+	return func(cfg *Config) {
+		cfg.zstdDictionaries = dicts
+	}
+}
+
+// WithZstdDecoderConcurrency sets the number of payloads that the
+// Zstd decoder of the consumer, shared with other consumers with
+// identical settings, decompresses at once.  Zero means GOMAXPROCS.
+func WithZstdDecoderConcurrency(n int) Option {
+	return func(cfg *Config) {
+		cfg.zstdConcurrency = n
+	}
+}
+
+// WithZstdMaxWindow limits the window size of Zstd-compressed
+// payloads, as a way to control memory usage.  Zero means the
+// library default.
+func WithZstdMaxWindow(bytes uint64) Option {
+	return func(cfg *Config) {
+		cfg.zstdMaxWindow = bytes
+	}
+}
+
+// WithAlignedBuffers makes the consumer allocate the buffers of
+// decoded records at addresses that are a multiple of alignment,
+// padded with zeroes to a multiple of alignment, so that the records
+// can be exported through the Arrow C Data Interface to consumers
+// with stricter requirements than the Go allocator without a copy.
+// The alignment is rounded up to a power of two; values below 1
+// select 64 bytes, as recommended by the Arrow columnar format.
+func WithAlignedBuffers(alignment int) Option {
+	return func(cfg *Config) {
+		if alignment < 1 {
+			alignment = common.DefaultBufferAlignment
+		}
+		cfg.bufferAlignment = alignment
+	}
+}
+
 type streamConsumer struct {
 	bufReader   *bytes.Reader
 	ipcReader   *ipc.Reader
 	payloadType record_message.PayloadType
+	// lastUse is the value of Consumer.useSeq when the stream
+	// consumer was last used.
+	lastUse uint64
 }
 
 type Option func(*Config)
@@ -142,6 +278,7 @@ type Option func(*Config)
 func NewConsumer(opts ...Option) *Consumer {
 	cfg := Config{
 		memLimit:      defaultMemoryLimit,
+		maxSchemas:    DefaultMaxSchemas,
 		tracesConfig:  arrow.DefaultConfig(),
 		meterProvider: otel.GetMeterProvider(),
 		metricsLevel:  configtelemetry.LevelNormal,
@@ -149,7 +286,13 @@ func NewConsumer(opts ...Option) *Consumer {
 	for _, opt := range opts {
 		opt(&cfg)
 	}
+	if cfg.maxSchemas < 1 {
+		cfg.maxSchemas = DefaultMaxSchemas
+	}
 	var baseAlloc memory.Allocator = memory.NewGoAllocator()
+	if cfg.bufferAlignment != 0 {
+		baseAlloc = common.NewAlignedAllocator(baseAlloc, cfg.bufferAlignment)
+	}
 	if debug.AssertionsOn() {
 		baseAlloc = memory.NewCheckedAllocator(baseAlloc)
 	}
@@ -160,15 +303,18 @@ func NewConsumer(opts ...Option) *Consumer {
 		allocator:          allocator,
 		uniqueAttr:         attribute.String("stream_unique", fmt.Sprintf("%08x", rand.Uint32())),
 		streamConsumers:    make(map[string]*streamConsumer),
+		capacityHints:      otlp.NewCapacityHints(),
 		recordsCounter:     noop.Int64Counter{},
 		schemaResetCounter: noop.Int64Counter{},
+		schemaCloseCounter: noop.Int64Counter{},
 		memoryCounter:      noop.Int64UpDownCounter{},
 	}
-	if cfg.metricsLevel >= configtelemetry.LevelNormal {
+	if cfg.metricsLevel >= configtelemetry.LevelBasic {
 		meter := cfg.meterProvider.Meter("otel-arrow/pkg/otel/arrow_record")
 
 		c.recordsCounter = mustWarn(meter.Int64Counter("arrow_batch_records"))
 		c.schemaResetCounter = mustWarn(meter.Int64Counter("arrow_schema_resets"))
+		c.schemaCloseCounter = mustWarn(meter.Int64Counter("arrow_schemas_closed"))
 		c.memoryCounter = mustWarn(meter.Int64UpDownCounter("arrow_memory_inuse"))
 	}
 	return c
@@ -188,11 +334,14 @@ func mustWarn[T any](t T, err error) T {
 	return t
 }
 
+// metricOpts returns the attributes of a metric event: none at the
+// basic level of telemetry, which counts totals, kvs at the normal
+// level, and the consumer's unique attribute at the detailed level.
 func (c *Consumer) metricOpts(kvs ...attribute.KeyValue) []metric.AddOption {
 	if c.metricsLevel < configtelemetry.LevelNormal {
 		return nil
 	}
-	if c.metricsLevel > configtelemetry.LevelDetailed {
+	if c.metricsLevel >= configtelemetry.LevelDetailed {
 		kvs = append(kvs, c.uniqueAttr)
 	}
 	return []metric.AddOption{
@@ -234,27 +383,54 @@ func (c *Consumer) MetricsFrom(bar *colarspb.BatchArrowRecords) ([]pmetric.Metri
 		return nil, werror.Wrap(err)
 	}
 
-	result := make([]pmetric.Metrics, 0, len(records))
+	result := make([]pmetric.Metrics, 0, 1)
+
+	// A batch encoded in chunks is decoded one chunk at a time.
+	chunks := splitChunks(records, colarspb.ArrowPayloadType_UNIVARIATE_METRICS)
+	for i, chunk := range chunks {
+		metrics, err := c.metricsFromChunk(chunk)
+		if err != nil {
+			releaseChunks(chunks[i+1:])
+			return nil, werror.Wrap(err)
+		}
+		if metrics == nil {
+			continue
+		}
+		if len(result) == 0 {
+			result = append(result, *metrics)
+		} else {
+			metrics.ResourceMetrics().MoveAndAppendTo(result[0].ResourceMetrics())
+			c.ReleaseMetrics([]pmetric.Metrics{*metrics})
+		}
+	}
+
+	return result, nil
+}
+
+// metricsFromChunk decodes the metrics of one chunk of a batch, nil
+// if it has no metrics record.
+func (c *Consumer) metricsFromChunk(records []*record_message.RecordMessage) (*pmetric.Metrics, error) {
+	defer retainMain(records)()
 
 	// builds the related entities (i.e. Attributes, Summaries, Histograms, ...)
 	// from the records and returns the main record.
-	relatedData, metricsRecord, err := metricsotlp.RelatedDataFrom(records)
+	relatedData, metricsRecord, err := metricsotlp.RelatedDataFrom(records, c.capacityHints)
 	if err != nil {
-		return nil, werror.Wrap(err)
+		return nil, err
 	}
+	defer relatedData.Release()
 
 	// Process the main record with the related entities.
-	if metricsRecord != nil {
-		// Decode OTLP metrics from the combination of the main record and the
-		// related records.
-		metrics, err := metricsotlp.MetricsFrom(metricsRecord.Record(), relatedData)
-		if err != nil {
-			return nil, werror.Wrap(err)
-		}
-		result = append(result, metrics)
+	if metricsRecord == nil {
+		return nil, nil
 	}
-
-	return result, nil
+	// Decode OTLP metrics from the combination of the main record and the
+	// related records.
+	metrics := c.newMetrics()
+	if err := metricsotlp.MetricsInto(metrics, metricsRecord.Record(), relatedData); err != nil {
+		return nil, err
+	}
+	return &metrics, nil
 }
 
 // LogsFrom produces an array of [plog.Logs] from a BatchArrowRecords message.
@@ -265,22 +441,109 @@ func (c *Consumer) LogsFrom(bar *colarspb.BatchArrowRecords) ([]plog.Logs, error
 		return nil, werror.Wrap(err)
 	}
 
-	result := make([]plog.Logs, 0, len(records))
+	result := make([]plog.Logs, 0, 1)
+
+	// A batch encoded in chunks is decoded one chunk at a time.
+	chunks := splitChunks(records, colarspb.ArrowPayloadType_LOGS)
+	for i, chunk := range chunks {
+		logs, err := c.logsFromChunk(chunk)
+		if err != nil {
+			releaseChunks(chunks[i+1:])
+			return nil, werror.Wrap(err)
+		}
+		if logs == nil {
+			continue
+		}
+		if len(result) == 0 {
+			result = append(result, *logs)
+		} else {
+			logs.ResourceLogs().MoveAndAppendTo(result[0].ResourceLogs())
+			c.ReleaseLogs([]plog.Logs{*logs})
+		}
+	}
+
+	return result, nil
+}
+
+// logsFromChunk decodes the logs of one chunk of a batch, nil if it
+// has no logs record.
+func (c *Consumer) logsFromChunk(records []*record_message.RecordMessage) (*plog.Logs, error) {
+	defer retainMain(records)()
 
 	// Compute all related records (i.e. Attributes)
 	relatedData, logsRecord, err := logsotlp.RelatedDataFrom(records)
+	if relatedData != nil {
+		defer relatedData.Release()
+	}
 
-	if logsRecord != nil {
-		// Decode OTLP logs from the combination of the main record and the
-		// related records.
-		logs, err := logsotlp.LogsFrom(logsRecord.Record(), relatedData)
+	if logsRecord == nil {
+		return nil, err
+	}
+	// Decode OTLP logs from the combination of the main record and the
+	// related records.
+	logs := c.newLogs()
+	if err := logsotlp.LogsInto(logs, logsRecord.Record(), relatedData); err != nil {
+		return nil, err
+	}
+	return &logs, nil
+}
+
+// LogsRequestFrom produces a marshaled OTLP ExportLogsServiceRequest
+// from a BatchArrowRecords message, equivalent to marshaling the logs
+// returned by LogsFrom, without constructing pdata.
+func (c *Consumer) LogsRequestFrom(bar *colarspb.BatchArrowRecords) ([]byte, int, error) {
+	defer c.inuseChangeObserve()
+	records, err := c.Consume(bar)
+	if err != nil {
+		return nil, 0, werror.Wrap(err)
+	}
+	// The requests of the chunks of a batch are concatenated,
+	// which merges their repeated ResourceLogs fields.
+	var request []byte
+	var logRecords int
+	chunks := splitChunks(records, colarspb.ArrowPayloadType_LOGS)
+	for i, chunk := range chunks {
+		var count int
+		request, count, err = logsotlp.AppendExportRequest(request, chunk)
 		if err != nil {
-			return nil, werror.Wrap(err)
+			releaseChunks(chunks[i+1:])
+			return nil, 0, werror.Wrap(err)
 		}
-		result = append(result, logs)
+		logRecords += count
 	}
+	return request, logRecords, nil
+}
 
-	return result, nil
+// TracesRequestFrom produces a marshaled OTLP ExportTraceServiceRequest
+// from a BatchArrowRecords message, by marshaling the traces returned
+// by TracesFrom.
+func (c *Consumer) TracesRequestFrom(bar *colarspb.BatchArrowRecords) ([]byte, int, error) {
+	data, err := c.TracesFrom(bar)
+	if err != nil || len(data) == 0 {
+		return nil, 0, err
+	}
+	defer c.ReleaseTraces(data)
+	request, err := ptraceotlp.NewExportRequestFromTraces(data[0]).MarshalProto()
+	if err != nil {
+		return nil, 0, werror.Wrap(err)
+	}
+	return request, data[0].SpanCount(), nil
+}
+
+// MetricsRequestFrom produces a marshaled OTLP
+// ExportMetricsServiceRequest from a BatchArrowRecords message, by
+// marshaling the metrics returned by MetricsFrom.
+func (c *Consumer) MetricsRequestFrom(bar *colarspb.BatchArrowRecords) ([]byte, int, error) {
+	data, err := c.MetricsFrom(bar)
+	if err != nil || len(data) == 0 {
+		return nil, 0, err
+	}
+	defer c.ReleaseMetrics(data)
+	request, err := pmetricotlp.NewExportRequestFromMetrics(data[0]).MarshalProto()
+	if err != nil {
+		return nil, 0, werror.Wrap(err)
+	}
+	return request, data[0].DataPointCount(), nil
 }
 
 // TracesFrom produces an array of [ptrace.Traces] from a BatchArrowRecords message.
@@ -291,24 +554,53 @@ func (c *Consumer) TracesFrom(bar *colarspb.BatchArrowRecords) ([]ptrace.Traces,
 		return nil, werror.Wrap(err)
 	}
 
-	result := make([]ptrace.Traces, 0, len(records))
+	result := make([]ptrace.Traces, 0, 1)
 
-	// Compute all related records (i.e. Attributes, Events, and Links)
-	relatedData, tracesRecord, err := tracesotlp.RelatedDataFrom(records, c.tracesConfig)
-
-	if tracesRecord != nil {
-		// Decode OTLP traces from the combination of the main record and the
-		// related records.
-		traces, err := tracesotlp.TracesFrom(tracesRecord.Record(), relatedData)
+	// A batch encoded in chunks is decoded one chunk at a time.
+	chunks := splitChunks(records, colarspb.ArrowPayloadType_SPANS)
+	for i, chunk := range chunks {
+		traces, err := c.tracesFromChunk(chunk)
 		if err != nil {
+			releaseChunks(chunks[i+1:])
 			return nil, werror.Wrap(err)
 		}
-		result = append(result, traces)
+		if traces == nil {
+			continue
+		}
+		if len(result) == 0 {
+			result = append(result, *traces)
+		} else {
+			traces.ResourceSpans().MoveAndAppendTo(result[0].ResourceSpans())
+			c.ReleaseTraces([]ptrace.Traces{*traces})
+		}
 	}
 
 	return result, nil
 }
 
+// tracesFromChunk decodes the traces of one chunk of a batch, nil if
+// it has no traces record.
+func (c *Consumer) tracesFromChunk(records []*record_message.RecordMessage) (*ptrace.Traces, error) {
+	defer retainMain(records)()
+
+	// Compute all related records (i.e. Attributes, Events, and Links)
+	relatedData, tracesRecord, err := tracesotlp.RelatedDataFrom(records, c.tracesConfig, c.capacityHints)
+	if relatedData != nil {
+		defer relatedData.Release()
+	}
+
+	if tracesRecord == nil {
+		return nil, err
+	}
+	// Decode OTLP traces from the combination of the main record and the
+	// related records.
+	traces := c.newTraces()
+	if err := tracesotlp.TracesInto(traces, tracesRecord.Record(), relatedData); err != nil {
+		return nil, err
+	}
+	return &traces, nil
+}
+
 // Consume takes a BatchArrowRecords protobuf message and returns an array of RecordMessage.
 // Note: the records wrapped in the RecordMessage must be released after use by the caller.
 func (c *Consumer) Consume(bar *colarspb.BatchArrowRecords) ([]*record_message.RecordMessage, error) {
@@ -319,24 +611,22 @@ func (c *Consumer) Consume(bar *colarspb.BatchArrowRecords) ([]*record_message.R
 		c.recordsCounter.Add(ctx, int64(len(ibes)), c.metricOpts()...)
 	}()
 
+	// The streams that the producer closed may have payloads in
+	// this batch, they are released once it is read.
+	defer c.releaseClosedStreamConsumers(ctx, bar.ClosedSchemaIds)
+
 	// Transform each individual OtlpArrowPayload into RecordMessage
 	for _, payload := range bar.ArrowPayloads {
 		// Retrieves (or creates) the stream consumer for the schema id defined in the BatchArrowRecords message.
 		sc := c.streamConsumers[payload.SchemaId]
 		if sc == nil {
-			// cleanup previous stream consumer if any that have the same
-			// PayloadType. The reasoning is that if we have a new
-			// schema ID (i.e. schema change) we should no longer use
-			// the previous stream consumer for this PayloadType as schema
-			// changes are only additive.
-			// This will release the resources associated with the previous
-			// stream consumer.
-			for scID, sc := range c.streamConsumers {
-				if sc.payloadType == payload.Type {
-					sc.ipcReader.Release()
-					delete(c.streamConsumers, scID)
-				}
-			}
+			// Release the least recently used stream consumers of
+			// this PayloadType beyond MaxSchemaCacheSize, and of
+			// any type beyond the schema limit.  A new schema ID
+			// usually means a schema change, which is additive,
+			// but producers with a schema cache may still use the
+			// previous schemas.
+			c.evictStreamConsumers(payload.Type)
 
 			bufReader := bytes.NewReader([]byte{})
 			sc = &streamConsumer{
@@ -345,8 +635,18 @@ func (c *Consumer) Consume(bar *colarspb.BatchArrowRecords) ([]*record_message.R
 			}
 			c.streamConsumers[payload.SchemaId] = sc
 		}
+		c.useSeq++
+		sc.lastUse = c.useSeq
 
-		sc.bufReader.Reset(payload.Record)
+		record := payload.Record
+		if zstddict.IsFrame(record) {
+			var err error
+			if record, err = c.decompress(record); err != nil {
+				releaseRecords(ibes)
+				return nil, werror.Wrap(err)
+			}
+		}
+		sc.bufReader.Reset(record)
 		if sc.ipcReader == nil {
 			c.schemaResetCounter.Add(ctx, 1, c.metricOpts(attribute.String("payload_type", payload.Type.String()))...)
 			ipcReader, err := ipc.NewReader(
@@ -356,8 +656,12 @@ func (c *Consumer) Consume(bar *colarspb.BatchArrowRecords) ([]*record_message.R
 				ipc.WithZstd(),
 			)
 			if err != nil {
+				// A stream starts with its schema, so the
+				// producer continues a stream that was
+				// evicted, or never started.
+				delete(c.streamConsumers, payload.SchemaId)
 				releaseRecords(ibes)
-				return nil, werror.Wrap(err)
+				return nil, werror.Wrap(fmt.Errorf("%w: schema id %s: %w", ErrSchemaReset, payload.SchemaId, err))
 			}
 			sc.ipcReader = ipcReader
 		}
@@ -380,6 +684,27 @@ func (c *Consumer) Consume(bar *colarspb.BatchArrowRecords) ([]*record_message.R
 	return ibes, nil
 }
 
+// decompress decodes a payload compressed as a whole by a producer.
+// Payloads of earlier producers use IPC-level compression instead,
+// which the IPC reader decodes.
+func (c *Consumer) decompress(frame []byte) ([]byte, error) {
+	if c.zstdDecoder == nil {
+		opts := zstdOptions{
+			concurrency: c.zstdConcurrency,
+			window:      c.zstdMaxWindow,
+			memLimit:    c.memLimit,
+			dicts:       joinDictionaries(c.zstdDictionaries),
+		}
+		dec, err := zstdDecoders.acquire(opts)
+		if err != nil {
+			return nil, err
+		}
+		c.zstdDecoder = dec
+		c.zstdOptions = opts
+	}
+	return c.zstdDecoder.DecodeAll(frame, nil)
+}
+
 type runtimeChecker struct{}
 
 var _ memory.TestingT = &runtimeChecker{}
@@ -392,6 +717,10 @@ func (_ runtimeChecker) Helper() {}
 
 // Close closes the consumer and all its ipc readers.
 func (c *Consumer) Close() error {
+	if c.zstdDecoder != nil {
+		zstdDecoders.release(c.zstdOptions)
+		c.zstdDecoder = nil
+	}
 	for _, sc := range c.streamConsumers {
 		if sc.ipcReader != nil {
 			sc.ipcReader.Release()
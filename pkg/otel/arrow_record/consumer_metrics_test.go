@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+func TestConsumerMetricLevels(t *testing.T) {
+	payloadType := attribute.String("payload_type", "SPANS")
+
+	for _, test := range []struct {
+		level configtelemetry.Level
+		keys  []attribute.Key
+	}{
+		{configtelemetry.LevelNone, nil},
+		{configtelemetry.LevelBasic, nil},
+		{configtelemetry.LevelNormal, []attribute.Key{"payload_type"}},
+		{configtelemetry.LevelDetailed, []attribute.Key{"payload_type", "stream_unique"}},
+	} {
+		t.Run(test.level.String(), func(t *testing.T) {
+			c := NewConsumer(WithMeterProvider(noop.NewMeterProvider(), test.level))
+			defer func() { require.NoError(t, c.Close()) }()
+
+			set := metric.NewAddConfig(c.metricOpts(payloadType)).Attributes()
+			var keys []attribute.Key
+			for _, kv := range set.ToSlice() {
+				keys = append(keys, kv.Key)
+			}
+			require.Equal(t, test.keys, keys)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/pkg/werror"
+)
+
+// This file implements OTLP/JSON ingestion, for converting captured
+// samples and debugging payloads to BatchArrowRecords.
+
+// ErrUnknownJSONSignal is returned when an OTLP/JSON document does not
+// contain exactly one of resourceSpans, resourceMetrics, or
+// resourceLogs.
+var ErrUnknownJSONSignal = errors.New("OTLP/JSON document has no recognized signal")
+
+// JSONSignal identifies the signal of an OTLP/JSON document.
+type JSONSignal int
+
+const (
+	JSONUnknown JSONSignal = iota
+	JSONTraces
+	JSONMetrics
+	JSONLogs
+)
+
+// DetectJSONSignal returns the signal of an OTLP/JSON export request,
+// determined by its top-level field.  Both the lowerCamelCase and
+// snake_case field names defined by the OTLP/JSON encoding are
+// recognized.
+func DetectJSONSignal(data []byte) (JSONSignal, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return JSONUnknown, werror.Wrap(err)
+	}
+	signal := JSONUnknown
+	for key := range fields {
+		var found JSONSignal
+		switch strings.ReplaceAll(strings.ToLower(key), "_", "") {
+		case "resourcespans":
+			found = JSONTraces
+		case "resourcemetrics":
+			found = JSONMetrics
+		case "resourcelogs":
+			found = JSONLogs
+		default:
+			continue
+		}
+		if signal != JSONUnknown && signal != found {
+			return JSONUnknown, werror.Wrap(ErrUnknownJSONSignal)
+		}
+		signal = found
+	}
+	if signal == JSONUnknown {
+		return JSONUnknown, werror.Wrap(ErrUnknownJSONSignal)
+	}
+	return signal, nil
+}
+
+// BatchArrowRecordsFromJSON produces a BatchArrowRecords message from
+// an OTLP/JSON export request of any signal.
+func (p *Producer) BatchArrowRecordsFromJSON(data []byte) (*colarspb.BatchArrowRecords, error) {
+	signal, err := DetectJSONSignal(data)
+	if err != nil {
+		return nil, err
+	}
+	switch signal {
+	case JSONTraces:
+		var un ptrace.JSONUnmarshaler
+		td, err := un.UnmarshalTraces(data)
+		if err != nil {
+			return nil, werror.Wrap(err)
+		}
+		return p.BatchArrowRecordsFromTraces(td)
+	case JSONMetrics:
+		var un pmetric.JSONUnmarshaler
+		md, err := un.UnmarshalMetrics(data)
+		if err != nil {
+			return nil, werror.Wrap(err)
+		}
+		return p.BatchArrowRecordsFromMetrics(md)
+	default:
+		var un plog.JSONUnmarshaler
+		ld, err := un.UnmarshalLogs(data)
+		if err != nil {
+			return nil, werror.Wrap(err)
+		}
+		return p.BatchArrowRecordsFromLogs(ld)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestDetectJSONSignal(t *testing.T) {
+	for _, tc := range []struct {
+		input  string
+		signal JSONSignal
+	}{
+		{`{"resourceSpans":[]}`, JSONTraces},
+		{`{"resource_spans":[]}`, JSONTraces},
+		{`{"resourceMetrics":[]}`, JSONMetrics},
+		{`{"resource_logs":[]}`, JSONLogs},
+	} {
+		signal, err := DetectJSONSignal([]byte(tc.input))
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.signal, signal, tc.input)
+	}
+
+	for _, bad := range []string{`{}`, `{"resourceSpans":[],"resourceLogs":[]}`, `[1]`, `not json`} {
+		_, err := DetectJSONSignal([]byte(bad))
+		require.Error(t, err, bad)
+	}
+}
+
+func TestBatchArrowRecordsFromJSON(t *testing.T) {
+	producer := NewProducer()
+	consumer := NewConsumer()
+	defer func() {
+		require.NoError(t, producer.Close())
+		require.NoError(t, consumer.Close())
+	}()
+
+	td := ptrace.NewTraces()
+	span := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("from-json")
+	span.SetTraceID([16]byte{1})
+	span.SetSpanID([8]byte{2})
+	tdata, err := (&ptrace.JSONMarshaler{}).MarshalTraces(td)
+	require.NoError(t, err)
+
+	batch, err := producer.BatchArrowRecordsFromJSON(tdata)
+	require.NoError(t, err)
+	traces, err := consumer.TracesFrom(batch)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	require.Equal(t, "from-json", traces[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("gauge")
+	m.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(7)
+	mdata, err := (&pmetric.JSONMarshaler{}).MarshalMetrics(md)
+	require.NoError(t, err)
+
+	batch, err = producer.BatchArrowRecordsFromJSON(mdata)
+	require.NoError(t, err)
+	metrics, err := consumer.MetricsFrom(batch)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, 1, metrics[0].DataPointCount())
+
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("hello")
+	ldata, err := (&plog.JSONMarshaler{}).MarshalLogs(ld)
+	require.NoError(t, err)
+
+	batch, err = producer.BatchArrowRecordsFromJSON(ldata)
+	require.NoError(t, err)
+	logs, err := consumer.LogsFrom(batch)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, "hello", logs[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+
+	_, err = producer.BatchArrowRecordsFromJSON([]byte(`{"other":1}`))
+	require.ErrorIs(t, err, ErrUnknownJSONSignal)
+}
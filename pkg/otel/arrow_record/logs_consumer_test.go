@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+)
+
+// TestLogsConsumerLargeBatch verifies the decoding of a batch with
+// more log records with attributes than there are log record IDs,
+// whose IDs wrap around.
+func TestLogsConsumerLargeBatch(t *testing.T) {
+	ent := datagen.NewTestEntropy(12345)
+	dg := datagen.NewLogsGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	)
+	logs := dg.Generate(10, time.Minute)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for records.Len() <= math.MaxUint16+1 {
+		records.At(records.Len() % 10).CopyTo(records.AppendEmpty())
+	}
+
+	producer := NewProducer()
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	consumer := NewConsumer()
+	defer func() {
+		require.NoError(t, consumer.Close())
+	}()
+
+	batch, err := producer.BatchArrowRecordsFromLogs(logs)
+	require.NoError(t, err)
+	received, err := consumer.LogsFrom(batch)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(received))
+
+	assert.Equiv(
+		assert.NewStdUnitTest(t),
+		[]json.Marshaler{plogotlp.NewExportRequestFromLogs(logs)},
+		[]json.Marshaler{plogotlp.NewExportRequestFromLogs(received[0])},
+	)
+}
+
+// BenchmarkLogsConsumer decodes batches of log records into pdata.
+func BenchmarkLogsConsumer(b *testing.B) {
+	for _, size := range []int{1000, 10000} {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			ent := datagen.NewTestEntropy(12345)
+			dg := datagen.NewLogsGenerator(
+				ent,
+				ent.NewStandardResourceAttributes(),
+				ent.NewStandardInstrumentationScopes(),
+			)
+
+			// Every batch is decoded by a new consumer, from
+			// the same payloads.
+			producer := NewProducer()
+			defer producer.Close()
+			input := dg.Generate(size, time.Minute)
+			batch, err := producer.BatchArrowRecordsFromLogs(input)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			var logs []plog.Logs
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				consumer := NewConsumer()
+				logs, err = consumer.LogsFrom(batch)
+				if err != nil {
+					b.Fatal(err)
+				}
+				_ = consumer.Close()
+			}
+			b.StopTimer()
+			if logs[0].LogRecordCount() != input.LogRecordCount() {
+				b.Fatal("unexpected log record count")
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+)
+
+// generateMixedLogs returns logs with several resources and scopes,
+// and values of every type.
+func generateMixedLogs() plog.Logs {
+	logs := plog.NewLogs()
+	for r := 0; r < 3; r++ {
+		rl := logs.ResourceLogs().AppendEmpty()
+		rl.SetSchemaUrl(fmt.Sprint("resource-schema-", r))
+		rl.Resource().Attributes().PutStr("service.name", fmt.Sprint("service-", r))
+		rl.Resource().SetDroppedAttributesCount(uint32(r))
+		for s := 0; s < 2; s++ {
+			sl := rl.ScopeLogs().AppendEmpty()
+			if s == 1 {
+				sl.SetSchemaUrl("scope-schema")
+				sl.Scope().SetName("scope")
+				sl.Scope().SetVersion("v1")
+				sl.Scope().Attributes().PutBool("scope.bool", true)
+			}
+			for i := 0; i < 200; i++ {
+				log := sl.LogRecords().AppendEmpty()
+				if i%3 != 0 {
+					log.SetTimestamp(pcommon.Timestamp(1e18 + i))
+					log.SetObservedTimestamp(pcommon.Timestamp(2e18 + i))
+					log.SetTraceID(pcommon.TraceID{1, 2, 3, byte(i)})
+					log.SetSpanID(pcommon.SpanID{4, 5, byte(i)})
+					log.SetSeverityNumber(plog.SeverityNumber(i % 24))
+					log.SetSeverityText("INFO")
+					log.SetFlags(plog.LogRecordFlags(i % 2))
+					log.SetDroppedAttributesCount(uint32(i % 5))
+				}
+				attrs := log.Attributes()
+				switch i % 8 {
+				case 0:
+					log.Body().SetStr(fmt.Sprint("body-", i))
+					attrs.PutStr("str", "value")
+				case 1:
+					log.Body().SetInt(int64(-i))
+					attrs.PutInt("int", int64(i)*1000000007)
+				case 2:
+					log.Body().SetDouble(float64(i) / 3)
+					attrs.PutDouble("double", math.Inf(1))
+				case 3:
+					log.Body().SetBool(i%2 == 0)
+					attrs.PutEmptyBytes("bytes").FromRaw([]byte{byte(i)})
+					attrs.PutEmptyBytes("empty.bytes")
+				case 4:
+					log.Body().SetEmptyBytes().FromRaw([]byte("raw"))
+					attrs.PutEmptySlice("slice").AppendEmpty().SetStr("elem")
+				case 5:
+					// Deserialized maps are in random order,
+					// unless they have a single key.
+					body := log.Body().SetEmptyMap().PutEmptyMap("nested")
+					body.PutEmptySlice("list").AppendEmpty().SetBool(true)
+					attrs.PutEmptyMap("map").PutEmptyMap("nested").PutDouble("d", 1.5)
+				case 6:
+					log.Body().SetEmptySlice().AppendEmpty().SetInt(1)
+					attrs.PutEmpty("empty")
+					attrs.PutStr("", "empty key")
+				case 7:
+					// No body and no attributes.
+				}
+			}
+		}
+	}
+	return logs
+}
+
+// TestLogsRequestFrom verifies that the requests produced by
+// LogsRequestFrom are identical to marshaled LogsFrom results.
+func TestLogsRequestFrom(t *testing.T) {
+	producer := NewProducer()
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	pdataConsumer := NewConsumer()
+	defer func() {
+		require.NoError(t, pdataConsumer.Close())
+	}()
+	requestConsumer := NewConsumer()
+	defer func() {
+		require.NoError(t, requestConsumer.Close())
+	}()
+
+	var marshaler plog.ProtoMarshaler
+	for _, logs := range []plog.Logs{
+		generateMixedLogs(),
+		GenerateLogs(0, 10),
+		GenerateLogs(10, 100),
+		generateMixedLogs(),
+	} {
+		batch, err := producer.BatchArrowRecordsFromLogs(logs)
+		require.NoError(t, err)
+
+		received, err := pdataConsumer.LogsFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(received))
+		expect, err := marshaler.MarshalLogs(received[0])
+		require.NoError(t, err)
+
+		request, count, err := requestConsumer.LogsRequestFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, logs.LogRecordCount(), count)
+		require.Equal(t, expect, request)
+	}
+}
+
+// TestLogsRequestFromEquiv verifies that the requests produced by
+// LogsRequestFrom are equivalent to LogsFrom results.  Serialized
+// maps with several keys are decoded in random order, so these are
+// not compared byte for byte.
+func TestLogsRequestFromEquiv(t *testing.T) {
+	stdTesting := assert.NewStdUnitTest(t)
+	ent := datagen.NewTestEntropy(12345)
+	dg := datagen.NewLogsGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	)
+
+	producer := NewProducer()
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	pdataConsumer := NewConsumer()
+	defer func() {
+		require.NoError(t, pdataConsumer.Close())
+	}()
+	requestConsumer := NewConsumer()
+	defer func() {
+		require.NoError(t, requestConsumer.Close())
+	}()
+
+	for i := 0; i < 5; i++ {
+		logs := dg.Generate(100, time.Minute)
+		batch, err := producer.BatchArrowRecordsFromLogs(logs)
+		require.NoError(t, err)
+
+		received, err := pdataConsumer.LogsFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(received))
+		// Compare what the receiving end would see.
+		expect := plogotlp.NewExportRequest()
+		data, err := plogotlp.NewExportRequestFromLogs(received[0]).MarshalProto()
+		require.NoError(t, err)
+		require.NoError(t, expect.UnmarshalProto(data))
+
+		request, count, err := requestConsumer.LogsRequestFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, logs.LogRecordCount(), count)
+
+		decoded := plogotlp.NewExportRequest()
+		require.NoError(t, decoded.UnmarshalProto(request))
+		assert.Equiv(stdTesting,
+			[]json.Marshaler{expect},
+			[]json.Marshaler{decoded},
+		)
+	}
+}
+
+// BenchmarkLogsRequest compares decoding batches of log records into
+// marshaled OTLP requests with and without constructing pdata.
+func BenchmarkLogsRequest(b *testing.B) {
+	ent := datagen.NewTestEntropy(12345)
+	dg := datagen.NewLogsGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	)
+	producer := NewProducer()
+	defer producer.Close()
+	batch, err := producer.BatchArrowRecordsFromLogs(dg.Generate(10000, time.Minute))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("pdata", func(b *testing.B) {
+		var marshaler plog.ProtoMarshaler
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			consumer := NewConsumer()
+			logs, err := consumer.LogsFrom(batch)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err = marshaler.MarshalLogs(logs[0]); err != nil {
+				b.Fatal(err)
+			}
+			_ = consumer.Close()
+		}
+	})
+	b.Run("request", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			consumer := NewConsumer()
+			if _, _, err := consumer.LogsRequestFrom(batch); err != nil {
+				b.Fatal(err)
+			}
+			_ = consumer.Close()
+		}
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+	"github.com/open-telemetry/otel-arrow/pkg/memorypressure"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+)
+
+// TestProducerMemoryPressure verifies that a memory-pressure signal
+// resets the producer's stream producers, and that the consumer
+// decodes the new streams that follow.
+func TestProducerMemoryPressure(t *testing.T) {
+	ent := datagen.NewTestEntropy(12345)
+	dg := datagen.NewTracesGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	)
+	stdTesting := assert.NewStdUnitTest(t)
+
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	notifier := memorypressure.NewNotifier()
+	producer := NewProducerWithOptions(config.WithAllocator(pool), config.WithMemoryPressureNotifier(notifier))
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	consumer := NewConsumer()
+	defer func() {
+		require.NoError(t, consumer.Close())
+	}()
+
+	schemaIDs := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		if i == 2 {
+			notifier.Notify()
+		}
+		traces := dg.Generate(10, time.Minute)
+		batch, err := producer.BatchArrowRecordsFromTraces(traces)
+		require.NoError(t, err)
+
+		newIDs := 0
+		for _, payload := range batch.ArrowPayloads {
+			if !schemaIDs[payload.SchemaId] {
+				newIDs++
+				schemaIDs[payload.SchemaId] = true
+			}
+		}
+		if i == 2 {
+			require.Equal(t, len(batch.ArrowPayloads), newIDs, "reset batch uses new streams")
+		}
+
+		received, err := consumer.TracesFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(received))
+		assert.Equiv(
+			stdTesting,
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(traces)},
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(received[0])},
+		)
+	}
+	require.Equal(t, uint64(1), producer.GetAndResetStats().MemoryPressureResets)
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"math/bits"
+	"sync"
+
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+)
+
+// BatchReleaser is implemented by producers that pool the buffers
+// backing the payloads of the batches they produce.
+type BatchReleaser interface {
+	// Release returns the payload buffers of a batch for reuse.
+	// The caller must not use the batch afterward.
+	Release(*colarspb.BatchArrowRecords)
+}
+
+var _ BatchReleaser = &Producer{}
+
+const (
+	// minPooledPayloadBits is the smallest size class, 1KiB.
+	minPooledPayloadBits = 10
+	// maxPooledPayloadBits is the largest size class, 64MiB.
+	// Larger payloads are not pooled.
+	maxPooledPayloadBits = 26
+)
+
+// payloadPools holds released payload buffers by power-of-two size
+// class, so that a buffer taken from a pool is never more than twice
+// the size requested.  The pools are shared by all producers, since
+// producers are replaced with their streams.
+var payloadPools [maxPooledPayloadBits + 1]sync.Pool
+
+// sizeClass returns the size class of a buffer of the given size.
+func sizeClass(size int) int {
+	class := bits.Len(uint(size - 1))
+	if class < minPooledPayloadBits {
+		return minPooledPayloadBits
+	}
+	return class
+}
+
+// getPayloadBuffer returns a buffer of the given length.
+func getPayloadBuffer(size int) []byte {
+	class := sizeClass(size)
+	if class > maxPooledPayloadBits {
+		return make([]byte, size)
+	}
+	if bp, ok := payloadPools[class].Get().(*[]byte); ok {
+		return (*bp)[:size]
+	}
+	return make([]byte, size, 1<<class)
+}
+
+// putPayloadBuffer returns a buffer to the pool of the largest size
+// class it can hold.
+func putPayloadBuffer(buf []byte) {
+	c := cap(buf)
+	if c < 1<<minPooledPayloadBits {
+		return
+	}
+	class := bits.Len(uint(c)) - 1
+	if class > maxPooledPayloadBits {
+		return
+	}
+	buf = buf[:0]
+	payloadPools[class].Put(&buf)
+}
+
+// Release returns the payload buffers of a batch produced by this
+// producer for reuse by any producer.  gRPC serializes a message
+// before Send() returns, so batches may be released once sent.
+// Releasing is optional; batches that are not released are garbage
+// collected.
+func (p *Producer) Release(bar *colarspb.BatchArrowRecords) {
+	for _, payload := range bar.ArrowPayloads {
+		if payload.Record != nil {
+			putPayloadBuffer(payload.Record)
+			payload.Record = nil
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// PdataReleaser is implemented by consumers that decode into pooled
+// pdata, see WithPooledPdata.
+type PdataReleaser interface {
+	// ReleaseLogs returns logs returned by LogsFrom for reuse.
+	// The caller must not use the logs, or any part of them,
+	// afterward.
+	ReleaseLogs([]plog.Logs)
+	// ReleaseMetrics returns metrics returned by MetricsFrom for
+	// reuse, see ReleaseLogs.
+	ReleaseMetrics([]pmetric.Metrics)
+	// ReleaseTraces returns traces returned by TracesFrom for
+	// reuse, see ReleaseLogs.
+	ReleaseTraces([]ptrace.Traces)
+}
+
+var _ PdataReleaser = &Consumer{}
+
+// The pools hold released pdata with the elements they held, which
+// the decoders reuse.  The pools are shared by all consumers, since
+// consumers are replaced with their streams.
+var (
+	logsPool    sync.Pool
+	metricsPool sync.Pool
+	tracesPool  sync.Pool
+)
+
+// WithPooledPdata makes LogsFrom, MetricsFrom and TracesFrom decode
+// into pdata released by earlier calls to ReleaseLogs, ReleaseMetrics
+// and ReleaseTraces, reusing the resources, scopes and items it holds
+// instead of allocating them.  This benefits callers that discard the
+// pdata once consumed; releasing is optional, pdata that is not
+// released is garbage collected.
+func WithPooledPdata() Option {
+	return func(cfg *Config) {
+		cfg.pooledPdata = true
+	}
+}
+
+func (c *Consumer) newLogs() plog.Logs {
+	if c.pooledPdata {
+		if lp, ok := logsPool.Get().(*plog.Logs); ok {
+			return *lp
+		}
+	}
+	return plog.NewLogs()
+}
+
+func (c *Consumer) newMetrics() pmetric.Metrics {
+	if c.pooledPdata {
+		if mp, ok := metricsPool.Get().(*pmetric.Metrics); ok {
+			return *mp
+		}
+	}
+	return pmetric.NewMetrics()
+}
+
+func (c *Consumer) newTraces() ptrace.Traces {
+	if c.pooledPdata {
+		if tp, ok := tracesPool.Get().(*ptrace.Traces); ok {
+			return *tp
+		}
+	}
+	return ptrace.NewTraces()
+}
+
+// ReleaseLogs implements PdataReleaser.  It has no effect unless the
+// consumer is configured WithPooledPdata.  Logs marked read-only,
+// e.g., shared by several pipelines, are not reused.
+func (c *Consumer) ReleaseLogs(data []plog.Logs) {
+	if !c.pooledPdata {
+		return
+	}
+	for i := range data {
+		if !data[i].IsReadOnly() {
+			released := data[i]
+			logsPool.Put(&released)
+		}
+	}
+}
+
+// ReleaseMetrics implements PdataReleaser, see ReleaseLogs.
+func (c *Consumer) ReleaseMetrics(data []pmetric.Metrics) {
+	if !c.pooledPdata {
+		return
+	}
+	for i := range data {
+		if !data[i].IsReadOnly() {
+			released := data[i]
+			metricsPool.Put(&released)
+		}
+	}
+}
+
+// ReleaseTraces implements PdataReleaser, see ReleaseLogs.
+func (c *Consumer) ReleaseTraces(data []ptrace.Traces) {
+	if !c.pooledPdata {
+		return
+	}
+	for i := range data {
+		if !data[i].IsReadOnly() {
+			released := data[i]
+			tracesPool.Put(&released)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+)
+
+// pooledBatchSizes alternate large and small batches, so that the
+// pooled pdata holds both fewer and more elements than decoded.
+var pooledBatchSizes = []int{30, 3, 50, 1, 20}
+
+// pooledConsumers returns a producer, with a consumer decoding its
+// batches into pooled pdata and one decoding them into new pdata.
+// Decoding into pooled pdata leaves empty attribute maps non-nil,
+// which encode like nil ones, so that both results are compared after
+// a protobuf round trip.
+func pooledConsumers(t *testing.T) (producer *Producer, pooled, plain *Consumer) {
+	producer = NewProducer()
+	pooled, plain = NewConsumer(WithPooledPdata()), NewConsumer()
+	t.Cleanup(func() {
+		require.NoError(t, producer.Close())
+		require.NoError(t, pooled.Close())
+		require.NoError(t, plain.Close())
+	})
+	return producer, pooled, plain
+}
+
+func TestConsumerPooledTraces(t *testing.T) {
+	ent := datagen.NewTestEntropy(int64(rand.Uint64())) //nolint:gosec // only used for testing
+	dgs := []*datagen.TraceGenerator{
+		datagen.NewTracesGenerator(ent, ent.NewStandardResourceAttributes(), ent.NewStandardInstrumentationScopes()),
+		datagen.NewTracesGenerator(ent, ent.NewSingleResourceAttributes(), ent.NewSingleInstrumentationScopes()),
+	}
+
+	producer, pooled, plain := pooledConsumers(t)
+	stdTesting := assert.NewStdUnitTest(t)
+	var marshaler ptrace.ProtoMarshaler
+	var unmarshaler ptrace.ProtoUnmarshaler
+
+	for i, size := range pooledBatchSizes {
+		batch, err := producer.BatchArrowRecordsFromTraces(dgs[i%len(dgs)].Generate(size, time.Minute))
+		require.NoError(t, err)
+
+		received, err := pooled.TracesFrom(batch)
+		require.NoError(t, err)
+		require.Len(t, received, 1)
+		expected, err := plain.TracesFrom(batch)
+		require.NoError(t, err)
+
+		encoded, err := marshaler.MarshalTraces(received[0])
+		require.NoError(t, err)
+		decoded, err := unmarshaler.UnmarshalTraces(encoded)
+		require.NoError(t, err)
+		encoded, err = marshaler.MarshalTraces(expected[0])
+		require.NoError(t, err)
+		expected[0], err = unmarshaler.UnmarshalTraces(encoded)
+		require.NoError(t, err)
+		assert.Equiv(
+			stdTesting,
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(expected[0])},
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(decoded)},
+		)
+
+		pooled.ReleaseTraces(received)
+	}
+}
+
+func TestConsumerPooledLogs(t *testing.T) {
+	ent := datagen.NewTestEntropy(int64(rand.Uint64())) //nolint:gosec // only used for testing
+	dgs := []*datagen.LogsGenerator{
+		datagen.NewLogsGenerator(ent, ent.NewStandardResourceAttributes(), ent.NewStandardInstrumentationScopes()),
+		datagen.NewLogsGenerator(ent, ent.NewSingleResourceAttributes(), ent.NewSingleInstrumentationScopes()),
+	}
+
+	producer, pooled, plain := pooledConsumers(t)
+	stdTesting := assert.NewStdUnitTest(t)
+	var marshaler plog.ProtoMarshaler
+	var unmarshaler plog.ProtoUnmarshaler
+
+	for i, size := range pooledBatchSizes {
+		batch, err := producer.BatchArrowRecordsFromLogs(dgs[i%len(dgs)].Generate(size, time.Minute))
+		require.NoError(t, err)
+
+		received, err := pooled.LogsFrom(batch)
+		require.NoError(t, err)
+		require.Len(t, received, 1)
+		expected, err := plain.LogsFrom(batch)
+		require.NoError(t, err)
+
+		encoded, err := marshaler.MarshalLogs(received[0])
+		require.NoError(t, err)
+		decoded, err := unmarshaler.UnmarshalLogs(encoded)
+		require.NoError(t, err)
+		encoded, err = marshaler.MarshalLogs(expected[0])
+		require.NoError(t, err)
+		expected[0], err = unmarshaler.UnmarshalLogs(encoded)
+		require.NoError(t, err)
+		assert.Equiv(
+			stdTesting,
+			[]json.Marshaler{plogotlp.NewExportRequestFromLogs(expected[0])},
+			[]json.Marshaler{plogotlp.NewExportRequestFromLogs(decoded)},
+		)
+
+		pooled.ReleaseLogs(received)
+	}
+}
+
+func TestConsumerPooledMetrics(t *testing.T) {
+	ent := datagen.NewTestEntropy(int64(rand.Uint64())) //nolint:gosec // only used for testing
+	dgs := []*datagen.MetricsGenerator{
+		datagen.NewMetricsGenerator(ent, ent.NewStandardResourceAttributes(), ent.NewStandardInstrumentationScopes()),
+		datagen.NewMetricsGenerator(ent, ent.NewSingleResourceAttributes(), ent.NewSingleInstrumentationScopes()),
+	}
+
+	producer, pooled, plain := pooledConsumers(t)
+	stdTesting := assert.NewStdUnitTest(t)
+	var marshaler pmetric.ProtoMarshaler
+	var unmarshaler pmetric.ProtoUnmarshaler
+
+	for i, size := range pooledBatchSizes {
+		batch, err := producer.BatchArrowRecordsFromMetrics(dgs[i%len(dgs)].GenerateAllKindOfMetrics(size, time.Minute))
+		require.NoError(t, err)
+
+		received, err := pooled.MetricsFrom(batch)
+		require.NoError(t, err)
+		require.Len(t, received, 1)
+		expected, err := plain.MetricsFrom(batch)
+		require.NoError(t, err)
+
+		encoded, err := marshaler.MarshalMetrics(received[0])
+		require.NoError(t, err)
+		decoded, err := unmarshaler.UnmarshalMetrics(encoded)
+		require.NoError(t, err)
+		encoded, err = marshaler.MarshalMetrics(expected[0])
+		require.NoError(t, err)
+		expected[0], err = unmarshaler.UnmarshalMetrics(encoded)
+		require.NoError(t, err)
+		assert.Equiv(
+			stdTesting,
+			[]json.Marshaler{pmetricotlp.NewExportRequestFromMetrics(expected[0])},
+			[]json.Marshaler{pmetricotlp.NewExportRequestFromMetrics(decoded)},
+		)
+
+		pooled.ReleaseMetrics(received)
+	}
+}
+
+func TestConsumerReleaseReadOnly(t *testing.T) {
+	consumer := NewConsumer(WithPooledPdata())
+	traces := ptrace.NewTraces()
+	traces.ResourceSpans().AppendEmpty()
+	traces.MarkReadOnly()
+
+	// Read-only pdata is not reused, since the decoders could
+	// not reset it.
+	consumer.ReleaseTraces([]ptrace.Traces{traces})
+	require.NotEqual(t, traces, consumer.newTraces())
+	require.NoError(t, consumer.Close())
+}
@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/pkg/record_message"
+	"github.com/open-telemetry/otel-arrow/pkg/werror"
+)
+
+// PipelinedProducer is implemented by producers that encode a batch in
+// two stages.  The first stage converts the OTLP entities into Arrow
+// records using the builders of the producer, the second writes these
+// records to the IPC streams of the producer.  Each stage must be
+// called by one goroutine at a time, in the order of the batches, but
+// the two stages do not share state, so that a batch can be built
+// while the previous one is written.
+type PipelinedProducer interface {
+	// BuildRecords performs the first stage of the encoding of a
+	// ptrace.Traces, plog.Logs, or pmetric.Metrics.
+	BuildRecords(records any) (*BuiltRecords, error)
+
+	// BatchArrowRecordsFromBuilt performs the second stage of the
+	// encoding.  Batches must be passed in the order they were
+	// built.
+	BatchArrowRecordsFromBuilt(*BuiltRecords) (*colarspb.BatchArrowRecords, error)
+}
+
+var _ PipelinedProducer = &Producer{}
+
+// BuiltRecords are the Arrow records of a batch, between the two
+// stages of a PipelinedProducer.
+type BuiltRecords struct {
+	rms []*record_message.RecordMessage
+
+	// resetStreams is set when the builders were reset under
+	// memory pressure or trimmed, the stream producers are reset
+	// before writing these records.
+	resetStreams bool
+
+	// produced counts the batches of this signal.
+	produced *uint64
+}
+
+// emptyBuilt returns the built records of a batch without items,
+// e.g., with only resources and scopes without items, which upstream
+// processors leave behind after filtering.  Such a batch is sent
+// without payloads instead of being encoded.
+func emptyBuilt(produced *uint64) *BuiltRecords {
+	return &BuiltRecords{produced: produced}
+}
+
+// Release releases the records of a batch that will not be written.
+func (b *BuiltRecords) Release() {
+	releaseRecords(b.rms)
+	b.rms = nil
+}
+
+// BuildRecords implements PipelinedProducer.
+func (p *Producer) BuildRecords(records any) (*BuiltRecords, error) {
+	switch data := records.(type) {
+	case ptrace.Traces:
+		return p.buildTraces(data)
+	case plog.Logs:
+		return p.buildLogs(data)
+	case pmetric.Metrics:
+		return p.buildMetrics(data)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP type: %T", records)
+	}
+}
+
+// BatchArrowRecordsFromBuilt implements PipelinedProducer.
+func (p *Producer) BatchArrowRecordsFromBuilt(built *BuiltRecords) (*colarspb.BatchArrowRecords, error) {
+	if built.resetStreams {
+		if err := p.closeStreamProducers(); err != nil {
+			built.Release()
+			return nil, werror.Wrap(err)
+		}
+	}
+
+	if len(built.rms) == 0 {
+		// See emptyBuilt().
+		p.stats.EmptyBatches++
+		*built.produced++
+		batchId := p.batchId
+		p.batchId++
+		return &colarspb.BatchArrowRecords{BatchId: batchId}, nil
+	}
+
+	bar, err := p.Produce(built.rms)
+	built.rms = nil
+	if err != nil {
+		return nil, werror.Wrap(err)
+	}
+	*built.produced++
+	return bar, nil
+}
@@ -0,0 +1,127 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/protobuf/proto"
+
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+	"github.com/open-telemetry/otel-arrow/pkg/memorypressure"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+)
+
+// TestProducerPipelined verifies that batches built by one goroutine
+// and written by another are identical to batches produced directly,
+// including across a memory-pressure reset.
+func TestProducerPipelined(t *testing.T) {
+	ent := datagen.NewTestEntropy(12345)
+	dg := datagen.NewTracesGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	)
+	stdTesting := assert.NewStdUnitTest(t)
+
+	const numBatches = 10
+	var inputs []ptrace.Traces
+	for i := 0; i < numBatches; i++ {
+		inputs = append(inputs, dg.Generate(10+i, time.Minute))
+	}
+
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	directNotifier := memorypressure.NewNotifier()
+	direct := NewProducerWithOptions(config.WithMemoryPressureNotifier(directNotifier))
+	defer func() {
+		require.NoError(t, direct.Close())
+	}()
+	pipelinedNotifier := memorypressure.NewNotifier()
+	pipelined := NewProducerWithOptions(config.WithAllocator(pool), config.WithMemoryPressureNotifier(pipelinedNotifier))
+	defer func() {
+		require.NoError(t, pipelined.Close())
+	}()
+	consumer := NewConsumer()
+	defer func() {
+		require.NoError(t, consumer.Close())
+	}()
+
+	var expect []*colarspb.BatchArrowRecords
+	for i, traces := range inputs {
+		if i == numBatches/2 {
+			directNotifier.Notify()
+		}
+		batch, err := direct.BatchArrowRecordsFromTraces(traces)
+		require.NoError(t, err)
+		expect = append(expect, batch)
+	}
+
+	// The writer runs one batch behind the builder.
+	builtCh := make(chan *BuiltRecords, 1)
+	go func() {
+		defer close(builtCh)
+		for i, traces := range inputs {
+			if i == numBatches/2 {
+				pipelinedNotifier.Notify()
+			}
+			built, err := pipelined.BuildRecords(traces)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			builtCh <- built
+		}
+	}()
+
+	i := 0
+	for built := range builtCh {
+		batch, err := pipelined.BatchArrowRecordsFromBuilt(built)
+		require.NoError(t, err)
+		require.True(t, proto.Equal(expect[i], batch))
+
+		received, err := consumer.TracesFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(received))
+		assert.Equiv(
+			stdTesting,
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(inputs[i])},
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(received[0])},
+		)
+		i++
+	}
+	require.Equal(t, numBatches, i)
+
+	stats := pipelined.GetAndResetStats()
+	require.Equal(t, uint64(1), stats.MemoryPressureResets)
+	require.Equal(t, uint64(numBatches), stats.TracesBatchesProduced)
+
+	// Records that are built but not written are released.
+	built, err := pipelined.BuildRecords(inputs[0])
+	require.NoError(t, err)
+	built.Release()
+
+	_, err = pipelined.BuildRecords("not OTLP")
+	require.Error(t, err)
+}
@@ -19,12 +19,14 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"sync/atomic"
 	"time"
 
 	"github.com/HdrHistogram/hdrhistogram-go"
 	"github.com/apache/arrow/go/v14/arrow"
 	"github.com/apache/arrow/go/v14/arrow/ipc"
 	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/klauspost/compress/zstd"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -63,10 +65,11 @@ var _ ProducerAPI = &Producer{}
 // Producer is a BatchArrowRecords producer.
 type (
 	Producer struct {
-		pool            memory.Allocator // Use a custom memory allocator
-		zstd            bool             // Use IPC ZSTD compression
-		streamProducers map[string]*streamProducer
+		pool            memory.Allocator            // Use a custom memory allocator
+		recycler        *acommon.RecyclingAllocator // Reuses the buffers of released records, nil if disabled
+		streamProducers map[uint64]*streamProducer  // By structure hash, see streamProducerFor
 		nextSchemaId    int64
+		useSeq          uint64 // Orders the uses of stream producers
 		batchId         int64
 
 		// Builder for each OTEL entities
@@ -84,6 +87,46 @@ type (
 
 		// Producer observer
 		observer observer.ProducerObserver
+
+		// conf is retained to re-create the builders after a
+		// memory-pressure reset.
+		conf *cfg.Config
+
+		// underPressure is set by the memory-pressure notifier,
+		// from any goroutine, and checked before each batch.
+		underPressure      atomic.Bool
+		unregisterPressure func()
+
+		// highWater is the encoded size of the largest batch since
+		// the previous trim, and belowHighWater the number of
+		// consecutive batches encoded in less than half of it, see
+		// Config.TrimAfterBatches.  trimPending is set by the
+		// stage that writes the batches and checked by the stage
+		// that builds them.
+		highWater      int
+		belowHighWater int
+		trimPending    atomic.Bool
+
+		// buildFailed is set when the builders failed to encode a
+		// batch, they are reset before the next batch since they
+		// may hold part of the failed batch.
+		buildFailed atomic.Bool
+
+		// zstdEncoder compresses payloads, nil if compression is
+		// disabled.  It is shared with the producers configured
+		// with identical zstdOptions.
+		zstdEncoder *zstd.Encoder
+		zstdOptions zstdOptions
+
+		// schemaChurn tracks the schema changes of the payload
+		// types, see Config.SchemaChurnThreshold.
+		schemaChurn *schemaChurn
+
+		// closedSchemaIDs are the schema IDs of the streams
+		// closed since the last batch with payloads, which
+		// announces them to the consumer, see
+		// takeClosedSchemaIDs.
+		closedSchemaIDs []string
 	}
 
 	consoleObserver struct {
@@ -102,6 +145,16 @@ type (
 		lastProduction time.Time
 		schema         *arrow.Schema
 		payloadType    record_message.PayloadType
+		// structure is the ID of the record's schema, see
+		// carrow.SchemaToID.
+		structure string
+		// lastUse is the value of Producer.useSeq when the
+		// stream producer was last used.
+		lastUse uint64
+		// uncompressedLeft is the number of payloads to send
+		// uncompressed before compression is tried again, see
+		// Config.MinCompressionRatio.
+		uncompressedLeft int
 	}
 )
 
@@ -122,6 +175,15 @@ func NewProducerWithOptions(options ...cfg.Option) *Producer {
 		opt(conf)
 	}
 
+	// Record builders hand their buffers over to the records they
+	// build, the recycler returns them to the builders once the
+	// records are released.
+	var recycler *acommon.RecyclingAllocator
+	if conf.BufferRetention > 0 {
+		recycler = acommon.NewRecyclingAllocator(conf.Pool, conf.BufferRetention)
+		conf.Pool = recycler
+	}
+
 	// Configure the various level of statistics to collect and display.
 	stats := pstats.NewProducerStats()
 	stats.SchemaStats = conf.SchemaStats
@@ -131,6 +193,52 @@ func NewProducerWithOptions(options ...cfg.Option) *Producer {
 	stats.CompressionRatioStats = conf.CompressionRatioStats
 	stats.ProducerStats = conf.ProducerStats
 
+	p := &Producer{
+		pool:            conf.Pool,
+		recycler:        recycler,
+		streamProducers: make(map[uint64]*streamProducer),
+		batchId:         0,
+
+		conf:        conf,
+		stats:       stats,
+		observer:    conf.Observer,
+		schemaChurn: newSchemaChurn(),
+	}
+	p.initBuilders()
+
+	// Payloads compressed as a whole, rather than at the IPC
+	// level, share an encoder reused across batches.
+	if (conf.Zstd && conf.PayloadZstd) || conf.ZstdDictionary != nil {
+		p.zstdOptions = zstdOptions{
+			concurrency: conf.ZstdConcurrency,
+			window:      conf.ZstdWindowSize,
+			level:       conf.ZstdLevel,
+			dicts:       string(conf.ZstdDictionary),
+		}
+		enc, err := zstdEncoders.acquire(p.zstdOptions)
+		if err != nil {
+			// Dictionaries are checked by zstddict.ID() and
+			// window sizes by the caller when configured;
+			// this is a programming error.
+			panic(err)
+		}
+		p.zstdEncoder = enc
+	}
+
+	if conf.MemoryPressure != nil {
+		p.unregisterPressure = conf.MemoryPressure.Register(func() {
+			p.underPressure.Store(true)
+		})
+	}
+	return p
+}
+
+// initBuilders creates the record and entity builders for each OTEL
+// entity.
+func (p *Producer) initBuilders() {
+	conf := p.conf
+	stats := p.stats
+
 	// Record builders
 	metricsRecordBuilder := builder.NewRecordBuilderExt(
 		conf.Pool,
@@ -185,23 +293,13 @@ func NewProducerWithOptions(options ...cfg.Option) *Producer {
 		panic(err)
 	}
 
-	return &Producer{
-		pool:            conf.Pool,
-		zstd:            conf.Zstd,
-		streamProducers: make(map[string]*streamProducer),
-		batchId:         0,
-
-		metricsBuilder: metricsBuilder,
-		logsBuilder:    logsBuilder,
-		tracesBuilder:  tracesBuilder,
+	p.metricsBuilder = metricsBuilder
+	p.logsBuilder = logsBuilder
+	p.tracesBuilder = tracesBuilder
 
-		metricsRecordBuilder: metricsRecordBuilder,
-		logsRecordBuilder:    logsRecordBuilder,
-		tracesRecordBuilder:  tracesRecordBuilder,
-
-		stats:    stats,
-		observer: conf.Observer,
-	}
+	p.metricsRecordBuilder = metricsRecordBuilder
+	p.logsRecordBuilder = logsRecordBuilder
+	p.tracesRecordBuilder = tracesRecordBuilder
 }
 
 // SetObserver adds an observer to the producer.
@@ -211,6 +309,23 @@ func (p *Producer) SetObserver(observer observer.ProducerObserver) {
 
 // BatchArrowRecordsFromMetrics produces a BatchArrowRecords message from a [pmetric.Metrics] messages.
 func (p *Producer) BatchArrowRecordsFromMetrics(metrics pmetric.Metrics) (*colarspb.BatchArrowRecords, error) {
+	if p.conf.MaxChunkItems > 0 && chunkItems(metrics) > p.conf.MaxChunkItems {
+		return p.batchChunks(metrics)
+	}
+	built, err := p.buildMetrics(metrics)
+	if err != nil {
+		return nil, err
+	}
+	return p.BatchArrowRecordsFromBuilt(built)
+}
+
+// buildMetrics builds the Arrow records of a [pmetric.Metrics].
+func (p *Producer) buildMetrics(metrics pmetric.Metrics) (*BuiltRecords, error) {
+	if metrics.DataPointCount() == 0 {
+		return emptyBuilt(&p.stats.MetricsBatchesProduced), nil
+	}
+	resetStreams := p.checkMemoryPressure()
+
 	// Builds a main Record and n related Records from the metrics passed in
 	// parameter. All these Arrow records are wrapped into a BatchArrowRecords
 	// and will be released by the Producer.Produce method.
@@ -221,12 +336,14 @@ func (p *Producer) BatchArrowRecordsFromMetrics(metrics pmetric.Metrics) (*colar
 		return p.metricsBuilder, nil
 	}, metrics, p.observer)
 	if err != nil {
+		p.buildFailed.Store(true)
 		return nil, werror.Wrap(err)
 	}
 
 	// builds the related records (e.g. INT_SUM, INT_GAUGE, INT_GAUGE_ATTRS, ...)
 	rms, err := p.metricsBuilder.RelatedData().BuildRecordMessages()
 	if err != nil {
+		record.Release()
 		return nil, werror.Wrap(err)
 	}
 
@@ -236,16 +353,32 @@ func (p *Producer) BatchArrowRecordsFromMetrics(metrics pmetric.Metrics) (*colar
 	// in the collector.
 	rms = append([]*record_message.RecordMessage{record_message.NewMetricsMessage(schemaID, record)}, rms...)
 
-	bar, err := p.Produce(rms)
-	if err != nil {
-		return nil, werror.Wrap(err)
-	}
-	p.stats.MetricsBatchesProduced++
-	return bar, nil
+	return &BuiltRecords{
+		rms:          rms,
+		resetStreams: resetStreams,
+		produced:     &p.stats.MetricsBatchesProduced,
+	}, nil
 }
 
 // BatchArrowRecordsFromLogs produces a BatchArrowRecords message from a [plog.Logs] messages.
 func (p *Producer) BatchArrowRecordsFromLogs(ls plog.Logs) (*colarspb.BatchArrowRecords, error) {
+	if p.conf.MaxChunkItems > 0 && chunkItems(ls) > p.conf.MaxChunkItems {
+		return p.batchChunks(ls)
+	}
+	built, err := p.buildLogs(ls)
+	if err != nil {
+		return nil, err
+	}
+	return p.BatchArrowRecordsFromBuilt(built)
+}
+
+// buildLogs builds the Arrow records of a [plog.Logs].
+func (p *Producer) buildLogs(ls plog.Logs) (*BuiltRecords, error) {
+	if ls.LogRecordCount() == 0 {
+		return emptyBuilt(&p.stats.LogsBatchesProduced), nil
+	}
+	resetStreams := p.checkMemoryPressure()
+
 	// Builds a main Record and n related Records from the logs passed in
 	// parameter. All these Arrow records are wrapped into a BatchArrowRecords
 	// and will be released by the Producer.Produce method.
@@ -254,11 +387,13 @@ func (p *Producer) BatchArrowRecordsFromLogs(ls plog.Logs) (*colarspb.BatchArrow
 		return p.logsBuilder, nil
 	}, ls, p.observer)
 	if err != nil {
+		p.buildFailed.Store(true)
 		return nil, werror.Wrap(err)
 	}
 
 	rms, err := p.logsBuilder.RelatedData().BuildRecordMessages()
 	if err != nil {
+		record.Release()
 		return nil, werror.Wrap(err)
 	}
 
@@ -267,16 +402,32 @@ func (p *Producer) BatchArrowRecordsFromLogs(ls plog.Logs) (*colarspb.BatchArrow
 	// in the collector.
 	rms = append([]*record_message.RecordMessage{record_message.NewLogsMessage(schemaID, record)}, rms...)
 
-	bar, err := p.Produce(rms)
-	if err != nil {
-		return nil, werror.Wrap(err)
-	}
-	p.stats.LogsBatchesProduced++
-	return bar, nil
+	return &BuiltRecords{
+		rms:          rms,
+		resetStreams: resetStreams,
+		produced:     &p.stats.LogsBatchesProduced,
+	}, nil
 }
 
 // BatchArrowRecordsFromTraces produces a BatchArrowRecords message from a [ptrace.Traces] messages.
 func (p *Producer) BatchArrowRecordsFromTraces(ts ptrace.Traces) (*colarspb.BatchArrowRecords, error) {
+	if p.conf.MaxChunkItems > 0 && chunkItems(ts) > p.conf.MaxChunkItems {
+		return p.batchChunks(ts)
+	}
+	built, err := p.buildTraces(ts)
+	if err != nil {
+		return nil, err
+	}
+	return p.BatchArrowRecordsFromBuilt(built)
+}
+
+// buildTraces builds the Arrow records of a [ptrace.Traces].
+func (p *Producer) buildTraces(ts ptrace.Traces) (*BuiltRecords, error) {
+	if ts.SpanCount() == 0 {
+		return emptyBuilt(&p.stats.TracesBatchesProduced), nil
+	}
+	resetStreams := p.checkMemoryPressure()
+
 	// Builds a main Record and n related Records from the traces passed in
 	// parameter. All these Arrow records are wrapped into a BatchArrowRecords
 	// and will be released by the Producer.Produce method.
@@ -285,11 +436,13 @@ func (p *Producer) BatchArrowRecordsFromTraces(ts ptrace.Traces) (*colarspb.Batc
 		return p.tracesBuilder, nil
 	}, ts, p.observer)
 	if err != nil {
+		p.buildFailed.Store(true)
 		return nil, werror.Wrap(err)
 	}
 
 	rms, err := p.tracesBuilder.RelatedData().BuildRecordMessages()
 	if err != nil {
+		record.Release()
 		return nil, werror.Wrap(err)
 	}
 
@@ -298,12 +451,11 @@ func (p *Producer) BatchArrowRecordsFromTraces(ts ptrace.Traces) (*colarspb.Batc
 	// in the collector.
 	rms = append([]*record_message.RecordMessage{record_message.NewTraceMessage(schemaID, record)}, rms...)
 
-	bar, err := p.Produce(rms)
-	if err != nil {
-		return nil, werror.Wrap(err)
-	}
-	p.stats.TracesBatchesProduced++
-	return bar, nil
+	return &BuiltRecords{
+		rms:          rms,
+		resetStreams: resetStreams,
+		produced:     &p.stats.TracesBatchesProduced,
+	}, nil
 }
 
 // MetricsRecordBuilderExt returns the record builder used to encode metrics.
@@ -335,6 +487,23 @@ func (p *Producer) TracesBuilder() *tracesarrow.TracesBuilder {
 
 // Close closes all stream producers.
 func (p *Producer) Close() error {
+	if p.unregisterPressure != nil {
+		p.unregisterPressure()
+		p.unregisterPressure = nil
+	}
+	if p.zstdEncoder != nil {
+		zstdEncoders.release(p.zstdOptions)
+		p.zstdEncoder = nil
+	}
+	p.releaseBuilders()
+	err := p.closeStreamProducers()
+	if p.recycler != nil {
+		p.recycler.Purge()
+	}
+	return err
+}
+
+func (p *Producer) releaseBuilders() {
 	p.metricsBuilder.Release()
 	p.logsBuilder.Release()
 	p.tracesBuilder.Release()
@@ -342,16 +511,71 @@ func (p *Producer) Close() error {
 	p.metricsRecordBuilder.Release()
 	p.logsRecordBuilder.Release()
 	p.tracesRecordBuilder.Release()
+}
 
-	for _, sp := range p.streamProducers {
-		if err := sp.ipcWriter.Close(); err != nil {
-			return werror.Wrap(err)
+func (p *Producer) closeStreamProducers() error {
+	for key, sp := range p.streamProducers {
+		if err := p.closeStreamProducer(key, sp); err != nil {
+			return err
 		}
-		p.stats.StreamProducersClosed++
 	}
 	return nil
 }
 
+// checkMemoryPressure resets the producer's builders when the
+// memory-pressure notifier has signaled since the previous batch, and
+// returns true if the stream producers must be reset before the
+// batch is written.  The dictionaries accumulated by the builders and
+// IPC writers are released.  The next batch starts new IPC streams
+// with new schema IDs, which the consumer treats as any other schema
+// change, at the cost of a less compact encoding while dictionaries
+// are rebuilt.
+//
+// The builders are reset in the same way when a capacity trim is
+// pending, see trackHighWater, or after they failed to encode a batch.
+func (p *Producer) checkMemoryPressure() bool {
+	pressure := p.underPressure.Swap(false)
+	trim := p.trimPending.Swap(false)
+	failed := p.buildFailed.Swap(false)
+	switch {
+	case pressure:
+		p.stats.MemoryPressureResets++
+	case trim:
+		p.stats.CapacityTrims++
+	case !failed:
+		return false
+	}
+	p.releaseBuilders()
+	p.initBuilders()
+	if p.recycler != nil {
+		p.recycler.Purge()
+	}
+	return true
+}
+
+// trackHighWater records the encoded size of a batch and schedules a
+// capacity trim once Config.TrimAfterBatches consecutive batches are
+// smaller than half of the largest batch since the previous trim.
+// The builders, accumulators and recycled buffers keep the capacity
+// of the largest batch they encoded; the trim releases it before the
+// next batch is built.
+func (p *Producer) trackHighWater(size int) {
+	if p.conf.TrimAfterBatches <= 0 {
+		return
+	}
+	if size >= p.highWater/2 {
+		p.highWater = max(p.highWater, size)
+		p.belowHighWater = 0
+		return
+	}
+	p.belowHighWater++
+	if p.belowHighWater >= p.conf.TrimAfterBatches {
+		p.trimPending.Store(true)
+		p.highWater = 0
+		p.belowHighWater = 0
+	}
+}
+
 // GetAndResetStats returns the stats and resets them.
 func (p *Producer) GetAndResetStats() pstats.ProducerStats {
 	return p.stats.GetAndReset()
@@ -359,7 +583,26 @@ func (p *Producer) GetAndResetStats() pstats.ProducerStats {
 
 // Produce takes a slice of RecordMessage and returns the corresponding BatchArrowRecords protobuf message.
 func (p *Producer) Produce(rms []*record_message.RecordMessage) (*colarspb.BatchArrowRecords, error) {
+	oapl, err := p.produce(rms)
+	if err != nil {
+		return nil, err
+	}
+
+	batchId := p.batchId
+	p.batchId++
+
+	return &colarspb.BatchArrowRecords{
+		BatchId:         batchId,
+		ArrowPayloads:   oapl,
+		ClosedSchemaIds: p.takeClosedSchemaIDs(),
+	}, nil
+}
+
+// produce writes the records to the IPC streams and returns the
+// corresponding payloads, releasing the records.
+func (p *Producer) produce(rms []*record_message.RecordMessage) ([]*colarspb.ArrowPayload, error) {
 	oapl := make([]*colarspb.ArrowPayload, len(rms))
+	size := 0
 
 	if p.stats.RecordStats {
 		fmt.Printf("==> Batch id %d\n", p.batchId)
@@ -371,47 +614,23 @@ func (p *Producer) Produce(rms []*record_message.RecordMessage) (*colarspb.Batch
 				rm.Record().Release()
 			}()
 
-			// Retrieves (or creates) the stream Producer for the schema id defined in the RecordMessage.
-			sp := p.streamProducers[rm.SchemaID()]
-			if sp == nil {
-				// cleanup previous stream producer if any that have the same
-				// PayloadType. The reasoning is that if we have a new
-				// schema ID (i.e. schema change) we should no longer use
-				// the previous stream producer for this PayloadType as schema
-				// changes are only additive.
-				// This will release the resources associated with the previous
-				// stream producer.
-				for ssID, sp := range p.streamProducers {
-					if sp.payloadType == rm.PayloadType() {
-						if err := sp.ipcWriter.Close(); err != nil {
-							return werror.Wrap(err)
-						}
-						p.stats.StreamProducersClosed++
-						delete(p.streamProducers, ssID)
-					}
-				}
-
-				var buf bytes.Buffer
-				sp = &streamProducer{
-					output:      buf,
-					schemaID:    fmt.Sprintf("%d", p.nextSchemaId),
-					payloadType: rm.PayloadType(),
-				}
-				p.streamProducers[rm.SchemaID()] = sp
-				p.nextSchemaId++
-				p.stats.StreamProducersCreated++
+			// Retrieves (or creates) the stream Producer for the schema defined in the RecordMessage.
+			sp, err := p.streamProducerFor(rm.SchemaID(), rm.PayloadType())
+			if err != nil {
+				return err
 			}
 
 			sp.lastProduction = time.Now()
 			sp.schema = rm.Record().Schema()
 
 			if sp.ipcWriter == nil {
+				p.observeSchemaChange(p.batchId, rm)
 				options := []ipc.Option{
 					ipc.WithAllocator(p.pool), // use allocator of the `Producer`
 					ipc.WithSchema(rm.Record().Schema()),
-					ipc.WithDictionaryDeltas(true), // enable dictionary deltas
+					ipc.WithDictionaryDeltas(!p.conf.NoDictionaryDeltas),
 				}
-				if p.zstd {
+				if p.conf.Zstd && p.zstdEncoder == nil {
 					options = append(options, ipc.WithZstd())
 				}
 				sp.ipcWriter = ipc.NewWriter(&sp.output, options...)
@@ -421,13 +640,13 @@ func (p *Producer) Produce(rms []*record_message.RecordMessage) (*colarspb.Batch
 				p.observer.OnRecord(rm.Record(), rm.PayloadType())
 			}
 
-			err := sp.ipcWriter.Write(rm.Record())
+			err = sp.ipcWriter.Write(rm.Record())
 			if err != nil {
 				return werror.Wrap(err)
 			}
 			outputBuf := sp.output.Bytes()
-			buf := make([]byte, len(outputBuf))
-			copy(buf, outputBuf)
+			size += len(outputBuf)
+			buf := p.compressPayload(sp, outputBuf)
 
 			if p.stats.RecordStats || p.stats.CompressionRatioStats {
 				payloadType := rm.PayloadType().String()
@@ -470,14 +689,8 @@ func (p *Producer) Produce(rms []*record_message.RecordMessage) (*colarspb.Batch
 			return nil, werror.Wrap(err)
 		}
 	}
-
-	batchId := p.batchId
-	p.batchId++
-
-	return &colarspb.BatchArrowRecords{
-		BatchId:       batchId,
-		ArrowPayloads: oapl,
-	}, nil
+	p.trackHighWater(size)
+	return oapl, nil
 }
 
 // ShowStats prints the stats to the console.
@@ -23,8 +23,11 @@ import (
 
 	"github.com/apache/arrow/go/v14/arrow/memory"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
 	"google.golang.org/protobuf/proto"
 
@@ -270,6 +273,99 @@ func TestProducerConsumerTraces(t *testing.T) {
 	}
 }
 
+// TestConsumerCapacityHints verifies that the consumer remembers the
+// sizes of the batches of a stream to pre-size the next.
+func TestConsumerCapacityHints(t *testing.T) {
+	ent := datagen.NewTestEntropy(int64(rand.Uint64())) //nolint:gosec // only used for testing
+	stdTesting := assert.NewStdUnitTest(t)
+	dg := datagen.NewTracesGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	)
+
+	producer := NewProducer()
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	consumer := NewConsumer()
+	defer func() {
+		require.NoError(t, consumer.Close())
+	}()
+
+	maxResources := 0
+	for i := 0; i < 3; i++ {
+		traces := dg.Generate(10, time.Minute)
+		maxResources = max(maxResources, traces.ResourceSpans().Len())
+		batch, err := producer.BatchArrowRecordsFromTraces(traces)
+		require.NoError(t, err)
+		received, err := consumer.TracesFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(received))
+
+		assert.Equiv(
+			stdTesting,
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(traces)},
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(received[0])},
+		)
+		require.Equal(t, maxResources, consumer.capacityHints.Hint(arrowpb.ArrowPayloadType_SPANS))
+		require.Positive(t, consumer.capacityHints.Hint(arrowpb.ArrowPayloadType_SPAN_ATTRS))
+	}
+}
+
+// TestProducerEmptyBatches verifies that batches without items are
+// sent without payloads and do not disturb the batches that follow.
+func TestProducerEmptyBatches(t *testing.T) {
+	producer := NewProducer()
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	consumer := NewConsumer()
+	defer func() {
+		require.NoError(t, consumer.Close())
+	}()
+
+	noSpans := ptrace.NewTraces()
+	noSpans.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty()
+
+	for _, traces := range []ptrace.Traces{ptrace.NewTraces(), noSpans} {
+		batch, err := producer.BatchArrowRecordsFromTraces(traces)
+		require.NoError(t, err)
+		require.Empty(t, batch.ArrowPayloads)
+
+		received, err := consumer.TracesFrom(batch)
+		require.NoError(t, err)
+		require.Empty(t, received)
+	}
+
+	batch, err := producer.BatchArrowRecordsFromLogs(plog.NewLogs())
+	require.NoError(t, err)
+	require.Empty(t, batch.ArrowPayloads)
+
+	batch, err = producer.BatchArrowRecordsFromMetrics(pmetric.NewMetrics())
+	require.NoError(t, err)
+	require.Empty(t, batch.ArrowPayloads)
+
+	stats := producer.GetAndResetStats()
+	require.EqualValues(t, 4, stats.EmptyBatches)
+	require.EqualValues(t, 2, stats.TracesBatchesProduced)
+
+	// A batch with items follows on the same stream.
+	ent := datagen.NewTestEntropy(12345)
+	traces := datagen.NewTracesGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	).Generate(10, time.Minute)
+	batch, err = producer.BatchArrowRecordsFromTraces(traces)
+	require.NoError(t, err)
+	require.NotEmpty(t, batch.ArrowPayloads)
+	received, err := consumer.TracesFrom(batch)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(received))
+	require.Equal(t, traces.SpanCount(), received[0].SpanCount())
+}
+
 func TestProducerConsumerLogs(t *testing.T) {
 	ent := datagen.NewTestEntropy(int64(rand.Uint64())) //nolint:gosec // only used for testing
 
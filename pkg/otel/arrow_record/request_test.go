@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+
+	"github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+)
+
+// TestTracesRequestFrom verifies that the requests produced by
+// TracesRequestFrom are equivalent to TracesFrom results.
+func TestTracesRequestFrom(t *testing.T) {
+	stdTesting := assert.NewStdUnitTest(t)
+	producer := NewProducer()
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	pdataConsumer := NewConsumer()
+	defer func() {
+		require.NoError(t, pdataConsumer.Close())
+	}()
+	requestConsumer := NewConsumer()
+	defer func() {
+		require.NoError(t, requestConsumer.Close())
+	}()
+
+	for _, count := range []int{1, 10, 100} {
+		traces := GenerateTraces(count, count)
+		batch, err := producer.BatchArrowRecordsFromTraces(traces)
+		require.NoError(t, err)
+
+		received, err := pdataConsumer.TracesFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(received))
+		expect := ptraceotlp.NewExportRequestFromTraces(received[0])
+
+		request, spans, err := requestConsumer.TracesRequestFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, traces.SpanCount(), spans)
+
+		decoded := ptraceotlp.NewExportRequest()
+		require.NoError(t, decoded.UnmarshalProto(request))
+		assert.Equiv(stdTesting,
+			[]json.Marshaler{expect},
+			[]json.Marshaler{decoded},
+		)
+	}
+}
+
+// TestMetricsRequestFrom verifies that the requests produced by
+// MetricsRequestFrom are equivalent to MetricsFrom results.
+func TestMetricsRequestFrom(t *testing.T) {
+	stdTesting := assert.NewStdUnitTest(t)
+	producer := NewProducer()
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	pdataConsumer := NewConsumer()
+	defer func() {
+		require.NoError(t, pdataConsumer.Close())
+	}()
+	requestConsumer := NewConsumer()
+	defer func() {
+		require.NoError(t, requestConsumer.Close())
+	}()
+
+	for _, count := range []int{1, 10, 100} {
+		metrics := GenerateMetrics(count, count)
+		batch, err := producer.BatchArrowRecordsFromMetrics(metrics)
+		require.NoError(t, err)
+
+		received, err := pdataConsumer.MetricsFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(received))
+		expect := pmetricotlp.NewExportRequestFromMetrics(received[0])
+
+		request, points, err := requestConsumer.MetricsRequestFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, metrics.DataPointCount(), points)
+
+		decoded := pmetricotlp.NewExportRequest()
+		require.NoError(t, decoded.UnmarshalProto(request))
+		assert.Equiv(stdTesting,
+			[]json.Marshaler{expect},
+			[]json.Marshaler{decoded},
+		)
+	}
+}
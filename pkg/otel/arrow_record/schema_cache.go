@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/open-telemetry/otel-arrow/pkg/record_message"
+	"github.com/open-telemetry/otel-arrow/pkg/werror"
+)
+
+// This file implements the schema cache of producers and consumers.
+// Producers identify the IPC stream of a record by a hash of the
+// structure of its schema.  By default, a new structure closes the
+// other streams of the same payload type.  With a schema cache (see
+// config.WithSchemaCacheSize), the streams of the most recently used
+// structures stay open, so that batches alternating between a few
+// shapes, e.g., from several services multiplexed on one exporter,
+// reuse their streams and dictionaries instead of resetting them.
+
+// MaxSchemaCacheSize is the number of streams per payload type that
+// a Consumer keeps open, and so the largest schema cache size of a
+// Producer.
+const MaxSchemaCacheSize = 4
+
+// structureHash returns the hash of a schema's structure, given its
+// schema ID as computed by carrow.SchemaToID.
+func structureHash(schemaID string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(schemaID))
+	return h.Sum64()
+}
+
+// streamProducerFor returns the stream producer of the record's
+// schema, creating it if necessary.  Creating one closes the least
+// recently used stream producers of the same payload type beyond the
+// schema cache size.
+func (p *Producer) streamProducerFor(schemaID string, payloadType record_message.PayloadType) (*streamProducer, error) {
+	key := structureHash(schemaID)
+	p.useSeq++
+
+	if sp := p.streamProducers[key]; sp != nil {
+		if sp.structure == schemaID {
+			sp.lastUse = p.useSeq
+			return sp, nil
+		}
+		// A hash collision, the new structure replaces the
+		// stream.
+		if err := p.closeStreamProducer(key, sp); err != nil {
+			return nil, err
+		}
+	}
+
+	cacheSize := p.conf.SchemaCacheSize
+	if cacheSize < 1 {
+		cacheSize = 1
+	}
+	for {
+		var oldestKey uint64
+		var oldest *streamProducer
+		count := 0
+		for k, sp := range p.streamProducers {
+			if sp.payloadType != payloadType {
+				continue
+			}
+			count++
+			if oldest == nil || sp.lastUse < oldest.lastUse {
+				oldestKey, oldest = k, sp
+			}
+		}
+		if count < cacheSize {
+			break
+		}
+		// Since schema changes are additive, a new structure
+		// usually supersedes the previous one.  This releases
+		// the resources of the stream producer.
+		if err := p.closeStreamProducer(oldestKey, oldest); err != nil {
+			return nil, err
+		}
+	}
+
+	sp := &streamProducer{
+		schemaID:    fmt.Sprintf("%d", p.nextSchemaId),
+		structure:   schemaID,
+		payloadType: payloadType,
+		lastUse:     p.useSeq,
+	}
+	p.streamProducers[key] = sp
+	p.nextSchemaId++
+	p.stats.StreamProducersCreated++
+	return sp, nil
+}
+
+// closeStreamProducer closes and removes one stream producer.
+func (p *Producer) closeStreamProducer(key uint64, sp *streamProducer) error {
+	if sp.ipcWriter != nil {
+		if err := sp.ipcWriter.Close(); err != nil {
+			return werror.Wrap(err)
+		}
+		// Only the streams that were written are known to
+		// the consumer.
+		p.closedSchemaIDs = append(p.closedSchemaIDs, sp.schemaID)
+	}
+	p.stats.StreamProducersClosed++
+	delete(p.streamProducers, key)
+	return nil
+}
+
+// takeClosedSchemaIDs returns the schema IDs of the streams closed
+// since the last batch with payloads, to be announced with the next
+// one, see BatchArrowRecords.ClosedSchemaIds.  Batches without
+// payloads do not announce them, since receivers answer them without
+// a consumer.  They are sorted, since a reset closes the streams in
+// map order.
+func (p *Producer) takeClosedSchemaIDs() []string {
+	ids := p.closedSchemaIDs
+	p.closedSchemaIDs = nil
+	sort.Strings(ids)
+	return ids
+}
+
+// evictStreamConsumers releases the least recently used stream
+// consumers of a payload type, so that a new one can be added
+// without exceeding MaxSchemaCacheSize, then the least recently used
+// ones of any type beyond the consumer's schema limit (see
+// WithMaxSchemas).  The streams of producers without a schema cache
+// are replaced one by one, and those of producers with a schema
+// cache are kept while the producer may reuse them.  A producer
+// that uses an evicted stream again is asked to reset, see
+// ErrSchemaReset.
+func (c *Consumer) evictStreamConsumers(payloadType record_message.PayloadType) {
+	for {
+		var oldestID, oldestOfTypeID string
+		var oldest, oldestOfType *streamConsumer
+		count := 0
+		for id, sc := range c.streamConsumers {
+			if oldest == nil || sc.lastUse < oldest.lastUse {
+				oldestID, oldest = id, sc
+			}
+			if sc.payloadType != payloadType {
+				continue
+			}
+			count++
+			if oldestOfType == nil || sc.lastUse < oldestOfType.lastUse {
+				oldestOfTypeID, oldestOfType = id, sc
+			}
+		}
+		switch {
+		case count >= MaxSchemaCacheSize:
+			c.releaseStreamConsumer(oldestOfTypeID, oldestOfType)
+		case len(c.streamConsumers) >= c.maxSchemas:
+			c.releaseStreamConsumer(oldestID, oldest)
+		default:
+			return
+		}
+	}
+}
+
+// releaseStreamConsumer releases and removes one stream consumer.
+func (c *Consumer) releaseStreamConsumer(id string, sc *streamConsumer) {
+	if sc.ipcReader != nil {
+		sc.ipcReader.Release()
+	}
+	delete(c.streamConsumers, id)
+}
+
+// releaseClosedStreamConsumers releases the stream consumers of the
+// schema IDs that the producer announced as closed, see
+// BatchArrowRecords.ClosedSchemaIds, instead of waiting for their
+// eviction, with their schemas and dictionaries.
+func (c *Consumer) releaseClosedStreamConsumers(ctx context.Context, ids []string) {
+	for _, id := range ids {
+		sc := c.streamConsumers[id]
+		if sc == nil {
+			continue
+		}
+		c.schemaCloseCounter.Add(ctx, 1, c.metricOpts(attribute.String("payload_type", sc.payloadType.String()))...)
+		c.releaseStreamConsumer(id, sc)
+	}
+}
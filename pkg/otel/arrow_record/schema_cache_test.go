@@ -0,0 +1,185 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/stretchr/testify/require"
+
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	carrow "github.com/open-telemetry/otel-arrow/pkg/arrow"
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/record_message"
+)
+
+// shapeRecord returns a record with one row, with a string column
+// for shape 0 and an additional integer column for shape 1.
+func shapeRecord(pool memory.Allocator, shape, row int) *record_message.RecordMessage {
+	fields := []arrow.Field{{Name: "key", Type: arrow.BinaryTypes.String}}
+	if shape == 1 {
+		fields = append(fields, arrow.Field{Name: "int", Type: arrow.PrimitiveTypes.Int64})
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	rb := array.NewRecordBuilder(pool, schema)
+	defer rb.Release()
+	rb.Field(0).(*array.StringBuilder).Append(fmt.Sprint("row ", row))
+	if shape == 1 {
+		rb.Field(1).(*array.Int64Builder).Append(int64(row))
+	}
+	return record_message.NewRelatedDataMessage(carrow.SchemaToID(schema), rb.NewRecord(), colarspb.ArrowPayloadType_RESOURCE_ATTRS)
+}
+
+func TestSchemaCache(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		cacheSize int
+		schemaIDs []string
+		created   uint64
+	}{
+		{"none", 0, []string{"0", "1", "2", "3", "4", "5"}, 6},
+		{"two", 2, []string{"0", "1", "0", "1", "0", "1"}, 2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer pool.AssertSize(t, 0)
+
+			producer := NewProducerWithOptions(
+				config.WithAllocator(pool),
+				config.WithSchemaCacheSize(test.cacheSize),
+			)
+			consumer := NewConsumer()
+
+			var schemaIDs []string
+			for row := 0; row < 6; row++ {
+				shape := row % 2
+				batch, err := producer.Produce([]*record_message.RecordMessage{shapeRecord(pool, shape, row)})
+				require.NoError(t, err)
+				require.Len(t, batch.ArrowPayloads, 1)
+				schemaIDs = append(schemaIDs, batch.ArrowPayloads[0].SchemaId)
+
+				records, err := consumer.Consume(batch)
+				require.NoError(t, err)
+				require.Len(t, records, 1)
+				record := records[0].Record()
+				require.Equal(t, int64(1+shape), record.NumCols())
+				require.Equal(t, fmt.Sprint("row ", row), record.Column(0).(*array.String).Value(0))
+				if shape == 1 {
+					require.Equal(t, int64(row), record.Column(1).(*array.Int64).Value(0))
+				}
+				releaseRecords(records)
+			}
+			require.Equal(t, test.schemaIDs, schemaIDs)
+
+			require.Equal(t, test.created, producer.GetAndResetStats().StreamProducersCreated)
+
+			require.NoError(t, producer.Close())
+			require.NoError(t, consumer.Close())
+		})
+	}
+}
+
+func TestConsumerMaxSchemas(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	newProducer := func() *Producer {
+		return NewProducerWithOptions(
+			config.WithAllocator(pool),
+			config.WithSchemaCacheSize(2),
+		)
+	}
+	producer := newProducer()
+	consumer := NewConsumer(WithMaxSchemas(1))
+
+	consume := func(shape, row int) error {
+		batch, err := producer.Produce([]*record_message.RecordMessage{shapeRecord(pool, shape, row)})
+		require.NoError(t, err)
+		records, err := consumer.Consume(batch)
+		if err != nil {
+			return err
+		}
+		require.Len(t, records, 1)
+		require.Equal(t, fmt.Sprint("row ", row), records[0].Record().Column(0).(*array.String).Value(0))
+		releaseRecords(records)
+		return nil
+	}
+
+	// The second shape evicts the stream of the first, which
+	// the producer continues.
+	require.NoError(t, consume(0, 0))
+	require.NoError(t, consume(1, 1))
+	require.Len(t, consumer.streamConsumers, 1)
+	require.ErrorIs(t, consume(0, 2), ErrSchemaReset)
+	require.Empty(t, consumer.streamConsumers)
+
+	// After a reset, the producer starts new streams.
+	require.NoError(t, producer.Close())
+	producer = newProducer()
+	require.NoError(t, consume(0, 3))
+	require.NoError(t, consume(0, 4))
+
+	require.NoError(t, producer.Close())
+	require.NoError(t, consumer.Close())
+}
+
+func TestClosedSchemaIDs(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	producer := NewProducerWithOptions(config.WithAllocator(pool))
+	consumer := NewConsumer()
+
+	consume := func(batch *colarspb.BatchArrowRecords) {
+		records, err := consumer.Consume(batch)
+		require.NoError(t, err)
+		releaseRecords(records)
+	}
+
+	// Each new shape closes the stream of the previous one, which
+	// the consumer releases after the batch.
+	var closed [][]string
+	for row := 0; row < 3; row++ {
+		batch, err := producer.Produce([]*record_message.RecordMessage{shapeRecord(pool, row%2, row)})
+		require.NoError(t, err)
+		closed = append(closed, batch.ClosedSchemaIds)
+		consume(batch)
+		require.Len(t, consumer.streamConsumers, 1)
+		require.Contains(t, consumer.streamConsumers, batch.ArrowPayloads[0].SchemaId)
+	}
+	require.Equal(t, [][]string{nil, {"0"}, {"1"}}, closed)
+
+	// A reset is announced with the next batch with payloads.
+	require.NoError(t, producer.closeStreamProducers())
+	var produced uint64
+	empty, err := producer.BatchArrowRecordsFromBuilt(emptyBuilt(&produced))
+	require.NoError(t, err)
+	require.Empty(t, empty.ClosedSchemaIds)
+
+	batch, err := producer.Produce([]*record_message.RecordMessage{shapeRecord(pool, 0, 3)})
+	require.NoError(t, err)
+	require.Equal(t, []string{"2"}, batch.ClosedSchemaIds)
+	consume(batch)
+	require.Len(t, consumer.streamConsumers, 1)
+	require.Contains(t, consumer.streamConsumers, "3")
+
+	require.NoError(t, producer.Close())
+	require.NoError(t, consumer.Close())
+}
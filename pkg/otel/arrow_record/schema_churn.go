@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/apache/arrow/go/v14/arrow"
+
+	carrow "github.com/open-telemetry/otel-arrow/pkg/arrow"
+	cfg "github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/constants"
+	"github.com/open-telemetry/otel-arrow/pkg/record_message"
+)
+
+// schemaChurnWindow is the number of recent batches in which the
+// schema changes of a payload type are counted, see
+// Config.SchemaChurnThreshold.
+const schemaChurnWindow = 100
+
+// schemaChurn tracks the schema changes of the payload types of a
+// producer.
+type schemaChurn struct {
+	// streamed records the payload types that had a stream, the
+	// first stream of a payload type is not a change.
+	streamed map[record_message.PayloadType]bool
+
+	// changes are the IDs of the batches that changed the schema
+	// of each payload type, within the window.
+	changes map[record_message.PayloadType][]int64
+
+	// keyTypes are the sets of value types, as bit sets, of the
+	// attribute keys of the records that changed the schema of
+	// each attribute payload type.
+	keyTypes map[record_message.PayloadType]map[string]uint16
+}
+
+func newSchemaChurn() *schemaChurn {
+	return &schemaChurn{
+		streamed: make(map[record_message.PayloadType]bool),
+		changes:  make(map[record_message.PayloadType][]int64),
+		keyTypes: make(map[record_message.PayloadType]map[string]uint16),
+	}
+}
+
+// observeSchemaChange is called when the record of batch batchId
+// starts a new stream for its payload type, and reports the payload
+// type when its schema changed Config.SchemaChurnThreshold times
+// within the window.
+func (p *Producer) observeSchemaChange(batchId int64, rm *record_message.RecordMessage) {
+	if p.conf.SchemaChurnThreshold <= 0 {
+		return
+	}
+	sc := p.schemaChurn
+	payloadType := rm.PayloadType()
+	if !sc.streamed[payloadType] {
+		sc.streamed[payloadType] = true
+		return
+	}
+
+	changes := append(sc.changes[payloadType], batchId)
+	for len(changes) != 0 && changes[0] <= batchId-schemaChurnWindow {
+		changes = changes[1:]
+	}
+	sc.changes[payloadType] = changes
+
+	attrs := strings.HasSuffix(payloadType.String(), "_ATTRS")
+	if attrs {
+		keyTypes := sc.keyTypes[payloadType]
+		if keyTypes == nil {
+			keyTypes = make(map[string]uint16)
+			sc.keyTypes[payloadType] = keyTypes
+		}
+		addKeyTypes(keyTypes, rm.Record())
+	}
+
+	if len(changes) < p.conf.SchemaChurnThreshold {
+		return
+	}
+
+	report := cfg.SchemaChurn{
+		PayloadType: payloadType.String(),
+		Changes:     len(changes),
+		Batches:     int(batchId-changes[0]) + 1,
+	}
+	for key, types := range sc.keyTypes[payloadType] {
+		if bits.OnesCount16(types) > 1 {
+			report.UnstableKeys = append(report.UnstableKeys, key)
+		}
+	}
+	sort.Strings(report.UnstableKeys)
+
+	// The next report follows as many changes.
+	delete(sc.changes, payloadType)
+	delete(sc.keyTypes, payloadType)
+
+	p.stats.SchemaChurnReports++
+	if p.conf.SchemaChurnReporter != nil {
+		p.conf.SchemaChurnReporter.ReportSchemaChurn(report)
+	}
+}
+
+// addKeyTypes adds the value type of each attribute of an attributes
+// record to the set of types of its key.  Records of an unexpected
+// structure are ignored, since the detection is best effort.
+func addKeyTypes(keyTypes map[string]uint16, record arrow.Record) {
+	keyID, err := carrow.FieldIDFromSchema(record.Schema(), constants.AttributeKey)
+	if err != nil || keyID == carrow.AbsentFieldID {
+		return
+	}
+	typeID, err := carrow.FieldIDFromSchema(record.Schema(), constants.AttributeType)
+	if err != nil || typeID == carrow.AbsentFieldID {
+		return
+	}
+	for row := 0; row < int(record.NumRows()); row++ {
+		key, err := carrow.StringFromRecord(record, keyID, row)
+		if err != nil {
+			return
+		}
+		typ, err := carrow.U8FromRecord(record, typeID, row)
+		if err != nil || typ >= 16 {
+			return
+		}
+		keyTypes[key] |= 1 << typ
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	carrow "github.com/open-telemetry/otel-arrow/pkg/arrow"
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/constants"
+	"github.com/open-telemetry/otel-arrow/pkg/record_message"
+)
+
+type testChurnReporter []config.SchemaChurn
+
+func (r *testChurnReporter) ReportSchemaChurn(report config.SchemaChurn) {
+	*r = append(*r, report)
+}
+
+// attrsRecord returns a span attributes record with one attribute
+// of each key, whose value is a string or an integer.
+func attrsRecord(pool memory.Allocator, keys []string, isInt bool) *record_message.RecordMessage {
+	valueField := arrow.Field{Name: constants.AttributeStr, Type: arrow.BinaryTypes.String}
+	valueType := pcommon.ValueTypeStr
+	if isInt {
+		valueField = arrow.Field{Name: constants.AttributeInt, Type: arrow.PrimitiveTypes.Int64}
+		valueType = pcommon.ValueTypeInt
+	}
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: constants.AttributeKey, Type: arrow.BinaryTypes.String},
+		{Name: constants.AttributeType, Type: arrow.PrimitiveTypes.Uint8},
+		valueField,
+	}, nil)
+
+	rb := array.NewRecordBuilder(pool, schema)
+	defer rb.Release()
+	for _, key := range keys {
+		rb.Field(0).(*array.StringBuilder).Append(key)
+		rb.Field(1).(*array.Uint8Builder).Append(uint8(valueType))
+		if isInt {
+			rb.Field(2).(*array.Int64Builder).Append(1)
+		} else {
+			rb.Field(2).(*array.StringBuilder).Append("1")
+		}
+	}
+	return record_message.NewRelatedDataMessage(carrow.SchemaToID(schema), rb.NewRecord(), colarspb.ArrowPayloadType_SPAN_ATTRS)
+}
+
+func TestSchemaChurn(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	var reports testChurnReporter
+	producer := NewProducerWithOptions(
+		config.WithAllocator(pool),
+		config.WithSchemaChurnDetection(3, &reports),
+	)
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+
+	// The "code" attribute alternates between a string and an
+	// integer, which changes the schema at each batch.  The
+	// first schema is not a change.
+	for i := 0; i < 4; i++ {
+		keys := []string{"code"}
+		if i%2 == 0 {
+			keys = append(keys, "name")
+		}
+		_, err := producer.Produce([]*record_message.RecordMessage{attrsRecord(pool, keys, i%2 == 1)})
+		require.NoError(t, err)
+	}
+	require.Equal(t, testChurnReporter{{
+		PayloadType:  "SPAN_ATTRS",
+		Changes:      3,
+		Batches:      3,
+		UnstableKeys: []string{"code"},
+	}}, reports)
+	require.EqualValues(t, 1, producer.GetAndResetStats().SchemaChurnReports)
+
+	// The next report follows as many changes.
+	for i := 0; i < 2; i++ {
+		_, err := producer.Produce([]*record_message.RecordMessage{attrsRecord(pool, []string{"code"}, i%2 == 0)})
+		require.NoError(t, err)
+	}
+	require.Len(t, reports, 1)
+}
+
+func TestSchemaChurnWindow(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	var reports testChurnReporter
+	producer := NewProducerWithOptions(
+		config.WithAllocator(pool),
+		config.WithSchemaChurnDetection(2, &reports),
+	)
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+
+	// Changes further apart than the window are not churn.
+	for i := 0; i < 3; i++ {
+		producer.batchId += schemaChurnWindow
+		_, err := producer.Produce([]*record_message.RecordMessage{attrsRecord(pool, []string{"code"}, i%2 == 1)})
+		require.NoError(t, err)
+	}
+	require.Empty(t, reports)
+}
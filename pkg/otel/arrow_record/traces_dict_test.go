@@ -77,6 +77,51 @@ func TestTracesWithNoDictionary(t *testing.T) {
 	require.Equal(t, 0, len(builder.Events().DictionariesWithOverflow))
 }
 
+// TestTracesWithNoDictionaryDeltas
+// Batches adding new values to the dictionaries are decoded from
+// replacement dictionaries.
+func TestTracesWithNoDictionaryDeltas(t *testing.T) {
+	t.Parallel()
+
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	producer := NewProducerWithOptions(
+		config.WithAllocator(pool),
+		config.WithNoDictionaryDeltas(),
+	)
+	defer func() {
+		if err := producer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	consumer := NewConsumer()
+	defer func() {
+		if err := consumer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	stdTesting := assert.NewStdUnitTest(t)
+
+	for i := 0; i < 10; i++ {
+		traces := GenerateTraces(i*10, 20)
+		batch, err := producer.BatchArrowRecordsFromTraces(traces)
+		require.NoError(t, err)
+		require.NotNil(t, batch)
+
+		received, err := consumer.TracesFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(received))
+
+		assert.Equiv(
+			stdTesting,
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(traces)},
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(received[0])},
+		)
+	}
+}
+
 // TestTracesMultiBatchWithDictionaryIndexChanges
 // Initial dictionary size uint8.
 // First batch of uint8 + 1 spans ==> dictionary overflow on 3 fields.
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+
+	"github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+	acommon "github.com/open-telemetry/otel-arrow/pkg/otel/common/arrow"
+)
+
+// TestProducerUnsupportedSpans verifies that a batch with more span
+// attributes than the encoding can address returns an
+// UnsupportedError, and that the producer encodes the next batch
+// correctly.
+func TestProducerUnsupportedSpans(t *testing.T) {
+	spans := func(count int) ptrace.Traces {
+		traces := ptrace.NewTraces()
+		ss := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+		for i := 0; i < count; i++ {
+			span := ss.AppendEmpty()
+			span.SetName("span")
+			span.Attributes().PutInt("index", int64(i))
+		}
+		return traces
+	}
+
+	producer := NewProducer()
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	consumer := NewConsumer()
+	defer func() {
+		require.NoError(t, consumer.Close())
+	}()
+
+	_, err := producer.BatchArrowRecordsFromTraces(spans(math.MaxUint16 + 2))
+	require.ErrorIs(t, err, acommon.ErrUnsupported)
+	var unsupported *acommon.UnsupportedError
+	require.True(t, errors.As(err, &unsupported))
+	require.Equal(t, "span-attrs", unsupported.Field)
+
+	traces := spans(10)
+	batch, err := producer.BatchArrowRecordsFromTraces(traces)
+	require.NoError(t, err)
+	received, err := consumer.TracesFrom(batch)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(received))
+	assert.Equiv(
+		assert.NewStdUnitTest(t),
+		[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(traces)},
+		[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(received[0])},
+	)
+}
@@ -0,0 +1,170 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// zstdOptions identifies the Zstd encoders and decoders that
+// producers and consumers can share.
+type zstdOptions struct {
+	// concurrency is the number of payloads that can be encoded
+	// or decoded at once, zero for GOMAXPROCS.
+	concurrency int
+	// window is the window size of an encoder, or the maximum
+	// window size of a decoder, zero for the library default.
+	window uint64
+	// level is the compression level of an encoder, zero for the
+	// library default.
+	level int
+	// memLimit is the maximum decoded size of a payload.
+	memLimit uint64
+	// dicts is the dictionary of an encoder, or the dictionaries
+	// of a decoder joined by joinDictionaries.
+	dicts string
+}
+
+// sharedCodec is a reference-counted encoder or decoder.
+type sharedCodec[T any] struct {
+	codec T
+	refs  int
+}
+
+// codecCache holds one encoder or decoder per set of options.  The
+// Zstd encoders and decoders are safe for concurrent use, so every
+// producer or consumer with identical options uses the same instance
+// for all of its batches, instead of constructing one per stream or,
+// as Arrow IPC-level compression does, one per record.
+type codecCache[T any] struct {
+	lock   sync.Mutex
+	codecs map[zstdOptions]*sharedCodec[T]
+	create func(zstdOptions) (T, error)
+	close  func(T)
+}
+
+var (
+	zstdEncoders = &codecCache[*zstd.Encoder]{
+		codecs: map[zstdOptions]*sharedCodec[*zstd.Encoder]{},
+		create: newZstdEncoder,
+		close:  func(e *zstd.Encoder) { _ = e.Close() },
+	}
+	zstdDecoders = &codecCache[*zstd.Decoder]{
+		codecs: map[zstdOptions]*sharedCodec[*zstd.Decoder]{},
+		create: newZstdDecoder,
+		close:  func(d *zstd.Decoder) { d.Close() },
+	}
+)
+
+// acquire returns the codec for the options, creating it if
+// necessary.  Each successful call must be paired with release.
+func (c *codecCache[T]) acquire(opts zstdOptions) (T, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if sc, ok := c.codecs[opts]; ok {
+		sc.refs++
+		return sc.codec, nil
+	}
+	codec, err := c.create(opts)
+	if err != nil {
+		return codec, err
+	}
+	c.codecs[opts] = &sharedCodec[T]{codec: codec, refs: 1}
+	return codec, nil
+}
+
+// release closes the codec for the options once it is no longer in
+// use.
+func (c *codecCache[T]) release(opts zstdOptions) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	sc, ok := c.codecs[opts]
+	if !ok {
+		return
+	}
+	sc.refs--
+	if sc.refs == 0 {
+		delete(c.codecs, opts)
+		c.close(sc.codec)
+	}
+}
+
+// has returns true when the codec for the options is in use, for
+// testing.
+func (c *codecCache[T]) has(opts zstdOptions) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, ok := c.codecs[opts]
+	return ok
+}
+
+func newZstdEncoder(opts zstdOptions) (*zstd.Encoder, error) {
+	concurrency := opts.concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	zopts := []zstd.EOption{
+		zstd.WithEncoderConcurrency(concurrency),
+	}
+	if opts.window != 0 {
+		zopts = append(zopts, zstd.WithWindowSize(int(opts.window)))
+	}
+	if opts.level != 0 {
+		zopts = append(zopts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(opts.level)))
+	}
+	if opts.dicts != "" {
+		zopts = append(zopts, zstd.WithEncoderDict([]byte(opts.dicts)))
+	}
+	return zstd.NewWriter(nil, zopts...)
+}
+
+func newZstdDecoder(opts zstdOptions) (*zstd.Decoder, error) {
+	zopts := []zstd.DOption{
+		zstd.WithDecoderConcurrency(opts.concurrency),
+		// Limits the decoded size of one payload.
+		zstd.WithDecoderMaxMemory(opts.memLimit),
+	}
+	if opts.window != 0 {
+		zopts = append(zopts, zstd.WithDecoderMaxWindow(opts.window))
+	}
+	var dicts [][]byte
+	for rest := opts.dicts; rest != ""; {
+		// Each dictionary is prefixed by its length, see
+		// joinDictionaries.
+		n := int(rest[0])<<24 | int(rest[1])<<16 | int(rest[2])<<8 | int(rest[3])
+		dicts = append(dicts, []byte(rest[4:4+n]))
+		rest = rest[4+n:]
+	}
+	if len(dicts) != 0 {
+		zopts = append(zopts, zstd.WithDecoderDicts(dicts...))
+	}
+	return zstd.NewReader(nil, zopts...)
+}
+
+// joinDictionaries returns the dictionaries as one string, each
+// prefixed by its 32-bit length, for use in zstdOptions.
+func joinDictionaries(dicts [][]byte) string {
+	var sb strings.Builder
+	for _, d := range dicts {
+		n := len(d)
+		sb.Write([]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)})
+		sb.Write(d)
+	}
+	return sb.String()
+}
@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
+)
+
+// TestProducerZstdDictionary verifies that payloads compressed with
+// a pre-trained dictionary are decoded by a consumer that has it.
+func TestProducerZstdDictionary(t *testing.T) {
+	ent := datagen.NewTestEntropy(12345)
+	dg := datagen.NewTracesGenerator(
+		ent,
+		ent.NewStandardResourceAttributes(),
+		ent.NewStandardInstrumentationScopes(),
+	)
+	stdTesting := assert.NewStdUnitTest(t)
+
+	// Train on the payloads of short, uncompressed streams.
+	var samples [][]byte
+	for i := 0; i < 20; i++ {
+		producer := NewProducerWithOptions(config.WithNoZstd())
+		for j := 0; j < 3; j++ {
+			batch, err := producer.BatchArrowRecordsFromTraces(dg.Generate(5, 100))
+			require.NoError(t, err)
+			for _, payload := range batch.ArrowPayloads {
+				samples = append(samples, payload.Record)
+			}
+		}
+		require.NoError(t, producer.Close())
+	}
+	dict, err := zstddict.Train(samples, 16<<10)
+	require.NoError(t, err)
+	_, err = zstddict.ID(dict)
+	require.NoError(t, err)
+
+	producer := NewProducerWithOptions(config.WithZstdDictionary(dict))
+	defer func() {
+		require.NoError(t, producer.Close())
+	}()
+	consumer := NewConsumer(WithZstdDictionaries(dict))
+	defer func() {
+		require.NoError(t, consumer.Close())
+	}()
+
+	for i := 0; i < 3; i++ {
+		traces := dg.Generate(5, 100)
+		batch, err := producer.BatchArrowRecordsFromTraces(traces)
+		require.NoError(t, err)
+		for _, payload := range batch.ArrowPayloads {
+			require.True(t, zstddict.IsFrame(payload.Record))
+		}
+
+		received, err := consumer.TracesFrom(batch)
+		require.NoError(t, err)
+		require.Len(t, received, 1)
+
+		assert.Equiv(
+			stdTesting,
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(traces)},
+			[]json.Marshaler{ptraceotlp.NewExportRequestFromTraces(received[0])},
+		)
+	}
+
+	// A consumer without the dictionary cannot decode the stream.
+	other := NewProducerWithOptions(config.WithZstdDictionary(dict))
+	defer func() {
+		require.NoError(t, other.Close())
+	}()
+	batch, err := other.BatchArrowRecordsFromTraces(dg.Generate(5, 100))
+	require.NoError(t, err)
+
+	plain := NewConsumer()
+	defer func() {
+		require.NoError(t, plain.Close())
+	}()
+	_, err = plain.TracesFrom(batch)
+	require.Error(t, err)
+}
@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow_record
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
+)
+
+// TestZstdSharedCodecs verifies that producers and consumers with
+// identical settings share one encoder and one decoder, which are
+// released with the last of them.
+func TestZstdSharedCodecs(t *testing.T) {
+	p1 := NewProducerWithOptions(config.WithPayloadZstd())
+	p2 := NewProducerWithOptions(config.WithPayloadZstd())
+	p3 := NewProducerWithOptions(config.WithPayloadZstd(), config.WithZstdConcurrency(1), config.WithZstdWindowSize(1<<20))
+	p4 := NewProducerWithOptions(config.WithNoZstd())
+	require.Same(t, p1.zstdEncoder, p2.zstdEncoder)
+	require.NotSame(t, p1.zstdEncoder, p3.zstdEncoder)
+	require.Nil(t, p4.zstdEncoder)
+
+	c1 := NewConsumer()
+	c2 := NewConsumer()
+	c3 := NewConsumer(WithZstdDecoderConcurrency(1), WithZstdMaxWindow(1<<20))
+	c4 := NewConsumer()
+
+	producers := []*Producer{p1, p2, p3, p4}
+	consumers := []*Consumer{c1, c2, c3, c4}
+	for i := 0; i < 3; i++ {
+		for j, p := range producers {
+			batch, err := p.BatchArrowRecordsFromTraces(GenerateTraces(i*10+j, 10))
+			require.NoError(t, err)
+			for _, payload := range batch.ArrowPayloads {
+				require.Equal(t, p != p4, zstddict.IsFrame(payload.Record))
+			}
+			traces, err := consumers[j].TracesFrom(batch)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(traces))
+			require.Equal(t, 10, traces[0].SpanCount())
+		}
+	}
+	require.Same(t, c1.zstdDecoder, c2.zstdDecoder)
+	require.NotSame(t, c1.zstdDecoder, c3.zstdDecoder)
+	// The consumer of uncompressed payloads has no decoder.
+	require.Nil(t, c4.zstdDecoder)
+	require.True(t, zstdEncoders.has(p3.zstdOptions))
+	require.True(t, zstdDecoders.has(c3.zstdOptions))
+	encoderOptions, decoderOptions := p3.zstdOptions, c3.zstdOptions
+
+	for j, p := range producers {
+		require.NoError(t, p.Close())
+		require.NoError(t, consumers[j].Close())
+	}
+	require.False(t, zstdEncoders.has(encoderOptions))
+	require.False(t, zstdDecoders.has(decoderOptions))
+}
+
+// TestZstdLevel verifies that producers with different levels use
+// different encoders, whose payloads consumers decode alike.
+func TestZstdLevel(t *testing.T) {
+	fastest := NewProducerWithOptions(config.WithPayloadZstd(), config.WithZstdLevel(1))
+	best := NewProducerWithOptions(config.WithPayloadZstd(), config.WithZstdLevel(19))
+	defer fastest.Close()
+	defer best.Close()
+	require.NotSame(t, fastest.zstdEncoder, best.zstdEncoder)
+
+	for _, p := range []*Producer{fastest, best} {
+		consumer := NewConsumer()
+		defer consumer.Close()
+		batch, err := p.BatchArrowRecordsFromTraces(GenerateTraces(0, 10))
+		require.NoError(t, err)
+		traces, err := consumer.TracesFrom(batch)
+		require.NoError(t, err)
+		require.Equal(t, 10, traces[0].SpanCount())
+	}
+}
+
+// TestZstdIPCDefault verifies that Zstd compresses at the IPC level
+// unless payload compression is configured, so that consumers of
+// earlier releases decode the payloads.
+func TestZstdIPCDefault(t *testing.T) {
+	producer := NewProducer()
+	defer producer.Close()
+	require.Nil(t, producer.zstdEncoder)
+
+	consumer := NewConsumer()
+	defer consumer.Close()
+	batch, err := producer.BatchArrowRecordsFromTraces(GenerateTraces(0, 10))
+	require.NoError(t, err)
+	for _, payload := range batch.ArrowPayloads {
+		require.False(t, zstddict.IsFrame(payload.Record))
+	}
+	traces, err := consumer.TracesFrom(batch)
+	require.NoError(t, err)
+	require.Equal(t, 10, traces[0].SpanCount())
+	// IPC-level compression needs no payload decoder.
+	require.Nil(t, consumer.zstdDecoder)
+}
+
+// BenchmarkProducerZstd measures the cost of compressing small
+// batches, for which constructing an encoder per batch dominates.
+func BenchmarkProducerZstd(b *testing.B) {
+	producer := NewProducerWithOptions(config.WithPayloadZstd())
+	defer producer.Close()
+	traces := GenerateTraces(0, 10)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := producer.BatchArrowRecordsFromTraces(traces); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/apache/arrow/go/v14/arrow/memory"
+)
+
+// DefaultBufferAlignment is the alignment recommended by the Arrow
+// columnar format, one cache line of 64 bytes.
+const DefaultBufferAlignment = 64
+
+// AlignedAllocator is a memory.Allocator that returns buffers whose
+// address is a multiple of an alignment and whose capacity is padded
+// to a multiple of the alignment with zeroes, whatever the underlying
+// allocator guarantees.  Arrays built on such buffers can be handed
+// to C Data Interface consumers that require aligned, padded buffers
+// (e.g., SIMD kernels) without copying them.
+//
+// An AlignedAllocator is safe for concurrent use.
+type AlignedAllocator struct {
+	allocator memory.Allocator
+	alignment int
+
+	lock sync.Mutex
+	// allocs maps the address of each aligned buffer to the
+	// allocation of the underlying allocator it was cut from.
+	allocs map[uintptr][]byte
+}
+
+var _ memory.Allocator = &AlignedAllocator{}
+
+// NewAlignedAllocator returns an allocator that aligns buffers to the
+// given alignment, which is rounded up to a power of two.  Values
+// below 1 select DefaultBufferAlignment.
+func NewAlignedAllocator(allocator memory.Allocator, alignment int) *AlignedAllocator {
+	if alignment < 1 {
+		alignment = DefaultBufferAlignment
+	}
+	for alignment&(alignment-1) != 0 {
+		alignment += alignment & -alignment
+	}
+	return &AlignedAllocator{
+		allocator: allocator,
+		alignment: alignment,
+		allocs:    map[uintptr][]byte{},
+	}
+}
+
+// Alignment returns the alignment of the buffers.
+func (a *AlignedAllocator) Alignment() int {
+	return a.alignment
+}
+
+// padded rounds size up to a multiple of the alignment, at least one.
+func (a *AlignedAllocator) padded(size int) int {
+	return max((size+a.alignment-1)&^(a.alignment-1), a.alignment)
+}
+
+// Allocate implements memory.Allocator.  Like the Go allocator, it
+// returns zeroed memory.
+func (a *AlignedAllocator) Allocate(size int) []byte {
+	padded := a.padded(size)
+	raw := a.allocator.Allocate(padded + a.alignment - 1)
+
+	addr := uintptr(unsafe.Pointer(unsafe.SliceData(raw)))
+	shift := int(-addr & uintptr(a.alignment-1))
+	buf := raw[shift : shift+padded : shift+padded]
+	clear(buf)
+
+	a.lock.Lock()
+	a.allocs[addr+uintptr(shift)] = raw
+	a.lock.Unlock()
+	return buf[:size]
+}
+
+// Reallocate implements memory.Allocator.
+func (a *AlignedAllocator) Reallocate(size int, b []byte) []byte {
+	if size <= cap(b) {
+		if size < len(b) {
+			// Keep the padding zeroed.
+			clear(b[size:])
+		}
+		return b[:size]
+	}
+	nb := a.Allocate(size)
+	copy(nb, b)
+	a.Free(b)
+	return nb
+}
+
+// Free implements memory.Allocator.
+func (a *AlignedAllocator) Free(b []byte) {
+	addr := uintptr(unsafe.Pointer(unsafe.SliceData(b)))
+
+	a.lock.Lock()
+	raw, ok := a.allocs[addr]
+	delete(a.allocs, addr)
+	a.lock.Unlock()
+
+	if ok {
+		a.allocator.Free(raw)
+	}
+}
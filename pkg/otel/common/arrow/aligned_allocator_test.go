@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/stretchr/testify/require"
+)
+
+// misalignedAllocator returns buffers at odd addresses, filled with
+// garbage.
+type misalignedAllocator struct {
+	memory.Allocator
+}
+
+func (m misalignedAllocator) Allocate(size int) []byte {
+	b := m.Allocator.Allocate(size + 1)[1:]
+	for i := range b {
+		b[i] = 0xff
+	}
+	return b
+}
+
+func requireAligned(t *testing.T, b []byte, alignment int) {
+	require.Zero(t, uintptr(unsafe.Pointer(unsafe.SliceData(b)))%uintptr(alignment))
+	require.Zero(t, cap(b)%alignment)
+	require.Equal(t, make([]byte, cap(b)-len(b)), b[len(b):cap(b)])
+}
+
+func TestAlignedAllocator(t *testing.T) {
+	check := memory.NewCheckedAllocator(misalignedAllocator{memory.NewGoAllocator()})
+	aligned := NewAlignedAllocator(check, 100)
+	require.Equal(t, 128, aligned.Alignment())
+	require.Equal(t, DefaultBufferAlignment, NewAlignedAllocator(check, 0).Alignment())
+
+	for _, size := range []int{0, 1, 127, 128, 1000} {
+		b := aligned.Allocate(size)
+		require.Len(t, b, size)
+		require.Equal(t, make([]byte, size), b)
+		requireAligned(t, b, 128)
+		aligned.Free(b)
+	}
+	check.AssertSize(t, 0)
+
+	// Shrinking zeroes the padding, growing beyond the capacity
+	// moves the contents.
+	b := aligned.Allocate(100)
+	for i := range b {
+		b[i] = 1
+	}
+	b = aligned.Reallocate(10, b)
+	requireAligned(t, b, 128)
+	b = aligned.Reallocate(300, b)
+	require.Len(t, b, 300)
+	require.Equal(t, byte(1), b[9])
+	require.Equal(t, byte(0), b[10])
+	requireAligned(t, b, 128)
+
+	aligned.Free(b)
+	check.AssertSize(t, 0)
+	require.Empty(t, aligned.allocs)
+}
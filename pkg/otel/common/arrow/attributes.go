@@ -104,6 +104,9 @@ type (
 		attrsMapCount uint16
 		attrs         []Attr16
 		sorter        Attrs16Sorter
+
+		// field names the attributes in errors, e.g., "span-attrs".
+		field string
 	}
 
 	// Attributes32Accumulator accumulates attributes for the scope of an entire
@@ -113,6 +116,9 @@ type (
 		attrsMapCount uint32
 		attrs         []Attr32
 		sorter        Attrs32Sorter
+
+		// field names the attributes in errors, e.g., "span-attrs".
+		field string
 	}
 )
 
@@ -210,7 +216,7 @@ func (c *Attributes16Accumulator) Append(attrs pcommon.Map) (int64, error) {
 	}
 
 	if c.attrsMapCount == math.MaxUint16 {
-		panic("The maximum number of group of attributes has been reached (max is uint16).")
+		return -1, werror.Wrap(NewLimitError(c.field, math.MaxUint16))
 	}
 
 	attrs.Range(func(k string, v pcommon.Value) bool {
@@ -233,7 +239,7 @@ func (c *Attributes16Accumulator) AppendWithID(parentID uint16, attrs pcommon.Ma
 	}
 
 	if c.attrsMapCount == math.MaxUint16 {
-		panic("The maximum number of group of attributes has been reached (max is uint16).")
+		return werror.Wrap(NewLimitError(c.field, math.MaxUint16))
 	}
 
 	attrs.Range(func(key string, v pcommon.Value) bool {
@@ -287,7 +293,7 @@ func (c *Attributes32Accumulator) Append(ID uint32, attrs pcommon.Map) error {
 	}
 
 	if c.attrsMapCount == math.MaxUint32 {
-		panic("The maximum number of group of attributes has been reached (max is uint32).")
+		return werror.Wrap(NewLimitError(c.field, math.MaxUint32))
 	}
 
 	attrs.Range(func(key string, v pcommon.Value) bool {
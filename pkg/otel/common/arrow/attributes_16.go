@@ -98,6 +98,7 @@ func NewAttrs16BuilderWithEncoding(rBuilder *builder.RecordBuilderExt, payloadTy
 		payloadType: payloadType,
 	}
 
+	b.accumulator.field = payloadType.prefix
 	b.init()
 	return b
 }
@@ -119,6 +119,7 @@ func NewAttrs32Builder(rBuilder *builder.RecordBuilderExt, payloadType *PayloadT
 		accumulator: NewAttributes32Accumulator(sorter),
 		payloadType: payloadType,
 	}
+	b.accumulator.field = payloadType.prefix
 	b.init()
 	return b
 }
@@ -131,6 +132,7 @@ func NewAttrs32BuilderWithEncoding(rBuilder *builder.RecordBuilderExt, payloadTy
 		payloadType: payloadType,
 	}
 
+	b.accumulator.field = payloadType.prefix
 	b.init()
 	return b
 }
@@ -17,10 +17,42 @@
 
 package arrow
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrBuilderAlreadyReleased = errors.New("builder already released")
 	ErrInvalidResourceID      = errors.New("invalid resource ID")
 	ErrInvalidScopeID         = errors.New("invalid scope ID")
+	ErrUnsupported            = errors.New("unsupported by the OTel Arrow encoding")
 )
+
+// UnsupportedError is returned by the builders for OTLP data that the
+// Arrow encoding cannot represent, e.g., a batch with more spans than
+// the IDs of its related records can address.  It matches
+// ErrUnsupported.
+type UnsupportedError struct {
+	// Field names the offending OTLP field, e.g., "span events".
+	Field string
+	// Reason tells why the field cannot be encoded.
+	Reason string
+}
+
+// NewLimitError returns an UnsupportedError for a batch with more
+// than limit items of a field.
+func NewLimitError(field string, limit uint64) *UnsupportedError {
+	return &UnsupportedError{
+		Field:  field,
+		Reason: fmt.Sprintf("more than %d per batch, reduce the batch size", limit),
+	}
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrUnsupported.Error(), e.Field, e.Reason)
+}
+
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
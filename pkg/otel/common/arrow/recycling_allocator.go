@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import (
+	"math/bits"
+	"sync"
+
+	"github.com/apache/arrow/go/v14/arrow/memory"
+)
+
+const (
+	// minRecycledBits is the smallest size class, 1KiB.  Smaller
+	// buffers are allocated directly.
+	minRecycledBits = 10
+	// maxRecycledBits is the largest size class, 16MiB.  Larger
+	// buffers are allocated directly.
+	maxRecycledBits = 24
+)
+
+// RecyclingAllocator is a memory.Allocator that retains the buffers
+// freed by released Arrow arrays, so that the builders of the next
+// batch reuse them instead of allocating.  Record builders hand their
+// buffers over to the arrays they build, so without recycling each
+// batch allocates all of its column buffers anew.
+//
+// Buffers are kept by power-of-two size class, up to a limit on the
+// total size retained; buffers freed beyond the limit are returned to
+// the underlying allocator.  A RecyclingAllocator is safe for
+// concurrent use.
+type RecyclingAllocator struct {
+	allocator memory.Allocator
+	limit     uint64
+
+	lock     sync.Mutex
+	free     [maxRecycledBits + 1][][]byte
+	retained uint64
+	reused   uint64
+}
+
+var _ memory.Allocator = &RecyclingAllocator{}
+
+// NewRecyclingAllocator returns an allocator that retains up to limit
+// bytes of freed buffers for reuse.
+func NewRecyclingAllocator(allocator memory.Allocator, limit uint64) *RecyclingAllocator {
+	return &RecyclingAllocator{
+		allocator: allocator,
+		limit:     limit,
+	}
+}
+
+// recycledClass returns the size class of a buffer of the given size,
+// or -1 if buffers of this size are not recycled.
+func recycledClass(size int) int {
+	if size <= 0 {
+		return -1
+	}
+	class := bits.Len(uint(size - 1))
+	if class < minRecycledBits {
+		return -1
+	}
+	if class > maxRecycledBits {
+		return -1
+	}
+	return class
+}
+
+// Allocate implements memory.Allocator.  Like the Go allocator, it
+// returns zeroed memory.
+func (r *RecyclingAllocator) Allocate(size int) []byte {
+	class := recycledClass(size)
+	if class < 0 {
+		return r.allocator.Allocate(size)
+	}
+	r.lock.Lock()
+	if n := len(r.free[class]); n != 0 {
+		buf := r.free[class][n-1]
+		r.free[class][n-1] = nil
+		r.free[class] = r.free[class][:n-1]
+		r.retained -= uint64(cap(buf))
+		r.reused++
+		r.lock.Unlock()
+
+		buf = buf[:size]
+		clear(buf)
+		return buf
+	}
+	r.lock.Unlock()
+	return r.allocator.Allocate(1 << class)[:size]
+}
+
+// Reallocate implements memory.Allocator.
+func (r *RecyclingAllocator) Reallocate(size int, b []byte) []byte {
+	if size <= cap(b) && recycledClass(cap(b)) >= 0 {
+		if size > len(b) {
+			clear(b[len(b):size])
+		}
+		return b[:size]
+	}
+	nb := r.Allocate(size)
+	copy(nb, b)
+	r.Free(b)
+	return nb
+}
+
+// Free implements memory.Allocator.
+func (r *RecyclingAllocator) Free(b []byte) {
+	b = b[:cap(b)]
+	class := recycledClass(len(b))
+	if class < 0 || len(b) != 1<<class {
+		r.allocator.Free(b)
+		return
+	}
+	r.lock.Lock()
+	if r.retained+uint64(len(b)) > r.limit {
+		r.lock.Unlock()
+		r.allocator.Free(b)
+		return
+	}
+	r.free[class] = append(r.free[class], b)
+	r.retained += uint64(len(b))
+	r.lock.Unlock()
+}
+
+// Retained returns the number of bytes retained for reuse.
+func (r *RecyclingAllocator) Retained() uint64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.retained
+}
+
+// Reused returns the number of allocations served by a retained
+// buffer.
+func (r *RecyclingAllocator) Reused() uint64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.reused
+}
+
+// Purge returns the retained buffers to the underlying allocator.
+func (r *RecyclingAllocator) Purge() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for class := range r.free {
+		for i, buf := range r.free[class] {
+			r.allocator.Free(buf)
+			r.free[class][i] = nil
+		}
+		r.free[class] = nil
+	}
+	r.retained = 0
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecyclingAllocator(t *testing.T) {
+	check := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	recycler := NewRecyclingAllocator(check, 8192)
+
+	// Small buffers are not recycled.
+	small := recycler.Allocate(100)
+	require.Len(t, small, 100)
+	recycler.Free(small)
+	require.Equal(t, uint64(0), recycler.Retained())
+	check.AssertSize(t, 0)
+
+	b := recycler.Allocate(3000)
+	require.Len(t, b, 3000)
+	require.Equal(t, 4096, cap(b))
+	for i := range b {
+		b[i] = 0xff
+	}
+	recycler.Free(b)
+	require.Equal(t, uint64(4096), recycler.Retained())
+
+	// The retained buffer is reused, zeroed.
+	c := recycler.Allocate(4000)
+	require.Equal(t, &b[0], &c[0])
+	require.Equal(t, make([]byte, 4000), c)
+	require.Equal(t, uint64(0), recycler.Retained())
+	require.Equal(t, uint64(1), recycler.Reused())
+
+	// Growing within capacity keeps the buffer and zeroes the
+	// extension.
+	c[200] = 0xff
+	c = recycler.Reallocate(100, c)
+	c = recycler.Reallocate(4096, c)
+	require.Equal(t, &b[0], &c[0])
+	require.Equal(t, make([]byte, 4096), c)
+
+	// Growing beyond capacity moves to the next size class.
+	c[0] = 1
+	c = recycler.Reallocate(5000, c)
+	require.Equal(t, 8192, cap(c))
+	require.Equal(t, byte(1), c[0])
+	require.Equal(t, uint64(4096), recycler.Retained())
+
+	// Buffers beyond the retention limit are freed.
+	recycler.Free(c)
+	require.Equal(t, uint64(4096), recycler.Retained())
+
+	recycler.Purge()
+	require.Equal(t, uint64(0), recycler.Retained())
+	check.AssertSize(t, 0)
+}
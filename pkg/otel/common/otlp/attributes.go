@@ -18,6 +18,8 @@
 package otlp
 
 import (
+	"bytes"
+
 	"github.com/apache/arrow/go/v14/arrow"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 
@@ -51,8 +53,7 @@ type (
 	// attributes are attached. So the maximum number of attributes per entity
 	// is not limited.
 	Attributes16Store struct {
-		lastID         uint16
-		attributesByID map[uint16]*pcommon.Map
+		attributesStore[uint16]
 	}
 
 	// Attributes32Store is a store for attributes.
@@ -61,8 +62,49 @@ type (
 	// attributes are attached. So the maximum number of attributes per entity
 	// is not limited.
 	Attributes32Store struct {
-		lastID         uint32
-		attributesByID map[uint32]*pcommon.Map
+		attributesStore[uint32]
+	}
+
+	// attributesStore indexes the rows of attributes records by
+	// parent ID.  The records are retained, and CopyAttributesByID
+	// decodes the attributes of a parent ID directly into the
+	// destination map of their entity, without an intermediate map.
+	// Every attribute of the entities built is still decoded and
+	// its strings copied.
+	attributesStore[T uint16 | uint32] struct {
+		lastID  T
+		records []attributesRecord
+		// rowsByID are the rows of the attributes by parent ID.
+		rowsByID       map[T][]attributeRow
+		attributesByID map[T]*pcommon.Map
+	}
+
+	// attributesRecord is a retained attributes record.
+	attributesRecord struct {
+		record arrow.Record
+		ids    *AttributeIDs
+		// serialized are the deserialized map and slice values by
+		// row.  They are decoded when the store is built, in order
+		// to report invalid values.
+		serialized map[int]pcommon.Value
+	}
+
+	// attributeRow is a row of one of the records of a store.
+	attributeRow struct {
+		record int32
+		row    int32
+	}
+
+	// attributeValue is the value of an attribute read from an
+	// attributes record.  Its strings and bytes share the record's
+	// buffers.
+	attributeValue struct {
+		vType pcommon.ValueType
+		str   string
+		i64   int64
+		f64   float64
+		bool  bool
+		bytes []byte
 	}
 
 	Attrs16ParentIdDecoder struct {
@@ -83,159 +125,192 @@ type (
 // NewAttributes16Store creates a new Attributes16Store.
 func NewAttributes16Store() *Attributes16Store {
 	return &Attributes16Store{
-		attributesByID: make(map[uint16]*pcommon.Map),
+		attributesStore: newAttributesStore[uint16](),
 	}
 }
 
 // NewAttributes32Store creates a new Attributes32Store.
 func NewAttributes32Store() *Attributes32Store {
 	return &Attributes32Store{
-		attributesByID: make(map[uint32]*pcommon.Map),
+		attributesStore: newAttributesStore[uint32](),
 	}
 }
 
+func newAttributesStore[T uint16 | uint32]() attributesStore[T] {
+	return attributesStore[T]{
+		rowsByID:       make(map[T][]attributeRow),
+		attributesByID: make(map[T]*pcommon.Map),
+	}
+}
+
+// Reserve pre-sizes the maps of an empty store for n parent IDs,
+// see CapacityHints.
+func (s *attributesStore[T]) Reserve(n int) {
+	if n <= 0 || len(s.rowsByID) != 0 {
+		return
+	}
+	s.rowsByID = make(map[T][]attributeRow, n)
+	s.attributesByID = make(map[T]*pcommon.Map, n)
+}
+
+// Len returns the number of parent IDs with attributes.
+func (s *attributesStore[T]) Len() int {
+	return len(s.rowsByID)
+}
+
 // AttributesByDeltaID returns the attributes for the given Delta ID.
-func (s *Attributes16Store) AttributesByDeltaID(ID uint16) *pcommon.Map {
+func (s *attributesStore[T]) AttributesByDeltaID(ID T) *pcommon.Map {
 	s.lastID += ID
-	if m, ok := s.attributesByID[s.lastID]; ok {
-		return m
-	}
-	return nil
+	return s.AttributesByID(s.lastID)
 }
 
 // AttributesByID returns the attributes for the given ID.
-func (s *Attributes16Store) AttributesByID(ID uint16) *pcommon.Map {
+func (s *attributesStore[T]) AttributesByID(ID T) *pcommon.Map {
 	if m, ok := s.attributesByID[ID]; ok {
 		return m
 	}
-	return nil
+	rows, ok := s.rowsByID[ID]
+	if !ok {
+		return nil
+	}
+	m := pcommon.NewMap()
+	s.putRows(rows, m)
+	s.attributesByID[ID] = &m
+	return &m
 }
 
-// AttributesByID returns the attributes for the given ID.
-func (s *Attributes32Store) AttributesByID(ID uint32) *pcommon.Map {
+// CopyAttributesByDeltaID decodes the attributes for the given Delta
+// ID into dest, see CopyAttributesByID.
+func (s *attributesStore[T]) CopyAttributesByDeltaID(ID T, dest pcommon.Map) {
+	s.lastID += ID
+	s.CopyAttributesByID(s.lastID, dest)
+}
+
+// CopyAttributesByID decodes the attributes for the given ID directly
+// into dest, which is expected to be empty, without the intermediate
+// map returned by AttributesByID.
+func (s *attributesStore[T]) CopyAttributesByID(ID T, dest pcommon.Map) {
 	if m, ok := s.attributesByID[ID]; ok {
-		return m
+		m.CopyTo(dest)
+		return
+	}
+	if rows, ok := s.rowsByID[ID]; ok {
+		s.putRows(rows, dest)
 	}
-	return nil
 }
 
-// AttributesByDeltaID returns the attributes for the given Delta ID.
-func (s *Attributes32Store) AttributesByDeltaID(ID uint32) *pcommon.Map {
-	s.lastID += ID
-	if m, ok := s.attributesByID[s.lastID]; ok {
-		return m
+// putRows decodes the attributes of rows into m.
+func (s *attributesStore[T]) putRows(rows []attributeRow, m pcommon.Map) {
+	m.EnsureCapacity(len(rows))
+	for _, r := range rows {
+		rec := &s.records[r.record]
+		// The rows have been read once when the store was built,
+		// so they can't fail.
+		key, _ := arrowutils.StringFromRecord(rec.record, rec.ids.Key, int(r.row))
+		value, _ := attributeValueFrom(rec.record, rec.ids, int(r.row))
+		_ = rec.put(m, key, value, int(r.row))
 	}
-	return nil
 }
 
-// Attributes16StoreFrom creates an Attribute16Store from an arrow.Record.
-// Note: This function doesn't release the record passed as argument. This is
-// the responsibility of the caller
-func Attributes16StoreFrom(record arrow.Record, store *Attributes16Store) error {
+// Release releases the records retained by the store.  The attributes
+// already accessed remain valid.
+func (s *attributesStore[T]) Release() {
+	for _, rec := range s.records {
+		rec.record.Release()
+	}
+	s.records = nil
+	s.rowsByID = make(map[T][]attributeRow)
+}
+
+// add indexes the rows of an attributes record by parent ID, given
+// the function reading the delta or parent ID of a row.
+func (s *attributesStore[T]) add(record arrow.Record, parentIDFrom func(record arrow.Record, fieldID int, row int) (T, error)) error {
 	attrIDS, err := SchemaToAttributeIDs(record.Schema())
 	if err != nil {
 		return werror.Wrap(err)
 	}
 
-	attrsCount := int(record.NumRows())
+	rec := attributesRecord{record: record, ids: attrIDS}
+	recIdx := int32(len(s.records))
 
-	parentIdDecoder := NewAttrs16ParentIdDecoder()
+	var prevParentID T
+	var prevKey string
+	var prevValue attributeValue
+	first := true
 
-	// Read all key/value tuples from the record and reconstruct the attributes
-	// map by ID.
+	attrsCount := int(record.NumRows())
 	for i := 0; i < attrsCount; i++ {
 		key, err := arrowutils.StringFromRecord(record, attrIDS.Key, i)
 		if err != nil {
 			return werror.Wrap(err)
 		}
-
-		vType, err := arrowutils.U8FromRecord(record, attrIDS.Type, i)
+		value, err := attributeValueFrom(record, attrIDS, i)
 		if err != nil {
 			return werror.Wrap(err)
 		}
-
-		value := pcommon.NewValueEmpty()
-		switch pcommon.ValueType(vType) {
-		case pcommon.ValueTypeStr:
-			v, err := arrowutils.StringFromRecord(record, attrIDS.Str, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			value.SetStr(v)
-		case pcommon.ValueTypeInt:
-			v, err := arrowutils.I64FromRecord(record, attrIDS.Int, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			value.SetInt(v)
-		case pcommon.ValueTypeDouble:
-			v, err := arrowutils.F64FromRecord(record, attrIDS.Double, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			value.SetDouble(v)
-		case pcommon.ValueTypeBool:
-			v, err := arrowutils.BoolFromRecord(record, attrIDS.Bool, i)
+		if value.vType == pcommon.ValueTypeMap || value.vType == pcommon.ValueTypeSlice {
+			v, err := value.deserialize()
 			if err != nil {
 				return werror.Wrap(err)
 			}
-			value.SetBool(v)
-		case pcommon.ValueTypeBytes:
-			v, err := arrowutils.BinaryFromRecord(record, attrIDS.Bytes, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			value.SetEmptyBytes().FromRaw(v)
-		case pcommon.ValueTypeSlice:
-			v, err := arrowutils.BinaryFromRecord(record, attrIDS.Ser, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			if err = common.Deserialize(v, value); err != nil {
-				return werror.Wrap(err)
-			}
-		case pcommon.ValueTypeMap:
-			v, err := arrowutils.BinaryFromRecord(record, attrIDS.Ser, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			if err = common.Deserialize(v, value); err != nil {
-				return werror.Wrap(err)
+			if rec.serialized == nil {
+				rec.serialized = make(map[int]pcommon.Value)
 			}
-		default:
-			// silently ignore unknown types to avoid DOS attacks
+			rec.serialized[i] = v
 		}
 
-		deltaOrParentID, err := arrowutils.U16FromRecord(record, attrIDS.ParentID, i)
+		deltaOrParentID, err := parentIDFrom(record, attrIDS.ParentID, i)
 		if err != nil {
 			return werror.Wrap(err)
 		}
-		parentID := parentIdDecoder.Decode(deltaOrParentID, key, &value)
-
-		m, ok := store.attributesByID[parentID]
-		if !ok {
-			newMap := pcommon.NewMap()
-			m = &newMap
-			store.attributesByID[parentID] = m
+		// Decoded as by Attrs16ParentIdDecoder.
+		parentID := deltaOrParentID
+		if !first && prevKey == key && value.equal(&prevValue) {
+			parentID = prevParentID + deltaOrParentID
+		} else {
+			prevKey = key
+			prevValue = value
 		}
-		value.CopyTo(m.PutEmpty(key))
+		prevParentID = parentID
+		first = false
+
+		s.rowsByID[parentID] = append(s.rowsByID[parentID], attributeRow{record: recIdx, row: int32(i)})
 	}
 
+	record.Retain()
+	s.records = append(s.records, rec)
 	return nil
 }
 
-// Attributes32StoreFrom creates an Attributes32Store from an arrow.Record.
+// Attributes16StoreFrom creates an Attribute16Store from an arrow.Record.
+// The store retains the record until it is released.
 // Note: This function doesn't release the record passed as argument. This is
 // the responsibility of the caller
-func Attributes32StoreFrom(record arrow.Record, store *Attributes32Store) error {
+func Attributes16StoreFrom(record arrow.Record, store *Attributes16Store) error {
+	return store.add(record, arrowutils.U16FromRecord)
+}
+
+// Attributes16Into decodes the attributes of an arrow.Record directly
+// into the maps returned by dest for their parent ID, avoiding the
+// intermediate maps of an Attributes16Store when the destination maps
+// are known.  The attributes of a parent ID for which dest returns
+// false are ignored.
+// Note: This function doesn't release the record passed as argument. This is
+// the responsibility of the caller
+func Attributes16Into(record arrow.Record, dest func(parentID uint16) (pcommon.Map, bool)) error {
 	attrIDS, err := SchemaToAttributeIDs(record.Schema())
 	if err != nil {
 		return werror.Wrap(err)
 	}
 
-	attrsCount := int(record.NumRows())
+	rec := attributesRecord{record: record, ids: attrIDS}
 
-	parentIdDecoder := NewAttrs32ParentIdDecoder()
+	var prevParentID uint16
+	var prevKey string
+	var prevValue attributeValue
+	first := true
+
+	attrsCount := int(record.NumRows())
 
 	// Read all key/value tuples from the record and reconstruct the attributes
 	// map by ID.
@@ -244,78 +319,136 @@ func Attributes32StoreFrom(record arrow.Record, store *Attributes32Store) error
 		if err != nil {
 			return werror.Wrap(err)
 		}
+		value, err := attributeValueFrom(record, attrIDS, i)
+		if err != nil {
+			return werror.Wrap(err)
+		}
 
-		vType, err := arrowutils.U8FromRecord(record, attrIDS.Type, i)
+		deltaOrParentID, err := arrowutils.U16FromRecord(record, attrIDS.ParentID, i)
 		if err != nil {
 			return werror.Wrap(err)
 		}
-		value := pcommon.NewValueEmpty()
-		switch pcommon.ValueType(vType) {
-		case pcommon.ValueTypeStr:
-			v, err := arrowutils.StringFromRecord(record, attrIDS.Str, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			value.SetStr(v)
-		case pcommon.ValueTypeInt:
-			v, err := arrowutils.I64FromRecord(record, attrIDS.Int, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			value.SetInt(v)
-		case pcommon.ValueTypeDouble:
-			v, err := arrowutils.F64FromRecord(record, attrIDS.Double, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			value.SetDouble(v)
-		case pcommon.ValueTypeBool:
-			v, err := arrowutils.BoolFromRecord(record, attrIDS.Bool, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			value.SetBool(v)
-		case pcommon.ValueTypeBytes:
-			v, err := arrowutils.BinaryFromRecord(record, attrIDS.Bytes, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			value.SetEmptyBytes().FromRaw(v)
-		case pcommon.ValueTypeSlice:
-			v, err := arrowutils.BinaryFromRecord(record, attrIDS.Ser, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			if err = common.Deserialize(v, value); err != nil {
-				return werror.Wrap(err)
-			}
-		case pcommon.ValueTypeMap:
-			v, err := arrowutils.BinaryFromRecord(record, attrIDS.Ser, i)
-			if err != nil {
-				return werror.Wrap(err)
-			}
-			if err = common.Deserialize(v, value); err != nil {
+		parentID := deltaOrParentID
+		if !first && prevKey == key && value.equal(&prevValue) {
+			parentID = prevParentID + deltaOrParentID
+		} else {
+			prevKey = key
+			prevValue = value
+		}
+		prevParentID = parentID
+		first = false
+
+		if m, ok := dest(parentID); ok {
+			if err := rec.put(m, key, value, i); err != nil {
 				return werror.Wrap(err)
 			}
-		default:
-			// silently ignore unknown types to avoid DOS attacks
 		}
+	}
 
-		deltaOrParentID, err := arrowutils.U32FromRecord(record, attrIDS.ParentID, i)
-		if err != nil {
-			return werror.Wrap(err)
-		}
-		parentID := parentIdDecoder.Decode(deltaOrParentID, key, &value)
+	return nil
+}
+
+// Attributes32StoreFrom creates an Attributes32Store from an arrow.Record.
+// The store retains the record until it is released.
+// Note: This function doesn't release the record passed as argument. This is
+// the responsibility of the caller
+func Attributes32StoreFrom(record arrow.Record, store *Attributes32Store) error {
+	return store.add(record, arrowutils.U32FromRecord)
+}
+
+// attributeValueFrom reads the value of an attribute without copying
+// its strings and bytes.  Map and slice values are left serialized.
+func attributeValueFrom(record arrow.Record, attrIDS *AttributeIDs, row int) (value attributeValue, err error) {
+	vType, err := arrowutils.U8FromRecord(record, attrIDS.Type, row)
+	if err != nil {
+		return value, werror.Wrap(err)
+	}
 
-		m, ok := store.attributesByID[parentID]
+	value.vType = pcommon.ValueType(vType)
+	switch value.vType {
+	case pcommon.ValueTypeStr:
+		value.str, err = arrowutils.StringFromRecord(record, attrIDS.Str, row)
+	case pcommon.ValueTypeInt:
+		value.i64, err = arrowutils.I64FromRecord(record, attrIDS.Int, row)
+	case pcommon.ValueTypeDouble:
+		value.f64, err = arrowutils.F64FromRecord(record, attrIDS.Double, row)
+	case pcommon.ValueTypeBool:
+		value.bool, err = arrowutils.BoolFromRecord(record, attrIDS.Bool, row)
+	case pcommon.ValueTypeBytes:
+		value.bytes, err = arrowutils.BinaryFromRecord(record, attrIDS.Bytes, row)
+	case pcommon.ValueTypeSlice, pcommon.ValueTypeMap:
+		value.bytes, err = arrowutils.BinaryFromRecord(record, attrIDS.Ser, row)
+	default:
+		// silently ignore unknown types to avoid DOS attacks
+		value.vType = pcommon.ValueTypeEmpty
+	}
+	if err != nil {
+		return value, werror.Wrap(err)
+	}
+	return value, nil
+}
+
+// equal returns true if both values are equal scalars, as compared by
+// carrow.Equal for the decoding of parent IDs.
+func (v *attributeValue) equal(o *attributeValue) bool {
+	if v.vType != o.vType {
+		return false
+	}
+	switch v.vType {
+	case pcommon.ValueTypeStr:
+		return v.str == o.str
+	case pcommon.ValueTypeInt:
+		return v.i64 == o.i64
+	case pcommon.ValueTypeDouble:
+		return v.f64 == o.f64
+	case pcommon.ValueTypeBool:
+		return v.bool == o.bool
+	case pcommon.ValueTypeBytes:
+		return bytes.Equal(v.bytes, o.bytes)
+	default:
+		return false
+	}
+}
+
+// deserialize decodes a map or slice value.
+func (v *attributeValue) deserialize() (pcommon.Value, error) {
+	value := pcommon.NewValueEmpty()
+	if err := common.Deserialize(v.bytes, value); err != nil {
+		return value, werror.Wrap(err)
+	}
+	return value, nil
+}
+
+// put copies an attribute of the record into a map.  Bytes are copied
+// since they share the record's buffers.
+func (r *attributesRecord) put(m pcommon.Map, key string, value attributeValue, row int) error {
+	switch value.vType {
+	case pcommon.ValueTypeStr:
+		m.PutStr(key, value.str)
+	case pcommon.ValueTypeInt:
+		m.PutInt(key, value.i64)
+	case pcommon.ValueTypeDouble:
+		m.PutDouble(key, value.f64)
+	case pcommon.ValueTypeBool:
+		m.PutBool(key, value.bool)
+	case pcommon.ValueTypeBytes:
+		// FromRaw copies the bytes.  Empty bytes are kept non-nil,
+		// as by Value.CopyTo, so that they are marshaled.
+		v := m.PutEmpty(key)
+		pcommon.NewValueBytes().CopyTo(v)
+		v.Bytes().FromRaw(value.bytes)
+	case pcommon.ValueTypeSlice, pcommon.ValueTypeMap:
+		v, ok := r.serialized[row]
 		if !ok {
-			newMap := pcommon.NewMap()
-			m = &newMap
-			store.attributesByID[parentID] = m
+			var err error
+			if v, err = value.deserialize(); err != nil {
+				return werror.Wrap(err)
+			}
 		}
-		value.CopyTo(m.PutEmpty(key))
+		v.CopyTo(m.PutEmpty(key))
+	default:
+		m.PutEmpty(key)
 	}
-
 	return nil
 }
 
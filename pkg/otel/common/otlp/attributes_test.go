@@ -0,0 +1,174 @@
+/*
+ * Copyright The OpenTelemetry Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package otlp
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/open-telemetry/otel-arrow/pkg/otel/common"
+	carrow "github.com/open-telemetry/otel-arrow/pkg/otel/common/arrow"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/constants"
+)
+
+type testAttr struct {
+	parentID uint16
+	key      string
+	value    pcommon.Value
+}
+
+// testAttributesRecord returns an attributes record with delta group
+// encoded parent IDs, the attributes being sorted by key and value.
+func testAttributesRecord(t *testing.T, pool memory.Allocator, attrs []testAttr) arrow.Record {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: constants.ParentID, Type: arrow.PrimitiveTypes.Uint16},
+		{Name: constants.AttributeKey, Type: arrow.BinaryTypes.String},
+		{Name: constants.AttributeType, Type: arrow.PrimitiveTypes.Uint8},
+		{Name: constants.AttributeStr, Type: arrow.BinaryTypes.String},
+		{Name: constants.AttributeInt, Type: arrow.PrimitiveTypes.Int64},
+		{Name: constants.AttributeBytes, Type: arrow.BinaryTypes.Binary},
+		{Name: constants.AttributeSer, Type: arrow.BinaryTypes.Binary},
+	}, nil)
+	rb := array.NewRecordBuilder(pool, schema)
+	defer rb.Release()
+
+	parentIDs := rb.Field(0).(*array.Uint16Builder)
+	keys := rb.Field(1).(*array.StringBuilder)
+	types := rb.Field(2).(*array.Uint8Builder)
+	strs := rb.Field(3).(*array.StringBuilder)
+	ints := rb.Field(4).(*array.Int64Builder)
+	bins := rb.Field(5).(*array.BinaryBuilder)
+	sers := rb.Field(6).(*array.BinaryBuilder)
+
+	for i, attr := range attrs {
+		parentID := attr.parentID
+		if i > 0 {
+			prev := attrs[i-1]
+			if prev.key == attr.key && carrow.Equal(&prev.value, &attr.value) {
+				parentID -= prev.parentID
+			}
+		}
+		parentIDs.Append(parentID)
+		keys.Append(attr.key)
+		types.Append(uint8(attr.value.Type()))
+
+		var ser []byte
+		if attr.value.Type() == pcommon.ValueTypeMap || attr.value.Type() == pcommon.ValueTypeSlice {
+			b, err := common.Serialize(&attr.value)
+			require.NoError(t, err)
+			ser = b
+		}
+		appendOrNull(strs, attr.value.Type() == pcommon.ValueTypeStr, func() { strs.Append(attr.value.Str()) })
+		appendOrNull(ints, attr.value.Type() == pcommon.ValueTypeInt, func() { ints.Append(attr.value.Int()) })
+		appendOrNull(bins, attr.value.Type() == pcommon.ValueTypeBytes, func() { bins.Append(attr.value.Bytes().AsRaw()) })
+		appendOrNull(sers, ser != nil, func() { sers.Append(ser) })
+	}
+	return rb.NewRecord()
+}
+
+func appendOrNull(b array.Builder, present bool, appendValue func()) {
+	if present {
+		appendValue()
+	} else {
+		b.AppendNull()
+	}
+}
+
+func TestAttributes16StoreLazy(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+
+	kvlist := pcommon.NewValueMap()
+	kvlist.Map().PutStr("nested", "value")
+
+	attrs := []testAttr{
+		{0, "bytes", pcommon.NewValueBytes()},
+		{0, "int", pcommon.NewValueInt(1)},
+		{1, "int", pcommon.NewValueInt(1)},
+		{3, "int", pcommon.NewValueInt(1)},
+		{1, "kvlist", kvlist},
+		{0, "str", pcommon.NewValueStr("a")},
+		{1, "str", pcommon.NewValueStr("a")},
+		{3, "str", pcommon.NewValueStr("b")},
+	}
+	attrs[0].value.Bytes().FromRaw([]byte("raw"))
+
+	record := testAttributesRecord(t, pool, attrs)
+	store := NewAttributes16Store()
+	require.NoError(t, Attributes16StoreFrom(record, store))
+	// The store retains the record.
+	record.Release()
+
+	// Nothing is decoded until accessed.
+	require.Empty(t, store.attributesByID)
+
+	expected := map[uint16]map[string]any{
+		0: {"bytes": []byte("raw"), "int": int64(1), "str": "a"},
+		1: {"int": int64(1), "kvlist": map[string]any{"nested": "value"}, "str": "a"},
+		3: {"int": int64(1), "str": "b"},
+	}
+	require.Equal(t, expected[1], store.AttributesByID(1).AsRaw())
+	require.Len(t, store.attributesByID, 1)
+	require.Equal(t, expected[0], store.AttributesByDeltaID(0).AsRaw())
+	require.Nil(t, store.AttributesByDeltaID(2))
+	require.Equal(t, expected[3], store.AttributesByDeltaID(1).AsRaw())
+
+	// The attributes are decoded directly into the destination,
+	// without an intermediate map.
+	direct := NewAttributes16Store()
+	require.NoError(t, Attributes16StoreFrom(record, direct))
+	dest := pcommon.NewMap()
+	direct.CopyAttributesByID(1, dest)
+	require.Equal(t, expected[1], dest.AsRaw())
+	dest = pcommon.NewMap()
+	direct.CopyAttributesByDeltaID(3, dest)
+	require.Equal(t, expected[3], dest.AsRaw())
+	dest = pcommon.NewMap()
+	direct.CopyAttributesByID(2, dest)
+	require.Equal(t, 0, dest.Len())
+	require.Empty(t, direct.attributesByID)
+	direct.Release()
+
+	// The attributes accessed remain valid after the release.
+	store.Release()
+	require.Equal(t, expected[1], store.AttributesByID(1).AsRaw())
+
+	// Attributes16Into decodes the same attributes.
+	record = testAttributesRecord(t, pool, attrs)
+	defer record.Release()
+	into := map[uint16]pcommon.Map{}
+	require.NoError(t, Attributes16Into(record, func(parentID uint16) (pcommon.Map, bool) {
+		if parentID == 3 {
+			return pcommon.Map{}, false
+		}
+		m, ok := into[parentID]
+		if !ok {
+			m = pcommon.NewMap()
+			into[parentID] = m
+		}
+		return m, true
+	}))
+	require.Len(t, into, 2)
+	require.Equal(t, expected[0], into[0].AsRaw())
+	require.Equal(t, expected[1], into[1].AsRaw())
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright The OpenTelemetry Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package otlp
+
+import (
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+)
+
+// capacityHintWindow is the number of recent decodes whose sizes are
+// remembered for each payload type.
+const capacityHintWindow = 8
+
+type (
+	// CapacityHints remembers, by payload type, the number of
+	// entities decoded from the recent batches of a stream, e.g.,
+	// the number of parent IDs of an attributes record.  Decoders
+	// pre-size the maps and slices of the next batch accordingly,
+	// so that streams with consistent batch shapes do not grow them
+	// one entry at a time.  The hint is the largest of the recent
+	// sizes, so that it follows a stream whose batches shrink.  A
+	// nil *CapacityHints gives no hints.
+	//
+	// CapacityHints is not safe for concurrent use, like the
+	// consumer of the stream that owns it.
+	CapacityHints struct {
+		sizes map[colarspb.ArrowPayloadType]*recentSizes
+	}
+
+	// recentSizes is a ring of the sizes of the recent decodes.
+	recentSizes struct {
+		sizes [capacityHintWindow]int
+		next  int
+	}
+)
+
+// NewCapacityHints returns hints without history.
+func NewCapacityHints() *CapacityHints {
+	return &CapacityHints{
+		sizes: make(map[colarspb.ArrowPayloadType]*recentSizes),
+	}
+}
+
+// Hint returns the capacity to reserve for the payload type, zero
+// without history.
+func (h *CapacityHints) Hint(payloadType colarspb.ArrowPayloadType) int {
+	if h == nil {
+		return 0
+	}
+	rs := h.sizes[payloadType]
+	if rs == nil {
+		return 0
+	}
+	hint := 0
+	for _, size := range rs.sizes {
+		hint = max(hint, size)
+	}
+	return hint
+}
+
+// Observe records the size decoded for the payload type.
+func (h *CapacityHints) Observe(payloadType colarspb.ArrowPayloadType, size int) {
+	if h == nil {
+		return
+	}
+	rs := h.sizes[payloadType]
+	if rs == nil {
+		rs = &recentSizes{}
+		h.sizes[payloadType] = rs
+	}
+	rs.sizes[rs.next] = size
+	rs.next = (rs.next + 1) % capacityHintWindow
+}
+
+// ReservableStore is a store that CapacityHints pre-size, e.g.,
+// Attributes16Store and Attributes32Store.
+type ReservableStore interface {
+	// Reserve pre-sizes an empty store for n entities.
+	Reserve(n int)
+	// Len returns the number of entities of the store.
+	Len() int
+}
+
+// ReserveStores pre-sizes the stores of the payload types.
+func (h *CapacityHints) ReserveStores(stores map[colarspb.ArrowPayloadType]ReservableStore) {
+	if h == nil {
+		return
+	}
+	for payloadType, store := range stores {
+		store.Reserve(h.Hint(payloadType))
+	}
+}
+
+// ObserveStores records the sizes of the stores of the payload
+// types, once built.
+func (h *CapacityHints) ObserveStores(stores map[colarspb.ArrowPayloadType]ReservableStore) {
+	if h == nil {
+		return
+	}
+	for payloadType, store := range stores {
+		h.Observe(payloadType, store.Len())
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright The OpenTelemetry Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package otlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+)
+
+func TestCapacityHints(t *testing.T) {
+	const pt = colarspb.ArrowPayloadType_SPAN_ATTRS
+
+	var none *CapacityHints
+	none.Observe(pt, 10)
+	require.Equal(t, 0, none.Hint(pt))
+
+	hints := NewCapacityHints()
+	require.Equal(t, 0, hints.Hint(pt))
+
+	hints.Observe(pt, 10)
+	hints.Observe(pt, 3)
+	require.Equal(t, 10, hints.Hint(pt))
+	require.Equal(t, 0, hints.Hint(colarspb.ArrowPayloadType_SPANS))
+
+	// The largest size is forgotten after the window.
+	for i := 1; i < capacityHintWindow; i++ {
+		hints.Observe(pt, 3)
+	}
+	require.Equal(t, 3, hints.Hint(pt))
+}
+
+func TestCapacityHintsStores(t *testing.T) {
+	const pt = colarspb.ArrowPayloadType_SPAN_ATTRS
+
+	hints := NewCapacityHints()
+	store := NewAttributes16Store()
+	stores := map[colarspb.ArrowPayloadType]ReservableStore{pt: store}
+
+	store.rowsByID[1] = []attributeRow{{}}
+	store.rowsByID[2] = []attributeRow{{}}
+	hints.ObserveStores(stores)
+	require.Equal(t, 2, hints.Hint(pt))
+
+	// Only empty stores are reserved.
+	hints.ReserveStores(stores)
+	require.Equal(t, 2, store.Len())
+
+	store = NewAttributes16Store()
+	hints.ReserveStores(map[colarspb.ArrowPayloadType]ReservableStore{pt: store})
+	require.Equal(t, 0, store.Len())
+	require.NotNil(t, store.attributesByID)
+}
@@ -73,10 +73,7 @@ func UpdateResourceFromRecord(r pcommon.Resource, record arrow.Record, row int,
 		return "", werror.WrapWithContext(err, map[string]interface{}{"row": row})
 	}
 	if ID != nil {
-		attrs := attrsStore.AttributesByDeltaID(*ID)
-		if attrs != nil {
-			attrs.CopyTo(r.Attributes())
-		}
+		attrsStore.CopyAttributesByDeltaID(*ID, r.Attributes())
 	}
 	return
 }
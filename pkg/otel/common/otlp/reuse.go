@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// The decoders can decode into pdata released by an earlier batch,
+// in which case they reuse its elements by position instead of
+// appending new ones.  Each reused element is reset before it is
+// decoded again, which keeps the capacity of its slices and maps, and
+// the elements left over are truncated once decoded.
+
+// ReusableSlice is the subset of the pdata slice API used to reuse
+// elements, e.g., ptrace.SpanSlice with E = ptrace.Span.
+type ReusableSlice[E any] interface {
+	Len() int
+	At(int) E
+	AppendEmpty() E
+	RemoveIf(func(E) bool)
+}
+
+var (
+	emptyResource = pcommon.NewResource()
+	emptyScope    = pcommon.NewInstrumentationScope()
+)
+
+// ElementAt returns the i-th element of s, reset, or appends an empty
+// one if s has i elements.
+func ElementAt[E any, S ReusableSlice[E]](s S, i int, reset func(E)) E {
+	if i < s.Len() {
+		e := s.At(i)
+		reset(e)
+		return e
+	}
+	return s.AppendEmpty()
+}
+
+// Truncate removes the elements of s beyond the first n.
+func Truncate[E any, S ReusableSlice[E]](s S, n int) {
+	if s.Len() <= n {
+		return
+	}
+	i := 0
+	s.RemoveIf(func(E) bool {
+		i++
+		return i > n
+	})
+}
+
+// ResetResource clears r, keeping the capacity of its attributes.
+func ResetResource(r pcommon.Resource) {
+	emptyResource.CopyTo(r)
+}
+
+// ResetScope clears s, keeping the capacity of its attributes.
+func ResetScope(s pcommon.InstrumentationScope) {
+	emptyScope.CopyTo(s)
+}
@@ -79,10 +79,7 @@ func UpdateScopeFromRecord(
 		return werror.WrapWithContext(err, map[string]interface{}{"row": row})
 	}
 	if ID != nil {
-		attrs := attrsStore.AttributesByDeltaID(*ID)
-		if attrs != nil {
-			attrs.CopyTo(s.Attributes())
-		}
+		attrsStore.CopyAttributesByDeltaID(*ID, s.Attributes())
 	}
 	s.SetName(name)
 	s.SetVersion(version)
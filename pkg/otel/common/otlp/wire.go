@@ -0,0 +1,421 @@
+/*
+ * Copyright The OpenTelemetry Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package otlp
+
+// Encoding of Arrow records directly into the OTLP protobuf wire
+// format, without constructing pdata.  The output is identical to
+// the pdata marshaler's: fields are appended in increasing field
+// number order, and the fields that pdata always marshals (e.g.,
+// non-nullable messages) are appended even when empty.
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	arrowutils "github.com/open-telemetry/otel-arrow/pkg/arrow"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/common"
+	"github.com/open-telemetry/otel-arrow/pkg/werror"
+)
+
+// Field numbers of the OTLP AnyValue message.
+const (
+	AnyValueString protowire.Number = 1
+	AnyValueBool   protowire.Number = 2
+	AnyValueInt    protowire.Number = 3
+	AnyValueDouble protowire.Number = 4
+	AnyValueArray  protowire.Number = 5
+	AnyValueKvlist protowire.Number = 6
+	AnyValueBytes  protowire.Number = 7
+)
+
+// Field numbers of the other OTLP common messages.
+const (
+	keyValueKey   = 1
+	keyValueValue = 2
+
+	arrayValueValues  = 1
+	kvlistValueValues = 1
+
+	resourceAttributes             = 1
+	resourceDroppedAttributesCount = 2
+
+	scopeName                   = 1
+	scopeVersion                = 2
+	scopeAttributes             = 3
+	scopeDroppedAttributesCount = 4
+)
+
+// Field numbers of the attributes of the OTLP Resource and
+// InstrumentationScope messages, for Attributes16WireFrom.
+const (
+	ResourceAttributesField protowire.Number = resourceAttributes
+	ScopeAttributesField    protowire.Number = scopeAttributes
+)
+
+// Attributes16Wire holds the attributes of an attributes record,
+// encoded as repeated OTLP KeyValue fields, by parent ID.
+type Attributes16Wire struct {
+	lastID uint16
+	byID   [][]byte
+}
+
+// AttributesByDeltaID returns the encoded attributes for the given
+// Delta ID.
+func (s *Attributes16Wire) AttributesByDeltaID(ID uint16) []byte {
+	if s == nil {
+		return nil
+	}
+	s.lastID += ID
+	return s.AttributesByID(s.lastID)
+}
+
+// AttributesByID returns the encoded attributes for the given ID.
+func (s *Attributes16Wire) AttributesByID(ID uint16) []byte {
+	if s == nil || int(ID) >= len(s.byID) {
+		return nil
+	}
+	return s.byID[ID]
+}
+
+// wireValue is an attribute value read from an attributes record.
+// Values of type Slice and Map are deserialized into pdata, as they
+// are CBOR-encoded in the record.
+type wireValue struct {
+	typ     pcommon.ValueType
+	str     string
+	integer int64
+	double  float64
+	boolean bool
+	bin     []byte
+	value   pcommon.Value
+}
+
+// equal has the semantics of arrow.Equal for pcommon.Values.
+func (v *wireValue) equal(o *wireValue) bool {
+	if v.typ != o.typ {
+		return false
+	}
+	switch v.typ {
+	case pcommon.ValueTypeStr:
+		return v.str == o.str
+	case pcommon.ValueTypeInt:
+		return v.integer == o.integer
+	case pcommon.ValueTypeDouble:
+		return v.double == o.double
+	case pcommon.ValueTypeBool:
+		return v.boolean == o.boolean
+	case pcommon.ValueTypeBytes:
+		return bytes.Equal(v.bin, o.bin)
+	default:
+		return false
+	}
+}
+
+// appendAnyValue appends the fields of the OTLP AnyValue message
+// holding the value.
+func (v *wireValue) appendAnyValue(b []byte) []byte {
+	switch v.typ {
+	case pcommon.ValueTypeStr:
+		b = protowire.AppendTag(b, AnyValueString, protowire.BytesType)
+		b = protowire.AppendString(b, v.str)
+	case pcommon.ValueTypeInt:
+		b = protowire.AppendTag(b, AnyValueInt, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.integer))
+	case pcommon.ValueTypeDouble:
+		b = protowire.AppendTag(b, AnyValueDouble, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(v.double))
+	case pcommon.ValueTypeBool:
+		b = protowire.AppendTag(b, AnyValueBool, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(v.boolean))
+	case pcommon.ValueTypeBytes:
+		b = protowire.AppendTag(b, AnyValueBytes, protowire.BytesType)
+		b = protowire.AppendBytes(b, v.bin)
+	case pcommon.ValueTypeSlice, pcommon.ValueTypeMap:
+		b = appendAnyValue(b, v.value, true)
+	}
+	return b
+}
+
+// AppendAnyValue appends the fields of the OTLP AnyValue message
+// holding a pdata value.
+func AppendAnyValue(b []byte, v pcommon.Value) []byte {
+	return appendAnyValue(b, v, false)
+}
+
+// appendAnyValue appends the fields of an AnyValue message.  pdata
+// marshals a bytes value unless nil, which an empty value decoded
+// from a record is unless copied, as attribute values are by
+// Attributes16StoreFrom.
+func appendAnyValue(b []byte, v pcommon.Value, copied bool) []byte {
+	switch v.Type() {
+	case pcommon.ValueTypeStr:
+		b = protowire.AppendTag(b, AnyValueString, protowire.BytesType)
+		b = protowire.AppendString(b, v.Str())
+	case pcommon.ValueTypeInt:
+		b = protowire.AppendTag(b, AnyValueInt, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.Int()))
+	case pcommon.ValueTypeDouble:
+		b = protowire.AppendTag(b, AnyValueDouble, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(v.Double()))
+	case pcommon.ValueTypeBool:
+		b = protowire.AppendTag(b, AnyValueBool, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(v.Bool()))
+	case pcommon.ValueTypeBytes:
+		if raw := v.Bytes().AsRaw(); raw != nil || copied {
+			b = protowire.AppendTag(b, AnyValueBytes, protowire.BytesType)
+			b = protowire.AppendBytes(b, raw)
+		}
+	case pcommon.ValueTypeSlice:
+		var start int
+		b, start = BeginMessage(b, AnyValueArray)
+		s := v.Slice()
+		for i := 0; i < s.Len(); i++ {
+			var elem int
+			b, elem = BeginMessage(b, arrayValueValues)
+			b = appendAnyValue(b, s.At(i), copied)
+			b = EndMessage(b, elem)
+		}
+		b = EndMessage(b, start)
+	case pcommon.ValueTypeMap:
+		var start int
+		b, start = BeginMessage(b, AnyValueKvlist)
+		v.Map().Range(func(k string, v pcommon.Value) bool {
+			var kv int
+			b, kv = BeginMessage(b, kvlistValueValues)
+			b = appendKey(b, k)
+			var value int
+			b, value = BeginMessage(b, keyValueValue)
+			b = appendAnyValue(b, v, copied)
+			b = EndMessage(b, value)
+			b = EndMessage(b, kv)
+			return true
+		})
+		b = EndMessage(b, start)
+	}
+	return b
+}
+
+func appendKey(b []byte, key string) []byte {
+	if len(key) > 0 {
+		b = protowire.AppendTag(b, keyValueKey, protowire.BytesType)
+		b = protowire.AppendString(b, key)
+	}
+	return b
+}
+
+// BeginMessage appends the tag of an embedded message field and a
+// placeholder for its length, and returns the offset of the message.
+// The message is appended next, then finished by EndMessage.
+func BeginMessage(b []byte, field protowire.Number) ([]byte, int) {
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	b = append(b, 0)
+	return b, len(b)
+}
+
+// EndMessage writes the length of the message begun at offset start,
+// which is moved if its length doesn't fit in the placeholder.
+func EndMessage(b []byte, start int) []byte {
+	size := len(b) - start
+	n := protowire.SizeVarint(uint64(size))
+	if n > 1 {
+		b = append(b, make([]byte, n-1)...)
+		copy(b[start+n-1:], b[start:start+size])
+	}
+	protowire.AppendVarint(b[:start-1], uint64(size))
+	return b
+}
+
+// AppendString appends a string field, unless empty.
+func AppendString(b []byte, field protowire.Number, s string) []byte {
+	if len(s) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// AppendUint32 appends a uint32 field, unless zero.
+func AppendUint32(b []byte, field protowire.Number, v uint32) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, field, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+// Attributes16WireFrom encodes the attributes of an arrow.Record as
+// OTLP KeyValue fields with the given field number, by parent ID.
+// Note: This function doesn't release the record passed as argument. This is
+// the responsibility of the caller
+func Attributes16WireFrom(record arrow.Record, field protowire.Number) (*Attributes16Wire, error) {
+	attrIDS, err := SchemaToAttributeIDs(record.Schema())
+	if err != nil {
+		return nil, werror.Wrap(err)
+	}
+
+	store := &Attributes16Wire{}
+	attrsCount := int(record.NumRows())
+
+	// The parent IDs are decoded as by Attrs16ParentIdDecoder, with
+	// the delta group encoding.
+	var prevParentID uint16
+	var prevKey string
+	var values [2]wireValue
+	var prevValue *wireValue
+
+	for i := 0; i < attrsCount; i++ {
+		key, err := arrowutils.StringFromRecord(record, attrIDS.Key, i)
+		if err != nil {
+			return nil, werror.Wrap(err)
+		}
+
+		vType, err := arrowutils.U8FromRecord(record, attrIDS.Type, i)
+		if err != nil {
+			return nil, werror.Wrap(err)
+		}
+
+		// Alternate between two values, the previous one being
+		// compared to the current one.
+		value := &values[i%2]
+		value.typ = pcommon.ValueType(vType)
+		switch value.typ {
+		case pcommon.ValueTypeStr:
+			value.str, err = arrowutils.StringFromRecord(record, attrIDS.Str, i)
+		case pcommon.ValueTypeInt:
+			value.integer, err = arrowutils.I64FromRecord(record, attrIDS.Int, i)
+		case pcommon.ValueTypeDouble:
+			value.double, err = arrowutils.F64FromRecord(record, attrIDS.Double, i)
+		case pcommon.ValueTypeBool:
+			value.boolean, err = arrowutils.BoolFromRecord(record, attrIDS.Bool, i)
+		case pcommon.ValueTypeBytes:
+			value.bin, err = arrowutils.BinaryFromRecord(record, attrIDS.Bytes, i)
+		case pcommon.ValueTypeSlice, pcommon.ValueTypeMap:
+			var v []byte
+			v, err = arrowutils.BinaryFromRecord(record, attrIDS.Ser, i)
+			if err == nil {
+				value.value = pcommon.NewValueEmpty()
+				err = common.Deserialize(v, value.value)
+			}
+		default:
+			// silently ignore unknown types to avoid DOS attacks
+			value.typ = pcommon.ValueTypeEmpty
+		}
+		if err != nil {
+			return nil, werror.Wrap(err)
+		}
+
+		deltaOrParentID, err := arrowutils.U16FromRecord(record, attrIDS.ParentID, i)
+		if err != nil {
+			return nil, werror.Wrap(err)
+		}
+		var parentID uint16
+		if prevValue != nil && prevKey == key && prevValue.equal(value) {
+			parentID = prevParentID + deltaOrParentID
+		} else {
+			parentID = deltaOrParentID
+		}
+		// Comparing to the latest of equal values is the same as
+		// comparing to the first, and the previous value is
+		// never overwritten by the next.
+		prevParentID = parentID
+		prevKey = key
+		prevValue = value
+
+		if int(parentID) >= len(store.byID) {
+			store.byID = append(store.byID, make([][]byte, int(parentID)+1-len(store.byID))...)
+		}
+		b := store.byID[parentID]
+		b, kv := BeginMessage(b, field)
+		b = appendKey(b, key)
+		b, start := BeginMessage(b, keyValueValue)
+		b = value.appendAnyValue(b)
+		b = EndMessage(b, start)
+		store.byID[parentID] = EndMessage(b, kv)
+	}
+
+	return store, nil
+}
+
+// AppendResourceFromRecord appends the resource of a row as an OTLP
+// Resource message field, and returns its schema URL.  The resource
+// attributes are encoded with field number 1.
+func AppendResourceFromRecord(b []byte, field protowire.Number, record arrow.Record, row int, resIds *ResourceIds, attrs *Attributes16Wire) (_ []byte, schemaUrl string, err error) {
+	resArr, err := arrowutils.StructFromRecord(record, resIds.Resource, row)
+	if err != nil {
+		return b, "", werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+
+	schemaUrl, err = arrowutils.StringFromStruct(resArr, row, resIds.SchemaUrl)
+	if err != nil {
+		return b, "", werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	droppedAttributesCount, err := arrowutils.U32FromStruct(resArr, row, resIds.DroppedAttributesCount)
+	if err != nil {
+		return b, "", werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	ID, err := arrowutils.NullableU16FromStruct(resArr, row, resIds.ID)
+	if err != nil {
+		return b, "", werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+
+	b, start := BeginMessage(b, field)
+	if ID != nil {
+		b = append(b, attrs.AttributesByDeltaID(*ID)...)
+	}
+	b = AppendUint32(b, resourceDroppedAttributesCount, droppedAttributesCount)
+	return EndMessage(b, start), schemaUrl, nil
+}
+
+// AppendScopeFromRecord appends the scope of a row as an OTLP
+// InstrumentationScope message field.  The scope attributes are
+// encoded with field number 3.
+func AppendScopeFromRecord(b []byte, field protowire.Number, record arrow.Record, row int, ids *ScopeIds, attrs *Attributes16Wire) ([]byte, error) {
+	scopeArray, err := arrowutils.StructFromRecord(record, ids.Scope, row)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	name, err := arrowutils.StringFromStruct(scopeArray, row, ids.Name)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	version, err := arrowutils.StringFromStruct(scopeArray, row, ids.Version)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	droppedAttributesCount, err := arrowutils.U32FromStruct(scopeArray, row, ids.DroppedAttributesCount)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	ID, err := arrowutils.NullableU16FromStruct(scopeArray, row, ids.ID)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+
+	b, start := BeginMessage(b, field)
+	b = AppendString(b, scopeName, name)
+	b = AppendString(b, scopeVersion, version)
+	if ID != nil {
+		b = append(b, attrs.AttributesByDeltaID(*ID)...)
+	}
+	b = AppendUint32(b, scopeDroppedAttributesCount, droppedAttributesCount)
+	return EndMessage(b, start), nil
+}
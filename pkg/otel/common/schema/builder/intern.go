@@ -0,0 +1,70 @@
+/*
+ * Copyright The OpenTelemetry Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package builder
+
+import (
+	"github.com/cespare/xxhash/v2"
+)
+
+// stringInterner shares the bytes of the equal strings appended to the
+// dictionary columns of a record.  Arrow dictionary builders take
+// bytes, and converting each string allocates a copy, while the
+// values of attributes repeat across rows.  With the interner, each
+// distinct value is converted once per record.
+//
+// The strings are keyed by their xxhash.  A hash collision is detected
+// by comparing the strings, and the colliding string is converted
+// without being interned.
+type stringInterner struct {
+	byHash map[uint64][]byte
+}
+
+func newStringInterner() *stringInterner {
+	return &stringInterner{
+		byHash: make(map[uint64][]byte),
+	}
+}
+
+// bytes returns the bytes of s, shared with the equal strings
+// previously interned.  The bytes must not be modified.
+func (i *stringInterner) bytes(s string) []byte {
+	if i == nil {
+		return []byte(s)
+	}
+
+	h := xxhash.Sum64String(s)
+	if b, ok := i.byHash[h]; ok {
+		if string(b) == s {
+			return b
+		}
+		return []byte(s)
+	}
+
+	b := []byte(s)
+	i.byHash[h] = b
+	return b
+}
+
+// reset forgets the interned strings, once the dictionaries of a
+// record have been built.
+func (i *stringInterner) reset() {
+	if i == nil {
+		return
+	}
+	clear(i.byHash)
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright The OpenTelemetry Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringInterner(t *testing.T) {
+	i := newStringInterner()
+
+	// Strings built at runtime don't share storage.
+	a := strings.Repeat("value", 2)
+	b := strings.Repeat("value", 2)
+
+	ab := i.bytes(a)
+	require.Equal(t, []byte(a), ab)
+	bb := i.bytes(b)
+	require.Same(t, &ab[0], &bb[0])
+
+	require.Zero(t, testing.AllocsPerRun(10, func() {
+		_ = i.bytes(b)
+	}))
+
+	// A collision returns a copy of the string.
+	i.byHash[xxhash.Sum64String("colliding")] = []byte("other")
+	require.Equal(t, []byte("colliding"), i.bytes("colliding"))
+
+	i.reset()
+	require.Empty(t, i.byHash)
+	cb := i.bytes(b)
+	require.NotSame(t, &ab[0], &cb[0])
+
+	// A nil interner converts the strings.
+	var none *stringInterner
+	require.Equal(t, []byte(a), none.bytes(a))
+	none.reset()
+}
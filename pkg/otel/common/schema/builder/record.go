@@ -78,6 +78,10 @@ type RecordBuilderExt struct {
 
 	// The observer that is notified when certain events occur.
 	observer observer.ProducerObserver
+
+	// interner shares the bytes of the values appended to the
+	// dictionary string columns of the record.
+	interner *stringInterner
 }
 
 // NewRecordBuilderExt creates a new RecordBuilderExt from the given allocator
@@ -111,6 +115,7 @@ func NewRecordBuilderExt(
 		stats:              stats,
 		metadata:           make(map[string]string),
 		observer:           observer,
+		interner:           newStringInterner(),
 	}
 }
 
@@ -159,6 +164,8 @@ func (rb *RecordBuilderExt) RecordBuilder() *array.RecordBuilder {
 // NewRecord returns a new record from the underlying array.RecordBuilder or
 // ErrSchemaNotUpToDate if the schema is not up-to-date.
 func (rb *RecordBuilderExt) NewRecord() (arrow.Record, error) {
+	defer rb.interner.reset()
+
 	// If one of the tree transformation has been removed, or updated, then
 	// the schema must be updated.
 	if !rb.IsSchemaUpToDate() {
@@ -505,7 +512,9 @@ func (rb *RecordBuilderExt) StringBuilder(name string) *StringBuilder {
 	b := rb.builder(name)
 
 	if b != nil {
-		return NewStringBuilder(b, transformNode, rb.updateRequest)
+		sb := NewStringBuilder(b, transformNode, rb.updateRequest)
+		sb.interner = rb.interner
+		return sb
 	} else {
 		return NewStringBuilder(nil, transformNode, rb.updateRequest)
 	}
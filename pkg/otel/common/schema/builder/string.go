@@ -28,6 +28,10 @@ type StringBuilder struct {
 	builder       array.Builder
 	transformNode *schema.TransformNode
 	updateRequest *update.SchemaUpdateRequest
+
+	// interner shares the bytes of the values appended to a
+	// dictionary, nil if the values are not interned.
+	interner *stringInterner
 }
 
 // NewStringBuilder creates a new StringBuilder.
@@ -59,7 +63,7 @@ func (b *StringBuilder) Append(value string) {
 		case *array.StringBuilder:
 			builder.Append(value)
 		case *array.BinaryDictionaryBuilder:
-			if err := builder.AppendString(value); err != nil {
+			if err := builder.Append(b.interner.bytes(value)); err != nil {
 				// Should never happen.
 				panic(err)
 			}
@@ -89,7 +93,7 @@ func (b *StringBuilder) AppendNonEmpty(value string) {
 		case *array.StringBuilder:
 			builder.Append(value)
 		case *array.BinaryDictionaryBuilder:
-			if err := builder.AppendString(value); err != nil {
+			if err := builder.Append(b.interner.bytes(value)); err != nil {
 				// Should never happen.
 				panic(err)
 			}
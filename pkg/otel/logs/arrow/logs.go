@@ -209,7 +209,7 @@ func (b *LogsBuilder) Append(logs plog.Logs) (err error) {
 
 	attrsAccu := b.relatedData.AttrsBuilders().LogRecord().Accumulator()
 
-	logID := uint16(0)
+	logID := 0
 	resLogID := -1
 	scopeLogID := -1
 	resID := int64(-1)
@@ -221,11 +221,14 @@ func (b *LogsBuilder) Append(logs plog.Logs) (err error) {
 		log := logRec.Log
 		logAttrs := log.Attributes()
 
-		ID := logID
+		ID := uint16(logID)
 
 		if logAttrs.Len() == 0 {
 			b.ib.AppendNull()
 		} else {
+			if logID > math.MaxUint16 {
+				return werror.Wrap(acommon.NewLimitError("log records with attributes", math.MaxUint16+1))
+			}
 			b.ib.Append(ID)
 			logID++
 		}
@@ -245,7 +248,10 @@ func (b *LogsBuilder) Append(logs plog.Logs) (err error) {
 			}
 		}
 		// Check resID validity
-		if resID == -1 || resID > math.MaxUint16 {
+		if resID > math.MaxUint16 {
+			return werror.Wrap(acommon.NewLimitError("resources", math.MaxUint16+1))
+		}
+		if resID == -1 {
 			return werror.WrapWithContext(acommon.ErrInvalidResourceID, map[string]interface{}{
 				"resource_id": resID,
 			})
@@ -270,7 +276,10 @@ func (b *LogsBuilder) Append(logs plog.Logs) (err error) {
 			}
 		}
 		// Check scopeID validity
-		if scopeID == -1 || scopeID > math.MaxUint16 {
+		if scopeID > math.MaxUint16 {
+			return werror.Wrap(acommon.NewLimitError("scopes", math.MaxUint16+1))
+		}
+		if scopeID == -1 {
 			return werror.WrapWithContext(acommon.ErrInvalidScopeID, map[string]interface{}{
 				"scope_id": scopeID,
 			})
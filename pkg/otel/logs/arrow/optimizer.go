@@ -68,6 +68,48 @@ func NewLogsOptimizer(sorter LogSorter) *LogsOptimizer {
 }
 
 func (t *LogsOptimizer) Optimize(logs plog.Logs) *LogsOptimized {
+	resLogsSlice := logs.ResourceLogs()
+	if resLogsSlice.Len() == 1 && resLogsSlice.At(0).ScopeLogs().Len() == 1 {
+		return t.optimizeSingleScope(resLogsSlice.At(0))
+	}
+	return t.optimizeGeneral(logs)
+}
+
+// optimizeSingleScope flattens the log records of a batch with a
+// single resource and scope, the common case of a batch exported by
+// an agent.  No grouping is needed, so the resource and scope are not
+// serialized to compute their IDs, and the flattened log records are
+// allocated at once.
+func (t *LogsOptimizer) optimizeSingleScope(resLogs plog.ResourceLogs) *LogsOptimized {
+	scopeLogs := resLogs.ScopeLogs().At(0)
+	logRecords := scopeLogs.LogRecords()
+
+	resScope := &ResScope{
+		Resource:          resLogs.Resource(),
+		ResourceSchemaUrl: resLogs.SchemaUrl(),
+		Scope:             scopeLogs.Scope(),
+		ScopeSchemaUrl:    scopeLogs.SchemaUrl(),
+	}
+	flattened := make([]FlattenedLog, logRecords.Len())
+	logsOptimized := &LogsOptimized{
+		Logs: make([]*FlattenedLog, logRecords.Len()),
+	}
+	for k := range flattened {
+		flattened[k] = FlattenedLog{
+			ResScope: resScope,
+			Log:      logRecords.At(k),
+		}
+		logsOptimized.Logs[k] = &flattened[k]
+	}
+
+	t.sorter.Sort(logsOptimized.Logs)
+
+	return logsOptimized
+}
+
+// optimizeGeneral flattens the log records of any batch, grouping
+// them by resource and scope.
+func (t *LogsOptimizer) optimizeGeneral(logs plog.Logs) *LogsOptimized {
 	logsOptimized := &LogsOptimized{
 		Logs: make([]*FlattenedLog, 0, 32),
 	}
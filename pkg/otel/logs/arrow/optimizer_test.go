@@ -0,0 +1,86 @@
+/*
+ * Copyright The OpenTelemetry Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package arrow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// singleScopeLogs returns a batch of log records sharing one resource
+// and scope, as exported by an agent.
+func singleScopeLogs(count int) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.SetSchemaUrl("resource-schema")
+	rl.Resource().Attributes().PutStr("service.name", "agent")
+	rl.Resource().Attributes().PutStr("host.name", "host")
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.SetSchemaUrl("scope-schema")
+	sl.Scope().SetName("scope")
+	sl.Scope().SetVersion("v1")
+	for i := 0; i < count; i++ {
+		log := sl.LogRecords().AppendEmpty()
+		log.Body().SetStr(fmt.Sprint("log-", i))
+		log.SetTraceID(pcommon.TraceID{byte(i % 13), byte(i)})
+		log.SetTimestamp(pcommon.Timestamp(1e18 + i))
+		log.Attributes().PutInt("index", int64(i))
+	}
+	return logs
+}
+
+// TestOptimizeSingleScope verifies that batches with a single
+// resource and scope are flattened as by the general path.
+func TestOptimizeSingleScope(t *testing.T) {
+	optimizer := NewLogsOptimizer(SortLogsByResourceLogsIDScopeLogsIDTraceID())
+	logs := singleScopeLogs(100)
+
+	single := optimizer.Optimize(logs)
+	general := optimizer.optimizeGeneral(logs)
+
+	require.Equal(t, len(general.Logs), len(single.Logs))
+	for i := range general.Logs {
+		expect, actual := general.Logs[i], single.Logs[i]
+		require.Equal(t, expect.Log, actual.Log)
+		require.Equal(t, expect.ResScope, actual.ResScope)
+	}
+}
+
+// BenchmarkOptimizeSingleScope compares the fast path for batches with
+// a single resource and scope against the general path.
+func BenchmarkOptimizeSingleScope(b *testing.B) {
+	optimizer := NewLogsOptimizer(SortLogsByResourceLogsIDScopeLogsIDTraceID())
+	logs := singleScopeLogs(1000)
+
+	b.Run("general", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			optimizer.optimizeGeneral(logs)
+		}
+	})
+	b.Run("single", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			optimizer.Optimize(logs)
+		}
+	})
+}
@@ -59,14 +59,30 @@ type LogRecordIDs struct {
 // record must be released by the caller.
 func LogsFrom(record arrow.Record, relatedData *RelatedData) (plog.Logs, error) {
 	logs := plog.NewLogs()
+	return logs, LogsInto(logs, record, relatedData)
+}
 
+// LogsInto decodes the given Arrow Record into logs, which may hold
+// the log records of an earlier batch, e.g., pooled by the caller.
+// Its resource logs, scope logs and log records are reused by
+// position and the ones left over are removed, so that logs holds
+// exactly the decoded log records.
+//
+// Important Note: This function doesn't take ownership of the record, so the
+// record must be released by the caller.
+func LogsInto(logs plog.Logs, record arrow.Record, relatedData *RelatedData) error {
 	if relatedData == nil {
-		return logs, werror.Wrap(otlp.ErrMissingRelatedData)
+		return werror.Wrap(otlp.ErrMissingRelatedData)
 	}
 
 	logRecordIDs, err := SchemaToIDs(record.Schema())
 	if err != nil {
-		return logs, werror.Wrap(err)
+		return werror.Wrap(err)
+	}
+
+	sizes, err := groupSizesFromRecord(record, logRecordIDs)
+	if err != nil {
+		return werror.Wrap(err)
 	}
 
 	var resLogs plog.ResourceLogs
@@ -74,6 +90,7 @@ func LogsFrom(record arrow.Record, relatedData *RelatedData) (plog.Logs, error)
 	var logRecordSlice plog.LogRecordSlice
 
 	resLogsSlice := logs.ResourceLogs()
+	resLogsSlice.EnsureCapacity(len(sizes.scopes))
 	rows := int(record.NumRows())
 
 	prevResID := None
@@ -81,22 +98,39 @@ func LogsFrom(record arrow.Record, relatedData *RelatedData) (plog.Logs, error)
 
 	var resID uint16
 	var scopeID uint16
+	var resIdx, scopeIdx int
+
+	// The number of elements decoded in the current scope logs and
+	// log record slices.
+	var scopeLogsIdx, logRecordIdx int
+
+	// The attributes of each log record, by log record ID, into
+	// which the log record attributes are decoded.
+	attrsByID := make([]logRecordAttrs, 0, rows)
+	var dupAttrs []logRecordAttrs
 
 	for row := 0; row < rows; row++ {
 		// Process resource logs, resource, schema url (resource)
 		resDeltaID, err := otlp.ResourceIDFromRecord(record, row, logRecordIDs.Resource)
 		resID += resDeltaID
 		if err != nil {
-			return logs, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		if prevResID != int(resID) {
 			prevResID = int(resID)
-			resLogs = resLogsSlice.AppendEmpty()
+			if resIdx != 0 {
+				otlp.Truncate[plog.LogRecord](logRecordSlice, logRecordIdx)
+				otlp.Truncate[plog.ScopeLogs](scopeLogsSlice, scopeLogsIdx)
+			}
+			resLogs = otlp.ElementAt(resLogsSlice, resIdx, resetResourceLogs)
 			scopeLogsSlice = resLogs.ScopeLogs()
+			scopeLogsSlice.EnsureCapacity(sizes.scopes[resIdx])
+			resIdx++
+			scopeLogsIdx = 0
 			prevScopeID = None
 			schemaUrl, err := otlp.UpdateResourceFromRecord(resLogs.Resource(), record, row, logRecordIDs.Resource, relatedData.ResAttrMapStore)
 			if err != nil {
-				return logs, werror.Wrap(err)
+				return werror.Wrap(err)
 			}
 			resLogs.SetSchemaUrl(schemaUrl)
 		}
@@ -105,146 +139,157 @@ func LogsFrom(record arrow.Record, relatedData *RelatedData) (plog.Logs, error)
 		scopeDeltaID, err := otlp.ScopeIDFromRecord(record, row, logRecordIDs.Scope)
 		scopeID += scopeDeltaID
 		if err != nil {
-			return logs, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		if prevScopeID != int(scopeID) {
 			prevScopeID = int(scopeID)
-			scopeLogs := scopeLogsSlice.AppendEmpty()
+			if scopeLogsIdx != 0 {
+				otlp.Truncate[plog.LogRecord](logRecordSlice, logRecordIdx)
+			}
+			scopeLogs := otlp.ElementAt(scopeLogsSlice, scopeLogsIdx, resetScopeLogs)
+			scopeLogsIdx++
 			logRecordSlice = scopeLogs.LogRecords()
+			logRecordSlice.EnsureCapacity(sizes.logRecords[scopeIdx])
+			scopeIdx++
+			logRecordIdx = 0
 			if err = otlp.UpdateScopeFromRecord(scopeLogs.Scope(), record, row, logRecordIDs.Scope, relatedData.ScopeAttrMapStore); err != nil {
-				return logs, werror.Wrap(err)
+				return werror.Wrap(err)
 			}
 
 			schemaUrl, err := arrowutils.StringFromRecord(record, logRecordIDs.SchemaUrl, row)
 			if err != nil {
-				return logs, werror.Wrap(err)
+				return werror.Wrap(err)
 			}
 			scopeLogs.SetSchemaUrl(schemaUrl)
 		}
 
 		// Process log record fields
-		logRecord := logRecordSlice.AppendEmpty()
+		logRecord := otlp.ElementAt(logRecordSlice, logRecordIdx, resetLogRecord)
+		logRecordIdx++
 		deltaID, err := arrowutils.NullableU16FromRecord(record, logRecordIDs.ID, row)
 		if err != nil {
-			return logs, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 
 		timeUnixNano, err := arrowutils.TimestampFromRecord(record, logRecordIDs.TimeUnixNano, row)
 		if err != nil {
-			return logs, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+			return werror.WrapWithContext(err, map[string]interface{}{"row": row})
 		}
 		observedTimeUnixNano, err := arrowutils.TimestampFromRecord(record, logRecordIDs.ObservedTimeUnixNano, row)
 		if err != nil {
-			return logs, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+			return werror.WrapWithContext(err, map[string]interface{}{"row": row})
 		}
 
 		traceID, err := arrowutils.FixedSizeBinaryFromRecord(record, logRecordIDs.TraceID, row)
 		if err != nil {
-			return logs, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+			return werror.WrapWithContext(err, map[string]interface{}{"row": row})
 		}
 		if len(traceID) != 16 {
-			return logs, werror.WrapWithContext(common.ErrInvalidTraceIDLength, map[string]interface{}{"row": row, "traceID": traceID})
+			return werror.WrapWithContext(common.ErrInvalidTraceIDLength, map[string]interface{}{"row": row, "traceID": traceID})
 		}
 		spanID, err := arrowutils.FixedSizeBinaryFromRecord(record, logRecordIDs.SpanID, row)
 		if err != nil {
-			return logs, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+			return werror.WrapWithContext(err, map[string]interface{}{"row": row})
 		}
 		if len(spanID) != 8 {
-			return logs, werror.WrapWithContext(common.ErrInvalidSpanIDLength, map[string]interface{}{"row": row, "spanID": spanID})
+			return werror.WrapWithContext(common.ErrInvalidSpanIDLength, map[string]interface{}{"row": row, "spanID": spanID})
 		}
 
 		severityNumber, err := arrowutils.I32FromRecord(record, logRecordIDs.SeverityNumber, row)
 		if err != nil {
-			return logs, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+			return werror.WrapWithContext(err, map[string]interface{}{"row": row})
 		}
 		severityText, err := arrowutils.StringFromRecord(record, logRecordIDs.SeverityText, row)
 		if err != nil {
-			return logs, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+			return werror.WrapWithContext(err, map[string]interface{}{"row": row})
 		}
 
 		// Read the body value based on the body type
 		bodyStruct, err := arrowutils.StructFromRecord(record, logRecordIDs.Body, row)
 		if err != nil {
-			return logs, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+			return werror.WrapWithContext(err, map[string]interface{}{"row": row})
 		}
 
 		if bodyStruct != nil {
 			// If there is a body struct, read the body type and value
 			bodyType, err := arrowutils.U8FromStruct(bodyStruct, row, logRecordIDs.BodyType)
 			if err != nil {
-				return logs, werror.Wrap(err)
+				return werror.Wrap(err)
 			}
 			body := logRecord.Body()
 			switch pcommon.ValueType(bodyType) {
 			case pcommon.ValueTypeStr:
 				v, err := arrowutils.StringFromStruct(bodyStruct, row, logRecordIDs.BodyStr)
 				if err != nil {
-					return logs, werror.Wrap(err)
+					return werror.Wrap(err)
 				}
 				body.SetStr(v)
 			case pcommon.ValueTypeInt:
 				v, err := arrowutils.I64FromStruct(bodyStruct, row, logRecordIDs.BodyInt)
 				if err != nil {
-					return logs, werror.Wrap(err)
+					return werror.Wrap(err)
 				}
 				body.SetInt(v)
 			case pcommon.ValueTypeDouble:
 				v, err := arrowutils.F64FromStruct(bodyStruct, row, logRecordIDs.BodyDouble)
 				if err != nil {
-					return logs, werror.Wrap(err)
+					return werror.Wrap(err)
 				}
 				body.SetDouble(v)
 			case pcommon.ValueTypeBool:
 				v, err := arrowutils.BoolFromStruct(bodyStruct, row, logRecordIDs.BodyBool)
 				if err != nil {
-					return logs, werror.Wrap(err)
+					return werror.Wrap(err)
 				}
 				body.SetBool(v)
 			case pcommon.ValueTypeBytes:
 				v, err := arrowutils.BinaryFromStruct(bodyStruct, row, logRecordIDs.BodyBytes)
 				if err != nil {
-					return logs, werror.Wrap(err)
+					return werror.Wrap(err)
 				}
 				body.SetEmptyBytes().FromRaw(v)
 			case pcommon.ValueTypeSlice:
 				v, err := arrowutils.BinaryFromStruct(bodyStruct, row, logRecordIDs.BodySer)
 				if err != nil {
-					return logs, werror.Wrap(err)
+					return werror.Wrap(err)
 				}
 				if err = common.Deserialize(v, body); err != nil {
-					return logs, werror.Wrap(err)
+					return werror.Wrap(err)
 				}
 			case pcommon.ValueTypeMap:
 				v, err := arrowutils.BinaryFromStruct(bodyStruct, row, logRecordIDs.BodySer)
 				if err != nil {
-					return logs, werror.Wrap(err)
+					return werror.Wrap(err)
 				}
 				if err = common.Deserialize(v, body); err != nil {
-					return logs, werror.Wrap(err)
+					return werror.Wrap(err)
 				}
 			default:
 				// silently ignore unknown types to avoid DOS attacks
 			}
 		}
 
-		logRecordAttrs := logRecord.Attributes()
-
 		if deltaID != nil {
 			ID := relatedData.LogRecordIDFromDelta(*deltaID)
-			attrs := relatedData.LogRecordAttrMapStore.AttributesByID(ID)
-			if attrs != nil {
-				attrs.CopyTo(logRecordAttrs)
+			if int(ID) >= len(attrsByID) {
+				attrsByID = append(attrsByID, make([]logRecordAttrs, int(ID)+1-len(attrsByID))...)
+			}
+			if attrsByID[ID].ok {
+				// IDs are unique unless they wrapped around.
+				dupAttrs = append(dupAttrs, logRecordAttrs{id: ID, attrs: logRecord.Attributes(), ok: true})
+			} else {
+				attrsByID[ID] = logRecordAttrs{id: ID, attrs: logRecord.Attributes(), ok: true}
 			}
 		}
 
 		droppedAttributesCount, err := arrowutils.U32FromRecord(record, logRecordIDs.DropAttributesCount, row)
 		if err != nil {
-			return logs, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+			return werror.WrapWithContext(err, map[string]interface{}{"row": row})
 		}
 
 		flags, err := arrowutils.U32FromRecord(record, logRecordIDs.Flags, row)
 		if err != nil {
-			return logs, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+			return werror.WrapWithContext(err, map[string]interface{}{"row": row})
 		}
 
 		var tid pcommon.TraceID
@@ -261,8 +306,95 @@ func LogsFrom(record arrow.Record, relatedData *RelatedData) (plog.Logs, error)
 		logRecord.SetDroppedAttributesCount(droppedAttributesCount)
 		logRecord.SetFlags(plog.LogRecordFlags(flags))
 	}
+	if resIdx != 0 {
+		otlp.Truncate[plog.LogRecord](logRecordSlice, logRecordIdx)
+		otlp.Truncate[plog.ScopeLogs](scopeLogsSlice, scopeLogsIdx)
+	}
+	otlp.Truncate[plog.ResourceLogs](resLogsSlice, resIdx)
+
+	if relatedData.logRecordAttrs != nil {
+		err = otlp.Attributes16Into(relatedData.logRecordAttrs, func(ID uint16) (pcommon.Map, bool) {
+			if int(ID) >= len(attrsByID) {
+				return pcommon.Map{}, false
+			}
+			return attrsByID[ID].attrs, attrsByID[ID].ok
+		})
+		if err != nil {
+			return werror.Wrap(err)
+		}
+		for _, dup := range dupAttrs {
+			attrsByID[dup.id].attrs.CopyTo(dup.attrs)
+		}
+	}
+
+	return nil
+}
+
+var emptyLogRecord = plog.NewLogRecord()
 
-	return logs, nil
+func resetResourceLogs(rl plog.ResourceLogs) {
+	otlp.ResetResource(rl.Resource())
+}
+
+func resetScopeLogs(sl plog.ScopeLogs) {
+	otlp.ResetScope(sl.Scope())
+}
+
+func resetLogRecord(lr plog.LogRecord) {
+	emptyLogRecord.CopyTo(lr)
+}
+
+// logRecordAttrs is the attribute map of a log record.
+type logRecordAttrs struct {
+	id    uint16
+	attrs pcommon.Map
+	ok    bool
+}
+
+// groupSizes is the number of scopes of each resource, and the
+// number of log records of each scope, of a logs record.
+type groupSizes struct {
+	scopes     []int
+	logRecords []int
+}
+
+// groupSizesFromRecord scans the resource and scope IDs of a logs
+// record, so that LogsFrom sizes each slice before appending to it.
+func groupSizesFromRecord(record arrow.Record, logRecordIDs *LogRecordIDs) (groupSizes, error) {
+	var sizes groupSizes
+	rows := int(record.NumRows())
+
+	prevResID := None
+	prevScopeID := None
+
+	var resID uint16
+	var scopeID uint16
+
+	for row := 0; row < rows; row++ {
+		resDeltaID, err := otlp.ResourceIDFromRecord(record, row, logRecordIDs.Resource)
+		if err != nil {
+			return sizes, werror.Wrap(err)
+		}
+		resID += resDeltaID
+		if prevResID != int(resID) {
+			prevResID = int(resID)
+			prevScopeID = None
+			sizes.scopes = append(sizes.scopes, 0)
+		}
+
+		scopeDeltaID, err := otlp.ScopeIDFromRecord(record, row, logRecordIDs.Scope)
+		if err != nil {
+			return sizes, werror.Wrap(err)
+		}
+		scopeID += scopeDeltaID
+		if prevScopeID != int(scopeID) {
+			prevScopeID = int(scopeID)
+			sizes.scopes[len(sizes.scopes)-1]++
+			sizes.logRecords = append(sizes.logRecords, 0)
+		}
+		sizes.logRecords[len(sizes.logRecords)-1]++
+	}
+	return sizes, nil
 }
 
 func SchemaToIDs(schema *arrow.Schema) (*LogRecordIDs, error) {
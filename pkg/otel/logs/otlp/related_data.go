@@ -18,6 +18,8 @@
 package otlp
 
 import (
+	"github.com/apache/arrow/go/v14/arrow"
+
 	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
 	"github.com/open-telemetry/otel-arrow/pkg/otel"
 	"github.com/open-telemetry/otel-arrow/pkg/otel/common/otlp"
@@ -29,18 +31,31 @@ import (
 
 type (
 	RelatedData struct {
-		LogRecordID           uint16
-		ResAttrMapStore       *otlp.Attributes16Store
-		ScopeAttrMapStore     *otlp.Attributes16Store
-		LogRecordAttrMapStore *otlp.Attributes16Store
+		LogRecordID       uint16
+		ResAttrMapStore   *otlp.Attributes16Store
+		ScopeAttrMapStore *otlp.Attributes16Store
+
+		// logRecordAttrs is the record of log record attributes,
+		// if any.  They are decoded by LogsFrom directly into the
+		// log records.
+		logRecordAttrs arrow.Record
 	}
 )
 
 func NewRelatedData() *RelatedData {
 	return &RelatedData{
-		ResAttrMapStore:       otlp.NewAttributes16Store(),
-		ScopeAttrMapStore:     otlp.NewAttributes16Store(),
-		LogRecordAttrMapStore: otlp.NewAttributes16Store(),
+		ResAttrMapStore:   otlp.NewAttributes16Store(),
+		ScopeAttrMapStore: otlp.NewAttributes16Store(),
+	}
+}
+
+// Release releases the related records retained by the RelatedData.
+func (r *RelatedData) Release() {
+	r.ResAttrMapStore.Release()
+	r.ScopeAttrMapStore.Release()
+	if r.logRecordAttrs != nil {
+		r.logRecordAttrs.Release()
+		r.logRecordAttrs = nil
 	}
 }
 
@@ -50,13 +65,17 @@ func (r *RelatedData) LogRecordIDFromDelta(delta uint16) uint16 {
 }
 
 func RelatedDataFrom(records []*record_message.RecordMessage) (relatedData *RelatedData, logsRecord *record_message.RecordMessage, err error) {
+	related := NewRelatedData()
 	defer func() {
 		for _, record := range records {
 			record.Record().Release()
 		}
+		if err != nil {
+			related.Release()
+		}
 	}()
 
-	relatedData = NewRelatedData()
+	relatedData = related
 
 	// Create the attribute map stores for all the attribute records.
 	for _, record := range records {
@@ -72,10 +91,11 @@ func RelatedDataFrom(records []*record_message.RecordMessage) (relatedData *Rela
 				return nil, nil, werror.Wrap(err)
 			}
 		case colarspb.ArrowPayloadType_LOG_ATTRS:
-			err = otlp.Attributes16StoreFrom(record.Record(), relatedData.LogRecordAttrMapStore)
-			if err != nil {
-				return nil, nil, werror.Wrap(err)
+			if relatedData.logRecordAttrs != nil {
+				return nil, nil, werror.Wrap(otel.ErrDuplicatePayloadType)
 			}
+			record.Record().Retain()
+			relatedData.logRecordAttrs = record.Record()
 		case colarspb.ArrowPayloadType_LOGS:
 			if logsRecord != nil {
 				return nil, nil, werror.Wrap(otel.ErrMultipleTracesRecords)
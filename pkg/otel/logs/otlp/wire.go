@@ -0,0 +1,356 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"math"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	arrowutils "github.com/open-telemetry/otel-arrow/pkg/arrow"
+	"github.com/open-telemetry/otel-arrow/pkg/otel"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/common"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/common/otlp"
+	"github.com/open-telemetry/otel-arrow/pkg/record_message"
+	"github.com/open-telemetry/otel-arrow/pkg/werror"
+)
+
+// Field numbers of the OTLP logs messages.
+const (
+	requestResourceLogs = 1
+
+	resourceLogsResource  = 1
+	resourceLogsScopeLogs = 2
+	resourceLogsSchemaUrl = 3
+
+	scopeLogsScope      = 1
+	scopeLogsLogRecords = 2
+	scopeLogsSchemaUrl  = 3
+
+	logRecordTimeUnixNano           = 1
+	logRecordSeverityNumber         = 2
+	logRecordSeverityText           = 3
+	logRecordBody                   = 5
+	logRecordAttributes             = 6
+	logRecordDroppedAttributesCount = 7
+	logRecordFlags                  = 8
+	logRecordTraceID                = 9
+	logRecordSpanID                 = 10
+	logRecordObservedTimeUnixNano   = 11
+)
+
+// wireRelatedData holds the attributes of the related records,
+// encoded as OTLP KeyValue fields.
+type wireRelatedData struct {
+	logRecordID    uint16
+	resAttrs       *otlp.Attributes16Wire
+	scopeAttrs     *otlp.Attributes16Wire
+	logRecordAttrs *otlp.Attributes16Wire
+}
+
+// AppendExportRequest appends to dst the marshaled OTLP
+// ExportLogsServiceRequest decoded from the records of a batch,
+// without constructing pdata, and returns the number of log records.
+// The result is equivalent to marshaling the logs returned by
+// LogsFrom (identical, except for the order of keys in serialized
+// maps), and is nil if the batch has no logs record.
+//
+// Important Note: This function releases the records.
+func AppendExportRequest(dst []byte, records []*record_message.RecordMessage) (_ []byte, logRecords int, err error) {
+	defer func() {
+		for _, record := range records {
+			record.Record().Release()
+		}
+	}()
+
+	var related wireRelatedData
+	var logsRecord arrow.Record
+	for _, record := range records {
+		switch record.PayloadType() {
+		case colarspb.ArrowPayloadType_RESOURCE_ATTRS:
+			related.resAttrs, err = otlp.Attributes16WireFrom(record.Record(), otlp.ResourceAttributesField)
+		case colarspb.ArrowPayloadType_SCOPE_ATTRS:
+			related.scopeAttrs, err = otlp.Attributes16WireFrom(record.Record(), otlp.ScopeAttributesField)
+		case colarspb.ArrowPayloadType_LOG_ATTRS:
+			related.logRecordAttrs, err = otlp.Attributes16WireFrom(record.Record(), logRecordAttributes)
+		case colarspb.ArrowPayloadType_LOGS:
+			if logsRecord != nil {
+				err = otel.ErrMultipleTracesRecords
+			}
+			logsRecord = record.Record()
+		default:
+			err = otel.UnknownPayloadType
+		}
+		if err != nil {
+			return dst, 0, werror.Wrap(err)
+		}
+	}
+	if logsRecord == nil {
+		return nil, 0, nil
+	}
+	if dst == nil {
+		// The result is never empty.
+		dst = []byte{}
+	}
+	return appendResourceLogs(dst, logsRecord, &related)
+}
+
+// appendResourceLogs follows the structure of LogsFrom.
+func appendResourceLogs(b []byte, record arrow.Record, related *wireRelatedData) (_ []byte, logRecords int, err error) {
+	logRecordIDs, err := SchemaToIDs(record.Schema())
+	if err != nil {
+		return b, 0, werror.Wrap(err)
+	}
+
+	rows := int(record.NumRows())
+
+	prevResID := None
+	prevScopeID := None
+
+	var resID uint16
+	var scopeID uint16
+
+	// Offsets of the open ResourceLogs and ScopeLogs messages, whose
+	// schema URLs are appended when they end.
+	resStart, scopeStart := -1, -1
+	var resSchemaUrl, scopeSchemaUrl string
+
+	endScopeLogs := func() {
+		if scopeStart >= 0 {
+			b = otlp.AppendString(b, scopeLogsSchemaUrl, scopeSchemaUrl)
+			b = otlp.EndMessage(b, scopeStart)
+			scopeStart = -1
+		}
+	}
+	endResourceLogs := func() {
+		if resStart >= 0 {
+			endScopeLogs()
+			b = otlp.AppendString(b, resourceLogsSchemaUrl, resSchemaUrl)
+			b = otlp.EndMessage(b, resStart)
+			resStart = -1
+		}
+	}
+
+	for row := 0; row < rows; row++ {
+		resDeltaID, err := otlp.ResourceIDFromRecord(record, row, logRecordIDs.Resource)
+		resID += resDeltaID
+		if err != nil {
+			return b, 0, werror.Wrap(err)
+		}
+		if prevResID != int(resID) {
+			prevResID = int(resID)
+			prevScopeID = None
+			endResourceLogs()
+			b, resStart = otlp.BeginMessage(b, requestResourceLogs)
+			b, resSchemaUrl, err = otlp.AppendResourceFromRecord(b, resourceLogsResource, record, row, logRecordIDs.Resource, related.resAttrs)
+			if err != nil {
+				return b, 0, werror.Wrap(err)
+			}
+		}
+
+		scopeDeltaID, err := otlp.ScopeIDFromRecord(record, row, logRecordIDs.Scope)
+		scopeID += scopeDeltaID
+		if err != nil {
+			return b, 0, werror.Wrap(err)
+		}
+		if prevScopeID != int(scopeID) {
+			prevScopeID = int(scopeID)
+			endScopeLogs()
+			b, scopeStart = otlp.BeginMessage(b, resourceLogsScopeLogs)
+			b, err = otlp.AppendScopeFromRecord(b, scopeLogsScope, record, row, logRecordIDs.Scope, related.scopeAttrs)
+			if err != nil {
+				return b, 0, werror.Wrap(err)
+			}
+			scopeSchemaUrl, err = arrowutils.StringFromRecord(record, logRecordIDs.SchemaUrl, row)
+			if err != nil {
+				return b, 0, werror.Wrap(err)
+			}
+		}
+
+		var start int
+		b, start = otlp.BeginMessage(b, scopeLogsLogRecords)
+		if b, err = appendLogRecord(b, record, row, logRecordIDs, related); err != nil {
+			return b, 0, err
+		}
+		b = otlp.EndMessage(b, start)
+		logRecords++
+	}
+	endResourceLogs()
+	return b, logRecords, nil
+}
+
+// appendLogRecord appends the fields of the OTLP LogRecord message of
+// a row, following LogsFrom.
+func appendLogRecord(b []byte, record arrow.Record, row int, logRecordIDs *LogRecordIDs, related *wireRelatedData) ([]byte, error) {
+	deltaID, err := arrowutils.NullableU16FromRecord(record, logRecordIDs.ID, row)
+	if err != nil {
+		return b, werror.Wrap(err)
+	}
+	timeUnixNano, err := arrowutils.TimestampFromRecord(record, logRecordIDs.TimeUnixNano, row)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	observedTimeUnixNano, err := arrowutils.TimestampFromRecord(record, logRecordIDs.ObservedTimeUnixNano, row)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	traceID, err := arrowutils.FixedSizeBinaryFromRecord(record, logRecordIDs.TraceID, row)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	if len(traceID) != 16 {
+		return b, werror.WrapWithContext(common.ErrInvalidTraceIDLength, map[string]interface{}{"row": row, "traceID": traceID})
+	}
+	spanID, err := arrowutils.FixedSizeBinaryFromRecord(record, logRecordIDs.SpanID, row)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	if len(spanID) != 8 {
+		return b, werror.WrapWithContext(common.ErrInvalidSpanIDLength, map[string]interface{}{"row": row, "spanID": spanID})
+	}
+	severityNumber, err := arrowutils.I32FromRecord(record, logRecordIDs.SeverityNumber, row)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	severityText, err := arrowutils.StringFromRecord(record, logRecordIDs.SeverityText, row)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	droppedAttributesCount, err := arrowutils.U32FromRecord(record, logRecordIDs.DropAttributesCount, row)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	flags, err := arrowutils.U32FromRecord(record, logRecordIDs.Flags, row)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+
+	if timeUnixNano != 0 {
+		b = protowire.AppendTag(b, logRecordTimeUnixNano, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, uint64(timeUnixNano))
+	}
+	if severityNumber != 0 {
+		b = protowire.AppendTag(b, logRecordSeverityNumber, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(severityNumber))
+	}
+	b = otlp.AppendString(b, logRecordSeverityText, severityText)
+
+	var body int
+	b, body = otlp.BeginMessage(b, logRecordBody)
+	if b, err = appendBody(b, record, row, logRecordIDs); err != nil {
+		return b, err
+	}
+	b = otlp.EndMessage(b, body)
+
+	if deltaID != nil {
+		ID := related.logRecordID + *deltaID
+		related.logRecordID = ID
+		b = append(b, related.logRecordAttrs.AttributesByID(ID)...)
+	}
+	b = otlp.AppendUint32(b, logRecordDroppedAttributesCount, droppedAttributesCount)
+	if flags != 0 {
+		b = protowire.AppendTag(b, logRecordFlags, protowire.Fixed32Type)
+		b = protowire.AppendFixed32(b, flags)
+	}
+	// pdata marshals empty IDs as empty fields.
+	b = appendID(b, logRecordTraceID, traceID)
+	b = appendID(b, logRecordSpanID, spanID)
+	if observedTimeUnixNano != 0 {
+		b = protowire.AppendTag(b, logRecordObservedTimeUnixNano, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, uint64(observedTimeUnixNano))
+	}
+	return b, nil
+}
+
+func appendID(b []byte, field protowire.Number, id []byte) []byte {
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	for _, c := range id {
+		if c != 0 {
+			return protowire.AppendBytes(b, id)
+		}
+	}
+	return protowire.AppendVarint(b, 0)
+}
+
+// appendBody appends the fields of the OTLP AnyValue message of the
+// body of a row.
+func appendBody(b []byte, record arrow.Record, row int, logRecordIDs *LogRecordIDs) ([]byte, error) {
+	bodyStruct, err := arrowutils.StructFromRecord(record, logRecordIDs.Body, row)
+	if err != nil {
+		return b, werror.WrapWithContext(err, map[string]interface{}{"row": row})
+	}
+	if bodyStruct == nil {
+		return b, nil
+	}
+	bodyType, err := arrowutils.U8FromStruct(bodyStruct, row, logRecordIDs.BodyType)
+	if err != nil {
+		return b, werror.Wrap(err)
+	}
+	switch pcommon.ValueType(bodyType) {
+	case pcommon.ValueTypeStr:
+		v, err := arrowutils.StringFromStruct(bodyStruct, row, logRecordIDs.BodyStr)
+		if err != nil {
+			return b, werror.Wrap(err)
+		}
+		b = protowire.AppendTag(b, otlp.AnyValueString, protowire.BytesType)
+		b = protowire.AppendString(b, v)
+	case pcommon.ValueTypeInt:
+		v, err := arrowutils.I64FromStruct(bodyStruct, row, logRecordIDs.BodyInt)
+		if err != nil {
+			return b, werror.Wrap(err)
+		}
+		b = protowire.AppendTag(b, otlp.AnyValueInt, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v))
+	case pcommon.ValueTypeDouble:
+		v, err := arrowutils.F64FromStruct(bodyStruct, row, logRecordIDs.BodyDouble)
+		if err != nil {
+			return b, werror.Wrap(err)
+		}
+		b = protowire.AppendTag(b, otlp.AnyValueDouble, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(v))
+	case pcommon.ValueTypeBool:
+		v, err := arrowutils.BoolFromStruct(bodyStruct, row, logRecordIDs.BodyBool)
+		if err != nil {
+			return b, werror.Wrap(err)
+		}
+		b = protowire.AppendTag(b, otlp.AnyValueBool, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(v))
+	case pcommon.ValueTypeBytes:
+		v, err := arrowutils.BinaryFromStruct(bodyStruct, row, logRecordIDs.BodyBytes)
+		if err != nil {
+			return b, werror.Wrap(err)
+		}
+		if len(v) > 0 {
+			b = protowire.AppendTag(b, otlp.AnyValueBytes, protowire.BytesType)
+			b = protowire.AppendBytes(b, v)
+		}
+	case pcommon.ValueTypeSlice, pcommon.ValueTypeMap:
+		v, err := arrowutils.BinaryFromStruct(bodyStruct, row, logRecordIDs.BodySer)
+		if err != nil {
+			return b, werror.Wrap(err)
+		}
+		body := pcommon.NewValueEmpty()
+		if err = common.Deserialize(v, body); err != nil {
+			return b, werror.Wrap(err)
+		}
+		b = otlp.AppendAnyValue(b, body)
+	default:
+		// silently ignore unknown types to avoid DOS attacks
+	}
+	return b, nil
+}
@@ -117,6 +117,7 @@ func CheckEncodeDecode(
 	// Convert the Arrow records back to OTLP.
 	logs, err := logsotlp.LogsFrom(record, relatedData)
 	record.Release()
+	relatedData.Release()
 	require.NoError(t, err)
 
 	assert.Equiv(stdTesting, []json.Marshaler{expectedRequest}, []json.Marshaler{plogotlp.NewExportRequestFromLogs(logs)})
@@ -175,6 +176,9 @@ func CheckEncodeMessUpDecode(
 	// Convert the Arrow records back to OTLP.
 	_, err = logsotlp.LogsFrom(record, relatedData)
 	record.Release()
+	if relatedData != nil {
+		relatedData.Release()
+	}
 
 	if mainRecordChanged || relatedData == nil {
 		require.Error(t, err)
@@ -27,7 +27,6 @@ package arrow
 
 import (
 	"errors"
-	"math"
 	"sort"
 
 	"github.com/apache/arrow/go/v14/arrow"
@@ -320,10 +319,8 @@ func (a *EHDPAccumulator) Append(
 	metricID uint16,
 	ehdps pmetric.ExponentialHistogramDataPointSlice,
 ) {
-	if a.groupCount == math.MaxUint32 {
-		panic("The maximum number of group of exponential histogram data points has been reached (max is uint32).")
-	}
-
+	// The groups are bounded by the number of metrics, which
+	// MetricsBuilder.Append limits.
 	if ehdps.Len() == 0 {
 		return
 	}
@@ -263,7 +263,7 @@ func (a *ExemplarAccumulator) IsEmpty() bool {
 // Append appends a slice of exemplars to the accumulator.
 func (a *ExemplarAccumulator) Append(dpID uint32, exemplars pmetric.ExemplarSlice) error {
 	if a.groupCount == math.MaxUint32 {
-		panic("The maximum number of group of exemplars has been reached (max is uint32).")
+		return werror.Wrap(carrow.NewLimitError("data points with exemplars", math.MaxUint32))
 	}
 
 	if exemplars.Len() == 0 {
@@ -31,7 +31,6 @@ import (
 	"github.com/apache/arrow/go/v14/arrow"
 
 	"errors"
-	"math"
 
 	"github.com/open-telemetry/otel-arrow/pkg/otel/common/schema"
 	"github.com/open-telemetry/otel-arrow/pkg/otel/constants"
@@ -320,10 +319,8 @@ func (a *HDPAccumulator) Append(
 	parentID uint16,
 	hdps pmetric.HistogramDataPointSlice,
 ) {
-	if a.groupCount == math.MaxUint32 {
-		panic("The maximum number of group of histogram data points has been reached (max is uint32).")
-	}
-
+	// The groups are bounded by the number of metrics, which
+	// MetricsBuilder.Append limits.
 	if hdps.Len() == 0 {
 		return
 	}
@@ -84,7 +84,7 @@ func NewMetricsBuilder(
 
 	relatedData, err := NewRelatedData(cfg, stats, observer)
 	if err != nil {
-		panic(err)
+		return nil, werror.Wrap(err)
 	}
 
 	if stats.SchemaStats {
@@ -169,7 +169,7 @@ func (b *MetricsBuilder) Append(metrics pmetric.Metrics) error {
 		b.analyzer.ShowStats("")
 	}
 
-	metricID := uint16(0)
+	metricID := 0
 	resID := int64(-1)
 	scopeID := int64(-1)
 	var resMetricsID, scopeMetricsID string
@@ -178,7 +178,10 @@ func (b *MetricsBuilder) Append(metrics pmetric.Metrics) error {
 	b.builder.Reserve(len(optimizedMetrics.Metrics))
 
 	for _, metric := range optimizedMetrics.Metrics {
-		ID := metricID
+		if metricID > math.MaxUint16 {
+			return werror.Wrap(carrow.NewLimitError("metrics", math.MaxUint16+1))
+		}
+		ID := uint16(metricID)
 
 		b.ib.Append(ID)
 		metricID++
@@ -198,7 +201,10 @@ func (b *MetricsBuilder) Append(metrics pmetric.Metrics) error {
 			}
 		}
 		// Check resID validity
-		if resID == -1 || resID > math.MaxUint16 {
+		if resID > math.MaxUint16 {
+			return werror.Wrap(carrow.NewLimitError("resources", math.MaxUint16+1))
+		}
+		if resID == -1 {
 			return werror.WrapWithContext(carrow.ErrInvalidResourceID, map[string]interface{}{
 				"resource_id": resID,
 			})
@@ -223,7 +229,10 @@ func (b *MetricsBuilder) Append(metrics pmetric.Metrics) error {
 			}
 		}
 		// Check scopeID validity
-		if scopeID == -1 || scopeID > math.MaxUint16 {
+		if scopeID > math.MaxUint16 {
+			return werror.Wrap(carrow.NewLimitError("scopes", math.MaxUint16+1))
+		}
+		if scopeID == -1 {
 			return werror.WrapWithContext(carrow.ErrInvalidScopeID, map[string]interface{}{
 				"scope_id": scopeID,
 			})
@@ -16,7 +16,6 @@ package arrow
 
 import (
 	"errors"
-	"math"
 	"sort"
 
 	"github.com/apache/arrow/go/v14/arrow"
@@ -263,10 +262,8 @@ func (a *SummaryAccumulator) Append(
 	parentID uint16,
 	summaries pmetric.SummaryDataPointSlice,
 ) {
-	if a.groupCount == math.MaxUint32 {
-		panic("The maximum number of group of summary data points has been reached (max is uint32).")
-	}
-
+	// The groups are bounded by the number of metrics, which
+	// MetricsBuilder.Append limits.
 	if summaries.Len() == 0 {
 		return
 	}
@@ -271,10 +271,7 @@ func EHistogramDataPointsStoreFrom(record arrow.Record, exemplarsStore *Exemplar
 			exemplars := exemplarsStore.ExemplarsByID(lastID)
 			exemplars.MoveAndAppendTo(hdp.Exemplars())
 
-			attrs := attrsStore.AttributesByID(lastID)
-			if attrs != nil {
-				attrs.CopyTo(hdp.Attributes())
-			}
+			attrsStore.CopyAttributesByID(lastID, hdp.Attributes())
 		}
 	}
 
@@ -193,10 +193,7 @@ func ExemplarsStoreFrom(
 		}
 
 		if ID != nil {
-			attrs := attrsStore.AttributesByDeltaID(*ID)
-			if attrs != nil {
-				attrs.CopyTo(exemplar.FilteredAttributes())
-			}
+			attrsStore.CopyAttributesByDeltaID(*ID, exemplar.FilteredAttributes())
 		}
 	}
 
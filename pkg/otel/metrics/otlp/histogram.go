@@ -255,10 +255,7 @@ func HistogramDataPointsStoreFrom(record arrow.Record, exemplarsStore *Exemplars
 			exemplars := exemplarsStore.ExemplarsByID(lastID)
 			exemplars.MoveAndAppendTo(hdp.Exemplars())
 
-			attrs := attrsStore.AttributesByID(lastID)
-			if attrs != nil {
-				attrs.CopyTo(hdp.Attributes())
-			}
+			attrsStore.CopyAttributesByID(lastID, hdp.Attributes())
 		}
 	}
 
@@ -18,6 +18,7 @@ import (
 	"github.com/apache/arrow/go/v14/arrow"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
 	arrowutils "github.com/open-telemetry/otel-arrow/pkg/arrow"
 	"github.com/open-telemetry/otel-arrow/pkg/otel/common/otlp"
 	"github.com/open-telemetry/otel-arrow/pkg/otel/constants"
@@ -48,21 +49,42 @@ type (
 // record must be released by the caller.
 func MetricsFrom(record arrow.Record, relatedData *RelatedData) (pmetric.Metrics, error) {
 	metrics := pmetric.NewMetrics()
+	return metrics, MetricsInto(metrics, record, relatedData)
+}
 
+// MetricsInto decodes the given Arrow Record into metrics, which may
+// hold the metrics of an earlier batch, e.g., pooled by the caller.
+// Its resource metrics, scope metrics and metrics are reused by
+// position and the ones left over are removed, so that metrics holds
+// exactly the decoded metrics.
+//
+// Important Note: This function doesn't take ownership of the record, so the
+// record must be released by the caller.
+func MetricsInto(metrics pmetric.Metrics, record arrow.Record, relatedData *RelatedData) error {
 	if relatedData == nil {
-		return metrics, werror.Wrap(otlp.ErrMissingRelatedData)
+		return werror.Wrap(otlp.ErrMissingRelatedData)
 	}
 
 	metricsIDs, err := SchemaToIds(record.Schema())
 	if err != nil {
-		return metrics, werror.Wrap(err)
+		return werror.Wrap(err)
 	}
 
 	var resMetrics pmetric.ResourceMetrics
 	var scopeMetricsSlice pmetric.ScopeMetricsSlice
 	var metricSlice pmetric.MetricSlice
 
+	// The number of elements decoded in the current resource
+	// metrics and scope metrics slices.
+	var resIdx, scopeIdx int
+
+	// The number of resources is remembered as the size of the
+	// metrics payload.
 	resMetricsSlice := metrics.ResourceMetrics()
+	resMetricsSlice.EnsureCapacity(relatedData.CapacityHints.Hint(colarspb.ArrowPayloadType_UNIVARIATE_METRICS))
+	defer func() {
+		relatedData.CapacityHints.Observe(colarspb.ArrowPayloadType_UNIVARIATE_METRICS, resMetricsSlice.Len())
+	}()
 	rows := int(record.NumRows())
 
 	prevResID := None
@@ -76,16 +98,21 @@ func MetricsFrom(record arrow.Record, relatedData *RelatedData) (pmetric.Metrics
 		resDeltaID, err := otlp.ResourceIDFromRecord(record, row, metricsIDs.Resource)
 		resID += resDeltaID
 		if err != nil {
-			return metrics, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		if prevResID != int(resID) {
 			prevResID = int(resID)
-			resMetrics = resMetricsSlice.AppendEmpty()
+			if resIdx != 0 {
+				otlp.Truncate[pmetric.ScopeMetrics](scopeMetricsSlice, scopeIdx)
+			}
+			resMetrics = otlp.ElementAt(resMetricsSlice, resIdx, resetResourceMetrics)
+			resIdx++
 			scopeMetricsSlice = resMetrics.ScopeMetrics()
+			scopeIdx = 0
 			prevScopeID = None
 			schemaUrl, err := otlp.UpdateResourceFromRecord(resMetrics.Resource(), record, row, metricsIDs.Resource, relatedData.ResAttrMapStore)
 			if err != nil {
-				return metrics, werror.Wrap(err)
+				return werror.Wrap(err)
 			}
 			resMetrics.SetSchemaUrl(schemaUrl)
 		}
@@ -94,19 +121,20 @@ func MetricsFrom(record arrow.Record, relatedData *RelatedData) (pmetric.Metrics
 		scopeDeltaID, err := otlp.ScopeIDFromRecord(record, row, metricsIDs.Scope)
 		scopeID += scopeDeltaID
 		if err != nil {
-			return metrics, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		if prevScopeID != int(scopeID) {
 			prevScopeID = int(scopeID)
-			scopeMetrics := scopeMetricsSlice.AppendEmpty()
+			scopeMetrics := otlp.ElementAt(scopeMetricsSlice, scopeIdx, resetScopeMetrics)
+			scopeIdx++
 			metricSlice = scopeMetrics.Metrics()
 			if err = otlp.UpdateScopeFromRecord(scopeMetrics.Scope(), record, row, metricsIDs.Scope, relatedData.ScopeAttrMapStore); err != nil {
-				return metrics, werror.Wrap(err)
+				return werror.Wrap(err)
 			}
 
 			schemaUrl, err := arrowutils.StringFromRecord(record, metricsIDs.SchemaUrl, row)
 			if err != nil {
-				return metrics, werror.Wrap(err)
+				return werror.Wrap(err)
 			}
 			scopeMetrics.SetSchemaUrl(schemaUrl)
 		}
@@ -115,41 +143,41 @@ func MetricsFrom(record arrow.Record, relatedData *RelatedData) (pmetric.Metrics
 		metric := metricSlice.AppendEmpty()
 		deltaID, err := arrowutils.U16FromRecord(record, metricsIDs.ID, row)
 		if err != nil {
-			return metrics, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		ID := relatedData.MetricIDFromDelta(deltaID)
 
 		metricType, err := arrowutils.U8FromRecord(record, metricsIDs.MetricType, row)
 		if err != nil {
-			return metrics, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 
 		name, err := arrowutils.StringFromRecord(record, metricsIDs.Name, row)
 		if err != nil {
-			return metrics, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		metric.SetName(name)
 
 		description, err := arrowutils.StringFromRecord(record, metricsIDs.Description, row)
 		if err != nil {
-			return metrics, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		metric.SetDescription(description)
 
 		unit, err := arrowutils.StringFromRecord(record, metricsIDs.Unit, row)
 		if err != nil {
-			return metrics, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		metric.SetUnit(unit)
 
 		aggregationTemporality, err := arrowutils.I32FromRecord(record, metricsIDs.AggregationTemporality, row)
 		if err != nil {
-			return metrics, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 
 		isMonotonic, err := arrowutils.BoolFromRecord(record, metricsIDs.IsMonotonic, row)
 		if err != nil {
-			return metrics, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 
 		switch pmetric.MetricType(metricType) {
@@ -182,8 +210,23 @@ func MetricsFrom(record arrow.Record, relatedData *RelatedData) (pmetric.Metrics
 		}
 
 	}
+	if resIdx != 0 {
+		otlp.Truncate[pmetric.ScopeMetrics](scopeMetricsSlice, scopeIdx)
+	}
+	otlp.Truncate[pmetric.ResourceMetrics](resMetricsSlice, resIdx)
+
+	return err
+}
+
+func resetResourceMetrics(rm pmetric.ResourceMetrics) {
+	otlp.ResetResource(rm.Resource())
+}
 
-	return metrics, err
+// resetScopeMetrics clears the metrics of sm, which are not reused
+// since a metric cannot be reset to no data.
+func resetScopeMetrics(sm pmetric.ScopeMetrics) {
+	otlp.ResetScope(sm.Scope())
+	otlp.Truncate[pmetric.Metric](sm.Metrics(), 0)
 }
 
 func SchemaToIds(schema *arrow.Schema) (*MetricsIds, error) {
@@ -184,10 +184,7 @@ func NumberDataPointsStoreFrom(record arrow.Record, exemplarsStore *ExemplarsSto
 			exemplars := exemplarsStore.ExemplarsByID(lastID)
 			exemplars.MoveAndAppendTo(ndp.Exemplars())
 
-			attrs := attrsStore.AttributesByID(lastID)
-			if attrs != nil {
-				attrs.CopyTo(ndp.Attributes())
-			}
+			attrsStore.CopyAttributesByID(lastID, ndp.Attributes())
 		}
 	}
 
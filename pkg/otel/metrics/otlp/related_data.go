@@ -50,6 +50,10 @@ type (
 		NumberDataPointExemplarsStore     *ExemplarsStore
 		HistogramDataPointExemplarsStore  *ExemplarsStore
 		EHistogramDataPointExemplarsStore *ExemplarsStore
+
+		// CapacityHints pre-size the stores and the metrics of
+		// the batch, nil for no hints.
+		CapacityHints *otlp.CapacityHints
 	}
 )
 
@@ -76,16 +80,54 @@ func NewRelatedData() *RelatedData {
 	}
 }
 
+// Release releases the attributes records retained by the RelatedData.
+func (r *RelatedData) Release() {
+	r.ResAttrMapStore.Release()
+	r.ScopeAttrMapStore.Release()
+	r.NumberDPAttrsStore.Release()
+	r.SummaryAttrsStore.Release()
+	r.HistogramAttrsStore.Release()
+	r.ExpHistogramAttrsStore.Release()
+	r.NumberDPExemplarAttrsStore.Release()
+	r.HistogramExemplarAttrsStore.Release()
+	r.ExpHistogramExemplarAttrsStore.Release()
+}
+
+// attributeStores returns the attributes stores by payload type.
+func (r *RelatedData) attributeStores() map[colarspb.ArrowPayloadType]otlp.ReservableStore {
+	return map[colarspb.ArrowPayloadType]otlp.ReservableStore{
+		colarspb.ArrowPayloadType_RESOURCE_ATTRS:                  r.ResAttrMapStore,
+		colarspb.ArrowPayloadType_SCOPE_ATTRS:                     r.ScopeAttrMapStore,
+		colarspb.ArrowPayloadType_NUMBER_DP_ATTRS:                 r.NumberDPAttrsStore,
+		colarspb.ArrowPayloadType_SUMMARY_DP_ATTRS:                r.SummaryAttrsStore,
+		colarspb.ArrowPayloadType_HISTOGRAM_DP_ATTRS:              r.HistogramAttrsStore,
+		colarspb.ArrowPayloadType_EXP_HISTOGRAM_DP_ATTRS:          r.ExpHistogramAttrsStore,
+		colarspb.ArrowPayloadType_NUMBER_DP_EXEMPLAR_ATTRS:        r.NumberDPExemplarAttrsStore,
+		colarspb.ArrowPayloadType_HISTOGRAM_DP_EXEMPLAR_ATTRS:     r.HistogramExemplarAttrsStore,
+		colarspb.ArrowPayloadType_EXP_HISTOGRAM_DP_EXEMPLAR_ATTRS: r.ExpHistogramExemplarAttrsStore,
+	}
+}
+
 func (r *RelatedData) MetricIDFromDelta(delta uint16) uint16 {
 	r.MetricID += delta
 	return r.MetricID
 }
 
-func RelatedDataFrom(records []*record_message.RecordMessage) (relatedData *RelatedData, metricsRecord *record_message.RecordMessage, err error) {
+// RelatedDataFrom builds the related data of the records of a batch.
+// The hints, which may be nil, pre-size the stores and are updated
+// with their sizes.
+func RelatedDataFrom(records []*record_message.RecordMessage, hints *otlp.CapacityHints) (relatedData *RelatedData, metricsRecord *record_message.RecordMessage, err error) {
+	related := NewRelatedData()
+	related.CapacityHints = hints
+	stores := related.attributeStores()
+	hints.ReserveStores(stores)
 	defer func() {
 		for _, record := range records {
 			record.Record().Release()
 		}
+		if err != nil {
+			related.Release()
+		}
 	}()
 
 	var numberDPRec *record_message.RecordMessage
@@ -96,7 +138,7 @@ func RelatedDataFrom(records []*record_message.RecordMessage) (relatedData *Rela
 	var histogramDBExRec *record_message.RecordMessage
 	var expHistogramDBExRec *record_message.RecordMessage
 
-	relatedData = NewRelatedData()
+	relatedData = related
 
 	for _, record := range records {
 		switch record.PayloadType() {
@@ -265,5 +307,6 @@ func RelatedDataFrom(records []*record_message.RecordMessage) (relatedData *Rela
 		}
 	}
 
+	hints.ObserveStores(stores)
 	return
 }
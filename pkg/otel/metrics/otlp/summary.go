@@ -186,10 +186,7 @@ func SummaryDataPointsStoreFrom(record arrow.Record, attrsStore *otlp.Attributes
 		sdp.SetFlags(pmetric.DataPointFlags(flags))
 
 		if ID != nil {
-			attrs := attrsStore.AttributesByDeltaID(*ID)
-			if attrs != nil {
-				attrs.CopyTo(sdp.Attributes())
-			}
+			attrsStore.CopyAttributesByDeltaID(*ID, sdp.Attributes())
 		}
 	}
 
@@ -158,8 +158,9 @@ func CheckEncodeDecode(t *testing.T, expectedRequest pmetricotlp.ExportRequest)
 		require.Error(t, schema.ErrSchemaNotUpToDate)
 	}
 
-	relatedData, _, err := otlp.RelatedDataFrom(relatedRecords)
+	relatedData, _, err := otlp.RelatedDataFrom(relatedRecords, nil)
 	require.NoError(t, err)
+	defer relatedData.Release()
 
 	// Convert the Arrow records back to OTLP.
 	metrics, err := otlp.MetricsFrom(record, relatedData)
@@ -219,7 +220,10 @@ func OneRoundOfMessUpArrowRecords(t *testing.T, expectedRequest pmetricotlp.Expo
 	// Mix up the Arrow records in such a way as to make decoding impossible.
 	mainRecordChanged, record, relatedRecords := common.MixUpArrowRecords(rng, record, relatedRecords)
 
-	relatedData, _, err := otlp.RelatedDataFrom(relatedRecords)
+	relatedData, _, err := otlp.RelatedDataFrom(relatedRecords, nil)
+	if relatedData != nil {
+		defer relatedData.Release()
+	}
 
 	// Convert the Arrow records back to OTLP.
 	_, err = otlp.MetricsFrom(record, relatedData)
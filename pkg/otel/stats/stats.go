@@ -34,6 +34,11 @@ type (
 		TracesBatchesProduced  uint64
 		StreamProducersCreated uint64
 		StreamProducersClosed  uint64
+		MemoryPressureResets   uint64
+		CapacityTrims          uint64
+		UncompressedPayloads   uint64
+		EmptyBatches           uint64
+		SchemaChurnReports     uint64
 		RecordBuilderStats     RecordBuilderStats
 
 		// SchemaStats is a flag that indicates whether to display schema stats.
@@ -97,6 +102,11 @@ func (s *ProducerStats) Reset() {
 	s.TracesBatchesProduced = 0
 	s.StreamProducersCreated = 0
 	s.StreamProducersClosed = 0
+	s.MemoryPressureResets = 0
+	s.CapacityTrims = 0
+	s.UncompressedPayloads = 0
+	s.EmptyBatches = 0
+	s.SchemaChurnReports = 0
 	s.RecordBuilderStats.Reset()
 }
 
@@ -114,6 +124,11 @@ func (s *ProducerStats) Show(indent string) {
 	fmt.Printf("%s- Traces batches produced: %d\n", indent, s.TracesBatchesProduced)
 	fmt.Printf("%s- Stream producers created: %d\n", indent, s.StreamProducersCreated)
 	fmt.Printf("%s- Stream producers closed: %d\n", indent, s.StreamProducersClosed)
+	fmt.Printf("%s- Memory pressure resets: %d\n", indent, s.MemoryPressureResets)
+	fmt.Printf("%s- Capacity trims: %d\n", indent, s.CapacityTrims)
+	fmt.Printf("%s- Uncompressed payloads: %d\n", indent, s.UncompressedPayloads)
+	fmt.Printf("%s- Empty batches: %d\n", indent, s.EmptyBatches)
+	fmt.Printf("%s- Schema churn reports: %d\n", indent, s.SchemaChurnReports)
 	fmt.Printf("%s- RecordBuilder:\n", indent)
 	s.RecordBuilderStats.Show(indent + "  ")
 }
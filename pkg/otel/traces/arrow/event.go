@@ -255,7 +255,7 @@ func (a *EventAccumulator) IsEmpty() bool {
 // Append appends a slice of events to the accumulator.
 func (a *EventAccumulator) Append(spanID uint16, events ptrace.SpanEventSlice) error {
 	if a.groupCount == math.MaxUint16 {
-		panic("The maximum number of group of events has been reached (max is uint16).")
+		return werror.Wrap(acommon.NewLimitError("spans with events", math.MaxUint16))
 	}
 
 	if events.Len() == 0 {
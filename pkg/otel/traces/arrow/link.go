@@ -258,7 +258,7 @@ func (a *LinkAccumulator) IsEmpty() bool {
 // Append appends a new link to the builder.
 func (a *LinkAccumulator) Append(spanID uint16, links ptrace.SpanLinkSlice) error {
 	if a.groupCount == math.MaxUint16 {
-		panic("The maximum number of group of links has been reached (max is uint16).")
+		return werror.Wrap(acommon.NewLimitError("spans with links", math.MaxUint16))
 	}
 
 	if links.Len() == 0 {
@@ -74,7 +74,53 @@ func NewTracesOptimizer(sorter SpanSorter) *TracesOptimizer {
 	}
 }
 
+// singleResScopeID identifies the resource and the scope of the spans
+// of a batch with a single resource and scope, so that these need
+// not be serialized to compute their IDs.  It must not be empty,
+// which would look like the initial value to TracesBuilder.Append.
+const singleResScopeID = "0"
+
 func (t *TracesOptimizer) Optimize(traces ptrace.Traces) *TracesOptimized {
+	resSpans := traces.ResourceSpans()
+	if resSpans.Len() == 1 && resSpans.At(0).ScopeSpans().Len() == 1 {
+		return t.optimizeSingleScope(resSpans.At(0))
+	}
+	return t.optimizeGeneral(traces)
+}
+
+// optimizeSingleScope flattens the spans of a batch with a single
+// resource and scope, the common case of a batch exported by an
+// agent.  No grouping is needed, and the flattened spans are
+// allocated at once.
+func (t *TracesOptimizer) optimizeSingleScope(resSpan ptrace.ResourceSpans) *TracesOptimized {
+	scopeSpan := resSpan.ScopeSpans().At(0)
+	spans := scopeSpan.Spans()
+
+	flattened := make([]FlattenedSpan, spans.Len())
+	tracesOptimized := &TracesOptimized{
+		Spans: make([]*FlattenedSpan, spans.Len()),
+	}
+	for k := range flattened {
+		flattened[k] = FlattenedSpan{
+			ResourceSpanID:    singleResScopeID,
+			Resource:          resSpan.Resource(),
+			ResourceSchemaUrl: resSpan.SchemaUrl(),
+			ScopeSpanID:       singleResScopeID,
+			Scope:             scopeSpan.Scope(),
+			ScopeSchemaUrl:    scopeSpan.SchemaUrl(),
+			Span:              spans.At(k),
+		}
+		tracesOptimized.Spans[k] = &flattened[k]
+	}
+
+	t.sorter.Sort(tracesOptimized.Spans)
+
+	return tracesOptimized
+}
+
+// optimizeGeneral flattens the spans of any batch, grouping them by
+// resource and scope.
+func (t *TracesOptimizer) optimizeGeneral(traces ptrace.Traces) *TracesOptimized {
 	tracesOptimized := &TracesOptimized{
 		Spans: make([]*FlattenedSpan, 0),
 	}
@@ -0,0 +1,92 @@
+/*
+ * Copyright The OpenTelemetry Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package arrow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// singleScopeTraces returns a batch of spans sharing one resource
+// and scope, as exported by an agent.
+func singleScopeTraces(count int) ptrace.Traces {
+	traces := ptrace.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.SetSchemaUrl("resource-schema")
+	rs.Resource().Attributes().PutStr("service.name", "agent")
+	rs.Resource().Attributes().PutStr("host.name", "host")
+	ss := rs.ScopeSpans().AppendEmpty()
+	ss.SetSchemaUrl("scope-schema")
+	ss.Scope().SetName("scope")
+	ss.Scope().SetVersion("v1")
+	for i := 0; i < count; i++ {
+		span := ss.Spans().AppendEmpty()
+		span.SetName(fmt.Sprint("span-", i%7))
+		span.SetTraceID(pcommon.TraceID{byte(i % 13), byte(i)})
+		span.SetSpanID(pcommon.SpanID{byte(i)})
+		span.SetStartTimestamp(pcommon.Timestamp(1e18 + i%5))
+		span.Attributes().PutInt("index", int64(i))
+	}
+	return traces
+}
+
+// TestOptimizeSingleScope verifies that batches with a single
+// resource and scope are flattened as by the general path.
+func TestOptimizeSingleScope(t *testing.T) {
+	optimizer := NewTracesOptimizer(SortSpansByResourceSpanIdScopeSpanIdNameTraceId())
+	traces := singleScopeTraces(100)
+
+	single := optimizer.Optimize(traces)
+	general := optimizer.optimizeGeneral(traces)
+
+	require.Equal(t, len(general.Spans), len(single.Spans))
+	for i := range general.Spans {
+		expect, actual := general.Spans[i], single.Spans[i]
+		require.Equal(t, expect.Span, actual.Span)
+		require.Equal(t, expect.Resource, actual.Resource)
+		require.Equal(t, expect.ResourceSchemaUrl, actual.ResourceSchemaUrl)
+		require.Equal(t, expect.Scope, actual.Scope)
+		require.Equal(t, expect.ScopeSchemaUrl, actual.ScopeSchemaUrl)
+		require.Equal(t, singleResScopeID, actual.ResourceSpanID)
+		require.Equal(t, singleResScopeID, actual.ScopeSpanID)
+	}
+}
+
+// BenchmarkOptimizeSingleScope compares the fast path for batches with
+// a single resource and scope against the general path.
+func BenchmarkOptimizeSingleScope(b *testing.B) {
+	optimizer := NewTracesOptimizer(SortSpansByResourceSpanIdScopeSpanIdNameTraceId())
+	traces := singleScopeTraces(1000)
+
+	b.Run("general", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			optimizer.optimizeGeneral(traces)
+		}
+	})
+	b.Run("single", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			optimizer.Optimize(traces)
+		}
+	})
+}
@@ -100,7 +100,7 @@ func NewTracesBuilder(
 
 	relatedData, err := NewRelatedData(cfg, stats, observer)
 	if err != nil {
-		panic(err)
+		return nil, werror.Wrap(err)
 	}
 
 	if stats.SchemaStats {
@@ -191,7 +191,7 @@ func (b *TracesBuilder) Append(traces ptrace.Traces) error {
 		b.analyzer.ShowStats("")
 	}
 
-	spanID := uint16(0)
+	spanID := 0
 	resID := int64(-1)
 	scopeID := int64(-1)
 	var resSpanID, scopeSpanID string
@@ -208,11 +208,14 @@ func (b *TracesBuilder) Append(traces ptrace.Traces) error {
 		spanEvents := span.Span.Events()
 		spanLinks := span.Span.Links()
 
-		ID := spanID
+		ID := uint16(spanID)
 		if spanAttrs.Len() == 0 && spanEvents.Len() == 0 && spanLinks.Len() == 0 {
 			// No related data found
 			b.ib.AppendNull()
 		} else {
+			if spanID > math.MaxUint16 {
+				return werror.Wrap(acommon.NewLimitError("spans with attributes, events, or links", math.MaxUint16+1))
+			}
 			b.ib.Append(ID)
 			spanID++
 		}
@@ -232,7 +235,10 @@ func (b *TracesBuilder) Append(traces ptrace.Traces) error {
 			}
 		}
 		// Check resID validity
-		if resID == -1 || resID > math.MaxUint16 {
+		if resID > math.MaxUint16 {
+			return werror.Wrap(acommon.NewLimitError("resources", math.MaxUint16+1))
+		}
+		if resID == -1 {
 			return werror.WrapWithContext(acommon.ErrInvalidResourceID, map[string]interface{}{
 				"resource_id": resID,
 			})
@@ -257,7 +263,10 @@ func (b *TracesBuilder) Append(traces ptrace.Traces) error {
 			}
 		}
 		// Check scopeID validity
-		if scopeID == -1 || scopeID > math.MaxUint16 {
+		if scopeID > math.MaxUint16 {
+			return werror.Wrap(acommon.NewLimitError("scopes", math.MaxUint16+1))
+		}
+		if scopeID == -1 {
 			return werror.WrapWithContext(acommon.ErrInvalidScopeID, map[string]interface{}{
 				"scope_id": scopeID,
 			})
@@ -126,10 +126,7 @@ func SpanEventsStoreFrom(
 		event.SetName(name)
 
 		if ID != nil {
-			attrs := attrsStore.AttributesByDeltaID(*ID)
-			if attrs != nil {
-				attrs.CopyTo(event.Attributes())
-			}
+			attrsStore.CopyAttributesByDeltaID(*ID, event.Attributes())
 		}
 
 		event.SetDroppedAttributesCount(dac)
@@ -140,10 +140,7 @@ func SpanLinksStoreFrom(
 		link.TraceState().FromRaw(traceState)
 
 		if ID != nil {
-			attrs := attrsStore.AttributesByDeltaID(*ID)
-			if attrs != nil {
-				attrs.CopyTo(link.Attributes())
-			}
+			attrsStore.CopyAttributesByDeltaID(*ID, link.Attributes())
 		}
 
 		link.SetDroppedAttributesCount(dac)
@@ -38,6 +38,10 @@ type (
 		SpanLinkAttrMapStore  *otlp.Attributes32Store
 		SpanEventsStore       *SpanEventsStore
 		SpanLinksStore        *SpanLinksStore
+
+		// CapacityHints pre-size the stores and the traces of
+		// the batch, nil for no hints.
+		CapacityHints *otlp.CapacityHints
 	}
 )
 
@@ -53,22 +57,52 @@ func NewRelatedData(conf *arrow.Config) *RelatedData {
 	}
 }
 
+// Release releases the attributes records retained by the RelatedData.
+func (r *RelatedData) Release() {
+	r.ResAttrMapStore.Release()
+	r.ScopeAttrMapStore.Release()
+	r.SpanAttrMapStore.Release()
+	r.SpanEventAttrMapStore.Release()
+	r.SpanLinkAttrMapStore.Release()
+}
+
+// attributeStores returns the attributes stores by payload type.
+func (r *RelatedData) attributeStores() map[colarspb.ArrowPayloadType]otlp.ReservableStore {
+	return map[colarspb.ArrowPayloadType]otlp.ReservableStore{
+		colarspb.ArrowPayloadType_RESOURCE_ATTRS:   r.ResAttrMapStore,
+		colarspb.ArrowPayloadType_SCOPE_ATTRS:      r.ScopeAttrMapStore,
+		colarspb.ArrowPayloadType_SPAN_ATTRS:       r.SpanAttrMapStore,
+		colarspb.ArrowPayloadType_SPAN_EVENT_ATTRS: r.SpanEventAttrMapStore,
+		colarspb.ArrowPayloadType_SPAN_LINK_ATTRS:  r.SpanLinkAttrMapStore,
+	}
+}
+
 func (r *RelatedData) SpanIDFromDelta(delta uint16) uint16 {
 	r.SpanID += delta
 	return r.SpanID
 }
 
-func RelatedDataFrom(records []*record_message.RecordMessage, conf *arrow.Config) (relatedData *RelatedData, tracesRecord *record_message.RecordMessage, err error) {
+// RelatedDataFrom builds the related data of the records of a batch.
+// The hints, which may be nil, pre-size the stores and are updated
+// with their sizes.
+func RelatedDataFrom(records []*record_message.RecordMessage, conf *arrow.Config, hints *otlp.CapacityHints) (relatedData *RelatedData, tracesRecord *record_message.RecordMessage, err error) {
+	related := NewRelatedData(conf)
+	related.CapacityHints = hints
+	stores := related.attributeStores()
+	hints.ReserveStores(stores)
 	defer func() {
 		for _, record := range records {
 			record.Record().Release()
 		}
+		if err != nil {
+			related.Release()
+		}
 	}()
 
 	var spanEventRecord *record_message.RecordMessage
 	var spanLinkRecord *record_message.RecordMessage
 
-	relatedData = NewRelatedData(conf)
+	relatedData = related
 
 	// Scan the records to find the traces record and the span event record.
 	// Create the attribute map stores for all the attribute records.
@@ -144,5 +178,6 @@ func RelatedDataFrom(records []*record_message.RecordMessage, conf *arrow.Config
 		}
 	}
 
+	hints.ObserveStores(stores)
 	return
 }
@@ -21,6 +21,7 @@ import (
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
 	arrowutils "github.com/open-telemetry/otel-arrow/pkg/arrow"
 	"github.com/open-telemetry/otel-arrow/pkg/otel/common"
 	"github.com/open-telemetry/otel-arrow/pkg/otel/common/otlp"
@@ -65,21 +66,42 @@ type (
 // record must be released by the caller.
 func TracesFrom(record arrow.Record, relatedData *RelatedData) (ptrace.Traces, error) {
 	traces := ptrace.NewTraces()
+	return traces, TracesInto(traces, record, relatedData)
+}
 
+// TracesInto decodes the given Arrow Record into traces, which may
+// hold the spans of an earlier batch, e.g., pooled by the caller.  Its
+// resource spans, scope spans and spans are reused by position and
+// the ones left over are removed, so that traces holds exactly the
+// decoded spans.
+//
+// Important Note: This function doesn't take ownership of the record, so the
+// record must be released by the caller.
+func TracesInto(traces ptrace.Traces, record arrow.Record, relatedData *RelatedData) error {
 	if relatedData == nil {
-		return traces, werror.Wrap(otlp.ErrMissingRelatedData)
+		return werror.Wrap(otlp.ErrMissingRelatedData)
 	}
 
 	traceIDs, err := SchemaToIds(record.Schema())
 	if err != nil {
-		return traces, err
+		return err
 	}
 
 	var resSpans ptrace.ResourceSpans
 	var scopeSpansSlice ptrace.ScopeSpansSlice
 	var spanSlice ptrace.SpanSlice
 
+	// The number of elements decoded in the current resource
+	// spans, scope spans and span slices.
+	var resIdx, scopeIdx, spanIdx int
+
+	// The number of resources is remembered as the size of the
+	// spans payload.
 	resSpansSlice := traces.ResourceSpans()
+	resSpansSlice.EnsureCapacity(relatedData.CapacityHints.Hint(colarspb.ArrowPayloadType_SPANS))
+	defer func() {
+		relatedData.CapacityHints.Observe(colarspb.ArrowPayloadType_SPANS, resSpansSlice.Len())
+	}()
 	rows := int(record.NumRows())
 
 	prevResID := None
@@ -93,18 +115,24 @@ func TracesFrom(record arrow.Record, relatedData *RelatedData) (ptrace.Traces, e
 		resDeltaID, err := otlp.ResourceIDFromRecord(record, row, traceIDs.Resource)
 		resID += resDeltaID
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 
 		if prevResID != int(resID) {
 			prevResID = int(resID)
-			resSpans = resSpansSlice.AppendEmpty()
+			if resIdx != 0 {
+				otlp.Truncate[ptrace.Span](spanSlice, spanIdx)
+				otlp.Truncate[ptrace.ScopeSpans](scopeSpansSlice, scopeIdx)
+			}
+			resSpans = otlp.ElementAt(resSpansSlice, resIdx, resetResourceSpans)
+			resIdx++
 			scopeSpansSlice = resSpans.ScopeSpans()
+			scopeIdx = 0
 			prevScopeID = None
 
 			schemaUrl, err := otlp.UpdateResourceFromRecord(resSpans.Resource(), record, row, traceIDs.Resource, relatedData.ResAttrMapStore)
 			if err != nil {
-				return traces, werror.Wrap(err)
+				return werror.Wrap(err)
 			}
 			resSpans.SetSchemaUrl(schemaUrl)
 		}
@@ -113,99 +141,105 @@ func TracesFrom(record arrow.Record, relatedData *RelatedData) (ptrace.Traces, e
 		scopeDeltaID, err := otlp.ScopeIDFromRecord(record, row, traceIDs.Scope)
 		scopeID += scopeDeltaID
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		if prevScopeID != int(scopeID) {
 			prevScopeID = int(scopeID)
-			scopeSpans := scopeSpansSlice.AppendEmpty()
+			if scopeIdx != 0 {
+				otlp.Truncate[ptrace.Span](spanSlice, spanIdx)
+			}
+			scopeSpans := otlp.ElementAt(scopeSpansSlice, scopeIdx, resetScopeSpans)
+			scopeIdx++
 			spanSlice = scopeSpans.Spans()
+			spanIdx = 0
 			if err = otlp.UpdateScopeFromRecord(scopeSpans.Scope(), record, row, traceIDs.Scope, relatedData.ScopeAttrMapStore); err != nil {
-				return traces, werror.Wrap(err)
+				return werror.Wrap(err)
 			}
 
 			schemaUrl, err := arrowutils.StringFromRecord(record, traceIDs.SchemaUrl, row)
 			if err != nil {
-				return traces, werror.Wrap(err)
+				return werror.Wrap(err)
 			}
 			scopeSpans.SetSchemaUrl(schemaUrl)
 		}
 
 		// Process span fields
-		span := spanSlice.AppendEmpty()
+		span := otlp.ElementAt(spanSlice, spanIdx, resetSpan)
+		spanIdx++
 		deltaID, err := arrowutils.NullableU16FromRecord(record, traceIDs.ID, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 
 		traceID, err := arrowutils.FixedSizeBinaryFromRecord(record, traceIDs.TraceID, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		if len(traceID) != 16 {
-			return traces, werror.WrapWithContext(common.ErrInvalidTraceIDLength, map[string]interface{}{"traceID": traceID})
+			return werror.WrapWithContext(common.ErrInvalidTraceIDLength, map[string]interface{}{"traceID": traceID})
 		}
 		spanID, err := arrowutils.FixedSizeBinaryFromRecord(record, traceIDs.SpanID, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		if len(spanID) != 8 {
-			return traces, werror.WrapWithContext(common.ErrInvalidSpanIDLength, map[string]interface{}{"spanID": spanID})
+			return werror.WrapWithContext(common.ErrInvalidSpanIDLength, map[string]interface{}{"spanID": spanID})
 		}
 		traceState, err := arrowutils.StringFromRecord(record, traceIDs.TraceState, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		parentSpanID, err := arrowutils.FixedSizeBinaryFromRecord(record, traceIDs.ParentSpanID, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		if parentSpanID != nil && len(parentSpanID) != 8 {
-			return traces, werror.WrapWithContext(common.ErrInvalidSpanIDLength, map[string]interface{}{"parentSpanID": parentSpanID})
+			return werror.WrapWithContext(common.ErrInvalidSpanIDLength, map[string]interface{}{"parentSpanID": parentSpanID})
 		}
 		name, err := arrowutils.StringFromRecord(record, traceIDs.Name, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		kind, err := arrowutils.I32FromRecord(record, traceIDs.Kind, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		startTimeUnixNano, err := arrowutils.TimestampFromRecord(record, traceIDs.StartTimeUnixNano, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		durationNano, err := arrowutils.DurationFromRecord(record, traceIDs.DurationTimeUnixNano, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		endTimeUnixNano := startTimeUnixNano.ToTime(arrow.Nanosecond).Add(time.Duration(durationNano))
 		droppedAttributesCount, err := arrowutils.U32FromRecord(record, traceIDs.DropAttributesCount, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		droppedEventsCount, err := arrowutils.U32FromRecord(record, traceIDs.DropEventsCount, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		droppedLinksCount, err := arrowutils.U32FromRecord(record, traceIDs.DropLinksCount, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		statusArr, err := arrowutils.StructFromRecord(record, traceIDs.Status.Status, row)
 		if err != nil {
-			return traces, werror.Wrap(err)
+			return werror.Wrap(err)
 		}
 		if statusArr != nil {
 			// Status exists
 			message, err := arrowutils.StringFromStruct(statusArr, row, traceIDs.Status.Message)
 			if err != nil {
-				return traces, werror.Wrap(err)
+				return werror.Wrap(err)
 			}
 			span.Status().SetMessage(message)
 
 			code, err := arrowutils.I32FromStruct(statusArr, row, traceIDs.Status.Code)
 			if err != nil {
-				return traces, werror.Wrap(err)
+				return werror.Wrap(err)
 			}
 			span.Status().SetCode(ptrace.StatusCode(code))
 		}
@@ -214,10 +248,7 @@ func TracesFrom(record arrow.Record, relatedData *RelatedData) (ptrace.Traces, e
 			ID := relatedData.SpanIDFromDelta(*deltaID)
 
 			spanAttrs := span.Attributes()
-			attrs := relatedData.SpanAttrMapStore.AttributesByID(ID)
-			if attrs != nil {
-				attrs.CopyTo(spanAttrs)
-			}
+			relatedData.SpanAttrMapStore.CopyAttributesByID(ID, spanAttrs)
 
 			events := relatedData.SpanEventsStore.EventsByID(ID)
 			eventSlice := span.Events()
@@ -252,7 +283,26 @@ func TracesFrom(record arrow.Record, relatedData *RelatedData) (ptrace.Traces, e
 		span.SetDroppedEventsCount(droppedEventsCount)
 		span.SetDroppedLinksCount(droppedLinksCount)
 	}
-	return traces, err
+	if resIdx != 0 {
+		otlp.Truncate[ptrace.Span](spanSlice, spanIdx)
+		otlp.Truncate[ptrace.ScopeSpans](scopeSpansSlice, scopeIdx)
+	}
+	otlp.Truncate[ptrace.ResourceSpans](resSpansSlice, resIdx)
+	return err
+}
+
+var emptySpan = ptrace.NewSpan()
+
+func resetResourceSpans(rs ptrace.ResourceSpans) {
+	otlp.ResetResource(rs.Resource())
+}
+
+func resetScopeSpans(ss ptrace.ScopeSpans) {
+	otlp.ResetScope(ss.Scope())
+}
+
+func resetSpan(span ptrace.Span) {
+	emptySpan.CopyTo(span)
 }
 
 func SchemaToIds(schema *arrow.Schema) (*SpanIDs, error) {
@@ -315,8 +315,9 @@ func CheckEncodeDecode(
 		require.Error(t, acommon.ErrSchemaNotUpToDate)
 	}
 
-	relatedData, _, err := tracesotlp.RelatedDataFrom(relatedRecords, tracesarrow.NewConfig(conf))
+	relatedData, _, err := tracesotlp.RelatedDataFrom(relatedRecords, tracesarrow.NewConfig(conf), nil)
 	require.NoError(t, err)
+	defer relatedData.Release()
 
 	// Convert the Arrow record back to OTLP.
 	traces, err := tracesotlp.TracesFrom(record, relatedData)
@@ -374,7 +375,10 @@ func CheckEncodeMessUpDecode(
 	// Mix up the Arrow records in such a way as to make decoding impossible.
 	mainRecordChanged, record, relatedRecords := common.MixUpArrowRecords(rng, record, relatedRecords)
 
-	relatedData, _, err := tracesotlp.RelatedDataFrom(relatedRecords, tracesarrow.NewConfig(conf))
+	relatedData, _, err := tracesotlp.RelatedDataFrom(relatedRecords, tracesarrow.NewConfig(conf), nil)
+	if relatedData != nil {
+		defer relatedData.Release()
+	}
 
 	// Convert the Arrow record back to OTLP.
 	_, err = tracesotlp.TracesFrom(record, relatedData)
@@ -439,8 +443,9 @@ func checkTracesConversion(t *testing.T, expectedRequest ptraceotlp.ExportReques
 		require.Error(t, acommon.ErrSchemaNotUpToDate)
 	}
 
-	relatedData, _, err := tracesotlp.RelatedDataFrom(relatedRecords, tracesarrow.NewConfig(conf))
+	relatedData, _, err := tracesotlp.RelatedDataFrom(relatedRecords, tracesarrow.NewConfig(conf), nil)
 	require.NoError(t, err)
+	defer relatedData.Release()
 
 	// Convert the Arrow records back to OTLP.
 	traces, err := tracesotlp.TracesFrom(record, relatedData)
@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zstddict
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func testSamples(n int) [][]byte {
+	var samples [][]byte
+	for i := 0; i < n; i++ {
+		samples = append(samples, []byte(fmt.Sprintf(
+			"schema{resource:{service.name:string,host.name:string},spans:{trace_id:fixed16,name:string}} row=%d name=GET /api/v1/items/%d", i, i%7)))
+	}
+	return samples
+}
+
+func TestTrainAndUse(t *testing.T) {
+	_, err := Train(nil, 0)
+	require.ErrorIs(t, err, ErrNoSamples)
+
+	samples := testSamples(200)
+	d, err := Train(samples, 4096)
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(d), 4096+1024)
+
+	id, err := ID(d)
+	require.NoError(t, err)
+	require.NotZero(t, id)
+
+	_, err = ID([]byte("not a dictionary"))
+	require.Error(t, err)
+
+	plainEnc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer plainEnc.Close()
+	dictEnc, err := zstd.NewWriter(nil, zstd.WithEncoderDict(d))
+	require.NoError(t, err)
+	defer dictEnc.Close()
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderDicts(d))
+	require.NoError(t, err)
+	defer dec.Close()
+
+	var plainSize, dictSize int
+	for _, s := range samples {
+		plainSize += len(plainEnc.EncodeAll(s, nil))
+		frame := dictEnc.EncodeAll(s, nil)
+		dictSize += len(frame)
+
+		require.True(t, IsFrame(frame))
+		out, err := dec.DecodeAll(frame, nil)
+		require.NoError(t, err)
+		require.Equal(t, s, out)
+	}
+	require.Less(t, dictSize, plainSize)
+
+	require.False(t, IsFrame([]byte{0xff, 0xff, 0xff, 0xff}))
+}
+
+func TestTrainDegenerate(t *testing.T) {
+	var samples [][]byte
+	for i := 0; i < 200; i++ {
+		samples = append(samples, []byte(fmt.Sprintf("service.name=checkout span=GET /items/%d row=%d", i%7, i)))
+	}
+	// The builder panics on this input, which is reported as
+	// an error.
+	_, err := Train(samples, 4096)
+	require.ErrorContains(t, err, "training failed")
+}
@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// The batch functions re-batch captured data in order, as a batch
+// processor would, into batches of size items.  Resources and scopes
+// are repeated in every batch that contains their items.
+
+func batchTraces(td ptrace.Traces, size int) []ptrace.Traces {
+	var batches []ptrace.Traces
+	var cur ptrace.Traces
+	count := 0
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		var destRS ptrace.ResourceSpans
+		haveRS := false
+		sss := rs.ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			ss := sss.At(j)
+			var destSS ptrace.ScopeSpans
+			haveSS := false
+			spans := ss.Spans()
+			for k := 0; k < spans.Len(); k++ {
+				if count == 0 {
+					cur = ptrace.NewTraces()
+					batches = append(batches, cur)
+					haveRS, haveSS = false, false
+				}
+				if !haveRS {
+					destRS = cur.ResourceSpans().AppendEmpty()
+					rs.Resource().CopyTo(destRS.Resource())
+					destRS.SetSchemaUrl(rs.SchemaUrl())
+					haveRS = true
+				}
+				if !haveSS {
+					destSS = destRS.ScopeSpans().AppendEmpty()
+					ss.Scope().CopyTo(destSS.Scope())
+					destSS.SetSchemaUrl(ss.SchemaUrl())
+					haveSS = true
+				}
+				spans.At(k).CopyTo(destSS.Spans().AppendEmpty())
+				if count++; count == size {
+					count = 0
+				}
+			}
+		}
+	}
+	return batches
+}
+
+func batchLogs(ld plog.Logs, size int) []plog.Logs {
+	var batches []plog.Logs
+	var cur plog.Logs
+	count := 0
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		var destRL plog.ResourceLogs
+		haveRL := false
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			sl := sls.At(j)
+			var destSL plog.ScopeLogs
+			haveSL := false
+			records := sl.LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				if count == 0 {
+					cur = plog.NewLogs()
+					batches = append(batches, cur)
+					haveRL, haveSL = false, false
+				}
+				if !haveRL {
+					destRL = cur.ResourceLogs().AppendEmpty()
+					rl.Resource().CopyTo(destRL.Resource())
+					destRL.SetSchemaUrl(rl.SchemaUrl())
+					haveRL = true
+				}
+				if !haveSL {
+					destSL = destRL.ScopeLogs().AppendEmpty()
+					sl.Scope().CopyTo(destSL.Scope())
+					destSL.SetSchemaUrl(sl.SchemaUrl())
+					haveSL = true
+				}
+				records.At(k).CopyTo(destSL.LogRecords().AppendEmpty())
+				if count++; count == size {
+					count = 0
+				}
+			}
+		}
+	}
+	return batches
+}
+
+// batchMetrics counts data points but does not split metrics, so a
+// batch may exceed size by up to one metric's data points.
+func batchMetrics(md pmetric.Metrics, size int) []pmetric.Metrics {
+	var batches []pmetric.Metrics
+	var cur pmetric.Metrics
+	count := 0
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		var destRM pmetric.ResourceMetrics
+		haveRM := false
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			sm := sms.At(j)
+			var destSM pmetric.ScopeMetrics
+			haveSM := false
+			metrics := sm.Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				if count == 0 {
+					cur = pmetric.NewMetrics()
+					batches = append(batches, cur)
+					haveRM, haveSM = false, false
+				}
+				if !haveRM {
+					destRM = cur.ResourceMetrics().AppendEmpty()
+					rm.Resource().CopyTo(destRM.Resource())
+					destRM.SetSchemaUrl(rm.SchemaUrl())
+					haveRM = true
+				}
+				if !haveSM {
+					destSM = destRM.ScopeMetrics().AppendEmpty()
+					sm.Scope().CopyTo(destSM.Scope())
+					destSM.SetSchemaUrl(sm.SchemaUrl())
+					haveSM = true
+				}
+				metrics.At(k).CopyTo(destSM.Metrics().AppendEmpty())
+				if count += dataPoints(metrics.At(k)); count >= size {
+					count = 0
+				}
+			}
+		}
+	}
+	return batches
+}
+
+// dataPoints returns the number of data points of a metric, at
+// least one so that empty metrics make progress.
+func dataPoints(m pmetric.Metric) int {
+	n := 0
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		n = m.Gauge().DataPoints().Len()
+	case pmetric.MetricTypeSum:
+		n = m.Sum().DataPoints().Len()
+	case pmetric.MetricTypeHistogram:
+		n = m.Histogram().DataPoints().Len()
+	case pmetric.MetricTypeExponentialHistogram:
+		n = m.ExponentialHistogram().DataPoints().Len()
+	case pmetric.MetricTypeSummary:
+		n = m.Summary().DataPoints().Len()
+	}
+	if n == 0 {
+		return 1
+	}
+	return n
+}
@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/grpc"
+
+	"github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+)
+
+// capture accumulates telemetry of each signal, in arrival order,
+// to be re-batched by the estimator.
+type capture struct {
+	lock     sync.Mutex
+	traces   ptrace.Traces
+	metrics  pmetric.Metrics
+	logs     plog.Logs
+	requests int
+}
+
+func newCapture() *capture {
+	return &capture{
+		traces:  ptrace.NewTraces(),
+		metrics: pmetric.NewMetrics(),
+		logs:    plog.NewLogs(),
+	}
+}
+
+func (c *capture) addTraces(td ptrace.Traces) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.requests++
+	td.ResourceSpans().MoveAndAppendTo(c.traces.ResourceSpans())
+}
+
+func (c *capture) addMetrics(md pmetric.Metrics) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.requests++
+	md.ResourceMetrics().MoveAndAppendTo(c.metrics.ResourceMetrics())
+}
+
+func (c *capture) addLogs(ld plog.Logs) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.requests++
+	ld.ResourceLogs().MoveAndAppendTo(c.logs.ResourceLogs())
+}
+
+// readFile adds the newline-delimited OTLP/JSON export requests of
+// a file, e.g., the output of the file exporter.
+func (c *capture) readFile(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	for num, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if err := c.addJSON(line); err != nil {
+			return fmt.Errorf("%s:%d: %w", file, num+1, err)
+		}
+	}
+	return nil
+}
+
+func (c *capture) addJSON(line []byte) error {
+	signal, err := arrow_record.DetectJSONSignal(line)
+	if err != nil {
+		return err
+	}
+	switch signal {
+	case arrow_record.JSONTraces:
+		var un ptrace.JSONUnmarshaler
+		td, err := un.UnmarshalTraces(line)
+		if err != nil {
+			return err
+		}
+		c.addTraces(td)
+	case arrow_record.JSONMetrics:
+		var un pmetric.JSONUnmarshaler
+		md, err := un.UnmarshalMetrics(line)
+		if err != nil {
+			return err
+		}
+		c.addMetrics(md)
+	default:
+		var un plog.JSONUnmarshaler
+		ld, err := un.UnmarshalLogs(line)
+		if err != nil {
+			return err
+		}
+		c.addLogs(ld)
+	}
+	return nil
+}
+
+// The tap types are OTLP gRPC services that add every request to
+// the capture and return success.
+
+type tracesTap struct {
+	ptraceotlp.UnimplementedGRPCServer
+	*capture
+}
+
+type metricsTap struct {
+	pmetricotlp.UnimplementedGRPCServer
+	*capture
+}
+
+type logsTap struct {
+	plogotlp.UnimplementedGRPCServer
+	*capture
+}
+
+func (t *tracesTap) Export(_ context.Context, req ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error) {
+	t.addTraces(req.Traces())
+	return ptraceotlp.NewExportResponse(), nil
+}
+
+func (t *metricsTap) Export(_ context.Context, req pmetricotlp.ExportRequest) (pmetricotlp.ExportResponse, error) {
+	t.addMetrics(req.Metrics())
+	return pmetricotlp.NewExportResponse(), nil
+}
+
+func (t *logsTap) Export(_ context.Context, req plogotlp.ExportRequest) (plogotlp.ExportResponse, error) {
+	t.addLogs(req.Logs())
+	return plogotlp.NewExportResponse(), nil
+}
+
+// registerTap registers OTLP services for all signals that add to
+// the capture.
+func (c *capture) registerTap(srv *grpc.Server) {
+	ptraceotlp.RegisterGRPCServer(srv, &tracesTap{capture: c})
+	pmetricotlp.RegisterGRPCServer(srv, &metricsTap{capture: c})
+	plogotlp.RegisterGRPCServer(srv, &logsTap{capture: c})
+}
@@ -0,0 +1,292 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/klauspost/compress/zstd"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/protobuf/proto"
+
+	colarspb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+)
+
+// Codecs are the encodings an estimate is made for.  The
+// compression of "otlp+" and "arrow+" codecs is applied to each
+// message, as gRPC does.
+const (
+	codecOTLP          = "otlp"
+	codecOTLPGzip      = "otlp+gzip"
+	codecOTLPZstd      = "otlp+zstd"
+	codecArrow         = "arrow"
+	codecArrowZstd     = "arrow+zstd"
+	codecArrowIPCZstd  = "arrow+ipczstd"
+	defaultCodecsValue = codecOTLP + "," + codecOTLPGzip + "," + codecOTLPZstd + "," + codecArrow + "," + codecArrowZstd + "," + codecArrowIPCZstd
+)
+
+var knownCodecs = map[string]bool{
+	codecOTLP:         true,
+	codecOTLPGzip:     true,
+	codecOTLPZstd:     true,
+	codecArrow:        true,
+	codecArrowZstd:    true,
+	codecArrowIPCZstd: true,
+}
+
+// parseCodecs parses a comma-separated list of codecs.
+func parseCodecs(s string) ([]string, error) {
+	var codecs []string
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		if !knownCodecs[c] {
+			return nil, fmt.Errorf("unknown codec: %q", c)
+		}
+		codecs = append(codecs, c)
+	}
+	return codecs, nil
+}
+
+// result is the projected size of one signal's data at one batch
+// size with one codec.
+type result struct {
+	signal    string
+	batchSize int
+	codec     string
+	batches   int
+	items     int
+	bytes     int
+}
+
+// estimator computes results for a capture.
+type estimator struct {
+	codecs     []string
+	batchSizes []int
+
+	// batchesPerStream restarts the simulated Arrow stream after
+	// this many batches, as max_stream_lifetime would, or never
+	// when zero.
+	batchesPerStream int
+
+	gzipBuf bytes.Buffer
+	gzipW   *gzip.Writer
+	zstdEnc *zstd.Encoder
+}
+
+func newEstimator(codecs []string, batchSizes []int, batchesPerStream int) (*estimator, error) {
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	e := &estimator{
+		codecs:           codecs,
+		batchSizes:       batchSizes,
+		batchesPerStream: batchesPerStream,
+		zstdEnc:          enc,
+	}
+	e.gzipW = gzip.NewWriter(&e.gzipBuf)
+	return e, nil
+}
+
+func (e *estimator) close() {
+	e.zstdEnc.Close()
+}
+
+// signalBatches is one signal's data at one batch size, with
+// functions to encode a batch as OTLP and as Arrow.
+type signalBatches struct {
+	signal string
+	items  int
+	count  int
+	otlp   func(i int) ([]byte, error)
+	arrow  func(p *arrow_record.Producer, i int) (*colarspb.BatchArrowRecords, error)
+}
+
+// estimate returns results for every signal present in the
+// capture, batch size, and codec.
+func (e *estimator) estimate(c *capture) ([]result, error) {
+	var results []result
+	for _, size := range e.batchSizes {
+		for _, sb := range e.signals(c, size) {
+			if sb.items == 0 {
+				continue
+			}
+			for _, codec := range e.codecs {
+				n, err := e.encode(sb, codec)
+				if err != nil {
+					return nil, fmt.Errorf("%s: batch size %d: %s: %w", sb.signal, size, codec, err)
+				}
+				results = append(results, result{
+					signal:    sb.signal,
+					batchSize: size,
+					codec:     codec,
+					batches:   sb.count,
+					items:     sb.items,
+					bytes:     n,
+				})
+			}
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].signal < results[j].signal
+	})
+	return results, nil
+}
+
+func (e *estimator) signals(c *capture, size int) []signalBatches {
+	traces := batchTraces(c.traces, size)
+	metrics := batchMetrics(c.metrics, size)
+	logs := batchLogs(c.logs, size)
+	return []signalBatches{
+		{
+			signal: "traces",
+			items:  c.traces.SpanCount(),
+			count:  len(traces),
+			otlp: func(i int) ([]byte, error) {
+				return ptraceotlp.NewExportRequestFromTraces(traces[i]).MarshalProto()
+			},
+			arrow: func(p *arrow_record.Producer, i int) (*colarspb.BatchArrowRecords, error) {
+				return p.BatchArrowRecordsFromTraces(traces[i])
+			},
+		},
+		{
+			signal: "metrics",
+			items:  c.metrics.DataPointCount(),
+			count:  len(metrics),
+			otlp: func(i int) ([]byte, error) {
+				return pmetricotlp.NewExportRequestFromMetrics(metrics[i]).MarshalProto()
+			},
+			arrow: func(p *arrow_record.Producer, i int) (*colarspb.BatchArrowRecords, error) {
+				return p.BatchArrowRecordsFromMetrics(metrics[i])
+			},
+		},
+		{
+			signal: "logs",
+			items:  c.logs.LogRecordCount(),
+			count:  len(logs),
+			otlp: func(i int) ([]byte, error) {
+				return plogotlp.NewExportRequestFromLogs(logs[i]).MarshalProto()
+			},
+			arrow: func(p *arrow_record.Producer, i int) (*colarspb.BatchArrowRecords, error) {
+				return p.BatchArrowRecordsFromLogs(logs[i])
+			},
+		},
+	}
+}
+
+// encode returns the total size of a signal's batches in codec.
+func (e *estimator) encode(sb signalBatches, codec string) (int, error) {
+	total := 0
+	if codec == codecOTLP || codec == codecOTLPGzip || codec == codecOTLPZstd {
+		for i := 0; i < sb.count; i++ {
+			data, err := sb.otlp(i)
+			if err != nil {
+				return 0, err
+			}
+			n, err := e.compress(codec, data)
+			if err != nil {
+				return 0, err
+			}
+			total += n
+		}
+		return total, nil
+	}
+
+	opt := config.WithNoZstd()
+	if codec == codecArrowIPCZstd {
+		opt = config.WithZstd()
+	}
+	var producer *arrow_record.Producer
+	defer func() {
+		if producer != nil {
+			_ = producer.Close()
+		}
+	}()
+	for i := 0; i < sb.count; i++ {
+		if producer == nil || (e.batchesPerStream > 0 && i%e.batchesPerStream == 0) {
+			if producer != nil {
+				_ = producer.Close()
+			}
+			producer = arrow_record.NewProducerWithOptions(opt)
+		}
+		batch, err := sb.arrow(producer, i)
+		if err != nil {
+			return 0, err
+		}
+		data, err := proto.Marshal(batch)
+		if err != nil {
+			return 0, err
+		}
+		n, err := e.compress(codec, data)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// compress returns the size of one message in codec.
+func (e *estimator) compress(codec string, data []byte) (int, error) {
+	switch codec {
+	case codecOTLPGzip:
+		e.gzipBuf.Reset()
+		e.gzipW.Reset(&e.gzipBuf)
+		if _, err := e.gzipW.Write(data); err != nil {
+			return 0, err
+		}
+		if err := e.gzipW.Close(); err != nil {
+			return 0, err
+		}
+		return e.gzipBuf.Len(), nil
+	case codecOTLPZstd, codecArrowZstd:
+		return len(e.zstdEnc.EncodeAll(data, nil)), nil
+	default:
+		return len(data), nil
+	}
+}
+
+// report prints results as a table.  The ratio compares each
+// codec with uncompressed OTLP at the same batch size.
+func report(w io.Writer, results []result) error {
+	otlp := map[string]int{}
+	for _, r := range results {
+		if r.codec == codecOTLP {
+			otlp[fmt.Sprint(r.signal, r.batchSize)] = r.bytes
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "signal\tbatch size\tcodec\tbatches\titems\tbytes\tbytes/item\tvs otlp\t")
+	for _, r := range results {
+		ratio := "-"
+		if base := otlp[fmt.Sprint(r.signal, r.batchSize)]; base != 0 {
+			ratio = fmt.Sprintf("%.1f%%", 100*float64(r.bytes)/float64(base))
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%d\t%d\t%.1f\t%s\t\n",
+			r.signal, r.batchSize, r.codec, r.batches, r.items, r.bytes,
+			float64(r.bytes)/float64(r.items), ratio)
+	}
+	return tw.Flush()
+}
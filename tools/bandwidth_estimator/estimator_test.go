@@ -0,0 +1,187 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+)
+
+func testTraces(n int) ptrace.Traces {
+	ent := datagen.NewTestEntropy(1)
+	dg := datagen.NewTracesGenerator(ent, ent.NewStandardResourceAttributes(), ent.NewStandardInstrumentationScopes())
+	return dg.Generate(n, 100)
+}
+
+func TestBatchTraces(t *testing.T) {
+	td := testTraces(250)
+	batches := batchTraces(td, 100)
+	require.Len(t, batches, 3)
+	require.Equal(t, 100, batches[0].SpanCount())
+	require.Equal(t, 100, batches[1].SpanCount())
+	require.Equal(t, 50, batches[2].SpanCount())
+
+	// Order is preserved and resources travel with their spans.
+	var batched []ptrace.Span
+	for _, b := range batches {
+		rss := b.ResourceSpans()
+		for r := 0; r < rss.Len(); r++ {
+			require.Greater(t, rss.At(r).Resource().Attributes().Len(), 0)
+			batched = append(batched, spansOf(b.ResourceSpans().At(r))...)
+		}
+	}
+	var original []ptrace.Span
+	for r := 0; r < td.ResourceSpans().Len(); r++ {
+		original = append(original, spansOf(td.ResourceSpans().At(r))...)
+	}
+	require.Equal(t, len(original), len(batched))
+	for i := range original {
+		require.Equal(t, original[i].SpanID(), batched[i].SpanID())
+	}
+}
+
+func spansOf(rs ptrace.ResourceSpans) []ptrace.Span {
+	var spans []ptrace.Span
+	for i := 0; i < rs.ScopeSpans().Len(); i++ {
+		ss := rs.ScopeSpans().At(i).Spans()
+		for j := 0; j < ss.Len(); j++ {
+			spans = append(spans, ss.At(j))
+		}
+	}
+	return spans
+}
+
+func TestBatchLogsAndMetrics(t *testing.T) {
+	ld := plog.NewLogs()
+	sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	for i := 0; i < 7; i++ {
+		sl.LogRecords().AppendEmpty()
+	}
+	logs := batchLogs(ld, 3)
+	require.Len(t, logs, 3)
+	require.Equal(t, 1, logs[2].LogRecordCount())
+
+	md := pmetric.NewMetrics()
+	ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	for i := 0; i < 4; i++ {
+		g := ms.AppendEmpty().SetEmptyGauge()
+		g.DataPoints().AppendEmpty()
+		g.DataPoints().AppendEmpty()
+	}
+	ms.AppendEmpty().SetName("empty")
+
+	// Metrics are not split: 2+2 points fill a batch of 3.
+	metrics := batchMetrics(md, 3)
+	require.Len(t, metrics, 3)
+	require.Equal(t, 4, metrics[0].DataPointCount())
+	require.Equal(t, 4, metrics[1].DataPointCount())
+	require.Equal(t, 1, metrics[2].MetricCount())
+}
+
+func TestParseFlags(t *testing.T) {
+	sizes, err := parseBatchSizes("10, 100")
+	require.NoError(t, err)
+	require.Equal(t, []int{10, 100}, sizes)
+	for _, bad := range []string{"", "0", "x", "10,-1"} {
+		_, err := parseBatchSizes(bad)
+		require.Error(t, err, bad)
+	}
+
+	codecs, err := parseCodecs(defaultCodecsValue)
+	require.NoError(t, err)
+	require.Len(t, codecs, len(knownCodecs))
+	_, err = parseCodecs("otlp,arrow+lz4")
+	require.Error(t, err)
+}
+
+func TestEstimate(t *testing.T) {
+	c := newCapture()
+	var m ptrace.JSONMarshaler
+	var lines [][]byte
+	for i := 0; i < 10; i++ {
+		data, err := m.MarshalTraces(testTraces(20))
+		require.NoError(t, err)
+		lines = append(lines, data)
+	}
+	file := filepath.Join(t.TempDir(), "traces.json")
+	require.NoError(t, os.WriteFile(file, bytes.Join(lines, []byte("\n")), 0o600))
+	require.NoError(t, c.readFile(file))
+	require.Equal(t, 10, c.requests)
+
+	codecs, err := parseCodecs(defaultCodecsValue)
+	require.NoError(t, err)
+	est, err := newEstimator(codecs, []int{10, 200}, 5)
+	require.NoError(t, err)
+	defer est.close()
+
+	results, err := est.estimate(c)
+	require.NoError(t, err)
+	require.Len(t, results, 2*len(codecs))
+
+	sizes := map[string]int{}
+	for _, r := range results {
+		require.Equal(t, "traces", r.signal)
+		require.Equal(t, 200, r.items)
+		require.Equal(t, 200/r.batchSize, r.batches)
+		if r.batchSize == 200 {
+			sizes[r.codec] = r.bytes
+		}
+	}
+	require.Less(t, sizes[codecOTLPZstd], sizes[codecOTLP])
+	require.Less(t, sizes[codecArrow], sizes[codecOTLP])
+	require.Less(t, sizes[codecArrowZstd], sizes[codecArrow])
+
+	var out strings.Builder
+	require.NoError(t, report(&out, results))
+	require.Contains(t, out.String(), "arrow+ipczstd")
+	require.Contains(t, out.String(), "100.0%")
+}
+
+func TestTap(t *testing.T) {
+	c := newCapture()
+	srv := grpc.NewServer()
+	c.registerTap(srv)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Stop()
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := ptraceotlp.NewGRPCClient(conn)
+	for i := 0; i < 3; i++ {
+		_, err := client.Export(context.Background(), ptraceotlp.NewExportRequestFromTraces(testTraces(5)))
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, c.requests)
+	require.Equal(t, 15, c.traces.SpanCount())
+}
@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	ossignal "os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+var help = flag.Bool("help", false, "Show help")
+
+// This tool estimates the bandwidth of captured OTLP telemetry sent
+// using OTel Arrow, for several batch sizes and codecs, to help size
+// batching configuration before a rollout.  The capture is read from
+// newline-delimited OTLP/JSON files (e.g., the output of the file
+// exporter) or received by a live OTLP/gRPC tap with -listen.
+func main() {
+	listen := flag.String("listen", "", "Address of an OTLP/gRPC tap to capture from, instead of files")
+	duration := flag.Duration("duration", time.Minute, "Duration of a live capture, 0 to capture until interrupted")
+	sizesFlag := flag.String("batch-sizes", "100,1000,10000", "Comma-separated batch sizes, in spans, data points, or log records")
+	codecsFlag := flag.String("codecs", defaultCodecsValue, "Comma-separated codecs")
+	batchesPerStream := flag.Int("batches-per-stream", 0, "Restart each Arrow stream after this many batches, 0 for never")
+
+	flag.Parse()
+
+	if *help || (flag.NArg() == 0 && *listen == "") {
+		flag.Usage()
+		os.Exit(0)
+	}
+
+	sizes, err := parseBatchSizes(*sizesFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	codecs, err := parseCodecs(*codecsFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	c := newCapture()
+	if *listen != "" {
+		if err := tap(c, *listen, *duration); err != nil {
+			log.Fatal(err)
+		}
+	}
+	for _, file := range flag.Args() {
+		if err := c.readFile(file); err != nil {
+			log.Fatalf("read: %v", err)
+		}
+	}
+	log.Printf("Captured %d requests: %d spans, %d data points, %d log records\n",
+		c.requests, c.traces.SpanCount(), c.metrics.DataPointCount(), c.logs.LogRecordCount())
+
+	est, err := newEstimator(codecs, sizes, *batchesPerStream)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer est.close()
+
+	results, err := est.estimate(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := report(os.Stdout, results); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// tap captures OTLP/gRPC requests sent to addr for the duration, or
+// until interrupted.
+func tap(c *capture, addr string, duration time.Duration) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := grpc.NewServer()
+	c.registerTap(srv)
+
+	ctx, cancel := ossignal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+	go func() {
+		<-ctx.Done()
+		srv.GracefulStop()
+	}()
+
+	log.Printf("Capturing OTLP/gRPC on %s\n", ln.Addr())
+	return srv.Serve(ln)
+}
+
+func parseBatchSizes(s string) ([]int, error) {
+	var sizes []int
+	for _, f := range strings.Split(s, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("invalid batch size: %q", f)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
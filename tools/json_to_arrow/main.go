@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+)
+
+var help = flag.Bool("help", false, "Show help")
+
+var outputFile = ""
+
+// This tool encodes newline-delimited OTLP/JSON export requests of any
+// signal (e.g., the output of the file exporter) into OTel Arrow
+// BatchArrowRecords.  One stream producer is used for all the input,
+// so the sizes reported reflect Arrow's stream-level dictionaries.
+// When -output is set, the batches are written as size-delimited
+// protobuf messages.
+func main() {
+	flag.StringVar(&outputFile, "output", outputFile, "Output file of size-delimited BatchArrowRecords")
+
+	flag.Parse()
+
+	if *help || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(0)
+	}
+
+	var out io.Writer
+	if outputFile != "" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			log.Fatalf("create: %s: %v", outputFile, err)
+		}
+		defer f.Close()
+		bw := bufio.NewWriter(f)
+		defer bw.Flush()
+		out = bw
+	}
+
+	producer := arrow_record.NewProducer()
+	defer producer.Close()
+
+	var jsonBytes, arrowBytes, batches int
+
+	for _, file := range flag.Args() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatalf("read: %s: %v", file, err)
+		}
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+			batch, err := producer.BatchArrowRecordsFromJSON(line)
+			if err != nil {
+				log.Fatalf("encode: %s: %v", file, err)
+			}
+			batches++
+			jsonBytes += len(line)
+			arrowBytes += proto.Size(batch)
+
+			if out != nil {
+				if _, err := protodelim.MarshalTo(out, batch); err != nil {
+					log.Fatalf("write: %s: %v", outputFile, err)
+				}
+			}
+		}
+	}
+
+	log.Printf("Encoded %d batches: %d bytes OTLP/JSON, %d bytes Arrow\n", batches, jsonBytes, arrowBytes)
+}
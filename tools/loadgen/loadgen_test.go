@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseMix(t *testing.T) {
+	m, err := parseMix("traces=3, logs ,metrics=0")
+	require.NoError(t, err)
+	require.Equal(t, []signal{signalTraces, signalLogs}, m.signals)
+	require.Equal(t, 4, m.total)
+
+	rnd := rand.New(rand.NewSource(1))
+	counts := map[signal]int{}
+	for i := 0; i < 4000; i++ {
+		counts[m.pick(rnd)]++
+	}
+	require.Zero(t, counts[signalMetrics])
+	require.InDelta(t, 3000, counts[signalTraces], 200)
+
+	for _, bad := range []string{"", "spans=1", "traces=x", "traces=-1", "logs=0"} {
+		_, err := parseMix(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestPacer(t *testing.T) {
+	require.Nil(t, newPacer(0))
+
+	p := newPacer(100)
+	now := time.Now()
+	require.Equal(t, now, p.reserve(now, 50))
+	require.Equal(t, now.Add(500*time.Millisecond), p.reserve(now, 50))
+	require.Equal(t, now.Add(time.Second), p.reserve(now, 10))
+
+	// No credit accumulates while idle.
+	later := now.Add(time.Minute)
+	require.Equal(t, later, p.reserve(later, 10))
+}
+
+func TestStatsReport(t *testing.T) {
+	start := time.Now()
+	st := newStats(start)
+	for i := 1; i <= 100; i++ {
+		st.record(10, time.Duration(i)*time.Millisecond, nil)
+	}
+	st.record(10, time.Second, status.Error(codes.Unavailable, "down"))
+	st.record(10, time.Second, errors.New("plain"))
+
+	line := st.report(start.Add(2 * time.Second))
+	require.Contains(t, line, "batches=102")
+	require.Contains(t, line, "acks/s=50.0")
+	require.Contains(t, line, "items/s=500.0")
+	require.Contains(t, line, "p50=50ms")
+	require.Contains(t, line, "p99=99ms")
+	require.Contains(t, line, "errors=Unknown:1,Unavailable:1")
+
+	// The interval resets, the total does not.
+	line = st.report(start.Add(3 * time.Second))
+	require.Contains(t, line, "batches=0")
+	require.Contains(t, line, "errors=none")
+	require.True(t, strings.HasPrefix(st.summary(start.Add(4*time.Second)), "total batches=102"))
+}
@@ -0,0 +1,188 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	ossignal "os/signal"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+var help = flag.Bool("help", false, "Show help")
+
+// This command drives an OTel Arrow receiver (or a standard OTLP
+// receiver, e.g., in front of an exporter pipeline) at a target rate
+// and reports acks/sec, latency percentiles, and error codes.
+func main() {
+	endpoint := flag.String("endpoint", "localhost:4317", "Receiver gRPC endpoint")
+	protocol := flag.String("protocol", "arrow", "Protocol: arrow or otlp")
+	useTLS := flag.Bool("tls", false, "Use TLS")
+	compression := flag.String("compression", "none", "gRPC compression: none or gzip")
+	rate := flag.Float64("rate", 10000, "Target items (spans, data points, log records) per second, 0 for unlimited")
+	concurrency := flag.Int("concurrency", 4, "Number of concurrent senders, each with one batch in flight")
+	batchSize := flag.Int("batch-size", 100, "Batch size passed to the data generators")
+	mixFlag := flag.String("mix", "traces=1,metrics=1,logs=1", "Signal mix as comma-separated signal=weight pairs")
+	duration := flag.Duration("duration", time.Minute, "Duration of the run, 0 to run until interrupted")
+	reportEvery := flag.Duration("report", 10*time.Second, "Reporting interval")
+	timeout := flag.Duration("timeout", 10*time.Second, "Per-batch timeout")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "Data generator seed")
+
+	flag.Parse()
+
+	if *help {
+		flag.Usage()
+		os.Exit(0)
+	}
+
+	m, err := parseMix(*mixFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *concurrency < 1 {
+		log.Fatal("concurrency must be at least 1")
+	}
+	if *protocol != "arrow" && *protocol != "otlp" {
+		log.Fatalf("unknown protocol: %q", *protocol)
+	}
+
+	dialOpts := []grpc.DialOption{}
+	if *useTLS {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
+	} else {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	switch *compression {
+	case "none":
+	case "gzip":
+		dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	default:
+		log.Fatalf("unknown compression: %q", *compression)
+	}
+
+	conn, err := grpc.NewClient(*endpoint, dialOpts...)
+	if err != nil {
+		log.Fatal("dial: ", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := ossignal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if *duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	st := newStats(time.Now())
+	pc := newPacer(*rate)
+
+	var wg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		w := &worker{
+			mix:       m,
+			batchSize: *batchSize,
+			timeout:   *timeout,
+			gen:       newGenerator(*seed + int64(i)),
+			rnd:       rand.New(rand.NewSource(*seed + int64(i))),
+			pacer:     pc,
+			stats:     st,
+			senders:   map[signal]sender{},
+		}
+		for _, sig := range m.signals {
+			if *protocol == "arrow" {
+				w.senders[sig] = newArrowSender(ctx, conn, sig)
+			} else {
+				w.senders[sig] = newOTLPSender(conn)
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.run(ctx)
+		}()
+	}
+
+	ticker := time.NewTicker(*reportEvery)
+	defer ticker.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			fmt.Println(st.report(now))
+			continue
+		case <-done:
+		}
+		break
+	}
+	fmt.Println(st.summary(time.Now()))
+}
+
+// worker sends one batch at a time, so that the number of workers
+// is the number of batches in flight.
+type worker struct {
+	mix       mix
+	batchSize int
+	timeout   time.Duration
+	gen       *generator
+	rnd       *rand.Rand
+	pacer     *pacer
+	stats     *stats
+	senders   map[signal]sender
+}
+
+func (w *worker) run(ctx context.Context) {
+	defer func() {
+		for _, s := range w.senders {
+			s.close()
+		}
+	}()
+
+	for ctx.Err() == nil {
+		sig := w.mix.pick(w.rnd)
+		data, items := w.gen.generate(sig, w.batchSize)
+
+		if err := w.pacer.wait(ctx, items); err != nil {
+			return
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, w.timeout)
+		start := time.Now()
+		err := w.senders[sig].send(sendCtx, data)
+		cancel()
+
+		if err != nil && ctx.Err() != nil {
+			// Interrupted by the end of the run.
+			return
+		}
+		w.stats.record(items, time.Since(start), err)
+	}
+}
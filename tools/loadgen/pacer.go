@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pacer spaces batches shared by all workers so that the aggregate
+// rate approaches a target in items per second.
+type pacer struct {
+	lock sync.Mutex
+	rate float64
+	next time.Time
+}
+
+// newPacer returns a pacer for itemsPerSec, or nil when the rate is
+// unlimited.
+func newPacer(itemsPerSec float64) *pacer {
+	if itemsPerSec <= 0 {
+		return nil
+	}
+	return &pacer{rate: itemsPerSec}
+}
+
+// reserve returns the time at which a batch of items may be sent.
+func (p *pacer) reserve(now time.Time, items int) time.Time {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// Do not accumulate credit while the target is not being
+	// kept up with.
+	if p.next.Before(now) {
+		p.next = now
+	}
+	at := p.next
+	p.next = p.next.Add(time.Duration(float64(items) / p.rate * float64(time.Second)))
+	return at
+}
+
+// wait blocks until a batch of items may be sent.
+func (p *pacer) wait(ctx context.Context, items int) error {
+	if p == nil {
+		return nil
+	}
+	delay := time.Until(p.reserve(time.Now(), items))
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
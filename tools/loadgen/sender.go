@@ -0,0 +1,177 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+)
+
+// sender delivers one batch and waits for its acknowledgement.
+type sender interface {
+	send(ctx context.Context, data any) error
+	close()
+}
+
+// arrowStream is the interface shared by the three Arrow stream
+// clients.
+type arrowStream interface {
+	Send(*arrowpb.BatchArrowRecords) error
+	Recv() (*arrowpb.BatchStatus, error)
+	CloseSend() error
+}
+
+// arrowSender sends over a long-lived Arrow stream, which is
+// re-established with a new producer after any stream error.  A
+// batch that times out breaks the stream.
+type arrowSender struct {
+	runCtx   context.Context
+	conn     *grpc.ClientConn
+	sig      signal
+	cancel   context.CancelFunc
+	stream   arrowStream
+	producer *arrowRecord.Producer
+}
+
+func newArrowSender(runCtx context.Context, conn *grpc.ClientConn, sig signal) *arrowSender {
+	return &arrowSender{
+		runCtx: runCtx,
+		conn:   conn,
+		sig:    sig,
+	}
+}
+
+func (s *arrowSender) open() error {
+	ctx, cancel := context.WithCancel(s.runCtx)
+	var stream arrowStream
+	var err error
+	switch s.sig {
+	case signalTraces:
+		stream, err = arrowpb.NewArrowTracesServiceClient(s.conn).ArrowTraces(ctx)
+	case signalMetrics:
+		stream, err = arrowpb.NewArrowMetricsServiceClient(s.conn).ArrowMetrics(ctx)
+	default:
+		stream, err = arrowpb.NewArrowLogsServiceClient(s.conn).ArrowLogs(ctx)
+	}
+	if err != nil {
+		cancel()
+		return err
+	}
+	s.cancel = cancel
+	s.stream = stream
+	s.producer = arrowRecord.NewProducer()
+	return nil
+}
+
+func (s *arrowSender) send(ctx context.Context, data any) error {
+	if s.stream == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	stop := context.AfterFunc(ctx, s.cancel)
+	defer stop()
+
+	var batch *arrowpb.BatchArrowRecords
+	var err error
+	switch data := data.(type) {
+	case ptrace.Traces:
+		batch, err = s.producer.BatchArrowRecordsFromTraces(data)
+	case pmetric.Metrics:
+		batch, err = s.producer.BatchArrowRecordsFromMetrics(data)
+	case plog.Logs:
+		batch, err = s.producer.BatchArrowRecordsFromLogs(data)
+	default:
+		err = fmt.Errorf("unsupported data type: %T", data)
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if err = s.stream.Send(batch); err != nil {
+		s.close()
+		return err
+	}
+	resp, err := s.stream.Recv()
+	if err != nil {
+		s.close()
+		return err
+	}
+	if resp.BatchId != batch.BatchId {
+		s.close()
+		return status.Errorf(codes.Internal, "unexpected batch ID: %d, expected %d", resp.BatchId, batch.BatchId)
+	}
+	if resp.StatusCode != arrowpb.StatusCode_OK {
+		// Arrow status codes match gRPC codes.
+		return status.Error(codes.Code(resp.StatusCode), resp.StatusMessage)
+	}
+	return nil
+}
+
+func (s *arrowSender) close() {
+	if s.stream == nil {
+		return
+	}
+	_ = s.stream.CloseSend()
+	s.cancel()
+	_ = s.producer.Close()
+	s.stream = nil
+	s.producer = nil
+}
+
+// otlpSender sends standard OTLP unary requests.
+type otlpSender struct {
+	traces  ptraceotlp.GRPCClient
+	metrics pmetricotlp.GRPCClient
+	logs    plogotlp.GRPCClient
+}
+
+func newOTLPSender(conn *grpc.ClientConn) *otlpSender {
+	return &otlpSender{
+		traces:  ptraceotlp.NewGRPCClient(conn),
+		metrics: pmetricotlp.NewGRPCClient(conn),
+		logs:    plogotlp.NewGRPCClient(conn),
+	}
+}
+
+func (s *otlpSender) send(ctx context.Context, data any) error {
+	var err error
+	switch data := data.(type) {
+	case ptrace.Traces:
+		_, err = s.traces.Export(ctx, ptraceotlp.NewExportRequestFromTraces(data))
+	case pmetric.Metrics:
+		_, err = s.metrics.Export(ctx, pmetricotlp.NewExportRequestFromMetrics(data))
+	case plog.Logs:
+		_, err = s.logs.Export(ctx, plogotlp.NewExportRequestFromLogs(data))
+	default:
+		err = fmt.Errorf("unsupported data type: %T", data)
+	}
+	return err
+}
+
+func (s *otlpSender) close() {}
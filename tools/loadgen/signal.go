@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/otel-arrow/pkg/datagen"
+)
+
+// signal identifies one of the three OTLP signals.
+type signal string
+
+const (
+	signalTraces  signal = "traces"
+	signalMetrics signal = "metrics"
+	signalLogs    signal = "logs"
+)
+
+// mix is a weighted choice of signals.
+type mix struct {
+	signals []signal
+	weights []int
+	total   int
+}
+
+// parseMix parses a comma-separated list of signal=weight pairs,
+// e.g., "traces=3,metrics=1".  A signal without weight counts 1.
+func parseMix(s string) (mix, error) {
+	var m mix
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weightStr, hasWeight := strings.Cut(part, "=")
+		weight := 1
+		if hasWeight {
+			var err error
+			weight, err = strconv.Atoi(weightStr)
+			if err != nil || weight < 0 {
+				return mix{}, fmt.Errorf("invalid weight for %q: %q", name, weightStr)
+			}
+		}
+		sig := signal(name)
+		switch sig {
+		case signalTraces, signalMetrics, signalLogs:
+		default:
+			return mix{}, fmt.Errorf("unknown signal: %q", name)
+		}
+		if weight == 0 {
+			continue
+		}
+		m.signals = append(m.signals, sig)
+		m.weights = append(m.weights, weight)
+		m.total += weight
+	}
+	if m.total == 0 {
+		return mix{}, fmt.Errorf("signal mix is empty: %q", s)
+	}
+	return m, nil
+}
+
+// pick chooses a signal according to the weights.
+func (m mix) pick(rnd *rand.Rand) signal {
+	n := rnd.Intn(m.total)
+	for i, w := range m.weights {
+		if n < w {
+			return m.signals[i]
+		}
+		n -= w
+	}
+	return m.signals[len(m.signals)-1]
+}
+
+// generator produces synthetic batches.  Not safe for concurrent
+// use; each worker has its own.
+type generator struct {
+	traces  *datagen.TraceGenerator
+	metrics *datagen.MetricsGenerator
+	logs    *datagen.LogsGenerator
+}
+
+func newGenerator(seed int64) *generator {
+	entropy := datagen.NewTestEntropy(seed)
+	resAttrs := entropy.NewStandardResourceAttributes()
+	scopes := entropy.NewStandardInstrumentationScopes()
+	return &generator{
+		traces:  datagen.NewTracesGenerator(entropy, resAttrs, scopes),
+		metrics: datagen.NewMetricsGenerator(entropy, resAttrs, scopes),
+		logs:    datagen.NewLogsGenerator(entropy, resAttrs, scopes),
+	}
+}
+
+// generate returns a batch of the signal and its number of items
+// (spans, data points, or log records).
+func (g *generator) generate(sig signal, batchSize int) (any, int) {
+	switch sig {
+	case signalTraces:
+		td := g.traces.Generate(batchSize, time.Minute)
+		return td, td.SpanCount()
+	case signalMetrics:
+		md := g.metrics.GenerateAllKindOfMetrics(batchSize, time.Minute)
+		return md, md.DataPointCount()
+	default:
+		ld := g.logs.Generate(batchSize, time.Minute)
+		return ld, ld.LogRecordCount()
+	}
+}
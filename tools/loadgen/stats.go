@@ -0,0 +1,147 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// stats accumulates the results of one reporting interval and of
+// the whole run.  It is safe for concurrent use.
+type stats struct {
+	lock sync.Mutex
+
+	start     time.Time
+	lastReset time.Time
+
+	interval window
+	total    window
+}
+
+// window is a set of results over a period of time.
+type window struct {
+	sent      int64
+	acked     int64
+	items     int64
+	latencies []time.Duration
+	errors    map[codes.Code]int64
+}
+
+func newStats(now time.Time) *stats {
+	return &stats{
+		start:     now,
+		lastReset: now,
+		interval:  window{errors: map[codes.Code]int64{}},
+		total:     window{errors: map[codes.Code]int64{}},
+	}
+}
+
+// record accounts for one batch of items that completed after
+// latency with err.
+func (s *stats) record(items int, latency time.Duration, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, w := range []*window{&s.interval, &s.total} {
+		w.sent++
+		if err != nil {
+			w.errors[status.Code(err)]++
+			continue
+		}
+		w.acked++
+		w.items += int64(items)
+		w.latencies = append(w.latencies, latency)
+	}
+}
+
+// report formats the current interval and starts a new one.
+func (s *stats) report(now time.Time) string {
+	s.lock.Lock()
+	w := s.interval
+	since := now.Sub(s.lastReset)
+	s.interval = window{errors: map[codes.Code]int64{}}
+	s.lastReset = now
+	s.lock.Unlock()
+
+	return fmt.Sprintf("elapsed=%s %s", now.Sub(s.start).Round(time.Second), w.format(since))
+}
+
+// summary formats the results of the whole run.
+func (s *stats) summary(now time.Time) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return fmt.Sprintf("total %s", s.total.format(now.Sub(s.start)))
+}
+
+func (w *window) format(period time.Duration) string {
+	secs := period.Seconds()
+	if secs <= 0 {
+		secs = 1
+	}
+	sort.Slice(w.latencies, func(i, j int) bool {
+		return w.latencies[i] < w.latencies[j]
+	})
+	return fmt.Sprintf("batches=%d acks/s=%.1f items/s=%.1f p50=%s p90=%s p99=%s max=%s errors=%s",
+		w.sent,
+		float64(w.acked)/secs,
+		float64(w.items)/secs,
+		percentile(w.latencies, 0.50),
+		percentile(w.latencies, 0.90),
+		percentile(w.latencies, 0.99),
+		percentile(w.latencies, 1),
+		formatErrors(w.errors),
+	)
+}
+
+// percentile returns the q-th quantile of sorted latencies.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(q*float64(len(sorted))+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// formatErrors prints error counts by gRPC code in a stable order.
+func formatErrors(errs map[codes.Code]int64) string {
+	if len(errs) == 0 {
+		return "none"
+	}
+	keys := make([]codes.Code, 0, len(errs))
+	for code := range errs {
+		keys = append(keys, code)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	parts := make([]string, 0, len(keys))
+	for _, code := range keys {
+		parts = append(parts, fmt.Sprintf("%s:%d", code, errs[code]))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/otel-arrow/pkg/config"
+	"github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	"github.com/open-telemetry/otel-arrow/pkg/zstddict"
+)
+
+var help = flag.Bool("help", false, "Show help")
+
+var (
+	outputFile       = "zstd.dict"
+	maxSize          = zstddict.DefaultMaxSize
+	batchesPerStream = 10
+)
+
+// This tool trains a Zstd dictionary for OTel Arrow payloads from
+// newline-delimited OTLP/JSON export requests of any signal (e.g.,
+// the output of the file exporter).  Each input line is encoded as
+// one batch, so the input should use the batch size of the
+// deployment.  A new stream producer is started every
+// -batches-per-stream batches, so that the samples include the
+// schemas and dictionaries sent at the start of each stream.
+func main() {
+	flag.StringVar(&outputFile, "output", outputFile, "Output dictionary file")
+	flag.IntVar(&maxSize, "size", maxSize, "Maximum dictionary size in bytes")
+	flag.IntVar(&batchesPerStream, "batches-per-stream", batchesPerStream, "Number of batches per simulated stream")
+
+	flag.Parse()
+
+	if *help || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(0)
+	}
+
+	var lines [][]byte
+	for _, file := range flag.Args() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatalf("read: %s: %v", file, err)
+		}
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) != 0 {
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	var samples [][]byte
+	encode(lines, config.WithNoZstd(), func(payload []byte) {
+		samples = append(samples, payload)
+	})
+
+	dict, err := zstddict.Train(samples, maxSize)
+	if err != nil {
+		log.Fatalf("train: %v", err)
+	}
+	id, err := zstddict.ID(dict)
+	if err != nil {
+		log.Fatalf("train: %v", err)
+	}
+	if err := os.WriteFile(outputFile, dict, 0o644); err != nil {
+		log.Fatalf("write: %s: %v", outputFile, err)
+	}
+	log.Printf("Wrote %d byte dictionary with ID %d to %s from %d samples\n", len(dict), id, outputFile, len(samples))
+
+	// Note that the sizes below are measured on the training
+	// input; use separate input to estimate the benefit.
+	ipcBytes := encode(lines, config.WithZstd(), nil)
+	dictBytes := encode(lines, config.WithZstdDictionary(dict), nil)
+	log.Printf("Encoded %d batches: %d bytes with IPC Zstd, %d bytes with dictionary\n", len(lines), ipcBytes, dictBytes)
+}
+
+// encode converts the input using one producer per
+// batchesPerStream lines, calling each (if not nil) with every
+// payload, and returns the total size of the batches.
+func encode(lines [][]byte, opt config.Option, each func([]byte)) int {
+	var total int
+	var producer *arrow_record.Producer
+	for i, line := range lines {
+		if i%batchesPerStream == 0 {
+			if producer != nil {
+				producer.Close()
+			}
+			producer = arrow_record.NewProducerWithOptions(opt)
+		}
+		batch, err := producer.BatchArrowRecordsFromJSON(line)
+		if err != nil {
+			log.Fatalf("encode: line %d: %v", i+1, err)
+		}
+		total += proto.Size(batch)
+		if each != nil {
+			for _, payload := range batch.ArrowPayloads {
+				each(payload.Record)
+			}
+		}
+	}
+	if producer != nil {
+		producer.Close()
+	}
+	return total
+}